@@ -10,6 +10,12 @@ const (
 	ActivityStreaming
 	// ActivityListening will display "Listening to {name}".
 	ActivityListening
+	// ActivityWatching will display "Watching {name}".
+	ActivityWatching
+	// ActivityCustom will display "{emoji} {state}".
+	ActivityCustom
+	// ActivityCompeting will display "Competing in {name}".
+	ActivityCompeting
 )
 
 // Activity represents a user activity (playing a game, streaming, etc.).
@@ -30,6 +36,15 @@ type Activity struct {
 	Party *ActivityParty `json:"party,omitempty"`
 	// Images for the presence and their hover texts.
 	Assets *ActivityAssets `json:"assets,omitempty"`
+	// Emoji of a custom status.
+	Emoji *ActivityEmoji `json:"emoji,omitempty"`
+}
+
+// ActivityEmoji is the emoji of a custom status.
+type ActivityEmoji struct {
+	Name     string `json:"name"`
+	ID       string `json:"id,omitempty"`
+	Animated bool   `json:"animated,omitempty"`
 }
 
 // ActivityTimestamp is the unix time (in milliseconds) of when the
@@ -0,0 +1,427 @@
+/*
+Package analytics records the usage of application commands: which command was
+used, in which guild and by whom, how long it took to handle and whether it
+succeeded. Invocations are batched and written to a Sink, which is in memory
+by default and can be exposed on a debug endpoint:
+
+	mem := analytics.NewMemory(1000)
+	collector := analytics.New(mem)
+	defer collector.Close()
+
+	client.UseMiddleware(collector.Middleware())
+	client.OnInteractionCreateCtx(func(ctx context.Context, i *harmony.Interaction) {
+		if err := handleCommand(ctx, i); err != nil {
+			analytics.Fail(ctx, err)
+		}
+	})
+
+	debug.NewAnalyticsHTTP(mem) // Or http.Handle("/debug/commands", mem).
+
+Handlers receiving interactions over HTTP are wrapped with Collector.Handler.
+Invocations can be stored in a SQL database or in ClickHouse with the sqlsink
+and clickhouse packages, or anywhere else by implementing Sink.
+*/
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/clock"
+	"github.com/skwair/harmony/command"
+	"github.com/skwair/harmony/interactions"
+)
+
+const (
+	// DefaultBatchSize is the default number of invocations written at once.
+	DefaultBatchSize = 100
+	// DefaultFlushInterval is the default maximum time invocations wait
+	// before being written.
+	DefaultFlushInterval = 5 * time.Second
+
+	// writeTimeout is the time allowed to write a batch to the sink.
+	writeTimeout = 30 * time.Second
+	// maxPendingBatches is the number of batches kept when the sink is
+	// too slow or failing, after which invocations are dropped.
+	maxPendingBatches = 100
+)
+
+// Invocation is the use of an application command.
+type Invocation struct {
+	// Name of the command, followed by the names of the sub command
+	// group and sub command used, if any, e.g. "config set".
+	Command   string `json:"command"`
+	CommandID string `json:"command_id"`
+	// ID of the guild the command was used in, empty in DMs.
+	GuildID string `json:"guild_id"`
+	UserID  string `json:"user_id"`
+	// Time the handling of the command started at.
+	Time time.Time `json:"time"`
+	// Time it took to handle the command.
+	Latency time.Duration `json:"latency"`
+	Success bool          `json:"success"`
+	// Error that caused the command to fail, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Sink stores invocations recorded by a Collector. Write is called by a single
+// goroutine at a time, with batches of invocations in the order they were recorded.
+type Sink interface {
+	Write(ctx context.Context, invocations []Invocation) error
+}
+
+// Collector records invocations of application commands and writes them to
+// its sink in batches, from a background goroutine. It is safe for concurrent
+// use. Create one with New and close it with Close.
+type Collector struct {
+	sink          Sink
+	batchSize     int
+	flushInterval time.Duration
+	clock         clock.Clock
+	onError       func(error)
+	ctx           context.Context
+
+	mu      sync.Mutex
+	pending []Invocation
+	dropped int
+
+	full      chan struct{}
+	closed    chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Option is a function that configures a Collector.
+type Option func(*Collector)
+
+// WithBatchSize sets the number of invocations written to the sink at once.
+// Defaults to DefaultBatchSize, which is also used if n is not positive.
+func WithBatchSize(n int) Option {
+	return func(c *Collector) {
+		c.batchSize = n
+	}
+}
+
+// WithFlushInterval sets the maximum time invocations wait before being
+// written to the sink when there are not enough of them to fill a batch.
+// Defaults to DefaultFlushInterval, which is also used if d is not positive.
+func WithFlushInterval(d time.Duration) Option {
+	return func(c *Collector) {
+		c.flushInterval = d
+	}
+}
+
+// WithClock sets the clock used to measure the latency of commands and to
+// periodically flush invocations, mainly for testing purposes. Defaults to
+// the system clock.
+func WithClock(clk clock.Clock) Option {
+	return func(c *Collector) {
+		c.clock = clk
+	}
+}
+
+// WithContext makes the collector close itself once the given context is
+// done, for instance the one of the client, see harmony.Client.Context.
+// Pending invocations are still written to the sink.
+// Defaults to context.Background, the collector is closed with Close.
+func WithContext(ctx context.Context) Option {
+	return func(c *Collector) {
+		c.ctx = ctx
+	}
+}
+
+// WithErrorHandler sets the function called when invocations can not be
+// written to the sink, or are dropped because it is too slow or failing.
+// Errors are ignored by default.
+func WithErrorHandler(f func(err error)) Option {
+	return func(c *Collector) {
+		c.onError = f
+	}
+}
+
+// New returns a new Collector writing invocations to the given sink, or to a
+// Memory sink keeping the last 1000 invocations if it is nil.
+func New(sink Sink, opts ...Option) *Collector {
+	if sink == nil {
+		sink = NewMemory(1000)
+	}
+
+	c := &Collector{
+		sink:          sink,
+		batchSize:     DefaultBatchSize,
+		flushInterval: DefaultFlushInterval,
+		clock:         clock.New(),
+		onError:       func(error) {},
+		ctx:           context.Background(),
+		full:          make(chan struct{}, 1),
+		closed:        make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.batchSize <= 0 {
+		c.batchSize = DefaultBatchSize
+	}
+	if c.flushInterval <= 0 {
+		c.flushInterval = DefaultFlushInterval
+	}
+
+	go c.run()
+
+	return c
+}
+
+// Record records the given invocation. Invocations recorded once the
+// collector is closed are dropped.
+func (c *Collector) Record(inv Invocation) {
+	select {
+	case <-c.closed:
+		return
+	default:
+	}
+
+	c.mu.Lock()
+	if len(c.pending) >= maxPendingBatches*c.batchSize {
+		c.dropped++
+		c.mu.Unlock()
+		return
+	}
+	c.pending = append(c.pending, inv)
+	full := len(c.pending) >= c.batchSize
+	c.mu.Unlock()
+
+	if full {
+		select {
+		case c.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Observe records the invocation of the command of the given interaction,
+// which started to be handled at start and failed if err is not nil. It does
+// nothing if the interaction is not an application command.
+func (c *Collector) Observe(i *harmony.Interaction, start time.Time, err error) {
+	if i.Type != harmony.InteractionTypeApplicationCommand || i.Data == nil {
+		return
+	}
+
+	inv := Invocation{
+		Command:   CommandName(i),
+		CommandID: i.Data.ID,
+		GuildID:   i.GuildID,
+		Time:      start,
+		Latency:   c.clock.Since(start),
+		Success:   err == nil,
+	}
+	if u := i.Author(); u != nil {
+		inv.UserID = u.ID
+	}
+	if err != nil {
+		inv.Error = err.Error()
+	}
+	c.Record(inv)
+}
+
+// Middleware returns a harmony.Middleware recording the invocations of
+// application commands received through the Gateway. Commands succeed unless
+// their handler panics or calls Fail with the context of the event, which
+// requires registering it with OnInteractionCreateCtx.
+func (c *Collector) Middleware() harmony.Middleware {
+	return func(next harmony.EventHandler) harmony.EventHandler {
+		return func(e *harmony.Event) {
+			i, ok := e.Data.(*harmony.Interaction)
+			if !ok || i.Type != harmony.InteractionTypeApplicationCommand {
+				next(e)
+				return
+			}
+
+			res := &result{}
+			start := c.clock.Now()
+			defer func() {
+				if r := recover(); r != nil {
+					c.Observe(i, start, fmt.Errorf("panic: %v", r))
+					panic(r)
+				}
+				c.Observe(i, start, res.error())
+			}()
+
+			next(e.WithContext(context.WithValue(e.Context(), resultKey{}, res)))
+		}
+	}
+}
+
+// Handler wraps a handler of interactions received over HTTP to record the
+// invocations of application commands. Commands succeed unless f panics,
+// returns no response or calls Fail with the given context.
+func (c *Collector) Handler(f interactions.HandlerFunc) interactions.HandlerFunc {
+	return func(ctx context.Context, i *harmony.Interaction) (resp *harmony.InteractionResponse) {
+		if i.Type != harmony.InteractionTypeApplicationCommand {
+			return f(ctx, i)
+		}
+
+		res := &result{}
+		start := c.clock.Now()
+		defer func() {
+			if r := recover(); r != nil {
+				c.Observe(i, start, fmt.Errorf("panic: %v", r))
+				panic(r)
+			}
+			if resp == nil {
+				res.fail(errors.New("no response"))
+			}
+			c.Observe(i, start, res.error())
+		}()
+
+		return f(context.WithValue(ctx, resultKey{}, res), i)
+	}
+}
+
+// Flush writes the pending invocations to the sink. Invocations that could not
+// be written are kept to be written by the next flush, up to a bound after
+// which the oldest ones are dropped.
+func (c *Collector) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	pending, dropped := c.pending, c.dropped
+	c.pending, c.dropped = nil, 0
+	c.mu.Unlock()
+
+	if dropped > 0 {
+		c.onError(fmt.Errorf("analytics: dropped %d invocations, the sink is too slow or failing", dropped))
+	}
+
+	for len(pending) > 0 {
+		n := c.batchSize
+		if n > len(pending) {
+			n = len(pending)
+		}
+		if err := c.sink.Write(ctx, pending[:n]); err != nil {
+			c.requeue(pending)
+			return fmt.Errorf("analytics: could not write %d invocations: %w", len(pending), err)
+		}
+		pending = pending[n:]
+	}
+	return nil
+}
+
+// requeue puts back invocations that could not be written before the ones
+// recorded since, dropping the oldest ones if there are too many of them.
+func (c *Collector) requeue(invocations []Invocation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending := make([]Invocation, 0, len(invocations)+len(c.pending))
+	pending = append(pending, invocations...)
+	pending = append(pending, c.pending...)
+	if max := maxPendingBatches * c.batchSize; len(pending) > max {
+		c.dropped += len(pending) - max
+		pending = pending[len(pending)-max:]
+	}
+	c.pending = pending
+}
+
+// Close stops the collector after writing the pending invocations to the sink.
+func (c *Collector) Close() {
+	c.close()
+	<-c.done
+}
+
+func (c *Collector) close() {
+	c.closeOnce.Do(func() { close(c.closed) })
+}
+
+// run flushes pending invocations when a batch is full or periodically,
+// until the collector is closed or its context is done.
+func (c *Collector) run() {
+	defer close(c.done)
+
+	ticker := c.clock.NewTicker(c.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.full:
+		case <-ticker.C():
+		case <-c.ctx.Done():
+			c.close()
+			c.flush()
+			return
+		case <-c.closed:
+			c.flush()
+			return
+		}
+		c.flush()
+	}
+}
+
+// flush writes the pending invocations to the sink, reporting errors.
+func (c *Collector) flush() {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	if err := c.Flush(ctx); err != nil {
+		c.onError(err)
+	}
+}
+
+// CommandName returns the name of the command of the given interaction, followed
+// by the names of the sub command group and sub command used, if any.
+func CommandName(i *harmony.Interaction) string {
+	if i.Data == nil {
+		return ""
+	}
+
+	names := []string{i.Data.Name}
+	opts := i.Data.Options
+	for len(opts) > 0 && (opts[0].Type == command.OptionTypeSubCommandGroup || opts[0].Type == command.OptionTypeSubCommand) {
+		names = append(names, opts[0].Name)
+		opts = opts[0].Options
+	}
+	return strings.Join(names, " ")
+}
+
+// resultKey is the context key of the result of an invocation.
+type resultKey struct{}
+
+// result is the result of an invocation, set by Fail.
+type result struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (r *result) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *result) error() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+// Fail marks the invocation of the command handled with the given context,
+// as passed by Collector.Middleware or Collector.Handler, as failed because
+// of err. Only the first error is kept. It does nothing if the context does
+// not come from a collector.
+func Fail(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	if res, ok := ctx.Value(resultKey{}).(*result); ok {
+		res.fail(err)
+	}
+}
@@ -0,0 +1,274 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony/clock"
+)
+
+// fakeSink is a Sink recording the batches written to it, failing while fail is set.
+type fakeSink struct {
+	mu      sync.Mutex
+	batches [][]Invocation
+	fail    bool
+}
+
+func (s *fakeSink) Write(_ context.Context, invocations []Invocation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fail {
+		return errors.New("sink unavailable")
+	}
+	s.batches = append(s.batches, append([]Invocation(nil), invocations...))
+	return nil
+}
+
+func (s *fakeSink) setFail(fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fail = fail
+}
+
+// written returns the batches written so far.
+func (s *fakeSink) written() [][]Invocation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([][]Invocation(nil), s.batches...)
+}
+
+// commands returns the commands of the invocations written so far, in order.
+func (s *fakeSink) commands() []string {
+	var commands []string
+	for _, batch := range s.written() {
+		for _, inv := range batch {
+			commands = append(commands, inv.Command)
+		}
+	}
+	return commands
+}
+
+// errorRecorder records the errors reported by a Collector.
+type errorRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *errorRecorder) record(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errs = append(r.errs, err)
+}
+
+// dropped returns the number of invocations reported as dropped.
+func (r *errorRecorder) dropped() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var dropped int
+	for _, err := range r.errs {
+		var n int
+		if _, scanErr := fmt.Sscanf(err.Error(), "analytics: dropped %d invocations", &n); scanErr == nil {
+			dropped += n
+		}
+	}
+	return dropped
+}
+
+func invocation(i int) Invocation {
+	return Invocation{Command: strconv.Itoa(i)}
+}
+
+func TestCollectorBatchSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		records   int
+		expected  int
+	}{
+		{name: "custom", batchSize: 3, records: 10, expected: 3},
+		{name: "zero", batchSize: 0, records: DefaultBatchSize + 10, expected: DefaultBatchSize},
+		{name: "negative", batchSize: -1, records: DefaultBatchSize + 10, expected: DefaultBatchSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &fakeSink{}
+			// With a mock clock, invocations are only written
+			// once a batch is full or the collector is closed.
+			c := New(sink, WithBatchSize(tt.batchSize), WithClock(clock.NewMock(time.Unix(0, 0))))
+			for i := 0; i < tt.records; i++ {
+				c.Record(invocation(i))
+			}
+			c.Close()
+
+			batches := sink.written()
+			if len(batches) == 0 || len(batches[0]) != tt.expected {
+				t.Fatalf("expected the first batch to hold %d invocations, got %d batches", tt.expected, len(batches))
+			}
+			for _, batch := range batches {
+				if len(batch) > tt.expected {
+					t.Errorf("expected batches of at most %d invocations, got %d", tt.expected, len(batch))
+				}
+			}
+			if commands := sink.commands(); len(commands) != tt.records {
+				t.Errorf("expected %d invocations to be written, got %d", tt.records, len(commands))
+			}
+		})
+	}
+}
+
+func TestCollectorFlushInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		elapsed  time.Duration
+		flushed  bool
+	}{
+		{name: "custom", interval: time.Second, elapsed: time.Second, flushed: true},
+		{name: "custom not elapsed", interval: time.Second, elapsed: time.Second / 2, flushed: false},
+		{name: "zero", interval: 0, elapsed: DefaultFlushInterval, flushed: true},
+		{name: "zero not elapsed", interval: 0, elapsed: time.Second, flushed: false},
+		{name: "negative", interval: -time.Second, elapsed: DefaultFlushInterval, flushed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewMock(time.Unix(0, 0))
+			sink := &fakeSink{}
+			c := New(sink, WithFlushInterval(tt.interval), WithClock(clk))
+			defer c.Close()
+
+			for clk.Pending() == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			c.Record(invocation(0))
+			clk.Add(tt.elapsed)
+
+			if tt.flushed {
+				deadline := time.Now().Add(5 * time.Second)
+				for len(sink.written()) == 0 {
+					if time.Now().After(deadline) {
+						t.Fatal("expected invocations to be flushed")
+					}
+					time.Sleep(time.Millisecond)
+				}
+			} else if len(sink.written()) != 0 {
+				t.Error("expected invocations not to be flushed yet")
+			}
+		})
+	}
+}
+
+func TestCollectorRequeue(t *testing.T) {
+	tests := []struct {
+		name string
+		// Number of invocations recorded while the sink fails,
+		// then once it works again.
+		failing, working int
+		written          []string
+	}{
+		{name: "requeued", failing: 3, working: 0, written: []string{"0", "1", "2"}},
+		{name: "requeued before new invocations", failing: 2, working: 2, written: []string{"0", "1", "2", "3"}},
+		{name: "nothing to requeue", failing: 0, working: 2, written: []string{"0", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &fakeSink{fail: true}
+			// Batches are never full, invocations are only written by Flush.
+			c := New(sink, WithBatchSize(10), WithClock(clock.NewMock(time.Unix(0, 0))))
+			defer c.Close()
+
+			ctx := context.Background()
+			for i := 0; i < tt.failing; i++ {
+				c.Record(invocation(i))
+			}
+			if err := c.Flush(ctx); (err != nil) != (tt.failing > 0) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			sink.setFail(false)
+			for i := tt.failing; i < tt.failing+tt.working; i++ {
+				c.Record(invocation(i))
+			}
+			if err := c.Flush(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			if commands := sink.commands(); strings.Join(commands, ",") != strings.Join(tt.written, ",") {
+				t.Errorf("expected %v to be written, got %v", tt.written, commands)
+			}
+		})
+	}
+}
+
+func TestCollectorDropsWhenFailing(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		records   int
+		dropped   int
+	}{
+		{name: "under the bound", batchSize: 2, records: maxPendingBatches * 2, dropped: 0},
+		{name: "over the bound", batchSize: 2, records: maxPendingBatches*2 + 50, dropped: 50},
+		{name: "single invocation batches", batchSize: 1, records: maxPendingBatches + 10, dropped: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &fakeSink{fail: true}
+			errs := &errorRecorder{}
+			c := New(sink, WithBatchSize(tt.batchSize), WithClock(clock.NewMock(time.Unix(0, 0))), WithErrorHandler(errs.record))
+
+			// Full batches are flushed in the background,
+			// failing and requeuing their invocations.
+			for i := 0; i < tt.records; i++ {
+				c.Record(invocation(i))
+			}
+			sink.setFail(false)
+			c.Close()
+
+			written := len(sink.commands())
+			if written != tt.records-tt.dropped {
+				t.Errorf("expected %d invocations to be written, got %d", tt.records-tt.dropped, written)
+			}
+			if dropped := errs.dropped(); dropped != tt.dropped {
+				t.Errorf("expected %d invocations to be reported as dropped, got %d", tt.dropped, dropped)
+			}
+		})
+	}
+}
+
+func TestCollectorContext(t *testing.T) {
+	sink := &fakeSink{}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := New(sink, WithContext(ctx), WithClock(clock.NewMock(time.Unix(0, 0))))
+
+	c.Record(invocation(0))
+	cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sink.commands()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected pending invocations to be written once the context is done")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	c.Record(invocation(1))
+	c.Close()
+	if commands := sink.commands(); len(commands) != 1 {
+		t.Errorf("expected invocations recorded once the context is done to be dropped, got %v", commands)
+	}
+}
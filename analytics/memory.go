@@ -0,0 +1,149 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+var _ Sink = (*Memory)(nil)
+
+// Memory is a Sink keeping the last invocations and statistics about each
+// command in memory. It is an http.Handler exposing them as JSON, meant to
+// be used as a debug endpoint. Statistics are lost when the program exits.
+type Memory struct {
+	mu       sync.Mutex
+	recent   []Invocation // Ring buffer of the last invocations.
+	next     int          // Index of the next invocation in recent.
+	size     int
+	commands map[string]*commandStats
+}
+
+// CommandStats are statistics about the invocations of a command.
+type CommandStats struct {
+	Command     string `json:"command"`
+	Invocations uint64 `json:"invocations"`
+	Failures    uint64 `json:"failures"`
+	// Number of distinct guilds the command was used in.
+	Guilds         int           `json:"guilds"`
+	AverageLatency time.Duration `json:"average_latency"`
+	MaxLatency     time.Duration `json:"max_latency"`
+	LastUsed       time.Time     `json:"last_used"`
+}
+
+type commandStats struct {
+	CommandStats
+	totalLatency time.Duration
+	guilds       map[string]struct{}
+}
+
+// NewMemory returns a new Memory sink keeping the last n invocations.
+// Statistics include all invocations.
+func NewMemory(n int) *Memory {
+	return &Memory{
+		recent:   make([]Invocation, 0, n),
+		size:     n,
+		commands: make(map[string]*commandStats),
+	}
+}
+
+// Write implements Sink.
+func (m *Memory) Write(_ context.Context, invocations []Invocation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, inv := range invocations {
+		m.add(inv)
+	}
+	return nil
+}
+
+func (m *Memory) add(inv Invocation) {
+	if m.size > 0 {
+		if len(m.recent) < m.size {
+			m.recent = append(m.recent, inv)
+		} else {
+			m.recent[m.next] = inv
+		}
+		m.next = (m.next + 1) % m.size
+	}
+
+	s, ok := m.commands[inv.Command]
+	if !ok {
+		s = &commandStats{
+			CommandStats: CommandStats{Command: inv.Command},
+			guilds:       make(map[string]struct{}),
+		}
+		m.commands[inv.Command] = s
+	}
+
+	s.Invocations++
+	if !inv.Success {
+		s.Failures++
+	}
+	if inv.GuildID != "" {
+		s.guilds[inv.GuildID] = struct{}{}
+	}
+	s.totalLatency += inv.Latency
+	if inv.Latency > s.MaxLatency {
+		s.MaxLatency = inv.Latency
+	}
+	if inv.Time.After(s.LastUsed) {
+		s.LastUsed = inv.Time
+	}
+}
+
+// Recent returns the last invocations, from the most recent to the oldest.
+func (m *Memory) Recent() []Invocation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	recent := make([]Invocation, 0, len(m.recent))
+	for i := 1; i <= len(m.recent); i++ {
+		recent = append(recent, m.recent[(m.next-i+len(m.recent))%len(m.recent)])
+	}
+	return recent
+}
+
+// Stats returns statistics about each command, from the most used to the least.
+func (m *Memory) Stats() []CommandStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]CommandStats, 0, len(m.commands))
+	for _, s := range m.commands {
+		cs := s.CommandStats
+		cs.Guilds = len(s.guilds)
+		cs.AverageLatency = s.totalLatency / time.Duration(s.Invocations)
+		stats = append(stats, cs)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Invocations != stats[j].Invocations {
+			return stats[i].Invocations > stats[j].Invocations
+		}
+		return stats[i].Command < stats[j].Command
+	})
+	return stats
+}
+
+// ServeHTTP writes the statistics of commands and the last invocations as JSON.
+func (m *Memory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body := struct {
+		Commands []CommandStats `json:"commands"`
+		Recent   []Invocation   `json:"recent"`
+	}{
+		Commands: m.Stats(),
+		Recent:   m.Recent(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
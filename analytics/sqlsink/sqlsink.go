@@ -0,0 +1,146 @@
+/*
+Package sqlsink provides an analytics.Sink inserting invocations of application
+commands into a table of a SQL database, through database/sql so it works with
+any driver:
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		// Handle error.
+	}
+	collector := analytics.New(sqlsink.New(db, "command_invocations", sqlsink.WithDollarPlaceholders()))
+
+The table must have the following columns, for instance with PostgreSQL:
+
+	CREATE TABLE command_invocations (
+		command    VARCHAR(100)     NOT NULL,
+		command_id VARCHAR(20)      NOT NULL,
+		guild_id   VARCHAR(20)      NOT NULL,
+		user_id    VARCHAR(20)      NOT NULL,
+		invoked_at TIMESTAMP        NOT NULL,
+		latency_ms DOUBLE PRECISION NOT NULL,
+		success    BOOLEAN          NOT NULL,
+		error      TEXT             NOT NULL
+	);
+
+Guild IDs are empty for commands used in DMs.
+*/
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skwair/harmony/analytics"
+)
+
+// maxRowsPerStatement limits the number of rows inserted by a single statement,
+// since databases limit the number of parameters a statement can have.
+const maxRowsPerStatement = 100
+
+// columns of the table invocations are inserted into.
+var columns = []string{"command", "command_id", "guild_id", "user_id", "invoked_at", "latency_ms", "success", "error"}
+
+var _ analytics.Sink = (*Sink)(nil)
+
+// Sink is an analytics.Sink inserting invocations into a SQL table.
+// Create one with New.
+type Sink struct {
+	db          *sql.DB
+	table       string
+	placeholder func(n int) string
+}
+
+// Option is a function that configures a Sink.
+type Option func(*Sink)
+
+// WithDollarPlaceholders makes the sink use numbered placeholders ($1, $2, ...)
+// in statements, as required by PostgreSQL. Defaults to question marks (?),
+// used by MySQL, SQLite and ClickHouse.
+func WithDollarPlaceholders() Option {
+	return func(s *Sink) {
+		s.placeholder = func(n int) string {
+			return "$" + strconv.Itoa(n)
+		}
+	}
+}
+
+// New returns a new Sink inserting invocations into the given table of db.
+func New(db *sql.DB, table string, opts ...Option) *Sink {
+	s := &Sink{
+		db:    db,
+		table: table,
+		placeholder: func(int) string {
+			return "?"
+		},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Write implements analytics.Sink. Invocations are inserted in a single transaction.
+func (s *Sink) Write(ctx context.Context, invocations []analytics.Invocation) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rolling back a committed transaction does nothing.
+	defer tx.Rollback()
+
+	for len(invocations) > 0 {
+		n := maxRowsPerStatement
+		if n > len(invocations) {
+			n = len(invocations)
+		}
+		query, args := s.insert(invocations[:n])
+		if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		invocations = invocations[n:]
+	}
+
+	return tx.Commit()
+}
+
+// insert returns the statement and its arguments inserting the given invocations.
+func (s *Sink) insert(invocations []analytics.Invocation) (string, []interface{}) {
+	var b strings.Builder
+	b.WriteString("INSERT INTO ")
+	b.WriteString(s.table)
+	b.WriteString(" (")
+	b.WriteString(strings.Join(columns, ", "))
+	b.WriteString(") VALUES ")
+
+	args := make([]interface{}, 0, len(invocations)*len(columns))
+	for i, inv := range invocations {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for j := range columns {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(s.placeholder(len(args) + j + 1))
+		}
+		b.WriteByte(')')
+
+		args = append(args,
+			inv.Command,
+			inv.CommandID,
+			inv.GuildID,
+			inv.UserID,
+			inv.Time.UTC(),
+			float64(inv.Latency)/float64(time.Millisecond),
+			inv.Success,
+			inv.Error,
+		)
+	}
+	return b.String(), args
+}
@@ -0,0 +1,323 @@
+/*
+Package antispam provides a plugin watching the rate at which members join guilds
+and send messages, to detect raids and spam. When the configured thresholds are
+exceeded, handlers are called with the evidence and configured actions are applied:
+slowing down the channel spam was detected in, or locking the guild down by
+disabling invites and direct messages for a while.
+
+	d := antispam.New(antispam.DefaultConfig(),
+		antispam.WithRaidHandler(func(r *antispam.RaidSuspected) {
+			// Alert moderators...
+		}),
+	)
+	client, err := harmony.NewClient(token, harmony.WithPlugins(d))
+
+The client needs the GUILD_MEMBERS privileged intent to detect raids and the
+GUILD_MESSAGES intent to detect spam.
+*/
+package antispam
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/clock"
+)
+
+const (
+	// actionTimeout is the time allowed to apply actions.
+	actionTimeout = 10 * time.Second
+	// pruneInterval is the interval at which windows with no recent
+	// events are dropped. Windows are only added as events are received,
+	// so they are pruned as events are received too.
+	pruneInterval = time.Minute
+)
+
+// Config configures the detection thresholds and the actions
+// applied when a raid or spam is suspected.
+type Config struct {
+	// A raid is suspected when RaidJoins members join the guild
+	// within RaidWindow. Raid detection is disabled if RaidJoins is 0.
+	RaidJoins  int
+	RaidWindow time.Duration
+	// Spam is suspected when a member sends SpamMessages messages
+	// within SpamWindow. Spam detection is disabled if SpamMessages is 0.
+	SpamMessages int
+	SpamWindow   time.Duration
+
+	// Slowmode is the rate limit per user, in seconds, applied to the channel
+	// spam is detected in. No slowmode is applied if it is 0.
+	Slowmode int
+	// Lockdown is the duration for which invites and direct messages are disabled
+	// when a raid is suspected, at most harmony.MaxIncidentActionDuration. The guild
+	// is not locked down if it is 0.
+	Lockdown time.Duration
+}
+
+// DefaultConfig returns a configuration detecting raids of 10 joins within 10 seconds
+// and spam of 5 messages within 5 seconds, without applying any action.
+func DefaultConfig() Config {
+	return Config{
+		RaidJoins:    10,
+		RaidWindow:   10 * time.Second,
+		SpamMessages: 5,
+		SpamWindow:   5 * time.Second,
+	}
+}
+
+// RaidSuspected is the evidence of a suspected raid.
+type RaidSuspected struct {
+	GuildID string
+	// IDs of the users that joined within the window,
+	// and time the first of them joined.
+	UserIDs []string
+	Since   time.Time
+	// LockedDown is set if the guild was locked down.
+	LockedDown bool
+}
+
+// SpamSuspected is the evidence of suspected spam.
+type SpamSuspected struct {
+	GuildID   string
+	ChannelID string
+	UserID    string
+	// IDs of the messages sent within the window, and
+	// time the first of them was received.
+	MessageIDs []string
+	Since      time.Time
+	// SlowedDown is set if a slowmode was applied to the channel.
+	SlowedDown bool
+}
+
+// Detector is a harmony.Plugin detecting raids and spam. Create one with New.
+type Detector struct {
+	config  Config
+	clock   clock.Clock
+	onRaid  func(*RaidSuspected)
+	onSpam  func(*SpamSuspected)
+	onError func(error)
+
+	mu      sync.RWMutex
+	configs map[string]Config
+
+	client *harmony.Client
+	sub    *harmony.Subscription
+
+	// Only accessed by the subscription processing events.
+	joins    map[string]*window // By guild.
+	messages map[string]*window // By guild and user.
+	pruned   time.Time
+}
+
+// Option is a function that configures a Detector.
+type Option func(*Detector)
+
+// WithRaidHandler sets the function called when a raid is suspected.
+func WithRaidHandler(f func(r *RaidSuspected)) Option {
+	return func(d *Detector) {
+		d.onRaid = f
+	}
+}
+
+// WithSpamHandler sets the function called when spam is suspected.
+func WithSpamHandler(f func(s *SpamSuspected)) Option {
+	return func(d *Detector) {
+		d.onSpam = f
+	}
+}
+
+// WithErrorHandler sets the function called when an action can not be
+// applied, for instance because of missing permissions. Errors are
+// ignored by default.
+func WithErrorHandler(f func(err error)) Option {
+	return func(d *Detector) {
+		d.onError = f
+	}
+}
+
+// WithClock sets the clock used by the detector, mainly for testing purposes.
+// Defaults to the system clock.
+func WithClock(c clock.Clock) Option {
+	return func(d *Detector) {
+		d.clock = c
+	}
+}
+
+// New returns a new Detector using the given configuration for
+// all guilds, unless overridden with SetGuildConfig.
+func New(cfg Config, opts ...Option) *Detector {
+	d := &Detector{
+		config:   cfg,
+		clock:    clock.New(),
+		onRaid:   func(*RaidSuspected) {},
+		onSpam:   func(*SpamSuspected) {},
+		onError:  func(error) {},
+		configs:  make(map[string]Config),
+		joins:    make(map[string]*window),
+		messages: make(map[string]*window),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// SetGuildConfig overrides the configuration of the given guild.
+func (d *Detector) SetGuildConfig(guildID string, cfg Config) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.configs[guildID] = cfg
+}
+
+// ResetGuildConfig makes the given guild use the default configuration of the detector.
+func (d *Detector) ResetGuildConfig(guildID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.configs, guildID)
+}
+
+func (d *Detector) guildConfig(guildID string) Config {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if cfg, ok := d.configs[guildID]; ok {
+		return cfg
+	}
+	return d.config
+}
+
+// Init implements the harmony.Plugin interface.
+func (d *Detector) Init(c *harmony.Client) error {
+	d.client = c
+	return nil
+}
+
+// Start implements the harmony.Plugin interface.
+func (d *Detector) Start(_ context.Context) error {
+	d.sub = d.client.Subscribe(d.handle)
+	return nil
+}
+
+// Stop implements the harmony.Plugin interface.
+func (d *Detector) Stop(ctx context.Context) error {
+	return d.sub.Close(ctx)
+}
+
+func (d *Detector) handle(e *harmony.Event) {
+	if now := d.clock.Now(); now.Sub(d.pruned) >= pruneInterval {
+		d.prune(now)
+		d.pruned = now
+	}
+
+	switch v := e.Data.(type) {
+	case *harmony.GuildMemberAdd:
+		if v.GuildMember != nil && v.User != nil {
+			d.memberJoined(v.GuildID, v.User.ID)
+		}
+	case *harmony.Message:
+		if e.Type == "MESSAGE_CREATE" && v.Author != nil && !v.Author.Bot {
+			d.messageSent(v)
+		}
+	}
+}
+
+func (d *Detector) memberJoined(guildID, userID string) {
+	cfg := d.guildConfig(guildID)
+	if cfg.RaidJoins <= 0 {
+		return
+	}
+
+	w, ok := d.joins[guildID]
+	if !ok {
+		w = &window{guildID: guildID}
+		d.joins[guildID] = w
+	}
+	now := d.clock.Now()
+	if w.add(userID, now, cfg.RaidWindow) < cfg.RaidJoins {
+		return
+	}
+
+	raid := &RaidSuspected{GuildID: guildID, UserIDs: w.ids(), Since: w.since()}
+	// Start over so the same joins do not trigger another detection.
+	delete(d.joins, guildID)
+
+	if cfg.Lockdown > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+		until := now.Add(cfg.Lockdown)
+		_, err := d.client.Guild(guildID).ModifyIncidentActions(ctx, until, until)
+		cancel()
+		if err != nil {
+			d.onError(fmt.Errorf("antispam: could not lock down guild %s: %w", guildID, err))
+		} else {
+			raid.LockedDown = true
+		}
+	}
+
+	d.onRaid(raid)
+}
+
+func (d *Detector) messageSent(msg *harmony.Message) {
+	if msg.GuildID == "" {
+		return
+	}
+	cfg := d.guildConfig(msg.GuildID)
+	if cfg.SpamMessages <= 0 {
+		return
+	}
+
+	key := msg.GuildID + "/" + msg.Author.ID
+	w, ok := d.messages[key]
+	if !ok {
+		w = &window{guildID: msg.GuildID}
+		d.messages[key] = w
+	}
+	if w.add(msg.ID, d.clock.Now(), cfg.SpamWindow) < cfg.SpamMessages {
+		return
+	}
+
+	spam := &SpamSuspected{
+		GuildID:    msg.GuildID,
+		ChannelID:  msg.ChannelID,
+		UserID:     msg.Author.ID,
+		MessageIDs: w.ids(),
+		Since:      w.since(),
+	}
+	delete(d.messages, key)
+
+	if cfg.Slowmode > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+		settings := channel.NewSettings(channel.WithRateLimitPerUser(cfg.Slowmode))
+		_, err := d.client.Channel(msg.ChannelID).ModifyWithReason(ctx, settings, "Anti-spam: spam suspected")
+		cancel()
+		if err != nil {
+			d.onError(fmt.Errorf("antispam: could not apply slowmode to channel %s: %w", msg.ChannelID, err))
+		} else {
+			spam.SlowedDown = true
+		}
+	}
+
+	d.onSpam(spam)
+}
+
+// prune drops windows whose events are all older than the window
+// duration configured for their guild, so they do not pile up.
+func (d *Detector) prune(now time.Time) {
+	for guildID, w := range d.joins {
+		if now.Sub(w.last()) > d.guildConfig(guildID).RaidWindow {
+			delete(d.joins, guildID)
+		}
+	}
+	for key, w := range d.messages {
+		if now.Sub(w.last()) > d.guildConfig(w.guildID).SpamWindow {
+			delete(d.messages, key)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package antispam
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/clock"
+	"github.com/skwair/harmony/harmonytest"
+)
+
+// recorder records the evidence and errors reported by a Detector.
+type recorder struct {
+	raids []*RaidSuspected
+	spams []*SpamSuspected
+	errs  []error
+}
+
+// newDetector returns a detector using a client of the given server,
+// reporting to the returned recorder. Events are given to it with handle.
+func newDetector(t *testing.T, srv *harmonytest.Server, clk clock.Clock, cfg Config) (*Detector, *recorder) {
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	rec := &recorder{}
+	d := New(cfg,
+		WithClock(clk),
+		WithRaidHandler(func(r *RaidSuspected) { rec.raids = append(rec.raids, r) }),
+		WithSpamHandler(func(s *SpamSuspected) { rec.spams = append(rec.spams, s) }),
+		WithErrorHandler(func(err error) { rec.errs = append(rec.errs, err) }),
+	)
+	if err = d.Init(c); err != nil {
+		t.Fatal(err)
+	}
+	return d, rec
+}
+
+func join(guildID, userID string) *harmony.Event {
+	return &harmony.Event{
+		Type: "GUILD_MEMBER_ADD",
+		Data: &harmony.GuildMemberAdd{GuildID: guildID, GuildMember: &harmony.GuildMember{User: &harmony.User{ID: userID}}},
+	}
+}
+
+func message(id, userID string, bot bool) *harmony.Event {
+	return &harmony.Event{
+		Type: "MESSAGE_CREATE",
+		Data: &harmony.Message{ID: id, GuildID: "1", ChannelID: "2", Author: &harmony.User{ID: userID, Bot: bot}},
+	}
+}
+
+func TestSpamDetection(t *testing.T) {
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	srv.Handle(http.MethodPatch, "/channels/:channel", func(w http.ResponseWriter, r *http.Request) {
+		harmonytest.WriteJSON(w, http.StatusOK, &harmony.Channel{ID: harmonytest.Param(r, "channel")})
+	})
+
+	clk := clock.NewMock(time.Unix(0, 0))
+	d, rec := newDetector(t, srv, clk, Config{SpamMessages: 3, SpamWindow: 5 * time.Second, Slowmode: 10})
+
+	d.handle(message("10", "3", false))
+	d.handle(message("11", "3", true))  // Bots are ignored.
+	d.handle(message("12", "4", false)) // Other users are counted apart.
+	clk.Add(6 * time.Second)
+	d.handle(message("13", "3", false)) // The first message left the window.
+	d.handle(message("14", "3", false))
+	if len(rec.spams) != 0 {
+		t.Fatalf("expected no spam to be detected yet, got %+v", rec.spams)
+	}
+
+	d.handle(message("15", "3", false))
+	if len(rec.spams) != 1 {
+		t.Fatalf("expected spam to be detected once, got %d", len(rec.spams))
+	}
+	spam := rec.spams[0]
+	if spam.UserID != "3" || spam.ChannelID != "2" || !spam.SlowedDown {
+		t.Errorf("expected spam of user 3 in slowed down channel 2, got %+v", spam)
+	}
+	if expected := []string{"13", "14", "15"}; !reflect.DeepEqual(spam.MessageIDs, expected) {
+		t.Errorf("expected messages %v, got %v", expected, spam.MessageIDs)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := srv.WaitRequest(ctx, harmonytest.MatchRoute(http.MethodPatch, "/channels/2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body struct {
+		RateLimitPerUser int `json:"rate_limit_per_user"`
+	}
+	if err = req.Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.RateLimitPerUser != 10 {
+		t.Errorf("expected a slowmode of 10 seconds, got %d", body.RateLimitPerUser)
+	}
+
+	// Detection starts over.
+	d.handle(message("16", "3", false))
+	if len(rec.spams) != 1 {
+		t.Errorf("expected messages to be counted again from zero, got %d detections", len(rec.spams))
+	}
+}
+
+func TestRaidDetection(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		lockedDown bool
+	}{
+		{name: "locked down", status: http.StatusOK, lockedDown: true},
+		{name: "lockdown failed", status: http.StatusForbidden, lockedDown: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := harmonytest.NewServer()
+			defer srv.Close()
+
+			srv.Handle(http.MethodPut, "/guilds/:guild/incident-actions", func(w http.ResponseWriter, r *http.Request) {
+				if tt.status != http.StatusOK {
+					harmonytest.WriteError(w, tt.status, 50013, "Missing Permissions")
+					return
+				}
+				harmonytest.WriteJSON(w, http.StatusOK, &harmony.GuildIncidents{})
+			})
+
+			clk := clock.NewMock(time.Unix(0, 0))
+			d, rec := newDetector(t, srv, clk, Config{RaidJoins: 3, RaidWindow: 10 * time.Second, Lockdown: time.Hour})
+			// Raid detection is disabled for this guild.
+			d.SetGuildConfig("2", Config{})
+
+			for i := 0; i < 3; i++ {
+				d.handle(join("1", strconv.Itoa(10+i)))
+				d.handle(join("2", strconv.Itoa(10+i)))
+			}
+
+			if len(rec.raids) != 1 {
+				t.Fatalf("expected a single raid to be detected, got %d", len(rec.raids))
+			}
+			raid := rec.raids[0]
+			if raid.GuildID != "1" || raid.LockedDown != tt.lockedDown || len(raid.UserIDs) != 3 {
+				t.Errorf("expected a raid of 3 users in guild 1, locked down: %t, got %+v", tt.lockedDown, raid)
+			}
+			if failed := len(rec.errs) == 1; failed == tt.lockedDown {
+				t.Errorf("expected an error to be reported: %t, got %v", !tt.lockedDown, rec.errs)
+			}
+			for _, err := range rec.errs {
+				var apiErr harmony.APIError
+				if !errors.As(err, &apiErr) {
+					t.Errorf("expected the error to wrap the API error, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	clk := clock.NewMock(time.Unix(0, 0))
+	d := New(Config{RaidJoins: 10, RaidWindow: time.Second, SpamMessages: 10, SpamWindow: time.Second}, WithClock(clk))
+	d.SetGuildConfig("2", Config{RaidJoins: 10, RaidWindow: time.Hour})
+
+	// Thresholds are never reached, so the detector needs no client.
+	clk.Add(pruneInterval)
+	d.handle(join("1", "10"))
+	d.handle(join("2", "10"))
+	d.handle(message("11", "3", false))
+	if len(d.joins) != 2 || len(d.messages) != 1 {
+		t.Fatalf("expected windows to be created, got %d joins and %d messages", len(d.joins), len(d.messages))
+	}
+
+	clk.Add(pruneInterval)
+	d.handle(&harmony.Event{Type: "TYPING_START", Data: &harmony.TypingStart{}})
+	if _, ok := d.joins["2"]; !ok || len(d.joins) != 1 || len(d.messages) != 0 {
+		t.Errorf("expected only the window of guild 2 to be kept, got %d joins and %d messages", len(d.joins), len(d.messages))
+	}
+}
+
+func TestWindow(t *testing.T) {
+	start := time.Unix(0, 0)
+	w := &window{}
+
+	tests := []struct {
+		id       string
+		elapsed  time.Duration
+		expected int
+	}{
+		{id: "1", elapsed: 0, expected: 1},
+		{id: "2", elapsed: time.Second, expected: 2},
+		{id: "3", elapsed: 2 * time.Second, expected: 3},
+		// Events exactly as old as the window are kept.
+		{id: "4", elapsed: 3 * time.Second, expected: 3},
+		{id: "5", elapsed: 10 * time.Second, expected: 1},
+	}
+	for _, tt := range tests {
+		if n := w.add(tt.id, start.Add(tt.elapsed), 2*time.Second); n != tt.expected {
+			t.Errorf("expected %d events after adding %s, got %d", tt.expected, tt.id, n)
+		}
+	}
+
+	if ids := w.ids(); !reflect.DeepEqual(ids, []string{"5"}) {
+		t.Errorf("expected only event 5 to be left, got %v", ids)
+	}
+	if since, last := w.since(), w.last(); !since.Equal(start.Add(10*time.Second)) || !last.Equal(since) {
+		t.Errorf("expected the window to start and end with event 5, got %s and %s", since, last)
+	}
+}
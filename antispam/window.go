@@ -0,0 +1,53 @@
+package antispam
+
+import "time"
+
+// window is a sliding window of events, identified by ID.
+type window struct {
+	guildID string
+	entries []entry
+}
+
+type entry struct {
+	id string
+	at time.Time
+}
+
+// add adds an event to the window, drops events older than the given
+// duration and returns the number of events left in the window.
+func (w *window) add(id string, now time.Time, d time.Duration) int {
+	w.entries = append(w.entries, entry{id: id, at: now})
+
+	i := 0
+	for i < len(w.entries) && now.Sub(w.entries[i].at) > d {
+		i++
+	}
+	w.entries = w.entries[i:]
+
+	return len(w.entries)
+}
+
+// ids returns the IDs of the events in the window.
+func (w *window) ids() []string {
+	ids := make([]string, len(w.entries))
+	for i, e := range w.entries {
+		ids[i] = e.id
+	}
+	return ids
+}
+
+// since returns the time of the oldest event in the window.
+func (w *window) since() time.Time {
+	if len(w.entries) == 0 {
+		return time.Time{}
+	}
+	return w.entries[0].at
+}
+
+// last returns the time of the newest event in the window.
+func (w *window) last() time.Time {
+	if len(w.entries) == 0 {
+		return time.Time{}
+	}
+	return w.entries[len(w.entries)-1].at
+}
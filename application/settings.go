@@ -0,0 +1,101 @@
+// Package application contains the settings used to modify the
+// application of the current bot.
+package application
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/skwair/harmony/optional"
+)
+
+// Settings are the settings of an application, all fields are optional
+// and only those explicitly set will be modified.
+type Settings struct {
+	Description                    *optional.String `json:"description,omitempty"`
+	CustomInstallURL               *optional.String `json:"custom_install_url,omitempty"`
+	RoleConnectionsVerificationURL *optional.String `json:"role_connections_verification_url,omitempty"`
+	InteractionsEndpointURL        *optional.String `json:"interactions_endpoint_url,omitempty"`
+
+	// Shadows of optional URLs, since optional values can not be read back.
+	urls map[string]string
+}
+
+// Setting is a function that configures an application.
+type Setting func(*Settings)
+
+// NewSettings returns new Settings to modify an application.
+func NewSettings(opts ...Setting) *Settings {
+	s := &Settings{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithDescription sets the description of an application.
+func WithDescription(desc string) Setting {
+	return func(s *Settings) {
+		s.Description = optional.NewString(desc)
+	}
+}
+
+// WithCustomInstallURL sets the default custom authorization URL of an application.
+// An empty URL removes it.
+func WithCustomInstallURL(u string) Setting {
+	return func(s *Settings) {
+		s.CustomInstallURL = s.url("custom install URL", u)
+	}
+}
+
+// WithRoleConnectionsVerificationURL sets the URL users are sent to in order
+// to verify their linked role connections. An empty URL removes it.
+func WithRoleConnectionsVerificationURL(u string) Setting {
+	return func(s *Settings) {
+		s.RoleConnectionsVerificationURL = s.url("role connections verification URL", u)
+	}
+}
+
+// WithInteractionsEndpointURL sets the URL interactions are sent to, instead of
+// being received through the Gateway. An empty URL removes it.
+func WithInteractionsEndpointURL(u string) Setting {
+	return func(s *Settings) {
+		s.InteractionsEndpointURL = s.url("interactions endpoint URL", u)
+	}
+}
+
+// url records the URL for validation and returns its optional value.
+func (s *Settings) url(name, u string) *optional.String {
+	if u == "" {
+		delete(s.urls, name)
+		return optional.NewNilString()
+	}
+
+	if s.urls == nil {
+		s.urls = make(map[string]string)
+	}
+	s.urls[name] = u
+	return optional.NewString(u)
+}
+
+// Validate checks that the URLs of the settings are valid absolute
+// HTTP(S) URLs and returns an error listing all the problems found, if any.
+func (s *Settings) Validate() error {
+	var problems []string
+
+	for name, raw := range s.urls {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("%s %q must be an absolute HTTP(S) URL", name, raw))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid application settings: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
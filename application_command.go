@@ -0,0 +1,215 @@
+package harmony
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/skwair/harmony/command"
+	"github.com/skwair/harmony/internal/endpoint"
+)
+
+// ApplicationCommand is a command of an application, such as a slash command.
+// See the command sub package to create commands.
+type ApplicationCommand struct {
+	ID            string       `json:"id"`
+	Type          command.Type `json:"type"`
+	ApplicationID string       `json:"application_id"`
+	// GuildID is set for guild commands only.
+	GuildID                  string            `json:"guild_id"`
+	Name                     string            `json:"name"`
+	NameLocalizations        map[string]string `json:"name_localizations"`
+	Description              string            `json:"description"`
+	DescriptionLocalizations map[string]string `json:"description_localizations"`
+	Options                  []command.Option  `json:"options"`
+	// Permissions members need to use the command by default,
+	// as a bit set. Nil if all members can use the command.
+	DefaultMemberPermissions *string `json:"default_member_permissions"`
+	DMPermission             *bool   `json:"dm_permission"`
+	NSFW                     bool    `json:"nsfw"`
+	// Version is updated every time the command is modified.
+	Version string `json:"version"`
+}
+
+// ApplicationResource is a resource that allows to perform various
+// actions on an application, such as managing its commands.
+type ApplicationResource struct {
+	applicationID string
+	client        *Client
+}
+
+// Application returns a new application resource to manage the application with the given ID.
+// For bots, the ID of the application is generally the same as the ID of the bot user.
+func (c *Client) Application(id string) *ApplicationResource {
+	return &ApplicationResource{applicationID: id, client: c}
+}
+
+// Commands returns the global commands of the application.
+func (r *ApplicationResource) Commands(ctx context.Context) ([]ApplicationCommand, error) {
+	e := endpoint.GetGlobalApplicationCommands(r.applicationID)
+	return r.client.applicationCommands(ctx, e)
+}
+
+// NewCommand creates a global command. Settings must at least have a name, and
+// a description for chat input commands. Creating a command with the same name as
+// an existing command of the same type overwrites the existing one. New global
+// commands are available in all guilds after at most one hour.
+func (r *ApplicationResource) NewCommand(ctx context.Context, settings *command.Settings) (*ApplicationCommand, error) {
+	if settings.Name == nil {
+		return nil, errors.New("application command name is required")
+	}
+
+	e := endpoint.CreateGlobalApplicationCommand(r.applicationID)
+	return r.client.applicationCommand(ctx, e, settings)
+}
+
+// ModifyCommand modifies a global command.
+func (r *ApplicationResource) ModifyCommand(ctx context.Context, id string, settings *command.Settings) (*ApplicationCommand, error) {
+	e := endpoint.EditGlobalApplicationCommand(r.applicationID, id)
+	return r.client.applicationCommand(ctx, e, settings)
+}
+
+// DeleteCommand deletes a global command.
+func (r *ApplicationResource) DeleteCommand(ctx context.Context, id string) error {
+	e := endpoint.DeleteGlobalApplicationCommand(r.applicationID, id)
+	return r.client.deleteApplicationCommand(ctx, e)
+}
+
+// OverwriteCommands replaces all the global commands of the application with the
+// given ones. Commands that are not listed are deleted.
+func (r *ApplicationResource) OverwriteCommands(ctx context.Context, settings []*command.Settings) ([]ApplicationCommand, error) {
+	e := endpoint.BulkOverwriteGlobalApplicationCommands(r.applicationID)
+	return r.client.overwriteApplicationCommands(ctx, e, settings)
+}
+
+// GuildCommands returns the commands of the application specific to the given guild.
+func (r *ApplicationResource) GuildCommands(ctx context.Context, guildID string) ([]ApplicationCommand, error) {
+	e := endpoint.GetGuildApplicationCommands(r.applicationID, guildID)
+	return r.client.applicationCommands(ctx, e)
+}
+
+// NewGuildCommand creates a command specific to the given guild. It is available
+// right away. See NewCommand for the required settings.
+func (r *ApplicationResource) NewGuildCommand(ctx context.Context, guildID string, settings *command.Settings) (*ApplicationCommand, error) {
+	if settings.Name == nil {
+		return nil, errors.New("application command name is required")
+	}
+
+	e := endpoint.CreateGuildApplicationCommand(r.applicationID, guildID)
+	return r.client.applicationCommand(ctx, e, settings)
+}
+
+// ModifyGuildCommand modifies a command specific to the given guild.
+func (r *ApplicationResource) ModifyGuildCommand(ctx context.Context, guildID, id string, settings *command.Settings) (*ApplicationCommand, error) {
+	e := endpoint.EditGuildApplicationCommand(r.applicationID, guildID, id)
+	return r.client.applicationCommand(ctx, e, settings)
+}
+
+// DeleteGuildCommand deletes a command specific to the given guild.
+func (r *ApplicationResource) DeleteGuildCommand(ctx context.Context, guildID, id string) error {
+	e := endpoint.DeleteGuildApplicationCommand(r.applicationID, guildID, id)
+	return r.client.deleteApplicationCommand(ctx, e)
+}
+
+// OverwriteGuildCommands replaces all the commands of the application specific to
+// the given guild with the given ones. Commands that are not listed are deleted.
+func (r *ApplicationResource) OverwriteGuildCommands(ctx context.Context, guildID string, settings []*command.Settings) ([]ApplicationCommand, error) {
+	e := endpoint.BulkOverwriteGuildApplicationCommands(r.applicationID, guildID)
+	return r.client.overwriteApplicationCommands(ctx, e, settings)
+}
+
+func (c *Client) applicationCommands(ctx context.Context, e *endpoint.Endpoint) ([]ApplicationCommand, error) {
+	resp, err := c.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var cmds []ApplicationCommand
+	if err = json.NewDecoder(resp.Body).Decode(&cmds); err != nil {
+		return nil, err
+	}
+	return cmds, nil
+}
+
+func (c *Client) applicationCommand(ctx context.Context, e *endpoint.Endpoint, settings *command.Settings) (*ApplicationCommand, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doReq(ctx, e, jsonPayload(b))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Creating a command returns 201 Created, or 200 OK if
+	// a command with the same name already existed.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, apiError(resp)
+	}
+
+	var cmd ApplicationCommand
+	if err = json.NewDecoder(resp.Body).Decode(&cmd); err != nil {
+		return nil, err
+	}
+	return &cmd, nil
+}
+
+func (c *Client) deleteApplicationCommand(ctx context.Context, e *endpoint.Endpoint) error {
+	resp, err := c.doReq(ctx, e, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return apiError(resp)
+	}
+	return nil
+}
+
+func (c *Client) overwriteApplicationCommands(ctx context.Context, e *endpoint.Endpoint, settings []*command.Settings) ([]ApplicationCommand, error) {
+	for _, s := range settings {
+		if s.Name == nil {
+			return nil, errors.New("application command name is required")
+		}
+		if err := s.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if settings == nil {
+		settings = []*command.Settings{}
+	}
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doReq(ctx, e, jsonPayload(b))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var cmds []ApplicationCommand
+	if err = json.NewDecoder(resp.Body).Decode(&cmds); err != nil {
+		return nil, err
+	}
+	return cmds, nil
+}
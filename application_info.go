@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/skwair/harmony/application"
 	"github.com/skwair/harmony/internal/endpoint"
 )
 
@@ -38,6 +39,37 @@ type ApplicationInfo struct {
 	PrimarySKUID        string   `json:"primary_sku_id,omitempty"`
 	Slug                string   `json:"slug,omitempty"`
 	CoverImage          string   `json:"cover_image,omitempty"`
+	// URL users are sent to in order to verify their linked role connections.
+	RoleConnectionsVerificationURL string `json:"role_connections_verification_url,omitempty"`
+	// Default custom authorization URL of the application, if set.
+	CustomInstallURL string `json:"custom_install_url,omitempty"`
+	// URL interactions are sent to, if not received through the Gateway.
+	InteractionsEndpointURL string `json:"interactions_endpoint_url,omitempty"`
+
+	Flags ApplicationFlag `json:"flags,omitempty"`
+}
+
+// ApplicationFlag describes public flags of an application.
+type ApplicationFlag int
+
+// List of application flags. Flags ending in "Limited" are set for
+// applications in less than 100 servers that enabled the corresponding
+// privileged intent, while the others are set when the application was
+// approved to use it in more servers.
+const (
+	ApplicationFlagGatewayPresence               ApplicationFlag = 1 << 12
+	ApplicationFlagGatewayPresenceLimited        ApplicationFlag = 1 << 13
+	ApplicationFlagGatewayGuildMembers           ApplicationFlag = 1 << 14
+	ApplicationFlagGatewayGuildMembersLimited    ApplicationFlag = 1 << 15
+	ApplicationFlagVerificationPendingGuildLimit ApplicationFlag = 1 << 16
+	ApplicationFlagEmbedded                      ApplicationFlag = 1 << 17
+	ApplicationFlagGatewayMessageContent         ApplicationFlag = 1 << 18
+	ApplicationFlagGatewayMessageContentLimited  ApplicationFlag = 1 << 19
+)
+
+// Has returns whether f has all the given flags set.
+func (f ApplicationFlag) Has(flags ApplicationFlag) bool {
+	return f&flags == flags
 }
 
 // ApplicationInfo returns the bot's OAuth2 application info.
@@ -59,3 +91,34 @@ func (c *Client) ApplicationInfo(ctx context.Context) (*ApplicationInfo, error)
 	}
 	return &a, nil
 }
+
+// ModifyApplicationInfo modifies the bot's application, for instance to set the
+// URL used to verify linked role connections. Settings are validated before being
+// sent, see application.Settings.Validate. Returns the updated application info.
+func (c *Client) ModifyApplicationInfo(ctx context.Context, settings *application.Settings) (*ApplicationInfo, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	e := endpoint.EditCurrentApplication()
+	resp, err := c.doReq(ctx, e, jsonPayload(b))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var a ApplicationInfo
+	if err = json.NewDecoder(resp.Body).Decode(&a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
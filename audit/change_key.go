@@ -53,4 +53,21 @@ const (
 
 	changeKeyID   changeKey = "id"
 	changeKeyType changeKey = "type"
+
+	changeKeyArchived            changeKey = "archived"
+	changeKeyLocked              changeKey = "locked"
+	changeKeyInvitable           changeKey = "invitable"
+	changeKeyAutoArchiveDuration changeKey = "auto_archive_duration"
+
+	changeKeyPrivacyLevel changeKey = "privacy_level"
+	changeKeyDescription  changeKey = "description"
+	changeKeyTags         changeKey = "tags"
+	changeKeyFormatType   changeKey = "format_type"
+	changeKeyEntityType   changeKey = "entity_type"
+	changeKeyStatus       changeKey = "status"
+	changeKeyLocation     changeKey = "location"
+
+	changeKeyEventType   changeKey = "event_type"
+	changeKeyTriggerType changeKey = "trigger_type"
+	changeKeyEnabled     changeKey = "enabled"
 )
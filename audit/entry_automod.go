@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"strconv"
+)
+
+func autoModerationRuleCreateFromEntry(e *rawEntry) (*AutoModerationRuleCreate, error) {
+	ruleCreate := &AutoModerationRuleCreate{
+		BaseEntry: baseEntryFromRaw(e),
+	}
+
+	var err error
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyName:
+			ruleCreate.Name, err = stringValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyEventType:
+			ruleCreate.EventType, err = intValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyTriggerType:
+			ruleCreate.TriggerType, err = intValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyEnabled:
+			ruleCreate.Enabled, err = boolValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ruleCreate, nil
+}
+
+func autoModerationRuleUpdateFromEntry(e *rawEntry) (*AutoModerationRuleUpdate, error) {
+	ruleUpdate := &AutoModerationRuleUpdate{
+		BaseEntry: baseEntryFromRaw(e),
+	}
+
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyName:
+			oldValue, newValue, err := stringValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			ruleUpdate.Name = &StringValues{Old: oldValue, New: newValue}
+
+		case changeKeyEventType:
+			oldValue, newValue, err := intValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			ruleUpdate.EventType = &IntValues{Old: oldValue, New: newValue}
+
+		case changeKeyEnabled:
+			oldValue, newValue, err := boolValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			ruleUpdate.Enabled = &BoolValues{Old: oldValue, New: newValue}
+		}
+	}
+
+	return ruleUpdate, nil
+}
+
+func autoModerationRuleDeleteFromEntry(e *rawEntry) (*AutoModerationRuleDelete, error) {
+	ruleDelete := &AutoModerationRuleDelete{
+		BaseEntry: baseEntryFromRaw(e),
+	}
+
+	var err error
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyName:
+			ruleDelete.Name, err = stringValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyEventType:
+			ruleDelete.EventType, err = intValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyTriggerType:
+			ruleDelete.TriggerType, err = intValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyEnabled:
+			ruleDelete.Enabled, err = boolValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ruleDelete, nil
+}
+
+func autoModerationActionFromEntry(e *rawEntry) (AutoModerationAction, error) {
+	action := AutoModerationAction{
+		BaseEntry: baseEntryFromRaw(e),
+		ChannelID: e.Options.ChannelID,
+		RuleName:  e.Options.RuleName,
+	}
+
+	if e.Options.RuleTriggerType != "" {
+		var err error
+		action.RuleTriggerType, err = strconv.Atoi(e.Options.RuleTriggerType)
+		if err != nil {
+			return AutoModerationAction{}, err
+		}
+	}
+
+	return action, nil
+}
@@ -167,19 +167,19 @@ func channelOverwriteCreateFromEntry(e *rawEntry) (*ChannelOverwriteCreate, erro
 			}
 
 		case changeKeyType:
-			overwriteCreate.Type, err = stringValue(ch.New)
+			overwriteCreate.Type, err = overwriteTypeValue(ch.New)
 			if err != nil {
 				return nil, err
 			}
 
 		case changeKeyAllow:
-			overwriteCreate.Allow, err = intValue(ch.New)
+			overwriteCreate.Allow, err = permissionValue(ch.New)
 			if err != nil {
 				return nil, err
 			}
 
 		case changeKeyDeny:
-			overwriteCreate.Deny, err = intValue(ch.New)
+			overwriteCreate.Deny, err = permissionValue(ch.New)
 			if err != nil {
 				return nil, err
 			}
@@ -192,7 +192,7 @@ func channelOverwriteCreateFromEntry(e *rawEntry) (*ChannelOverwriteCreate, erro
 func channelOverwriteUpdateFromEntry(e *rawEntry) (*ChannelOverwriteUpdate, error) {
 	overwriteUpdate := &ChannelOverwriteUpdate{
 		BaseEntry: baseEntryFromRaw(e),
-		Type:      e.Options.Type,
+		Type:      overwriteType(e.Options.Type),
 		ID:        e.Options.ID,
 		RoleName:  e.Options.RoleName,
 	}
@@ -200,18 +200,18 @@ func channelOverwriteUpdateFromEntry(e *rawEntry) (*ChannelOverwriteUpdate, erro
 	for _, ch := range e.Changes {
 		switch changeKey(ch.Key) {
 		case changeKeyAllow:
-			oldValue, newValue, err := intValues(ch.Old, ch.New)
+			oldValue, newValue, err := permissionValues(ch.Old, ch.New)
 			if err != nil {
 				return nil, err
 			}
-			overwriteUpdate.Allow = &IntValues{Old: oldValue, New: newValue}
+			overwriteUpdate.Allow = &PermissionValues{Old: oldValue, New: newValue}
 
 		case changeKeyDeny:
-			oldValue, newValue, err := intValues(ch.Old, ch.New)
+			oldValue, newValue, err := permissionValues(ch.Old, ch.New)
 			if err != nil {
 				return nil, err
 			}
-			overwriteUpdate.Deny = &IntValues{Old: oldValue, New: newValue}
+			overwriteUpdate.Deny = &PermissionValues{Old: oldValue, New: newValue}
 		}
 	}
 
@@ -234,19 +234,19 @@ func channelOverwriteDeleteFromEntry(e *rawEntry) (*ChannelOverwriteDelete, erro
 			}
 
 		case changeKeyType:
-			overwriteDelete.Type, err = stringValue(ch.Old)
+			overwriteDelete.Type, err = overwriteTypeValue(ch.Old)
 			if err != nil {
 				return nil, err
 			}
 
 		case changeKeyAllow:
-			overwriteDelete.Allow, err = intValue(ch.Old)
+			overwriteDelete.Allow, err = permissionValue(ch.Old)
 			if err != nil {
 				return nil, err
 			}
 
 		case changeKeyDeny:
-			overwriteDelete.Deny, err = intValue(ch.Old)
+			overwriteDelete.Deny, err = permissionValue(ch.Old)
 			if err != nil {
 				return nil, err
 			}
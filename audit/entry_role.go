@@ -15,7 +15,7 @@ func roleCreateFromEntry(e *rawEntry) (*RoleCreate, error) {
 			}
 
 		case changeKeyPermissions:
-			roleCreate.Permissions, err = intValue(ch.New)
+			roleCreate.Permissions, err = permissionValue(ch.New)
 			if err != nil {
 				return nil, err
 			}
@@ -58,11 +58,11 @@ func roleUpdateFromEntry(e *rawEntry) (*RoleUpdate, error) {
 			roleUpdate.Name = &StringValues{Old: oldValue, New: newValue}
 
 		case changeKeyPermissions:
-			oldValue, newValue, err := intValues(ch.Old, ch.New)
+			oldValue, newValue, err := permissionValues(ch.Old, ch.New)
 			if err != nil {
 				return nil, err
 			}
-			roleUpdate.Permissions = &IntValues{Old: oldValue, New: newValue}
+			roleUpdate.Permissions = &PermissionValues{Old: oldValue, New: newValue}
 
 		case changeKeyColor:
 			oldValue, newValue, err := intValues(ch.Old, ch.New)
@@ -105,7 +105,7 @@ func roleDeleteFromEntry(e *rawEntry) (*RoleDelete, error) {
 			}
 
 		case changeKeyPermissions:
-			roleDelete.Permissions, err = intValue(ch.Old)
+			roleDelete.Permissions, err = permissionValue(ch.Old)
 			if err != nil {
 				return nil, err
 			}
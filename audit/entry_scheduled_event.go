@@ -0,0 +1,172 @@
+package audit
+
+func scheduledEventCreateFromEntry(e *rawEntry) (*ScheduledEventCreate, error) {
+	eventCreate := &ScheduledEventCreate{
+		BaseEntry: baseEntryFromRaw(e),
+	}
+
+	var err error
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyName:
+			eventCreate.Name, err = stringValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyDescription:
+			eventCreate.Description, err = stringValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyChannelID:
+			eventCreate.ChannelID, err = stringValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyLocation:
+			eventCreate.Location, err = stringValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyEntityType:
+			eventCreate.EntityType, err = intValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyStatus:
+			eventCreate.Status, err = intValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyPrivacyLevel:
+			eventCreate.PrivacyLevel, err = intValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return eventCreate, nil
+}
+
+func scheduledEventUpdateFromEntry(e *rawEntry) (*ScheduledEventUpdate, error) {
+	eventUpdate := &ScheduledEventUpdate{
+		BaseEntry: baseEntryFromRaw(e),
+	}
+
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyName:
+			oldValue, newValue, err := stringValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			eventUpdate.Name = &StringValues{Old: oldValue, New: newValue}
+
+		case changeKeyDescription:
+			oldValue, newValue, err := stringValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			eventUpdate.Description = &StringValues{Old: oldValue, New: newValue}
+
+		case changeKeyChannelID:
+			oldValue, newValue, err := stringValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			eventUpdate.ChannelID = &StringValues{Old: oldValue, New: newValue}
+
+		case changeKeyLocation:
+			oldValue, newValue, err := stringValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			eventUpdate.Location = &StringValues{Old: oldValue, New: newValue}
+
+		case changeKeyEntityType:
+			oldValue, newValue, err := intValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			eventUpdate.EntityType = &IntValues{Old: oldValue, New: newValue}
+
+		case changeKeyStatus:
+			oldValue, newValue, err := intValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			eventUpdate.Status = &IntValues{Old: oldValue, New: newValue}
+
+		case changeKeyPrivacyLevel:
+			oldValue, newValue, err := intValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			eventUpdate.PrivacyLevel = &IntValues{Old: oldValue, New: newValue}
+		}
+	}
+
+	return eventUpdate, nil
+}
+
+func scheduledEventDeleteFromEntry(e *rawEntry) (*ScheduledEventDelete, error) {
+	eventDelete := &ScheduledEventDelete{
+		BaseEntry: baseEntryFromRaw(e),
+	}
+
+	var err error
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyName:
+			eventDelete.Name, err = stringValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyDescription:
+			eventDelete.Description, err = stringValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyChannelID:
+			eventDelete.ChannelID, err = stringValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyLocation:
+			eventDelete.Location, err = stringValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyEntityType:
+			eventDelete.EntityType, err = intValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyStatus:
+			eventDelete.Status, err = intValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyPrivacyLevel:
+			eventDelete.PrivacyLevel, err = intValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return eventDelete, nil
+}
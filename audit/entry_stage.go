@@ -0,0 +1,80 @@
+package audit
+
+func stageInstanceCreateFromEntry(e *rawEntry) (*StageInstanceCreate, error) {
+	stageCreate := &StageInstanceCreate{
+		BaseEntry: baseEntryFromRaw(e),
+		ChannelID: e.Options.ChannelID,
+	}
+
+	var err error
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyTopic:
+			stageCreate.Topic, err = stringValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyPrivacyLevel:
+			stageCreate.PrivacyLevel, err = intValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return stageCreate, nil
+}
+
+func stageInstanceUpdateFromEntry(e *rawEntry) (*StageInstanceUpdate, error) {
+	stageUpdate := &StageInstanceUpdate{
+		BaseEntry: baseEntryFromRaw(e),
+		ChannelID: e.Options.ChannelID,
+	}
+
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyTopic:
+			oldValue, newValue, err := stringValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			stageUpdate.Topic = &StringValues{Old: oldValue, New: newValue}
+
+		case changeKeyPrivacyLevel:
+			oldValue, newValue, err := intValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			stageUpdate.PrivacyLevel = &IntValues{Old: oldValue, New: newValue}
+		}
+	}
+
+	return stageUpdate, nil
+}
+
+func stageInstanceDeleteFromEntry(e *rawEntry) (*StageInstanceDelete, error) {
+	stageDelete := &StageInstanceDelete{
+		BaseEntry: baseEntryFromRaw(e),
+		ChannelID: e.Options.ChannelID,
+	}
+
+	var err error
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyTopic:
+			stageDelete.Topic, err = stringValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyPrivacyLevel:
+			stageDelete.PrivacyLevel, err = intValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return stageDelete, nil
+}
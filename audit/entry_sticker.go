@@ -0,0 +1,108 @@
+package audit
+
+func stickerCreateFromEntry(e *rawEntry) (*StickerCreate, error) {
+	stickerCreate := &StickerCreate{
+		BaseEntry: baseEntryFromRaw(e),
+	}
+
+	var err error
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyName:
+			stickerCreate.Name, err = stringValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyDescription:
+			stickerCreate.Description, err = stringValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyTags:
+			stickerCreate.Tags, err = stringValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyFormatType:
+			stickerCreate.FormatType, err = intValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return stickerCreate, nil
+}
+
+func stickerUpdateFromEntry(e *rawEntry) (*StickerUpdate, error) {
+	stickerUpdate := &StickerUpdate{
+		BaseEntry: baseEntryFromRaw(e),
+	}
+
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyName:
+			oldValue, newValue, err := stringValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			stickerUpdate.Name = &StringValues{Old: oldValue, New: newValue}
+
+		case changeKeyDescription:
+			oldValue, newValue, err := stringValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			stickerUpdate.Description = &StringValues{Old: oldValue, New: newValue}
+
+		case changeKeyTags:
+			oldValue, newValue, err := stringValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			stickerUpdate.Tags = &StringValues{Old: oldValue, New: newValue}
+		}
+	}
+
+	return stickerUpdate, nil
+}
+
+func stickerDeleteFromEntry(e *rawEntry) (*StickerDelete, error) {
+	stickerDelete := &StickerDelete{
+		BaseEntry: baseEntryFromRaw(e),
+	}
+
+	var err error
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyName:
+			stickerDelete.Name, err = stringValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyDescription:
+			stickerDelete.Description, err = stringValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyTags:
+			stickerDelete.Tags, err = stringValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyFormatType:
+			stickerDelete.FormatType, err = intValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return stickerDelete, nil
+}
@@ -0,0 +1,165 @@
+package audit
+
+func threadCreateFromEntry(e *rawEntry) (*ThreadCreate, error) {
+	threadCreate := &ThreadCreate{
+		BaseEntry: baseEntryFromRaw(e),
+	}
+
+	var err error
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyName:
+			threadCreate.Name, err = stringValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyType:
+			threadCreate.Type, err = intValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyArchived:
+			threadCreate.Archived, err = boolValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyLocked:
+			threadCreate.Locked, err = boolValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyInvitable:
+			threadCreate.Invitable, err = boolValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyAutoArchiveDuration:
+			threadCreate.AutoArchiveDuration, err = intValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyRateLimitPerUser:
+			threadCreate.RateLimitPerUser, err = intValue(ch.New)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return threadCreate, nil
+}
+
+func threadUpdateFromEntry(e *rawEntry) (*ThreadUpdate, error) {
+	threadUpdate := &ThreadUpdate{
+		BaseEntry: baseEntryFromRaw(e),
+	}
+
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyName:
+			oldValue, newValue, err := stringValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			threadUpdate.Name = &StringValues{Old: oldValue, New: newValue}
+
+		case changeKeyArchived:
+			oldValue, newValue, err := boolValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			threadUpdate.Archived = &BoolValues{Old: oldValue, New: newValue}
+
+		case changeKeyLocked:
+			oldValue, newValue, err := boolValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			threadUpdate.Locked = &BoolValues{Old: oldValue, New: newValue}
+
+		case changeKeyInvitable:
+			oldValue, newValue, err := boolValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			threadUpdate.Invitable = &BoolValues{Old: oldValue, New: newValue}
+
+		case changeKeyAutoArchiveDuration:
+			oldValue, newValue, err := intValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			threadUpdate.AutoArchiveDuration = &IntValues{Old: oldValue, New: newValue}
+
+		case changeKeyRateLimitPerUser:
+			oldValue, newValue, err := intValues(ch.Old, ch.New)
+			if err != nil {
+				return nil, err
+			}
+			threadUpdate.RateLimitPerUser = &IntValues{Old: oldValue, New: newValue}
+		}
+	}
+
+	return threadUpdate, nil
+}
+
+func threadDeleteFromEntry(e *rawEntry) (*ThreadDelete, error) {
+	threadDelete := &ThreadDelete{
+		BaseEntry: baseEntryFromRaw(e),
+	}
+
+	var err error
+	for _, ch := range e.Changes {
+		switch changeKey(ch.Key) {
+		case changeKeyName:
+			threadDelete.Name, err = stringValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyType:
+			threadDelete.Type, err = intValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyArchived:
+			threadDelete.Archived, err = boolValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyLocked:
+			threadDelete.Locked, err = boolValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyInvitable:
+			threadDelete.Invitable, err = boolValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyAutoArchiveDuration:
+			threadDelete.AutoArchiveDuration, err = intValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+
+		case changeKeyRateLimitPerUser:
+			threadDelete.RateLimitPerUser, err = intValue(ch.Old)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return threadDelete, nil
+}
@@ -36,6 +36,28 @@ const (
 	EntryTypeMessageDelete          EntryType = 72
 )
 
+// Entry types of newer actions.
+const (
+	EntryTypeStageInstanceCreate                     EntryType = 83
+	EntryTypeStageInstanceUpdate                     EntryType = 84
+	EntryTypeStageInstanceDelete                     EntryType = 85
+	EntryTypeStickerCreate                           EntryType = 90
+	EntryTypeStickerUpdate                           EntryType = 91
+	EntryTypeStickerDelete                           EntryType = 92
+	EntryTypeScheduledEventCreate                    EntryType = 100
+	EntryTypeScheduledEventUpdate                    EntryType = 101
+	EntryTypeScheduledEventDelete                    EntryType = 102
+	EntryTypeThreadCreate                            EntryType = 110
+	EntryTypeThreadUpdate                            EntryType = 111
+	EntryTypeThreadDelete                            EntryType = 112
+	EntryTypeAutoModerationRuleCreate                EntryType = 140
+	EntryTypeAutoModerationRuleUpdate                EntryType = 141
+	EntryTypeAutoModerationRuleDelete                EntryType = 142
+	EntryTypeAutoModerationBlockMessage              EntryType = 143
+	EntryTypeAutoModerationFlagToChannel             EntryType = 144
+	EntryTypeAutoModerationUserCommunicationDisabled EntryType = 145
+)
+
 // GuildUpdate is the audit log entry that describes how a guild was updated.
 // It contains a list of settings that can be updated on a guild.
 // Settings that are not nil are those which were modified. They contain both
@@ -119,8 +141,8 @@ type ChannelOverwriteCreate struct {
 
 	Type  string
 	ID    string
-	Allow int
-	Deny  int
+	Allow uint64
+	Deny  uint64
 
 	RoleName string // Name of the role if Type is "role".
 }
@@ -135,8 +157,8 @@ func (ChannelOverwriteCreate) EntryType() EntryType { return EntryTypeChannelOve
 type ChannelOverwriteUpdate struct {
 	BaseEntry
 
-	Allow *IntValues
-	Deny  *IntValues
+	Allow *PermissionValues
+	Deny  *PermissionValues
 
 	Type     string
 	ID       string
@@ -153,8 +175,8 @@ type ChannelOverwriteDelete struct {
 
 	Type  string
 	ID    string
-	Allow int
-	Deny  int
+	Allow uint64
+	Deny  uint64
 
 	RoleName string // Name of the role if Type is "role".
 }
@@ -230,7 +252,7 @@ type RoleCreate struct {
 	BaseEntry
 
 	Name        string
-	Permissions int
+	Permissions uint64
 	Color       int
 	Mentionable bool
 	Hoist       bool
@@ -247,7 +269,7 @@ type RoleUpdate struct {
 	BaseEntry
 
 	Name        *StringValues
-	Permissions *IntValues
+	Permissions *PermissionValues
 	Color       *IntValues
 	Mentionable *BoolValues
 	Hoist       *BoolValues
@@ -262,7 +284,7 @@ type RoleDelete struct {
 	BaseEntry
 
 	Name        string
-	Permissions int
+	Permissions uint64
 	Color       int
 	Mentionable bool
 	Hoist       bool
@@ -410,3 +432,282 @@ type MessageDelete struct {
 
 // EntryType implements the LogEntry interface.
 func (MessageDelete) EntryType() EntryType { return EntryTypeMessageDelete }
+
+// StageInstanceCreate is the audit log entry that describes a stage instance creation.
+// It contains the settings the stage instance was created with.
+type StageInstanceCreate struct {
+	BaseEntry
+
+	ChannelID    string
+	Topic        string
+	PrivacyLevel int
+}
+
+// EntryType implements the LogEntry interface.
+func (StageInstanceCreate) EntryType() EntryType { return EntryTypeStageInstanceCreate }
+
+// StageInstanceUpdate is the audit log entry that describes how a stage instance was updated.
+// Settings that are not nil are those which were modified. They contain both
+// their old value as well as the new one.
+type StageInstanceUpdate struct {
+	BaseEntry
+
+	ChannelID    string
+	Topic        *StringValues
+	PrivacyLevel *IntValues
+}
+
+// EntryType implements the LogEntry interface.
+func (StageInstanceUpdate) EntryType() EntryType { return EntryTypeStageInstanceUpdate }
+
+// StageInstanceDelete is the audit log entry that describes a stage instance deletion.
+// It contains settings this stage instance had before being deleted.
+type StageInstanceDelete struct {
+	BaseEntry
+
+	ChannelID    string
+	Topic        string
+	PrivacyLevel int
+}
+
+// EntryType implements the LogEntry interface.
+func (StageInstanceDelete) EntryType() EntryType { return EntryTypeStageInstanceDelete }
+
+// StickerCreate is the audit log entry that describes a sticker creation.
+// It contains the settings the sticker was created with.
+type StickerCreate struct {
+	BaseEntry
+
+	Name        string
+	Description string
+	Tags        string
+	FormatType  int
+}
+
+// EntryType implements the LogEntry interface.
+func (StickerCreate) EntryType() EntryType { return EntryTypeStickerCreate }
+
+// StickerUpdate is the audit log entry that describes how a sticker was updated.
+// Settings that are not nil are those which were modified. They contain both
+// their old value as well as the new one.
+type StickerUpdate struct {
+	BaseEntry
+
+	Name        *StringValues
+	Description *StringValues
+	Tags        *StringValues
+}
+
+// EntryType implements the LogEntry interface.
+func (StickerUpdate) EntryType() EntryType { return EntryTypeStickerUpdate }
+
+// StickerDelete is the audit log entry that describes a sticker deletion.
+// It contains settings this sticker had before being deleted.
+type StickerDelete struct {
+	BaseEntry
+
+	Name        string
+	Description string
+	Tags        string
+	FormatType  int
+}
+
+// EntryType implements the LogEntry interface.
+func (StickerDelete) EntryType() EntryType { return EntryTypeStickerDelete }
+
+// ScheduledEventCreate is the audit log entry that describes a scheduled event creation.
+// It contains the settings the scheduled event was created with.
+type ScheduledEventCreate struct {
+	BaseEntry
+
+	Name         string
+	Description  string
+	ChannelID    string
+	Location     string
+	EntityType   int
+	Status       int
+	PrivacyLevel int
+}
+
+// EntryType implements the LogEntry interface.
+func (ScheduledEventCreate) EntryType() EntryType { return EntryTypeScheduledEventCreate }
+
+// ScheduledEventUpdate is the audit log entry that describes how a scheduled event was updated.
+// Settings that are not nil are those which were modified. They contain both
+// their old value as well as the new one.
+type ScheduledEventUpdate struct {
+	BaseEntry
+
+	Name         *StringValues
+	Description  *StringValues
+	ChannelID    *StringValues
+	Location     *StringValues
+	EntityType   *IntValues
+	Status       *IntValues
+	PrivacyLevel *IntValues
+}
+
+// EntryType implements the LogEntry interface.
+func (ScheduledEventUpdate) EntryType() EntryType { return EntryTypeScheduledEventUpdate }
+
+// ScheduledEventDelete is the audit log entry that describes a scheduled event deletion.
+// It contains settings this scheduled event had before being deleted.
+type ScheduledEventDelete struct {
+	BaseEntry
+
+	Name         string
+	Description  string
+	ChannelID    string
+	Location     string
+	EntityType   int
+	Status       int
+	PrivacyLevel int
+}
+
+// EntryType implements the LogEntry interface.
+func (ScheduledEventDelete) EntryType() EntryType { return EntryTypeScheduledEventDelete }
+
+// ThreadCreate is the audit log entry that describes a thread creation.
+// It contains the settings the thread was created with.
+type ThreadCreate struct {
+	BaseEntry
+
+	Name                string
+	Type                int
+	Archived            bool
+	Locked              bool
+	Invitable           bool
+	AutoArchiveDuration int
+	RateLimitPerUser    int
+}
+
+// EntryType implements the LogEntry interface.
+func (ThreadCreate) EntryType() EntryType { return EntryTypeThreadCreate }
+
+// ThreadUpdate is the audit log entry that describes how a thread was updated.
+// Settings that are not nil are those which were modified. They contain both
+// their old value as well as the new one.
+type ThreadUpdate struct {
+	BaseEntry
+
+	Name                *StringValues
+	Archived            *BoolValues
+	Locked              *BoolValues
+	Invitable           *BoolValues
+	AutoArchiveDuration *IntValues
+	RateLimitPerUser    *IntValues
+}
+
+// EntryType implements the LogEntry interface.
+func (ThreadUpdate) EntryType() EntryType { return EntryTypeThreadUpdate }
+
+// ThreadDelete is the audit log entry that describes a thread deletion.
+// It contains settings this thread had before being deleted.
+type ThreadDelete struct {
+	BaseEntry
+
+	Name                string
+	Type                int
+	Archived            bool
+	Locked              bool
+	Invitable           bool
+	AutoArchiveDuration int
+	RateLimitPerUser    int
+}
+
+// EntryType implements the LogEntry interface.
+func (ThreadDelete) EntryType() EntryType { return EntryTypeThreadDelete }
+
+// AutoModerationRuleCreate is the audit log entry that describes an auto moderation rule creation.
+// It contains the settings the rule was created with.
+type AutoModerationRuleCreate struct {
+	BaseEntry
+
+	Name        string
+	EventType   int
+	TriggerType int
+	Enabled     bool
+}
+
+// EntryType implements the LogEntry interface.
+func (AutoModerationRuleCreate) EntryType() EntryType { return EntryTypeAutoModerationRuleCreate }
+
+// AutoModerationRuleUpdate is the audit log entry that describes how an auto moderation rule was updated.
+// Settings that are not nil are those which were modified. They contain both
+// their old value as well as the new one.
+type AutoModerationRuleUpdate struct {
+	BaseEntry
+
+	Name      *StringValues
+	EventType *IntValues
+	Enabled   *BoolValues
+}
+
+// EntryType implements the LogEntry interface.
+func (AutoModerationRuleUpdate) EntryType() EntryType { return EntryTypeAutoModerationRuleUpdate }
+
+// AutoModerationRuleDelete is the audit log entry that describes an auto moderation rule deletion.
+// It contains settings this rule had before being deleted.
+type AutoModerationRuleDelete struct {
+	BaseEntry
+
+	Name        string
+	EventType   int
+	TriggerType int
+	Enabled     bool
+}
+
+// EntryType implements the LogEntry interface.
+func (AutoModerationRuleDelete) EntryType() EntryType { return EntryTypeAutoModerationRuleDelete }
+
+// AutoModerationAction holds the details shared by audit log entries of actions
+// taken by auto moderation. UserID is the ID of the user whose message or
+// behavior triggered the rule.
+type AutoModerationAction struct {
+	BaseEntry
+
+	ChannelID       string // ID of the channel in which the rule was triggered.
+	RuleName        string
+	RuleTriggerType int
+}
+
+// AutoModerationBlockMessage is the audit log entry that describes a message
+// blocked by auto moderation.
+type AutoModerationBlockMessage struct {
+	AutoModerationAction
+}
+
+// EntryType implements the LogEntry interface.
+func (AutoModerationBlockMessage) EntryType() EntryType { return EntryTypeAutoModerationBlockMessage }
+
+// AutoModerationFlagToChannel is the audit log entry that describes a message
+// flagged by auto moderation.
+type AutoModerationFlagToChannel struct {
+	AutoModerationAction
+}
+
+// EntryType implements the LogEntry interface.
+func (AutoModerationFlagToChannel) EntryType() EntryType {
+	return EntryTypeAutoModerationFlagToChannel
+}
+
+// AutoModerationUserCommunicationDisabled is the audit log entry that describes
+// a member timed out by auto moderation.
+type AutoModerationUserCommunicationDisabled struct {
+	AutoModerationAction
+}
+
+// EntryType implements the LogEntry interface.
+func (AutoModerationUserCommunicationDisabled) EntryType() EntryType {
+	return EntryTypeAutoModerationUserCommunicationDisabled
+}
+
+// UnknownEntry is the audit log entry of actions that are not supported yet.
+type UnknownEntry struct {
+	BaseEntry
+
+	Type EntryType
+}
+
+// EntryType implements the LogEntry interface.
+func (e UnknownEntry) EntryType() EntryType { return e.Type }
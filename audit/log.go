@@ -12,6 +12,7 @@ type Log struct {
 // Entries are defined by the EntryType they describe.
 type LogEntry interface {
 	EntryType() EntryType
+	EntryID() string
 }
 
 // BaseEntry contains the shared fields of every log entries.
@@ -22,6 +23,9 @@ type BaseEntry struct {
 	Reason   string // Reason why this action was performed.
 }
 
+// EntryID returns the ID of the entry.
+func (e BaseEntry) EntryID() string { return e.ID }
+
 // PartialUser contains a subset of the regular harmony.User type.
 type PartialUser struct {
 	ID            string `json:"id,omitempty"`
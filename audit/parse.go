@@ -30,8 +30,12 @@ type rawEntry struct {
 
 		// CHANNEL_OVERWRITE_* actions.
 		ID       string `json:"id"`        // ID of the overwritten entity.
-		Type     string `json:"type"`      // Type of the overwritten entity ("member" or "role").
+		Type     string `json:"type"`      // Type of the overwritten entity ("0" for roles or "1" for members).
 		RoleName string `json:"role_name"` // Name of the role if Type is "role".
+
+		// AUTO_MODERATION_* actions.
+		RuleName        string `json:"auto_moderation_rule_name"`         // Name of the triggered rule.
+		RuleTriggerType string `json:"auto_moderation_rule_trigger_type"` // Trigger type of the triggered rule.
 	} `json:"options"`
 }
 
@@ -142,6 +146,69 @@ func ParseRaw(raw json.RawMessage) (*Log, error) {
 
 		case EntryTypeMessageDelete:
 			entry, err = messageDeleteFromEntry(&e)
+
+		case EntryTypeStageInstanceCreate:
+			entry, err = stageInstanceCreateFromEntry(&e)
+
+		case EntryTypeStageInstanceUpdate:
+			entry, err = stageInstanceUpdateFromEntry(&e)
+
+		case EntryTypeStageInstanceDelete:
+			entry, err = stageInstanceDeleteFromEntry(&e)
+
+		case EntryTypeStickerCreate:
+			entry, err = stickerCreateFromEntry(&e)
+
+		case EntryTypeStickerUpdate:
+			entry, err = stickerUpdateFromEntry(&e)
+
+		case EntryTypeStickerDelete:
+			entry, err = stickerDeleteFromEntry(&e)
+
+		case EntryTypeScheduledEventCreate:
+			entry, err = scheduledEventCreateFromEntry(&e)
+
+		case EntryTypeScheduledEventUpdate:
+			entry, err = scheduledEventUpdateFromEntry(&e)
+
+		case EntryTypeScheduledEventDelete:
+			entry, err = scheduledEventDeleteFromEntry(&e)
+
+		case EntryTypeThreadCreate:
+			entry, err = threadCreateFromEntry(&e)
+
+		case EntryTypeThreadUpdate:
+			entry, err = threadUpdateFromEntry(&e)
+
+		case EntryTypeThreadDelete:
+			entry, err = threadDeleteFromEntry(&e)
+
+		case EntryTypeAutoModerationRuleCreate:
+			entry, err = autoModerationRuleCreateFromEntry(&e)
+
+		case EntryTypeAutoModerationRuleUpdate:
+			entry, err = autoModerationRuleUpdateFromEntry(&e)
+
+		case EntryTypeAutoModerationRuleDelete:
+			entry, err = autoModerationRuleDeleteFromEntry(&e)
+
+		case EntryTypeAutoModerationBlockMessage:
+			var a AutoModerationAction
+			a, err = autoModerationActionFromEntry(&e)
+			entry = &AutoModerationBlockMessage{AutoModerationAction: a}
+
+		case EntryTypeAutoModerationFlagToChannel:
+			var a AutoModerationAction
+			a, err = autoModerationActionFromEntry(&e)
+			entry = &AutoModerationFlagToChannel{AutoModerationAction: a}
+
+		case EntryTypeAutoModerationUserCommunicationDisabled:
+			var a AutoModerationAction
+			a, err = autoModerationActionFromEntry(&e)
+			entry = &AutoModerationUserCommunicationDisabled{AutoModerationAction: a}
+
+		default:
+			entry = &UnknownEntry{BaseEntry: baseEntryFromRaw(&e), Type: EntryType(e.ActionType)}
 		}
 
 		if err != nil {
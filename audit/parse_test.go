@@ -0,0 +1,172 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseRaw(t *testing.T) {
+	base := BaseEntry{ID: "1", UserID: "2", TargetID: "3", Reason: "reason"}
+
+	tests := []struct {
+		name     string
+		entry    string
+		expected LogEntry
+	}{
+		{
+			name: "stage instance create",
+			entry: `{"action_type":83,"options":{"channel_id":"4"},"changes":[
+				{"key":"topic","new_value":"Town hall"},
+				{"key":"privacy_level","new_value":2}]}`,
+			expected: &StageInstanceCreate{BaseEntry: base, ChannelID: "4", Topic: "Town hall", PrivacyLevel: 2},
+		},
+		{
+			name: "sticker update",
+			entry: `{"action_type":91,"changes":[
+				{"key":"name","old_value":"wave","new_value":"hello"},
+				{"key":"tags","old_value":"👋","new_value":"🙂"}]}`,
+			expected: &StickerUpdate{
+				BaseEntry: base,
+				Name:      &StringValues{Old: "wave", New: "hello"},
+				Tags:      &StringValues{Old: "👋", New: "🙂"},
+			},
+		},
+		{
+			name: "scheduled event create",
+			entry: `{"action_type":100,"changes":[
+				{"key":"name","new_value":"Game night"},
+				{"key":"channel_id","new_value":"4"},
+				{"key":"entity_type","new_value":2},
+				{"key":"status","new_value":1},
+				{"key":"privacy_level","new_value":2}]}`,
+			expected: &ScheduledEventCreate{
+				BaseEntry:    base,
+				Name:         "Game night",
+				ChannelID:    "4",
+				EntityType:   2,
+				Status:       1,
+				PrivacyLevel: 2,
+			},
+		},
+		{
+			name: "thread create",
+			entry: `{"action_type":110,"changes":[
+				{"key":"name","new_value":"help"},
+				{"key":"type","new_value":11},
+				{"key":"locked","new_value":true},
+				{"key":"auto_archive_duration","new_value":1440}]}`,
+			expected: &ThreadCreate{BaseEntry: base, Name: "help", Type: 11, Locked: true, AutoArchiveDuration: 1440},
+		},
+		{
+			name: "thread update",
+			entry: `{"action_type":111,"changes":[
+				{"key":"archived","old_value":false,"new_value":true},
+				{"key":"rate_limit_per_user","old_value":0,"new_value":10}]}`,
+			expected: &ThreadUpdate{
+				BaseEntry:        base,
+				Archived:         &BoolValues{Old: false, New: true},
+				RateLimitPerUser: &IntValues{Old: 0, New: 10},
+			},
+		},
+		{
+			name: "thread delete",
+			entry: `{"action_type":112,"changes":[
+				{"key":"name","old_value":"help"},
+				{"key":"archived","old_value":true}]}`,
+			expected: &ThreadDelete{BaseEntry: base, Name: "help", Archived: true},
+		},
+		{
+			name: "auto moderation rule update",
+			entry: `{"action_type":141,"changes":[
+				{"key":"name","old_value":"spam","new_value":"links"},
+				{"key":"enabled","old_value":true,"new_value":false}]}`,
+			expected: &AutoModerationRuleUpdate{
+				BaseEntry: base,
+				Name:      &StringValues{Old: "spam", New: "links"},
+				Enabled:   &BoolValues{Old: true, New: false},
+			},
+		},
+		{
+			name: "auto moderation block message",
+			entry: `{"action_type":143,"options":{"channel_id":"4",
+				"auto_moderation_rule_name":"links","auto_moderation_rule_trigger_type":"1"}}`,
+			expected: &AutoModerationBlockMessage{AutoModerationAction: AutoModerationAction{
+				BaseEntry:       base,
+				ChannelID:       "4",
+				RuleName:        "links",
+				RuleTriggerType: 1,
+			}},
+		},
+		{
+			name:  "auto moderation user communication disabled",
+			entry: `{"action_type":145,"options":{"auto_moderation_rule_name":"spam"}}`,
+			expected: &AutoModerationUserCommunicationDisabled{AutoModerationAction: AutoModerationAction{
+				BaseEntry: base,
+				RuleName:  "spam",
+			}},
+		},
+		{
+			name:     "unknown",
+			entry:    `{"action_type":999,"changes":[{"key":"name","new_value":"x"}]}`,
+			expected: &UnknownEntry{BaseEntry: base, Type: 999},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, err := ParseRaw(rawLog(t, tt.entry))
+			if err != nil {
+				t.Fatalf("could not parse audit log: %v", err)
+			}
+			if len(log.Entries) != 1 {
+				t.Fatalf("expected a single entry, got %d", len(log.Entries))
+			}
+
+			entry := log.Entries[0]
+			if !reflect.DeepEqual(entry, tt.expected) {
+				t.Errorf("expected entry to be %+v, got %+v", tt.expected, entry)
+			}
+			if entry.EntryType() != tt.expected.EntryType() || entry.EntryID() != "1" {
+				t.Errorf("expected entry %s of type %d, got entry %s of type %d",
+					"1", tt.expected.EntryType(), entry.EntryID(), entry.EntryType())
+			}
+		})
+	}
+}
+
+func TestParseRawInvalidValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry string
+	}{
+		{name: "thread create", entry: `{"action_type":110,"changes":[{"key":"name","new_value":1}]}`},
+		{name: "thread update", entry: `{"action_type":111,"changes":[{"key":"locked","old_value":"no","new_value":true}]}`},
+		{name: "stage instance delete", entry: `{"action_type":85,"changes":[{"key":"privacy_level","old_value":"2"}]}`},
+		{name: "auto moderation action", entry: `{"action_type":144,"options":{"auto_moderation_rule_trigger_type":"keyword"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRaw(rawLog(t, tt.entry)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// rawLog returns a raw audit log holding the given entry, along
+// with the ID, user ID, target ID and reason shared by all tests.
+func rawLog(t *testing.T, entry string) json.RawMessage {
+	var e map[string]interface{}
+	if err := json.Unmarshal([]byte(entry), &e); err != nil {
+		t.Fatalf("invalid entry: %v", err)
+	}
+	e["id"], e["user_id"], e["target_id"], e["reason"] = "1", "2", "3", "reason"
+
+	b, err := json.Marshal(map[string]interface{}{"audit_log_entries": []interface{}{e}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
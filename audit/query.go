@@ -0,0 +1,62 @@
+package audit
+
+// Query is a query to the audit log of a guild. All fields are optional.
+type Query struct {
+	// UserID filters entries for actions performed by this user.
+	UserID string
+	// ActionType filters entries of this type.
+	ActionType EntryType
+	// Before filters entries that are older than this entry ID.
+	Before string
+	// Limit is the maximum number of entries to return,
+	// between 1 and 100. Defaults to 50.
+	Limit int
+}
+
+// QueryOption is a function that configures a query to the audit log.
+type QueryOption func(*Query)
+
+// NewQuery returns a new query to the audit log.
+func NewQuery(opts ...QueryOption) *Query {
+	q := &Query{}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	return q
+}
+
+// WithUserID makes the query only return entries for actions
+// performed by the given user.
+func WithUserID(id string) QueryOption {
+	return func(q *Query) {
+		q.UserID = id
+	}
+}
+
+// WithActionType makes the query only return entries of the given type.
+func WithActionType(typ EntryType) QueryOption {
+	return func(q *Query) {
+		q.ActionType = typ
+	}
+}
+
+// WithBefore makes the query only return entries older than the given entry.
+// It is used to paginate the audit log, the given ID being the one of the
+// last entry of the previous query.
+func WithBefore(id string) QueryOption {
+	return func(q *Query) {
+		q.Before = id
+	}
+}
+
+// WithLimit sets the maximum number of entries the query returns.
+// It must be between 1 and 100 and defaults to 50 if not specified.
+// When iterating over the audit log, it is the number of entries
+// fetched per request.
+func WithLimit(limit int) QueryOption {
+	return func(q *Query) {
+		q.Limit = limit
+	}
+}
@@ -3,6 +3,7 @@ package audit
 import (
 	"bytes"
 	"encoding/json"
+	"strconv"
 
 	"github.com/skwair/harmony/permission"
 )
@@ -17,6 +18,11 @@ type IntValues struct {
 	Old, New int
 }
 
+// PermissionValues holds a pair of permission bit sets.
+type PermissionValues struct {
+	Old, New uint64
+}
+
 // BoolValues holds a pair of boolean values.
 type BoolValues struct {
 	Old, New bool
@@ -78,6 +84,63 @@ func intValue(val json.RawMessage) (int, error) {
 	return i, nil
 }
 
+func permissionValues(oldValue, newValue json.RawMessage) (old uint64, new uint64, err error) {
+	if old, err = permissionValue(oldValue); err != nil {
+		return 0, 0, err
+	}
+	if new, err = permissionValue(newValue); err != nil {
+		return 0, 0, err
+	}
+	return old, new, nil
+}
+
+// permissionValue decodes a permission bit set, which is sent as a
+// string since it does not fit in 53 bits, or as a number in old entries.
+func permissionValue(val json.RawMessage) (uint64, error) {
+	if len(val) == 0 {
+		return 0, nil
+	}
+
+	var p uint64
+	if val[0] != '"' {
+		err := json.Unmarshal(val, &p)
+		return p, err
+	}
+
+	s, err := stringValue(val)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// overwriteTypeValue decodes the type of a permission overwrite, sent as 0
+// for roles and 1 for members, and returns either "role" or "member".
+func overwriteTypeValue(val json.RawMessage) (string, error) {
+	if len(val) == 0 {
+		return "", nil
+	}
+	if val[0] == '"' {
+		s, err := stringValue(val)
+		return overwriteType(s), err
+	}
+
+	i, err := intValue(val)
+	return overwriteType(strconv.Itoa(i)), err
+}
+
+// overwriteType returns the type of a permission overwrite, either "role"
+// or "member", given its type as sent by Discord.
+func overwriteType(typ string) string {
+	switch typ {
+	case "0":
+		return "role"
+	case "1":
+		return "member"
+	}
+	return typ
+}
+
 func boolValues(oldValue, newValue json.RawMessage) (old bool, new bool, err error) {
 	if len(oldValue) != 0 {
 		if err = json.Unmarshal(oldValue, &old); err != nil {
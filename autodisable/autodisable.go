@@ -0,0 +1,262 @@
+/*
+Package autodisable disables the components of messages once they expire, so
+users are not left with buttons and select menus nobody handles anymore:
+
+	t := autodisable.New(client)
+	defer t.Close()
+
+	msg, err := client.Channel(channelID).Send(ctx, harmony.WithComponents(rows...))
+	if err != nil {
+		// Handle error.
+	}
+	t.Track(msg, 5*time.Minute)
+
+	// Later, in the handler of a component that ends the interaction:
+	err = t.Disable(ctx, i.Message.ID)
+
+Messages sent in response to interactions, including ephemeral ones, are tracked
+with TrackResponse. Tracked messages are kept in memory: their components are
+disabled when the tracker is closed, but are lost if the program crashes.
+*/
+package autodisable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/clock"
+	"github.com/skwair/harmony/component"
+)
+
+const (
+	// requestTimeout is the time allowed to disable the components of a message.
+	requestTimeout = 10 * time.Second
+	// MaxResponseTimeout is the maximum timeout of messages tracked with
+	// TrackResponse, since interaction tokens are only valid for 15 minutes.
+	MaxResponseTimeout = 14 * time.Minute
+)
+
+// ErrNotTracked is returned by Disable when the message is not tracked,
+// for instance because its components were already disabled.
+var ErrNotTracked = errors.New("autodisable: message is not tracked")
+
+// Tracker disables the components of tracked messages once they expire, or
+// once the context of its client is done, see harmony.WithContext. It is
+// safe for concurrent use. Create one with New.
+type Tracker struct {
+	client  *harmony.Client
+	clock   clock.Clock
+	onError func(error)
+
+	mu       sync.Mutex
+	messages map[string]*entry // By message ID.
+
+	wg        sync.WaitGroup
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+type entry struct {
+	channelID  string
+	components []component.ActionRow
+	// Set for messages sent in response to interactions.
+	interaction *harmony.Interaction
+	cancel      chan struct{}
+}
+
+// Option is a function that configures a Tracker.
+type Option func(*Tracker)
+
+// WithErrorHandler sets the function called when the components of an expired
+// message can not be disabled. Errors are ignored by default.
+func WithErrorHandler(f func(err error)) Option {
+	return func(t *Tracker) {
+		t.onError = f
+	}
+}
+
+// WithClock sets the clock used by the tracker, mainly for testing purposes.
+// Defaults to the clock of the client.
+func WithClock(c clock.Clock) Option {
+	return func(t *Tracker) {
+		t.clock = c
+	}
+}
+
+// New returns a new Tracker disabling components through the given client.
+func New(c *harmony.Client, opts ...Option) *Tracker {
+	t := &Tracker{
+		client:   c,
+		clock:    c.Clock(),
+		onError:  func(error) {},
+		messages: make(map[string]*entry),
+		closed:   make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if done := c.Context().Done(); done != nil {
+		go t.closeOnDone(done)
+	}
+
+	return t
+}
+
+// Track disables the components of the given message once the given timeout
+// expires. Tracking a message again, for instance after editing its components,
+// replaces its components and timeout.
+func (t *Tracker) Track(msg *harmony.Message, timeout time.Duration) {
+	t.track(msg, nil, timeout)
+}
+
+// TrackResponse is like Track for a message sent in response to the given
+// interaction, such as its response or a follow-up message. Those messages are
+// edited with the token of the interaction, so the timeout can not be longer
+// than MaxResponseTimeout.
+func (t *Tracker) TrackResponse(i *harmony.Interaction, msg *harmony.Message, timeout time.Duration) {
+	if timeout > MaxResponseTimeout {
+		timeout = MaxResponseTimeout
+	}
+	t.track(msg, i, timeout)
+}
+
+func (t *Tracker) track(msg *harmony.Message, i *harmony.Interaction, timeout time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	select {
+	case <-t.closed:
+		return
+	default:
+	}
+
+	if previous, ok := t.messages[msg.ID]; ok {
+		close(previous.cancel)
+	}
+
+	e := &entry{
+		channelID:   msg.ChannelID,
+		components:  msg.Components,
+		interaction: i,
+		cancel:      make(chan struct{}),
+	}
+	t.messages[msg.ID] = e
+
+	t.wg.Add(1)
+	go t.run(msg.ID, e, timeout)
+}
+
+// Disable disables the components of the given tracked message right away, for
+// instance after an interaction that ends the conversation with the user. It
+// returns ErrNotTracked if the message is not tracked.
+func (t *Tracker) Disable(ctx context.Context, messageID string) error {
+	t.mu.Lock()
+	e, ok := t.messages[messageID]
+	if ok {
+		delete(t.messages, messageID)
+		close(e.cancel)
+	}
+	t.mu.Unlock()
+
+	if !ok {
+		return ErrNotTracked
+	}
+	return t.disable(ctx, messageID, e)
+}
+
+// Forget stops tracking the given message without disabling its components,
+// for instance because it was deleted. It returns false if the message is
+// not tracked.
+func (t *Tracker) Forget(messageID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.messages[messageID]
+	if !ok {
+		return false
+	}
+	delete(t.messages, messageID)
+	close(e.cancel)
+	return true
+}
+
+// Tracked returns whether the given message is tracked.
+func (t *Tracker) Tracked(messageID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, ok := t.messages[messageID]
+	return ok
+}
+
+// Close disables the components of all tracked messages
+// and waits for them to be disabled.
+func (t *Tracker) Close() {
+	t.close()
+	t.wg.Wait()
+}
+
+// close makes all tracked messages expire without waiting
+// for their components to be disabled.
+func (t *Tracker) close() {
+	t.closeOnce.Do(func() {
+		t.mu.Lock()
+		close(t.closed)
+		t.mu.Unlock()
+	})
+}
+
+// closeOnDone closes the tracker once done is closed.
+func (t *Tracker) closeOnDone(done <-chan struct{}) {
+	select {
+	case <-done:
+		t.close()
+	case <-t.closed:
+	}
+}
+
+func (t *Tracker) run(messageID string, e *entry, timeout time.Duration) {
+	defer t.wg.Done()
+
+	select {
+	case <-t.clock.After(timeout):
+	case <-t.closed:
+	case <-e.cancel:
+		return
+	}
+
+	t.mu.Lock()
+	if t.messages[messageID] != e {
+		// Disabled or forgotten in the meantime.
+		t.mu.Unlock()
+		return
+	}
+	delete(t.messages, messageID)
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if err := t.disable(ctx, messageID, e); err != nil {
+		t.onError(fmt.Errorf("autodisable: could not disable components of message %s: %w", messageID, err))
+	}
+}
+
+// disable edits the given message to disable its components.
+func (t *Tracker) disable(ctx context.Context, messageID string, e *entry) error {
+	rows := component.Disable(e.components)
+
+	var err error
+	if e.interaction != nil {
+		_, err = t.client.Interaction(e.interaction).EditFollowUp(ctx, messageID, harmony.WithComponents(rows...))
+	} else {
+		_, err = t.client.Channel(e.channelID).Edit(ctx, messageID, harmony.WithComponents(rows...))
+	}
+	return err
+}
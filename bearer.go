@@ -0,0 +1,107 @@
+package harmony
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/skwair/harmony/internal/endpoint"
+)
+
+// OAuth2 scopes checked by the client before calling
+// endpoints that require a bearer token.
+const (
+	ScopeIdentify          = "identify"
+	ScopeGuilds            = "guilds"
+	ScopeGuildsMembersRead = "guilds.members.read"
+	ScopeConnections       = "connections"
+)
+
+// ErrBearerTokenRequired is returned when calling an endpoint that is only
+// available to OAuth2 bearer tokens with a client using a bot token.
+// See WithBearerToken.
+var ErrBearerTokenRequired = errors.New("this endpoint requires an OAuth2 bearer token, see WithBearerToken")
+
+// ErrBotTokenRequired is returned by Connect when the client
+// uses an OAuth2 bearer token, which can not connect to the Gateway.
+var ErrBotTokenRequired = errors.New("connecting to the Gateway requires a bot token")
+
+// MissingScopeError is returned when calling an endpoint with a bearer
+// token that was not granted the OAuth2 scope the endpoint requires.
+type MissingScopeError struct {
+	Scope string
+}
+
+// Error implements the error interface.
+func (e *MissingScopeError) Error() string {
+	return fmt.Sprintf("this endpoint requires the %q OAuth2 scope, which was not granted to the token", e.Scope)
+}
+
+// WithBearerToken makes the client authenticate with an OAuth2 bearer token
+// obtained by a user authorizing an application, instead of a bot token. The
+// given scopes are the ones that were granted to the token. They are used to
+// fail early, with a *MissingScopeError, when calling endpoints that require
+// other scopes.
+// This is meant for tooling acting on behalf of users who authorized it, such
+// as dashboards. Automating normal user accounts ("self-bots") is not supported
+// and is against Discord's terms of service.
+// A client using a bearer token can not connect to the Gateway, see ErrBotTokenRequired.
+func WithBearerToken(scopes ...string) ClientOption {
+	return func(c *Client) {
+		c.bearer = true
+		c.scopes = make(map[string]struct{}, len(scopes))
+		for _, s := range scopes {
+			c.scopes[strings.TrimSpace(s)] = struct{}{}
+		}
+	}
+}
+
+// requireScope returns an error if the client does not use
+// a bearer token that was granted the given scope.
+func (c *Client) requireScope(scope string) error {
+	if !c.bearer {
+		return ErrBearerTokenRequired
+	}
+	if _, ok := c.scopes[scope]; !ok {
+		return &MissingScopeError{Scope: scope}
+	}
+	return nil
+}
+
+// checkScope is like requireScope, but for endpoints that are also available
+// to bot tokens. It only returns an error if the client uses a bearer token
+// that was not granted the given scope.
+func (c *Client) checkScope(scope string) error {
+	if !c.bearer {
+		return nil
+	}
+	return c.requireScope(scope)
+}
+
+// GuildMember returns the guild member of the current user in the given guild.
+// Requires a bearer token with the "guilds.members.read" scope, see WithBearerToken.
+func (r *CurrentUserResource) GuildMember(ctx context.Context, guildID string) (*GuildMember, error) {
+	if err := r.client.requireScope(ScopeGuildsMembersRead); err != nil {
+		return nil, err
+	}
+
+	e := endpoint.GetCurrentUserGuildMember(guildID)
+	resp, err := r.client.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var m GuildMember
+	if err = json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
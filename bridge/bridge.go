@@ -0,0 +1,367 @@
+/*
+Package bridge provides a plugin mirroring messages between linked channels,
+possibly across guilds. Messages are reposted through webhooks so they keep the
+name and avatar of their author, and edits and deletions are mirrored as well:
+
+	b := bridge.New()
+	b.Link(channelID, otherGuildChannelID)
+	client, err := harmony.NewClient(token, harmony.WithPlugins(b))
+
+The bridge creates a webhook in each destination channel the first time it
+mirrors a message there, which requires the 'MANAGE_WEBHOOKS' permission, and
+reuses it afterwards. Attachments are mirrored as links. Mirrored messages are
+tracked in memory, so edits and deletions of messages sent before a restart
+are not mirrored. The client needs the GUILD_MESSAGES and MESSAGE_CONTENT
+intents.
+*/
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/embed"
+)
+
+// DefaultWebhookName is the default name of the webhooks created by the bridge.
+const DefaultWebhookName = "Harmony Bridge"
+
+const (
+	// requestTimeout is the time allowed to mirror a single event.
+	requestTimeout = 10 * time.Second
+	// maxTrackedMessages is the number of mirrored messages whose
+	// mirrors are remembered, so their edits and deletions are mirrored.
+	maxTrackedMessages = 10000
+	// Limits enforced by Discord on messages sent by webhooks.
+	maxContentLength  = 2000
+	maxUsernameLength = 80
+)
+
+// Bridge is a harmony.Plugin mirroring messages between linked channels.
+// Links can be changed at any time. Create one with New.
+type Bridge struct {
+	webhookName string
+	onError     func(error)
+
+	mu    sync.RWMutex
+	links map[string]map[string]bool // Destination channels by source channel.
+
+	client *harmony.Client
+	sub    *harmony.Subscription
+	userID string
+
+	// Only accessed by the subscription processing events.
+	webhooks map[string]*harmony.Webhook // By destination channel.
+	own      map[string]bool             // IDs of the webhooks of the bridge.
+	mirrors  map[string][]mirror         // By source message.
+	order    []string                    // Source messages, oldest first.
+}
+
+// mirror is a copy of a message, sent through a webhook.
+type mirror struct {
+	webhook   *harmony.Webhook
+	messageID string
+}
+
+// Option is a function that configures a Bridge.
+type Option func(*Bridge)
+
+// WithWebhookName sets the name of the webhooks created by the bridge.
+// Defaults to DefaultWebhookName.
+func WithWebhookName(name string) Option {
+	return func(b *Bridge) {
+		b.webhookName = name
+	}
+}
+
+// WithErrorHandler sets the function called when a message can not be
+// mirrored, for instance because of missing permissions. Errors are
+// ignored by default.
+func WithErrorHandler(f func(err error)) Option {
+	return func(b *Bridge) {
+		b.onError = f
+	}
+}
+
+// New returns a new Bridge with no links.
+func New(opts ...Option) *Bridge {
+	b := &Bridge{
+		webhookName: DefaultWebhookName,
+		onError:     func(error) {},
+		links:       make(map[string]map[string]bool),
+		webhooks:    make(map[string]*harmony.Webhook),
+		own:         make(map[string]bool),
+		mirrors:     make(map[string][]mirror),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Link links the two given channels, so messages sent in either one
+// are mirrored to the other.
+func (b *Bridge) Link(channelID, otherChannelID string) {
+	b.LinkOneWay(channelID, otherChannelID)
+	b.LinkOneWay(otherChannelID, channelID)
+}
+
+// LinkOneWay links the two given channels so messages sent in the
+// first one are mirrored to the second one, but not the other way.
+func (b *Bridge) LinkOneWay(from, to string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.links[from] == nil {
+		b.links[from] = make(map[string]bool)
+	}
+	b.links[from][to] = true
+}
+
+// Unlink removes links between the two given channels, in both directions.
+func (b *Bridge) Unlink(channelID, otherChannelID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.links[channelID], otherChannelID)
+	delete(b.links[otherChannelID], channelID)
+}
+
+func (b *Bridge) destinations(channelID string) []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var dests []string
+	for id := range b.links[channelID] {
+		dests = append(dests, id)
+	}
+	return dests
+}
+
+// Init implements the harmony.Plugin interface.
+func (b *Bridge) Init(c *harmony.Client) error {
+	b.client = c
+	return nil
+}
+
+// Start implements the harmony.Plugin interface.
+func (b *Bridge) Start(ctx context.Context) error {
+	u, err := b.client.CurrentUser().Get(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get current user: %w", err)
+	}
+	b.userID = u.ID
+
+	b.sub = b.client.Subscribe(b.handle)
+	return nil
+}
+
+// Stop implements the harmony.Plugin interface.
+func (b *Bridge) Stop(ctx context.Context) error {
+	return b.sub.Close(ctx)
+}
+
+func (b *Bridge) handle(e *harmony.Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	switch d := e.Data.(type) {
+	case *harmony.Message:
+		// Ignore messages sent by the bridge itself, so they are not mirrored back.
+		if d.Author == nil || b.own[d.WebhookID] || d.Type.IsSystem() {
+			return
+		}
+		if e.Type == "MESSAGE_CREATE" {
+			b.create(ctx, d)
+		} else if e.Type == "MESSAGE_UPDATE" {
+			b.edit(ctx, d)
+		}
+	case *harmony.MessageDelete:
+		b.delete(ctx, d.MessageID)
+	case *harmony.MessageDeleteBulk:
+		for _, id := range d.IDs {
+			b.delete(ctx, id)
+		}
+	}
+}
+
+func (b *Bridge) create(ctx context.Context, msg *harmony.Message) {
+	dests := b.destinations(msg.ChannelID)
+	if len(dests) == 0 {
+		return
+	}
+
+	p := &harmony.WebhookParameters{
+		Content:   mirrorContent(msg),
+		Username:  username(msg),
+		AvatarURL: msg.Author.AvatarURL(),
+		Embeds:    richEmbeds(msg.Embeds),
+	}
+	if p.Content == "" && len(p.Embeds) == 0 {
+		return
+	}
+
+	var mirrors []mirror
+	for _, channelID := range dests {
+		w, m, err := b.exec(ctx, channelID, p)
+		if err != nil {
+			b.onError(fmt.Errorf("bridge: could not mirror message %s to channel %s: %w", msg.ID, channelID, err))
+			continue
+		}
+		mirrors = append(mirrors, mirror{webhook: w, messageID: m.ID})
+	}
+	b.track(msg.ID, mirrors)
+}
+
+// exec executes the webhook of the given channel, creating it if needed.
+func (b *Bridge) exec(ctx context.Context, channelID string, p *harmony.WebhookParameters) (*harmony.Webhook, *harmony.Message, error) {
+	w, err := b.webhook(ctx, channelID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := harmony.ExecWebhook(ctx, w.ID, w.Token, p, true)
+	// The webhook may have been deleted by someone else, create a new one.
+	var apiErr harmony.APIError
+	if errors.As(err, &apiErr) && apiErr.HTTPCode == http.StatusNotFound {
+		delete(b.webhooks, channelID)
+		if w, err = b.webhook(ctx, channelID); err != nil {
+			return nil, nil, err
+		}
+		m, err = harmony.ExecWebhook(ctx, w.ID, w.Token, p, true)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return w, m, nil
+}
+
+// webhook returns the webhook of the bridge in the given channel, creating it if needed.
+func (b *Bridge) webhook(ctx context.Context, channelID string) (*harmony.Webhook, error) {
+	if w, ok := b.webhooks[channelID]; ok {
+		return w, nil
+	}
+
+	webhooks, err := b.client.Channel(channelID).Webhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var w *harmony.Webhook
+	for i := range webhooks {
+		wh := &webhooks[i]
+		if wh.Name == b.webhookName && wh.Token != "" && wh.User != nil && wh.User.ID == b.userID {
+			w = wh
+			break
+		}
+	}
+	if w == nil {
+		if w, err = b.client.Channel(channelID).NewWebhookWithReason(ctx, b.webhookName, "", "Message bridge"); err != nil {
+			return nil, err
+		}
+	}
+
+	b.webhooks[channelID] = w
+	b.own[w.ID] = true
+	return w, nil
+}
+
+func (b *Bridge) edit(ctx context.Context, msg *harmony.Message) {
+	mirrors, ok := b.mirrors[msg.ID]
+	if !ok {
+		return
+	}
+
+	p := &harmony.WebhookParameters{
+		Content: mirrorContent(msg),
+		Embeds:  richEmbeds(msg.Embeds),
+	}
+	for _, m := range mirrors {
+		if _, err := harmony.EditWebhookMessage(ctx, m.webhook.ID, m.webhook.Token, m.messageID, p); err != nil {
+			b.onError(fmt.Errorf("bridge: could not mirror edit of message %s to channel %s: %w", msg.ID, m.webhook.ChannelID, err))
+		}
+	}
+}
+
+func (b *Bridge) delete(ctx context.Context, messageID string) {
+	mirrors, ok := b.mirrors[messageID]
+	if !ok {
+		return
+	}
+	delete(b.mirrors, messageID)
+
+	for _, m := range mirrors {
+		if err := harmony.DeleteWebhookMessage(ctx, m.webhook.ID, m.webhook.Token, m.messageID); err != nil {
+			b.onError(fmt.Errorf("bridge: could not mirror deletion of message %s to channel %s: %w", messageID, m.webhook.ChannelID, err))
+		}
+	}
+}
+
+// track remembers the mirrors of the given message, forgetting the
+// oldest tracked messages if there are too many.
+func (b *Bridge) track(messageID string, mirrors []mirror) {
+	if len(mirrors) == 0 {
+		return
+	}
+
+	b.mirrors[messageID] = mirrors
+	b.order = append(b.order, messageID)
+	for len(b.order) > maxTrackedMessages {
+		delete(b.mirrors, b.order[0])
+		b.order = b.order[1:]
+	}
+}
+
+// mirrorContent returns the content of the mirror of the given message: its
+// content followed by links to its attachments, without mass mentions.
+func mirrorContent(msg *harmony.Message) string {
+	content := msg.Content
+	for _, a := range msg.Attachments {
+		if content != "" {
+			content += "\n"
+		}
+		content += a.URL
+	}
+
+	// Webhooks can mention everyone, make sure mirrored messages do not.
+	content = strings.NewReplacer("@everyone", "@\u200beveryone", "@here", "@\u200bhere").Replace(content)
+	return truncate(content, maxContentLength)
+}
+
+// username returns the name shown as the author of the mirror of the given message.
+func username(msg *harmony.Message) string {
+	name := msg.Author.Username
+	if msg.Member != nil && msg.Member.Nick != "" {
+		name = msg.Member.Nick
+	}
+	return truncate(name, maxUsernameLength)
+}
+
+// richEmbeds returns the embeds sent with a message. Other embeds,
+// such as link previews, are generated again by Discord.
+func richEmbeds(embeds []embed.Embed) []embed.Embed {
+	var rich []embed.Embed
+	for _, e := range embeds {
+		if e.Type == "" || e.Type == "rich" {
+			rich = append(rich, e)
+		}
+	}
+	return rich
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:max-1]) + "…"
+}
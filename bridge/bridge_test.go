@@ -0,0 +1,300 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/embed"
+	"github.com/skwair/harmony/harmonytest"
+	"github.com/skwair/harmony/message"
+)
+
+// webhookServer serves the endpoints of webhooks, which are always
+// requested on Discord without authentication, and records requests.
+type webhookServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []string        // Method and path of each request.
+	contents []string        // Content of each executed or edited message.
+	deleted  map[string]bool // IDs of the webhooks deleted by someone else.
+}
+
+func newWebhookServer() *webhookServer {
+	s := &webhookServer{deleted: make(map[string]bool)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *webhookServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	// Paths are /api/vX/webhooks/:id/:token[/messages/:message].
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")[3:]
+
+	var p struct {
+		Content string `json:"content"`
+	}
+	if r.Method != http.MethodDelete {
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" /"+strings.Join(segments, "/"))
+	if s.deleted[segments[0]] {
+		harmonytest.WriteError(w, http.StatusNotFound, 10015, "Unknown Webhook")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		if r.URL.Query().Get("wait") != "true" {
+			http.Error(w, "expected wait to be true", http.StatusBadRequest)
+			return
+		}
+		s.contents = append(s.contents, p.Content)
+		harmonytest.WriteJSON(w, http.StatusOK, &harmony.Message{ID: "m" + segments[0], WebhookID: segments[0]})
+	case http.MethodPatch:
+		s.contents = append(s.contents, p.Content)
+		harmonytest.WriteJSON(w, http.StatusOK, &harmony.Message{ID: segments[3], WebhookID: segments[0]})
+	case http.MethodDelete:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// reset returns and forgets the recorded requests and contents.
+func (s *webhookServer) reset() (requests, contents []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests, contents = s.requests, s.contents
+	s.requests, s.contents = nil, nil
+	return requests, contents
+}
+
+// redirectTransport sends requests for Discord to another server.
+type redirectTransport struct {
+	to *url.URL
+}
+
+func (t *redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Host != "discord.com" {
+		return http.DefaultTransport.RoundTrip(r)
+	}
+
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host, r.Host = t.to.Scheme, t.to.Host, t.to.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestBridge(t *testing.T) {
+	hooks := newWebhookServer()
+	defer hooks.Close()
+
+	to, err := url.Parse(hooks.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = &redirectTransport{to: to}
+	defer func() { http.DefaultClient.Transport = transport }()
+
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	// Channel 2 already has a webhook of the bridge, channel 3 has none.
+	var created int32
+	srv.Handle(http.MethodGet, "/channels/:channel/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		var webhooks []harmony.Webhook
+		if harmonytest.Param(r, "channel") == "2" {
+			webhooks = append(webhooks, harmony.Webhook{ID: "w2", Token: "t", ChannelID: "2", Name: DefaultWebhookName, User: srv.User()})
+		}
+		harmonytest.WriteJSON(w, http.StatusOK, webhooks)
+	})
+	srv.Handle(http.MethodPost, "/channels/:channel/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&created, 1)
+		channelID := harmonytest.Param(r, "channel")
+		harmonytest.WriteJSON(w, http.StatusOK, &harmony.Webhook{ID: "new" + channelID, Token: "t", ChannelID: channelID})
+	})
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	var errs []error
+	b := New(WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	if err = b.Init(c); err != nil {
+		t.Fatal(err)
+	}
+	b.userID = srv.User().ID
+	b.Link("1", "2")
+	b.LinkOneWay("1", "3")
+
+	author := &harmony.User{ID: "4", Username: "user"}
+	b.handle(&harmony.Event{Type: "MESSAGE_CREATE", Data: &harmony.Message{ID: "10", ChannelID: "1", Content: "hello @everyone", Author: author}})
+	requests, contents := hooks.reset()
+	if len(requests) != 2 || atomic.LoadInt32(&created) != 1 {
+		t.Fatalf("expected the message to be mirrored to both channels, creating a single webhook, got %v", requests)
+	}
+	for _, content := range contents {
+		if content != "hello @\u200beveryone" {
+			t.Errorf("expected the mass mention to be neutralized, got %q", content)
+		}
+	}
+
+	// Messages sent by the webhooks of the bridge are not mirrored back.
+	b.handle(&harmony.Event{Type: "MESSAGE_CREATE", Data: &harmony.Message{ID: "11", ChannelID: "2", Content: "hello", Author: author, WebhookID: "w2"}})
+	b.handle(&harmony.Event{Type: "MESSAGE_CREATE", Data: &harmony.Message{ID: "12", ChannelID: "2", Author: author, Type: message.TypeGuildMemberJoin}})
+	if requests, _ = hooks.reset(); len(requests) != 0 {
+		t.Errorf("expected messages of the bridge and system messages to be ignored, got %v", requests)
+	}
+
+	b.handle(&harmony.Event{Type: "MESSAGE_UPDATE", Data: &harmony.Message{ID: "10", ChannelID: "1", Content: "edited", Author: author}})
+	requests, contents = hooks.reset()
+	if !contains(requests, "PATCH /w2/t/messages/mw2") || !contains(requests, "PATCH /new3/t/messages/mnew3") {
+		t.Errorf("expected the edit to be mirrored to both channels, got %v", requests)
+	}
+	if len(contents) != 2 || contents[0] != "edited" {
+		t.Errorf("expected the mirrors to be edited, got %v", contents)
+	}
+
+	b.handle(&harmony.Event{Type: "MESSAGE_DELETE", Data: &harmony.MessageDelete{ChannelID: "1", MessageID: "10"}})
+	if requests, _ = hooks.reset(); !contains(requests, "DELETE /w2/t/messages/mw2") || !contains(requests, "DELETE /new3/t/messages/mnew3") {
+		t.Errorf("expected the deletion to be mirrored to both channels, got %v", requests)
+	}
+	b.handle(&harmony.Event{Type: "MESSAGE_DELETE", Data: &harmony.MessageDelete{ChannelID: "1", MessageID: "10"}})
+	if requests, _ = hooks.reset(); len(requests) != 0 {
+		t.Errorf("expected mirrors to be forgotten once deleted, got %v", requests)
+	}
+
+	// A webhook deleted by someone else is created again.
+	hooks.mu.Lock()
+	hooks.deleted["new3"] = true
+	hooks.mu.Unlock()
+	b.Unlink("1", "2")
+	srv.Handle(http.MethodPost, "/channels/:channel/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&created, 1)
+		harmonytest.WriteJSON(w, http.StatusOK, &harmony.Webhook{ID: "again", Token: "t", ChannelID: harmonytest.Param(r, "channel")})
+	})
+
+	b.handle(&harmony.Event{Type: "MESSAGE_CREATE", Data: &harmony.Message{ID: "13", ChannelID: "1", Content: "again", Author: author}})
+	if requests, _ = hooks.reset(); len(requests) != 2 || requests[1] != "POST /again/t" || atomic.LoadInt32(&created) != 2 {
+		t.Errorf("expected the message to be mirrored with a new webhook, got %v", requests)
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
+
+func TestMirrorContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      *harmony.Message
+		expected string
+	}{
+		{name: "content", msg: &harmony.Message{Content: "hello"}, expected: "hello"},
+		{
+			name:     "attachments",
+			msg:      &harmony.Message{Content: "look", Attachments: []message.Attachment{{URL: "a.png"}, {URL: "b.png"}}},
+			expected: "look\na.png\nb.png",
+		},
+		{name: "attachments only", msg: &harmony.Message{Attachments: []message.Attachment{{URL: "a.png"}}}, expected: "a.png"},
+		{name: "mass mentions", msg: &harmony.Message{Content: "@everyone @here"}, expected: "@\u200beveryone @\u200bhere"},
+		{
+			name:     "too long",
+			msg:      &harmony.Message{Content: strings.Repeat("a", maxContentLength), Attachments: []message.Attachment{{URL: "a.png"}}},
+			expected: strings.Repeat("a", maxContentLength-1) + "…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if content := mirrorContent(tt.msg); content != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, content)
+			}
+		})
+	}
+}
+
+func TestUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      *harmony.Message
+		expected string
+	}{
+		{name: "username", msg: &harmony.Message{Author: &harmony.User{Username: "user"}}, expected: "user"},
+		{
+			name:     "nick",
+			msg:      &harmony.Message{Author: &harmony.User{Username: "user"}, Member: &harmony.GuildMember{Nick: "nick"}},
+			expected: "nick",
+		},
+		{
+			name:     "no nick",
+			msg:      &harmony.Message{Author: &harmony.User{Username: "user"}, Member: &harmony.GuildMember{}},
+			expected: "user",
+		},
+		{
+			name:     "too long",
+			msg:      &harmony.Message{Author: &harmony.User{Username: strings.Repeat("é", 100)}},
+			expected: strings.Repeat("é", maxUsernameLength-1) + "…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if name := username(tt.msg); name != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, name)
+			}
+		})
+	}
+}
+
+func TestRichEmbeds(t *testing.T) {
+	embeds := []embed.Embed{
+		{Title: "default"},
+		{Type: "rich", Title: "rich"},
+		{Type: "link", Title: "link"},
+		{Type: "image", Title: "image"},
+	}
+
+	rich := richEmbeds(embeds)
+	if len(rich) != 2 || rich[0].Title != "default" || rich[1].Title != "rich" {
+		t.Errorf("expected only rich embeds to be kept, got %+v", rich)
+	}
+}
+
+func TestTrack(t *testing.T) {
+	b := New()
+	for i := 0; i <= maxTrackedMessages; i++ {
+		b.track(strconv.Itoa(i), []mirror{{messageID: "1"}})
+	}
+	b.track("untracked", nil)
+
+	if len(b.mirrors) != maxTrackedMessages || len(b.order) != maxTrackedMessages {
+		t.Errorf("expected %d messages to be tracked, got %d", maxTrackedMessages, len(b.mirrors))
+	}
+	if _, ok := b.mirrors["0"]; ok {
+		t.Error("expected the oldest message to be forgotten")
+	}
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -37,6 +37,20 @@ type Channel struct {
 
 	ParentID         string    `json:"parent_id,omitempty"` // ID of the parent category for a channel.
 	LastPinTimestamp time.Time `json:"last_pin_timestamp,omitempty"`
+
+	// For threads. The ParentID is the ID of the channel the thread was created in.
+	MessageCount   int                     `json:"message_count,omitempty"`
+	MemberCount    int                     `json:"member_count,omitempty"` // Stops counting at 50.
+	ThreadMetadata *channel.ThreadMetadata `json:"thread_metadata,omitempty"`
+	// Member is the thread member of the current user, if they joined the thread.
+	// Only set by some endpoints.
+	Member *ThreadMember `json:"member,omitempty"`
+	// Default duration after which new threads of the channel are archived.
+	DefaultAutoArchiveDuration channel.AutoArchiveDuration `json:"default_auto_archive_duration,omitempty"`
+
+	// Fields received from the Gateway that are not known by
+	// Harmony, only set if WithRawExtra is enabled.
+	RawExtra RawExtra `json:"-"`
 }
 
 // ChannelResource is a resource that allows to perform various actions on a Discord channel.
@@ -79,9 +93,13 @@ func (r *ChannelResource) Modify(ctx context.Context, settings *channel.Settings
 // ModifyWithReason updates the channel's settings. Requires the 'MANAGE_CHANNELS'
 // permission for the guild. Fires a Channel Update Gateway event. If modifying
 // category, individual Channel Update events will fire for each child channel
-// that also changes.
+// that also changes. For group DMs, only the name and the icon can be modified.
 // The given reason will be set in the audit log entry for this action.
 func (r *ChannelResource) ModifyWithReason(ctx context.Context, settings *channel.Settings, reason string) (*Channel, error) {
+	return r.modify(ctx, settings, reason)
+}
+
+func (r *ChannelResource) modify(ctx context.Context, settings interface{}, reason string) (*Channel, error) {
 	b, err := json.Marshal(settings)
 	if err != nil {
 		return nil, err
@@ -291,9 +309,18 @@ func (r *ChannelResource) TriggerTyping(ctx context.Context) error {
 }
 
 // Webhooks returns webhooks for the channel.
+// If state tracking is enabled, they are cached, see State.Webhooks.
 func (r *ChannelResource) Webhooks(ctx context.Context) ([]Webhook, error) {
 	e := endpoint.GetChannelWebhooks(r.channelID)
-	return r.client.webhooks(ctx, e)
+	webhooks, err := r.client.webhooks(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.client.withStateTracking {
+		r.client.State.setWebhooks(r.channelID, webhooks)
+	}
+	return webhooks, nil
 }
 
 // NewWebhook is like NewWebhookWithReason but with no particular reason.
@@ -13,6 +13,13 @@ const (
 	TypeGuildCategory
 	TypeGuildNews
 	TypeGuildStore
+	TypeGuildNewsThread    Type = 10
+	TypeGuildPublicThread  Type = 11
+	TypeGuildPrivateThread Type = 12
+	TypeGuildStageVoice    Type = 13
+	TypeGuildDirectory     Type = 14
+	TypeGuildForum         Type = 15
+	TypeGuildMedia         Type = 16
 )
 
 // Mention represents a channel mention.
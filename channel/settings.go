@@ -1,26 +1,87 @@
 package channel
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/skwair/harmony/optional"
 	"github.com/skwair/harmony/permission"
 )
 
-// Settings describes a channel creation or update.
+// Constraints enforced by Discord on channel settings.
+const (
+	MaxNameLength         = 100
+	MaxTopicLength        = 1024
+	MaxForumTopicLength   = 4096
+	MinBitrate            = 8000
+	MaxBitrate            = 384000
+	MaxVoiceUserLimit     = 99
+	MaxStageUserLimit     = 10000
+	MaxRateLimitPerUser   = 21600
+	MaxForumTags          = 20
+	MaxForumTagNameLength = 20
+)
+
+// AutoArchiveDuration is the duration, in minutes, after which threads
+// stop showing in the channel list after recent activity.
+type AutoArchiveDuration int
+
+// Allowed auto archive durations.
+const (
+	AutoArchiveOneHour   AutoArchiveDuration = 60
+	AutoArchiveOneDay    AutoArchiveDuration = 1440
+	AutoArchiveThreeDays AutoArchiveDuration = 4320
+	AutoArchiveOneWeek   AutoArchiveDuration = 10080
+)
+
+// ForumTag is a tag that can be applied to threads of
+// a forum or media channel.
+type ForumTag struct {
+	// ID of the tag. Leave empty when creating a new tag.
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	// Whether this tag can only be added to or removed from
+	// threads by members with the 'MANAGE_THREADS' permission.
+	Moderated bool `json:"moderated"`
+	// Set either the ID of a custom emoji or the
+	// name (unicode character) of a standard emoji.
+	EmojiID   string `json:"emoji_id,omitempty"`
+	EmojiName string `json:"emoji_name,omitempty"`
+}
+
+// Settings describes a channel creation or update. All fields are optional
+// and only those explicitly set will be sent, even when set to their zero
+// value. This allows to clear a field, for instance by setting the topic to
+// an empty string or the rate limit per user to 0.
 type Settings struct {
-	Name      *optional.String `json:"name,omitempty"` // 2-100 characters.
-	Type      *optional.Int    `json:"type"`
-	Topic     *optional.String `json:"topic"` // 0-1000 characters.
+	Name      *optional.String `json:"name,omitempty"` // 1-100 characters.
+	Type      *optional.Int    `json:"type,omitempty"`
+	Topic     *optional.String `json:"topic,omitempty"` // 0-1024 characters (0-4096 for forums).
 	Bitrate   *optional.Int    `json:"bitrate,omitempty"`
 	UserLimit *optional.Int    `json:"user_limit,omitempty"`
 	// RateLimitPerUser is the amount of seconds a user has to wait before sending
-	// another message (0-120); bots, as well as users with the permission
+	// another message (0-21600); bots, as well as users with the permission
 	// 'manage_messages' or 'manage_channel', are unaffected.
-	RateLimitPerUser *optional.Int `json:"rate_limit_per_user"`
+	RateLimitPerUser *optional.Int `json:"rate_limit_per_user,omitempty"`
 	// Sorting position of the channel.
-	Position    *optional.Int          `json:"position"`
-	Permissions []permission.Overwrite `json:"permission_overwrites,omitempty"`
-	ParentID    *optional.String       `json:"parent_id,omitempty"`
-	NSFW        *optional.Bool         `json:"nsfw,omitempty"`
+	Position *optional.Int `json:"position,omitempty"`
+	// Permissions is a pointer so an empty list of overwrites can
+	// be sent to remove all existing ones.
+	Permissions *[]permission.Overwrite `json:"permission_overwrites,omitempty"`
+	ParentID    *optional.String        `json:"parent_id,omitempty"`
+	NSFW        *optional.Bool          `json:"nsfw,omitempty"`
+	// Icon of a group DM, as a base64 encoded image.
+	Icon *optional.String `json:"icon,omitempty"`
+
+	// Default duration, in minutes, after which new threads created in
+	// the channel are archived (text, news and forum only).
+	DefaultAutoArchiveDuration *optional.Int `json:"default_auto_archive_duration,omitempty"`
+	// Rate limit per user applied to new threads (text and forum only).
+	DefaultThreadRateLimitPerUser *optional.Int `json:"default_thread_rate_limit_per_user,omitempty"`
+	// Tags that can be applied to threads (forum and media only).
+	AvailableTags *[]ForumTag `json:"available_tags,omitempty"`
 }
 
 // Setting is a function that configures a channel.
@@ -44,7 +105,7 @@ func WithName(name string) Setting {
 	}
 }
 
-// WithType sets the name of a channel.
+// WithType sets the type of a channel.
 func WithType(t Type) Setting {
 	return func(s *Settings) {
 		s.Type = optional.NewInt(int(t))
@@ -52,6 +113,7 @@ func WithType(t Type) Setting {
 }
 
 // WithTopic sets the topic of a channel (text only).
+// An empty topic will remove the current topic.
 func WithTopic(topic string) Setting {
 	return func(s *Settings) {
 		s.Topic = optional.NewString(topic)
@@ -66,6 +128,7 @@ func WithBitrate(bitrate int) Setting {
 }
 
 // WithUserLimit sets the user limit of a channel (audio only).
+// A limit of 0 means there is no limit.
 func WithUserLimit(limit int) Setting {
 	return func(s *Settings) {
 		s.UserLimit = optional.NewInt(limit)
@@ -73,6 +136,7 @@ func WithUserLimit(limit int) Setting {
 }
 
 // WithRateLimitPerUser sets the rate limit per user (text only).
+// A rate limit of 0 disables it.
 func WithRateLimitPerUser(rateLimit int) Setting {
 	return func(s *Settings) {
 		s.RateLimitPerUser = optional.NewInt(rateLimit)
@@ -90,14 +154,22 @@ func WithPosition(pos int) Setting {
 // Pass an empty array to remove all permission overwrites.
 func WithPermissions(perms []permission.Overwrite) Setting {
 	return func(s *Settings) {
-		s.Permissions = perms
+		if perms == nil {
+			perms = []permission.Overwrite{}
+		}
+		s.Permissions = &perms
 	}
 }
 
 // WithParent sets the parent ID channel of a channel.
+// An empty id will remove the channel from its category.
 func WithParent(id string) Setting {
 	return func(s *Settings) {
-		s.ParentID = optional.NewString(id)
+		if id == "" {
+			s.ParentID = optional.NewNilString()
+		} else {
+			s.ParentID = optional.NewString(id)
+		}
 	}
 }
 
@@ -107,3 +179,156 @@ func WithNSFW(yes bool) Setting {
 		s.NSFW = optional.NewBool(yes)
 	}
 }
+
+// WithIcon sets the icon of a channel (group DM only), which is a base64
+// encoded image. An empty icon will remove the current icon.
+func WithIcon(icon string) Setting {
+	return func(s *Settings) {
+		if icon == "" {
+			s.Icon = optional.NewNilString()
+		} else {
+			s.Icon = optional.NewString(icon)
+		}
+	}
+}
+
+// WithDefaultAutoArchiveDuration sets the default duration after which new
+// threads of a channel are archived (text, news and forum only).
+func WithDefaultAutoArchiveDuration(d AutoArchiveDuration) Setting {
+	return func(s *Settings) {
+		s.DefaultAutoArchiveDuration = optional.NewInt(int(d))
+	}
+}
+
+// WithDefaultThreadRateLimitPerUser sets the rate limit per user applied
+// to new threads of a channel (text and forum only).
+func WithDefaultThreadRateLimitPerUser(rateLimit int) Setting {
+	return func(s *Settings) {
+		s.DefaultThreadRateLimitPerUser = optional.NewInt(rateLimit)
+	}
+}
+
+// WithAvailableTags sets the tags that can be applied to threads of a
+// channel (forum and media only). Existing tags that are not given will
+// be removed.
+func WithAvailableTags(tags ...ForumTag) Setting {
+	return func(s *Settings) {
+		if tags == nil {
+			tags = []ForumTag{}
+		}
+		s.AvailableTags = &tags
+	}
+}
+
+// Validate checks these settings against the constraints enforced by Discord
+// and returns an error describing every violated constraint, if any. Settings
+// that only apply to some channel types are checked against the type set with
+// WithType, if any.
+func (s *Settings) Validate() error {
+	var problems []string
+
+	if s.Name != nil {
+		name, _ := s.Name.Value()
+		if l := utf8.RuneCountInString(name); l == 0 || l > MaxNameLength {
+			problems = append(problems, fmt.Sprintf("name must be between 1 and %d characters", MaxNameLength))
+		}
+	}
+
+	typ, typed := s.Type.Value()
+	t := Type(typ)
+	is := func(types ...Type) bool {
+		if !typed {
+			return true
+		}
+		for _, typ := range types {
+			if t == typ {
+				return true
+			}
+		}
+		return false
+	}
+
+	if s.Topic != nil {
+		max := MaxTopicLength
+		if typed && (t == TypeGuildForum || t == TypeGuildMedia) {
+			max = MaxForumTopicLength
+		}
+		if !is(TypeGuildText, TypeGuildNews, TypeGuildForum, TypeGuildMedia) {
+			problems = append(problems, "topic can only be set for text, news, forum and media channels")
+		} else if topic, _ := s.Topic.Value(); utf8.RuneCountInString(topic) > max {
+			problems = append(problems, fmt.Sprintf("topic must be at most %d characters", max))
+		}
+	}
+
+	if s.Bitrate != nil {
+		if !is(TypeGuildVoice, TypeGuildStageVoice) {
+			problems = append(problems, "bitrate can only be set for voice and stage channels")
+		} else if bitrate, ok := s.Bitrate.Value(); ok && (bitrate < MinBitrate || bitrate > MaxBitrate) {
+			problems = append(problems, fmt.Sprintf("bitrate must be between %d and %d", MinBitrate, MaxBitrate))
+		}
+	}
+
+	if s.UserLimit != nil {
+		max := MaxVoiceUserLimit
+		if typed && t == TypeGuildStageVoice {
+			max = MaxStageUserLimit
+		}
+		if !is(TypeGuildVoice, TypeGuildStageVoice) {
+			problems = append(problems, "user limit can only be set for voice and stage channels")
+		} else if limit, ok := s.UserLimit.Value(); ok && (limit < 0 || limit > max) {
+			problems = append(problems, fmt.Sprintf("user limit must be between 0 and %d", max))
+		}
+	}
+
+	if s.RateLimitPerUser != nil {
+		if !is(TypeGuildText, TypeGuildVoice, TypeGuildStageVoice, TypeGuildForum, TypeGuildMedia) {
+			problems = append(problems, "rate limit per user can not be set for this channel type")
+		} else if rateLimit, ok := s.RateLimitPerUser.Value(); ok && (rateLimit < 0 || rateLimit > MaxRateLimitPerUser) {
+			problems = append(problems, fmt.Sprintf("rate limit per user must be between 0 and %d", MaxRateLimitPerUser))
+		}
+	}
+
+	if s.DefaultAutoArchiveDuration != nil {
+		if d, ok := s.DefaultAutoArchiveDuration.Value(); ok {
+			switch AutoArchiveDuration(d) {
+			case AutoArchiveOneHour, AutoArchiveOneDay, AutoArchiveThreeDays, AutoArchiveOneWeek:
+			default:
+				problems = append(problems, fmt.Sprintf("invalid default auto archive duration %d", d))
+			}
+		}
+		if !is(TypeGuildText, TypeGuildNews, TypeGuildForum, TypeGuildMedia) {
+			problems = append(problems, "default auto archive duration can only be set for text, news, forum and media channels")
+		}
+	}
+
+	if s.DefaultThreadRateLimitPerUser != nil && !is(TypeGuildText, TypeGuildForum, TypeGuildMedia) {
+		problems = append(problems, "default thread rate limit per user can only be set for text, forum and media channels")
+	}
+
+	if s.AvailableTags != nil {
+		tags := *s.AvailableTags
+		if !is(TypeGuildForum, TypeGuildMedia) {
+			problems = append(problems, "available tags can only be set for forum and media channels")
+		}
+		if len(tags) > MaxForumTags {
+			problems = append(problems, fmt.Sprintf("at most %d tags are allowed, got %d", MaxForumTags, len(tags)))
+		}
+		for i, tag := range tags {
+			if l := utf8.RuneCountInString(tag.Name); l == 0 || l > MaxForumTagNameLength {
+				problems = append(problems, fmt.Sprintf("tag %d: name must be between 1 and %d characters", i, MaxForumTagNameLength))
+			}
+			if tag.EmojiID != "" && tag.EmojiName != "" {
+				problems = append(problems, fmt.Sprintf("tag %d: only one of emoji ID or emoji name can be set", i))
+			}
+		}
+	}
+
+	if s.Icon != nil && !is(TypeGroupDM) {
+		problems = append(problems, "icon can only be set for group DMs")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid channel settings: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package channel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skwair/harmony/optional"
+)
+
+func TestSettingsValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings *Settings
+		valid    bool
+	}{
+		{name: "empty", settings: NewSettings(), valid: true},
+		{name: "name", settings: NewSettings(WithName("general")), valid: true},
+		{name: "empty name", settings: NewSettings(WithName("")), valid: false},
+		{name: "name too long", settings: NewSettings(WithName(strings.Repeat("a", MaxNameLength+1))), valid: false},
+		{name: "nil name", settings: &Settings{Name: optional.NewNilString()}, valid: false},
+		{name: "literal name too long", settings: &Settings{Name: optional.NewString(strings.Repeat("a", MaxNameLength+1))}, valid: false},
+		{name: "voice bitrate", settings: NewSettings(WithType(TypeGuildVoice), WithBitrate(64000)), valid: true},
+		{name: "bitrate out of range", settings: NewSettings(WithType(TypeGuildVoice), WithBitrate(MaxBitrate+1)), valid: false},
+		{name: "literal bitrate out of range", settings: &Settings{Bitrate: optional.NewInt(MinBitrate - 1)}, valid: false},
+		{name: "nil bitrate", settings: &Settings{Bitrate: optional.NewNilInt()}, valid: true},
+		{name: "text bitrate", settings: NewSettings(WithType(TypeGuildText), WithBitrate(64000)), valid: false},
+		{name: "literal type", settings: &Settings{Type: optional.NewInt(int(TypeGuildText)), Bitrate: optional.NewInt(64000)}, valid: false},
+		{name: "stage user limit", settings: NewSettings(WithType(TypeGuildStageVoice), WithUserLimit(MaxStageUserLimit)), valid: true},
+		{name: "voice user limit", settings: &Settings{Type: optional.NewInt(int(TypeGuildVoice)), UserLimit: optional.NewInt(MaxVoiceUserLimit + 1)}, valid: false},
+		{name: "topic too long", settings: &Settings{Topic: optional.NewString(strings.Repeat("a", MaxTopicLength+1))}, valid: false},
+		{name: "forum topic", settings: NewSettings(WithType(TypeGuildForum), WithTopic(strings.Repeat("a", MaxForumTopicLength))), valid: true},
+		{name: "rate limit out of range", settings: &Settings{RateLimitPerUser: optional.NewInt(-1)}, valid: false},
+		{name: "auto archive duration", settings: NewSettings(WithDefaultAutoArchiveDuration(AutoArchiveOneDay)), valid: true},
+		{name: "invalid auto archive duration", settings: &Settings{DefaultAutoArchiveDuration: optional.NewInt(2)}, valid: false},
+		{name: "text tags", settings: NewSettings(WithType(TypeGuildText), WithAvailableTags(ForumTag{Name: "a"})), valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.settings.Validate(); (err == nil) != tt.valid {
+				t.Errorf("expected settings to be valid: %t, got error: %v", tt.valid, err)
+			}
+		})
+	}
+}
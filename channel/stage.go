@@ -0,0 +1,99 @@
+package channel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/skwair/harmony/optional"
+)
+
+// MaxStageTopicLength is the maximum length of the topic of a stage instance.
+const MaxStageTopicLength = 120
+
+// StagePrivacyLevel describes who can see a stage instance.
+type StagePrivacyLevel int
+
+// Supported stage privacy levels:
+const (
+	// StagePrivacyLevelGuildOnly stage instances are only visible to guild members.
+	StagePrivacyLevelGuildOnly StagePrivacyLevel = 2
+)
+
+// StageInstanceSettings describes a stage instance creation or update. All fields
+// are optional and only those explicitly set will be sent.
+type StageInstanceSettings struct {
+	Topic        *optional.String `json:"topic,omitempty"` // 1-120 characters.
+	PrivacyLevel *optional.Int    `json:"privacy_level,omitempty"`
+	// Only when creating a stage instance.
+	SendStartNotification *optional.Bool   `json:"send_start_notification,omitempty"`
+	GuildScheduledEventID *optional.String `json:"guild_scheduled_event_id,omitempty"`
+}
+
+// StageInstanceSetting is a function that configures a stage instance.
+type StageInstanceSetting func(*StageInstanceSettings)
+
+// NewStageInstanceSettings returns new StageInstanceSettings
+// to create or modify a stage instance.
+func NewStageInstanceSettings(opts ...StageInstanceSetting) *StageInstanceSettings {
+	s := &StageInstanceSettings{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithStageTopic sets the topic of a stage instance.
+func WithStageTopic(topic string) StageInstanceSetting {
+	return func(s *StageInstanceSettings) {
+		s.Topic = optional.NewString(topic)
+	}
+}
+
+// WithStagePrivacyLevel sets the privacy level of a stage instance.
+// Defaults to StagePrivacyLevelGuildOnly.
+func WithStagePrivacyLevel(l StagePrivacyLevel) StageInstanceSetting {
+	return func(s *StageInstanceSettings) {
+		s.PrivacyLevel = optional.NewInt(int(l))
+	}
+}
+
+// WithStartNotification sets whether members are notified that a stage instance
+// started. Requires the 'MENTION_EVERYONE' permission.
+func WithStartNotification(yes bool) StageInstanceSetting {
+	return func(s *StageInstanceSettings) {
+		s.SendStartNotification = optional.NewBool(yes)
+	}
+}
+
+// WithStageScheduledEvent sets the scheduled event a stage instance is started for.
+func WithStageScheduledEvent(eventID string) StageInstanceSetting {
+	return func(s *StageInstanceSettings) {
+		s.GuildScheduledEventID = optional.NewString(eventID)
+	}
+}
+
+// Validate checks these settings against the constraints enforced by Discord
+// and returns an error describing every violated constraint, if any.
+func (s *StageInstanceSettings) Validate() error {
+	var problems []string
+
+	if s.Topic != nil {
+		topic, _ := s.Topic.Value()
+		if l := utf8.RuneCountInString(topic); l == 0 || l > MaxStageTopicLength {
+			problems = append(problems, fmt.Sprintf("topic must be between 1 and %d characters", MaxStageTopicLength))
+		}
+	}
+
+	if l, ok := s.PrivacyLevel.Value(); ok && StagePrivacyLevel(l) != StagePrivacyLevelGuildOnly {
+		problems = append(problems, fmt.Sprintf("invalid privacy level %d", l))
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid stage instance settings: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
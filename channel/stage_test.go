@@ -0,0 +1,32 @@
+package channel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skwair/harmony/optional"
+)
+
+func TestStageInstanceSettingsValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings *StageInstanceSettings
+		valid    bool
+	}{
+		{name: "empty", settings: NewStageInstanceSettings(), valid: true},
+		{name: "topic", settings: NewStageInstanceSettings(WithStageTopic("Town hall")), valid: true},
+		{name: "empty topic", settings: NewStageInstanceSettings(WithStageTopic("")), valid: false},
+		{name: "literal topic too long", settings: &StageInstanceSettings{Topic: optional.NewString(strings.Repeat("a", MaxStageTopicLength+1))}, valid: false},
+		{name: "privacy level", settings: NewStageInstanceSettings(WithStagePrivacyLevel(StagePrivacyLevelGuildOnly)), valid: true},
+		{name: "invalid privacy level", settings: NewStageInstanceSettings(WithStagePrivacyLevel(1)), valid: false},
+		{name: "literal invalid privacy level", settings: &StageInstanceSettings{PrivacyLevel: optional.NewInt(1)}, valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.settings.Validate(); (err == nil) != tt.valid {
+				t.Errorf("expected settings to be valid: %t, got error: %v", tt.valid, err)
+			}
+		})
+	}
+}
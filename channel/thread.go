@@ -0,0 +1,158 @@
+package channel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/skwair/harmony/optional"
+)
+
+// ThreadMetadata holds the fields specific to threads.
+type ThreadMetadata struct {
+	Archived bool `json:"archived"`
+	// Duration after which the thread is archived after recent activity.
+	AutoArchiveDuration AutoArchiveDuration `json:"auto_archive_duration"`
+	// Time the archived status of the thread was last changed.
+	ArchiveTimestamp time.Time `json:"archive_timestamp"`
+	// Locked threads can only be unarchived by members
+	// with the 'MANAGE_THREADS' permission.
+	Locked bool `json:"locked"`
+	// Whether non-moderators can add other non-moderators
+	// to the thread (private threads only).
+	Invitable bool `json:"invitable"`
+	// Time the thread was created, nil for threads
+	// created before January 9, 2022.
+	CreateTimestamp *time.Time `json:"create_timestamp"`
+}
+
+// IsThread returns whether the given channel type is a thread.
+func IsThread(t Type) bool {
+	return t == TypeGuildNewsThread || t == TypeGuildPublicThread || t == TypeGuildPrivateThread
+}
+
+// ThreadSettings describes a thread creation or update. All fields are optional
+// and only those explicitly set will be sent.
+type ThreadSettings struct {
+	Name                *optional.String `json:"name,omitempty"` // 1-100 characters.
+	AutoArchiveDuration *optional.Int    `json:"auto_archive_duration,omitempty"`
+	// Type of the thread, only when starting a thread without a message.
+	Type      *optional.Int  `json:"type,omitempty"`
+	Invitable *optional.Bool `json:"invitable,omitempty"`
+	// RateLimitPerUser is the amount of seconds a user has to
+	// wait before sending another message (0-21600).
+	RateLimitPerUser *optional.Int `json:"rate_limit_per_user,omitempty"`
+	// Only when modifying a thread.
+	Archived *optional.Bool `json:"archived,omitempty"`
+	Locked   *optional.Bool `json:"locked,omitempty"`
+}
+
+// ThreadSetting is a function that configures a thread.
+type ThreadSetting func(*ThreadSettings)
+
+// NewThreadSettings returns new ThreadSettings to start or modify a thread.
+func NewThreadSettings(opts ...ThreadSetting) *ThreadSettings {
+	s := &ThreadSettings{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithThreadName sets the name of a thread.
+func WithThreadName(name string) ThreadSetting {
+	return func(s *ThreadSettings) {
+		s.Name = optional.NewString(name)
+	}
+}
+
+// WithThreadType sets the type of a thread started without a message, either
+// TypeGuildPublicThread, TypeGuildPrivateThread or TypeGuildNewsThread.
+// Defaults to TypeGuildPrivateThread.
+func WithThreadType(t Type) ThreadSetting {
+	return func(s *ThreadSettings) {
+		s.Type = optional.NewInt(int(t))
+	}
+}
+
+// WithAutoArchiveDuration sets the duration after which a thread is archived after
+// recent activity. Defaults to the default auto archive duration of the channel.
+func WithAutoArchiveDuration(d AutoArchiveDuration) ThreadSetting {
+	return func(s *ThreadSettings) {
+		s.AutoArchiveDuration = optional.NewInt(int(d))
+	}
+}
+
+// WithInvitable sets whether non-moderators can add other
+// non-moderators to a thread (private threads only).
+func WithInvitable(yes bool) ThreadSetting {
+	return func(s *ThreadSettings) {
+		s.Invitable = optional.NewBool(yes)
+	}
+}
+
+// WithThreadRateLimitPerUser sets the rate limit per user of a thread.
+// A rate limit of 0 disables it.
+func WithThreadRateLimitPerUser(rateLimit int) ThreadSetting {
+	return func(s *ThreadSettings) {
+		s.RateLimitPerUser = optional.NewInt(rateLimit)
+	}
+}
+
+// WithArchived sets whether a thread is archived.
+func WithArchived(yes bool) ThreadSetting {
+	return func(s *ThreadSettings) {
+		s.Archived = optional.NewBool(yes)
+	}
+}
+
+// WithLocked sets whether a thread is locked. Locked threads can only
+// be unarchived by members with the 'MANAGE_THREADS' permission.
+func WithLocked(yes bool) ThreadSetting {
+	return func(s *ThreadSettings) {
+		s.Locked = optional.NewBool(yes)
+	}
+}
+
+// Validate checks these settings against the constraints enforced by Discord
+// and returns an error describing every violated constraint, if any.
+func (s *ThreadSettings) Validate() error {
+	var problems []string
+
+	if s.Name != nil {
+		name, _ := s.Name.Value()
+		if l := utf8.RuneCountInString(name); l == 0 || l > MaxNameLength {
+			problems = append(problems, fmt.Sprintf("name must be between 1 and %d characters", MaxNameLength))
+		}
+	}
+
+	typ, typed := s.Type.Value()
+	if typed && !IsThread(Type(typ)) {
+		problems = append(problems, fmt.Sprintf("invalid thread type %d", typ))
+	}
+
+	if s.Invitable != nil && typed && Type(typ) != TypeGuildPrivateThread {
+		problems = append(problems, "invitable can only be set for private threads")
+	}
+
+	if d, ok := s.AutoArchiveDuration.Value(); ok {
+		switch AutoArchiveDuration(d) {
+		case AutoArchiveOneHour, AutoArchiveOneDay, AutoArchiveThreeDays, AutoArchiveOneWeek:
+		default:
+			problems = append(problems, fmt.Sprintf("invalid auto archive duration %d", d))
+		}
+	}
+
+	if rateLimit, ok := s.RateLimitPerUser.Value(); ok && (rateLimit < 0 || rateLimit > MaxRateLimitPerUser) {
+		problems = append(problems, fmt.Sprintf("rate limit per user must be between 0 and %d", MaxRateLimitPerUser))
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid thread settings: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
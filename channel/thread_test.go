@@ -0,0 +1,35 @@
+package channel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skwair/harmony/optional"
+)
+
+func TestThreadSettingsValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings *ThreadSettings
+		valid    bool
+	}{
+		{name: "empty", settings: NewThreadSettings(), valid: true},
+		{name: "name", settings: NewThreadSettings(WithThreadName("thread")), valid: true},
+		{name: "literal name too long", settings: &ThreadSettings{Name: optional.NewString(strings.Repeat("a", MaxNameLength+1))}, valid: false},
+		{name: "private thread", settings: NewThreadSettings(WithThreadType(TypeGuildPrivateThread), WithInvitable(true)), valid: true},
+		{name: "not a thread", settings: NewThreadSettings(WithThreadType(TypeGuildText)), valid: false},
+		{name: "literal not a thread", settings: &ThreadSettings{Type: optional.NewInt(int(TypeGuildVoice))}, valid: false},
+		{name: "invitable public thread", settings: NewThreadSettings(WithThreadType(TypeGuildPublicThread), WithInvitable(true)), valid: false},
+		{name: "auto archive duration", settings: NewThreadSettings(WithAutoArchiveDuration(AutoArchiveOneWeek)), valid: true},
+		{name: "invalid auto archive duration", settings: &ThreadSettings{AutoArchiveDuration: optional.NewInt(5)}, valid: false},
+		{name: "rate limit out of range", settings: &ThreadSettings{RateLimitPerUser: optional.NewInt(MaxRateLimitPerUser + 1)}, valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.settings.Validate(); (err == nil) != tt.valid {
+				t.Errorf("expected settings to be valid: %t, got error: %v", tt.valid, err)
+			}
+		})
+	}
+}
@@ -10,8 +10,10 @@ import (
 	"time"
 
 	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/component"
 	"github.com/skwair/harmony/embed"
 	"github.com/skwair/harmony/internal/endpoint"
+	"github.com/skwair/harmony/internal/pagination"
 	"github.com/skwair/harmony/message"
 )
 
@@ -60,11 +62,127 @@ type Message struct {
 	WebhookID       string               `json:"webhook_id"`
 	Type            message.Type         `json:"type"`
 
+	// Interactive components of the message, such as buttons.
+	Components []component.ActionRow `json:"components"`
+	// Stickers sent with the message.
+	StickerItems []StickerItem `json:"sticker_items"`
+
 	// Sent with Rich Presence-related chat embeds.
 	Activity         *MessageActivity    `json:"activity"`
 	Application      *MessageApplication `json:"application"`
 	MessageReference *message.Reference  `json:"message_reference"`
 	Flags            message.Flag        `json:"flags"`
+
+	// Set for messages of type message.TypeRoleSubscriptionPurchase.
+	RoleSubscriptionData *message.RoleSubscriptionData `json:"role_subscription_data"`
+	// Set if the message is the result of an interaction.
+	InteractionMetadata *MessageInteractionMetadata `json:"interaction_metadata"`
+	// Interaction is the legacy version of InteractionMetadata. It is still
+	// useful because it holds the name of the command that generated the
+	// message, which InteractionMetadata does not.
+	Interaction *MessageInteraction `json:"interaction"`
+
+	// Fields received from the Gateway that are not known by
+	// Harmony, only set if WithRawExtra is enabled.
+	RawExtra RawExtra `json:"-"`
+}
+
+// ApplicationIntegrationType describes where an application can be installed.
+type ApplicationIntegrationType string
+
+const (
+	// ApplicationIntegrationTypeGuildInstall means the
+	// application is installed in a guild.
+	ApplicationIntegrationTypeGuildInstall ApplicationIntegrationType = "0"
+	// ApplicationIntegrationTypeUserInstall means the
+	// application is installed on a user.
+	ApplicationIntegrationTypeUserInstall ApplicationIntegrationType = "1"
+)
+
+// MessageInteractionMetadata holds information about the interaction
+// that generated a message.
+type MessageInteractionMetadata struct {
+	// ID of the interaction.
+	ID string `json:"id"`
+	// Type of the interaction.
+	Type InteractionType `json:"type"`
+	// User who triggered the interaction.
+	User *User `json:"user"`
+	// AuthorizingIntegrationOwners maps installation contexts that the
+	// interaction was authorized for to the ID of the guild or user that
+	// installed the application. See AuthorizingGuildID and AuthorizingUserID.
+	AuthorizingIntegrationOwners map[ApplicationIntegrationType]string `json:"authorizing_integration_owners"`
+	// ID of the original response message, only set on follow-up messages.
+	OriginalResponseMessageID string `json:"original_response_message_id"`
+
+	// Following fields are only set for some types of interactions.
+
+	// ID of the message that contained the interactive component,
+	// for message component interactions.
+	InteractedMessageID string `json:"interacted_message_id"`
+	// User the command was run on, for user context menu commands.
+	TargetUser *User `json:"target_user"`
+	// ID of the message the command was run on, for message
+	// context menu commands.
+	TargetMessageID string `json:"target_message_id"`
+	// Metadata of the interaction that opened the modal,
+	// for modal submit interactions.
+	TriggeringInteractionMetadata *MessageInteractionMetadata `json:"triggering_interaction_metadata"`
+}
+
+// AuthorizingGuildID returns the ID of the guild the application was installed
+// in that authorized the interaction, or an empty string if it was not
+// authorized by a guild installation.
+func (m *MessageInteractionMetadata) AuthorizingGuildID() string {
+	return m.AuthorizingIntegrationOwners[ApplicationIntegrationTypeGuildInstall]
+}
+
+// AuthorizingUserID returns the ID of the user the application was installed
+// on that authorized the interaction, or an empty string if it was not
+// authorized by a user installation.
+func (m *MessageInteractionMetadata) AuthorizingUserID() string {
+	return m.AuthorizingIntegrationOwners[ApplicationIntegrationTypeUserInstall]
+}
+
+// IsFollowup returns whether the message is a follow-up message and not the
+// original response to the interaction.
+func (m *MessageInteractionMetadata) IsFollowup() bool {
+	return m.OriginalResponseMessageID != ""
+}
+
+// MessageInteraction is the legacy version of MessageInteractionMetadata.
+type MessageInteraction struct {
+	// ID of the interaction.
+	ID string `json:"id"`
+	// Type of the interaction.
+	Type InteractionType `json:"type"`
+	// Name of the application command, including
+	// subcommands and subcommand groups.
+	Name string `json:"name"`
+	// User who triggered the interaction.
+	User *User `json:"user"`
+}
+
+// TriggeringUser returns the user that triggered the interaction that produced
+// this message, or nil if this message was not produced by an interaction.
+func (m *Message) TriggeringUser() *User {
+	switch {
+	case m.InteractionMetadata != nil:
+		return m.InteractionMetadata.User
+	case m.Interaction != nil:
+		return m.Interaction.User
+	default:
+		return nil
+	}
+}
+
+// CommandName returns the name of the application command that produced this
+// message, or an empty string if it was not produced by an application command.
+func (m *Message) CommandName() string {
+	if m.Interaction == nil || m.Interaction.Type != InteractionTypeApplicationCommand {
+		return ""
+	}
+	return m.Interaction.Name
 }
 
 // Messages returns messages in the channel. If operating on a guild channel, this
@@ -102,6 +220,11 @@ func (r *ChannelResource) Messages(ctx context.Context, query string, limit int)
 		q.Set("limit", strconv.Itoa(limit))
 	}
 
+	return r.messages(ctx, q)
+}
+
+// messages fetches a page of messages of the channel.
+func (r *ChannelResource) messages(ctx context.Context, q url.Values) ([]Message, error) {
 	e := endpoint.GetChannelMessages(r.channelID, q.Encode())
 	resp, err := r.client.doReq(ctx, e, nil)
 	if err != nil {
@@ -223,6 +346,27 @@ func WithFiles(files ...*File) MessageOption {
 	})
 }
 
+// WithComponents sets the interactive components of a message, such as buttons
+// or select menus. Users interacting with them trigger interactions, see
+// Client.OnMessageComponent. When editing a message, giving no rows removes
+// all components from the message.
+func WithComponents(rows ...component.ActionRow) MessageOption {
+	return MessageOption(func(m *createMessage) {
+		if rows == nil {
+			rows = []component.ActionRow{}
+		}
+		m.Components = &rows
+	})
+}
+
+// WithStickers sets the stickers of a message, up to 3. Guild stickers
+// can only be sent in their guild.
+func WithStickers(stickerIDs ...string) MessageOption {
+	return MessageOption(func(m *createMessage) {
+		m.StickerIDs = stickerIDs
+	})
+}
+
 // WithTTS enables text to speech for a message.
 func WithTTS() MessageOption {
 	return MessageOption(func(m *createMessage) {
@@ -230,6 +374,29 @@ func WithTTS() MessageOption {
 	})
 }
 
+// WithReply makes a message a reply to the message with the given ID, in the
+// same channel. If failIfNotExists is false and the message does not exist,
+// a regular message is sent instead of failing.
+// By default, replies mention the author of the original message, use
+// WithAllowedMentions to prevent it.
+func WithReply(messageID string, failIfNotExists bool) MessageOption {
+	return MessageOption(func(m *createMessage) {
+		m.MessageReference = &message.Reference{
+			MessageID:       messageID,
+			FailIfNotExists: &failIfNotExists,
+		}
+	})
+}
+
+// WithAllowedMentions sets which mentions in the content of a message actually
+// notify users, for instance to prevent content provided by users from
+// mentioning everyone. See message.NoMentions.
+func WithAllowedMentions(am *message.AllowedMentions) MessageOption {
+	return MessageOption(func(m *createMessage) {
+		m.AllowedMentions = am
+	})
+}
+
 // WithNonce sets the nonce of a message.
 // The nonce will be returned in the result and also transmitted to other clients.
 func WithNonce(n string) MessageOption {
@@ -251,7 +418,8 @@ func (r *ChannelResource) Send(ctx context.Context, opts ...MessageOption) (*Mes
 		opt(&msg)
 	}
 
-	if msg.Content == "" && msg.Embed == nil && len(msg.files) == 0 {
+	if msg.Content == "" && msg.Embed == nil && len(msg.files) == 0 && len(msg.StickerIDs) == 0 &&
+		(msg.Components == nil || len(*msg.Components) == 0) {
 		return nil, ErrInvalidSend
 	}
 
@@ -270,6 +438,12 @@ type createMessage struct {
 	TTS     bool         `json:"tts,omitempty"`
 	Embed   *embed.Embed `json:"embed,omitempty"`
 
+	Components *[]component.ActionRow `json:"components,omitempty"`
+	StickerIDs []string               `json:"sticker_ids,omitempty"`
+
+	MessageReference *message.Reference       `json:"message_reference,omitempty"`
+	AllowedMentions  *message.AllowedMentions `json:"allowed_mentions,omitempty"`
+
 	files []File
 }
 
@@ -280,8 +454,13 @@ func (cm *createMessage) json() ([]byte, error) {
 }
 
 func (c *Client) sendMessage(ctx context.Context, channelID string, msg *createMessage) (*Message, error) {
-	if msg.Embed != nil && msg.Embed.Type == "" {
-		msg.Embed.Type = "rich"
+	if msg.Embed != nil {
+		if err := msg.Embed.Validate(); err != nil {
+			return nil, err
+		}
+		if msg.Embed.Type == "" {
+			msg.Embed.Type = "rich"
+		}
 	}
 
 	var payload *requestPayload
@@ -318,23 +497,49 @@ func (c *Client) sendMessage(ctx context.Context, channelID string, msg *createM
 }
 
 type editMessage struct {
-	Content string       `json:"content,omitempty"`
-	Embed   *embed.Embed `json:"embed,omitempty"`
+	Content         string                   `json:"content,omitempty"`
+	Embed           *embed.Embed             `json:"embed,omitempty"`
+	Components      *[]component.ActionRow   `json:"components,omitempty"`
+	AllowedMentions *message.AllowedMentions `json:"allowed_mentions,omitempty"`
 }
 
 // EditMessage edits a previously sent message. You can only edit messages that have
 // been sent by the current user. Fires a Message Update Gateway event. See EditEmbed
 // if you need to edit some emended content.
 func (r *ChannelResource) EditMessage(ctx context.Context, messageID, content string) (*Message, error) {
-	return r.client.editMessage(ctx, r.channelID, messageID, &editMessage{content, nil})
+	return r.client.editMessage(ctx, r.channelID, messageID, &editMessage{Content: content})
 }
 
 // EditEmbed is like EditMessage but with embedded content support.
 func (r *ChannelResource) EditEmbed(ctx context.Context, messageID, content string, embed *embed.Embed) (*Message, error) {
-	return r.client.editMessage(ctx, r.channelID, messageID, &editMessage{content, embed})
+	return r.client.editMessage(ctx, r.channelID, messageID, &editMessage{Content: content, Embed: embed})
+}
+
+// Edit is like EditMessage but accepts the same options as Send, except for
+// files, TTS, nonce and reply which can not be edited. Only the given options
+// are modified, for instance WithComponents alone only updates components.
+func (r *ChannelResource) Edit(ctx context.Context, messageID string, opts ...MessageOption) (*Message, error) {
+	var msg createMessage
+	for _, opt := range opts {
+		opt(&msg)
+	}
+
+	edit := &editMessage{
+		Content:         msg.Content,
+		Embed:           msg.Embed,
+		Components:      msg.Components,
+		AllowedMentions: msg.AllowedMentions,
+	}
+	return r.client.editMessage(ctx, r.channelID, messageID, edit)
 }
 
 func (c *Client) editMessage(ctx context.Context, channelID, messageID string, edit *editMessage) (*Message, error) {
+	if edit.Embed != nil {
+		if err := edit.Embed.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	b, err := json.Marshal(edit)
 	if err != nil {
 		return nil, err
@@ -391,18 +596,12 @@ type Reaction struct {
 // If set to 0, it defaults to 25. If more than 100 users reacted with the given emoji,
 // the before and after parameters can be used to fetch more users.
 func (r *ChannelResource) Reactions(ctx context.Context, messageID, emoji string, limit int, before, after string) ([]User, error) {
-	q := url.Values{}
-	if limit > 0 {
-		q.Set("limit", strconv.Itoa(limit))
-	}
-	if before != "" {
-		q.Set("before", before)
-	}
-	if after != "" {
-		q.Set("after", after)
-	}
+	return r.reactions(ctx, messageID, emoji, pagination.Cursor{Before: before, After: after, Limit: limit})
+}
 
-	e := endpoint.GetReactions(r.channelID, messageID, url.PathEscape(emoji), q.Encode())
+// reactions fetches a page of users that reacted to a message with the given emoji.
+func (r *ChannelResource) reactions(ctx context.Context, messageID, emoji string, c pagination.Cursor) ([]User, error) {
+	e := endpoint.GetReactions(r.channelID, messageID, url.PathEscape(emoji), c.Values().Encode())
 	resp, err := r.client.doReq(ctx, e, nil)
 	if err != nil {
 		return nil, err
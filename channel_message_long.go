@@ -0,0 +1,167 @@
+package harmony
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxMessageLength is the maximum number of characters in the content of a message.
+const MaxMessageLength = 2000
+
+// codeFence delimits code blocks in messages.
+const codeFence = "```"
+
+// LongMessageOption allows to customize how SendLong sends long content.
+type LongMessageOption func(*longMessage)
+
+type longMessage struct {
+	fileThreshold int
+	fileName      string
+}
+
+// WithFileThreshold makes SendLong upload the content as a text file attachment
+// with the given name instead of splitting it, when it is longer than threshold
+// characters. If name is empty, it defaults to "message.txt".
+func WithFileThreshold(threshold int, name string) LongMessageOption {
+	return func(m *longMessage) {
+		if name == "" {
+			name = "message.txt"
+		}
+		m.fileThreshold = threshold
+		m.fileName = name
+	}
+}
+
+// SendLong sends content that can exceed MaxMessageLength to the channel. The content
+// is split into multiple messages, preferably at paragraph, line or word boundaries.
+// Code blocks that span multiple messages are closed at the end of a message and
+// reopened, with the same language, at the beginning of the next one.
+// Messages are sent in order and those successfully sent are returned, even
+// if an error occurs. See Send for the required permissions.
+func (r *ChannelResource) SendLong(ctx context.Context, content string, opts ...LongMessageOption) ([]Message, error) {
+	var m longMessage
+	for _, opt := range opts {
+		opt(&m)
+	}
+
+	if content == "" {
+		return nil, ErrInvalidSend
+	}
+
+	if m.fileThreshold > 0 && utf8.RuneCountInString(content) > m.fileThreshold {
+		f := FileFromReadCloser(ioutil.NopCloser(strings.NewReader(content)), m.fileName)
+		msg, err := r.Send(ctx, WithFiles(f))
+		if err != nil {
+			return nil, err
+		}
+		return []Message{*msg}, nil
+	}
+
+	var msgs []Message
+	for _, chunk := range splitMessage(content, MaxMessageLength) {
+		msg, err := r.SendMessage(ctx, chunk)
+		if err != nil {
+			return msgs, err
+		}
+		msgs = append(msgs, *msg)
+	}
+	return msgs, nil
+}
+
+// splitMessage splits the given content into chunks of at most max characters.
+func splitMessage(content string, max int) []string {
+	var (
+		chunks []string
+		// Opening line of a code block the next chunk continues, if any.
+		reopen string
+	)
+	for {
+		text := reopen + content
+		if utf8.RuneCountInString(text) <= max {
+			return append(chunks, text)
+		}
+
+		// Always leave room to close a code block.
+		limit := max - len("\n"+codeFence)
+		cut, skip := splitIndex(text, limit, len(reopen))
+
+		// Do not split right after the opening line of a code block, it would be empty.
+		line := strings.LastIndexByte(text[:cut], '\n') + 1
+		if open := unclosedCodeBlock(text[:cut]); open != "" && open == strings.TrimSpace(text[line:cut]) {
+			if line-1 > len(reopen) {
+				cut, skip = line-1, 1
+			} else {
+				cut, skip = splitIndex(text, limit, cut)
+			}
+		}
+		chunk := text[:cut]
+
+		reopen = ""
+		if open := unclosedCodeBlock(chunk); open != "" {
+			chunk += "\n" + codeFence
+			reopen = open + "\n"
+		}
+		chunks = append(chunks, chunk)
+		content = text[cut+skip:]
+
+		// Do not reopen a code block only to close it right away.
+		if reopen != "" {
+			line := content
+			if i := strings.IndexByte(content, '\n'); i >= 0 {
+				line = content[:i]
+			}
+			if strings.TrimSpace(line) == codeFence {
+				content = strings.TrimPrefix(content[len(line):], "\n")
+				reopen = ""
+			}
+		}
+		if content == "" {
+			return chunks
+		}
+	}
+}
+
+// splitIndex returns the byte index at which text should be split to have a
+// first part of at most max characters, and the number of separator bytes to
+// skip after it. The index is always greater than min to ensure progress.
+func splitIndex(text string, max, min int) (index, skip int) {
+	limit := len(text)
+	n := 0
+	for i := range text {
+		if n == max {
+			limit = i
+			break
+		}
+		n++
+	}
+	window := text[:limit]
+
+	for _, sep := range []string{"\n\n", "\n", " "} {
+		if i := strings.LastIndex(window, sep); i > min {
+			return i, len(sep)
+		}
+	}
+	return limit, 0
+}
+
+// unclosedCodeBlock returns the opening line (e.g. "```go") of the
+// code block left open at the end of text, or "" if there is none.
+func unclosedCodeBlock(text string) string {
+	var open string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, codeFence) {
+			continue
+		}
+		switch {
+		case open != "":
+			open = ""
+		case strings.Count(line, codeFence) == 1:
+			// Single line blocks such as "```code```" do not open a block.
+			open = line
+		}
+	}
+	return open
+}
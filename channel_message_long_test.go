@@ -0,0 +1,94 @@
+package harmony
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{name: "short", content: "hello", expected: []string{"hello"}},
+		{
+			name:     "paragraphs",
+			content:  "aaaa bbbb\n\ncccc dddd eeee",
+			expected: []string{"aaaa bbbb", "cccc dddd eeee"},
+		},
+		{
+			name:     "lines",
+			content:  "aaaa bbbb\ncccc dddd eeee",
+			expected: []string{"aaaa bbbb", "cccc dddd eeee"},
+		},
+		{
+			name:     "words",
+			content:  "aaaa bbbb cccc dddd eeee",
+			expected: []string{"aaaa bbbb cccc", "dddd eeee"},
+		},
+		{
+			name:     "no separator",
+			content:  strings.Repeat("a", 25),
+			expected: []string{strings.Repeat("a", 16), strings.Repeat("a", 9)},
+		},
+		{
+			name:     "multi-byte characters",
+			content:  "héllo wörld ünïcode ßtring",
+			expected: []string{"héllo wörld", "ünïcode ßtring"},
+		},
+		{
+			name:     "code block",
+			content:  "```go\nfunc a() {}\nfunc b() {}\n```\nafter",
+			expected: []string{"```go\nfunc a()\n```", "```go\n{}\n```", "```go\nfunc b()\n```", "```go\n{}\n```\nafter"},
+		},
+		{
+			name:     "code block opened at the end",
+			content:  "intro\n```\nline one\nline two\n```",
+			expected: []string{"intro", "```\nline one\n```", "```\nline two\n```"},
+		},
+		{
+			name:     "single line code",
+			content:  "```aaaa``` bbbb cccc dddd",
+			expected: []string{"```aaaa``` bbbb", "cccc dddd"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := splitMessage(tt.content, 20)
+			if !reflect.DeepEqual(chunks, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, chunks)
+			}
+			for _, chunk := range chunks {
+				if n := utf8.RuneCountInString(chunk); n > 20 {
+					t.Errorf("expected chunks of at most 20 characters, got %d: %q", n, chunk)
+				}
+				if open := unclosedCodeBlock(chunk); open != "" {
+					t.Errorf("expected code blocks to be closed, got %q", chunk)
+				}
+			}
+		})
+	}
+}
+
+func TestUnclosedCodeBlock(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "no code", expected: ""},
+		{text: "```go\ncode", expected: "```go"},
+		{text: "```go\ncode\n```", expected: ""},
+		{text: "```code```", expected: ""},
+		{text: "```\nfirst\n```\ntext\n  ```py\nsecond", expected: "```py"},
+	}
+
+	for _, tt := range tests {
+		if open := unclosedCodeBlock(tt.text); open != tt.expected {
+			t.Errorf("expected %q for %q, got %q", tt.expected, tt.text, open)
+		}
+	}
+}
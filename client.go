@@ -3,6 +3,7 @@ package harmony
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"sync"
@@ -11,14 +12,18 @@ import (
 	"go.uber.org/atomic"
 	"nhooyr.io/websocket"
 
+	"github.com/skwair/harmony/clock"
+	"github.com/skwair/harmony/eventfilter"
+	"github.com/skwair/harmony/internal/breaker"
 	"github.com/skwair/harmony/internal/payload"
 	"github.com/skwair/harmony/internal/rate"
 	"github.com/skwair/harmony/log"
+	"github.com/skwair/harmony/ratelimit"
 	"github.com/skwair/harmony/voice"
 )
 
 const (
-	defaultBaseURL        = "https://discord.com/api/v6"
+	defaultBaseURL        = "https://discord.com/api/v9"
 	defaultLargeThreshold = 250
 )
 
@@ -44,24 +49,49 @@ type Client struct {
 	// User-Agent when sending HTTP request.
 	// It defaults to "Harmony".
 	name string
+	// See WithUserAgentSuffix for more information.
+	userAgentSuffix string
+	// See WithDefaultHeaders for more information.
+	defaultHeader http.Header
 
 	// Authentication token used to interact with
 	// Discord's API.
 	token string
+	// Whether token is an OAuth2 bearer token and the scopes
+	// granted to it. See WithBearerToken.
+	bearer bool
+	scopes map[string]struct{}
 
 	gatewayURL string
 	baseURL    string // Base URL of the Discord API.
+	// HTTP client used to dial Gateway and voice websockets,
+	// nil to use http.DefaultClient. See WithProxy.
+	dialClient *http.Client
 
 	// Underlying HTTP client used to call Discord's REST API.
 	client *http.Client
 
 	// Rate limiter used to throttle outgoing HTTP requests.
 	limiter *rate.Limiter
+	// Where the state of the rate limiter is persisted,
+	// nil if it is not. See WithRateLimitStore.
+	rateLimitStore ratelimit.Store
+	// Circuit breaker failing requests fast during outages,
+	// nil if disabled. See WithCircuitBreaker.
+	breaker          *breaker.Breaker
+	breakerThreshold int
+	breakerCooldown  time.Duration
+	// UNIX timestamp in nanoseconds until which requests are not
+	// sent because Cloudflare banned the client, see OnCloudflareBan.
+	bannedUntil *atomic.Int64
 
 	// Underlying websocket used to communicate with
 	// Discord's real-time API.
 	conn    *websocket.Conn
 	connRMu sync.Mutex // Read mutex.
+	// Decompresses payloads received on conn, nil
+	// if compression is disabled. See WithCompression.
+	inflater *payload.Inflater
 
 	// Whether the client is currently connecting to the Gateway.
 	connecting *atomic.Bool
@@ -74,17 +104,22 @@ type Client struct {
 
 	// When connectingToVoice is true, some
 	// payloads received by the event handler will
-	// be sent through this channel.
+	// be sent through this channel. It is buffered
+	// so the event handler never blocks on it.
 	voicePayloads chan *payload.Payload
 
 	// See WithLargeThreshold for more information.
 	largeThreshold int
 	// See WithSharding for more information.
 	shard [2]int
-	// See WithGuildSubscriptions for more information.
-	guildSubscriptions bool
+	// See WithCompression for more information.
+	compression bool
 	// See WithGatewayIntents for more information.
 	intents GatewayIntent
+	// See WithInitialPresence for more information.
+	initialPresence *Status
+	// See WithEventFilter for more information.
+	eventFilter *eventfilter.Filter
 
 	userID    string
 	sessionID string
@@ -92,12 +127,22 @@ type Client struct {
 	// Sequence number of the last Dispatch event
 	// we received from the Gateway.
 	sequence *atomic.Int64
+	// See WithEventDeduplication for more information.
+	eventDeduplication bool
+	// Session and sequence number of the last Dispatch
+	// event handled, used to drop events replayed by the
+	// Gateway. Only accessed by the goroutine reading
+	// Gateway payloads.
+	lastDispatchedSession string
+	lastDispatchedSeq     int64
 	// UNIX timestamp in nanoseconds of the last
 	// heartbeat acknowledgement.
 	lastHeartbeatACK *atomic.Int64
 	// UNIX timestamp in nanoseconds of the last
 	// heartbeat send. Used to calculate RTT.
 	lastHeartbeatSend *atomic.Int64
+	// RTT of the last acknowledged heartbeat, in nanoseconds.
+	latency *atomic.Int64
 
 	// wg keeps track of all goroutines necessary to
 	// maintain a connection to the Gateway.
@@ -121,20 +166,98 @@ type Client struct {
 	// Registered event handlers for this Client.
 	handlersMu sync.RWMutex
 	handlers   map[string]handler
+	// See UseMiddleware.
+	middlewares []Middleware
+	// Channels returned by Events.
+	eventStreams []eventStream
+	// See Subscribe.
+	subscriptions []*Subscription
+	// Parent context of all contexts of the client,
+	// see WithContext.
+	baseCtx context.Context
+	// Context passed to event handlers, canceled on Disconnect.
+	handlersCtx    context.Context
+	cancelHandlers context.CancelFunc
 
 	// Backoff strategy used when trying to reconnect to
 	// the Gateway after an error.
 	backoff backoff
+	// See WithReconnectPolicy.
+	maxReconnectAttempts int
+	onReconnectAttempt   func(attempt int, err error) bool
+	onReconnectGiveUp    func(err error)
 
 	// If true (the default value), the State
 	// will be populated and updated as events
 	// are received from the Discord Gateway.
 	withStateTracking bool
+	stateBackend      StateBackend
 	State             *State
+	// See WithStateInterning.
+	stateInterning bool
+	// See WithMessageCacheSize.
+	messageCacheSize int
+	// See WithMemberChunking for more information.
+	memberChunking bool
+
+	// Pending Request Guild Members by nonce,
+	// see RequestGuildMembers.
+	memberRequestsMu  sync.Mutex
+	memberRequests    map[string]*memberRequest
+	memberRequestsSeq int64
 
 	// voice connections that were established by
-	// this client.
-	voiceConnections map[string]*voice.Connection
+	// this client. It is only modified with both mu
+	// and voiceConnectionsMu held, so holding either
+	// one of them is enough to read it.
+	voiceConnectionsMu sync.RWMutex
+	voiceConnections   map[string]*voice.Connection
+	// Closing one of those channels stops the goroutine
+	// watching the voice connection of the corresponding
+	// guild for automatic disconnection.
+	voiceWatchersMu sync.Mutex
+	voiceWatchers   map[string]chan struct{}
+
+	// Counts of events and opcodes received from the
+	// Gateway that are not supported, and of events
+	// dropped by the event filter or because a channel
+	// returned by Events was full. See Stats.
+	statsMu        sync.Mutex
+	unknownEvents  map[string]uint64
+	unknownOpcodes map[int]uint64
+	filteredEvents map[string]uint64
+	droppedEvents  map[string]uint64
+	// Retry budgets of the current and previous minutes.
+	retryBudget     RetryBudget
+	lastRetryBudget RetryBudget
+	// See WithRetryBudgetHandler.
+	onRetryBudget func(RetryBudget)
+
+	// See WithPlugins for more information.
+	plugins        []Plugin
+	pluginsMu      sync.Mutex
+	pluginsStarted bool
+
+	// See WithPayloadDumpDir for more information.
+	payloadDumpDir string
+	// See WithRawExtra for more information.
+	rawExtra bool
+
+	// See WithErrorReporter for more information.
+	errorReporter ErrorReporter
+
+	// See WithMetricsCollector for more information.
+	metrics MetricsCollector
+
+	// See WithPayloadLogging for more information.
+	payloadLogging *payloadLogging
+
+	// See WithErrorMessages for more information.
+	errorMessages *ErrorMessages
+
+	// Clock used for all internal timing (heartbeats,
+	// backoff, rate limiting, etc.). See WithClock.
+	clock clock.Clock
 
 	logger log.Logger
 }
@@ -142,9 +265,9 @@ type Client struct {
 // NewClient creates a new client to work with Discord's API.
 // It is meant to be long lived and shared across your application.
 // The token is automatically prefixed with "Bot ", which is a requirement
-// by Discord for bot users. Automated normal user accounts (generally called
-// "self-bots"), are not supported. To customize a Client, refer to available
-// ClientOption.
+// by Discord for bot users, unless WithBearerToken is used. Automated normal
+// user accounts (generally called "self-bots"), are not supported. To customize
+// a Client, refer to available ClientOption.
 func NewClient(token string, opts ...ClientOption) (*Client, error) {
 	if token == "" {
 		return nil, errors.New("harmony: a token is mandatory to create a client")
@@ -155,18 +278,28 @@ func NewClient(token string, opts ...ClientOption) (*Client, error) {
 		token:              "Bot " + token,
 		baseURL:            defaultBaseURL,
 		client:             http.DefaultClient,
-		limiter:            rate.NewLimiter(),
 		largeThreshold:     defaultLargeThreshold,
-		guildSubscriptions: true,
+		compression:        true,
 		intents:            GatewayIntentUnprivileged,
 		handlers:           make(map[string]handler),
 		backoff:            defaultBackoff,
 		withStateTracking:  true,
+		eventDeduplication: true,
+		memberRequests:     make(map[string]*memberRequest),
 		voiceConnections:   make(map[string]*voice.Connection),
+		voiceWatchers:      make(map[string]chan struct{}),
+		unknownEvents:      make(map[string]uint64),
+		filteredEvents:     make(map[string]uint64),
+		droppedEvents:      make(map[string]uint64),
+		unknownOpcodes:     make(map[int]uint64),
 		logger:             log.NewStd(os.Stderr, log.LevelError),
+		payloadLogging:     newPayloadLogging(log.LevelDebug, false),
+		clock:              clock.New(),
 		sequence:           atomic.NewInt64(0),
 		lastHeartbeatSend:  atomic.NewInt64(0),
 		lastHeartbeatACK:   atomic.NewInt64(0),
+		latency:            atomic.NewInt64(0),
+		bannedUntil:        atomic.NewInt64(0),
 		connected:          atomic.NewBool(false),
 		connecting:         atomic.NewBool(false),
 		connectingToVoice:  atomic.NewBool(false),
@@ -177,9 +310,54 @@ func NewClient(token string, opts ...ClientOption) (*Client, error) {
 		opt(c)
 	}
 
+	if c.bearer {
+		c.token = "Bearer " + token
+	}
+
+	c.limiter = rate.NewLimiter(c.clock)
+	if c.rateLimitStore != nil {
+		c.restoreRateLimits()
+	}
+	if c.baseCtx == nil {
+		c.baseCtx = context.Background()
+	}
+	c.handlersCtx, c.cancelHandlers = context.WithCancel(c.baseCtx)
+	if c.breakerThreshold > 0 {
+		c.breaker = breaker.New(c.clock, c.breakerThreshold, c.breakerCooldown)
+	}
+
 	if c.withStateTracking {
-		c.State = newState()
+		if c.stateBackend == nil {
+			c.stateBackend = NewMemoryStateBackend()
+		}
+		if b, ok := c.stateBackend.(FallibleStateBackend); ok {
+			b.SetErrorHandler(c.stateBackendError)
+		}
+		c.State = newState(c.stateBackend, c.stateInterning)
+		if c.messageCacheSize > 0 {
+			c.State.messages = newMessageCache(c.messageCacheSize, c.intents&GatewayIntentMessageContent != 0)
+		}
+	}
+
+	if err := c.initPlugins(); err != nil {
+		return nil, fmt.Errorf("harmony: %w", err)
+	}
+
+	if c.baseCtx.Done() != nil {
+		go c.disconnectOnDone()
 	}
 
 	return c, nil
 }
+
+// Context returns the parent context of the client, see WithContext. Helpers
+// working in the background on behalf of the client, such as schedulers, stop
+// once it is done.
+func (c *Client) Context() context.Context {
+	return c.baseCtx
+}
+
+// Clock returns the clock used by the client, see WithClock.
+func (c *Client) Clock() clock.Clock {
+	return c.clock
+}
@@ -1,10 +1,16 @@
 package harmony
 
 import (
+	"context"
 	"net/http"
+	"net/url"
 	"time"
 
+	"github.com/skwair/harmony/clock"
+	"github.com/skwair/harmony/eventfilter"
+	"github.com/skwair/harmony/internal/proxy"
 	"github.com/skwair/harmony/log"
+	"github.com/skwair/harmony/ratelimit"
 )
 
 // ClientOption is a function that configures a Client.
@@ -20,6 +26,26 @@ func WithName(n string) ClientOption {
 	}
 }
 
+// WithUserAgentSuffix appends the given suffix to the User-Agent of HTTP
+// requests sent by the Client, for example to identify the deployment
+// or the team operating the bot.
+// Defaults to "", no suffix is added.
+func WithUserAgentSuffix(suffix string) ClientOption {
+	return func(c *Client) {
+		c.userAgentSuffix = suffix
+	}
+}
+
+// WithDefaultHeaders sets headers that are added to all HTTP requests sent
+// by the Client to Discord's REST API. Headers set by the Client itself,
+// such as Authorization or User-Agent, can not be overridden this way.
+// Defaults to no additional headers.
+func WithDefaultHeaders(h http.Header) ClientOption {
+	return func(c *Client) {
+		c.defaultHeader = h.Clone()
+	}
+}
+
 // WithHTTPClient can be used to specify the http.Client to use when making
 // HTTP requests to the Discord HTTP API.
 // Defaults to http.DefaultClient.
@@ -29,6 +55,34 @@ func WithHTTPClient(client *http.Client) ClientOption {
 	}
 }
 
+// WithCircuitBreaker enables a circuit breaker per family of REST routes, such as
+// "/channels/:id/messages". After threshold consecutive server errors (5xx responses,
+// including Cloudflare errors, or failed requests) for a family, requests to it fail
+// immediately with ErrCircuitOpen for the given cooldown, instead of waiting for
+// Discord to time out. A single request is then sent to probe recovery: if it
+// succeeds, requests are sent again, otherwise they keep failing fast for another
+// cooldown. This protects latency-sensitive code, such as interaction handlers,
+// during partial outages.
+// Defaults to disabled.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) ClientOption {
+	return func(c *Client) {
+		c.breakerThreshold = threshold
+		c.breakerCooldown = cooldown
+	}
+}
+
+// WithRateLimitStore sets the store the state of the REST rate limiter is saved to,
+// so it is restored when the client is created again, for instance after the
+// program restarted. This prevents bots restarting in a loop from sending requests
+// to rate limit buckets they already exhausted, which can get them temporarily
+// banned by Cloudflare. See the ratelimit package for more information.
+// Defaults to nil, the state of the rate limiter is not persisted.
+func WithRateLimitStore(s ratelimit.Store) ClientOption {
+	return func(c *Client) {
+		c.rateLimitStore = s
+	}
+}
+
 // WithBaseURL can be used to change de base URL of the API.
 // This is used for testing.
 // Deprecated.
@@ -48,21 +102,46 @@ func WithSharding(current, total int) ClientOption {
 	}
 }
 
-// WithGuildSubscriptions allows to set whether the client should identify to the Gateway with
-// guild subscription enabled or not. Guild subscriptions are guild member presence updates
-// and typing events.
-// Defaults to true.
-// While not deprecated, Guild Subscriptions have been superseded by Gateway Intents. It is recommended
-// to use WithGatewayIntents for better results.
+// WithGuildSubscriptions has no effect.
+//
+// Deprecated: guild subscriptions were removed from the Gateway in favor of
+// Gateway Intents. Use WithGatewayIntents without GatewayIntentGuildPresences
+// and GatewayIntentGuildMessageTyping instead.
 func WithGuildSubscriptions(y bool) ClientOption {
+	return func(c *Client) {}
+}
+
+// WithCompression allows to set whether the client receives Gateway payloads with
+// zlib-stream compression, which significantly reduces the bandwidth used by bots
+// in many guilds. When disabled, only large payloads are compressed.
+// Defaults to true.
+func WithCompression(y bool) ClientOption {
+	return func(c *Client) {
+		c.compression = y
+	}
+}
+
+// WithProxy allows to set the proxy the Gateway and voice websockets are dialed
+// through. The URL scheme can be http or https for proxies supporting the CONNECT
+// method, or socks5 for SOCKS5 proxies, with credentials in the user info of the
+// URL if required. A nil URL disables proxies. Voice data is still sent directly
+// over UDP, which these proxies do not support.
+// Requests to the HTTP API use the client set with WithHTTPClient.
+// Defaults to the proxy set by the HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+// environment variables, if any, see http.ProxyFromEnvironment.
+func WithProxy(u *url.URL) ClientOption {
 	return func(c *Client) {
-		c.guildSubscriptions = y
+		c.dialClient = proxy.Client(u)
 	}
 }
 
 // WithGatewayIntents allows to customize which Gateway Intents the client should subscribe to.
 // See https://discord.com/developers/docs/topics/gateway#gateway-intents for more information.
-// By default, the client subscribes to all unprivileged events.
+// By default, the client subscribes to all unprivileged events. Without GatewayIntentMessageContent,
+// messages received from the Gateway have no content, except direct messages and the ones
+// mentioning the current user.
+// Connect fails with ErrInvalidIntents if intents are invalid, and with a DisallowedIntentsError
+// if privileged intents are not enabled for the application of the bot.
 func WithGatewayIntents(i GatewayIntent) ClientOption {
 	return func(c *Client) {
 		c.intents = i
@@ -78,6 +157,77 @@ func WithStateTracking(y bool) ClientOption {
 	}
 }
 
+// WithStateInterning allows you to specify whether IDs and other frequently repeated
+// strings of objects stored in the State are interned, so equal strings share the
+// same memory. Since the same users, roles and guilds are referenced by many cached
+// objects, this significantly reduces the memory used by the State of bots in many
+// guilds, at the cost of a little more CPU when updating it. Interned strings are
+// kept for the lifetime of the client, even once the objects referencing them are
+// removed from the State. See State.SizeEstimate to measure the memory used by the
+// State. Defaults to false.
+func WithStateInterning(y bool) ClientOption {
+	return func(c *Client) {
+		c.stateInterning = y
+	}
+}
+
+// WithMessageCacheSize sets the number of messages the State keeps for each channel,
+// the most recent ones. Cached messages are updated by MESSAGE_UPDATE events, which
+// can be partial, and are given to OnMessageDelete and OnMessageDeleteBulk handlers,
+// so they can see the original content of deleted messages. Without the
+// GatewayIntentMessageContent intent, the content of most messages is empty. Messages
+// are always kept in memory, whatever the state backend. It has no effect if state
+// tracking is disabled. Defaults to 0, messages are not cached.
+func WithMessageCacheSize(n int) ClientOption {
+	return func(c *Client) {
+		c.messageCacheSize = n
+	}
+}
+
+// WithMemberChunking allows you to specify whether the client requests all members of
+// large guilds when they become available, so the State knows about all of them. By
+// default, the Gateway only sends members of guilds with less members than the large
+// threshold (see WithLargeThreshold). It requires the GatewayIntentGuildMembers intent,
+// presences are also requested if the GatewayIntentGuildPresences intent is set.
+// Defaults to false.
+func WithMemberChunking(y bool) ClientOption {
+	return func(c *Client) {
+		c.memberChunking = y
+	}
+}
+
+// WithEventFilter sets the filter dropping Gateway events before they are fully
+// decoded. Dropped events are neither passed to handlers nor used to update the
+// State, so filtering events such as GUILD_CREATE leaves the State incomplete.
+// READY, RESUMED and voice events the client relies on are never dropped.
+// Defaults to nil, all events are kept. See the eventfilter package.
+func WithEventFilter(f *eventfilter.Filter) ClientOption {
+	return func(c *Client) {
+		c.eventFilter = f
+	}
+}
+
+// WithStateBackend sets the backend storing the objects cached by the State,
+// for instance to share them between several processes. It has no effect if
+// state tracking is disabled.
+// Defaults to a backend storing objects in memory, see NewMemoryStateBackend.
+func WithStateBackend(b StateBackend) ClientOption {
+	return func(c *Client) {
+		c.stateBackend = b
+	}
+}
+
+// WithEventDeduplication allows you to specify whether Dispatch events replayed by
+// the Gateway after resuming a session are dropped instead of being handled again.
+// Events are identified by their session and sequence number. Disable it if you
+// want handlers to receive replayed events as they are sent by the Gateway.
+// Defaults to true.
+func WithEventDeduplication(y bool) ClientOption {
+	return func(c *Client) {
+		c.eventDeduplication = y
+	}
+}
+
 // WithLargeThreshold allows you to set the large threshold when connecting to the Gateway.
 // This threshold will dictate the number of offline guild members are returned with a guild.
 // See: https://discord.com/developers/docs/topics/gateway#request-guild-members for more details.
@@ -94,6 +244,17 @@ func WithLargeThreshold(t int) ClientOption {
 	}
 }
 
+// WithInitialPresence sets the presence the client has as soon as it identifies
+// to the Gateway, so it appears with the right status and activity right away
+// instead of appearing online first and being updated with
+// CurrentUserResource.SetStatus later.
+// Defaults to nil, the client appears online with no activity.
+func WithInitialPresence(status *Status) ClientOption {
+	return func(c *Client) {
+		c.initialPresence = status
+	}
+}
+
 // WithBackoffStrategy allows you to customize the backoff strategy used when trying
 // to reconnect to the Discord Gateway after an error occurred (such as a network
 // failure).
@@ -115,3 +276,35 @@ func WithLogger(l log.Logger) ClientOption {
 		c.logger = l
 	}
 }
+
+// WithClock can be used to set the clock used by the client for all of its
+// internal timing, such as heartbeats, reconnection backoff and rate limiting.
+// This is mostly useful in tests, to fast-forward time with a clock.Mock.
+// Defaults to the real clock, see clock.New.
+func WithClock(clk clock.Clock) ClientOption {
+	return func(c *Client) {
+		c.clock = clk
+	}
+}
+
+// WithContext sets the parent context of the client. Once it is canceled, the
+// client disconnects from the Gateway, leaving voice channels and stopping
+// plugins, stops trying to reconnect and can not be connected again. Contexts
+// passed to event handlers are derived from it. This makes the lifecycle of
+// the client follow the one of the application, for instance with errgroup.
+// Defaults to context.Background, the client is disconnected with Disconnect.
+func WithContext(ctx context.Context) ClientOption {
+	return func(c *Client) {
+		c.baseCtx = ctx
+	}
+}
+
+// WithErrorMessages sets the messages shown to users when responding to
+// interactions with InteractionResource.RespondError.
+// Defaults to no messages: user facing errors are shown with their fallback
+// message and other errors with DefaultInternalErrorMessage.
+func WithErrorMessages(m *ErrorMessages) ClientOption {
+	return func(c *Client) {
+		c.errorMessages = m
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Package clock defines the Clock interface Harmony uses for all of its internal
+timing (heartbeats, reconnection backoff, rate limiting, etc.), so it can be
+replaced in tests.
+
+New returns a Clock backed by the standard time package, which is what Harmony
+uses by default. NewMock returns a Clock whose time only moves forward when
+told to, making it possible to test time-dependent logic without waiting:
+
+	clk := clock.NewMock(time.Now())
+	client, err := harmony.NewClient(token, harmony.WithClock(clk))
+	// ...
+	clk.Add(2 * time.Minute) // Fires every timer and ticker due in the next 2 minutes.
+*/
+package clock
+
+import "time"
+
+// Clock gives access to the current time and allows to wait for some time
+// to pass.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Since returns the time elapsed since t.
+	Since(t time.Time) time.Duration
+	// Until returns the duration until t.
+	Until(t time.Time) time.Duration
+	// Sleep pauses the current goroutine for at least the duration d.
+	Sleep(d time.Duration)
+	// After waits for the duration to elapse and then sends the
+	// current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+	// NewTicker returns a new Ticker sending the current time
+	// on its channel every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker holds a channel that delivers ticks of a clock at intervals.
+type Ticker interface {
+	// C returns the channel on which ticks are delivered.
+	C() <-chan time.Time
+	// Stop turns off the ticker. It does not close the channel.
+	Stop()
+}
+
+// New returns a Clock backed by the standard time package.
+func New() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) Until(t time.Time) time.Duration        { return time.Until(t) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -0,0 +1,155 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Mock is a Clock whose time only changes when Add or Set is called.
+// It is meant to be used in tests. All methods are safe for concurrent use.
+type Mock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+}
+
+var _ Clock = (*Mock)(nil)
+
+// waiter is a pending timer or ticker of a Mock.
+type waiter struct {
+	at    time.Time
+	every time.Duration // Zero for one-shot timers.
+	c     chan time.Time
+}
+
+// NewMock returns a new Mock clock set to the given time.
+func NewMock(now time.Time) *Mock {
+	return &Mock{now: now}
+}
+
+// Now implements the Clock interface.
+func (m *Mock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.now
+}
+
+// Since implements the Clock interface.
+func (m *Mock) Since(t time.Time) time.Duration {
+	return m.Now().Sub(t)
+}
+
+// Until implements the Clock interface.
+func (m *Mock) Until(t time.Time) time.Duration {
+	return t.Sub(m.Now())
+}
+
+// Sleep implements the Clock interface. It blocks until the time of
+// the clock is moved forward by at least d.
+func (m *Mock) Sleep(d time.Duration) {
+	<-m.After(d)
+}
+
+// After implements the Clock interface.
+func (m *Mock) After(d time.Duration) <-chan time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w := &waiter{at: m.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.c <- m.now
+		return w.c
+	}
+	m.waiters = append(m.waiters, w)
+	return w.c
+}
+
+// NewTicker implements the Clock interface.
+func (m *Mock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w := &waiter{at: m.now.Add(d), every: d, c: make(chan time.Time, 1)}
+	m.waiters = append(m.waiters, w)
+	return &mockTicker{m: m, w: w}
+}
+
+// Add moves the time of the clock forward by d, firing every timer and
+// ticker that is due in order.
+func (m *Mock) Add(d time.Duration) {
+	m.Set(m.Now().Add(d))
+}
+
+// Set sets the time of the clock to t, firing every timer and ticker that
+// is due in order. Setting a time in the past does not fire anything.
+func (m *Mock) Set(t time.Time) {
+	for {
+		m.mu.Lock()
+		sort.Slice(m.waiters, func(i, j int) bool {
+			return m.waiters[i].at.Before(m.waiters[j].at)
+		})
+
+		if len(m.waiters) == 0 || m.waiters[0].at.After(t) {
+			m.now = t
+			m.mu.Unlock()
+			break
+		}
+
+		w := m.waiters[0]
+		fired := w.at
+		m.now = fired
+		if w.every > 0 {
+			w.at = w.at.Add(w.every)
+		} else {
+			m.waiters = m.waiters[1:]
+		}
+		m.mu.Unlock()
+
+		// Like time.Ticker, drop ticks for slow receivers.
+		select {
+		case w.c <- fired:
+		default:
+		}
+
+		// Give goroutines waiting on this timer
+		// a chance to run before moving on.
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Pending returns the number of timers and tickers waiting for the clock to
+// move forward. It is useful to make sure a goroutine is waiting on the clock
+// before calling Add.
+func (m *Mock) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.waiters)
+}
+
+type mockTicker struct {
+	m *Mock
+	w *waiter
+}
+
+func (t *mockTicker) C() <-chan time.Time {
+	return t.w.c
+}
+
+func (t *mockTicker) Stop() {
+	t.m.mu.Lock()
+	defer t.m.mu.Unlock()
+
+	for i, w := range t.m.waiters {
+		if w == t.w {
+			t.m.waiters = append(t.m.waiters[:i], t.m.waiters[i+1:]...)
+			return
+		}
+	}
+}
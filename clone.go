@@ -1,12 +1,19 @@
 package harmony
 
+import (
+	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/component"
+	"github.com/skwair/harmony/embed"
+	"github.com/skwair/harmony/message"
+)
+
 // Clone returns a clone of this User.
 func (u *User) Clone() *User {
 	if u == nil {
 		return nil
 	}
 
-	return &User{
+	user := &User{
 		ID:            u.ID,
 		Username:      u.Username,
 		Discriminator: u.Discriminator,
@@ -15,7 +22,16 @@ func (u *User) Clone() *User {
 		MFAEnabled:    u.MFAEnabled,
 		Verified:      u.Verified,
 		Email:         u.Email,
+		Banner:        u.Banner,
+		AccentColor:   u.AccentColor,
+	}
+
+	if u.AvatarDecorationData != nil {
+		decoration := *u.AvatarDecorationData
+		user.AvatarDecorationData = &decoration
 	}
+
+	return user
 }
 
 // Clone returns a clone of this Guild.
@@ -44,6 +60,13 @@ func (g *Guild) Clone() *Guild {
 		WidgetEnabled:               g.WidgetEnabled,
 		WidgetChannelID:             g.WidgetChannelID,
 		SystemChannelID:             g.SystemChannelID,
+		NSFWLevel:                   g.NSFWLevel,
+		PreferredLocale:             g.PreferredLocale,
+		MaxVideoChannelUsers:        g.MaxVideoChannelUsers,
+		SafetyAlertsChannelID:       g.SafetyAlertsChannelID,
+		HubType:                     g.HubType,
+		ApproximateMemberCount:      g.ApproximateMemberCount,
+		ApproximatePresenceCount:    g.ApproximatePresenceCount,
 		JoinedAt:                    g.JoinedAt,
 		Large:                       g.Large,
 		Unavailable:                 g.Unavailable,
@@ -60,6 +83,11 @@ func (g *Guild) Clone() *Guild {
 		guild.Emojis = append(guild.Emojis, *emoji)
 	}
 
+	for i := 0; i < len(g.Stickers); i++ {
+		s := g.Stickers[i].Clone()
+		guild.Stickers = append(guild.Stickers, *s)
+	}
+
 	for i := 0; i < len(g.VoiceStates); i++ {
 		vs := g.VoiceStates[i].Clone()
 		guild.VoiceStates = append(guild.VoiceStates, *vs)
@@ -80,6 +108,16 @@ func (g *Guild) Clone() *Guild {
 		guild.Presences = append(guild.Presences, *presence)
 	}
 
+	for i := 0; i < len(g.Threads); i++ {
+		th := g.Threads[i].Clone()
+		guild.Threads = append(guild.Threads, *th)
+	}
+
+	if g.IncidentsData != nil {
+		incidents := *g.IncidentsData
+		guild.IncidentsData = &incidents
+	}
+
 	guild.Features = append(guild.Features, g.Features...)
 
 	return guild
@@ -91,7 +129,7 @@ func (r *Role) Clone() *Role {
 		return nil
 	}
 
-	return &Role{
+	role := &Role{
 		ID:          r.ID,
 		Name:        r.Name,
 		Color:       r.Color,
@@ -101,6 +139,13 @@ func (r *Role) Clone() *Role {
 		Managed:     r.Managed,
 		Mentionable: r.Mentionable,
 	}
+
+	if r.Tags != nil {
+		tags := *r.Tags
+		role.Tags = &tags
+	}
+
+	return role
 }
 
 // Clone returns a clone of this Emoji.
@@ -127,6 +172,17 @@ func (e *Emoji) Clone() *Emoji {
 	return emoji
 }
 
+// Clone returns a clone of this Sticker.
+func (s *Sticker) Clone() *Sticker {
+	if s == nil {
+		return nil
+	}
+
+	sticker := *s
+	sticker.User = s.User.Clone()
+	return &sticker
+}
+
 // Clone returns a clone of this GuildMember.
 func (m *GuildMember) Clone() *GuildMember {
 	if m == nil {
@@ -140,6 +196,11 @@ func (m *GuildMember) Clone() *GuildMember {
 		JoinedAt: m.JoinedAt,
 		Deaf:     m.Deaf,
 		Mute:     m.Mute,
+		Avatar:   m.Avatar,
+		Banner:   m.Banner,
+		Flags:    m.Flags,
+
+		CommunicationDisabledUntil: m.CommunicationDisabledUntil,
 	}
 }
 
@@ -165,6 +226,19 @@ func (c *Channel) Clone() *Channel {
 		ApplicationID:    c.ApplicationID,
 		ParentID:         c.ParentID,
 		LastPinTimestamp: c.LastPinTimestamp,
+
+		MessageCount:               c.MessageCount,
+		MemberCount:                c.MemberCount,
+		DefaultAutoArchiveDuration: c.DefaultAutoArchiveDuration,
+	}
+
+	if c.ThreadMetadata != nil {
+		metadata := *c.ThreadMetadata
+		channel.ThreadMetadata = &metadata
+	}
+	if c.Member != nil {
+		member := *c.Member
+		channel.Member = &member
 	}
 
 	for i := 0; i < len(c.PermissionOverwrites); i++ {
@@ -194,6 +268,7 @@ func (p *Presence) Clone() *Presence {
 	}
 
 	presence.Roles = append(presence.Roles, p.Roles...)
+	presence.Activities = append(presence.Activities, p.Activities...)
 
 	return presence
 }
@@ -209,3 +284,87 @@ func (g *UnavailableGuild) Clone() *UnavailableGuild {
 		Unavailable: g.Unavailable,
 	}
 }
+
+// Clone returns a clone of this Webhook.
+func (w *Webhook) Clone() *Webhook {
+	if w == nil {
+		return nil
+	}
+
+	webhook := *w
+	webhook.User = w.User.Clone()
+
+	return &webhook
+}
+
+// Clone returns a clone of this Integration.
+func (i *Integration) Clone() *Integration {
+	if i == nil {
+		return nil
+	}
+
+	integration := *i
+	integration.User = i.User.Clone()
+	if i.Account != nil {
+		account := *i.Account
+		integration.Account = &account
+	}
+
+	return &integration
+}
+
+// Clone returns a clone of this Message.
+func (m *Message) Clone() *Message {
+	if m == nil {
+		return nil
+	}
+
+	msg := *m
+	msg.Author = m.Author.Clone()
+	msg.Member = m.Member.Clone()
+
+	msg.Mentions = append([]User(nil), m.Mentions...)
+	msg.MentionRoles = append([]string(nil), m.MentionRoles...)
+	msg.MentionChannels = append([]channel.Mention(nil), m.MentionChannels...)
+	msg.Attachments = append([]message.Attachment(nil), m.Attachments...)
+	msg.Embeds = append([]embed.Embed(nil), m.Embeds...)
+	msg.Reactions = append([]Reaction(nil), m.Reactions...)
+	msg.Components = append([]component.ActionRow(nil), m.Components...)
+	msg.StickerItems = append([]StickerItem(nil), m.StickerItems...)
+
+	if m.Activity != nil {
+		activity := *m.Activity
+		msg.Activity = &activity
+	}
+	if m.Application != nil {
+		application := *m.Application
+		msg.Application = &application
+	}
+	if m.MessageReference != nil {
+		reference := *m.MessageReference
+		msg.MessageReference = &reference
+	}
+	if m.RoleSubscriptionData != nil {
+		data := *m.RoleSubscriptionData
+		msg.RoleSubscriptionData = &data
+	}
+	if m.InteractionMetadata != nil {
+		metadata := *m.InteractionMetadata
+		metadata.User = m.InteractionMetadata.User.Clone()
+		msg.InteractionMetadata = &metadata
+	}
+	if m.Interaction != nil {
+		interaction := *m.Interaction
+		interaction.User = m.Interaction.User.Clone()
+		msg.Interaction = &interaction
+	}
+
+	if m.RawExtra != nil {
+		msg.RawExtra = make(RawExtra, len(m.RawExtra))
+		for k, v := range m.RawExtra {
+			msg.RawExtra[k] = v
+		}
+	}
+
+	return &msg
+}
@@ -0,0 +1,103 @@
+package harmony
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skwair/harmony/internal/endpoint"
+)
+
+// defaultCloudflareBanDuration is how long requests are not sent after Cloudflare
+// banned the client, if it did not say for how long. Bans usually last an hour.
+const defaultCloudflareBanDuration = time.Hour
+
+// CloudflareBan describes a ban of the IP address of a client by Cloudflare,
+// see OnCloudflareBan.
+type CloudflareBan struct {
+	// Method and Path of the request that was rejected, with
+	// webhook and interaction tokens redacted.
+	Method string
+	Path   string
+	// Until is when the client sends requests again.
+	Until time.Time
+	// RayID identifies the response for Cloudflare, if set.
+	RayID string
+}
+
+// isCloudflareBan reports whether the given response is a ban from Cloudflare
+// rather than a rate limit from Discord. Discord sends its rate limits as JSON
+// while Cloudflare sends an HTML page or "error code: 1015" as plain text.
+// body is the beginning of the body of the response.
+func isCloudflareBan(resp *http.Response, body []byte) bool {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	if bytes.Contains(body, []byte("1015")) && bytes.Contains(body, []byte("error code")) {
+		return true
+	}
+	return !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json")
+}
+
+// checkCloudflareBan checks whether the given 429 response is a ban from Cloudflare.
+// If it is, it stops sending requests until the ban is over, notifies the
+// OnCloudflareBan handler and returns an error wrapping ErrCloudflareBan.
+// Otherwise, it returns nil and the response can be read as usual.
+func (c *Client) checkCloudflareBan(e *endpoint.Endpoint, resp *http.Response) error {
+	// Only the beginning of the body is needed to recognize bans,
+	// but the body must be readable as a whole if it is not one.
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(resp.Body, head)
+	head = head[:n]
+	if !isCloudflareBan(resp, head) {
+		resp.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(head), resp.Body), Closer: resp.Body}
+		return nil
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	d := defaultCloudflareBanDuration
+	if after, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil && after > 0 {
+		d = time.Duration(after * float64(time.Second))
+	}
+	until := c.clock.Now().Add(d)
+	c.bannedUntil.Store(until.UnixNano())
+
+	ban := &CloudflareBan{
+		Method: e.Method,
+		Path:   redactPath(e.Path),
+		Until:  until,
+		RayID:  resp.Header.Get("CF-Ray"),
+	}
+	err := fmt.Errorf("%s %s: %w until %s (ray ID: %q)", e.Method, redactPath(e.Path), ErrCloudflareBan, until.Format(time.RFC3339), ban.RayID)
+	c.logger.Errorf("%v, not sending requests until then", err)
+	c.reportError(err, &ErrorEvent{
+		Source:     ErrorSourceREST,
+		Method:     e.Method,
+		Path:       redactPath(e.Path),
+		StatusCode: resp.StatusCode,
+	})
+	c.runHandler(eventCloudflareBan, ban)
+	return err
+}
+
+// banned returns how long requests must not be sent
+// because of a ban from Cloudflare, if any.
+func (c *Client) banned() time.Duration {
+	until := c.bannedUntil.Load()
+	if until == 0 {
+		return 0
+	}
+	return time.Unix(0, until).Sub(c.clock.Now())
+}
+
+// readCloser combines a Reader and a Closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,280 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/optional"
+)
+
+// Constraints enforced by Discord on application commands.
+const (
+	MaxNameLength        = 32
+	MaxDescriptionLength = 100
+	MaxOptions           = 25
+	MaxChoices           = 25
+)
+
+// namePattern is the pattern names of chat input commands and options must match.
+var namePattern = regexp.MustCompile(`^[-_\p{L}\p{N}\p{Devanagari}\p{Thai}]{1,32}$`)
+
+// Type is the type of an application command.
+type Type int
+
+const (
+	// TypeChatInput are slash commands, shown when a user types "/".
+	TypeChatInput Type = 1
+	// TypeUser are commands shown when right clicking on a user.
+	TypeUser Type = 2
+	// TypeMessage are commands shown when right clicking on a message.
+	TypeMessage Type = 3
+)
+
+// OptionType is the type of an option of an application command.
+type OptionType int
+
+const (
+	OptionTypeSubCommand      OptionType = 1
+	OptionTypeSubCommandGroup OptionType = 2
+	OptionTypeString          OptionType = 3
+	OptionTypeInteger         OptionType = 4
+	OptionTypeBoolean         OptionType = 5
+	OptionTypeUser            OptionType = 6
+	OptionTypeChannel         OptionType = 7
+	OptionTypeRole            OptionType = 8
+	OptionTypeMentionable     OptionType = 9
+	OptionTypeNumber          OptionType = 10
+	OptionTypeAttachment      OptionType = 11
+)
+
+// Option is a parameter, a sub command or a group of sub commands of
+// a chat input command.
+type Option struct {
+	Type                     OptionType        `json:"type"`
+	Name                     string            `json:"name"`
+	NameLocalizations        map[string]string `json:"name_localizations,omitempty"`
+	Description              string            `json:"description"`
+	DescriptionLocalizations map[string]string `json:"description_localizations,omitempty"`
+	Required                 bool              `json:"required,omitempty"`
+	// Choices the user can pick from, for string, integer and number options.
+	Choices []Choice `json:"choices,omitempty"`
+	// Options of a sub command or sub command group.
+	Options []Option `json:"options,omitempty"`
+	// Types of channel that can be picked, for channel options.
+	ChannelTypes []channel.Type `json:"channel_types,omitempty"`
+	// Bounds of the value, for integer and number options.
+	MinValue *float64 `json:"min_value,omitempty"`
+	MaxValue *float64 `json:"max_value,omitempty"`
+	// Bounds of the length of the value, for string options.
+	MinLength *int `json:"min_length,omitempty"`
+	MaxLength *int `json:"max_length,omitempty"`
+	// Whether autocomplete interactions are enabled for this option.
+	// Can not be set if Choices are set.
+	Autocomplete bool `json:"autocomplete,omitempty"`
+}
+
+// Choice is a value the user can pick for an option.
+type Choice struct {
+	Name              string            `json:"name"`
+	NameLocalizations map[string]string `json:"name_localizations,omitempty"`
+	// Value of the choice, a string, an integer or a float
+	// depending on the type of the option.
+	Value interface{} `json:"value"`
+}
+
+// Settings are the settings of an application command, all fields are optional
+// and only those explicitly set will be modified.
+type Settings struct {
+	Type                     Type              `json:"type,omitempty"`
+	Name                     *optional.String  `json:"name,omitempty"`
+	NameLocalizations        map[string]string `json:"name_localizations,omitempty"`
+	Description              *optional.String  `json:"description,omitempty"`
+	DescriptionLocalizations map[string]string `json:"description_localizations,omitempty"`
+	Options                  *[]Option         `json:"options,omitempty"`
+	DefaultMemberPermissions *optional.String  `json:"default_member_permissions,omitempty"`
+	DMPermission             *optional.Bool    `json:"dm_permission,omitempty"`
+	NSFW                     *optional.Bool    `json:"nsfw,omitempty"`
+
+	// Shadows of optional fields, since optional values can not be read back.
+	name        *string
+	description *string
+}
+
+// Setting is a function that configures an application command.
+type Setting func(*Settings)
+
+// NewSettings returns new Settings to create or modify an application command.
+func NewSettings(opts ...Setting) *Settings {
+	s := &Settings{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithType sets the type of an application command. It can only
+// be set when creating a command and defaults to TypeChatInput.
+func WithType(t Type) Setting {
+	return func(s *Settings) {
+		s.Type = t
+	}
+}
+
+// WithName sets the name of an application command.
+func WithName(name string) Setting {
+	return func(s *Settings) {
+		s.Name = optional.NewString(name)
+		s.name = &name
+	}
+}
+
+// WithNameLocalizations sets the names of an application command by locale.
+func WithNameLocalizations(names map[string]string) Setting {
+	return func(s *Settings) {
+		s.NameLocalizations = names
+	}
+}
+
+// WithDescription sets the description of an application command.
+// Only chat input commands have a description.
+func WithDescription(desc string) Setting {
+	return func(s *Settings) {
+		s.Description = optional.NewString(desc)
+		s.description = &desc
+	}
+}
+
+// WithDescriptionLocalizations sets the descriptions of an application command by locale.
+func WithDescriptionLocalizations(descs map[string]string) Setting {
+	return func(s *Settings) {
+		s.DescriptionLocalizations = descs
+	}
+}
+
+// WithOptions sets the options of a chat input command.
+func WithOptions(opts ...Option) Setting {
+	return func(s *Settings) {
+		if opts == nil {
+			opts = []Option{}
+		}
+		s.Options = &opts
+	}
+}
+
+// WithDefaultMemberPermissions sets the permissions members need to use an
+// application command by default. Use 0 to restrict it to administrators.
+func WithDefaultMemberPermissions(perms uint64) Setting {
+	return func(s *Settings) {
+		s.DefaultMemberPermissions = optional.NewString(strconv.FormatUint(perms, 10))
+	}
+}
+
+// WithoutDefaultMemberPermissions makes an application command
+// usable by all members by default.
+func WithoutDefaultMemberPermissions() Setting {
+	return func(s *Settings) {
+		s.DefaultMemberPermissions = optional.NewNilString()
+	}
+}
+
+// WithDMPermission sets whether a global application command can be used in DMs.
+func WithDMPermission(yes bool) Setting {
+	return func(s *Settings) {
+		s.DMPermission = optional.NewBool(yes)
+	}
+}
+
+// WithNSFW sets whether an application command is age-restricted.
+func WithNSFW(yes bool) Setting {
+	return func(s *Settings) {
+		s.NSFW = optional.NewBool(yes)
+	}
+}
+
+// Validate checks the settings against the constraints enforced by Discord
+// and returns an error listing all the problems found, if any.
+func (s *Settings) Validate() error {
+	var problems []string
+
+	chatInput := s.Type == 0 || s.Type == TypeChatInput
+	if s.name != nil {
+		if chatInput {
+			if !validName(*s.name) {
+				problems = append(problems, fmt.Sprintf("name %q must be 1-%d lowercase letters, digits, '-' or '_'", *s.name, MaxNameLength))
+			}
+		} else if n := utf8.RuneCountInString(*s.name); n < 1 || n > MaxNameLength {
+			problems = append(problems, fmt.Sprintf("name must be 1-%d characters long, got %d", MaxNameLength, n))
+		}
+	}
+	if s.description != nil {
+		switch n := utf8.RuneCountInString(*s.description); {
+		case !chatInput && n > 0:
+			problems = append(problems, "only chat input commands can have a description")
+		case chatInput && (n < 1 || n > MaxDescriptionLength):
+			problems = append(problems, fmt.Sprintf("description must be 1-%d characters long, got %d", MaxDescriptionLength, n))
+		}
+	}
+	if s.Options != nil {
+		if !chatInput && len(*s.Options) > 0 {
+			problems = append(problems, "only chat input commands can have options")
+		}
+		problems = append(problems, validateOptions(*s.Options, "")...)
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid application command settings: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func validateOptions(opts []Option, path string) []string {
+	var problems []string
+
+	if len(opts) > MaxOptions {
+		problems = append(problems, fmt.Sprintf("%sat most %d options are allowed, got %d", path, MaxOptions, len(opts)))
+	}
+
+	sawOptional := false
+	for i, o := range opts {
+		p := fmt.Sprintf("%soption %d", path, i)
+
+		if !validName(o.Name) {
+			problems = append(problems, fmt.Sprintf("%s: name %q must be 1-%d lowercase letters, digits, '-' or '_'", p, o.Name, MaxNameLength))
+		}
+		if n := utf8.RuneCountInString(o.Description); n < 1 || n > MaxDescriptionLength {
+			problems = append(problems, fmt.Sprintf("%s: description must be 1-%d characters long, got %d", p, MaxDescriptionLength, n))
+		}
+		if len(o.Choices) > MaxChoices {
+			problems = append(problems, fmt.Sprintf("%s: at most %d choices are allowed, got %d", p, MaxChoices, len(o.Choices)))
+		}
+		if o.Autocomplete && len(o.Choices) > 0 {
+			problems = append(problems, fmt.Sprintf("%s: autocomplete can not be enabled with choices", p))
+		}
+
+		if o.Type == OptionTypeSubCommand || o.Type == OptionTypeSubCommandGroup {
+			problems = append(problems, validateOptions(o.Options, p+": ")...)
+			continue
+		}
+		// Required options must be listed before optional ones.
+		if o.Required && sawOptional {
+			problems = append(problems, fmt.Sprintf("%s: required options must come before optional ones", p))
+		}
+		if !o.Required {
+			sawOptional = true
+		}
+	}
+
+	return problems
+}
+
+// validName reports whether name is a valid name for a chat input command or an option.
+func validName(name string) bool {
+	return namePattern.MatchString(name) && strings.ToLower(name) == name
+}
@@ -0,0 +1,223 @@
+/*
+Package commandrouter routes application command interactions to handlers by
+command name. Handlers can return a Modal, which is shown to the user, and are
+called again with the values typed in it once the user submits it, so commands
+that need several steps can be written as a single handler:
+
+	r := commandrouter.New(client, customid.NewCodec(secret))
+	r.Handle("report", func(ctx context.Context, req *commandrouter.Request) *commandrouter.Modal {
+		if !req.Submitted() {
+			return &commandrouter.Modal{
+				Title:  "Report a user",
+				Inputs: []component.TextInput{{CustomID: "reason", Label: "Reason"}},
+				State:  []string{req.Interaction.Data.TargetID},
+			}
+		}
+
+		userID, reason := req.State[0], req.Inputs["reason"]
+		// ...
+		return nil
+	})
+	client.OnInteractionCreateCtx(r.HandleInteraction)
+
+The custom ID of modals is encoded with the codec given to the router, so
+submissions of modals shown by other means are not routed to command handlers.
+If an OnModalSubmit handler is registered, modal submissions are not passed to
+OnInteractionCreate handlers and the router must be registered for both:
+
+	client.OnModalSubmitCtx(r.HandleInteraction)
+*/
+package commandrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/component"
+	"github.com/skwair/harmony/customid"
+)
+
+// modalPrefix prefixes the name of the custom ID of modals shown by a router.
+// Command names can not contain it, so these custom IDs can not be confused
+// with the ones of other modals.
+const modalPrefix = "/"
+
+// ErrNotRouted is wrapped by errors reported for modal submissions
+// whose custom ID was not encoded by a router.
+var ErrNotRouted = errors.New("commandrouter: modal was not shown by a command handler")
+
+// Modal is a modal shown to the user in response to an application command.
+// Once the user submits it, the handler of the command is called again with
+// the values typed in its text inputs.
+type Modal struct {
+	Title string
+	// Text inputs of the modal, shown one per row, up to 5.
+	Inputs []component.TextInput
+	// State is carried by the custom ID of the modal and passed back to the
+	// handler when the modal is submitted, see Request.State. Along with the
+	// name of the command, it must fit in customid.MaxLength characters.
+	State []string
+}
+
+// Request is an application command interaction, or the submission of a
+// modal returned by the handler of an application command.
+type Request struct {
+	Interaction *harmony.Interaction
+	// Name of the command.
+	Command string
+	// State of the submitted modal, see Modal.State.
+	State []string
+	// Values typed by the user in the text inputs of the
+	// submitted modal, by custom ID. Nil for commands.
+	Inputs map[string]string
+}
+
+// Submitted returns whether the request is the submission of a modal.
+func (r *Request) Submitted() bool {
+	return r.Inputs != nil
+}
+
+// HandlerFunc handles an application command or the submission of a modal it
+// returned. It either responds to the interaction itself and returns nil, or
+// returns a modal which is then shown to the user.
+type HandlerFunc func(ctx context.Context, r *Request) *Modal
+
+// Router routes application command interactions, and the submissions of the
+// modals returned by their handlers, to the handler registered for the name of
+// the command. It is safe for concurrent use. Create one with New.
+type Router struct {
+	client  *harmony.Client
+	codec   *customid.Codec
+	onError func(ctx context.Context, i *harmony.Interaction, err error)
+
+	mu       sync.RWMutex
+	handlers map[string]HandlerFunc
+}
+
+// Option is a function that configures a Router.
+type Option func(*Router)
+
+// WithErrorHandler sets the function called when an interaction can not be
+// handled: there is no handler for its command, the custom ID of the submitted
+// modal was not encoded by the router or the modal could not be shown. Such
+// interactions are ignored by default, which makes the user see an error after
+// 3 seconds.
+func WithErrorHandler(f func(ctx context.Context, i *harmony.Interaction, err error)) Option {
+	return func(r *Router) {
+		r.onError = f
+	}
+}
+
+// New returns a new Router responding to interactions with the given client
+// and encoding the custom ID of modals with the given codec.
+func New(client *harmony.Client, codec *customid.Codec, opts ...Option) *Router {
+	r := &Router{
+		client:   client,
+		codec:    codec,
+		onError:  func(context.Context, *harmony.Interaction, error) {},
+		handlers: make(map[string]HandlerFunc),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Handle registers the handler of the application command with the given
+// name, replacing the previous one if any.
+func (r *Router) Handle(command string, f HandlerFunc) {
+	if f == nil {
+		panic("commandrouter: trying to register a nil handler")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers[command] = f
+}
+
+// HandleInteraction routes the given application command or modal submit
+// interaction to the handler of its command, and shows the modal it returns,
+// if any. Other interactions are ignored. It can be registered with
+// harmony.Client.OnInteractionCreateCtx and harmony.Client.OnModalSubmitCtx.
+func (r *Router) HandleInteraction(ctx context.Context, i *harmony.Interaction) {
+	if i.Data == nil {
+		return
+	}
+
+	var req *Request
+	switch i.Type {
+	case harmony.InteractionTypeApplicationCommand:
+		req = &Request{Interaction: i, Command: i.Data.Name}
+
+	case harmony.InteractionTypeModalSubmit:
+		name, state, err := r.codec.Decode(i.Data.CustomID)
+		if err != nil {
+			r.onError(ctx, i, fmt.Errorf("%w: %v", ErrNotRouted, err))
+			return
+		}
+		if !strings.HasPrefix(name, modalPrefix) {
+			r.onError(ctx, i, ErrNotRouted)
+			return
+		}
+		req = &Request{
+			Interaction: i,
+			Command:     strings.TrimPrefix(name, modalPrefix),
+			State:       state,
+			Inputs:      textInputs(i.Data.Components),
+		}
+
+	default:
+		return
+	}
+
+	r.mu.RLock()
+	f, ok := r.handlers[req.Command]
+	r.mu.RUnlock()
+
+	if !ok {
+		r.onError(ctx, i, fmt.Errorf("commandrouter: no handler for %q", req.Command))
+		return
+	}
+
+	if m := f(ctx, req); m != nil {
+		if err := r.showModal(ctx, req, m); err != nil {
+			r.onError(ctx, i, err)
+		}
+	}
+}
+
+// showModal responds to the interaction of the given request with m.
+func (r *Router) showModal(ctx context.Context, req *Request, m *Modal) error {
+	customID, err := r.codec.Encode(modalPrefix+req.Command, m.State...)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]component.ActionRow, len(m.Inputs))
+	for i := range m.Inputs {
+		input := m.Inputs[i]
+		rows[i] = component.NewActionRow(&input)
+	}
+
+	return r.client.Interaction(req.Interaction).RespondModal(ctx, customID, m.Title, rows...)
+}
+
+// textInputs returns the values of the text inputs held by the given rows, by custom ID.
+func textInputs(rows []component.ActionRow) map[string]string {
+	inputs := make(map[string]string)
+	for _, row := range rows {
+		for _, c := range row.Components {
+			if input, ok := c.(*component.TextInput); ok {
+				inputs[input.CustomID] = input.Value
+			}
+		}
+	}
+	return inputs
+}
@@ -0,0 +1,182 @@
+package commandrouter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/component"
+	"github.com/skwair/harmony/customid"
+	"github.com/skwair/harmony/harmonytest"
+)
+
+// modalResponse is the body of a modal response, as sent to Discord.
+type modalResponse struct {
+	Type harmony.InteractionResponseType `json:"type"`
+	Data struct {
+		CustomID   string                `json:"custom_id"`
+		Title      string                `json:"title"`
+		Components []component.ActionRow `json:"components"`
+	} `json:"data"`
+}
+
+func TestRouterModalFlow(t *testing.T) {
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	srv.Handle(http.MethodPost, "/interactions/:id/:token/callback", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	var requests []*Request
+	r := New(c, customid.NewCodec([]byte("secret")))
+	r.Handle("report", func(ctx context.Context, req *Request) *Modal {
+		requests = append(requests, req)
+		if req.Submitted() {
+			return nil
+		}
+		return &Modal{
+			Title:  "Report a user",
+			Inputs: []component.TextInput{{CustomID: "reason", Label: "Reason"}},
+			State:  []string{req.Interaction.Data.TargetID},
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	r.HandleInteraction(ctx, &harmony.Interaction{
+		ID:    "1",
+		Type:  harmony.InteractionTypeApplicationCommand,
+		Token: "token",
+		Data:  &harmony.InteractionData{Name: "report", TargetID: "42"},
+	})
+
+	req, err := srv.WaitRequest(ctx, harmonytest.MatchRoute(http.MethodPost, "/interactions/1/token/callback"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res modalResponse
+	if err = req.Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Type != harmony.InteractionResponseTypeModal || res.Data.Title != "Report a user" {
+		t.Fatalf("expected a modal response titled %q, got %+v", "Report a user", res)
+	}
+	if len(res.Data.Components) != 1 || len(res.Data.Components[0].Components) != 1 {
+		t.Fatalf("expected a single row with a single text input, got %+v", res.Data.Components)
+	}
+
+	// Submit the modal, with the custom ID it was shown with.
+	input := res.Data.Components[0].Components[0].(*component.TextInput)
+	input.Value = "spam"
+	r.HandleInteraction(ctx, &harmony.Interaction{
+		ID:    "2",
+		Type:  harmony.InteractionTypeModalSubmit,
+		Token: "token",
+		Data: &harmony.InteractionData{
+			CustomID:   res.Data.CustomID,
+			Components: res.Data.Components,
+		},
+	})
+
+	if len(requests) != 2 {
+		t.Fatalf("expected the handler to be called twice, got %d", len(requests))
+	}
+	submitted := requests[1]
+	if !submitted.Submitted() || submitted.Command != "report" {
+		t.Errorf("expected a submission of the report command, got %+v", submitted)
+	}
+	if !reflect.DeepEqual(submitted.State, []string{"42"}) {
+		t.Errorf("expected state to be [42], got %q", submitted.State)
+	}
+	if !reflect.DeepEqual(submitted.Inputs, map[string]string{"reason": "spam"}) {
+		t.Errorf("expected inputs to be map[reason:spam], got %v", submitted.Inputs)
+	}
+}
+
+func TestRouterErrors(t *testing.T) {
+	codec := customid.NewCodec([]byte("secret"))
+	other, err := codec.Encode("feedback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown, err := codec.Encode(modalPrefix + "unknown")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		interaction *harmony.Interaction
+		notRouted   bool
+	}{
+		{
+			name:        "unknown command",
+			interaction: &harmony.Interaction{Type: harmony.InteractionTypeApplicationCommand, Data: &harmony.InteractionData{Name: "unknown"}},
+		},
+		{
+			name:        "unknown command modal",
+			interaction: &harmony.Interaction{Type: harmony.InteractionTypeModalSubmit, Data: &harmony.InteractionData{CustomID: unknown}},
+		},
+		{
+			name:        "other modal",
+			interaction: &harmony.Interaction{Type: harmony.InteractionTypeModalSubmit, Data: &harmony.InteractionData{CustomID: other}},
+			notRouted:   true,
+		},
+		{
+			name:        "forged modal",
+			interaction: &harmony.Interaction{Type: harmony.InteractionTypeModalSubmit, Data: &harmony.InteractionData{CustomID: modalPrefix + "report"}},
+			notRouted:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []error
+			r := New(nil, codec, WithErrorHandler(func(_ context.Context, _ *harmony.Interaction, err error) {
+				errs = append(errs, err)
+			}))
+			r.Handle("report", func(context.Context, *Request) *Modal {
+				t.Error("expected the handler not to be called")
+				return nil
+			})
+
+			r.HandleInteraction(context.Background(), tt.interaction)
+
+			if len(errs) != 1 {
+				t.Fatalf("expected a single error, got %v", errs)
+			}
+			if notRouted := errors.Is(errs[0], ErrNotRouted); notRouted != tt.notRouted {
+				t.Errorf("expected error to be ErrNotRouted: %t, got %v", tt.notRouted, errs[0])
+			}
+		})
+	}
+}
+
+func TestRouterIgnoresOtherInteractions(t *testing.T) {
+	r := New(nil, customid.NewCodec(nil), WithErrorHandler(func(_ context.Context, _ *harmony.Interaction, err error) {
+		t.Errorf("unexpected error: %v", err)
+	}))
+	r.Handle("report", func(context.Context, *Request) *Modal {
+		t.Error("expected the handler not to be called")
+		return nil
+	})
+
+	for _, typ := range []harmony.InteractionType{
+		harmony.InteractionTypePing,
+		harmony.InteractionTypeMessageComponent,
+		harmony.InteractionTypeApplicationCommandAutocomplete,
+	} {
+		r.HandleInteraction(context.Background(), &harmony.Interaction{Type: typ, Data: &harmony.InteractionData{Name: "report"}})
+	}
+}
@@ -0,0 +1,282 @@
+// Package component contains the interactive components that can be
+// attached to messages, such as buttons and select menus, or shown
+// in modals, such as text inputs.
+package component
+
+import (
+	"encoding/json"
+
+	"github.com/skwair/harmony/channel"
+)
+
+// Type is the type of a component.
+type Type int
+
+// List of component types.
+const (
+	TypeActionRow         Type = 1
+	TypeButton            Type = 2
+	TypeStringSelect      Type = 3
+	TypeTextInput         Type = 4
+	TypeUserSelect        Type = 5
+	TypeRoleSelect        Type = 6
+	TypeMentionableSelect Type = 7
+	TypeChannelSelect     Type = 8
+)
+
+// Component is a component that can be placed in an action row,
+// either a *Button, a *SelectMenu, a *TextInput or an *Unknown component.
+type Component interface {
+	ComponentType() Type
+}
+
+// ActionRow is a row of components. A message can have up to 5 action rows,
+// each holding up to 5 buttons or a single select menu.
+type ActionRow struct {
+	Components []Component
+}
+
+// NewActionRow returns an action row holding the given components.
+func NewActionRow(components ...Component) ActionRow {
+	return ActionRow{Components: components}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r ActionRow) MarshalJSON() ([]byte, error) {
+	components := r.Components
+	if components == nil {
+		components = []Component{}
+	}
+
+	return json.Marshal(struct {
+		Type       Type        `json:"type"`
+		Components []Component `json:"components"`
+	}{
+		Type:       TypeActionRow,
+		Components: components,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *ActionRow) UnmarshalJSON(b []byte) error {
+	var row struct {
+		Components []json.RawMessage `json:"components"`
+	}
+	if err := json.Unmarshal(b, &row); err != nil {
+		return err
+	}
+
+	r.Components = make([]Component, 0, len(row.Components))
+	for _, raw := range row.Components {
+		var typ struct {
+			Type Type `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &typ); err != nil {
+			return err
+		}
+
+		var c Component
+		switch typ.Type {
+		case TypeButton:
+			c = &Button{}
+		case TypeStringSelect, TypeUserSelect, TypeRoleSelect, TypeMentionableSelect, TypeChannelSelect:
+			c = &SelectMenu{}
+		case TypeTextInput:
+			c = &TextInput{}
+		default:
+			r.Components = append(r.Components, &Unknown{Type: typ.Type, Raw: raw})
+			continue
+		}
+
+		if err := json.Unmarshal(raw, c); err != nil {
+			return err
+		}
+		r.Components = append(r.Components, c)
+	}
+	return nil
+}
+
+// Disable returns a copy of the given action rows with all their buttons and
+// select menus disabled, for instance so users can not interact with a message
+// anymore. Link buttons are kept enabled since they do not send interactions.
+func Disable(rows []ActionRow) []ActionRow {
+	disabled := make([]ActionRow, len(rows))
+	for i, row := range rows {
+		components := make([]Component, len(row.Components))
+		for j, c := range row.Components {
+			switch c := c.(type) {
+			case *Button:
+				b := *c
+				if b.Style != ButtonStyleLink {
+					b.Disabled = true
+				}
+				components[j] = &b
+			case *SelectMenu:
+				m := *c
+				m.Disabled = true
+				components[j] = &m
+			default:
+				components[j] = c
+			}
+		}
+		disabled[i] = ActionRow{Components: components}
+	}
+	return disabled
+}
+
+// Emoji is the emoji shown on a button or a select menu option. Set the
+// Name only for a standard emoji, or the ID for a custom emoji.
+type Emoji struct {
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Animated bool   `json:"animated,omitempty"`
+}
+
+// ButtonStyle is the style of a button.
+type ButtonStyle int
+
+// List of button styles.
+const (
+	ButtonStylePrimary   ButtonStyle = 1
+	ButtonStyleSecondary ButtonStyle = 2
+	ButtonStyleSuccess   ButtonStyle = 3
+	ButtonStyleDanger    ButtonStyle = 4
+	// ButtonStyleLink buttons navigate to their URL and
+	// do not send interactions.
+	ButtonStyleLink ButtonStyle = 5
+)
+
+// Button is a clickable component. Buttons send an interaction with their
+// custom ID when clicked, except link buttons which have a URL instead.
+type Button struct {
+	Style    ButtonStyle `json:"style"`
+	Label    string      `json:"label,omitempty"`
+	Emoji    *Emoji      `json:"emoji,omitempty"`
+	CustomID string      `json:"custom_id,omitempty"`
+	URL      string      `json:"url,omitempty"`
+	Disabled bool        `json:"disabled,omitempty"`
+}
+
+// ComponentType implements the Component interface.
+func (*Button) ComponentType() Type { return TypeButton }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (b *Button) MarshalJSON() ([]byte, error) {
+	type button Button
+	return json.Marshal(struct {
+		Type Type `json:"type"`
+		*button
+	}{
+		Type:   TypeButton,
+		button: (*button)(b),
+	})
+}
+
+// SelectMenu is a dropdown list users can pick values from. String select
+// menus list the given options, while other types list users, roles,
+// mentionables or channels of the guild.
+type SelectMenu struct {
+	// Type of the select menu, defaults to TypeStringSelect.
+	Type     Type   `json:"type"`
+	CustomID string `json:"custom_id"`
+	// Options of a string select menu, up to 25.
+	Options []SelectOption `json:"options,omitempty"`
+	// Types of channel that can be picked, for channel select menus.
+	ChannelTypes []channel.Type `json:"channel_types,omitempty"`
+	Placeholder  string         `json:"placeholder,omitempty"`
+	// Minimum and maximum number of values that can be picked, between 0 and 25.
+	// Nil values default to 1.
+	MinValues *int `json:"min_values,omitempty"`
+	MaxValues *int `json:"max_values,omitempty"`
+	Disabled  bool `json:"disabled,omitempty"`
+}
+
+// ComponentType implements the Component interface.
+func (m *SelectMenu) ComponentType() Type {
+	if m.Type == 0 {
+		return TypeStringSelect
+	}
+	return m.Type
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *SelectMenu) MarshalJSON() ([]byte, error) {
+	type selectMenu SelectMenu
+	menu := selectMenu(*m)
+	menu.Type = m.ComponentType()
+	return json.Marshal(menu)
+}
+
+// SelectOption is an option of a string select menu.
+type SelectOption struct {
+	Label       string `json:"label"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Emoji       *Emoji `json:"emoji,omitempty"`
+	// Whether this option is selected by default.
+	Default bool `json:"default,omitempty"`
+}
+
+// TextInputStyle is the style of a text input.
+type TextInputStyle int
+
+// List of text input styles.
+const (
+	// TextInputStyleShort text inputs hold a single line.
+	TextInputStyleShort TextInputStyle = 1
+	// TextInputStyleParagraph text inputs hold multiple lines.
+	TextInputStyleParagraph TextInputStyle = 2
+)
+
+// TextInput is a field users can type text in. Text inputs can only be
+// shown in modals, one per action row. In modal submit interactions, they
+// hold the custom ID and the value typed by the user.
+type TextInput struct {
+	CustomID string `json:"custom_id"`
+	// Style of the text input, defaults to TextInputStyleShort.
+	Style TextInputStyle `json:"style"`
+	Label string         `json:"label,omitempty"`
+	// Minimum and maximum length of the text, between 0 and 4000.
+	MinLength *int `json:"min_length,omitempty"`
+	MaxLength *int `json:"max_length,omitempty"`
+	// Whether the text input must be filled in. Nil defaults to true.
+	Required *bool `json:"required,omitempty"`
+	// Value is the pre-filled text, or the text typed
+	// by the user in modal submit interactions.
+	Value       string `json:"value,omitempty"`
+	Placeholder string `json:"placeholder,omitempty"`
+}
+
+// ComponentType implements the Component interface.
+func (*TextInput) ComponentType() Type { return TypeTextInput }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (t *TextInput) MarshalJSON() ([]byte, error) {
+	type textInput TextInput
+	input := textInput(*t)
+	if input.Style == 0 {
+		input.Style = TextInputStyleShort
+	}
+	return json.Marshal(struct {
+		Type Type `json:"type"`
+		textInput
+	}{
+		Type:      TypeTextInput,
+		textInput: input,
+	})
+}
+
+// Unknown is a component that is not supported by this package.
+// It is kept as is so messages can be edited without losing it.
+type Unknown struct {
+	Type Type
+	Raw  json.RawMessage
+}
+
+// ComponentType implements the Component interface.
+func (u *Unknown) ComponentType() Type { return u.Type }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (u *Unknown) MarshalJSON() ([]byte, error) {
+	return u.Raw, nil
+}
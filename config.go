@@ -0,0 +1,382 @@
+package harmony
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/skwair/harmony/clock"
+	"github.com/skwair/harmony/eventfilter"
+	"github.com/skwair/harmony/log"
+	"github.com/skwair/harmony/ratelimit"
+)
+
+// allGatewayIntents is the set of all known Gateway intents, OR'd.
+const allGatewayIntents = GatewayIntentUnprivileged | GatewayIntentGuildMembers | GatewayIntentGuildPresences | GatewayIntentMessageContent
+
+// Config is an alternative to ClientOptions to configure a Client, see
+// NewClientWithConfig. Its zero value is a valid configuration that
+// matches the defaults of NewClient.
+type Config struct {
+	// Name of the client, see WithName.
+	Name string
+	// UserAgentSuffix, see WithUserAgentSuffix.
+	UserAgentSuffix string
+	// DefaultHeaders, see WithDefaultHeaders.
+	DefaultHeaders http.Header
+	// HTTPClient, see WithHTTPClient.
+	HTTPClient *http.Client
+	// Proxy, see WithProxy. If nil, the proxy set by environment
+	// variables is used, unless DisableProxy is set.
+	Proxy        *url.URL
+	DisableProxy bool
+	// BearerToken and Scopes, see WithBearerToken.
+	BearerToken bool
+	Scopes      []string
+	// CircuitBreakerThreshold and CircuitBreakerCooldown, see WithCircuitBreaker.
+	// The circuit breaker is disabled if the threshold is zero.
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+	// RateLimitStore, see WithRateLimitStore.
+	RateLimitStore ratelimit.Store
+	// RetryBudgetHandler, see WithRetryBudgetHandler.
+	RetryBudgetHandler func(b RetryBudget)
+	// ErrorMessages, see WithErrorMessages.
+	ErrorMessages *ErrorMessages
+
+	// Shard and ShardCount, see WithSharding.
+	Shard      int
+	ShardCount int
+	// Intents, see WithGatewayIntents. If zero, the client
+	// subscribes to all unprivileged events.
+	Intents GatewayIntent
+	// Deprecated: DisableGuildSubscriptions has no effect, see WithGuildSubscriptions.
+	DisableGuildSubscriptions bool
+	// LargeThreshold, see WithLargeThreshold. If zero, defaults to 250.
+	LargeThreshold int
+	// InitialPresence, see WithInitialPresence.
+	InitialPresence *Status
+	// DisableCompression, see WithCompression.
+	DisableCompression bool
+	// DisableStateTracking, see WithStateTracking.
+	DisableStateTracking bool
+	// StateInterning, see WithStateInterning.
+	StateInterning bool
+	// StateBackend, see WithStateBackend.
+	StateBackend StateBackend
+	// MessageCacheSize, see WithMessageCacheSize.
+	MessageCacheSize int
+	// MemberChunking, see WithMemberChunking.
+	MemberChunking bool
+	// EventFilter, see WithEventFilter.
+	EventFilter *eventfilter.Filter
+	// DisableEventDeduplication, see WithEventDeduplication.
+	DisableEventDeduplication bool
+
+	// Backoff strategy, see WithBackoffStrategy. All fields
+	// must be set for the strategy to be customized.
+	BackoffBaseDelay time.Duration
+	BackoffMaxDelay  time.Duration
+	BackoffFactor    float64
+	BackoffJitter    float64
+	// ReconnectPolicy, see WithReconnectPolicy. Its delays can
+	// not be set along with a custom backoff strategy.
+	ReconnectPolicy *ReconnectPolicy
+
+	// Context, see WithContext.
+	Context context.Context
+	// Logger, see WithLogger.
+	Logger log.Logger
+	// Clock, see WithClock.
+	Clock clock.Clock
+	// ErrorReporter, see WithErrorReporter.
+	ErrorReporter ErrorReporter
+	// MetricsCollector, see WithMetricsCollector.
+	MetricsCollector MetricsCollector
+	// PayloadLogging enables WithPayloadLogging with PayloadLogLevel
+	// and the other PayloadLog fields, which are only used if it is set.
+	PayloadLogging         bool
+	PayloadLogLevel        log.Level
+	PayloadLogUserContent  bool
+	PayloadLogRedactFields []string
+	// PayloadDumpDir, see WithPayloadDumpDir.
+	PayloadDumpDir string
+	// RawExtra, see WithRawExtra.
+	RawExtra bool
+	// Plugins, see WithPlugins.
+	Plugins []Plugin
+}
+
+// ConfigError is returned by Config.Validate when a configuration is invalid.
+// It lists all the problems found in the configuration.
+type ConfigError struct {
+	Problems []string
+}
+
+// Error implements the error interface.
+func (e *ConfigError) Error() string {
+	return "harmony: invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks the configuration and returns a *ConfigError listing all
+// the problems found, or nil if the configuration is valid.
+func (cfg *Config) Validate() error {
+	var problems []string
+	addf := func(format string, v ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, v...))
+	}
+
+	if cfg.ShardCount < 0 {
+		addf("shard count must be positive, got %d", cfg.ShardCount)
+	}
+	if cfg.Shard < 0 {
+		addf("shard must be positive, got %d", cfg.Shard)
+	}
+	if cfg.ShardCount == 0 && cfg.Shard != 0 {
+		addf("shard is set to %d but shard count is not set", cfg.Shard)
+	}
+	if cfg.ShardCount > 0 && cfg.Shard >= cfg.ShardCount {
+		addf("shard must be lower than shard count (%d), got %d", cfg.ShardCount, cfg.Shard)
+	}
+
+	if u := cfg.Proxy; u != nil {
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			addf("proxy scheme must be one of http, https or socks5, got %q", u.Scheme)
+		}
+		if cfg.DisableProxy {
+			addf("proxy is set but proxies are disabled")
+		}
+	}
+	if len(cfg.Scopes) > 0 && !cfg.BearerToken {
+		addf("scopes are set but the token is not a bearer token")
+	}
+	if cfg.CircuitBreakerThreshold < 0 {
+		addf("circuit breaker threshold must be positive, got %d", cfg.CircuitBreakerThreshold)
+	}
+	if cfg.CircuitBreakerThreshold > 0 && cfg.CircuitBreakerCooldown <= 0 {
+		addf("circuit breaker cooldown must be positive, got %s", cfg.CircuitBreakerCooldown)
+	}
+
+	if unknown := cfg.Intents &^ allGatewayIntents; unknown != 0 {
+		addf("unknown gateway intents: %b", unknown)
+	}
+	intents := cfg.intents()
+	if !cfg.DisableStateTracking && intents&GatewayIntentGuild == 0 {
+		addf("state tracking requires the GUILDS intent, add it or disable state tracking")
+	}
+	if intents&GatewayIntentGuildPresences != 0 && intents&GatewayIntentGuild == 0 {
+		addf("the GUILD_PRESENCES intent requires the GUILDS intent")
+	}
+	if cfg.MemberChunking && intents&GatewayIntentGuildMembers == 0 {
+		addf("member chunking requires the GUILD_MEMBERS intent")
+	}
+
+	if cfg.DisableStateTracking {
+		if cfg.StateInterning {
+			addf("state interning is enabled but state tracking is disabled")
+		}
+		if cfg.StateBackend != nil {
+			addf("state backend is set but state tracking is disabled")
+		}
+		if cfg.MessageCacheSize > 0 {
+			addf("message cache size is set but state tracking is disabled")
+		}
+	}
+	if cfg.MessageCacheSize < 0 {
+		addf("message cache size must be positive, got %d", cfg.MessageCacheSize)
+	}
+
+	if cfg.LargeThreshold != 0 && (cfg.LargeThreshold < 50 || cfg.LargeThreshold > 250) {
+		addf("large threshold must be between 50 and 250, got %d", cfg.LargeThreshold)
+	}
+
+	if p := cfg.InitialPresence; p != nil {
+		switch p.Status {
+		case "online", "dnd", "idle", "invisible", "offline":
+		default:
+			addf("initial presence status must be one of online, dnd, idle, invisible or offline, got %q", p.Status)
+		}
+	}
+
+	if cfg.customBackoff() {
+		if cfg.BackoffBaseDelay <= 0 {
+			addf("backoff base delay must be positive, got %s", cfg.BackoffBaseDelay)
+		}
+		if cfg.BackoffMaxDelay < cfg.BackoffBaseDelay {
+			addf("backoff max delay (%s) must not be lower than base delay (%s)", cfg.BackoffMaxDelay, cfg.BackoffBaseDelay)
+		}
+		if cfg.BackoffFactor < 1 {
+			addf("backoff factor must be at least 1, got %g", cfg.BackoffFactor)
+		}
+		if cfg.BackoffJitter < 0 || cfg.BackoffJitter > 1 {
+			addf("backoff jitter must be between 0 and 1, got %g", cfg.BackoffJitter)
+		}
+	}
+
+	if p := cfg.ReconnectPolicy; p != nil {
+		if cfg.customBackoff() && (p.BaseDelay != 0 || p.MaxDelay != 0 || p.Factor != 0 || p.Jitter != 0) {
+			addf("reconnect policy delays can not be set along with a custom backoff strategy")
+		}
+		if p.BaseDelay < 0 {
+			addf("reconnect policy base delay must be positive, got %s", p.BaseDelay)
+		}
+		if p.MaxDelay < 0 || (p.MaxDelay > 0 && p.MaxDelay < p.BaseDelay) {
+			addf("reconnect policy max delay (%s) must not be lower than base delay (%s)", p.MaxDelay, p.BaseDelay)
+		}
+		if p.Factor != 0 && p.Factor < 1 {
+			addf("reconnect policy factor must be at least 1, got %g", p.Factor)
+		}
+		if p.Jitter < 0 || p.Jitter > 1 {
+			addf("reconnect policy jitter must be between 0 and 1, got %g", p.Jitter)
+		}
+		if p.MaxAttempts < 0 {
+			addf("reconnect policy max attempts must be positive, got %d", p.MaxAttempts)
+		}
+	}
+
+	if cfg.Context != nil && cfg.Context.Err() != nil {
+		addf("context is already done: %v", cfg.Context.Err())
+	}
+
+	if cfg.PayloadLogging {
+		if cfg.PayloadLogLevel < log.LevelError || cfg.PayloadLogLevel > log.LevelDebug {
+			addf("payload log level must be between %d and %d, got %d", log.LevelError, log.LevelDebug, cfg.PayloadLogLevel)
+		}
+	} else if cfg.PayloadLogLevel != 0 || cfg.PayloadLogUserContent || len(cfg.PayloadLogRedactFields) > 0 {
+		addf("payload logging fields are set but payload logging is not enabled")
+	}
+
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
+
+// intents returns the intents the client will subscribe to.
+func (cfg *Config) intents() GatewayIntent {
+	if cfg.Intents == 0 {
+		return GatewayIntentUnprivileged
+	}
+	return cfg.Intents
+}
+
+// customBackoff reports whether a custom backoff strategy is configured.
+func (cfg *Config) customBackoff() bool {
+	return cfg.BackoffBaseDelay != 0 || cfg.BackoffMaxDelay != 0 ||
+		cfg.BackoffFactor != 0 || cfg.BackoffJitter != 0
+}
+
+// Options returns the ClientOptions equivalent to this configuration.
+// It does not validate the configuration.
+func (cfg *Config) Options() []ClientOption {
+	opts := []ClientOption{
+		WithGatewayIntents(cfg.intents()),
+		WithCompression(!cfg.DisableCompression),
+		WithStateTracking(!cfg.DisableStateTracking),
+		WithStateInterning(cfg.StateInterning),
+		WithMemberChunking(cfg.MemberChunking),
+		WithEventDeduplication(!cfg.DisableEventDeduplication),
+	}
+
+	if cfg.Name != "" {
+		opts = append(opts, WithName(cfg.Name))
+	}
+	if cfg.UserAgentSuffix != "" {
+		opts = append(opts, WithUserAgentSuffix(cfg.UserAgentSuffix))
+	}
+	if cfg.DefaultHeaders != nil {
+		opts = append(opts, WithDefaultHeaders(cfg.DefaultHeaders))
+	}
+	if cfg.HTTPClient != nil {
+		opts = append(opts, WithHTTPClient(cfg.HTTPClient))
+	}
+	if cfg.Proxy != nil || cfg.DisableProxy {
+		opts = append(opts, WithProxy(cfg.Proxy))
+	}
+	if cfg.BearerToken {
+		opts = append(opts, WithBearerToken(cfg.Scopes...))
+	}
+	if cfg.CircuitBreakerThreshold > 0 {
+		opts = append(opts, WithCircuitBreaker(cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown))
+	}
+	if cfg.RateLimitStore != nil {
+		opts = append(opts, WithRateLimitStore(cfg.RateLimitStore))
+	}
+	if cfg.RetryBudgetHandler != nil {
+		opts = append(opts, WithRetryBudgetHandler(cfg.RetryBudgetHandler))
+	}
+	if cfg.ErrorMessages != nil {
+		opts = append(opts, WithErrorMessages(cfg.ErrorMessages))
+	}
+	if cfg.ShardCount > 0 {
+		opts = append(opts, WithSharding(cfg.Shard, cfg.ShardCount))
+	}
+	if cfg.LargeThreshold != 0 {
+		opts = append(opts, WithLargeThreshold(cfg.LargeThreshold))
+	}
+	if cfg.InitialPresence != nil {
+		opts = append(opts, WithInitialPresence(cfg.InitialPresence))
+	}
+	if cfg.StateBackend != nil {
+		opts = append(opts, WithStateBackend(cfg.StateBackend))
+	}
+	if cfg.MessageCacheSize > 0 {
+		opts = append(opts, WithMessageCacheSize(cfg.MessageCacheSize))
+	}
+	if cfg.EventFilter != nil {
+		opts = append(opts, WithEventFilter(cfg.EventFilter))
+	}
+	if cfg.customBackoff() {
+		opts = append(opts, WithBackoffStrategy(cfg.BackoffBaseDelay, cfg.BackoffMaxDelay, cfg.BackoffFactor, cfg.BackoffJitter))
+	}
+	if cfg.ReconnectPolicy != nil {
+		opts = append(opts, WithReconnectPolicy(*cfg.ReconnectPolicy))
+	}
+	if cfg.Context != nil {
+		opts = append(opts, WithContext(cfg.Context))
+	}
+	if cfg.Logger != nil {
+		opts = append(opts, WithLogger(cfg.Logger))
+	}
+	if cfg.Clock != nil {
+		opts = append(opts, WithClock(cfg.Clock))
+	}
+	if cfg.ErrorReporter != nil {
+		opts = append(opts, WithErrorReporter(cfg.ErrorReporter))
+	}
+	if cfg.MetricsCollector != nil {
+		opts = append(opts, WithMetricsCollector(cfg.MetricsCollector))
+	}
+	if cfg.PayloadLogging {
+		opts = append(opts, WithPayloadLogging(cfg.PayloadLogLevel,
+			PayloadLogUserContent(cfg.PayloadLogUserContent),
+			PayloadLogRedactFields(cfg.PayloadLogRedactFields...),
+		))
+	}
+	if cfg.PayloadDumpDir != "" {
+		opts = append(opts, WithPayloadDumpDir(cfg.PayloadDumpDir))
+	}
+	if cfg.RawExtra {
+		opts = append(opts, WithRawExtra(true))
+	}
+	if len(cfg.Plugins) > 0 {
+		opts = append(opts, WithPlugins(cfg.Plugins...))
+	}
+
+	return opts
+}
+
+// NewClientWithConfig is like NewClient but configures the client with the
+// given Config instead of ClientOptions. The configuration is validated first
+// and a *ConfigError is returned if it is invalid.
+func NewClientWithConfig(token string, cfg Config) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return NewClient(token, cfg.Options()...)
+}
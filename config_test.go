@@ -0,0 +1,172 @@
+package harmony_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/harmonytest"
+	"github.com/skwair/harmony/log"
+)
+
+func TestConfigValidate(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		cfg      harmony.Config
+		problems []string
+	}{
+		{name: "zero value"},
+		{
+			name: "valid",
+			cfg: harmony.Config{
+				ShardCount:              2,
+				Shard:                   1,
+				Intents:                 harmony.GatewayIntentUnprivileged | harmony.GatewayIntentGuildMembers,
+				Proxy:                   &url.URL{Scheme: "socks5", Host: "localhost:1080"},
+				BearerToken:             true,
+				Scopes:                  []string{"identify"},
+				CircuitBreakerThreshold: 5,
+				CircuitBreakerCooldown:  time.Minute,
+				StateInterning:          true,
+				MessageCacheSize:        100,
+				MemberChunking:          true,
+				ReconnectPolicy:         &harmony.ReconnectPolicy{MaxAttempts: 5, BaseDelay: time.Second},
+				Context:                 context.Background(),
+				PayloadLogging:          true,
+				PayloadLogLevel:         log.LevelInfo,
+			},
+		},
+		{
+			name:     "sharding",
+			cfg:      harmony.Config{ShardCount: 2, Shard: 2},
+			problems: []string{"shard must be lower than shard count"},
+		},
+		{
+			name:     "proxy",
+			cfg:      harmony.Config{Proxy: &url.URL{Scheme: "ftp", Host: "localhost"}, DisableProxy: true},
+			problems: []string{"proxy scheme", "proxies are disabled"},
+		},
+		{
+			name:     "scopes without bearer token",
+			cfg:      harmony.Config{Scopes: []string{"identify"}},
+			problems: []string{"not a bearer token"},
+		},
+		{
+			name:     "circuit breaker",
+			cfg:      harmony.Config{CircuitBreakerThreshold: 5},
+			problems: []string{"circuit breaker cooldown"},
+		},
+		{
+			name:     "member chunking without intent",
+			cfg:      harmony.Config{MemberChunking: true},
+			problems: []string{"GUILD_MEMBERS"},
+		},
+		{
+			name: "state options without state tracking",
+			cfg: harmony.Config{
+				DisableStateTracking: true,
+				StateInterning:       true,
+				StateBackend:         harmony.NewMemoryStateBackend(),
+				MessageCacheSize:     100,
+			},
+			problems: []string{"state interning", "state backend", "message cache size"},
+		},
+		{
+			name:     "negative message cache size",
+			cfg:      harmony.Config{MessageCacheSize: -1},
+			problems: []string{"message cache size must be positive"},
+		},
+		{
+			name: "reconnect policy",
+			cfg: harmony.Config{
+				ReconnectPolicy: &harmony.ReconnectPolicy{
+					BaseDelay:   time.Minute,
+					MaxDelay:    time.Second,
+					Factor:      0.5,
+					Jitter:      2,
+					MaxAttempts: -1,
+				},
+			},
+			problems: []string{"max delay", "factor", "jitter", "max attempts"},
+		},
+		{
+			name: "reconnect policy with backoff strategy",
+			cfg: harmony.Config{
+				BackoffBaseDelay: time.Second,
+				BackoffMaxDelay:  time.Minute,
+				BackoffFactor:    2,
+				ReconnectPolicy:  &harmony.ReconnectPolicy{BaseDelay: time.Second},
+			},
+			problems: []string{"custom backoff strategy"},
+		},
+		{
+			name:     "canceled context",
+			cfg:      harmony.Config{Context: canceled},
+			problems: []string{"context is already done"},
+		},
+		{
+			name:     "payload log level",
+			cfg:      harmony.Config{PayloadLogging: true, PayloadLogLevel: 4},
+			problems: []string{"payload log level"},
+		},
+		{
+			name:     "payload logging disabled",
+			cfg:      harmony.Config{PayloadLogUserContent: true},
+			problems: []string{"payload logging is not enabled"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if len(tt.problems) == 0 {
+				if err != nil {
+					t.Fatalf("expected configuration to be valid, got %v", err)
+				}
+				return
+			}
+
+			var cfgErr *harmony.ConfigError
+			if !errors.As(err, &cfgErr) {
+				t.Fatalf("expected a *ConfigError, got %v", err)
+			}
+			if len(cfgErr.Problems) != len(tt.problems) {
+				t.Fatalf("expected %d problems, got %q", len(tt.problems), cfgErr.Problems)
+			}
+			for i, problem := range tt.problems {
+				if !strings.Contains(cfgErr.Problems[i], problem) {
+					t.Errorf("expected problem %d to contain %q, got %q", i, problem, cfgErr.Problems[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConfigOptions(t *testing.T) {
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	cfg := harmony.Config{BearerToken: true, Scopes: []string{"identify"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected configuration to be valid, got %v", err)
+	}
+
+	c, err := srv.NewClient(cfg.Options()...)
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = c.Connect(ctx); !errors.Is(err, harmony.ErrBotTokenRequired) {
+		t.Errorf("expected ErrBotTokenRequired, got %v", err)
+	}
+}
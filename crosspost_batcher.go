@@ -0,0 +1,210 @@
+package harmony
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Discord allows to crosspost at most 10 messages per hour in each
+// announcement channel.
+const (
+	defaultCrosspostLimit  = 10
+	defaultCrosspostWindow = time.Hour
+)
+
+// CrosspostBatcher queues crossposts and announcements across many announcement
+// channels and sends them over time, so the strict per-channel limit on
+// crossposts is never exceeded. Channels are processed concurrently, while
+// messages of a single channel are crossposted in the order they were queued.
+// Create one with Client.NewCrosspostBatcher. It is safe for concurrent use.
+type CrosspostBatcher struct {
+	client *Client
+	limit  int
+	window time.Duration
+
+	mu    sync.Mutex
+	queue map[string][]crosspostJob // Queued jobs by channel ID.
+	sent  map[string][]time.Time    // Recent crossposts by channel ID.
+}
+
+type crosspostJob struct {
+	// ID of the message to crosspost. If empty, a new
+	// message is first sent with the given options.
+	messageID string
+	opts      []MessageOption
+}
+
+// CrosspostResult is the result of a crosspost sent by a CrosspostBatcher.
+type CrosspostResult struct {
+	ChannelID string
+	// MessageID is the ID of the message to crosspost. It is empty
+	// for announcements that could not be sent.
+	MessageID string
+	// Message is the crossposted message, if Err is nil.
+	Message *Message
+	Err     error
+}
+
+// CrosspostBatcherOption allows to customize a CrosspostBatcher.
+type CrosspostBatcherOption func(*CrosspostBatcher)
+
+// WithCrosspostLimit sets how many messages can be crossposted in each channel
+// during the given window of time.
+// Defaults to 10 per hour, which is the limit enforced by Discord.
+func WithCrosspostLimit(limit int, window time.Duration) CrosspostBatcherOption {
+	return func(b *CrosspostBatcher) {
+		if limit < 1 {
+			limit = 1
+		}
+		b.limit = limit
+		b.window = window
+	}
+}
+
+// NewCrosspostBatcher returns a new CrosspostBatcher using this client.
+func (c *Client) NewCrosspostBatcher(opts ...CrosspostBatcherOption) *CrosspostBatcher {
+	b := &CrosspostBatcher{
+		client: c,
+		limit:  defaultCrosspostLimit,
+		window: defaultCrosspostWindow,
+		queue:  make(map[string][]crosspostJob),
+		sent:   make(map[string][]time.Time),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Crosspost queues the crosspost of an existing message of an announcement channel.
+func (b *CrosspostBatcher) Crosspost(channelID, messageID string) {
+	b.enqueue(channelID, crosspostJob{messageID: messageID})
+}
+
+// Announce queues a new message to send in an announcement channel
+// and to crosspost right after. See ChannelResource.Send.
+func (b *CrosspostBatcher) Announce(channelID string, opts ...MessageOption) {
+	b.enqueue(channelID, crosspostJob{opts: opts})
+}
+
+func (b *CrosspostBatcher) enqueue(channelID string, job crosspostJob) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.queue[channelID] = append(b.queue[channelID], job)
+}
+
+// Flush sends all queued crossposts and announcements, waiting when needed to
+// respect the per-channel limit, and returns one result per queued item. It
+// returns when everything has been sent or when ctx or the context of the
+// client is done (see WithContext), in which case the results of items that
+// were not sent hold the context's error.
+func (b *CrosspostBatcher) Flush(ctx context.Context) []CrosspostResult {
+	b.mu.Lock()
+	queue := b.queue
+	b.queue = make(map[string][]crosspostJob)
+	b.mu.Unlock()
+
+	var (
+		mu      sync.Mutex
+		results []CrosspostResult
+		wg      sync.WaitGroup
+	)
+	for channelID, jobs := range queue {
+		wg.Add(1)
+		go func(channelID string, jobs []crosspostJob) {
+			defer wg.Done()
+
+			for _, job := range jobs {
+				res := b.send(ctx, channelID, job)
+
+				mu.Lock()
+				results = append(results, res)
+				mu.Unlock()
+			}
+		}(channelID, jobs)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// send sends a single job once the channel has room for it. The slot
+// reserved for it is released if it could not be crossposted.
+func (b *CrosspostBatcher) send(ctx context.Context, channelID string, job crosspostJob) CrosspostResult {
+	res := CrosspostResult{ChannelID: channelID, MessageID: job.messageID}
+
+	reserved, err := b.wait(ctx, channelID)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	ch := b.client.Channel(channelID)
+	if job.messageID == "" {
+		msg, err := ch.Send(ctx, job.opts...)
+		if err != nil {
+			b.release(channelID, reserved)
+			res.Err = err
+			return res
+		}
+		res.MessageID = msg.ID
+	}
+
+	res.Message, res.Err = ch.CrossPostMessage(ctx, res.MessageID)
+	if res.Err != nil {
+		b.release(channelID, reserved)
+	}
+	return res
+}
+
+// wait blocks until a message can be crossposted in the given channel and
+// reserves a slot for it, returning the time of the reservation, or until
+// ctx is done.
+func (b *CrosspostBatcher) wait(ctx context.Context, channelID string) (time.Time, error) {
+	for {
+		b.mu.Lock()
+		now := b.client.clock.Now()
+
+		// Forget about crossposts that left the window.
+		sent := b.sent[channelID]
+		for len(sent) > 0 && now.Sub(sent[0]) >= b.window {
+			sent = sent[1:]
+		}
+
+		if len(sent) < b.limit {
+			b.sent[channelID] = append(sent, now)
+			b.mu.Unlock()
+			return now, nil
+		}
+		b.sent[channelID] = sent
+		delay := b.window - now.Sub(sent[0])
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return time.Time{}, ctx.Err()
+		case <-b.client.baseCtx.Done():
+			return time.Time{}, b.client.baseCtx.Err()
+		case <-b.client.clock.After(delay):
+		}
+	}
+}
+
+// release frees the slot reserved at the given time in the given channel,
+// for a message that could not be crossposted.
+func (b *CrosspostBatcher) release(channelID string, reserved time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sent := b.sent[channelID]
+	for i, t := range sent {
+		if t.Equal(reserved) {
+			b.sent[channelID] = append(sent[:i:i], sent[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package harmony_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/clock"
+	"github.com/skwair/harmony/harmonytest"
+)
+
+func TestCrosspostBatcherReleasesFailedSlots(t *testing.T) {
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	srv.Handle(http.MethodPost, "/channels/:channel/messages/:message/crosspost", func(w http.ResponseWriter, r *http.Request) {
+		if harmonytest.Param(r, "message") == "10" {
+			harmonytest.WriteError(w, http.StatusForbidden, 50001, "Missing Access")
+			return
+		}
+		harmonytest.WriteJSON(w, http.StatusOK, &harmony.Message{ID: harmonytest.Param(r, "message"), ChannelID: "1"})
+	})
+
+	// With a mock clock, the window never elapses: the second crosspost
+	// can only be sent if the slot of the failed one was released.
+	c, err := srv.NewClient(harmony.WithClock(clock.NewMock(time.Unix(0, 0))))
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	b := c.NewCrosspostBatcher(harmony.WithCrosspostLimit(1, time.Hour))
+	b.Crosspost("1", "10")
+	b.Crosspost("1", "11")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	results := b.Flush(ctx)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Err == nil {
+		t.Error("expected the first crosspost to fail")
+	}
+	if results[1].Err != nil || results[1].Message == nil {
+		t.Errorf("expected the second crosspost to be sent, got %v", results[1].Err)
+	}
+}
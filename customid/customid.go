@@ -0,0 +1,255 @@
+/*
+Package customid encodes small state payloads into the custom IDs of message
+components and routes component interactions to handlers by name, so they can
+be handled after a restart without keeping any state:
+
+	codec := customid.NewCodec(secret)
+	id, err := codec.Encode("vote", pollID, "yes")
+	// Send a button with this custom ID.
+
+	r := customid.NewRouter(codec)
+	r.Handle("vote", func(ctx context.Context, i *harmony.Interaction, values []string) {
+		pollID, choice := values[0], values[1]
+		// ...
+	})
+	client.OnMessageComponentCtx(r.HandleInteraction)
+
+Modals shown with harmony.InteractionResource.RespondModal can be given a custom
+ID encoded by the codec as well, so their submissions are routed the same way:
+
+	r.Handle("feedback", func(ctx context.Context, i *harmony.Interaction, values []string) {
+		text := i.Data.TextInputValue("text")
+		// ...
+	})
+	client.OnModalSubmitCtx(r.HandleInteraction)
+
+Custom IDs are made of a name followed by values, separated by colons. When the
+codec has a key, a truncated HMAC of the custom ID is appended to it, so users
+can not forge custom IDs with arbitrary values.
+*/
+package customid
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/skwair/harmony"
+)
+
+const (
+	// MaxLength is the maximum length of a custom ID, in characters.
+	MaxLength = 100
+
+	separator = ':'
+	escape    = '\\'
+	// signatureSize is the size of the truncated HMAC, in bytes.
+	// It takes 11 characters once encoded.
+	signatureSize = 8
+)
+
+var (
+	// ErrTooLong is returned when encoding values that do not fit in MaxLength characters.
+	ErrTooLong = fmt.Errorf("customid: custom ID is longer than %d characters", MaxLength)
+	// ErrInvalidSignature is returned when decoding a custom ID with an invalid signature.
+	ErrInvalidSignature = errors.New("customid: invalid signature")
+	// ErrMalformed is returned when decoding a custom ID that was not encoded by a Codec.
+	ErrMalformed = errors.New("customid: malformed custom ID")
+)
+
+// Codec encodes and decodes custom IDs. It is safe for concurrent use.
+// Create one with NewCodec.
+type Codec struct {
+	key []byte
+}
+
+// NewCodec returns a new Codec signing custom IDs with the given key.
+// If the key is nil, custom IDs are not signed.
+func NewCodec(key []byte) *Codec {
+	return &Codec{key: key}
+}
+
+// Encode returns a custom ID holding the given name and values. Colons and
+// backslashes in values are escaped. It returns ErrTooLong if the custom ID
+// is longer than MaxLength characters.
+func (c *Codec) Encode(name string, values ...string) (string, error) {
+	var b strings.Builder
+	writeEscaped(&b, name)
+	for _, v := range values {
+		b.WriteRune(separator)
+		writeEscaped(&b, v)
+	}
+
+	if c.key != nil {
+		sig := c.sign(b.String())
+		b.WriteRune(separator)
+		b.WriteString(sig)
+	}
+
+	id := b.String()
+	if utf8.RuneCountInString(id) > MaxLength {
+		return "", ErrTooLong
+	}
+	return id, nil
+}
+
+// Decode returns the name and values held by the given custom ID. It returns
+// ErrInvalidSignature if the codec has a key and the signature is invalid.
+func (c *Codec) Decode(customID string) (name string, values []string, err error) {
+	fields, ok := split(customID)
+	if !ok {
+		return "", nil, ErrMalformed
+	}
+
+	if c.key != nil {
+		if len(fields) < 2 {
+			return "", nil, ErrInvalidSignature
+		}
+		sig := fields[len(fields)-1]
+		signed := customID[:len(customID)-len(sig)-1]
+		if !hmac.Equal([]byte(sig), []byte(c.sign(signed))) {
+			return "", nil, ErrInvalidSignature
+		}
+		fields = fields[:len(fields)-1]
+	}
+
+	return fields[0], fields[1:], nil
+}
+
+// Name returns the name of the given custom ID, without checking its signature.
+func Name(customID string) string {
+	fields, ok := split(customID)
+	if !ok {
+		return ""
+	}
+	return fields[0]
+}
+
+func (c *Codec) sign(s string) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(s))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureSize])
+}
+
+func writeEscaped(b *strings.Builder, s string) {
+	for _, r := range s {
+		if r == separator || r == escape {
+			b.WriteRune(escape)
+		}
+		b.WriteRune(r)
+	}
+}
+
+// split splits the given custom ID into its unescaped fields.
+func split(customID string) ([]string, bool) {
+	var (
+		fields  []string
+		field   strings.Builder
+		escaped bool
+	)
+	for _, r := range customID {
+		switch {
+		case escaped:
+			field.WriteRune(r)
+			escaped = false
+		case r == escape:
+			escaped = true
+		case r == separator:
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if escaped {
+		return nil, false
+	}
+	return append(fields, field.String()), true
+}
+
+// HandlerFunc handles an interaction whose custom ID has the name it is
+// registered for, with the values decoded from the custom ID.
+type HandlerFunc func(ctx context.Context, i *harmony.Interaction, values []string)
+
+// Router routes message component and modal submit interactions to the handler
+// registered for the name of their custom ID. It is safe for concurrent use.
+// Create one with NewRouter.
+type Router struct {
+	codec   *Codec
+	onError func(ctx context.Context, i *harmony.Interaction, err error)
+
+	mu       sync.RWMutex
+	handlers map[string]HandlerFunc
+}
+
+// RouterOption is a function that configures a Router.
+type RouterOption func(*Router)
+
+// WithErrorHandler sets the function called when an interaction can not be
+// routed: its custom ID is malformed, has an invalid signature or there is no
+// handler for its name. Such interactions are ignored by default, which makes
+// the user see an error after 3 seconds.
+func WithErrorHandler(f func(ctx context.Context, i *harmony.Interaction, err error)) RouterOption {
+	return func(r *Router) {
+		r.onError = f
+	}
+}
+
+// NewRouter returns a new Router decoding custom IDs with the given codec.
+func NewRouter(codec *Codec, opts ...RouterOption) *Router {
+	r := &Router{
+		codec:    codec,
+		onError:  func(context.Context, *harmony.Interaction, error) {},
+		handlers: make(map[string]HandlerFunc),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Handle registers the handler of custom IDs with the given name,
+// replacing the previous one if any.
+func (r *Router) Handle(name string, f HandlerFunc) {
+	if f == nil {
+		panic("customid: trying to register a nil handler")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers[name] = f
+}
+
+// HandleInteraction routes the given interaction to the handler registered for
+// the name of its custom ID. It can be registered with
+// harmony.Client.OnMessageComponentCtx and harmony.Client.OnModalSubmitCtx.
+func (r *Router) HandleInteraction(ctx context.Context, i *harmony.Interaction) {
+	if i.Data == nil || i.Data.CustomID == "" {
+		return
+	}
+
+	name, values, err := r.codec.Decode(i.Data.CustomID)
+	if err != nil {
+		r.onError(ctx, i, err)
+		return
+	}
+
+	r.mu.RLock()
+	f, ok := r.handlers[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		r.onError(ctx, i, fmt.Errorf("customid: no handler for %q", name))
+		return
+	}
+	f(ctx, i, values)
+}
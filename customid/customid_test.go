@@ -0,0 +1,122 @@
+package customid
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    []byte
+		values []string
+		// Expected custom ID, without its signature if the codec has a key.
+		customID string
+	}{
+		{name: "unsigned", values: []string{"1", "yes"}, customID: "vote:1:yes"},
+		{name: "unsigned without values", values: []string{}, customID: "vote"},
+		{name: "unsigned escaped", values: []string{`a:b`, `c\d`}, customID: `vote:a\:b:c\\d`},
+		{name: "signed", key: []byte("secret"), values: []string{"1", "yes"}, customID: "vote:1:yes"},
+		{name: "signed without values", key: []byte("secret"), values: []string{}, customID: "vote"},
+		{name: "signed escaped", key: []byte("secret"), values: []string{`a:b`, `c\d`}, customID: `vote:a\:b:c\\d`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCodec(tt.key)
+
+			id, err := c.Encode("vote", tt.values...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.key == nil && id != tt.customID {
+				t.Errorf("expected custom ID to be %q, got %q", tt.customID, id)
+			}
+			if tt.key != nil && !strings.HasPrefix(id, tt.customID+":") {
+				t.Errorf("expected custom ID to be %q followed by a signature, got %q", tt.customID, id)
+			}
+
+			name, values, err := c.Decode(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != "vote" {
+				t.Errorf("expected name to be vote, got %q", name)
+			}
+			if !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("expected values to be %q, got %q", tt.values, values)
+			}
+			if n := Name(id); n != "vote" {
+				t.Errorf("expected Name to return vote, got %q", n)
+			}
+		})
+	}
+}
+
+func TestCodecDecodeErrors(t *testing.T) {
+	key := []byte("secret")
+	signed, err := NewCodec(key).Encode("vote", "1", "yes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := signed[strings.LastIndexByte(signed, separator)+1:]
+
+	tests := []struct {
+		name     string
+		key      []byte
+		customID string
+		err      error
+	}{
+		{name: "valid", key: key, customID: signed},
+		{name: "forged value", key: key, customID: "vote:1:no:" + sig, err: ErrInvalidSignature},
+		{name: "other key", key: []byte("other"), customID: signed, err: ErrInvalidSignature},
+		{name: "missing signature", key: key, customID: "vote", err: ErrInvalidSignature},
+		{name: "truncated signature", key: key, customID: signed[:len(signed)-1], err: ErrInvalidSignature},
+		{name: "escaped signature", key: key, customID: "vote:1:yes\\:" + sig, err: ErrInvalidSignature},
+		{name: "malformed", key: key, customID: `vote:1\`, err: ErrMalformed},
+		{name: "unsigned malformed", customID: `vote\`, err: ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := NewCodec(tt.key).Decode(tt.customID)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected error to be %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestCodecEncodeTooLong(t *testing.T) {
+	tests := []struct {
+		name string
+		key  []byte
+		// Length of the value that makes the custom ID exactly MaxLength characters.
+		max int
+	}{
+		// "v:" takes 2 characters.
+		{name: "unsigned", max: MaxLength - 2},
+		// The signature and its separator take 12 more characters.
+		{name: "signed", key: []byte("secret"), max: MaxLength - 2 - 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCodec(tt.key)
+
+			id, err := c.Encode("v", strings.Repeat("é", tt.max))
+			if err != nil {
+				t.Fatalf("expected a custom ID of %d characters to be valid, got %v", MaxLength, err)
+			}
+			if _, _, err = c.Decode(id); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = c.Encode("v", strings.Repeat("é", tt.max+1)); !errors.Is(err, ErrTooLong) {
+				t.Errorf("expected ErrTooLong, got %v", err)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/analytics"
 )
 
 type httpDebugger struct {
@@ -73,3 +74,23 @@ func (d *httpDebugger) all(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// NewStatsHTTP registers an HTTP handler exposing the statistics
+// of the given client, see harmony.Client.Stats.
+func NewStatsHTTP(client *harmony.Client) {
+	http.HandleFunc("/debug/stats", func(w http.ResponseWriter, r *http.Request) {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(client.Stats()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// NewAnalyticsHTTP registers an HTTP handler exposing the statistics
+// and last invocations of application commands kept by the given
+// analytics sink, see analytics.Memory.
+func NewAnalyticsHTTP(m *analytics.Memory) {
+	http.Handle("/debug/commands", m)
+}
@@ -0,0 +1,232 @@
+package harmony
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// RawExtra holds the fields of a Gateway payload that are not (yet) known
+// by Harmony, keyed by their JSON name. It allows to access data that was
+// recently added by Discord without waiting for a new release. See WithRawExtra.
+type RawExtra map[string]json.RawMessage
+
+// rawExtraSetter is implemented by types that keep track of the unknown
+// fields they were decoded from.
+type rawExtraSetter interface {
+	setRawExtra(RawExtra)
+}
+
+func (ch *Channel) setRawExtra(e RawExtra) { ch.RawExtra = e }
+func (g *Guild) setRawExtra(e RawExtra)    { g.RawExtra = e }
+func (m *Message) setRawExtra(e RawExtra)  { m.RawExtra = e }
+func (u *User) setRawExtra(e RawExtra)     { u.RawExtra = e }
+
+// WithPayloadDumpDir sets a directory where Gateway payloads that could not
+// be decoded are written, one file per payload. This is useful to report
+// decoding issues caused by changes in Discord's API.
+// Defaults to "", payloads are only logged.
+func WithPayloadDumpDir(dir string) ClientOption {
+	return func(c *Client) {
+		c.payloadDumpDir = dir
+	}
+}
+
+// WithRawExtra allows you to specify whether the fields of Gateway payloads that are
+// not known by Harmony are kept in the RawExtra field of channels, guilds, messages
+// and users. Finding them requires decoding payloads a second time, so it is
+// disabled by default.
+func WithRawExtra(y bool) ClientOption {
+	return func(c *Client) {
+		c.rawExtra = y
+	}
+}
+
+// decodeEvent decodes the payload of the given event into v. Decoding is
+// tolerant: fields with an unexpected type are left to their zero value and
+// the rest of the event is still decoded. It reports whether v can be
+// dispatched, which is only false if the payload is not valid JSON. Errors
+// are logged and, if WithPayloadDumpDir is set, the payload is dumped to disk.
+// Unknown fields are only collected if WithRawExtra is set.
+func (c *Client) decodeEvent(typ string, data json.RawMessage, v interface{}) bool {
+	err := json.Unmarshal(data, v)
+	if err != nil {
+		c.recordBadPayload(typ, data, err)
+
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return false
+		}
+	}
+
+	if s, ok := v.(rawExtraSetter); ok && c.rawExtra {
+		s.setRawExtra(unknownFields(data, v))
+	}
+
+	return true
+}
+
+// guildCreate is used to decode GUILD_CREATE events. Members and presences,
+// which make most of the payload of large guilds, are decoded separately.
+type guildCreate struct {
+	*Guild
+	Members   json.RawMessage `json:"members,omitempty"`
+	Presences json.RawMessage `json:"presences,omitempty"`
+}
+
+// decodeGuildCreate is like decodeEvent for GUILD_CREATE events. The members
+// and presences of the guild are decoded incrementally, yielding the processor
+// between chunks, so decoding large guilds does not stall other goroutines such
+// as event handlers.
+func (c *Client) decodeGuildCreate(typ string, data json.RawMessage, g *Guild) bool {
+	gc := &guildCreate{Guild: g}
+	if !c.decodeEvent(typ, data, gc) {
+		return false
+	}
+
+	if err := decodeChunked(gc.Members, &g.Members); err != nil {
+		c.recordBadPayload(typ, data, err)
+	}
+	if err := decodeChunked(gc.Presences, &g.Presences); err != nil {
+		c.recordBadPayload(typ, data, err)
+	}
+	return true
+}
+
+// decodeChunkSize is the number of elements of a JSON array decoded
+// by decodeChunked before yielding the processor.
+const decodeChunkSize = 1000
+
+// decodeChunked decodes the given JSON array into the slice pointed to by v,
+// one element at a time, yielding the processor every decodeChunkSize elements.
+// Like decodeEvent, elements with fields of an unexpected type are still decoded,
+// the first such error is returned once the whole array is decoded.
+func decodeChunked(data json.RawMessage, v interface{}) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil {
+		return err
+	} else if t != json.Delim('[') {
+		return fmt.Errorf("expected JSON array, got %v", t)
+	}
+
+	slice := reflect.ValueOf(v).Elem()
+	var typeErr error
+	for i := 0; dec.More(); i++ {
+		if i > 0 && i%decodeChunkSize == 0 {
+			runtime.Gosched()
+		}
+
+		elem := reflect.New(slice.Type().Elem())
+		if err := dec.Decode(elem.Interface()); err != nil {
+			var e *json.UnmarshalTypeError
+			if !errors.As(err, &e) {
+				return err
+			}
+			if typeErr == nil {
+				typeErr = err
+			}
+		}
+		slice.Set(reflect.Append(slice, elem.Elem()))
+	}
+	return typeErr
+}
+
+// recordBadPayload logs the given decoding error and dumps the payload
+// that caused it if the client has a dump directory.
+func (c *Client) recordBadPayload(typ string, data json.RawMessage, err error) {
+	c.logger.Warnf("could not fully decode %s event: %v", typ, err)
+
+	if c.payloadDumpDir == "" {
+		return
+	}
+
+	name := fmt.Sprintf("%s-%d.json", strings.ToLower(typ), c.clock.Now().UnixNano())
+	path := filepath.Join(c.payloadDumpDir, name)
+	if err = os.MkdirAll(c.payloadDumpDir, 0o755); err == nil {
+		err = ioutil.WriteFile(path, data, 0o644)
+	}
+	if err != nil {
+		c.logger.Errorf("could not dump %s event payload: %v", typ, err)
+		return
+	}
+	c.logger.Infof("dumped %s event payload to %s", typ, path)
+}
+
+// unknownFields returns the top level fields of data that have no
+// corresponding field in v, or nil if there are none.
+func unknownFields(data json.RawMessage, v interface{}) RawExtra {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+
+	known := knownFields(reflect.TypeOf(v))
+	var extra RawExtra
+	for name, value := range fields {
+		if _, ok := known[name]; ok {
+			continue
+		}
+		if extra == nil {
+			extra = make(RawExtra)
+		}
+		extra[name] = value
+	}
+	return extra
+}
+
+// knownFieldsCache caches the set of JSON field names per type.
+var knownFieldsCache sync.Map // map[reflect.Type]map[string]struct{}
+
+// knownFields returns the set of JSON field names the given struct type
+// (or pointer to struct type) is decoded from.
+func knownFields(t reflect.Type) map[string]struct{} {
+	if known, ok := knownFieldsCache.Load(t); ok {
+		return known.(map[string]struct{})
+	}
+
+	known := make(map[string]struct{})
+	collectFields(t, known)
+	knownFieldsCache.Store(t, known)
+	return known
+}
+
+func collectFields(t reflect.Type, known map[string]struct{}) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			collectFields(f.Type, known)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue // Unexported.
+		}
+		if name == "" {
+			name = f.Name
+		}
+		known[name] = struct{}{}
+	}
+}
@@ -0,0 +1,202 @@
+package harmony
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestDecodeEvent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "harmony")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name     string
+		data     string
+		rawExtra bool
+		ok       bool
+		expected *Message
+		dumped   bool
+	}{
+		{
+			name:     "valid",
+			data:     `{"id":"1","content":"hello","new_field":"x"}`,
+			ok:       true,
+			expected: &Message{ID: "1", Content: "hello"},
+		},
+		{
+			name:     "raw extra",
+			data:     `{"id":"1","content":"hello","new_field":"x"}`,
+			rawExtra: true,
+			ok:       true,
+			expected: &Message{ID: "1", Content: "hello", RawExtra: RawExtra{"new_field": json.RawMessage(`"x"`)}},
+		},
+		{
+			name:     "unexpected type",
+			data:     `{"id":"1","content":42,"pinned":true}`,
+			ok:       true,
+			expected: &Message{ID: "1", Pinned: true},
+			dumped:   true,
+		},
+		{
+			name:     "invalid JSON",
+			data:     `{"id":"1",`,
+			ok:       false,
+			expected: &Message{},
+			dumped:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := filepath.Join(dir, tt.name)
+			c, err := NewClient("token", WithPayloadDumpDir(sub), WithRawExtra(tt.rawExtra))
+			if err != nil {
+				t.Fatalf("could not create client: %v", err)
+			}
+
+			msg := &Message{}
+			if ok := c.decodeEvent("MESSAGE_CREATE", json.RawMessage(tt.data), msg); ok != tt.ok {
+				t.Errorf("expected decodeEvent to return %t, got %t", tt.ok, ok)
+			}
+			if !reflect.DeepEqual(msg, tt.expected) {
+				t.Errorf("expected message to be %+v, got %+v", tt.expected, msg)
+			}
+
+			files, _ := ioutil.ReadDir(sub)
+			if dumped := len(files) == 1; dumped != tt.dumped {
+				t.Fatalf("expected payload to be dumped: %t, got %d files", tt.dumped, len(files))
+			}
+			if tt.dumped {
+				b, err := ioutil.ReadFile(filepath.Join(sub, files[0].Name()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(b) != tt.data {
+					t.Errorf("expected the payload to be dumped as is, got %s", b)
+				}
+			}
+		})
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	type embedded struct {
+		Embedded string `json:"embedded"`
+	}
+	type v struct {
+		embedded
+		Name     string `json:"name,omitempty"`
+		Ignored  string `json:"-"`
+		Untagged string
+		private  string
+	}
+
+	data := json.RawMessage(`{"embedded":"1","name":"2","Untagged":"3","Ignored":"4","private":"5","-":"6"}`)
+	expected := RawExtra{
+		"Ignored": json.RawMessage(`"4"`),
+		"private": json.RawMessage(`"5"`),
+		"-":       json.RawMessage(`"6"`),
+	}
+	if extra := unknownFields(data, &v{}); !reflect.DeepEqual(extra, expected) {
+		t.Errorf("expected unknown fields to be %v, got %v", expected, extra)
+	}
+
+	if extra := unknownFields(json.RawMessage(`{"name":"2"}`), &v{}); extra != nil {
+		t.Errorf("expected no unknown fields, got %v", extra)
+	}
+}
+
+func TestDecodeChunked(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []GuildMember
+		err      bool
+	}{
+		{name: "empty"},
+		{name: "null", data: `null`},
+		{name: "empty array", data: `[]`},
+		{
+			name:     "members",
+			data:     `[{"nick":"a"},{"nick":"b"}]`,
+			expected: []GuildMember{{Nick: "a"}, {Nick: "b"}},
+		},
+		{
+			name:     "unexpected type",
+			data:     `[{"nick":1},{"nick":"b"}]`,
+			expected: []GuildMember{{}, {Nick: "b"}},
+			err:      true,
+		},
+		{name: "not an array", data: `{"nick":"a"}`, err: true},
+		{name: "invalid JSON", data: `[{"nick":"a"},`, expected: []GuildMember{{Nick: "a"}}, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var members []GuildMember
+			err := decodeChunked(json.RawMessage(tt.data), &members)
+			if (err != nil) != tt.err {
+				t.Errorf("expected error: %t, got %v", tt.err, err)
+			}
+			if !reflect.DeepEqual(members, tt.expected) {
+				t.Errorf("expected members to be %+v, got %+v", tt.expected, members)
+			}
+		})
+	}
+}
+
+func TestDecodeChunkedLarge(t *testing.T) {
+	n := 2*decodeChunkSize + 1
+	members := make([]GuildMember, n)
+	for i := range members {
+		members[i].Nick = strconv.Itoa(i)
+	}
+	data, err := json.Marshal(members)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded []GuildMember
+	if err = decodeChunked(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, members) {
+		t.Errorf("expected %d members to be decoded in order, got %d", n, len(decoded))
+	}
+}
+
+func TestDecodeGuildCreate(t *testing.T) {
+	c, err := NewClient("token")
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	data := json.RawMessage(`{"id":"1","name":"guild",
+		"members":[{"nick":"a"},{"nick":1}],
+		"presences":[{"roles":["2"]}]}`)
+	g := &Guild{}
+	if !c.decodeGuildCreate("GUILD_CREATE", data, g) {
+		t.Fatal("expected the guild to be dispatched")
+	}
+	if g.ID != "1" || g.Name != "guild" {
+		t.Errorf("expected guild 1, got %+v", g)
+	}
+	if len(g.Members) != 2 || g.Members[0].Nick != "a" {
+		t.Errorf("expected both members to be decoded, got %+v", g.Members)
+	}
+	if len(g.Presences) != 1 || !reflect.DeepEqual(g.Presences[0].Roles, []string{"2"}) {
+		t.Errorf("expected the presence to be decoded, got %+v", g.Presences)
+	}
+
+	if c.decodeGuildCreate("GUILD_CREATE", json.RawMessage(`{"id":`), &Guild{}) {
+		t.Error("expected an invalid guild not to be dispatched")
+	}
+}
@@ -21,6 +21,7 @@ const (
 	eventGuildBanAdd                = "GUILD_BAN_ADD"
 	eventGuildBanRemove             = "GUILD_BAN_REMOVE"
 	eventGuildEmojisUpdate          = "GUILD_EMOJIS_UPDATE"
+	eventGuildStickersUpdate        = "GUILD_STICKERS_UPDATE"
 	eventGuildIntegrationsUpdate    = "GUILD_INTEGRATIONS_UPDATE"
 	eventGuildMemberAdd             = "GUILD_MEMBER_ADD"
 	eventGuildMemberRemove          = "GUILD_MEMBER_REMOVE"
@@ -31,6 +32,7 @@ const (
 	eventGuildRoleDelete            = "GUILD_ROLE_DELETE"
 	eventGuildInviteCreate          = "INVITE_CREATE"
 	eventGuildInviteDelete          = "INVITE_DELETE"
+	eventInteractionCreate          = "INTERACTION_CREATE"
 	eventMessageCreate              = "MESSAGE_CREATE"
 	eventMessageUpdate              = "MESSAGE_UPDATE"
 	eventMessageDelete              = "MESSAGE_DELETE"
@@ -41,26 +43,56 @@ const (
 	eventMessageReactionRemoveAll   = "MESSAGE_REACTION_REMOVE_ALL"
 	eventMessageReactionRemoveEmoji = "MESSAGE_REACTION_REMOVE_EMOJI"
 	eventPresenceUpdate             = "PRESENCE_UPDATE"
+	eventThreadCreate               = "THREAD_CREATE"
+	eventThreadUpdate               = "THREAD_UPDATE"
+	eventThreadDelete               = "THREAD_DELETE"
+	eventThreadListSync             = "THREAD_LIST_SYNC"
+	eventThreadMemberUpdate         = "THREAD_MEMBER_UPDATE"
+	eventThreadMembersUpdate        = "THREAD_MEMBERS_UPDATE"
 	eventTypingStart                = "TYPING_START"
 	eventUserUpdate                 = "USER_UPDATE"
+	eventStageInstanceCreate        = "STAGE_INSTANCE_CREATE"
+	eventStageInstanceUpdate        = "STAGE_INSTANCE_UPDATE"
+	eventStageInstanceDelete        = "STAGE_INSTANCE_DELETE"
 	eventVoiceStateUpdate           = "VOICE_STATE_UPDATE"
 	eventVoiceServerUpdate          = "VOICE_SERVER_UPDATE"
 	eventWebhooksUpdate             = "WEBHOOKS_UPDATE"
 )
 
+// Events fired when users subscribe to or unsubscribe from guild scheduled events.
+const (
+	eventGuildScheduledEventUserAdd    = "GUILD_SCHEDULED_EVENT_USER_ADD"
+	eventGuildScheduledEventUserRemove = "GUILD_SCHEDULED_EVENT_USER_REMOVE"
+)
+
+// eventMessageComponent is not a Gateway event but the key of the handler of
+// INTERACTION_CREATE events triggered by message components.
+const eventMessageComponent = "MESSAGE_COMPONENT"
+
+// eventModalSubmit is not a Gateway event but the key of the handler of
+// INTERACTION_CREATE events triggered by submitting modals.
+const eventModalSubmit = "MODAL_SUBMIT"
+
+// eventRaw is not a Gateway event but the key of the handler
+// called with every Dispatch event, see OnRawEvent.
+const eventRaw = "RAW"
+
+// eventCloudflareBan is not a Gateway event but the key of the handler
+// called when Cloudflare bans the client, see OnCloudflareBan.
+const eventCloudflareBan = "CLOUDFLARE_BAN"
+
 // NOTE: consider using a map[string]sync.Pool to cache event objects.
 
 // dispatch dispatches events to user handlers, updating the State
 // if it is enabled.
 func (c *Client) dispatch(typ string, data json.RawMessage) error {
-	var err error
 	switch typ {
 	case eventHello:
 	case eventReady:
 		c.connected.Store(true)
 		var r Ready
-		if err = json.Unmarshal(data, &r); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &r) {
+			return nil
 		}
 		c.handle(eventReady, &r)
 	case eventResumed:
@@ -69,8 +101,8 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 
 	case eventChannelCreate:
 		var ch Channel
-		if err = json.Unmarshal(data, &ch); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &ch) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.updateChannel(&ch)
@@ -78,8 +110,8 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 		c.handle(eventChannelCreate, &ch)
 	case eventChannelUpdate:
 		var ch Channel
-		if err = json.Unmarshal(data, &ch); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &ch) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.updateChannel(&ch)
@@ -87,8 +119,8 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 		c.handle(eventChannelUpdate, &ch)
 	case eventChannelDelete:
 		var ch Channel
-		if err = json.Unmarshal(data, &ch); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &ch) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.removeChannel(&ch)
@@ -97,27 +129,79 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 
 	case eventChannelPinsUpdate:
 		var pins ChannelPinsUpdate
-		if err = json.Unmarshal(data, &pins); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &pins) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.updatePins(&pins)
 		}
 		c.handle(eventChannelPinsUpdate, &pins)
 
+	case eventThreadCreate:
+		var th Channel
+		if !c.decodeEvent(typ, data, &th) {
+			return nil
+		}
+		if c.withStateTracking {
+			c.State.updateChannel(&th)
+		}
+		c.handle(eventThreadCreate, &th)
+	case eventThreadUpdate:
+		var th Channel
+		if !c.decodeEvent(typ, data, &th) {
+			return nil
+		}
+		if c.withStateTracking {
+			c.State.updateChannel(&th)
+		}
+		c.handle(eventThreadUpdate, &th)
+	case eventThreadDelete:
+		var th Channel
+		if !c.decodeEvent(typ, data, &th) {
+			return nil
+		}
+		if c.withStateTracking {
+			c.State.removeChannel(&th)
+		}
+		c.handle(eventThreadDelete, &th)
+	case eventThreadListSync:
+		var sync ThreadListSync
+		if !c.decodeEvent(typ, data, &sync) {
+			return nil
+		}
+		if c.withStateTracking {
+			c.State.syncThreads(&sync)
+		}
+		c.handle(eventThreadListSync, &sync)
+	case eventThreadMemberUpdate:
+		var tmu ThreadMemberUpdate
+		if !c.decodeEvent(typ, data, &tmu) {
+			return nil
+		}
+		c.handle(eventThreadMemberUpdate, &tmu)
+	case eventThreadMembersUpdate:
+		var tmu ThreadMembersUpdate
+		if !c.decodeEvent(typ, data, &tmu) {
+			return nil
+		}
+		c.handle(eventThreadMembersUpdate, &tmu)
+
 	case eventGuildCreate:
 		var g Guild
-		if err = json.Unmarshal(data, &g); err != nil {
-			return err
+		if !c.decodeGuildCreate(typ, data, &g) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.updateGuild(&g)
 		}
+		if c.memberChunking {
+			c.requestAllGuildMembers(&g)
+		}
 		c.handle(eventGuildCreate, &g)
 	case eventGuildUpdate:
 		var g Guild
-		if err = json.Unmarshal(data, &g); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &g) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.updateGuild(&g)
@@ -125,8 +209,8 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 		c.handle(eventGuildUpdate, &g)
 	case eventGuildDelete:
 		var g UnavailableGuild
-		if err = json.Unmarshal(data, &g); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &g) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.removeGuild(&g)
@@ -135,38 +219,51 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 
 	case eventGuildBanAdd:
 		var ban GuildBan
-		if err = json.Unmarshal(data, &ban); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &ban) {
+			return nil
 		}
 		c.handle(eventGuildBanAdd, &ban)
 	case eventGuildBanRemove:
 		var ban GuildBan
-		if err = json.Unmarshal(data, &ban); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &ban) {
+			return nil
 		}
 		c.handle(eventGuildBanRemove, &ban)
 
 	case eventGuildEmojisUpdate:
 		var ge GuildEmojis
-		if err = json.Unmarshal(data, &ge); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &ge) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.updateGuildEmojis(ge.GuildID, ge.Emojis)
 		}
 		c.handle(eventGuildEmojisUpdate, &ge)
 
+	case eventGuildStickersUpdate:
+		var gs GuildStickers
+		if !c.decodeEvent(typ, data, &gs) {
+			return nil
+		}
+		if c.withStateTracking {
+			c.State.updateGuildStickers(gs.GuildID, gs.Stickers)
+		}
+		c.handle(eventGuildStickersUpdate, &gs)
+
 	case eventGuildIntegrationsUpdate:
 		var giu GuildIntegrationUpdate
-		if err = json.Unmarshal(data, &giu); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &giu) {
+			return nil
+		}
+		if c.withStateTracking {
+			c.State.invalidateIntegrations(giu.GuildID)
 		}
 		c.handle(eventGuildIntegrationsUpdate, &giu)
 
 	case eventGuildMemberAdd:
 		var m GuildMemberAdd
-		if err = json.Unmarshal(data, &m); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &m) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.guildMemberAdd(&m)
@@ -174,8 +271,8 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 		c.handle(eventGuildMemberAdd, &m)
 	case eventGuildMemberRemove:
 		var m GuildMemberRemove
-		if err = json.Unmarshal(data, &m); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &m) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.guildMemberRemove(&m)
@@ -183,8 +280,8 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 		c.handle(eventGuildMemberRemove, &m)
 	case eventGuildMemberUpdate:
 		var m GuildMemberUpdate
-		if err = json.Unmarshal(data, &m); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &m) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.guildMemberUpdate(&m)
@@ -193,25 +290,19 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 
 	case eventGuildMembersChunk:
 		var chunk GuildMembersChunk
-		if err = json.Unmarshal(data, &chunk); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &chunk) {
+			return nil
 		}
 		if c.withStateTracking {
-			for _, m := range chunk.Members {
-				c.State.guildMemberUpdate(&GuildMemberUpdate{
-					GuildID: chunk.GuildID,
-					Roles:   m.Roles,
-					User:    m.User,
-					Nick:    m.Nick,
-				})
-			}
+			c.State.guildMembersChunk(&chunk)
 		}
+		c.deliverMemberChunk(&chunk)
 		c.handle(eventGuildMembersChunk, &chunk)
 
 	case eventGuildRoleCreate:
 		var gr GuildRole
-		if err = json.Unmarshal(data, &gr); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &gr) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.guildRoleCreate(&gr)
@@ -219,8 +310,8 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 		c.handle(eventGuildRoleCreate, &gr)
 	case eventGuildRoleUpdate:
 		var gr GuildRole
-		if err = json.Unmarshal(data, &gr); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &gr) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.guildRoleUpdate(&gr)
@@ -228,86 +319,125 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 		c.handle(eventGuildRoleUpdate, &gr)
 	case eventGuildRoleDelete:
 		var gr GuildRoleDelete
-		if err = json.Unmarshal(data, &gr); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &gr) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.guildRoleRemove(&gr)
 		}
 		c.handle(eventGuildRoleDelete, &gr)
+	case eventGuildScheduledEventUserAdd, eventGuildScheduledEventUserRemove:
+		var u GuildScheduledEventUser
+		if !c.decodeEvent(typ, data, &u) {
+			return nil
+		}
+		c.handle(typ, &u)
+
 	case eventGuildInviteCreate:
 		var gic GuildInviteCreate
-		if err = json.Unmarshal(data, &gic); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &gic) {
+			return nil
 		}
 		c.handle(eventGuildInviteCreate, &gic)
 	case eventGuildInviteDelete:
 		var gid GuildInviteDelete
-		if err = json.Unmarshal(data, &gid); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &gid) {
+			return nil
 		}
 		c.handle(eventGuildInviteDelete, &gid)
 
+	case eventInteractionCreate:
+		var i Interaction
+		if !c.decodeEvent(typ, data, &i) {
+			return nil
+		}
+		c.publish(eventInteractionCreate, &i)
+		// Message component and modal submit interactions go to the
+		// OnMessageComponent and OnModalSubmit handlers if there are
+		// some, the OnInteractionCreate one otherwise.
+		switch {
+		case i.Type == InteractionTypeMessageComponent && c.runHandler(eventMessageComponent, &i):
+		case i.Type == InteractionTypeModalSubmit && c.runHandler(eventModalSubmit, &i):
+		default:
+			c.runHandler(eventInteractionCreate, &i)
+		}
+
 	case eventMessageCreate:
 		var msg Message
-		if err = json.Unmarshal(data, &msg); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &msg) {
+			return nil
+		}
+		if c.withStateTracking {
+			c.State.addMessage(&msg)
 		}
 		c.handle(eventMessageCreate, &msg)
 	case eventMessageUpdate:
 		var msg Message
-		if err = json.Unmarshal(data, &msg); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &msg) {
+			return nil
+		}
+		if c.withStateTracking {
+			c.State.updateMessage(&msg, data)
 		}
 		c.handle(eventMessageUpdate, &msg)
 	case eventMessageDelete:
 		var md MessageDelete
-		if err = json.Unmarshal(data, &md); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &md) {
+			return nil
+		}
+		if c.withStateTracking {
+			md.Message = c.State.removeMessage(md.ChannelID, md.MessageID)
 		}
 		c.handle(eventMessageDelete, &md)
 	case eventMessageDeleteBulk:
 		var md MessageDeleteBulk
-		if err = json.Unmarshal(data, &md); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &md) {
+			return nil
+		}
+		if c.withStateTracking {
+			for _, id := range md.IDs {
+				if m := c.State.removeMessage(md.ChannelID, id); m != nil {
+					md.Messages = append(md.Messages, *m)
+				}
+			}
 		}
 		c.handle(eventMessageDeleteBulk, &md)
 	case eventMessageAck:
 		var ma MessageAck
-		if err = json.Unmarshal(data, &ma); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &ma) {
+			return nil
 		}
 		c.handle(eventMessageAck, &ma)
 
 	case eventMessageReactionAdd:
 		var mr MessageReaction
-		if err = json.Unmarshal(data, &mr); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &mr) {
+			return nil
 		}
 		c.handle(eventMessageReactionAdd, &mr)
 	case eventMessageReactionRemove:
 		var mr MessageReaction
-		if err = json.Unmarshal(data, &mr); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &mr) {
+			return nil
 		}
 		c.handle(eventMessageReactionRemove, &mr)
 	case eventMessageReactionRemoveAll:
 		var mr MessageReactionRemoveAll
-		if err = json.Unmarshal(data, &mr); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &mr) {
+			return nil
 		}
 		c.handle(eventMessageReactionRemoveAll, &mr)
 	case eventMessageReactionRemoveEmoji:
 		var m MessageReactionRemoveEmoji
-		if err = json.Unmarshal(data, &m); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &m) {
+			return nil
 		}
 		c.handle(eventMessageReactionRemoveEmoji, &m)
 
 	case eventPresenceUpdate:
 		var p Presence
-		if err = json.Unmarshal(data, &p); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &p) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.updatePresence(&p)
@@ -316,15 +446,15 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 
 	case eventTypingStart:
 		var ts TypingStart
-		if err = json.Unmarshal(data, &ts); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &ts) {
+			return nil
 		}
 		c.handle(eventTypingStart, &ts)
 
 	case eventUserUpdate:
 		var u User
-		if err = json.Unmarshal(data, &u); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &u) {
+			return nil
 		}
 		if c.withStateTracking {
 			c.State.updateUser(&u)
@@ -333,8 +463,8 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 
 	case eventVoiceStateUpdate:
 		var vs voice.StateUpdate
-		if err = json.Unmarshal(data, &vs); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &vs) {
+			return nil
 		}
 
 		// If this update concerns a voice connection managed
@@ -344,7 +474,7 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 		// reconnect to a wrong channel or with a wrong state
 		// (deafen/muted) if it had to reconnect.
 		if vs.UserID == c.userID && vs.ChannelID != nil {
-			conn, ok := c.voiceConnections[vs.GuildID]
+			conn, ok := c.voiceConnection(vs.GuildID)
 			if ok {
 				conn.SetState(&vs.State)
 			}
@@ -356,16 +486,16 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 		c.handle(eventVoiceStateUpdate, &vs)
 	case eventVoiceServerUpdate:
 		var vs voice.ServerUpdate
-		if err = json.Unmarshal(data, &vs); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &vs) {
+			return nil
 		}
 
 		// If this update concerns a voice connection managed
 		// by the Client, make sure to update it accordingly
 		// so it can connect to the new voice server.
-		if conn, ok := c.voiceConnections[vs.GuildID]; ok {
+		if conn, ok := c.voiceConnection(vs.GuildID); ok {
 			go func() {
-				if err = conn.UpdateServer(&vs); err != nil {
+				if err := conn.UpdateServer(&vs); err != nil {
 					c.logger.Errorf("could not update voice server (guild=%q): %v", vs.GuildID, err)
 					return
 				}
@@ -377,28 +507,52 @@ func (c *Client) dispatch(typ string, data json.RawMessage) error {
 
 	case eventWebhooksUpdate:
 		var wu WebhooksUpdate
-		if err = json.Unmarshal(data, &wu); err != nil {
-			return err
+		if !c.decodeEvent(typ, data, &wu) {
+			return nil
+		}
+		if c.withStateTracking {
+			c.State.invalidateWebhooks(wu.ChannelID)
 		}
 		c.handle(eventWebhooksUpdate, &wu)
 
+	case eventStageInstanceCreate, eventStageInstanceUpdate, eventStageInstanceDelete:
+		var si StageInstance
+		if !c.decodeEvent(typ, data, &si) {
+			return nil
+		}
+		c.handle(typ, &si)
+
 	default:
+		c.recordUnknownEvent(typ)
 		c.logger.Infof("unrecognized event %s: %s", typ, string(data))
+		// Let channels returned by Events receive events this
+		// version does not know about yet, see OnRawEvent.
+		c.publish(typ, &RawEvent{Type: typ, Data: data})
 		return nil
 	}
-	return err
+	return nil
 }
 
 // handle calls the registered user event handler for the given event,
-// if there is one.
+// if there is one, and sends the event to channels returned by Events.
 func (c *Client) handle(event string, d interface{}) {
+	c.publish(event, d)
+	c.runHandler(event, d)
+}
+
+// runHandler calls the registered user event handler for the given
+// event and reports whether there was one.
+func (c *Client) runHandler(event string, d interface{}) bool {
 	c.handlersMu.RLock()
 	h, ok := c.handlers[event]
+	middlewares := c.middlewares
+	ctx := c.handlersCtx
 	c.handlersMu.RUnlock()
 	if ok {
 		// Call the registered handler in its own goroutine
 		// so it does not block the dispatcher and events
 		// can continue to be treated as we receive them.
-		go h.handle(d)
+		go c.callHandler(ctx, event, h, middlewares, d)
 	}
+	return ok
 }
@@ -0,0 +1,296 @@
+//go:build e2e
+// +build e2e
+
+package harmony_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/harmonytest/sandbox"
+	"github.com/skwair/harmony/invite"
+	"github.com/skwair/harmony/permission"
+	"github.com/skwair/harmony/role"
+)
+
+// End to end tests of the REST resources, run against a sandbox guild with:
+//
+//	HARMONY_TEST_BOT_TOKEN=... HARMONY_TEST_GUILD_ID=... go test -tags e2e -run E2E .
+//
+// or against a disposable guild created from a template with:
+//
+//	HARMONY_TEST_BOT_TOKEN=... HARMONY_TEST_TEMPLATE=... go test -tags e2e -run E2E .
+
+func TestMain(m *testing.M) {
+	os.Exit(sandbox.Main(m))
+}
+
+func TestE2EGuild(t *testing.T) {
+	sb := sandbox.New(t)
+	defer sb.Close()
+
+	g, err := sb.Guild().Get(context.Background())
+	if err != nil {
+		t.Fatalf("could not get guild: %v", err)
+	}
+	if g.ID != sb.GuildID {
+		t.Fatalf("expected guild ID to be %s; got %s", sb.GuildID, g.ID)
+	}
+
+	ch := sb.TextChannel()
+
+	chs, err := sb.Guild().Channels(context.Background())
+	if err != nil {
+		t.Fatalf("could not get guild channels: %v", err)
+	}
+	if !hasChannel(chs, ch.ID) {
+		t.Fatalf("expected guild channels to contain %q", ch.Name)
+	}
+}
+
+func TestE2EChannels(t *testing.T) {
+	sb := sandbox.New(t)
+	defer sb.Close()
+
+	cat := sb.Category()
+	ch := sb.TextChannel(channel.WithParent(cat.ID), channel.WithTopic("created"))
+	if ch.ParentID != cat.ID {
+		t.Fatalf("expected parent ID to be %s; got %s", cat.ID, ch.ParentID)
+	}
+
+	ctx := context.Background()
+
+	modified, err := sb.Client.Channel(ch.ID).Modify(ctx, channel.NewSettings(channel.WithTopic("modified")))
+	if err != nil {
+		t.Fatalf("could not modify channel: %v", err)
+	}
+	if modified.Topic != "modified" {
+		t.Fatalf("expected topic to be %q; got %q", "modified", modified.Topic)
+	}
+
+	got, err := sb.Client.Channel(ch.ID).Get(ctx)
+	if err != nil {
+		t.Fatalf("could not get channel: %v", err)
+	}
+	if got.Topic != "modified" {
+		t.Fatalf("expected topic to be %q; got %q", "modified", got.Topic)
+	}
+
+	voice := sb.VoiceChannel(channel.WithUserLimit(2))
+	if voice.UserLimit != 2 {
+		t.Fatalf("expected user limit to be %d; got %d", 2, voice.UserLimit)
+	}
+
+	if _, err = sb.Client.Channel(voice.ID).Delete(ctx); err != nil {
+		t.Fatalf("could not delete channel: %v", err)
+	}
+	if _, err = sb.Client.Channel(voice.ID).Get(ctx); err == nil {
+		t.Fatal("expected deleted channel to be unknown")
+	}
+}
+
+func TestE2EMessages(t *testing.T) {
+	sb := sandbox.New(t)
+	defer sb.Close()
+
+	ch := sb.TextChannel()
+	first := sb.Message(ch.ID, "first")
+	last := sb.Message(ch.ID, "last")
+
+	c := sb.Client.Channel(ch.ID)
+	ctx := context.Background()
+
+	msgs, err := c.Messages(ctx, "<"+last.ID, 0)
+	if err != nil {
+		t.Fatalf("could not get messages: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].ID != first.ID {
+		t.Fatalf("expected to get the first message only; got %d messages", len(msgs))
+	}
+
+	if _, err = c.EditMessage(ctx, last.ID, "edited"); err != nil {
+		t.Fatalf("could not edit message: %v", err)
+	}
+	msg, err := c.Message(ctx, last.ID)
+	if err != nil {
+		t.Fatalf("could not get message: %v", err)
+	}
+	if msg.Content != "edited" {
+		t.Fatalf("expected content to be %q; got %q", "edited", msg.Content)
+	}
+
+	if err = c.AddReaction(ctx, last.ID, "👍"); err != nil {
+		t.Fatalf("could not add reaction: %v", err)
+	}
+	users, err := c.Reactions(ctx, last.ID, "👍", 0, "", "")
+	if err != nil {
+		t.Fatalf("could not get reactions: %v", err)
+	}
+	if len(users) != 1 {
+		t.Fatalf("expected %d user to have reacted; got %d", 1, len(users))
+	}
+	if err = c.RemoveAllReactions(ctx, last.ID); err != nil {
+		t.Fatalf("could not remove reactions: %v", err)
+	}
+
+	if err = c.PinMessage(ctx, first.ID); err != nil {
+		t.Fatalf("could not pin message: %v", err)
+	}
+	pins, err := c.Pins(ctx)
+	if err != nil {
+		t.Fatalf("could not get pins: %v", err)
+	}
+	if len(pins) != 1 || pins[0].ID != first.ID {
+		t.Fatalf("expected the first message to be the only pin; got %d pins", len(pins))
+	}
+	if err = c.UnpinMessage(ctx, first.ID); err != nil {
+		t.Fatalf("could not unpin message: %v", err)
+	}
+
+	if err = c.DeleteMessage(ctx, first.ID); err != nil {
+		t.Fatalf("could not delete message: %v", err)
+	}
+}
+
+func TestE2EMessageEvents(t *testing.T) {
+	sb := sandbox.New(t)
+	defer sb.Close()
+
+	created := make(chan *harmony.Message, 1)
+	sb.Client.OnMessageCreate(func(m *harmony.Message) {
+		select {
+		case created <- m:
+		default:
+		}
+	})
+	sb.Connect()
+
+	ch := sb.TextChannel()
+	sent := sb.Message(ch.ID, "event")
+
+	select {
+	case m := <-created:
+		if m.ID != sent.ID {
+			t.Fatalf("expected message ID to be %s; got %s", sent.ID, m.ID)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("did not receive message create event")
+	}
+}
+
+func TestE2ERoles(t *testing.T) {
+	sb := sandbox.New(t)
+	defer sb.Close()
+
+	r := sb.Role(
+		role.WithColor(0x336677),
+		role.WithMentionable(true),
+		role.WithPermissions(uint64(permission.ReadMessageHistory|permission.SendMessages)),
+	)
+	if r.Color != 0x336677 || !r.Mentionable {
+		t.Fatalf("expected role to be created with its settings; got %+v", r)
+	}
+
+	ctx := context.Background()
+
+	modified, err := sb.Guild().ModifyRole(ctx, r.ID, role.NewSettings(role.WithHoist(true)))
+	if err != nil {
+		t.Fatalf("could not modify role: %v", err)
+	}
+	if !modified.Hoist {
+		t.Fatal("expected role to be hoisted")
+	}
+
+	me, err := sb.Client.CurrentUser().Get(ctx)
+	if err != nil {
+		t.Fatalf("could not get current user: %v", err)
+	}
+
+	if err = sb.Guild().AddMemberRole(ctx, me.ID, r.ID); err != nil {
+		t.Fatalf("could not add role: %v", err)
+	}
+	member, err := sb.Guild().Member(ctx, me.ID)
+	if err != nil {
+		t.Fatalf("could not get guild member: %v", err)
+	}
+	if !member.HasRole(r.ID) {
+		t.Fatal("guild member should have the role")
+	}
+
+	if err = sb.Guild().RemoveMemberRole(ctx, me.ID, r.ID); err != nil {
+		t.Fatalf("could not remove role: %v", err)
+	}
+	member, err = sb.Guild().Member(ctx, me.ID)
+	if err != nil {
+		t.Fatalf("could not get guild member: %v", err)
+	}
+	if member.HasRole(r.ID) {
+		t.Fatal("guild member should not have the role anymore")
+	}
+}
+
+func TestE2EWebhooks(t *testing.T) {
+	sb := sandbox.New(t)
+	defer sb.Close()
+
+	ch := sb.TextChannel()
+	wh := sb.Webhook(ch.ID)
+
+	ctx := context.Background()
+
+	whs, err := sb.Client.Channel(ch.ID).Webhooks(ctx)
+	if err != nil {
+		t.Fatalf("could not get channel webhooks: %v", err)
+	}
+	if len(whs) != 1 || whs[0].ID != wh.ID {
+		t.Fatalf("expected the channel to have a single webhook; got %d", len(whs))
+	}
+
+	msg, err := sb.Client.Webhook(wh.ID).Execute(ctx, wh.Token, &harmony.WebhookParameters{Content: "webhook"}, true)
+	if err != nil {
+		t.Fatalf("could not execute webhook: %v", err)
+	}
+	if msg.Content != "webhook" || msg.WebhookID != wh.ID {
+		t.Fatalf("expected message to be sent by the webhook; got %+v", msg)
+	}
+}
+
+func TestE2EInvites(t *testing.T) {
+	sb := sandbox.New(t)
+	defer sb.Close()
+
+	ch := sb.TextChannel()
+	i := sb.Invite(ch.ID, invite.WithMaxUses(1))
+	if i.MaxUses != 1 {
+		t.Fatalf("expected the invite to have %d max uses; got %d", 1, i.MaxUses)
+	}
+
+	got, err := sb.Client.Invite(i.Code).Get(context.Background(), false)
+	if err != nil {
+		t.Fatalf("could not get invite: %v", err)
+	}
+	if got.Channel == nil || got.Channel.ID != ch.ID {
+		t.Fatalf("expected the invite to point to channel %s", ch.ID)
+	}
+
+	invites, err := sb.Client.Channel(ch.ID).Invites(context.Background())
+	if err != nil {
+		t.Fatalf("could not get channel invites: %v", err)
+	}
+	if len(invites) != 1 {
+		t.Fatalf("expected the channel to have %d invite; got %d", 1, len(invites))
+	}
+}
+
+func hasChannel(chs []harmony.Channel, id string) bool {
+	for _, ch := range chs {
+		if ch.ID == id {
+			return true
+		}
+	}
+	return false
+}
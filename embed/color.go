@@ -0,0 +1,41 @@
+package embed
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxColor is the maximum value of the color of an embed.
+const MaxColor = 0xffffff
+
+// Some commonly used colors, matching the ones of the Discord client.
+const (
+	ColorBlurple = 0x5865f2
+	ColorGreen   = 0x57f287
+	ColorYellow  = 0xfee75c
+	ColorFuchsia = 0xeb459e
+	ColorRed     = 0xed4245
+	ColorWhite   = 0xffffff
+	ColorBlack   = 0x23272a
+)
+
+// RGB returns the color of an embed from its red, green and blue components.
+func RGB(r, g, b uint8) int {
+	return int(r)<<16 | int(g)<<8 | int(b)
+}
+
+// ParseHexColor returns the color of an embed from its hexadecimal
+// representation, such as "#3277ce" or "3277ce".
+func ParseHexColor(s string) (int, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 {
+		return 0, fmt.Errorf("invalid color %q: must have 6 hexadecimal digits", s)
+	}
+
+	c, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid color %q: %w", s, err)
+	}
+	return int(c), nil
+}
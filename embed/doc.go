@@ -16,7 +16,13 @@ Here is an example of how to create a complex embed :
 		Fields(
 			embed.NewField().Name("field 1").Value("value 1").Build(),
 			embed.NewField().Name("field 2").Value("value 2").Build(),
+		).
+		Field("field 3", "value 3", true).
 		Footer(embed.NewFooter().Text("footer").Build()).
 		Build()
+
+Discord enforces limits on the length of the different parts of embeds, see the
+Max* constants. Embeds are validated before being sent so these limits are
+reported without sending any request, and can also be checked with Validate.
 */
 package embed
@@ -26,11 +26,13 @@ type Builder interface {
 	URL(u string) Builder
 	Timestamp(t time.Time) Builder
 	Color(c int) Builder
+	ColorRGB(r, g, b uint8) Builder
 	Footer(f *Footer) Builder
 	Image(i *Image) Builder
 	Thumbnail(i *Thumbnail) Builder
 	Author(i *Author) Builder
 	Fields(fields ...*Field) Builder
+	Field(name, value string, inline bool) Builder
 	Build() *Embed
 }
 
@@ -72,6 +74,11 @@ func (e *builder) Color(c int) Builder {
 	return e
 }
 
+func (e *builder) ColorRGB(r, g, b uint8) Builder {
+	e.color = RGB(r, g, b)
+	return e
+}
+
 func (e *builder) Footer(f *Footer) Builder {
 	e.footer = f
 	return e
@@ -99,6 +106,13 @@ func (e *builder) Fields(fields ...*Field) Builder {
 	return e
 }
 
+func (e *builder) Field(name, value string, inline bool) Builder {
+	e.fields = append(e.fields, Field{Name: name, Value: value, Inline: inline})
+	return e
+}
+
+// Build returns the embed. It is not validated against the constraints enforced
+// by Discord until it is sent, use Validate to check it beforehand.
 func (e *builder) Build() *Embed {
 	return &Embed{
 		Title:       e.title,
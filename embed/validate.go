@@ -0,0 +1,122 @@
+package embed
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Constraints enforced by Discord on embeds.
+const (
+	MaxTitleLength       = 256
+	MaxDescriptionLength = 4096
+	MaxFields            = 25
+	MaxFieldNameLength   = 256
+	MaxFieldValueLength  = 1024
+	MaxFooterTextLength  = 2048
+	MaxAuthorNameLength  = 256
+	// MaxTotalLength is the maximum number of characters in the title, description,
+	// fields, footer text and author name of all embeds of a message combined.
+	MaxTotalLength = 6000
+	// MaxEmbeds is the maximum number of embeds in a message.
+	MaxEmbeds = 10
+)
+
+// Validate checks this embed against the constraints enforced by Discord and
+// returns an error describing every violated constraint, if any.
+func (e *Embed) Validate() error {
+	problems := e.problems()
+	if e.Length() > MaxTotalLength {
+		problems = append(problems, fmt.Sprintf("total length must be at most %d characters", MaxTotalLength))
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid embed: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ValidateAll is like Validate but checks all embeds of a message, including
+// the constraints on the number of embeds and their combined length.
+func ValidateAll(embeds []Embed) error {
+	var problems []string
+
+	if len(embeds) > MaxEmbeds {
+		problems = append(problems, fmt.Sprintf("a message can have at most %d embeds", MaxEmbeds))
+	}
+
+	total := 0
+	for i := range embeds {
+		for _, p := range embeds[i].problems() {
+			problems = append(problems, fmt.Sprintf("embed %d: %s", i, p))
+		}
+		total += embeds[i].Length()
+	}
+	if total > MaxTotalLength {
+		problems = append(problems, fmt.Sprintf("total length of embeds must be at most %d characters", MaxTotalLength))
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid embeds: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Length returns the number of characters of this embed counting towards
+// MaxTotalLength: its title, description, fields, footer text and author name.
+func (e *Embed) Length() int {
+	l := utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+	for _, f := range e.Fields {
+		l += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+	}
+	if e.Footer != nil {
+		l += utf8.RuneCountInString(e.Footer.Text)
+	}
+	if e.Author != nil {
+		l += utf8.RuneCountInString(e.Author.Name)
+	}
+	return l
+}
+
+// problems returns the constraints violated by this embed,
+// except for its total length.
+func (e *Embed) problems() []string {
+	var problems []string
+
+	if utf8.RuneCountInString(e.Title) > MaxTitleLength {
+		problems = append(problems, fmt.Sprintf("title must be at most %d characters", MaxTitleLength))
+	}
+	if utf8.RuneCountInString(e.Description) > MaxDescriptionLength {
+		problems = append(problems, fmt.Sprintf("description must be at most %d characters", MaxDescriptionLength))
+	}
+
+	if len(e.Fields) > MaxFields {
+		problems = append(problems, fmt.Sprintf("an embed can have at most %d fields", MaxFields))
+	}
+	for i, f := range e.Fields {
+		if l := utf8.RuneCountInString(f.Name); l == 0 || l > MaxFieldNameLength {
+			problems = append(problems, fmt.Sprintf("name of field %d must be between 1 and %d characters", i, MaxFieldNameLength))
+		}
+		if l := utf8.RuneCountInString(f.Value); l == 0 || l > MaxFieldValueLength {
+			problems = append(problems, fmt.Sprintf("value of field %d must be between 1 and %d characters", i, MaxFieldValueLength))
+		}
+	}
+
+	if e.Footer != nil {
+		if l := utf8.RuneCountInString(e.Footer.Text); l == 0 || l > MaxFooterTextLength {
+			problems = append(problems, fmt.Sprintf("footer text must be between 1 and %d characters", MaxFooterTextLength))
+		}
+	}
+	if e.Author != nil {
+		if l := utf8.RuneCountInString(e.Author.Name); l == 0 || l > MaxAuthorNameLength {
+			problems = append(problems, fmt.Sprintf("author name must be between 1 and %d characters", MaxAuthorNameLength))
+		}
+	}
+
+	if e.Color < 0 || e.Color > MaxColor {
+		problems = append(problems, fmt.Sprintf("color must be between 0 and %#06x", MaxColor))
+	}
+
+	return problems
+}
@@ -0,0 +1,46 @@
+package harmony
+
+import (
+	"context"
+
+	"github.com/skwair/harmony/invite"
+)
+
+// EmbeddedActivity is an activity that can be launched in a voice channel,
+// such as Watch Together.
+type EmbeddedActivity struct {
+	ApplicationID string
+	Name          string
+}
+
+// EmbeddedActivities lists well known embedded activities. Discord does not
+// expose an endpoint to list activities available to bots, so this list is
+// maintained by Harmony and may be incomplete. Some activities also require
+// the guild to be boosted.
+var EmbeddedActivities = []EmbeddedActivity{
+	{ApplicationID: "880218394199220334", Name: "Watch Together"},
+	{ApplicationID: "755827207812677713", Name: "Poker Night"},
+	{ApplicationID: "773336526917861400", Name: "Betrayal.io"},
+	{ApplicationID: "814288819477020702", Name: "Fishington.io"},
+	{ApplicationID: "832012774040141894", Name: "Chess In The Park"},
+	{ApplicationID: "832013003968348200", Name: "Checkers In The Park"},
+	{ApplicationID: "832025144389533716", Name: "Blazing 8s"},
+	{ApplicationID: "852509694341283871", Name: "SpellCast"},
+	{ApplicationID: "879863686565621790", Name: "Letter League"},
+	{ApplicationID: "879863976006127627", Name: "Word Snacks"},
+	{ApplicationID: "902271654783242291", Name: "Sketch Heads"},
+	{ApplicationID: "903769130790969345", Name: "Land-io"},
+	{ApplicationID: "945737671223947305", Name: "Putt Party"},
+	{ApplicationID: "947957217959759964", Name: "Bobble League"},
+	{ApplicationID: "976052223358406656", Name: "Ask Away"},
+	{ApplicationID: "950505761862189096", Name: "Know What I Meme"},
+}
+
+// NewActivityInvite creates an invite that launches the embedded activity of
+// the given application in the voice channel. Members joining with this invite
+// join the activity directly. Requires the CREATE_INSTANT_INVITE permission.
+// See EmbeddedActivities for a list of well known activities.
+func (r *ChannelResource) NewActivityInvite(ctx context.Context, applicationID string, opts ...invite.Setting) (*Invite, error) {
+	opts = append(append([]invite.Setting{}, opts...), invite.WithTargetEmbeddedApplication(applicationID))
+	return r.NewInvite(ctx, invite.NewSettings(opts...))
+}
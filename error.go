@@ -7,6 +7,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+
+	"nhooyr.io/websocket"
 )
 
 var (
@@ -22,6 +24,38 @@ var (
 	// ErrNotConnectedToVoice is returned when trying to switch to a different voice
 	// channel in a guild where you are not yet connected to a voice channel.
 	ErrNotConnectedToVoice = errors.New("not connected to a voice channel in this guild, use the JoinVoiceChannel method first")
+	// ErrImageTooLarge is returned by ImageData and ImageDataFromFile when the
+	// image is larger than the allowed maximum size.
+	ErrImageTooLarge = errors.New("image is too large")
+	// ErrInvalidIntents is returned by Connect when the Gateway closes the connection
+	// because the client identified with an invalid value for intents (close code 4013).
+	ErrInvalidIntents = errors.New("invalid gateway intents")
+	// ErrDisallowedIntents is matched by errors returned when the Gateway closes the
+	// connection because the client identified with privileged intents that are not
+	// enabled for its application (close code 4014). See DisallowedIntentsError.
+	ErrDisallowedIntents = errors.New("disallowed gateway intents")
+	// ErrAuthenticationFailed is matched by errors returned when the Gateway closes
+	// the connection because the token of the client is invalid (close code 4004).
+	ErrAuthenticationFailed = errors.New("gateway authentication failed")
+	// ErrInvalidShard is matched by errors returned when the Gateway closes the
+	// connection because the client identified with an invalid shard (close code 4010).
+	ErrInvalidShard = errors.New("invalid shard")
+	// ErrShardingRequired is matched by errors returned when the Gateway closes the
+	// connection because the bot is in too many guilds to connect without sharding
+	// (close code 4011). See ShardManager.
+	ErrShardingRequired = errors.New("sharding required")
+	// ErrInvalidAPIVersion is matched by errors returned when the Gateway closes the
+	// connection because the client uses an invalid version of the Gateway (close code 4012).
+	ErrInvalidAPIVersion = errors.New("invalid gateway version")
+	// ErrReconnectAborted is matched by the error reported when the client stops trying to
+	// reconnect to the Gateway because of its reconnect policy. See WithReconnectPolicy.
+	ErrReconnectAborted = errors.New("reconnection to the gateway aborted")
+	// ErrCircuitOpen is returned by REST calls when the circuit breaker of their route
+	// is open because of repeated server errors, see WithCircuitBreaker.
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+	// ErrCloudflareBan is returned by REST calls when Cloudflare banned the IP address
+	// of the client, or while this ban lasts. See OnCloudflareBan.
+	ErrCloudflareBan = errors.New("banned by Cloudflare")
 
 	// errMustReconnect is an internal error used to signal that we need to reconnect to the Gateway.
 	errMustReconnect = errors.New("must reconnect to the Gateway")
@@ -33,6 +67,9 @@ type APIError struct {
 	Code     int      `json:"code"`
 	Message  string   `json:"message"`
 	Misc     []string `json:"_misc"`
+	// Errors details which fields of the request were invalid, if any.
+	// Its structure mirrors the one of the request.
+	Errors json.RawMessage `json:"errors,omitempty"`
 }
 
 // Error implements the error interface.
@@ -58,6 +95,10 @@ func (e APIError) Error() string {
 		s.WriteString(fmt.Sprintf(" %s", m))
 		i++
 	}
+
+	if len(e.Errors) > 0 {
+		s.WriteString(fmt.Sprintf(" %s", e.Errors))
+	}
 	return s.String()
 }
 
@@ -112,3 +153,111 @@ func apiError(resp *http.Response) error {
 	}
 	return validationErr
 }
+
+// GatewayCloseError is returned or reported when the Gateway closes the connection
+// with one of its close codes. Errors for fatal close codes, which the client does
+// not try to recover from, match one of ErrAuthenticationFailed, ErrInvalidShard,
+// ErrShardingRequired, ErrInvalidAPIVersion, ErrInvalidIntents or ErrDisallowedIntents
+// with errors.Is. See https://discord.com/developers/docs/topics/opcodes-and-status-codes#gateway-gateway-close-event-codes.
+type GatewayCloseError struct {
+	Code   int
+	Reason string
+	// Err is the underlying websocket error.
+	Err error
+}
+
+// closeCodeErrors are the errors matched by GatewayCloseErrors, by close code.
+var closeCodeErrors = map[int]error{
+	4004:                       ErrAuthenticationFailed,
+	4010:                       ErrInvalidShard,
+	4011:                       ErrShardingRequired,
+	4012:                       ErrInvalidAPIVersion,
+	closeCodeInvalidIntents:    ErrInvalidIntents,
+	closeCodeDisallowedIntents: ErrDisallowedIntents,
+}
+
+// Error implements the error interface.
+func (e *GatewayCloseError) Error() string {
+	msg := fmt.Sprintf("gateway closed the connection with code %d", e.Code)
+	if err, ok := closeCodeErrors[e.Code]; ok {
+		msg += fmt.Sprintf(" (%v)", err)
+	}
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+// Is reports whether the close code of this error matches the given error,
+// such as ErrShardingRequired.
+func (e *GatewayCloseError) Is(target error) bool {
+	err, ok := closeCodeErrors[e.Code]
+	return ok && err == target
+}
+
+// Unwrap returns the underlying websocket error.
+func (e *GatewayCloseError) Unwrap() error {
+	return e.Err
+}
+
+// Fatal reports whether the client does not try to reconnect
+// after the connection was closed with this error.
+func (e *GatewayCloseError) Fatal() bool {
+	return !shouldReconnect(e)
+}
+
+// gatewayCloseError returns err as a GatewayCloseError
+// if it is a websocket close error, else err itself.
+func gatewayCloseError(err error) error {
+	var closeErr websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		return err
+	}
+	return &GatewayCloseError{
+		Code:   int(closeErr.Code),
+		Reason: closeErr.Reason,
+		Err:    err,
+	}
+}
+
+// DisallowedIntentsError is returned by Connect when the Gateway closes the
+// connection because the client identified with privileged intents that are
+// not enabled for its application (close code 4014).
+type DisallowedIntentsError struct {
+	// ApplicationID is the ID of the application of the bot,
+	// empty if it could not be fetched.
+	ApplicationID string
+	// Missing are the privileged intents requested by the client
+	// that are not enabled for the application. If application
+	// flags could not be fetched, this is set to all privileged
+	// intents requested by the client.
+	Missing GatewayIntent
+	// Err is the underlying error returned by the Gateway.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *DisallowedIntentsError) Error() string {
+	var names []string
+	for _, p := range privilegedIntents {
+		if e.Missing&p.intent != 0 {
+			names = append(names, p.name)
+		}
+	}
+
+	where := "in the Bot tab of your application on https://discord.com/developers/applications"
+	if e.ApplicationID != "" {
+		where = fmt.Sprintf("on https://discord.com/developers/applications/%s/bot", e.ApplicationID)
+	}
+
+	return fmt.Sprintf(
+		"disallowed gateway intents: privileged intent(s) %s not enabled for this application, "+
+			"enable them %s or remove them with WithGatewayIntents: %v",
+		strings.Join(names, ", "), where, e.Err,
+	)
+}
+
+// Unwrap returns the underlying error returned by the Gateway.
+func (e *DisallowedIntentsError) Unwrap() error {
+	return e.Err
+}
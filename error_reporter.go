@@ -0,0 +1,108 @@
+package harmony
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrorSource is the part of the client an error reported
+// to an ErrorReporter originates from.
+type ErrorSource string
+
+// Sources of errors reported to an ErrorReporter.
+const (
+	// ErrorSourceHandler means an event handler panicked.
+	ErrorSourceHandler ErrorSource = "handler"
+	// ErrorSourceGateway means the connection to the Gateway failed.
+	ErrorSourceGateway ErrorSource = "gateway"
+	// ErrorSourceREST means a request to the REST API failed, either
+	// because it could not be sent or because Discord returned a 5xx.
+	ErrorSourceREST ErrorSource = "rest"
+	// ErrorSourceState means the StateBackend failed to read or
+	// write an object, see FallibleStateBackend.
+	ErrorSourceState ErrorSource = "state"
+)
+
+// ErrorEvent holds metadata about an error reported to an ErrorReporter.
+type ErrorEvent struct {
+	Source ErrorSource
+	// Shard and ShardCount are set to the sharding configuration
+	// of the client. Both are 0 if sharding is not enabled.
+	Shard      int
+	ShardCount int
+
+	// Event is the name of the Gateway event (e.g. "MESSAGE_CREATE")
+	// being handled when a handler panicked.
+	Event string
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+
+	// Method and Path of the REST request that failed, and the
+	// status code of the response if one was received. Webhook
+	// and interaction tokens are redacted from the path.
+	Method     string
+	Path       string
+	StatusCode int
+}
+
+// ErrorReporter is the interface to implement in order to send errors
+// encountered by a Client to an error tracking service such as Sentry.
+// See the errorreport/sentry package for an implementation reporting to Sentry.
+type ErrorReporter interface {
+	// CaptureException is called each time the client encounters
+	// an error. It must be safe for concurrent use.
+	CaptureException(err error, event *ErrorEvent)
+}
+
+// WithErrorReporter sets the ErrorReporter errors encountered by the client
+// are sent to. When set, panics in event handlers are recovered and reported
+// instead of crashing the program.
+// Defaults to nil, errors are only logged.
+func WithErrorReporter(r ErrorReporter) ClientOption {
+	return func(c *Client) {
+		c.errorReporter = r
+	}
+}
+
+// reportError sends the given error to the error reporter of the client, if any.
+func (c *Client) reportError(err error, event *ErrorEvent) {
+	if c.errorReporter == nil {
+		return
+	}
+
+	event.Shard, event.ShardCount = c.shard[0], c.shard[1]
+	c.errorReporter.CaptureException(err, event)
+}
+
+// callHandler calls the given handler for the given event, through the given
+// middlewares if any. The context of the event is derived from ctx. If the
+// client has an error reporter, panics are recovered and reported.
+func (c *Client) callHandler(ctx context.Context, event string, h handler, middlewares []Middleware, d interface{}) {
+	if c.errorReporter != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				err, ok := r.(error)
+				if !ok {
+					err = fmt.Errorf("%v", r)
+				}
+				c.logger.Errorf("recovered from panic in %s handler: %v", event, err)
+				c.reportError(fmt.Errorf("panic in %s handler: %w", event, err), &ErrorEvent{
+					Source: ErrorSourceHandler,
+					Event:  event,
+					Stack:  debug.Stack(),
+				})
+			}
+		}()
+	}
+
+	// Message component and modal submit interactions have
+	// their own handlers but are received as regular interactions.
+	typ := event
+	if typ == eventMessageComponent || typ == eventModalSubmit {
+		typ = eventInteractionCreate
+	}
+	e := &Event{Type: typ, Shard: c.shard[0], Data: d}
+	e.ctx = context.WithValue(ctx, eventContextKey{}, e)
+	chain(middlewares, h)(e)
+}
@@ -0,0 +1,241 @@
+/*
+Package sentry provides a harmony.ErrorReporter sending errors to Sentry
+(https://sentry.io). It talks directly to Sentry's HTTP API so it does not
+require any additional dependency:
+
+	reporter, err := sentry.New("https://public@o0.ingest.sentry.io/0")
+	if err != nil {
+		// Handle error
+	}
+
+	client, err := harmony.NewClient(token, harmony.WithErrorReporter(reporter))
+
+Errors are sent in the background and are dropped if they can not be sent.
+*/
+package sentry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skwair/harmony"
+)
+
+// Reporter is a harmony.ErrorReporter sending errors to Sentry.
+type Reporter struct {
+	storeURL  string
+	publicKey string
+
+	client      *http.Client
+	environment string
+	release     string
+	serverName  string
+	tags        map[string]string
+
+	onError func(error)
+}
+
+var _ harmony.ErrorReporter = (*Reporter)(nil)
+
+// Option is a function that configures a Reporter.
+// It is used in New.
+type Option func(*Reporter)
+
+// WithHTTPClient sets the http.Client used to send errors to Sentry.
+// Defaults to an http.Client with a 10 seconds timeout.
+func WithHTTPClient(client *http.Client) Option {
+	return func(r *Reporter) {
+		r.client = client
+	}
+}
+
+// WithEnvironment sets the environment (e.g. "production") errors are reported in.
+func WithEnvironment(env string) Option {
+	return func(r *Reporter) {
+		r.environment = env
+	}
+}
+
+// WithRelease sets the release (e.g. a version or a commit hash) errors are reported for.
+func WithRelease(release string) Option {
+	return func(r *Reporter) {
+		r.release = release
+	}
+}
+
+// WithServerName sets the name of the server errors are reported from.
+func WithServerName(name string) Option {
+	return func(r *Reporter) {
+		r.serverName = name
+	}
+}
+
+// WithTags sets static tags added to every reported error.
+func WithTags(tags map[string]string) Option {
+	return func(r *Reporter) {
+		r.tags = tags
+	}
+}
+
+// WithErrorHandler sets a function called when an error can not be sent to Sentry.
+// Defaults to nil, such errors are silently ignored.
+func WithErrorHandler(f func(error)) Option {
+	return func(r *Reporter) {
+		r.onError = f
+	}
+}
+
+// New returns a new Reporter sending errors to the project identified by the
+// given DSN, as found in the "Client Keys" settings of the Sentry project.
+func New(dsn string, opts ...Option) (*Reporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sentry: invalid DSN: %w", err)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, errors.New("sentry: invalid DSN: missing public key")
+	}
+
+	i := strings.LastIndex(u.Path, "/")
+	projectID := u.Path[i+1:]
+	if projectID == "" {
+		return nil, errors.New("sentry: invalid DSN: missing project ID")
+	}
+
+	r := &Reporter{
+		storeURL:  fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, u.Path[:i], projectID),
+		publicKey: u.User.Username(),
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r, nil
+}
+
+// CaptureException implements the harmony.ErrorReporter interface.
+func (r *Reporter) CaptureException(err error, event *harmony.ErrorEvent) {
+	e := r.newEvent(err, event)
+
+	go func() {
+		if err := r.send(e); err != nil && r.onError != nil {
+			r.onError(err)
+		}
+	}()
+}
+
+// sentryEvent is an event as expected by Sentry's store endpoint.
+// See https://develop.sentry.dev/sdk/event-payloads/ for more information.
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+	Exception   struct {
+		Values []sentryException `json:"values"`
+	} `json:"exception"`
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func (r *Reporter) newEvent(err error, event *harmony.ErrorEvent) *sentryEvent {
+	e := &sentryEvent{
+		EventID:     newEventID(),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Level:       "error",
+		Platform:    "go",
+		Logger:      "harmony",
+		Environment: r.environment,
+		Release:     r.release,
+		ServerName:  r.serverName,
+		Tags:        make(map[string]string),
+		Extra:       make(map[string]string),
+	}
+
+	for k, v := range r.tags {
+		e.Tags[k] = v
+	}
+	e.Tags["source"] = string(event.Source)
+	if event.ShardCount > 0 {
+		e.Tags["shard"] = strconv.Itoa(event.Shard)
+	}
+	if event.Event != "" {
+		e.Tags["event"] = event.Event
+	}
+	if event.Method != "" {
+		e.Tags["http.method"] = event.Method
+		e.Extra["path"] = event.Path
+	}
+	if event.StatusCode != 0 {
+		e.Tags["http.status_code"] = strconv.Itoa(event.StatusCode)
+	}
+	if len(event.Stack) > 0 {
+		e.Extra["stack"] = string(event.Stack)
+	}
+
+	// Report the whole chain of wrapped errors, innermost first as expected by Sentry.
+	for ; err != nil; err = errors.Unwrap(err) {
+		e.Exception.Values = append([]sentryException{{
+			Type:  reflect.TypeOf(err).String(),
+			Value: err.Error(),
+		}}, e.Exception.Values...)
+	}
+
+	return e
+}
+
+func (r *Reporter) send(e *sentryEvent) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.storeURL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf(
+		"Sentry sentry_version=7, sentry_client=harmony-sentry/1.0, sentry_timestamp=%d, sentry_key=%s",
+		time.Now().Unix(), r.publicKey,
+	))
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("sentry: could not send event: %s", resp.Status)
+	}
+	return nil
+}
+
+// newEventID returns a random 32 characters hexadecimal event ID.
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,270 @@
+package harmony
+
+import (
+	"context"
+	"sync"
+)
+
+// Event is an event received from the Gateway,
+// as sent on channels returned by Client.Events.
+type Event struct {
+	// Type of the event, e.g. "MESSAGE_CREATE".
+	Type string
+	// Shard the event was received on. It is always 0
+	// if sharding is not enabled, see WithSharding.
+	Shard int
+	// Data of the event. Its type is the same as the one passed to the
+	// handler of this event, for instance *Message for "MESSAGE_CREATE".
+	Data interface{}
+
+	ctx context.Context
+}
+
+// Context returns the context of the event. For events passed to handlers, it
+// is canceled when the client disconnects and carries the event, see
+// EventFromContext. For events sent on channels returned by Events or passed
+// to functions given to Subscribe, it is context.Background.
+func (e *Event) Context() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
+}
+
+// WithContext returns a shallow copy of the event with its context set to ctx,
+// for instance to let middlewares add a timeout or values to the context passed
+// to handlers registered with OnXxxCtx methods.
+func (e *Event) WithContext(ctx context.Context) *Event {
+	if ctx == nil {
+		panic("harmony: nil context")
+	}
+	e2 := *e
+	e2.ctx = ctx
+	return &e2
+}
+
+// Events returns a channel through which all events received by the client
+// are sent, in addition to being passed to registered handlers. This allows
+// to consume events with select statements or to bridge them to other
+// systems. Since a client is connected to a single shard, events sent on
+// this channel all come from the same shard, see ShardManager.Events to
+// receive events from all shards.
+//
+// The channel has the given buffer size. Events are dropped when the buffer
+// is full, so the client never blocks on a slow consumer. Dropped events are
+// logged as warnings and counted in Stats, see Subscribe to receive events
+// without dropping them. Each call returns a new channel receiving all events.
+//
+// The channel is closed when the client disconnects or when the returned
+// function is called, whichever comes first. The function can be called
+// more than once.
+func (c *Client) Events(size int) (<-chan *Event, func()) {
+	ch := make(chan *Event, size)
+	c.addEventStream(ch, true)
+	return ch, func() { c.removeEventStream(ch) }
+}
+
+// eventStream is a channel events received by the client are sent to.
+type eventStream struct {
+	ch chan *Event
+	// Whether the channel is closed by the client. Channels returned
+	// by ShardManager.Events are shared by all shards, the manager
+	// closes them instead.
+	owned bool
+}
+
+// addEventStream makes the client send all events it receives to ch.
+func (c *Client) addEventStream(ch chan *Event, owned bool) {
+	c.handlersMu.Lock()
+	c.eventStreams = append(c.eventStreams, eventStream{ch: ch, owned: owned})
+	c.handlersMu.Unlock()
+}
+
+// removeEventStream stops sending events to ch, closing it if it is owned
+// by the client. It is a no-op if ch was already removed.
+func (c *Client) removeEventStream(ch chan *Event) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+
+	for i, s := range c.eventStreams {
+		if s.ch != ch {
+			continue
+		}
+		c.eventStreams = append(c.eventStreams[:i:i], c.eventStreams[i+1:]...)
+		if s.owned {
+			close(s.ch)
+		}
+		return
+	}
+}
+
+// closeEventStreams closes and removes all channels owned
+// by the client and ends all subscriptions.
+func (c *Client) closeEventStreams() {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+
+	for _, s := range c.subscriptions {
+		s.end()
+	}
+	c.subscriptions = nil
+
+	var shared []eventStream
+	for _, s := range c.eventStreams {
+		if s.owned {
+			close(s.ch)
+		} else {
+			shared = append(shared, s)
+		}
+	}
+	c.eventStreams = shared
+}
+
+// publish sends the given event to all channels returned
+// by Events and queues it for all subscriptions.
+func (c *Client) publish(event string, d interface{}) {
+	c.handlersMu.RLock()
+	defer c.handlersMu.RUnlock()
+
+	if len(c.eventStreams) == 0 && len(c.subscriptions) == 0 {
+		return
+	}
+
+	e := &Event{Type: event, Shard: c.shard[0], Data: d}
+	for _, s := range c.subscriptions {
+		s.push(e)
+	}
+	for _, s := range c.eventStreams {
+		select {
+		case s.ch <- e:
+		default:
+			c.recordDroppedEvent(event)
+			c.logger.Warnf("event channel is full, dropped %s event", event)
+		}
+	}
+}
+
+// Subscription is a subscription to the events received
+// by a client. Create one with Client.Subscribe.
+type Subscription struct {
+	client *Client
+	f      func(*Event)
+
+	mu    sync.Mutex
+	queue []*Event
+	// Signaled when events are queued.
+	ready chan struct{}
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// Subscribe calls f with all events received by the client, in addition to
+// passing them to registered handlers. Events are passed to f one at a time,
+// in the order they are received, from a goroutine dedicated to the
+// subscription. Unlike handlers, subscriptions do not replace each other,
+// which makes them the way for plugins to receive events.
+//
+// Unlike Events, events are never dropped: they are queued while f processes
+// previous ones, so the client never blocks on a slow subscriber either. f
+// must keep up with the rate events are received at, else the queue grows.
+//
+// The subscription ends when the client disconnects or when it is closed,
+// whichever comes first. Events still queued at that time are discarded.
+func (c *Client) Subscribe(f func(e *Event)) *Subscription {
+	if f == nil {
+		panic("harmony: trying to subscribe with a nil function")
+	}
+
+	s := &Subscription{
+		client: c,
+		f:      f,
+		ready:  make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+
+	c.handlersMu.Lock()
+	c.subscriptions = append(c.subscriptions, s)
+	c.handlersMu.Unlock()
+
+	return s
+}
+
+// Close ends the subscription and waits for its function to return if it is
+// processing an event, or for ctx to be done. It must not be called from the
+// function of the subscription. It can be called more than once.
+func (s *Subscription) Close(ctx context.Context) error {
+	s.client.handlersMu.Lock()
+	for i, sub := range s.client.subscriptions {
+		if sub == s {
+			s.client.subscriptions = append(s.client.subscriptions[:i:i], s.client.subscriptions[i+1:]...)
+			break
+		}
+	}
+	s.client.handlersMu.Unlock()
+
+	s.end()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// end stops the goroutine of the subscription without waiting for it.
+func (s *Subscription) end() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// push queues the given event.
+func (s *Subscription) push(e *Event) {
+	s.mu.Lock()
+	s.queue = append(s.queue, e)
+	s.mu.Unlock()
+
+	select {
+	case s.ready <- struct{}{}:
+	default: // Already signaled.
+	}
+}
+
+// next removes the oldest event from the queue and returns it,
+// or returns nil if the queue is empty.
+func (s *Subscription) next() *Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) == 0 {
+		return nil
+	}
+	e := s.queue[0]
+	s.queue[0] = nil
+	s.queue = s.queue[1:]
+	return e
+}
+
+func (s *Subscription) run() {
+	defer close(s.done)
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-s.ready:
+		}
+
+		for e := s.next(); e != nil; e = s.next() {
+			select {
+			case <-s.stop:
+				return
+			default:
+			}
+			s.f(e)
+		}
+	}
+}
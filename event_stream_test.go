@@ -0,0 +1,133 @@
+package harmony_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/harmonytest"
+)
+
+func TestEvents(t *testing.T) {
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	events, _ := c.Events(1)
+	unsubscribed, unsubscribe := c.Events(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = c.Connect(ctx); err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer c.Disconnect()
+
+	unsubscribe()
+	unsubscribe()
+	for range unsubscribed {
+		// Drain events received while connecting.
+	}
+
+	if e := <-events; e.Type != "READY" {
+		t.Fatalf("expected a READY event, got %s", e.Type)
+	}
+
+	// The second event does not fit in the buffer of the channel.
+	typing := &harmony.TypingStart{ChannelID: "1", UserID: "2"}
+	for i := 0; i < 2; i++ {
+		if err = srv.Dispatch("TYPING_START", typing); err != nil {
+			t.Fatalf("could not dispatch event: %v", err)
+		}
+	}
+
+	for c.Stats().DroppedEvents["TYPING_START"] != 1 {
+		select {
+		case <-time.After(time.Millisecond):
+		case <-ctx.Done():
+			t.Fatalf("expected one event to be dropped, got %v", c.Stats().DroppedEvents)
+		}
+	}
+
+	e := <-events
+	if e.Type != "TYPING_START" {
+		t.Errorf("expected a TYPING_START event, got %s", e.Type)
+	}
+
+	c.Disconnect()
+	if _, ok := <-events; ok {
+		t.Fatal("expected the channel to be closed once disconnected")
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	// The first event blocks the subscription until
+	// all events are dispatched, so they are queued.
+	const count = 100
+	unblock := make(chan struct{})
+	typing := make(chan *harmony.TypingStart, count)
+	sub := c.Subscribe(func(e *harmony.Event) {
+		<-unblock
+		if d, ok := e.Data.(*harmony.TypingStart); ok {
+			typing <- d
+		}
+	})
+	closed := c.Subscribe(func(e *harmony.Event) {
+		t.Errorf("unexpected %s event on a closed subscription", e.Type)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = closed.Close(ctx); err != nil {
+		t.Fatalf("could not close subscription: %v", err)
+	}
+	if err = closed.Close(ctx); err != nil {
+		t.Fatalf("could not close subscription twice: %v", err)
+	}
+
+	if err = c.Connect(ctx); err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer c.Disconnect()
+
+	for i := 0; i < count; i++ {
+		if err = srv.Dispatch("TYPING_START", &harmony.TypingStart{ChannelID: "1", Timestamp: int64(i)}); err != nil {
+			t.Fatalf("could not dispatch event: %v", err)
+		}
+	}
+	close(unblock)
+
+	for i := 0; i < count; i++ {
+		select {
+		case d := <-typing:
+			if d.Timestamp != int64(i) {
+				t.Fatalf("expected event %d to be received, got %d", i, d.Timestamp)
+			}
+		case <-ctx.Done():
+			t.Fatalf("expected %d events to be received, got %d", count, i)
+		}
+	}
+	if dropped := c.Stats().DroppedEvents; len(dropped) != 0 {
+		t.Errorf("expected no event to be dropped, got %v", dropped)
+	}
+
+	c.Disconnect()
+	if err = sub.Close(ctx); err != nil {
+		t.Errorf("could not close subscription ended by Disconnect: %v", err)
+	}
+}
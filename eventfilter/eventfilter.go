@@ -0,0 +1,253 @@
+/*
+Package eventfilter drops Gateway events a bot is not interested in before they
+are fully decoded, which saves a lot of CPU and memory for high-volume bots such
+as analytics ingestion workloads. Filters are described by a Config, which can be
+loaded from a JSON file:
+
+	{
+		"events": {
+			"MESSAGE_CREATE": {
+				"guilds": ["613425648685547541"],
+				"content": {"prefix": ["!"], "ignore_bots": true}
+			},
+			"TYPING_START": {"drop": true},
+			"*": {"guilds": ["613425648685547541"]}
+		}
+	}
+
+Once compiled with New, a filter is given to a client with harmony.WithEventFilter.
+The number of events it drops is reported by harmony.Client.Stats.
+*/
+package eventfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AnyEvent is the event type of the rule applied to events whose type has no rule.
+const AnyEvent = "*"
+
+// Config describes which events are kept.
+type Config struct {
+	// Rules by event type, such as "MESSAGE_CREATE", or AnyEvent. Events
+	// of a type without rule, and without AnyEvent rule, are all kept.
+	Events map[string]Rule `json:"events"`
+}
+
+// Rule describes which events of a type are kept. Events are kept if they match
+// all the conditions of the rule.
+type Rule struct {
+	// Drop drops all events of the type.
+	Drop bool `json:"drop,omitempty"`
+	// Guilds keeps only events from these guilds, if set. Events
+	// that are not sent from a guild, such as DMs, are dropped.
+	Guilds []string `json:"guilds,omitempty"`
+	// Channels keeps only events from these channels, if set. Messages sent in
+	// threads are from the thread, not its parent channel. Events that are not
+	// sent from a channel are dropped.
+	Channels []string `json:"channels,omitempty"`
+	// Content filters events with a content, such as messages. Events
+	// without content, such as most message updates, are not affected.
+	Content *ContentRule `json:"content,omitempty"`
+}
+
+// ContentRule describes which events with a content are kept.
+type ContentRule struct {
+	// Prefix keeps events whose content starts with one of these prefixes, if set.
+	Prefix []string `json:"prefix,omitempty"`
+	// Contains keeps events whose content contains one of these strings, if set.
+	Contains []string `json:"contains,omitempty"`
+	// Regexp keeps events whose content matches this regular expression, if set.
+	Regexp string `json:"regexp,omitempty"`
+	// IgnoreBots drops events whose author is a bot.
+	IgnoreBots bool `json:"ignore_bots,omitempty"`
+}
+
+// Filter decides which Gateway events are kept. It is safe for concurrent use.
+// Create one with New.
+type Filter struct {
+	rules map[string]*rule
+	any   *rule
+}
+
+type rule struct {
+	drop     bool
+	guilds   map[string]struct{}
+	channels map[string]struct{}
+	content  *contentRule
+}
+
+type contentRule struct {
+	prefix     []string
+	contains   []string
+	re         *regexp.Regexp
+	ignoreBots bool
+}
+
+// New compiles the given configuration into a Filter. It returns an error if a
+// regular expression is invalid.
+func New(cfg *Config) (*Filter, error) {
+	f := &Filter{rules: make(map[string]*rule, len(cfg.Events))}
+
+	for typ, r := range cfg.Events {
+		compiled, err := compile(&r)
+		if err != nil {
+			return nil, fmt.Errorf("eventfilter: invalid rule for %s events: %w", typ, err)
+		}
+		if typ == AnyEvent {
+			f.any = compiled
+		} else {
+			f.rules[strings.ToUpper(typ)] = compiled
+		}
+	}
+
+	return f, nil
+}
+
+func compile(r *Rule) (*rule, error) {
+	compiled := &rule{
+		drop:     r.Drop,
+		guilds:   set(r.Guilds),
+		channels: set(r.Channels),
+	}
+
+	if c := r.Content; c != nil {
+		compiled.content = &contentRule{
+			prefix:     c.Prefix,
+			contains:   c.Contains,
+			ignoreBots: c.IgnoreBots,
+		}
+		if c.Regexp != "" {
+			re, err := regexp.Compile(c.Regexp)
+			if err != nil {
+				return nil, err
+			}
+			compiled.content.re = re
+		}
+	}
+
+	return compiled, nil
+}
+
+func set(ids []string) map[string]struct{} {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	s := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		s[id] = struct{}{}
+	}
+	return s
+}
+
+// fields holds the fields of events filters look at. Decoding
+// them is much cheaper than decoding the whole event.
+type fields struct {
+	ID        string  `json:"id"`
+	GuildID   string  `json:"guild_id"`
+	ChannelID string  `json:"channel_id"`
+	Content   *string `json:"content"`
+	Author    *struct {
+		Bot bool `json:"bot"`
+	} `json:"author"`
+}
+
+// Keep reports whether the event with the given type and raw data is kept.
+// Events that can not be decoded are kept, so they are reported when
+// fully decoded.
+func (f *Filter) Keep(typ string, data json.RawMessage) bool {
+	r, ok := f.rules[typ]
+	if !ok {
+		r = f.any
+	}
+	if r == nil {
+		return true
+	}
+	if r.drop {
+		return false
+	}
+	if r.guilds == nil && r.channels == nil && r.content == nil {
+		return true
+	}
+
+	var fs fields
+	if err := json.Unmarshal(data, &fs); err != nil {
+		return true
+	}
+
+	if r.guilds != nil {
+		guildID := fs.GuildID
+		if isGuildEvent(typ) {
+			guildID = fs.ID
+		}
+		if _, ok := r.guilds[guildID]; !ok {
+			return false
+		}
+	}
+
+	if r.channels != nil {
+		channelID := fs.ChannelID
+		if isChannelEvent(typ) {
+			channelID = fs.ID
+		}
+		if _, ok := r.channels[channelID]; !ok {
+			return false
+		}
+	}
+
+	if r.content != nil && fs.Content != nil {
+		if r.content.ignoreBots && fs.Author != nil && fs.Author.Bot {
+			return false
+		}
+		return r.content.match(*fs.Content)
+	}
+
+	return true
+}
+
+func (r *contentRule) match(content string) bool {
+	if len(r.prefix) > 0 && !anyOf(r.prefix, content, strings.HasPrefix) {
+		return false
+	}
+	if len(r.contains) > 0 && !anyOf(r.contains, content, strings.Contains) {
+		return false
+	}
+	if r.re != nil && !r.re.MatchString(content) {
+		return false
+	}
+	return true
+}
+
+func anyOf(patterns []string, content string, match func(s, pattern string) bool) bool {
+	for _, p := range patterns {
+		if match(content, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isGuildEvent reports whether events of the given type are guilds,
+// whose ID is the guild ID.
+func isGuildEvent(typ string) bool {
+	switch typ {
+	case "GUILD_CREATE", "GUILD_UPDATE", "GUILD_DELETE":
+		return true
+	}
+	return false
+}
+
+// isChannelEvent reports whether events of the given type are channels,
+// whose ID is the channel ID.
+func isChannelEvent(typ string) bool {
+	switch typ {
+	case "CHANNEL_CREATE", "CHANNEL_UPDATE", "CHANNEL_DELETE",
+		"THREAD_CREATE", "THREAD_UPDATE", "THREAD_DELETE":
+		return true
+	}
+	return false
+}
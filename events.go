@@ -1,6 +1,7 @@
 package harmony
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/skwair/harmony/voice"
@@ -95,6 +96,117 @@ func (c *Client) OnChannelPinsUpdate(f func(cpu *ChannelPinsUpdate)) {
 	c.registerHandler(eventChannelPinsUpdate, channelPinsUpdateHandler(f))
 }
 
+type threadCreateHandler func(*Channel)
+
+// handle implements the handler interface.
+func (h threadCreateHandler) handle(v interface{}) {
+	h(v.(*Channel))
+}
+
+// OnThreadCreate registers the handler function for the "THREAD_CREATE" event.
+// This event is fired when a thread is created, or when the current user is added
+// to a private thread.
+func (c *Client) OnThreadCreate(f func(th *Channel)) {
+	c.registerHandler(eventThreadCreate, threadCreateHandler(f))
+}
+
+type threadUpdateHandler func(*Channel)
+
+// handle implements the handler interface.
+func (h threadUpdateHandler) handle(v interface{}) {
+	h(v.(*Channel))
+}
+
+// OnThreadUpdate registers the handler function for the "THREAD_UPDATE" event.
+// This event is fired when a thread is updated, but not when its last message ID changes.
+func (c *Client) OnThreadUpdate(f func(th *Channel)) {
+	c.registerHandler(eventThreadUpdate, threadUpdateHandler(f))
+}
+
+type threadDeleteHandler func(*Channel)
+
+// handle implements the handler interface.
+func (h threadDeleteHandler) handle(v interface{}) {
+	h(v.(*Channel))
+}
+
+// OnThreadDelete registers the handler function for the "THREAD_DELETE" event.
+// This event is fired when a thread relevant to the current user is deleted. Only
+// the ID, GuildID, ParentID and Type fields of the thread are set.
+func (c *Client) OnThreadDelete(f func(th *Channel)) {
+	c.registerHandler(eventThreadDelete, threadDeleteHandler(f))
+}
+
+// ThreadListSync is Fired when the current user gains access to a channel.
+type ThreadListSync struct {
+	GuildID string `json:"guild_id"`
+	// IDs of the channels whose threads are synced. If empty,
+	// the threads of the whole guild are synced.
+	ChannelIDs []string `json:"channel_ids"`
+	// Active threads of the synced channels.
+	Threads []Channel `json:"threads"`
+	// Thread members of the current user for the synced threads.
+	Members []ThreadMember `json:"members"`
+}
+
+type threadListSyncHandler func(*ThreadListSync)
+
+// handle implements the handler interface.
+func (h threadListSyncHandler) handle(v interface{}) {
+	h(v.(*ThreadListSync))
+}
+
+// OnThreadListSync registers the handler function for the "THREAD_LIST_SYNC" event.
+// This event is fired when the current user gains access to a channel.
+func (c *Client) OnThreadListSync(f func(tls *ThreadListSync)) {
+	c.registerHandler(eventThreadListSync, threadListSyncHandler(f))
+}
+
+// ThreadMemberUpdate is Fired when the thread member of the current user is updated.
+type ThreadMemberUpdate struct {
+	ThreadMember
+	GuildID string `json:"guild_id"`
+}
+
+type threadMemberUpdateHandler func(*ThreadMemberUpdate)
+
+// handle implements the handler interface.
+func (h threadMemberUpdateHandler) handle(v interface{}) {
+	h(v.(*ThreadMemberUpdate))
+}
+
+// OnThreadMemberUpdate registers the handler function for the "THREAD_MEMBER_UPDATE" event.
+// This event is fired when the thread member of the current user is updated.
+func (c *Client) OnThreadMemberUpdate(f func(tmu *ThreadMemberUpdate)) {
+	c.registerHandler(eventThreadMemberUpdate, threadMemberUpdateHandler(f))
+}
+
+// ThreadMembersUpdate is Fired when users are added to or removed from a thread.
+type ThreadMembersUpdate struct {
+	// ID of the thread.
+	ID          string `json:"id"`
+	GuildID     string `json:"guild_id"`
+	MemberCount int    `json:"member_count"` // Stops counting at 50.
+	// Members added to the thread, their Member field is set
+	// if the GUILD_MEMBERS privileged intent is enabled.
+	AddedMembers     []ThreadMember `json:"added_members"`
+	RemovedMemberIDs []string       `json:"removed_member_ids"`
+}
+
+type threadMembersUpdateHandler func(*ThreadMembersUpdate)
+
+// handle implements the handler interface.
+func (h threadMembersUpdateHandler) handle(v interface{}) {
+	h(v.(*ThreadMembersUpdate))
+}
+
+// OnThreadMembersUpdate registers the handler function for the "THREAD_MEMBERS_UPDATE" event.
+// This event is fired when users are added to or removed from a thread. Without the
+// GUILD_MEMBERS privileged intent, it is only fired for the current user.
+func (c *Client) OnThreadMembersUpdate(f func(tmu *ThreadMembersUpdate)) {
+	c.registerHandler(eventThreadMembersUpdate, threadMembersUpdateHandler(f))
+}
+
 type guildCreateHandler func(*Guild)
 
 // handle implements the handler interface.
@@ -186,6 +298,24 @@ func (c *Client) OnGuildEmojisUpdate(f func(emojis *GuildEmojis)) {
 	c.registerHandler(eventGuildEmojisUpdate, guildEmojisUpdateHandler(f))
 }
 
+type GuildStickers struct {
+	Stickers []Sticker `json:"stickers"`
+	GuildID  string    `json:"guild_id"`
+}
+
+type guildStickersUpdateHandler func(*GuildStickers)
+
+// handle implements the handler interface.
+func (h guildStickersUpdateHandler) handle(v interface{}) {
+	h(v.(*GuildStickers))
+}
+
+// OnGuildStickersUpdate registers the handler function for the "GUILD_STICKERS_UPDATE" event.
+// Fired when a guild's stickers have been updated.
+func (c *Client) OnGuildStickersUpdate(f func(stickers *GuildStickers)) {
+	c.registerHandler(eventGuildStickersUpdate, guildStickersUpdateHandler(f))
+}
+
 type GuildIntegrationUpdate struct {
 	GuildID string `json:"guild_id"`
 }
@@ -262,6 +392,15 @@ func (c *Client) OnGuildMemberUpdate(f func(m *GuildMemberUpdate)) {
 type GuildMembersChunk struct {
 	GuildID string        `json:"guild_id"`
 	Members []GuildMember `json:"members"`
+	// Index of this chunk, from 0 to ChunkCount-1.
+	ChunkIndex int `json:"chunk_index"`
+	ChunkCount int `json:"chunk_count"`
+	// IDs of requested users that were not found.
+	NotFound []string `json:"not_found"`
+	// Presences of the members, if they were requested.
+	Presences []Presence `json:"presences"`
+	// Nonce of the request this chunk responds to.
+	Nonce string `json:"nonce"`
 }
 
 type guildMembersChunkHandler func(*GuildMembersChunk)
@@ -326,6 +465,33 @@ func (c *Client) OnGuildRoleDelete(f func(r *GuildRoleDelete)) {
 	c.registerHandler(eventGuildRoleDelete, guildRoleDeleteHandler(f))
 }
 
+// GuildScheduledEventUser is Fired when a user subscribes to
+// or unsubscribes from a guild scheduled event.
+type GuildScheduledEventUser struct {
+	GuildScheduledEventID string `json:"guild_scheduled_event_id"`
+	UserID                string `json:"user_id"`
+	GuildID               string `json:"guild_id"`
+}
+
+type guildScheduledEventUserHandler func(*GuildScheduledEventUser)
+
+// handle implements the handler interface.
+func (h guildScheduledEventUserHandler) handle(v interface{}) {
+	h(v.(*GuildScheduledEventUser))
+}
+
+// OnGuildScheduledEventUserAdd registers the handler function for the "GUILD_SCHEDULED_EVENT_USER_ADD" event.
+// Fired when a user subscribes to a guild scheduled event.
+func (c *Client) OnGuildScheduledEventUserAdd(f func(u *GuildScheduledEventUser)) {
+	c.registerHandler(eventGuildScheduledEventUserAdd, guildScheduledEventUserHandler(f))
+}
+
+// OnGuildScheduledEventUserRemove registers the handler function for the "GUILD_SCHEDULED_EVENT_USER_REMOVE" event.
+// Fired when a user unsubscribes from a guild scheduled event.
+func (c *Client) OnGuildScheduledEventUserRemove(f func(u *GuildScheduledEventUser)) {
+	c.registerHandler(eventGuildScheduledEventUserRemove, guildScheduledEventUserHandler(f))
+}
+
 type GuildInviteCreate struct {
 	ChannelID      string    `json:"channel_id"`
 	Code           string    `json:"code"`
@@ -372,6 +538,36 @@ func (c *Client) OnGuildInviteDelete(f func(i *GuildInviteDelete)) {
 	c.registerHandler(eventGuildInviteDelete, guildInviteDeleteHandler(f))
 }
 
+type interactionCreateHandler func(*Interaction)
+
+// handle implements the handler interface.
+func (h interactionCreateHandler) handle(v interface{}) {
+	h(v.(*Interaction))
+}
+
+// OnInteractionCreate registers the handler function for the "INTERACTION_CREATE" event.
+// Fired when a user uses an application command, interacts with a message component
+// or submits a modal. Interactions must be responded to within 3 seconds, see Client.Interaction.
+func (c *Client) OnInteractionCreate(f func(i *Interaction)) {
+	c.registerHandler(eventInteractionCreate, interactionCreateHandler(f))
+}
+
+// OnMessageComponent registers the handler function for interactions triggered by
+// message components, such as buttons. Those interactions are not passed to the
+// OnInteractionCreate handler when this handler is registered. Use the Update or
+// DeferUpdate methods of Client.Interaction to edit the message the component
+// is attached to.
+func (c *Client) OnMessageComponent(f func(i *Interaction)) {
+	c.registerHandler(eventMessageComponent, interactionCreateHandler(f))
+}
+
+// OnModalSubmit registers the handler function for interactions triggered by users
+// submitting modals, see InteractionResource.RespondModal. Those interactions are
+// not passed to the OnInteractionCreate handler when this handler is registered.
+func (c *Client) OnModalSubmit(f func(i *Interaction)) {
+	c.registerHandler(eventModalSubmit, interactionCreateHandler(f))
+}
+
 type messageCreateHandler func(*Message)
 
 // handle implements the handler interface.
@@ -402,6 +598,10 @@ func (c *Client) OnMessageUpdate(f func(m *Message)) {
 type MessageDelete struct {
 	ChannelID string `json:"channel_id"`
 	MessageID string `json:"id"`
+
+	// Message is the deleted message as it was cached by the
+	// State, nil if it was not. See WithMessageCacheSize.
+	Message *Message `json:"-"`
 }
 
 type messageDeleteHandler func(*MessageDelete)
@@ -421,6 +621,10 @@ type MessageDeleteBulk struct {
 	GuildID   string   `json:"guild_id"`
 	ChannelID string   `json:"channel_id"`
 	IDs       []string `json:"ids"`
+
+	// Messages are the deleted messages that were cached
+	// by the State, in the order of IDs. See WithMessageCacheSize.
+	Messages []Message `json:"-"`
 }
 
 type messageDeleteBulkHandler func(*MessageDeleteBulk)
@@ -620,3 +824,73 @@ func (h webhooksUpdateHandler) handle(v interface{}) {
 func (c *Client) OnWebhooksUpdate(f func(wu *WebhooksUpdate)) {
 	c.registerHandler(eventWebhooksUpdate, webhooksUpdateHandler(f))
 }
+
+type stageInstanceHandler func(*StageInstance)
+
+// handle implements the handler interface.
+func (h stageInstanceHandler) handle(v interface{}) {
+	h(v.(*StageInstance))
+}
+
+// OnStageInstanceCreate registers the handler function for the "STAGE_INSTANCE_CREATE" event.
+// Fired when a stage instance is started in a stage channel.
+func (c *Client) OnStageInstanceCreate(f func(si *StageInstance)) {
+	c.registerHandler(eventStageInstanceCreate, stageInstanceHandler(f))
+}
+
+// OnStageInstanceUpdate registers the handler function for the "STAGE_INSTANCE_UPDATE" event.
+// Fired when the topic or privacy level of a stage instance is updated.
+func (c *Client) OnStageInstanceUpdate(f func(si *StageInstance)) {
+	c.registerHandler(eventStageInstanceUpdate, stageInstanceHandler(f))
+}
+
+// OnStageInstanceDelete registers the handler function for the "STAGE_INSTANCE_DELETE" event.
+// Fired when a stage instance ends.
+func (c *Client) OnStageInstanceDelete(f func(si *StageInstance)) {
+	c.registerHandler(eventStageInstanceDelete, stageInstanceHandler(f))
+}
+
+// RawEvent is a Dispatch event as received from the Gateway, before it is decoded.
+type RawEvent struct {
+	// Type of the event, e.g. "MESSAGE_CREATE".
+	Type string
+	// Data is the JSON payload of the event.
+	Data json.RawMessage
+}
+
+type rawEventHandler func(string, json.RawMessage)
+
+// handle implements the handler interface.
+func (h rawEventHandler) handle(v interface{}) {
+	e := v.(*RawEvent)
+	h(e.Type, e.Data)
+}
+
+// OnRawEvent registers the handler function called with the type and the raw JSON
+// payload of every Dispatch event received from the Gateway, including events this
+// version of Harmony does not know about yet, so they can be consumed without
+// waiting for a new release. It is called in addition to the handler of the event,
+// if any, but not for events dropped by the event filter (see WithEventFilter).
+// Middlewares see those calls as "RAW" events, with a *RawEvent as data. Data must
+// not be modified.
+func (c *Client) OnRawEvent(f func(eventType string, data json.RawMessage)) {
+	c.registerHandler(eventRaw, rawEventHandler(f))
+}
+
+type cloudflareBanHandler func(*CloudflareBan)
+
+// handle implements the handler interface.
+func (h cloudflareBanHandler) handle(v interface{}) {
+	h(v.(*CloudflareBan))
+}
+
+// OnCloudflareBan registers the handler function called when Cloudflare bans the IP
+// address of the client, typically because it sent too many invalid requests (401,
+// 403 or 429 responses). The client stops sending requests until the ban is over,
+// since more requests make it last longer, and requests fail with ErrCloudflareBan
+// meanwhile. This is critical: all bots sharing the IP address are banned, and the
+// cause of the ban should be investigated.
+// Middlewares see those calls as "CLOUDFLARE_BAN" events.
+func (c *Client) OnCloudflareBan(f func(ban *CloudflareBan)) {
+	c.registerHandler(eventCloudflareBan, cloudflareBanHandler(f))
+}
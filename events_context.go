@@ -0,0 +1,929 @@
+package harmony
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/skwair/harmony/voice"
+)
+
+// contextHandler is implemented by handlers registered with OnXxxCtx methods.
+type contextHandler interface {
+	handleContext(context.Context, interface{})
+}
+
+type eventContextKey struct{}
+
+// EventFromContext returns the event carried by the context passed to handlers
+// registered with OnXxxCtx methods, which holds the type of the event and the
+// shard it was received on.
+func EventFromContext(ctx context.Context) (*Event, bool) {
+	e, ok := ctx.Value(eventContextKey{}).(*Event)
+	return e, ok
+}
+
+// cancelHandlersContext cancels the context passed to running event handlers
+// and replaces it with a new one for handlers called after a new connection.
+func (c *Client) cancelHandlersContext() {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+
+	c.cancelHandlers()
+	c.handlersCtx, c.cancelHandlers = context.WithCancel(c.baseCtx)
+}
+
+type readyContextHandler func(context.Context, *Ready)
+
+// handle implements the handler interface.
+func (h readyContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*Ready))
+}
+
+// handleContext implements the contextHandler interface.
+func (h readyContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*Ready))
+}
+
+// OnReadyCtx is like OnReady, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnReadyCtx(f func(ctx context.Context, r *Ready)) {
+	c.registerHandler(eventReady, readyContextHandler(f))
+}
+
+type channelCreateContextHandler func(context.Context, *Channel)
+
+// handle implements the handler interface.
+func (h channelCreateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*Channel))
+}
+
+// handleContext implements the contextHandler interface.
+func (h channelCreateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*Channel))
+}
+
+// OnChannelCreateCtx is like OnChannelCreate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnChannelCreateCtx(f func(ctx context.Context, c *Channel)) {
+	c.registerHandler(eventChannelCreate, channelCreateContextHandler(f))
+}
+
+type channelUpdateContextHandler func(context.Context, *Channel)
+
+// handle implements the handler interface.
+func (h channelUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*Channel))
+}
+
+// handleContext implements the contextHandler interface.
+func (h channelUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*Channel))
+}
+
+// OnChannelUpdateCtx is like OnChannelUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnChannelUpdateCtx(f func(ctx context.Context, c *Channel)) {
+	c.registerHandler(eventChannelUpdate, channelUpdateContextHandler(f))
+}
+
+type channelDeleteContextHandler func(context.Context, *Channel)
+
+// handle implements the handler interface.
+func (h channelDeleteContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*Channel))
+}
+
+// handleContext implements the contextHandler interface.
+func (h channelDeleteContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*Channel))
+}
+
+// OnChannelDeleteCtx is like OnChannelDelete, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnChannelDeleteCtx(f func(ctx context.Context, c *Channel)) {
+	c.registerHandler(eventChannelDelete, channelDeleteContextHandler(f))
+}
+
+type channelPinsUpdateContextHandler func(context.Context, *ChannelPinsUpdate)
+
+// handle implements the handler interface.
+func (h channelPinsUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*ChannelPinsUpdate))
+}
+
+// handleContext implements the contextHandler interface.
+func (h channelPinsUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*ChannelPinsUpdate))
+}
+
+// OnChannelPinsUpdateCtx is like OnChannelPinsUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnChannelPinsUpdateCtx(f func(ctx context.Context, cpu *ChannelPinsUpdate)) {
+	c.registerHandler(eventChannelPinsUpdate, channelPinsUpdateContextHandler(f))
+}
+
+type threadCreateContextHandler func(context.Context, *Channel)
+
+// handle implements the handler interface.
+func (h threadCreateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*Channel))
+}
+
+// handleContext implements the contextHandler interface.
+func (h threadCreateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*Channel))
+}
+
+// OnThreadCreateCtx is like OnThreadCreate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnThreadCreateCtx(f func(ctx context.Context, th *Channel)) {
+	c.registerHandler(eventThreadCreate, threadCreateContextHandler(f))
+}
+
+type threadUpdateContextHandler func(context.Context, *Channel)
+
+// handle implements the handler interface.
+func (h threadUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*Channel))
+}
+
+// handleContext implements the contextHandler interface.
+func (h threadUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*Channel))
+}
+
+// OnThreadUpdateCtx is like OnThreadUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnThreadUpdateCtx(f func(ctx context.Context, th *Channel)) {
+	c.registerHandler(eventThreadUpdate, threadUpdateContextHandler(f))
+}
+
+type threadDeleteContextHandler func(context.Context, *Channel)
+
+// handle implements the handler interface.
+func (h threadDeleteContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*Channel))
+}
+
+// handleContext implements the contextHandler interface.
+func (h threadDeleteContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*Channel))
+}
+
+// OnThreadDeleteCtx is like OnThreadDelete, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnThreadDeleteCtx(f func(ctx context.Context, th *Channel)) {
+	c.registerHandler(eventThreadDelete, threadDeleteContextHandler(f))
+}
+
+type threadListSyncContextHandler func(context.Context, *ThreadListSync)
+
+// handle implements the handler interface.
+func (h threadListSyncContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*ThreadListSync))
+}
+
+// handleContext implements the contextHandler interface.
+func (h threadListSyncContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*ThreadListSync))
+}
+
+// OnThreadListSyncCtx is like OnThreadListSync, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnThreadListSyncCtx(f func(ctx context.Context, tls *ThreadListSync)) {
+	c.registerHandler(eventThreadListSync, threadListSyncContextHandler(f))
+}
+
+type threadMemberUpdateContextHandler func(context.Context, *ThreadMemberUpdate)
+
+// handle implements the handler interface.
+func (h threadMemberUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*ThreadMemberUpdate))
+}
+
+// handleContext implements the contextHandler interface.
+func (h threadMemberUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*ThreadMemberUpdate))
+}
+
+// OnThreadMemberUpdateCtx is like OnThreadMemberUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnThreadMemberUpdateCtx(f func(ctx context.Context, tmu *ThreadMemberUpdate)) {
+	c.registerHandler(eventThreadMemberUpdate, threadMemberUpdateContextHandler(f))
+}
+
+type threadMembersUpdateContextHandler func(context.Context, *ThreadMembersUpdate)
+
+// handle implements the handler interface.
+func (h threadMembersUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*ThreadMembersUpdate))
+}
+
+// handleContext implements the contextHandler interface.
+func (h threadMembersUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*ThreadMembersUpdate))
+}
+
+// OnThreadMembersUpdateCtx is like OnThreadMembersUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnThreadMembersUpdateCtx(f func(ctx context.Context, tmu *ThreadMembersUpdate)) {
+	c.registerHandler(eventThreadMembersUpdate, threadMembersUpdateContextHandler(f))
+}
+
+type guildCreateContextHandler func(context.Context, *Guild)
+
+// handle implements the handler interface.
+func (h guildCreateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*Guild))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildCreateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*Guild))
+}
+
+// OnGuildCreateCtx is like OnGuildCreate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildCreateCtx(f func(ctx context.Context, g *Guild)) {
+	c.registerHandler(eventGuildCreate, guildCreateContextHandler(f))
+}
+
+type guildUpdateContextHandler func(context.Context, *Guild)
+
+// handle implements the handler interface.
+func (h guildUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*Guild))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*Guild))
+}
+
+// OnGuildUpdateCtx is like OnGuildUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildUpdateCtx(f func(ctx context.Context, g *Guild)) {
+	c.registerHandler(eventGuildUpdate, guildUpdateContextHandler(f))
+}
+
+type guildDeleteContextHandler func(context.Context, *UnavailableGuild)
+
+// handle implements the handler interface.
+func (h guildDeleteContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*UnavailableGuild))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildDeleteContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*UnavailableGuild))
+}
+
+// OnGuildDeleteCtx is like OnGuildDelete, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildDeleteCtx(f func(ctx context.Context, g *UnavailableGuild)) {
+	c.registerHandler(eventGuildDelete, guildDeleteContextHandler(f))
+}
+
+type guildBanAddContextHandler func(context.Context, *GuildBan)
+
+// handle implements the handler interface.
+func (h guildBanAddContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildBan))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildBanAddContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildBan))
+}
+
+// OnGuildBanAddCtx is like OnGuildBanAdd, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildBanAddCtx(f func(ctx context.Context, ban *GuildBan)) {
+	c.registerHandler(eventGuildBanAdd, guildBanAddContextHandler(f))
+}
+
+type guildBanRemoveContextHandler func(context.Context, *GuildBan)
+
+// handle implements the handler interface.
+func (h guildBanRemoveContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildBan))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildBanRemoveContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildBan))
+}
+
+// OnGuildBanRemoveCtx is like OnGuildBanRemove, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildBanRemoveCtx(f func(ctx context.Context, ban *GuildBan)) {
+	c.registerHandler(eventGuildBanRemove, guildBanRemoveContextHandler(f))
+}
+
+type guildEmojisUpdateContextHandler func(context.Context, *GuildEmojis)
+
+// handle implements the handler interface.
+func (h guildEmojisUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildEmojis))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildEmojisUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildEmojis))
+}
+
+// OnGuildEmojisUpdateCtx is like OnGuildEmojisUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildEmojisUpdateCtx(f func(ctx context.Context, emojis *GuildEmojis)) {
+	c.registerHandler(eventGuildEmojisUpdate, guildEmojisUpdateContextHandler(f))
+}
+
+type guildStickersUpdateContextHandler func(context.Context, *GuildStickers)
+
+// handle implements the handler interface.
+func (h guildStickersUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildStickers))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildStickersUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildStickers))
+}
+
+// OnGuildStickersUpdateCtx is like OnGuildStickersUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildStickersUpdateCtx(f func(ctx context.Context, stickers *GuildStickers)) {
+	c.registerHandler(eventGuildStickersUpdate, guildStickersUpdateContextHandler(f))
+}
+
+type guildIntegrationUpdateContextHandler func(context.Context, *GuildIntegrationUpdate)
+
+// handle implements the handler interface.
+func (h guildIntegrationUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildIntegrationUpdate))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildIntegrationUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildIntegrationUpdate))
+}
+
+// OnGuildIntegrationsUpdateCtx is like OnGuildIntegrationsUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildIntegrationsUpdateCtx(f func(ctx context.Context, u *GuildIntegrationUpdate)) {
+	c.registerHandler(eventGuildIntegrationsUpdate, guildIntegrationUpdateContextHandler(f))
+}
+
+type guildMemberAddContextHandler func(context.Context, *GuildMemberAdd)
+
+// handle implements the handler interface.
+func (h guildMemberAddContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildMemberAdd))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildMemberAddContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildMemberAdd))
+}
+
+// OnGuildMemberAddCtx is like OnGuildMemberAdd, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildMemberAddCtx(f func(ctx context.Context, m *GuildMemberAdd)) {
+	c.registerHandler(eventGuildMemberAdd, guildMemberAddContextHandler(f))
+}
+
+type guildMemberRemoveContextHandler func(context.Context, *GuildMemberRemove)
+
+// handle implements the handler interface.
+func (h guildMemberRemoveContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildMemberRemove))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildMemberRemoveContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildMemberRemove))
+}
+
+// OnGuildMemberRemoveCtx is like OnGuildMemberRemove, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildMemberRemoveCtx(f func(ctx context.Context, m *GuildMemberRemove)) {
+	c.registerHandler(eventGuildMemberRemove, guildMemberRemoveContextHandler(f))
+}
+
+type guildMemberUpdateContextHandler func(context.Context, *GuildMemberUpdate)
+
+// handle implements the handler interface.
+func (h guildMemberUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildMemberUpdate))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildMemberUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildMemberUpdate))
+}
+
+// OnGuildMemberUpdateCtx is like OnGuildMemberUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildMemberUpdateCtx(f func(ctx context.Context, m *GuildMemberUpdate)) {
+	c.registerHandler(eventGuildMemberUpdate, guildMemberUpdateContextHandler(f))
+}
+
+type guildMembersChunkContextHandler func(context.Context, *GuildMembersChunk)
+
+// handle implements the handler interface.
+func (h guildMembersChunkContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildMembersChunk))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildMembersChunkContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildMembersChunk))
+}
+
+// OnGuildMembersChunkCtx is like OnGuildMembersChunk, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildMembersChunkCtx(f func(ctx context.Context, m *GuildMembersChunk)) {
+	c.registerHandler(eventGuildMembersChunk, guildMembersChunkContextHandler(f))
+}
+
+type guildRoleCreateContextHandler func(context.Context, *GuildRole)
+
+// handle implements the handler interface.
+func (h guildRoleCreateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildRole))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildRoleCreateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildRole))
+}
+
+// OnGuildRoleCreateCtx is like OnGuildRoleCreate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildRoleCreateCtx(f func(ctx context.Context, r *GuildRole)) {
+	c.registerHandler(eventGuildRoleCreate, guildRoleCreateContextHandler(f))
+}
+
+type guildRoleUpdateContextHandler func(context.Context, *GuildRole)
+
+// handle implements the handler interface.
+func (h guildRoleUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildRole))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildRoleUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildRole))
+}
+
+// OnGuildRoleUpdateCtx is like OnGuildRoleUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildRoleUpdateCtx(f func(ctx context.Context, r *GuildRole)) {
+	c.registerHandler(eventGuildRoleUpdate, guildRoleUpdateContextHandler(f))
+}
+
+type guildRoleDeleteContextHandler func(context.Context, *GuildRoleDelete)
+
+// handle implements the handler interface.
+func (h guildRoleDeleteContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildRoleDelete))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildRoleDeleteContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildRoleDelete))
+}
+
+// OnGuildRoleDeleteCtx is like OnGuildRoleDelete, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildRoleDeleteCtx(f func(ctx context.Context, r *GuildRoleDelete)) {
+	c.registerHandler(eventGuildRoleDelete, guildRoleDeleteContextHandler(f))
+}
+
+type guildScheduledEventUserContextHandler func(context.Context, *GuildScheduledEventUser)
+
+// handle implements the handler interface.
+func (h guildScheduledEventUserContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildScheduledEventUser))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildScheduledEventUserContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildScheduledEventUser))
+}
+
+// OnGuildScheduledEventUserAddCtx is like OnGuildScheduledEventUserAdd, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildScheduledEventUserAddCtx(f func(ctx context.Context, u *GuildScheduledEventUser)) {
+	c.registerHandler(eventGuildScheduledEventUserAdd, guildScheduledEventUserContextHandler(f))
+}
+
+// OnGuildScheduledEventUserRemoveCtx is like OnGuildScheduledEventUserRemove, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildScheduledEventUserRemoveCtx(f func(ctx context.Context, u *GuildScheduledEventUser)) {
+	c.registerHandler(eventGuildScheduledEventUserRemove, guildScheduledEventUserContextHandler(f))
+}
+
+type guildInviteCreateContextHandler func(context.Context, *GuildInviteCreate)
+
+// handle implements the handler interface.
+func (h guildInviteCreateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildInviteCreate))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildInviteCreateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildInviteCreate))
+}
+
+// OnGuildInviteCreateCtx is like OnGuildInviteCreate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildInviteCreateCtx(f func(ctx context.Context, i *GuildInviteCreate)) {
+	c.registerHandler(eventGuildInviteCreate, guildInviteCreateContextHandler(f))
+}
+
+type guildInviteDeleteContextHandler func(context.Context, *GuildInviteDelete)
+
+// handle implements the handler interface.
+func (h guildInviteDeleteContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*GuildInviteDelete))
+}
+
+// handleContext implements the contextHandler interface.
+func (h guildInviteDeleteContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*GuildInviteDelete))
+}
+
+// OnGuildInviteDeleteCtx is like OnGuildInviteDelete, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnGuildInviteDeleteCtx(f func(ctx context.Context, i *GuildInviteDelete)) {
+	c.registerHandler(eventGuildInviteDelete, guildInviteDeleteContextHandler(f))
+}
+
+type interactionCreateContextHandler func(context.Context, *Interaction)
+
+// handle implements the handler interface.
+func (h interactionCreateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*Interaction))
+}
+
+// handleContext implements the contextHandler interface.
+func (h interactionCreateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*Interaction))
+}
+
+// OnInteractionCreateCtx is like OnInteractionCreate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnInteractionCreateCtx(f func(ctx context.Context, i *Interaction)) {
+	c.registerHandler(eventInteractionCreate, interactionCreateContextHandler(f))
+}
+
+// OnMessageComponentCtx is like OnMessageComponent, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnMessageComponentCtx(f func(ctx context.Context, i *Interaction)) {
+	c.registerHandler(eventMessageComponent, interactionCreateContextHandler(f))
+}
+
+// OnModalSubmitCtx is like OnModalSubmit, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnModalSubmitCtx(f func(ctx context.Context, i *Interaction)) {
+	c.registerHandler(eventModalSubmit, interactionCreateContextHandler(f))
+}
+
+type messageCreateContextHandler func(context.Context, *Message)
+
+// handle implements the handler interface.
+func (h messageCreateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*Message))
+}
+
+// handleContext implements the contextHandler interface.
+func (h messageCreateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*Message))
+}
+
+// OnMessageCreateCtx is like OnMessageCreate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnMessageCreateCtx(f func(ctx context.Context, m *Message)) {
+	c.registerHandler(eventMessageCreate, messageCreateContextHandler(f))
+}
+
+type messageUpdateContextHandler func(context.Context, *Message)
+
+// handle implements the handler interface.
+func (h messageUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*Message))
+}
+
+// handleContext implements the contextHandler interface.
+func (h messageUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*Message))
+}
+
+// OnMessageUpdateCtx is like OnMessageUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnMessageUpdateCtx(f func(ctx context.Context, m *Message)) {
+	c.registerHandler(eventMessageUpdate, messageUpdateContextHandler(f))
+}
+
+type messageDeleteContextHandler func(context.Context, *MessageDelete)
+
+// handle implements the handler interface.
+func (h messageDeleteContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*MessageDelete))
+}
+
+// handleContext implements the contextHandler interface.
+func (h messageDeleteContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*MessageDelete))
+}
+
+// OnMessageDeleteCtx is like OnMessageDelete, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnMessageDeleteCtx(f func(ctx context.Context, m *MessageDelete)) {
+	c.registerHandler(eventMessageDelete, messageDeleteContextHandler(f))
+}
+
+type messageDeleteBulkContextHandler func(context.Context, *MessageDeleteBulk)
+
+// handle implements the handler interface.
+func (h messageDeleteBulkContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*MessageDeleteBulk))
+}
+
+// handleContext implements the contextHandler interface.
+func (h messageDeleteBulkContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*MessageDeleteBulk))
+}
+
+// OnMessageDeleteBulkCtx is like OnMessageDeleteBulk, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnMessageDeleteBulkCtx(f func(ctx context.Context, mdb *MessageDeleteBulk)) {
+	c.registerHandler(eventMessageDeleteBulk, messageDeleteBulkContextHandler(f))
+}
+
+type messageAckContextHandler func(context.Context, *MessageAck)
+
+// handle implements the handler interface.
+func (h messageAckContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*MessageAck))
+}
+
+// handleContext implements the contextHandler interface.
+func (h messageAckContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*MessageAck))
+}
+
+// OnMessageAckCtx is like OnMessageAck, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnMessageAckCtx(f func(ctx context.Context, ack *MessageAck)) {
+	c.registerHandler(eventMessageAck, messageAckContextHandler(f))
+}
+
+type messageReactionAddContextHandler func(context.Context, *MessageReaction)
+
+// handle implements the handler interface.
+func (h messageReactionAddContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*MessageReaction))
+}
+
+// handleContext implements the contextHandler interface.
+func (h messageReactionAddContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*MessageReaction))
+}
+
+// OnMessageReactionAddCtx is like OnMessageReactionAdd, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnMessageReactionAddCtx(f func(ctx context.Context, r *MessageReaction)) {
+	c.registerHandler(eventMessageReactionAdd, messageReactionAddContextHandler(f))
+}
+
+type messageReactionRemoveContextHandler func(context.Context, *MessageReaction)
+
+// handle implements the handler interface.
+func (h messageReactionRemoveContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*MessageReaction))
+}
+
+// handleContext implements the contextHandler interface.
+func (h messageReactionRemoveContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*MessageReaction))
+}
+
+// OnMessageReactionRemoveCtx is like OnMessageReactionRemove, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnMessageReactionRemoveCtx(f func(ctx context.Context, r *MessageReaction)) {
+	c.registerHandler(eventMessageReactionRemove, messageReactionRemoveContextHandler(f))
+}
+
+type messageReactionRemoveAllContextHandler func(context.Context, *MessageReactionRemoveAll)
+
+// handle implements the handler interface.
+func (h messageReactionRemoveAllContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*MessageReactionRemoveAll))
+}
+
+// handleContext implements the contextHandler interface.
+func (h messageReactionRemoveAllContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*MessageReactionRemoveAll))
+}
+
+// OnMessageReactionRemoveAllCtx is like OnMessageReactionRemoveAll, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnMessageReactionRemoveAllCtx(f func(ctx context.Context, r *MessageReactionRemoveAll)) {
+	c.registerHandler(eventMessageReactionRemoveAll, messageReactionRemoveAllContextHandler(f))
+}
+
+type messageReactionRemoveEmojiContextHandler func(context.Context, *MessageReactionRemoveEmoji)
+
+// handle implements the handler interface.
+func (h messageReactionRemoveEmojiContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*MessageReactionRemoveEmoji))
+}
+
+// handleContext implements the contextHandler interface.
+func (h messageReactionRemoveEmojiContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*MessageReactionRemoveEmoji))
+}
+
+// OnMessageReactionRemoveEmojiCtx is like OnMessageReactionRemoveEmoji, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnMessageReactionRemoveEmojiCtx(f func(ctx context.Context, r *MessageReactionRemoveEmoji)) {
+	c.registerHandler(eventMessageReactionRemoveEmoji, messageReactionRemoveEmojiContextHandler(f))
+}
+
+type presenceUpdateContextHandler func(context.Context, *Presence)
+
+// handle implements the handler interface.
+func (h presenceUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*Presence))
+}
+
+// handleContext implements the contextHandler interface.
+func (h presenceUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*Presence))
+}
+
+// OnPresenceUpdateCtx is like OnPresenceUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnPresenceUpdateCtx(f func(ctx context.Context, p *Presence)) {
+	c.registerHandler(eventPresenceUpdate, presenceUpdateContextHandler(f))
+}
+
+type typingStartContextHandler func(context.Context, *TypingStart)
+
+// handle implements the handler interface.
+func (h typingStartContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*TypingStart))
+}
+
+// handleContext implements the contextHandler interface.
+func (h typingStartContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*TypingStart))
+}
+
+// OnTypingStartCtx is like OnTypingStart, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnTypingStartCtx(f func(ctx context.Context, ts *TypingStart)) {
+	c.registerHandler(eventTypingStart, typingStartContextHandler(f))
+}
+
+type userUpdateContextHandler func(context.Context, *User)
+
+// handle implements the handler interface.
+func (h userUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*User))
+}
+
+// handleContext implements the contextHandler interface.
+func (h userUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*User))
+}
+
+// OnUserUpdateCtx is like OnUserUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnUserUpdateCtx(f func(ctx context.Context, u *User)) {
+	c.registerHandler(eventUserUpdate, userUpdateContextHandler(f))
+}
+
+type voiceStateUpdateContextHandler func(context.Context, *voice.StateUpdate)
+
+// handle implements the handler interface.
+func (h voiceStateUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*voice.StateUpdate))
+}
+
+// handleContext implements the contextHandler interface.
+func (h voiceStateUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*voice.StateUpdate))
+}
+
+// OnVoiceStateUpdateCtx is like OnVoiceStateUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnVoiceStateUpdateCtx(f func(ctx context.Context, update *voice.StateUpdate)) {
+	c.registerHandler(eventVoiceStateUpdate, voiceStateUpdateContextHandler(f))
+}
+
+type voiceServerUpdateContextHandler func(context.Context, *voice.ServerUpdate)
+
+// handle implements the handler interface.
+func (h voiceServerUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*voice.ServerUpdate))
+}
+
+// handleContext implements the contextHandler interface.
+func (h voiceServerUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*voice.ServerUpdate))
+}
+
+// OnVoiceServerUpdateCtx is like OnVoiceServerUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnVoiceServerUpdateCtx(f func(ctx context.Context, update *voice.ServerUpdate)) {
+	c.registerHandler(eventVoiceServerUpdate, voiceServerUpdateContextHandler(f))
+}
+
+type webhooksUpdateContextHandler func(context.Context, *WebhooksUpdate)
+
+// handle implements the handler interface.
+func (h webhooksUpdateContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*WebhooksUpdate))
+}
+
+// handleContext implements the contextHandler interface.
+func (h webhooksUpdateContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*WebhooksUpdate))
+}
+
+// OnWebhooksUpdateCtx is like OnWebhooksUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnWebhooksUpdateCtx(f func(ctx context.Context, wu *WebhooksUpdate)) {
+	c.registerHandler(eventWebhooksUpdate, webhooksUpdateContextHandler(f))
+}
+
+type stageInstanceContextHandler func(context.Context, *StageInstance)
+
+// handle implements the handler interface.
+func (h stageInstanceContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*StageInstance))
+}
+
+// handleContext implements the contextHandler interface.
+func (h stageInstanceContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*StageInstance))
+}
+
+// OnStageInstanceCreateCtx is like OnStageInstanceCreate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnStageInstanceCreateCtx(f func(ctx context.Context, si *StageInstance)) {
+	c.registerHandler(eventStageInstanceCreate, stageInstanceContextHandler(f))
+}
+
+// OnStageInstanceUpdateCtx is like OnStageInstanceUpdate, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnStageInstanceUpdateCtx(f func(ctx context.Context, si *StageInstance)) {
+	c.registerHandler(eventStageInstanceUpdate, stageInstanceContextHandler(f))
+}
+
+// OnStageInstanceDeleteCtx is like OnStageInstanceDelete, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnStageInstanceDeleteCtx(f func(ctx context.Context, si *StageInstance)) {
+	c.registerHandler(eventStageInstanceDelete, stageInstanceContextHandler(f))
+}
+
+type rawEventContextHandler func(context.Context, string, json.RawMessage)
+
+// handle implements the handler interface.
+func (h rawEventContextHandler) handle(v interface{}) {
+	e := v.(*RawEvent)
+	h(context.Background(), e.Type, e.Data)
+}
+
+// handleContext implements the contextHandler interface.
+func (h rawEventContextHandler) handleContext(ctx context.Context, v interface{}) {
+	e := v.(*RawEvent)
+	h(ctx, e.Type, e.Data)
+}
+
+// OnRawEventCtx is like OnRawEvent, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnRawEventCtx(f func(ctx context.Context, eventType string, data json.RawMessage)) {
+	c.registerHandler(eventRaw, rawEventContextHandler(f))
+}
+
+type cloudflareBanContextHandler func(context.Context, *CloudflareBan)
+
+// handle implements the handler interface.
+func (h cloudflareBanContextHandler) handle(v interface{}) {
+	h(context.Background(), v.(*CloudflareBan))
+}
+
+// handleContext implements the contextHandler interface.
+func (h cloudflareBanContextHandler) handleContext(ctx context.Context, v interface{}) {
+	h(ctx, v.(*CloudflareBan))
+}
+
+// OnCloudflareBanCtx is like OnCloudflareBan, but the handler function is given a context
+// canceled when the client disconnects, see EventFromContext.
+func (c *Client) OnCloudflareBanCtx(f func(ctx context.Context, ban *CloudflareBan)) {
+	c.registerHandler(eventCloudflareBan, cloudflareBanContextHandler(f))
+}
@@ -0,0 +1,90 @@
+package harmony_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/harmonytest"
+)
+
+func TestEventHandlerContext(t *testing.T) {
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	type call struct {
+		ctx   context.Context
+		event *harmony.Event
+		ok    bool
+	}
+	calls := make(chan call, 2)
+	c.OnTypingStartCtx(func(ctx context.Context, ts *harmony.TypingStart) {
+		e, ok := harmony.EventFromContext(ctx)
+		calls <- call{ctx: ctx, event: e, ok: ok}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = c.Connect(ctx); err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+
+	typing := &harmony.TypingStart{ChannelID: "1", UserID: "2"}
+	if err = srv.Dispatch("TYPING_START", typing); err != nil {
+		t.Fatalf("could not dispatch event: %v", err)
+	}
+
+	var first call
+	select {
+	case first = <-calls:
+	case <-ctx.Done():
+		t.Fatal("expected the handler to be called")
+	}
+	if !first.ok || first.event.Type != "TYPING_START" || first.event.Shard != 0 {
+		t.Errorf("expected a TYPING_START event on shard 0 in the context, got %+v", first.event)
+	}
+	if ts, ok := first.event.Data.(*harmony.TypingStart); !ok || ts.ChannelID != "1" {
+		t.Errorf("expected the data of the event to be the Typing Start, got %+v", first.event.Data)
+	}
+	if first.ctx.Err() != nil {
+		t.Errorf("expected the context not to be canceled while connected, got %v", first.ctx.Err())
+	}
+
+	c.Disconnect()
+	select {
+	case <-first.ctx.Done():
+	case <-ctx.Done():
+		t.Fatal("expected the context to be canceled once disconnected")
+	}
+
+	// Handlers called after a new connection are given a new context.
+	if err = c.Connect(ctx); err != nil {
+		t.Fatalf("could not connect again: %v", err)
+	}
+	defer c.Disconnect()
+
+	if err = srv.Dispatch("TYPING_START", typing); err != nil {
+		t.Fatalf("could not dispatch event: %v", err)
+	}
+	select {
+	case second := <-calls:
+		if second.ctx.Err() != nil {
+			t.Errorf("expected the context of the new connection not to be canceled, got %v", second.ctx.Err())
+		}
+	case <-ctx.Done():
+		t.Fatal("expected the handler to be called again")
+	}
+}
+
+func TestEventFromContext(t *testing.T) {
+	if e, ok := harmony.EventFromContext(context.Background()); ok || e != nil {
+		t.Errorf("expected no event in a context not given to a handler, got %+v", e)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/skwair/harmony"
 	"github.com/skwair/harmony/embed"
@@ -31,6 +32,20 @@ func (b *bot) handleCommand(ctx context.Context, m *harmony.Message) {
 			}
 
 			b.players[m.GuildID] = p
+
+			q, err := voice.NewQueue(m.GuildID, p, nil)
+			if err != nil {
+				b.sendError(ctx, m.ChannelID, err.Error())
+				return
+			}
+			// Announce each new track in the channel the first command was sent.
+			q.OnNowPlaying(func(t voice.Track) {
+				e := embed.New().Description(":notes: Now playing: " + t.Title).Build()
+				if _, err := b.client.Channel(m.ChannelID).Send(context.Background(), harmony.WithEmbed(e)); err != nil {
+					fmt.Println("could not send now playing message:", err)
+				}
+			})
+			b.queues[m.GuildID] = q
 		} else {
 			// If we already have an active player in this guild, then switch it to the user's channel.
 			// This is a no-op if it already was in the correct channel.
@@ -40,16 +55,44 @@ func (b *bot) handleCommand(ctx context.Context, m *harmony.Message) {
 			}
 		}
 
-		// Start playing a track with the player.
-		if err := p.Play("./happyrock.mp3"); err != nil {
+		// Add the track to the queue, it will start playing right
+		// away if nothing else is being played.
+		track := voice.Track{
+			Source:      "./happyrock.mp3",
+			Title:       "Happy Rock - Bensound",
+			Duration:    time.Minute + 45*time.Second,
+			RequestedBy: m.Author.ID,
+		}
+		if err := b.queues[m.GuildID].Enqueue(track); err != nil {
+			b.sendError(ctx, m.ChannelID, err.Error())
+			return
+		}
+
+	case "!skip":
+		q, ok := b.queues[m.GuildID]
+		if !ok {
+			b.sendError(ctx, m.ChannelID, "bot not playing music in this guild")
+			return
+		}
+
+		if err := q.Skip(); err != nil {
 			b.sendError(ctx, m.ChannelID, err.Error())
+		}
+
+	case "!loop":
+		q, ok := b.queues[m.GuildID]
+		if !ok {
+			b.sendError(ctx, m.ChannelID, "bot not playing music in this guild")
 			return
 		}
 
+		// Cycle through loop modes: off -> track -> queue -> off.
+		q.SetLoopMode((q.LoopMode() + 1) % 3)
+
 	case "!stop":
-		// If we have a player in this guild, stop it, else reply with an error.
-		if player, ok := b.players[m.GuildID]; ok {
-			player.Stop()
+		// If we have a queue in this guild, clear it, else reply with an error.
+		if q, ok := b.queues[m.GuildID]; ok {
+			q.Clear()
 		} else {
 			b.sendError(ctx, m.ChannelID, "bot not playing music in this guild")
 		}
@@ -62,6 +105,8 @@ func (b *bot) handleCommand(ctx context.Context, m *harmony.Message) {
 		}
 
 		// Else, destroy it and leave the voice channel.
+		b.queues[m.GuildID].Clear()
+		delete(b.queues, m.GuildID)
 		player.Destroy()
 		delete(b.players, m.GuildID)
 
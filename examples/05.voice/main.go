@@ -16,7 +16,8 @@ type bot struct {
 	client *harmony.Client
 
 	botID   string
-	players map[string]*Player // Player per guild ID.
+	players map[string]*Player      // Player per guild ID.
+	queues  map[string]*voice.Queue // Queue per guild ID, wrapping the player of the guild.
 }
 
 func main() {
@@ -35,6 +36,7 @@ func main() {
 	b := &bot{
 		client:  client,
 		players: make(map[string]*Player),
+		queues:  make(map[string]*voice.Queue),
 	}
 
 	// Subscribe to the ready event to know the bot user ID.
@@ -73,6 +75,8 @@ func (b *bot) onVoiceUpdate(vsu *voice.StateUpdate) {
 	// the player that was associated with it.
 	if vsu.UserID == b.botID && vsu.ChannelID == nil {
 		if player, ok := b.players[vsu.GuildID]; ok {
+			b.queues[vsu.GuildID].Clear()
+			delete(b.queues, vsu.GuildID)
 			player.Destroy()
 			delete(b.players, vsu.GuildID)
 		}
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/skwair/harmony/voice"
 	"github.com/skwair/harmony/voice/voiceutil"
@@ -21,7 +22,9 @@ const (
 	stateDestroyed
 )
 
-// Player wraps a voice connection and allows to play audio tracks on it.
+// Player wraps a voice connection and allows to play audio tracks on it,
+// decoded with ffmpeg. It implements voice.QueuePlayer so tracks can be
+// queued with voice.NewQueue.
 // Only one player may be bound to a voice connection at a time.
 type Player struct {
 	mu sync.Mutex
@@ -38,6 +41,8 @@ type Player struct {
 	playDone chan struct{}
 }
 
+var _ voice.QueuePlayer = (*Player)(nil)
+
 // NewPlayer returns a new player for the given voice connection.
 // A Player should be destroyed when not needed anymore by calling its
 // Destroy method.
@@ -59,6 +64,11 @@ func NewPlayer(conn *voice.Connection) (*Player, error) {
 // Play starts playing the given track on the Player.
 // It is an error to start playing a track if one is already playing.
 func (p *Player) Play(track string) error {
+	return p.PlayFrom(track, 0)
+}
+
+// PlayFrom is like Play but starts playing the track at the given offset.
+func (p *Player) PlayFrom(track string, offset time.Duration) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -73,8 +83,14 @@ func (p *Player) Play(track string) error {
 	p.state = statePlaying
 	p.playDone = make(chan struct{})
 
-	p.cmd = exec.Command(
-		"ffmpeg",
+	var args []string
+	if offset > 0 {
+		// Place the seek option before the input so ffmpeg
+		// skips directly to the offset instead of decoding
+		// everything up to it.
+		args = append(args, "-ss", strconv.FormatFloat(offset.Seconds(), 'f', 3, 64))
+	}
+	args = append(args,
 		"-i", track,
 		"-f", "s16le",
 		"-ar", strconv.Itoa(voiceutil.SampleRate),
@@ -82,6 +98,7 @@ func (p *Player) Play(track string) error {
 		"-acodec", "pcm_s16le",
 		"pipe:1",
 	)
+	p.cmd = exec.Command("ffmpeg", args...)
 
 	ffmpegStdOut, err := p.cmd.StdoutPipe()
 	if err != nil {
@@ -125,6 +142,16 @@ func (p *Player) Play(track string) error {
 	return nil
 }
 
+// Done returns a channel that is closed when the track currently being
+// played ends, either because it was played entirely or because the player
+// was stopped. It returns nil if no track was ever played.
+func (p *Player) Done() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.playDone
+}
+
 // Stop stops the currently played track. It's a no-op if nothing is being played.
 func (p *Player) Stop() {
 	p.mu.Lock()
@@ -3,6 +3,7 @@ package harmony
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 
 	"github.com/skwair/harmony/internal/endpoint"
 )
@@ -28,20 +29,52 @@ func (c *Client) Gateway(ctx context.Context) (string, error) {
 
 // GatewayBot returns a valid WSS URL and the recommended number of shards to connect with.
 func (c *Client) GatewayBot(ctx context.Context) (string, int, error) {
+	info, err := c.GatewayBotInfo(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	return info.URL, info.Shards, nil
+}
+
+// GatewayBotInfo holds information required to connect a bot to the Gateway.
+type GatewayBotInfo struct {
+	URL string `json:"url"`
+	// Recommended number of shards to connect with.
+	Shards            int               `json:"shards"`
+	SessionStartLimit SessionStartLimit `json:"session_start_limit"`
+}
+
+// SessionStartLimit describes how many sessions a bot can start.
+type SessionStartLimit struct {
+	// Total number of session starts allowed per day.
+	Total int `json:"total"`
+	// Number of session starts remaining.
+	Remaining int `json:"remaining"`
+	// Number of milliseconds until the limit resets.
+	ResetAfter int `json:"reset_after"`
+	// Number of shards that can identify at the same time,
+	// every 5 seconds.
+	MaxConcurrency int `json:"max_concurrency"`
+}
+
+// GatewayBotInfo returns a valid WSS URL, the recommended number of shards
+// to connect with and the limits on starting new sessions.
+func (c *Client) GatewayBotInfo(ctx context.Context) (*GatewayBotInfo, error) {
 	e := endpoint.GatewayBot()
 	resp, err := c.doReq(ctx, e, nil)
 	if err != nil {
-		return "", 0, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var gateway struct {
-		URL    string
-		Shards int
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
 	}
-	if err = json.NewDecoder(resp.Body).Decode(&gateway); err != nil {
-		return "", 0, err
+
+	var info GatewayBotInfo
+	if err = json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, err
 	}
-	c.gatewayURL = gateway.URL
-	return gateway.URL, gateway.Shards, nil
+	c.gatewayURL = info.URL
+	return &info, nil
 }
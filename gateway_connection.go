@@ -12,21 +12,50 @@ import (
 	"nhooyr.io/websocket"
 
 	"github.com/skwair/harmony/internal/payload"
+	"github.com/skwair/harmony/voice"
 )
 
 const (
-	gatewayVersion  = 6
+	gatewayVersion  = 9
 	gatewayEncoding = "json"
+
+	// closeCodeInvalidIntents is the close code sent by the Gateway
+	// when identifying with an invalid value for intents.
+	closeCodeInvalidIntents = 4013
+	// closeCodeDisallowedIntents is the close code sent by the Gateway
+	// when identifying with privileged intents the application is not
+	// allowed to use.
+	closeCodeDisallowedIntents = 4014
 )
 
 // Connect connects and identifies the client to the Discord Gateway.
+// Plugins are started the first time the client connects, see Plugin.
 func (c *Client) Connect(ctx context.Context) error {
+	if err := c.connect(ctx); err != nil {
+		return err
+	}
+
+	if err := c.startPlugins(ctx); err != nil {
+		c.Disconnect()
+		return err
+	}
+	return nil
+}
+
+// connect connects and identifies the client to the Discord Gateway.
+func (c *Client) connect(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if c.isConnected() {
 		return ErrAlreadyConnected
 	}
+	if err := c.baseCtx.Err(); err != nil {
+		return fmt.Errorf("client context is done: %w", err)
+	}
+	if c.bearer {
+		return ErrBotTokenRequired
+	}
 
 	c.connecting.Store(true)
 	defer c.connecting.Store(false)
@@ -45,25 +74,37 @@ func (c *Client) Connect(ctx context.Context) error {
 
 	// Those fields' lifecycle is tied to a connection, not to the Client,
 	// so we need to initialize them each time we attempt a new connection.
-	c.voicePayloads = make(chan *payload.Payload)
+	c.voicePayloads = make(chan *payload.Payload, voicePayloadsSize)
 	c.error = make(chan error)
 	c.reportErrorOnce = sync.Once{}
 	c.stop = make(chan struct{})
 
 	// This context is bound to the Gateway connection and will be
 	// canceled when it is closed.
-	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.ctx, c.cancel = context.WithCancel(c.baseCtx)
 
 	// Open the Gateway websocket connection.
 	header := make(http.Header)
 	header.Add("Accept-Encoding", "zlib")
 	gwURL := fmt.Sprintf("%s?v=%d&encoding=%s", c.gatewayURL, gatewayVersion, gatewayEncoding)
+	if c.compression {
+		gwURL += "&compress=zlib-stream"
+	}
 	c.logger.Debugf("connecting to the gateway: %s", gwURL)
-	c.conn, _, err = websocket.Dial(ctx, gwURL, &websocket.DialOptions{HTTPHeader: header})
+	c.conn, _, err = websocket.Dial(ctx, gwURL, &websocket.DialOptions{
+		HTTPHeader: header,
+		HTTPClient: c.dialClient,
+	})
 	if err != nil {
 		return err
 	}
 
+	// Each connection has its own compression context.
+	c.inflater = nil
+	if c.compression {
+		c.inflater = payload.NewInflater()
+	}
+
 	// If any error occurs during the connection process, we
 	// should close the underlying websocket connection, so
 	// we can try to reconnect later. We should also signal
@@ -77,6 +118,7 @@ func (c *Client) Connect(ctx context.Context) error {
 			c.connected.Store(false)
 			close(c.stop)
 			c.cancel()
+			c.closeInflater()
 		}
 	}()
 
@@ -111,6 +153,13 @@ func (c *Client) Connect(ctx context.Context) error {
 
 		// The Gateway should send us a Ready event if we successfully authenticated.
 		if err = c.ready(); err != nil {
+			err = gatewayCloseError(err)
+			switch websocket.CloseStatus(err) {
+			case closeCodeInvalidIntents:
+				err = fmt.Errorf("%w (intents=%d), check the value given to WithGatewayIntents", err, c.intents)
+			case closeCodeDisallowedIntents:
+				err = c.disallowedIntentsError(ctx, err)
+			}
 			return err
 		}
 	} else {
@@ -139,7 +188,13 @@ func (c *Client) Connect(ctx context.Context) error {
 }
 
 // Disconnect closes the connection to the Discord Gateway.
+// Plugins are stopped before the connection is closed, channels
+// returned by Events are closed and subscriptions are ended.
 func (c *Client) Disconnect() {
+	c.cancelHandlersContext()
+	c.stopPlugins()
+	c.closeEventStreams()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -156,16 +211,21 @@ func (c *Client) Disconnect() {
 
 	var wg sync.WaitGroup
 
+	conns := make(map[string]*voice.Connection, len(c.voiceConnections))
 	for guildID := range c.voiceConnections {
+		conns[guildID] = c.removeVoiceConnection(guildID)
+	}
+
+	for guildID, conn := range conns {
 		wg.Add(1)
 
-		go func(guildID string) {
+		go func(guildID string, conn *voice.Connection) {
 			defer wg.Done()
 
-			if err := c.LeaveVoiceChannel(ctx, guildID); err != nil {
+			if err := c.leaveVoiceChannel(ctx, guildID, conn); err != nil {
 				c.logger.Errorf("could not properly disconnect from voice channel: %v", err)
 			}
-		}(guildID)
+		}(guildID, conn)
 	}
 
 	// Wait for all voice connections to be closed.
@@ -177,6 +237,14 @@ func (c *Client) Disconnect() {
 	c.wg.Wait()
 }
 
+// disconnectOnDone disconnects the client once its parent context is done.
+// See WithContext.
+func (c *Client) disconnectOnDone() {
+	<-c.baseCtx.Done()
+	c.logger.Debug("client context is done, disconnecting")
+	c.Disconnect()
+}
+
 // wait waits for an error to happen while connected to the Gateway
 // or for a stop signal to be sent.
 // If an unexpected error happens while connected to the
@@ -191,6 +259,7 @@ func (c *Client) wait() {
 	select {
 	// An unexpected error occurred while communicating with the Gateway.
 	case err = <-c.error:
+		err = gatewayCloseError(err)
 		c.onGatewayError(err)
 
 	// User called Client.Disconnect.
@@ -202,11 +271,20 @@ func (c *Client) wait() {
 	close(c.voicePayloads)
 
 	c.cancel()
+	c.closeInflater()
 	c.connected.Store(false)
 
 	// If there was an error, try to reconnect depending on its code.
 	if shouldReconnect(err) {
-		c.reconnectWithBackoff()
+		c.reconnectWithBackoff(err)
+	}
+}
+
+// closeInflater stops the inflater of the
+// connection, if compression is enabled.
+func (c *Client) closeInflater() {
+	if c.inflater != nil {
+		_ = c.inflater.Close() // Closing a pipe never fails.
 	}
 }
 
@@ -222,7 +300,7 @@ func shouldReconnect(err error) bool {
 	}
 
 	switch websocket.CloseStatus(err) {
-	case 4001, 4002, 4003, 4004, 4005, 4010, 4011:
+	case 4001, 4002, 4003, 4004, 4005, 4010, 4011, 4012, closeCodeInvalidIntents, closeCodeDisallowedIntents:
 		return false
 	case 4000, 4007, 4008, 4009:
 		return true
@@ -233,23 +311,63 @@ func shouldReconnect(err error) bool {
 	}
 }
 
-// reconnectWithBackoff attempts to reconnect to the Gateway using the Client's
-// backoff strategy.
-func (c *Client) reconnectWithBackoff() {
+// disallowedIntentsError fetches the flags of the application to determine which
+// privileged intents are missing and returns a DisallowedIntentsError wrapping err.
+func (c *Client) disallowedIntentsError(ctx context.Context, err error) error {
+	e := &DisallowedIntentsError{
+		Missing: missingPrivilegedIntents(c.intents, 0),
+		Err:     err,
+	}
+
+	app, appErr := c.ApplicationInfo(ctx)
+	if appErr != nil {
+		c.logger.Errorf("could not fetch application flags to diagnose disallowed intents: %v", appErr)
+		return e
+	}
+
+	e.ApplicationID = app.ID
+	// Discord rejected the intents anyway, so keep the list of all
+	// requested privileged intents if flags look fine to us.
+	if missing := missingPrivilegedIntents(c.intents, app.Flags); missing != 0 {
+		e.Missing = missing
+	}
+	return e
+}
+
+// reconnectWithBackoff attempts to reconnect to the Gateway after the given
+// error using the Client's reconnect policy.
+func (c *Client) reconnectWithBackoff(cause error) {
 	c.reconnecting.Store(true)
 	defer c.reconnecting.Store(false)
 
 	c.logger.Debug("trying to reconnect to the gateway")
 
+	lastErr := cause
 	for i := 0; true; i++ {
+		if c.maxReconnectAttempts > 0 && i >= c.maxReconnectAttempts {
+			c.giveUpReconnecting(fmt.Errorf("%w after %d attempts: %v", ErrReconnectAborted, i, lastErr))
+			return
+		}
+		if c.onReconnectAttempt != nil && !c.onReconnectAttempt(i+1, lastErr) {
+			c.giveUpReconnecting(fmt.Errorf("%w by the reconnect policy: %v", ErrReconnectAborted, lastErr))
+			return
+		}
+
 		// Try to establish a new connection with a 30 seconds timeout.
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := context.WithTimeout(c.baseCtx, 30*time.Second)
 
-		if err := c.Connect(ctx); err != nil {
+		err := c.Connect(ctx)
+		c.observeReconnect(err == nil)
+		if err != nil {
 			cancel()
+			lastErr = err
 
+			if c.baseCtx.Err() != nil {
+				c.logger.Info("client context is done while trying to reconnect to the gateway, aborting")
+				return
+			}
 			if !shouldReconnect(err) {
-				c.logger.Error("invalid Gateway session, can not recover: %v", err)
+				c.giveUpReconnecting(err)
 				return
 			}
 
@@ -257,12 +375,15 @@ func (c *Client) reconnectWithBackoff() {
 			c.logger.Errorf("failed to reconnect: %v, retrying in %s", err, duration)
 
 			select {
-			case <-time.After(duration):
+			case <-c.clock.After(duration):
 				continue // Make a new connection attempt.
 			case <-c.stop:
 				// Client called Disconnect(), stop trying to reconnect.
 				c.logger.Info("client called Disconnect while trying to reconnect to the gateway, aborting")
 				return
+			case <-c.baseCtx.Done():
+				c.logger.Info("client context is done while trying to reconnect to the gateway, aborting")
+				return
 			}
 		} else {
 			cancel()
@@ -274,6 +395,48 @@ func (c *Client) reconnectWithBackoff() {
 	}
 }
 
+// giveUpReconnecting reports the given error, which made the client give up
+// reconnecting to the Gateway, and cleans up the client as Disconnect does
+// before calling the OnGiveUp function of the reconnect policy, if any.
+func (c *Client) giveUpReconnecting(err error) {
+	c.logger.Errorf("stopped trying to reconnect to the gateway: %v", err)
+	c.reportError(err, &ErrorEvent{Source: ErrorSourceGateway})
+
+	// This is called by the connection manager, which Disconnect waits
+	// for, clean up from another goroutine so it can return meanwhile.
+	go func() {
+		c.cancelHandlersContext()
+		c.stopPlugins()
+		c.closeEventStreams()
+
+		c.mu.Lock()
+		c.closeVoiceConnections()
+		c.mu.Unlock()
+
+		if c.onReconnectGiveUp != nil {
+			c.onReconnectGiveUp(err)
+		}
+	}()
+}
+
+// closeVoiceConnections closes and removes all voice connections without
+// notifying the Gateway, for when the connection to it is lost. c.mu must
+// be held.
+func (c *Client) closeVoiceConnections() {
+	c.voiceConnectionsMu.RLock()
+	guildIDs := make([]string, 0, len(c.voiceConnections))
+	for guildID := range c.voiceConnections {
+		guildIDs = append(guildIDs, guildID)
+	}
+	c.voiceConnectionsMu.RUnlock()
+
+	for _, guildID := range guildIDs {
+		if conn := c.removeVoiceConnection(guildID); conn != nil {
+			conn.Close()
+		}
+	}
+}
+
 // reportErr reports the first fatal error encountered while connected to
 // the Gateway. Calls after the first one are no-ops.
 func (c *Client) reportErr(err error) {
@@ -295,6 +458,7 @@ func (c *Client) reportErr(err error) {
 // closing the stop channel.
 func (c *Client) onGatewayError(err error) {
 	c.logger.Errorf("gateway connection error: %v", err)
+	c.reportError(err, &ErrorEvent{Source: ErrorSourceGateway})
 
 	if closeErr := c.conn.Close(websocket.StatusInternalError, "gateway error"); closeErr != nil {
 		c.logger.Errorf("could not properly close websocket connection (error): %v", closeErr)
@@ -322,6 +486,41 @@ func (c *Client) onDisconnect() {
 	c.resetGatewaySession()
 }
 
+// Connected reports whether the client is currently connected to the Gateway
+// and has received its Ready (or Resumed) event.
+func (c *Client) Connected() bool {
+	return c.isConnected()
+}
+
+// Reconnecting reports whether the client is currently trying to reconnect to
+// the Gateway after an error.
+func (c *Client) Reconnecting() bool {
+	return c.isReconnecting()
+}
+
+// Shard returns the shard this client is connected as, and the total number of
+// shards. Both are 0 if sharding is not enabled. See WithSharding.
+func (c *Client) Shard() (id, total int) {
+	return c.shard[0], c.shard[1]
+}
+
+// LastHeartbeatACK returns the time at which the last heartbeat acknowledgement
+// was received from the Gateway, or the zero time if none was received yet.
+func (c *Client) LastHeartbeatACK() time.Time {
+	ack := c.lastHeartbeatACK.Load()
+	if ack == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ack)
+}
+
+// Latency returns the time between the last heartbeat sent to the Gateway and
+// its acknowledgement, or 0 if no heartbeat was acknowledged yet. Unlike
+// State.RTT, it is available without state tracking.
+func (c *Client) Latency() time.Duration {
+	return time.Duration(c.latency.Load())
+}
+
 // isConnected reports whether the client is currently connected to the Gateway.
 func (c *Client) isConnected() bool {
 	return c.connected.Load()
@@ -20,7 +20,56 @@ const (
 	GatewayIntentDirectMessages         GatewayIntent = 1 << 12
 	GatewayIntentDirectMessageReactions GatewayIntent = 1 << 13
 	GatewayIntentDirectMessageTyping    GatewayIntent = 1 << 14
+	// GatewayIntentMessageContent is required to receive the content, embeds,
+	// attachments and components of most messages.
+	GatewayIntentMessageContent              GatewayIntent = 1 << 15
+	GatewayIntentGuildScheduledEvents        GatewayIntent = 1 << 16
+	GatewayIntentAutoModerationConfiguration GatewayIntent = 1 << 20
+	GatewayIntentAutoModerationExecution     GatewayIntent = 1 << 21
+	GatewayIntentGuildMessagePolls           GatewayIntent = 1 << 24
+	GatewayIntentDirectMessagePolls          GatewayIntent = 1 << 25
 )
 
-// Equivalent to all intents except privileged (GatewayIntentGuildMembers and GatewayIntentGuildPresences), OR'd.
-const GatewayIntentUnprivileged = GatewayIntentGuild | GatewayIntentGuildBans | GatewayIntentGuildEmojis | GatewayIntentGuildIntegrations | GatewayIntentGuildWebhooks | GatewayIntentGuildInvites | GatewayIntentGuildVoiceStates | GatewayIntentGuildMessages | GatewayIntentGuildMessageReactions | GatewayIntentGuildMessageTyping | GatewayIntentDirectMessages | GatewayIntentDirectMessageReactions | GatewayIntentDirectMessageTyping
+// Equivalent to all intents except privileged (GatewayIntentGuildMembers, GatewayIntentGuildPresences and GatewayIntentMessageContent), OR'd.
+const GatewayIntentUnprivileged = GatewayIntentGuild | GatewayIntentGuildBans | GatewayIntentGuildEmojis | GatewayIntentGuildIntegrations | GatewayIntentGuildWebhooks | GatewayIntentGuildInvites | GatewayIntentGuildVoiceStates | GatewayIntentGuildMessages | GatewayIntentGuildMessageReactions | GatewayIntentGuildMessageTyping | GatewayIntentDirectMessages | GatewayIntentDirectMessageReactions | GatewayIntentDirectMessageTyping | GatewayIntentGuildScheduledEvents | GatewayIntentAutoModerationConfiguration | GatewayIntentAutoModerationExecution | GatewayIntentGuildMessagePolls | GatewayIntentDirectMessagePolls
+
+// privilegedIntents lists privileged intents along with the application flags
+// that allow to use them. An application can use a privileged intent if it
+// has either one of those flags.
+var privilegedIntents = []struct {
+	intent GatewayIntent
+	name   string
+	flags  [2]ApplicationFlag
+}{
+	{
+		intent: GatewayIntentGuildMembers,
+		name:   "GUILD_MEMBERS",
+		flags:  [2]ApplicationFlag{ApplicationFlagGatewayGuildMembers, ApplicationFlagGatewayGuildMembersLimited},
+	},
+	{
+		intent: GatewayIntentGuildPresences,
+		name:   "GUILD_PRESENCES",
+		flags:  [2]ApplicationFlag{ApplicationFlagGatewayPresence, ApplicationFlagGatewayPresenceLimited},
+	},
+	{
+		intent: GatewayIntentMessageContent,
+		name:   "MESSAGE_CONTENT",
+		flags:  [2]ApplicationFlag{ApplicationFlagGatewayMessageContent, ApplicationFlagGatewayMessageContentLimited},
+	},
+}
+
+// missingPrivilegedIntents returns the privileged intents among the requested
+// ones that the application with the given flags is not allowed to use.
+func missingPrivilegedIntents(requested GatewayIntent, flags ApplicationFlag) GatewayIntent {
+	var missing GatewayIntent
+	for _, p := range privilegedIntents {
+		if requested&p.intent == 0 {
+			continue
+		}
+
+		if !flags.Has(p.flags[0]) && !flags.Has(p.flags[1]) {
+			missing |= p.intent
+		}
+	}
+	return missing
+}
@@ -3,6 +3,7 @@ package harmony
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -23,7 +24,7 @@ type Guild struct {
 	Splash                      *string                        `json:"splash,omitempty"`
 	Owner                       bool                           `json:"owner,omitempty"`
 	OwnerID                     string                         `json:"owner_id,omitempty"`
-	Permissions                 int                            `json:"permissions,omitempty"`
+	Permissions                 uint64                         `json:"permissions,omitempty,string"`
 	Region                      string                         `json:"region,omitempty"`
 	AFKChannelID                *string                        `json:"afk_channel_id,omitempty"`
 	AFKTimeout                  int                            `json:"afk_timeout,omitempty"`
@@ -34,12 +35,26 @@ type Guild struct {
 	ExplicitContentFilter       guild.ExplicitContentFilter    `json:"explicit_content_filter,omitempty"`
 	Roles                       []Role                         `json:"roles,omitempty"`
 	Emojis                      []Emoji                        `json:"emojis,omitempty"`
+	Stickers                    []Sticker                      `json:"stickers,omitempty"`
 	Features                    []string                       `json:"features,omitempty"`
 	MFALevel                    int                            `json:"mfa_level,omitempty"`
 	ApplicationID               *string                        `json:"application_id,omitempty"`
 	WidgetEnabled               bool                           `json:"widget_enabled,omitempty"`
 	WidgetChannelID             string                         `json:"widget_channel_id,omitempty"`
 	SystemChannelID             *string                        `json:"system_channel_id,omitempty"`
+	NSFWLevel                   guild.NSFWLevel                `json:"nsfw_level,omitempty"`
+	PreferredLocale             string                         `json:"preferred_locale,omitempty"`
+	MaxVideoChannelUsers        int                            `json:"max_video_channel_users,omitempty"`
+	SafetyAlertsChannelID       *string                        `json:"safety_alerts_channel_id,omitempty"`
+	// HubType is only set for Student Hub guilds.
+	HubType *guild.HubType `json:"hub_type,omitempty"`
+	// Incident actions and raid or spam alerts of the guild, if any.
+	IncidentsData *GuildIncidents `json:"incidents_data,omitempty"`
+
+	// Following fields are only sent when fetching
+	// a guild with GuildResource.GetWithCounts.
+	ApproximateMemberCount   int `json:"approximate_member_count,omitempty"`
+	ApproximatePresenceCount int `json:"approximate_presence_count,omitempty"`
 
 	// Following fields are only sent within the GUILD_CREATE event.
 	JoinedAt    time.Time     `json:"joined_at,omitempty"`
@@ -50,16 +65,24 @@ type Guild struct {
 	Members     []GuildMember `json:"members,omitempty"`
 	Channels    []Channel     `json:"channels,omitempty"`
 	Presences   []Presence    `json:"presences,omitempty"`
+	// Active threads of the guild the current user can access.
+	Threads []Channel `json:"threads,omitempty"`
+
+	// Fields received from the Gateway that are not known by
+	// Harmony, only set if WithRawExtra is enabled.
+	RawExtra RawExtra `json:"-"`
 }
 
 // Presence is a user's current state on a guild.
 // This event is sent when a user's presence is updated for a guild.
 type Presence struct {
-	User    *User     `json:"user,omitempty"`
-	Roles   []string  `json:"roles,omitempty"` // Array of IDs.
-	Game    *Activity `json:"game,omitempty"`
-	GuildID string    `json:"guild_id,omitempty"`
-	Status  string    `json:"status,omitempty"` // Either "idle", "dnd", "online", or "offline".
+	User  *User     `json:"user,omitempty"`
+	Roles []string  `json:"roles,omitempty"` // Array of IDs.
+	Game  *Activity `json:"game,omitempty"`
+	// Activities of the user, the first one being shown by clients.
+	Activities []Activity `json:"activities,omitempty"`
+	GuildID    string     `json:"guild_id,omitempty"`
+	Status     string     `json:"status,omitempty"` // Either "idle", "dnd", "online", or "offline".
 }
 
 // PartialGuild is a subset of the Guild object, returned by the Discord API
@@ -69,7 +92,7 @@ type PartialGuild struct {
 	Name        string `json:"name"`
 	Icon        string `json:"icon"`
 	Owner       bool   `json:"owner"`
-	Permissions int    `json:"permissions"`
+	Permissions uint64 `json:"permissions,string"`
 }
 
 // UnavailableGuild is a Guild that is not available, either because there is a
@@ -81,13 +104,18 @@ type UnavailableGuild struct {
 
 // CreateGuild creates a new guild with the given name.
 // Returns the created guild on success. Fires a Guild Create Gateway event.
+// Use NewGuild to set up roles and channels along with the guild.
 func (c *Client) CreateGuild(ctx context.Context, name string) (*Guild, error) {
-	s := struct {
-		Name string `json:"name"`
-	}{
-		Name: name,
-	}
-	b, err := json.Marshal(s)
+	return c.NewGuild(ctx, guild.NewCreateSettings(name))
+}
+
+// NewGuild creates a new guild with the given settings, including its initial
+// roles and channels. This endpoint can be used only by bots in less than 10
+// guilds. The bot is the owner of the created guild and can delete it with
+// GuildResource.Delete.
+// Returns the created guild on success. Fires a Guild Create Gateway event.
+func (c *Client) NewGuild(ctx context.Context, settings *guild.CreateSettings) (*Guild, error) {
+	b, err := json.Marshal(settings)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +152,21 @@ func (c *Client) Guild(id string) *GuildResource {
 
 // Get returns the guild.
 func (r *GuildResource) Get(ctx context.Context) (*Guild, error) {
-	e := endpoint.GetGuild(r.guildID)
+	return r.get(ctx, false)
+}
+
+// GetWithCounts is like Get but also sets the ApproximateMemberCount and
+// ApproximatePresenceCount fields of the returned guild.
+func (r *GuildResource) GetWithCounts(ctx context.Context) (*Guild, error) {
+	return r.get(ctx, true)
+}
+
+func (r *GuildResource) get(ctx context.Context, withCounts bool) (*Guild, error) {
+	q := url.Values{}
+	if withCounts {
+		q.Set("with_counts", "true")
+	}
+	e := endpoint.GetGuild(r.guildID, q.Encode())
 	resp, err := r.client.doReq(ctx, e, nil)
 	if err != nil {
 		return nil, err
@@ -216,9 +258,17 @@ func (r *GuildResource) NewChannel(ctx context.Context, settings *channel.Settin
 }
 
 // NewChannelWithReason creates a new channel in the guild. Requires the MANAGE_CHANNELS permission.
-// Fires a Channel Create Gateway event.
+// The settings must at least set the name of the channel and are validated before being sent,
+// see channel.Settings.Validate. Fires a Channel Create Gateway event.
 // The given reason will be set in the audit log entry for this action.
 func (r *GuildResource) NewChannelWithReason(ctx context.Context, settings *channel.Settings, reason string) (*Channel, error) {
+	if settings.Name == nil {
+		return nil, errors.New("invalid channel settings: name is required")
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
 	b, err := json.Marshal(settings)
 	if err != nil {
 		return nil, err
@@ -489,12 +539,6 @@ func (r *GuildResource) Webhooks(ctx context.Context) ([]Webhook, error) {
 	return r.client.webhooks(ctx, e)
 }
 
-type requestGuildMembers struct {
-	GuildID string `json:"guild_id"`
-	Query   string `json:"query"`
-	Limit   int    `json:"limit"`
-}
-
 // RequestGuildMembers is used to request offline members for the guild. When initially
 // connecting, the gateway will only send offline members if a guild has less than
 // the large_threshold members (value in the Gateway Identify). If a client wishes
@@ -504,7 +548,8 @@ type requestGuildMembers struct {
 // query is a string that username starts with, or an empty string to return all members.
 // limit is the maximum number of members to send or 0 to request all members matched.
 // You need to be connected to the Gateway to call this method, else it will
-// return ErrGatewayNotConnected.
+// return ErrGatewayNotConnected. See Client.RequestGuildMembers to receive the
+// members of a specific request.
 func (r *GuildResource) RequestGuildMembers(query string, limit int) error {
 	if !r.client.isConnected() {
 		return ErrGatewayNotConnected
@@ -512,7 +557,7 @@ func (r *GuildResource) RequestGuildMembers(query string, limit int) error {
 
 	return r.client.sendPayload(r.client.ctx, gatewayOpcodeRequestGuildMembers, &requestGuildMembers{
 		GuildID: r.guildID,
-		Query:   query,
+		Query:   &query,
 		Limit:   limit,
 	})
 }
@@ -0,0 +1,142 @@
+package guild
+
+import (
+	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/optional"
+	"github.com/skwair/harmony/permission"
+	"github.com/skwair/harmony/role"
+)
+
+// CreateSettings describes a guild to create. Only bots in less than
+// 10 guilds can create guilds.
+//
+// Roles and channels can be created along with the guild. They are identified
+// by placeholder IDs (any integer) that can be referenced by other parts of
+// the settings, such as the parent of a channel, a permission overwrite or the
+// AFK channel. Those placeholders are replaced by real IDs by Discord.
+type CreateSettings struct {
+	Name                        string        `json:"name"`
+	Icon                        string        `json:"icon,omitempty"`
+	VerificationLevel           *optional.Int `json:"verification_level,omitempty"`
+	DefaultMessageNotifications *optional.Int `json:"default_message_notifications,omitempty"`
+	ExplicitContentFilter       *optional.Int `json:"explicit_content_filter,omitempty"`
+	// Roles to create along with the guild. The first role of the
+	// list is the @everyone role.
+	Roles []CreateRole `json:"roles,omitempty"`
+	// Channels to create along with the guild. If set,
+	// default channels are not created.
+	Channels []CreateChannel `json:"channels,omitempty"`
+	// Placeholder IDs of the AFK and system channels. They are pointers
+	// so that a placeholder ID of 0 is sent as well.
+	AFKChannelID    *optional.Int `json:"afk_channel_id,omitempty"`
+	AFKTimeout      *optional.Int `json:"afk_timeout,omitempty"`
+	SystemChannelID *optional.Int `json:"system_channel_id,omitempty"`
+}
+
+// CreateRole is a role created along with a guild.
+type CreateRole struct {
+	// ID is a placeholder ID for this role.
+	ID int `json:"id"`
+	*role.Settings
+}
+
+// CreateChannel is a channel created along with a guild.
+type CreateChannel struct {
+	// ID is a placeholder ID for this channel. It is only
+	// required if the channel is referenced elsewhere.
+	ID *optional.Int `json:"id,omitempty"`
+	// ParentID is the placeholder ID of the category of this channel.
+	// Like ID, it is a pointer so that a placeholder ID of 0 is sent as well.
+	ParentID         *optional.Int `json:"parent_id,omitempty"`
+	Name             string        `json:"name"`
+	Type             channel.Type  `json:"type"`
+	Topic            string        `json:"topic,omitempty"`
+	Bitrate          int           `json:"bitrate,omitempty"`
+	UserLimit        int           `json:"user_limit,omitempty"`
+	RateLimitPerUser int           `json:"rate_limit_per_user,omitempty"`
+	NSFW             bool          `json:"nsfw,omitempty"`
+	// Permission overwrites of role type must
+	// reference the placeholder ID of a role.
+	Permissions []permission.Overwrite `json:"permission_overwrites,omitempty"`
+}
+
+// CreateSetting is a function that configures a guild to create.
+type CreateSetting func(*CreateSettings)
+
+// NewCreateSettings returns new CreateSettings to create a guild with the given name.
+func NewCreateSettings(name string, opts ...CreateSetting) *CreateSettings {
+	s := &CreateSettings{Name: name}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// CreateWithIcon sets the icon of the guild to create, which is
+// a base64 encoded 128x128 image.
+func CreateWithIcon(icon string) CreateSetting {
+	return func(s *CreateSettings) {
+		s.Icon = icon
+	}
+}
+
+// CreateWithVerificationLevel sets the verification level of the guild to create.
+func CreateWithVerificationLevel(lvl VerificationLevel) CreateSetting {
+	return func(s *CreateSettings) {
+		var settings Settings
+		WithVerificationLevel(lvl)(&settings)
+		s.VerificationLevel = settings.VerificationLevel
+	}
+}
+
+// CreateWithDefaultMessageNotifications sets the default notification level of the guild to create.
+func CreateWithDefaultMessageNotifications(lvl DefaultNotificationLevel) CreateSetting {
+	return func(s *CreateSettings) {
+		var settings Settings
+		WithDefaultMessageNotifications(lvl)(&settings)
+		s.DefaultMessageNotifications = settings.DefaultMessageNotifications
+	}
+}
+
+// CreateWithExplicitContentFilter sets the explicit content filter of the guild to create.
+func CreateWithExplicitContentFilter(lvl ExplicitContentFilter) CreateSetting {
+	return func(s *CreateSettings) {
+		var settings Settings
+		WithExplicitContentFilter(lvl)(&settings)
+		s.ExplicitContentFilter = settings.ExplicitContentFilter
+	}
+}
+
+// CreateWithRole adds a role to create along with the guild, identified by the
+// given placeholder ID. The first role added is the @everyone role.
+func CreateWithRole(id int, settings *role.Settings) CreateSetting {
+	return func(s *CreateSettings) {
+		s.Roles = append(s.Roles, CreateRole{ID: id, Settings: settings})
+	}
+}
+
+// CreateWithChannel adds a channel to create along with the guild.
+func CreateWithChannel(ch CreateChannel) CreateSetting {
+	return func(s *CreateSettings) {
+		s.Channels = append(s.Channels, ch)
+	}
+}
+
+// CreateWithAFKChannel sets the placeholder ID of the AFK
+// channel of the guild to create, along with its timeout.
+func CreateWithAFKChannel(id int, t AFKTimeout) CreateSetting {
+	return func(s *CreateSettings) {
+		s.AFKChannelID = optional.NewInt(id)
+		s.AFKTimeout = optional.NewInt(int(t))
+	}
+}
+
+// CreateWithSystemChannel sets the placeholder ID of the channel
+// to which system messages of the guild to create are sent.
+func CreateWithSystemChannel(id int) CreateSetting {
+	return func(s *CreateSettings) {
+		s.SystemChannelID = optional.NewInt(id)
+	}
+}
@@ -0,0 +1,57 @@
+package guild
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/optional"
+)
+
+func TestCreateSettingsPlaceholderIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings *CreateSettings
+		expected string
+	}{
+		{
+			name:     "no channels",
+			settings: NewCreateSettings("guild"),
+			expected: `{"name":"guild"}`,
+		},
+		{
+			name:     "zero placeholder IDs",
+			settings: NewCreateSettings("guild", CreateWithAFKChannel(0, AFKTimeoutOneMinute), CreateWithSystemChannel(0)),
+			expected: `{"name":"guild","afk_channel_id":0,"afk_timeout":60,"system_channel_id":0}`,
+		},
+		{
+			name:     "placeholder IDs",
+			settings: NewCreateSettings("guild", CreateWithAFKChannel(1, AFKTimeoutOneMinute), CreateWithSystemChannel(2)),
+			expected: `{"name":"guild","afk_channel_id":1,"afk_timeout":60,"system_channel_id":2}`,
+		},
+		{
+			name: "channels",
+			settings: NewCreateSettings("guild",
+				CreateWithChannel(CreateChannel{Name: "text"}),
+				CreateWithChannel(CreateChannel{ID: optional.NewInt(0), Name: "category", Type: channel.TypeGuildCategory}),
+				CreateWithChannel(CreateChannel{ID: optional.NewInt(1), ParentID: optional.NewInt(0), Name: "voice", Type: channel.TypeGuildVoice}),
+			),
+			expected: `{"name":"guild","channels":[` +
+				`{"name":"text","type":0},` +
+				`{"id":0,"name":"category","type":4},` +
+				`{"id":1,"parent_id":0,"name":"voice","type":2}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.settings)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, b)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package guild
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/skwair/harmony/optional"
+)
+
+// Constraints enforced by Discord on the discovery metadata of a guild.
+const (
+	MaxDiscoveryKeywords      = 10
+	MaxDiscoveryKeywordLength = 30
+	MaxDiscoverySubcategories = 5
+)
+
+// DiscoverySettings are the discovery settings of a guild, all fields are
+// optional and only those explicitly set will be modified.
+type DiscoverySettings struct {
+	PrimaryCategoryID           *optional.Int         `json:"primary_category_id,omitempty"`
+	Keywords                    *optional.StringSlice `json:"keywords,omitempty"`
+	EmojiDiscoverabilityEnabled *optional.Bool        `json:"emoji_discoverability_enabled,omitempty"`
+
+	// Shadow of Keywords, since optional values can not be read back.
+	keywords []string
+}
+
+// DiscoverySetting is a function that configures the discovery of a guild.
+type DiscoverySetting func(*DiscoverySettings)
+
+// NewDiscoverySettings returns new DiscoverySettings to modify the discovery of a guild.
+func NewDiscoverySettings(opts ...DiscoverySetting) *DiscoverySettings {
+	s := &DiscoverySettings{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithPrimaryCategory sets the primary discovery category of a guild.
+// See Client.DiscoveryCategories for the list of categories.
+func WithPrimaryCategory(id int) DiscoverySetting {
+	return func(s *DiscoverySettings) {
+		s.PrimaryCategoryID = optional.NewInt(id)
+	}
+}
+
+// WithKeywords sets the discovery search keywords of a guild.
+// Nil or empty keywords will remove all keywords of the guild.
+func WithKeywords(keywords []string) DiscoverySetting {
+	return func(s *DiscoverySettings) {
+		if len(keywords) == 0 {
+			s.Keywords = optional.NewNilStringSlice()
+		} else {
+			s.Keywords = optional.NewStringSlice(keywords)
+		}
+		s.keywords = keywords
+	}
+}
+
+// WithEmojiDiscoverability sets whether guild info is shown when
+// custom emojis of a guild are clicked.
+func WithEmojiDiscoverability(yes bool) DiscoverySetting {
+	return func(s *DiscoverySettings) {
+		s.EmojiDiscoverabilityEnabled = optional.NewBool(yes)
+	}
+}
+
+// Validate checks the settings against the constraints enforced by Discord
+// and returns an error listing all the problems found, if any.
+func (s *DiscoverySettings) Validate() error {
+	var problems []string
+
+	if len(s.keywords) > MaxDiscoveryKeywords {
+		problems = append(problems, fmt.Sprintf("at most %d keywords are allowed, got %d", MaxDiscoveryKeywords, len(s.keywords)))
+	}
+	for i, k := range s.keywords {
+		if k == "" {
+			problems = append(problems, fmt.Sprintf("keyword %d is empty", i))
+		}
+		if utf8.RuneCountInString(k) > MaxDiscoveryKeywordLength {
+			problems = append(problems, fmt.Sprintf("keyword %d must be at most %d characters long", i, MaxDiscoveryKeywordLength))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid discovery settings: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -1,6 +1,13 @@
 package guild
 
-import "github.com/skwair/harmony/optional"
+import (
+	"time"
+
+	"github.com/skwair/harmony/optional"
+)
+
+// MaxTimeout is the longest duration a guild member can be timed out for.
+const MaxTimeout = 28 * 24 * time.Hour
 
 // MemberSettings are the settings of a guild member, all fields are optional
 // and only those explicitly set will be modified.
@@ -11,6 +18,30 @@ type MemberSettings struct {
 	Deaf  *optional.Bool        `json:"deaf,omitempty"`
 	// ID of channel to move user to (if they are connected to voice).
 	ChannelID *optional.String `json:"channel_id,omitempty"`
+	Flags     *optional.Int    `json:"flags,omitempty"`
+	// Time until which the member is timed out, as an ISO8601 timestamp.
+	CommunicationDisabledUntil *optional.String `json:"communication_disabled_until,omitempty"`
+}
+
+// MemberFlag are flags of a guild member.
+type MemberFlag int
+
+// Flags of guild members. Only MemberFlagBypassesVerification can be modified.
+const (
+	// MemberFlagDidRejoin is set when the member has left and rejoined the guild.
+	MemberFlagDidRejoin MemberFlag = 1 << 0
+	// MemberFlagCompletedOnboarding is set when the member has completed onboarding.
+	MemberFlagCompletedOnboarding MemberFlag = 1 << 1
+	// MemberFlagBypassesVerification allows the member to bypass
+	// guild verification requirements.
+	MemberFlagBypassesVerification MemberFlag = 1 << 2
+	// MemberFlagStartedOnboarding is set when the member has started onboarding.
+	MemberFlagStartedOnboarding MemberFlag = 1 << 3
+)
+
+// Has returns whether these flags contain the given flag.
+func (f MemberFlag) Has(flag MemberFlag) bool {
+	return f&flag == flag
 }
 
 // MemberSetting is a function that configures a guild member.
@@ -28,6 +59,7 @@ func NewMemberSettings(opts ...MemberSetting) *MemberSettings {
 }
 
 // WithNick sets the name of a guild member.
+// An empty name will reset the nickname of the member.
 func WithNick(name string) MemberSetting {
 	return func(s *MemberSettings) {
 		s.Nick = optional.NewString(name)
@@ -56,8 +88,34 @@ func WithDeaf(yes bool) MemberSetting {
 }
 
 // WithChannelID sets the channel id of a guild member (if connected to voice).
+// An empty id will disconnect the member from voice.
 func WithChannelID(id string) MemberSetting {
 	return func(s *MemberSettings) {
-		s.ChannelID = optional.NewString(id)
+		if id == "" {
+			s.ChannelID = optional.NewNilString()
+		} else {
+			s.ChannelID = optional.NewString(id)
+		}
+	}
+}
+
+// WithFlags sets the flags of a guild member.
+func WithFlags(flags MemberFlag) MemberSetting {
+	return func(s *MemberSettings) {
+		s.Flags = optional.NewInt(int(flags))
+	}
+}
+
+// WithTimeoutUntil times out a guild member until the given time, at most
+// MaxTimeout in the future. Timed out members can not send messages, react
+// or join voice channels. A zero time removes the timeout of the member.
+// Requires the 'MODERATE_MEMBERS' permission.
+func WithTimeoutUntil(t time.Time) MemberSetting {
+	return func(s *MemberSettings) {
+		if t.IsZero() {
+			s.CommunicationDisabledUntil = optional.NewNilString()
+		} else {
+			s.CommunicationDisabledUntil = optional.NewString(t.UTC().Format(time.RFC3339))
+		}
 	}
 }
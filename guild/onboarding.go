@@ -0,0 +1,223 @@
+package guild
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/skwair/harmony/optional"
+)
+
+// Constraints enforced by Discord on the onboarding of a guild.
+const (
+	MaxOnboardingPrompts          = 15
+	MaxPromptOptions              = 50
+	MaxPromptTitleLength          = 100
+	MaxPromptOptionTitleLength    = 50
+	MaxPromptOptionDescLength     = 100
+	MinOnboardingDefaultChannels  = 7
+	MinOnboardingSendableChannels = 5
+)
+
+// OnboardingMode defines the criteria used to satisfy the onboarding constraints
+// that are required for enabling it.
+type OnboardingMode int
+
+const (
+	// OnboardingModeDefault only counts default
+	// channels towards constraints.
+	OnboardingModeDefault OnboardingMode = iota
+	// OnboardingModeAdvanced counts default channels and
+	// channels from prompts towards constraints.
+	OnboardingModeAdvanced
+)
+
+// PromptType is the type of an onboarding prompt.
+type PromptType int
+
+const (
+	PromptTypeMultipleChoice PromptType = iota
+	PromptTypeDropdown
+)
+
+// Prompt is a question asked to new members during onboarding.
+type Prompt struct {
+	// ID of the prompt. Leave empty when creating a new prompt.
+	ID           string         `json:"id,omitempty"`
+	Type         PromptType     `json:"type"`
+	Title        string         `json:"title"`
+	Options      []PromptOption `json:"options"`
+	SingleSelect bool           `json:"single_select"`
+	Required     bool           `json:"required"`
+	// Whether the prompt is shown during onboarding, or only in
+	// the Channels & Roles tab.
+	InOnboarding bool `json:"in_onboarding"`
+}
+
+// PromptOption is an option available within an onboarding prompt.
+// Selecting it grants the given roles and channels to the member.
+type PromptOption struct {
+	// ID of the option. Leave empty when creating a new option.
+	ID          string   `json:"id,omitempty"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	ChannelIDs  []string `json:"channel_ids"`
+	RoleIDs     []string `json:"role_ids"`
+	// Set either the ID of a custom emoji or the
+	// name (unicode character) of a standard emoji.
+	EmojiID       string `json:"emoji_id,omitempty"`
+	EmojiName     string `json:"emoji_name,omitempty"`
+	EmojiAnimated bool   `json:"emoji_animated,omitempty"`
+}
+
+// OnboardingSettings describes how to modify the onboarding of a guild.
+// All fields are optional and only those explicitly set will be modified.
+type OnboardingSettings struct {
+	Prompts           *[]Prompt             `json:"prompts,omitempty"`
+	DefaultChannelIDs *optional.StringSlice `json:"default_channel_ids,omitempty"`
+	Enabled           *optional.Bool        `json:"enabled,omitempty"`
+	Mode              *optional.Int         `json:"mode,omitempty"`
+}
+
+// OnboardingSetting is a function that configures the onboarding of a guild.
+type OnboardingSetting func(*OnboardingSettings)
+
+// NewOnboardingSettings returns new OnboardingSettings to modify the onboarding
+// of a guild.
+func NewOnboardingSettings(opts ...OnboardingSetting) *OnboardingSettings {
+	s := &OnboardingSettings{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithOnboardingEnabled sets whether onboarding is enabled in the guild.
+func WithOnboardingEnabled(yes bool) OnboardingSetting {
+	return func(s *OnboardingSettings) {
+		s.Enabled = optional.NewBool(yes)
+	}
+}
+
+// WithOnboardingMode sets the onboarding mode of the guild.
+func WithOnboardingMode(mode OnboardingMode) OnboardingSetting {
+	return func(s *OnboardingSettings) {
+		s.Mode = optional.NewInt(int(mode))
+	}
+}
+
+// WithDefaultChannels sets the channels members get opted into automatically.
+func WithDefaultChannels(ids ...string) OnboardingSetting {
+	return func(s *OnboardingSettings) {
+		if ids == nil {
+			ids = []string{}
+		}
+		s.DefaultChannelIDs = optional.NewStringSlice(ids)
+	}
+}
+
+// WithPrompts sets the prompts shown during onboarding and in customize community.
+// Existing prompts that are not given will be removed.
+func WithPrompts(prompts ...Prompt) OnboardingSetting {
+	return func(s *OnboardingSettings) {
+		if prompts == nil {
+			prompts = []Prompt{}
+		}
+		s.Prompts = &prompts
+	}
+}
+
+// Validate checks these settings against the constraints enforced by Discord
+// and returns an error describing every violated constraint, if any.
+// Note that it can not check that at least MinOnboardingSendableChannels
+// default channels allow @everyone to send messages, since this depends on
+// permissions of channels.
+func (s *OnboardingSettings) Validate() error {
+	var problems []string
+
+	if s.Prompts != nil {
+		prompts := *s.Prompts
+		if len(prompts) > MaxOnboardingPrompts {
+			problems = append(problems, fmt.Sprintf("at most %d prompts are allowed, got %d", MaxOnboardingPrompts, len(prompts)))
+		}
+
+		for i, p := range prompts {
+			problems = append(problems, p.validate(i)...)
+		}
+	}
+
+	// Constraints on default channels only apply when enabling onboarding.
+	enabled, _ := s.Enabled.Value()
+	if enabled && s.DefaultChannelIDs != nil {
+		defaultChannelIDs, _ := s.DefaultChannelIDs.Value()
+		channels := make(map[string]struct{})
+		for _, id := range defaultChannelIDs {
+			channels[id] = struct{}{}
+		}
+
+		mode, _ := s.Mode.Value()
+		if OnboardingMode(mode) == OnboardingModeAdvanced && s.Prompts != nil {
+			for _, p := range *s.Prompts {
+				if !p.InOnboarding {
+					continue
+				}
+				for _, o := range p.Options {
+					for _, id := range o.ChannelIDs {
+						channels[id] = struct{}{}
+					}
+				}
+			}
+		}
+
+		if len(channels) < MinOnboardingDefaultChannels {
+			problems = append(problems, fmt.Sprintf(
+				"at least %d default channels are required to enable onboarding, got %d",
+				MinOnboardingDefaultChannels, len(channels),
+			))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid onboarding settings: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (p *Prompt) validate(i int) []string {
+	var problems []string
+
+	if l := utf8.RuneCountInString(p.Title); l == 0 || l > MaxPromptTitleLength {
+		problems = append(problems, fmt.Sprintf("prompt %d: title must be between 1 and %d characters", i, MaxPromptTitleLength))
+	}
+
+	if len(p.Options) == 0 || len(p.Options) > MaxPromptOptions {
+		problems = append(problems, fmt.Sprintf("prompt %d: must have between 1 and %d options, got %d", i, MaxPromptOptions, len(p.Options)))
+	}
+
+	if p.Type != PromptTypeMultipleChoice && p.Type != PromptTypeDropdown {
+		problems = append(problems, fmt.Sprintf("prompt %d: unknown type %d", i, p.Type))
+	}
+
+	for j, o := range p.Options {
+		if l := utf8.RuneCountInString(o.Title); l == 0 || l > MaxPromptOptionTitleLength {
+			problems = append(problems, fmt.Sprintf("prompt %d, option %d: title must be between 1 and %d characters", i, j, MaxPromptOptionTitleLength))
+		}
+
+		if utf8.RuneCountInString(o.Description) > MaxPromptOptionDescLength {
+			problems = append(problems, fmt.Sprintf("prompt %d, option %d: description must be at most %d characters", i, j, MaxPromptOptionDescLength))
+		}
+
+		if len(o.ChannelIDs) == 0 && len(o.RoleIDs) == 0 {
+			problems = append(problems, fmt.Sprintf("prompt %d, option %d: must grant at least one channel or role", i, j))
+		}
+
+		if o.EmojiID != "" && o.EmojiName != "" {
+			problems = append(problems, fmt.Sprintf("prompt %d, option %d: only one of emoji ID or emoji name can be set", i, j))
+		}
+	}
+
+	return problems
+}
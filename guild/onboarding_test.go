@@ -0,0 +1,82 @@
+package guild
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/skwair/harmony/optional"
+)
+
+func TestOnboardingSettingsValidate(t *testing.T) {
+	ids := func(n int) []string {
+		ids := make([]string, n)
+		for i := range ids {
+			ids[i] = strconv.Itoa(i + 1)
+		}
+		return ids
+	}
+	prompt := Prompt{
+		Title:        "Pick a game",
+		InOnboarding: true,
+		Options:      []PromptOption{{Title: "Chess", ChannelIDs: []string{"100", "101"}}},
+	}
+
+	tests := []struct {
+		name     string
+		settings *OnboardingSettings
+		valid    bool
+	}{
+		{name: "empty", settings: NewOnboardingSettings(), valid: true},
+		{
+			name:     "enabled",
+			settings: NewOnboardingSettings(WithOnboardingEnabled(true), WithDefaultChannels(ids(MinOnboardingDefaultChannels)...)),
+			valid:    true,
+		},
+		{
+			name:     "not enough default channels",
+			settings: NewOnboardingSettings(WithOnboardingEnabled(true), WithDefaultChannels(ids(MinOnboardingDefaultChannels-1)...)),
+			valid:    false,
+		},
+		{
+			name:     "disabled",
+			settings: NewOnboardingSettings(WithOnboardingEnabled(false), WithDefaultChannels()),
+			valid:    true,
+		},
+		{
+			name: "literal not enough default channels",
+			settings: &OnboardingSettings{
+				Enabled:           optional.NewBool(true),
+				DefaultChannelIDs: optional.NewStringSlice(ids(1)),
+			},
+			valid: false,
+		},
+		{
+			name: "advanced mode counts prompt channels",
+			settings: NewOnboardingSettings(
+				WithOnboardingEnabled(true),
+				WithOnboardingMode(OnboardingModeAdvanced),
+				WithDefaultChannels(ids(MinOnboardingDefaultChannels-2)...),
+				WithPrompts(prompt),
+			),
+			valid: true,
+		},
+		{
+			name: "default mode ignores prompt channels",
+			settings: NewOnboardingSettings(
+				WithOnboardingEnabled(true),
+				WithDefaultChannels(ids(MinOnboardingDefaultChannels-2)...),
+				WithPrompts(prompt),
+			),
+			valid: false,
+		},
+		{name: "invalid prompt", settings: NewOnboardingSettings(WithPrompts(Prompt{Title: "No options"})), valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.settings.Validate(); (err == nil) != tt.valid {
+				t.Errorf("expected settings to be valid: %t, got error: %v", tt.valid, err)
+			}
+		})
+	}
+}
@@ -18,6 +18,8 @@ type Settings struct {
 	OwnerID                     *optional.String `json:"owner_id,omitempty"`
 	Splash                      *optional.String `json:"splash,omitempty"`
 	SystemChannelID             *optional.String `json:"system_channel_id,omitempty"`
+	PreferredLocale             *optional.String `json:"preferred_locale,omitempty"`
+	SafetyAlertsChannelID       *optional.String `json:"safety_alerts_channel_id,omitempty"`
 }
 
 // Setting is a function that configures a guild.
@@ -172,9 +174,14 @@ func WithAFKTimeout(t AFKTimeout) Setting {
 }
 
 // WithIcon sets the Guild icon which is a base64 encoded 128x128 jpeg image.
+// An empty icon will remove the current icon.
 func WithIcon(icon string) Setting {
 	return func(s *Settings) {
-		s.Icon = optional.NewString(icon)
+		if icon == "" {
+			s.Icon = optional.NewNilString()
+		} else {
+			s.Icon = optional.NewString(icon)
+		}
 	}
 }
 
@@ -186,15 +193,72 @@ func WithOwner(id string) Setting {
 }
 
 // WithSplash sets the Guild splash (VIP only) which is a base64 encoded 128x128 image.
+// An empty splash will remove the current splash.
 func WithSplash(splash string) Setting {
 	return func(s *Settings) {
-		s.Splash = optional.NewString(splash)
+		if splash == "" {
+			s.Splash = optional.NewNilString()
+		} else {
+			s.Splash = optional.NewString(splash)
+		}
 	}
 }
 
 // WithSystemChannel sets the id of the channel to which system messages are sent.
+// An empty id will disable system messages.
 func WithSystemChannel(id string) Setting {
 	return func(s *Settings) {
-		s.SystemChannelID = optional.NewString(id)
+		if id == "" {
+			s.SystemChannelID = optional.NewNilString()
+		} else {
+			s.SystemChannelID = optional.NewString(id)
+		}
+	}
+}
+
+// WithPreferredLocale sets the preferred locale of a Community guild, used
+// in server discovery and notices from Discord. Defaults to "en-US".
+func WithPreferredLocale(locale string) Setting {
+	return func(s *Settings) {
+		s.PreferredLocale = optional.NewString(locale)
+	}
+}
+
+// WithSafetyAlertsChannel sets the id of the channel where admins and moderators
+// of Community guilds receive safety alerts from Discord.
+// An empty id will disable safety alerts.
+func WithSafetyAlertsChannel(id string) Setting {
+	return func(s *Settings) {
+		if id == "" {
+			s.SafetyAlertsChannelID = optional.NewNilString()
+		} else {
+			s.SafetyAlertsChannelID = optional.NewString(id)
+		}
 	}
 }
+
+// NSFWLevel is the NSFW level of a guild, as set by Discord.
+type NSFWLevel int
+
+const (
+	// NSFWLevelDefault means the guild has not been classified yet.
+	NSFWLevelDefault NSFWLevel = iota
+	// NSFWLevelExplicit means the guild contains explicit content.
+	NSFWLevelExplicit
+	// NSFWLevelSafe means the guild is safe for work.
+	NSFWLevelSafe
+	// NSFWLevelAgeRestricted means the guild is age restricted.
+	NSFWLevelAgeRestricted
+)
+
+// HubType is the type of a Student Hub guild.
+type HubType int
+
+const (
+	// HubTypeDefault is the default Student Hub type.
+	HubTypeDefault HubType = iota
+	// HubTypeHighSchool is a Student Hub for a high school.
+	HubTypeHighSchool
+	// HubTypeCollege is a Student Hub for a college.
+	HubTypeCollege
+)
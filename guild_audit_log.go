@@ -4,76 +4,64 @@ import (
 	"context"
 	"io/ioutil"
 	"net/http"
-	"net/url"
 	"strconv"
 
 	"github.com/skwair/harmony/audit"
 	"github.com/skwair/harmony/internal/endpoint"
+	"github.com/skwair/harmony/internal/pagination"
 )
 
-type auditLogQuery struct {
-	userID    string
-	entryType audit.EntryType
-	before    string
-	limit     int
-}
-
-// AuditLogOption allows to customize a query to the audit log.
-type AuditLogOption func(*auditLogQuery)
+// AuditLogOption allows to customize a query to the audit log,
+// see the audit.WithXxx functions.
+type AuditLogOption = audit.QueryOption
 
 // WithUserID sets the user ID of the audit log query.
 // It make the query only return audit log entries that have been
 // creating for actions performed by this user.
+//
+// Deprecated: use audit.WithUserID.
 func WithUserID(id string) AuditLogOption {
-	return AuditLogOption(func(q *auditLogQuery) {
-		q.userID = id
-	})
+	return audit.WithUserID(id)
 }
 
 // WithEntryType sets the entry type the query must return.
+//
+// Deprecated: use audit.WithActionType.
 func WithEntryType(typ audit.EntryType) AuditLogOption {
-	return AuditLogOption(func(q *auditLogQuery) {
-		q.entryType = typ
-	})
+	return audit.WithActionType(typ)
 }
 
 // WithBefore is used to paginate the audit log. The before parameter is the
 // ID of the last audit log entry of our previous query.
+//
+// Deprecated: use audit.WithBefore.
 func WithBefore(before string) AuditLogOption {
-	return AuditLogOption(func(q *auditLogQuery) {
-		q.before = before
-	})
+	return audit.WithBefore(before)
 }
 
 // WithLimit sets the limit the audit log query should return.
 // It must be between 1 and 100 and defaults to 50 if not specified.
+//
+// Deprecated: use audit.WithLimit.
 func WithLimit(limit int) AuditLogOption {
-	return AuditLogOption(func(q *auditLogQuery) {
-		q.limit = limit
-	})
+	return audit.WithLimit(limit)
 }
 
 // AuditLog returns the audit log of the given Guild. Requires the 'VIEW_AUDIT_LOG' permission.
 func (r *GuildResource) AuditLog(ctx context.Context, opts ...AuditLogOption) (*audit.Log, error) {
-	query := &auditLogQuery{}
-
-	for _, opt := range opts {
-		opt(query)
-	}
+	query := audit.NewQuery(opts...)
+	return r.auditLog(ctx, query, pagination.Cursor{Before: query.Before, Limit: query.Limit})
+}
 
-	q := url.Values{}
+// auditLog fetches a page of the audit log of the guild.
+func (r *GuildResource) auditLog(ctx context.Context, query *audit.Query, c pagination.Cursor) (*audit.Log, error) {
+	q := c.Values()
 
-	if query.userID != "" {
-		q.Set("user_id", query.userID)
-	}
-	if query.entryType != 0 {
-		q.Set("action_type", strconv.Itoa(int(query.entryType)))
-	}
-	if query.before != "" {
-		q.Set("before", query.before)
+	if query.UserID != "" {
+		q.Set("user_id", query.UserID)
 	}
-	if query.limit != 0 {
-		q.Set("limit", strconv.Itoa(query.limit))
+	if query.ActionType != 0 {
+		q.Set("action_type", strconv.Itoa(int(query.ActionType)))
 	}
 
 	e := endpoint.GetAuditLog(r.guildID, q.Encode())
@@ -4,10 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
-	"net/url"
-	"strconv"
 
 	"github.com/skwair/harmony/internal/endpoint"
+	"github.com/skwair/harmony/internal/pagination"
 )
 
 // Ban represents a Guild ban.
@@ -19,7 +18,12 @@ type Ban struct {
 // Bans returns a list of bans for the users banned from this guild.
 // Requires the 'BAN_MEMBERS' permission.
 func (r *GuildResource) Bans(ctx context.Context) ([]Ban, error) {
-	e := endpoint.GetGuildBans(r.guildID)
+	return r.bans(ctx, pagination.Cursor{})
+}
+
+// bans fetches a page of guild bans.
+func (r *GuildResource) bans(ctx context.Context, c pagination.Cursor) ([]Ban, error) {
+	e := endpoint.GetGuildBans(r.guildID, c.Values().Encode())
 	resp, err := r.client.doReq(ctx, e, nil)
 	if err != nil {
 		return nil, err
@@ -49,16 +53,18 @@ func (r *GuildResource) Ban(ctx context.Context, userID string) error {
 // Parameter delMsgDays is the number of days to delete messages for (0-7).
 // Fires a Guild Ban Add Gateway event.
 func (r *GuildResource) BanWithReason(ctx context.Context, userID string, delMsgDays int, reason string) error {
-	q := url.Values{}
-	if reason != "" {
-		q.Set("reason", reason)
+	st := struct {
+		DeleteMessageDays int `json:"delete_message_days,omitempty"`
+	}{
+		DeleteMessageDays: delMsgDays,
 	}
-	if delMsgDays > 0 {
-		q.Set("delete-message-days", strconv.Itoa(delMsgDays))
+	b, err := json.Marshal(&st)
+	if err != nil {
+		return err
 	}
 
-	e := endpoint.CreateGuildBan(r.guildID, userID, q.Encode())
-	resp, err := r.client.doReq(ctx, e, nil)
+	e := endpoint.CreateGuildBan(r.guildID, userID)
+	resp, err := r.client.doReqWithHeader(ctx, e, jsonPayload(b), reasonHeader(reason))
 	if err != nil {
 		return err
 	}
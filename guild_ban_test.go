@@ -0,0 +1,60 @@
+package harmony_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony/harmonytest"
+)
+
+func TestBanWithReason(t *testing.T) {
+	tests := []struct {
+		name       string
+		delMsgDays int
+		reason     string
+		body       string
+	}{
+		{name: "no messages deleted", delMsgDays: 0, body: `{}`},
+		{name: "messages deleted", delMsgDays: 7, body: `{"delete_message_days":7}`},
+		{name: "with reason", delMsgDays: 1, reason: "spam", body: `{"delete_message_days":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := harmonytest.NewServer()
+			defer srv.Close()
+
+			srv.Handle(http.MethodPut, "/guilds/:guild/bans/:user", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			c, err := srv.NewClient()
+			if err != nil {
+				t.Fatalf("could not create client: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if err = c.Guild("1").BanWithReason(ctx, "2", tt.delMsgDays, tt.reason); err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := srv.WaitRequest(ctx, harmonytest.MatchRoute(http.MethodPut, "/guilds/1/bans/2"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(req.Query) != 0 {
+				t.Errorf("expected no query parameters, got %v", req.Query)
+			}
+			if string(req.Body) != tt.body {
+				t.Errorf("expected body %s, got %s", tt.body, req.Body)
+			}
+			if reason := req.Header.Get("X-Audit-Log-Reason"); reason != tt.reason {
+				t.Errorf("expected reason %q, got %q", tt.reason, reason)
+			}
+		})
+	}
+}
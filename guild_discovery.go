@@ -0,0 +1,183 @@
+package harmony
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/skwair/harmony/guild"
+	"github.com/skwair/harmony/internal/endpoint"
+)
+
+// DiscoveryMetadata is the metadata of a guild shown in Server Discovery.
+type DiscoveryMetadata struct {
+	GuildID string `json:"guild_id"`
+	// ID of the primary discovery category of the guild.
+	PrimaryCategoryID int `json:"primary_category_id"`
+	// Up to 10 keywords used to find the guild in search.
+	Keywords []string `json:"keywords"`
+	// Whether guild info is shown when custom emojis of the guild are clicked.
+	EmojiDiscoverabilityEnabled bool `json:"emoji_discoverability_enabled"`
+	// When the guild's partner application was accepted or denied,
+	// for applications via Server Settings.
+	PartnerActionedTimestamp *time.Time `json:"partner_actioned_timestamp"`
+	// When the server's partner application was submitted.
+	PartnerApplicationTimestamp *time.Time `json:"partner_application_timestamp"`
+	// IDs of up to 5 discovery subcategories of the guild.
+	CategoryIDs []int `json:"category_ids"`
+}
+
+// DiscoveryCategory is a category of Server Discovery.
+type DiscoveryCategory struct {
+	ID   int `json:"id"`
+	Name struct {
+		Default       string            `json:"default"`
+		Localizations map[string]string `json:"localizations"`
+	} `json:"name"`
+	// Whether the category can be set as a guild's primary category.
+	IsPrimary bool `json:"is_primary"`
+}
+
+// DiscoveryCategories returns the list of categories of Server Discovery. If locale is
+// not empty, category names are also returned in that language, if available.
+func (c *Client) DiscoveryCategories(ctx context.Context, locale string) ([]DiscoveryCategory, error) {
+	q := url.Values{}
+	if locale != "" {
+		q.Set("locale", locale)
+	}
+
+	e := endpoint.ListDiscoveryCategories(q.Encode())
+	resp, err := c.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var categories []DiscoveryCategory
+	if err = json.NewDecoder(resp.Body).Decode(&categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// ValidDiscoveryTerm returns whether the given term can be used as a discovery keyword.
+func (c *Client) ValidDiscoveryTerm(ctx context.Context, term string) (bool, error) {
+	q := url.Values{}
+	q.Set("term", term)
+
+	e := endpoint.ValidateDiscoverySearchTerm(q.Encode())
+	resp, err := c.doReq(ctx, e, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, apiError(resp)
+	}
+
+	var res struct {
+		Valid bool `json:"valid"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return false, err
+	}
+	return res.Valid, nil
+}
+
+// DiscoveryMetadata returns the discovery metadata of the guild.
+// Requires the 'MANAGE_GUILD' permission.
+func (r *GuildResource) DiscoveryMetadata(ctx context.Context) (*DiscoveryMetadata, error) {
+	e := endpoint.GetGuildDiscoveryMetadata(r.guildID)
+	resp, err := r.client.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var m DiscoveryMetadata
+	if err = json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ModifyDiscoveryMetadata is like ModifyDiscoveryMetadataWithReason but with no particular reason.
+func (r *GuildResource) ModifyDiscoveryMetadata(ctx context.Context, settings *guild.DiscoverySettings) (*DiscoveryMetadata, error) {
+	return r.ModifyDiscoveryMetadataWithReason(ctx, settings, "")
+}
+
+// ModifyDiscoveryMetadataWithReason modifies the discovery metadata of the guild.
+// Settings are validated before being sent, see guild.DiscoverySettings.Validate.
+// Requires the 'MANAGE_GUILD' permission.
+// The given reason will be set in the audit log entry for this action.
+func (r *GuildResource) ModifyDiscoveryMetadataWithReason(ctx context.Context, settings *guild.DiscoverySettings, reason string) (*DiscoveryMetadata, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	e := endpoint.ModifyGuildDiscoveryMetadata(r.guildID)
+	resp, err := r.client.doReqWithHeader(ctx, e, jsonPayload(b), reasonHeader(reason))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var m DiscoveryMetadata
+	if err = json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// AddDiscoveryCategory adds a discovery subcategory to the guild. A guild can
+// have at most 5 subcategories. Requires the 'MANAGE_GUILD' permission.
+func (r *GuildResource) AddDiscoveryCategory(ctx context.Context, categoryID int) error {
+	e := endpoint.AddGuildDiscoverySubcategory(r.guildID, strconv.Itoa(categoryID))
+	resp, err := r.client.doReq(ctx, e, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return apiError(resp)
+	}
+	return nil
+}
+
+// RemoveDiscoveryCategory removes a discovery subcategory from the guild.
+// Requires the 'MANAGE_GUILD' permission.
+func (r *GuildResource) RemoveDiscoveryCategory(ctx context.Context, categoryID int) error {
+	e := endpoint.RemoveGuildDiscoverySubcategory(r.guildID, strconv.Itoa(categoryID))
+	resp, err := r.client.doReq(ctx, e, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return apiError(resp)
+	}
+	return nil
+}
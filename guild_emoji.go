@@ -3,6 +3,7 @@ package harmony
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/skwair/harmony/internal/endpoint"
@@ -19,6 +20,15 @@ type Emoji struct {
 	Animated      bool   `json:"animated"`
 }
 
+// URL returns the URL of the image of this custom emoji.
+func (e *Emoji) URL() string {
+	ext := "png"
+	if e.Animated {
+		ext = "gif"
+	}
+	return fmt.Sprintf("%s/emojis/%s.%s", cdnURL, e.ID, ext)
+}
+
 // Emojis returns the list of emojis of the guild.
 // Requires the MANAGE_EMOJIS permission.
 func (r *GuildResource) Emojis(ctx context.Context) ([]Emoji, error) {
@@ -66,8 +76,8 @@ func (r *GuildResource) NewEmoji(ctx context.Context, name, image string, roles
 }
 
 // NewEmojiWithReason creates a new emoji for the guild. image is the base64 encoded data of a
-// 128*128 image. Requires the 'MANAGE_EMOJIS' permission. Fires a Guild Emojis Update
-// Gateway event.
+// 128*128 image, which can be obtained with ImageData and MaxEmojiSize. Requires the
+// 'MANAGE_EMOJIS' permission. Fires a Guild Emojis Update Gateway event.
 // The given reason will be set in the audit log entry for this action.
 func (r *GuildResource) NewEmojiWithReason(ctx context.Context, name, image string, roles []string, reason string) (*Emoji, error) {
 	st := struct {
@@ -0,0 +1,68 @@
+package harmony
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/skwair/harmony/internal/endpoint"
+)
+
+// MaxIncidentActionDuration is the longest duration invites
+// or direct messages of a guild can be disabled for at once.
+const MaxIncidentActionDuration = 24 * time.Hour
+
+// GuildIncidents holds the incident actions and the
+// raid or spam alerts of a guild.
+type GuildIncidents struct {
+	// Time until which invites and direct messages
+	// between members are disabled, if they are.
+	InvitesDisabledUntil *time.Time `json:"invites_disabled_until"`
+	DMsDisabledUntil     *time.Time `json:"dms_disabled_until"`
+	// Time at which Discord detected DM spam or a raid, if it did.
+	DMSpamDetectedAt *time.Time `json:"dm_spam_detected_at"`
+	RaidDetectedAt   *time.Time `json:"raid_detected_at"`
+}
+
+// ModifyIncidentActions disables invites and direct messages between members of the
+// guild until the given times, at most MaxIncidentActionDuration in the future. A zero
+// time enables them again. Both actions are always set, so use the current values of
+// the guild to only modify one of them. Requires the 'MANAGE_GUILD' permission.
+func (r *GuildResource) ModifyIncidentActions(ctx context.Context, invitesDisabledUntil, dmsDisabledUntil time.Time) (*GuildIncidents, error) {
+	timeOrNil := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+		t = t.UTC()
+		return &t
+	}
+	st := struct {
+		InvitesDisabledUntil *time.Time `json:"invites_disabled_until"`
+		DMsDisabledUntil     *time.Time `json:"dms_disabled_until"`
+	}{
+		InvitesDisabledUntil: timeOrNil(invitesDisabledUntil),
+		DMsDisabledUntil:     timeOrNil(dmsDisabledUntil),
+	}
+	b, err := json.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+
+	e := endpoint.ModifyGuildIncidentActions(r.guildID)
+	resp, err := r.client.doReq(ctx, e, jsonPayload(b))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var incidents GuildIncidents
+	if err = json.NewDecoder(resp.Body).Decode(&incidents); err != nil {
+		return nil, err
+	}
+	return &incidents, nil
+}
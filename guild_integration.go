@@ -32,6 +32,7 @@ type IntegrationAccount struct {
 
 // Integrations returns the list of integrations for the guild.
 // Requires the 'MANAGE_GUILD' permission.
+// If state tracking is enabled, they are cached, see State.Integrations.
 func (r *GuildResource) Integrations(ctx context.Context) ([]Integration, error) {
 	e := endpoint.GetGuildIntegrations(r.guildID)
 	resp, err := r.client.doReq(ctx, e, nil)
@@ -48,6 +49,10 @@ func (r *GuildResource) Integrations(ctx context.Context) ([]Integration, error)
 	if err = json.NewDecoder(resp.Body).Decode(&integrations); err != nil {
 		return nil, err
 	}
+
+	if r.client.withStateTracking {
+		r.client.State.setIntegrations(r.guildID, integrations)
+	}
 	return integrations, nil
 }
 
@@ -3,13 +3,14 @@ package harmony
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"net/url"
-	"strconv"
 	"time"
 
 	"github.com/skwair/harmony/guild"
 	"github.com/skwair/harmony/internal/endpoint"
+	"github.com/skwair/harmony/internal/pagination"
+	"github.com/skwair/harmony/permission"
 )
 
 // GuildMember represents a User in a Guild.
@@ -20,12 +21,64 @@ type GuildMember struct {
 	JoinedAt time.Time `json:"joined_at,omitempty"`
 	Deaf     bool      `json:"deaf,omitempty"`
 	Mute     bool      `json:"mute,omitempty"`
+	// Guild specific avatar and banner hashes of the member, if set.
+	Avatar *string          `json:"avatar,omitempty"`
+	Banner *string          `json:"banner,omitempty"`
+	Flags  guild.MemberFlag `json:"flags,omitempty"`
+	// Time until which the member is timed out, nil if it is not.
+	CommunicationDisabledUntil *time.Time `json:"communication_disabled_until,omitempty"`
 }
 
-// PermissionsIn returns the permissions of the Guild member in the given Guild and channel.
-func (m *GuildMember) PermissionsIn(g *Guild, ch *Channel) (permissions int) {
-	base := computeBasePermissions(g, m)
-	return computeOverwrites(ch, m, base)
+// TimedOut returns whether this member is currently timed out.
+func (m *GuildMember) TimedOut() bool {
+	return m.CommunicationDisabledUntil != nil && m.CommunicationDisabledUntil.After(time.Now())
+}
+
+// GuildAvatarURL returns the URL of the avatar this member has in the given
+// guild. If the member has no guild specific avatar, it falls back to the
+// avatar of the user. It returns an empty string if the user of the member
+// is not set, as in some Gateway events.
+func (m *GuildMember) GuildAvatarURL(guildID string) string {
+	// URLs of member avatars contain the ID of the user.
+	if m.User == nil {
+		return ""
+	}
+	if m.Avatar == nil || *m.Avatar == "" {
+		return m.User.AvatarURL()
+	}
+
+	return fmt.Sprintf("%s/guilds/%s/users/%s/avatars/%s.%s",
+		cdnURL, guildID, m.User.ID, *m.Avatar, imageExtension(*m.Avatar))
+}
+
+// GuildBannerURL returns the URL of the banner this member has in the given
+// guild. If the member has no guild specific banner, it falls back to the
+// banner of the user, which may be empty. It returns an empty string if the
+// user of the member is not set, as in some Gateway events.
+func (m *GuildMember) GuildBannerURL(guildID string) string {
+	// URLs of member banners contain the ID of the user.
+	if m.User == nil {
+		return ""
+	}
+	if m.Banner == nil || *m.Banner == "" {
+		return m.User.BannerURL()
+	}
+
+	return fmt.Sprintf("%s/guilds/%s/users/%s/banners/%s.%s",
+		cdnURL, guildID, m.User.ID, *m.Banner, imageExtension(*m.Banner))
+}
+
+// PermissionsIn returns the permissions of the Guild member in the given Guild and channel,
+// or in the Guild as a whole if ch is nil. See permission.Compute for more information.
+func (m *GuildMember) PermissionsIn(g *Guild, ch *Channel) (permissions uint64) {
+	return permission.Compute(m.permissionMember(), g.permissionGuild(), permissionChannel(ch))
+}
+
+// Outranks returns whether this member is higher than the target member in the role
+// hierarchy of the given Guild, which is required to kick, ban, time out or manage
+// the roles and nickname of the target. See permission.Outranks for more information.
+func (m *GuildMember) Outranks(g *Guild, target *GuildMember) bool {
+	return permission.Outranks(g.permissionGuild(), m.permissionMember(), target.permissionMember())
 }
 
 // HasRole returns whether this member has the given role.
@@ -72,13 +125,12 @@ func (r *GuildResource) Members(ctx context.Context, limit int, after string) ([
 		limit = 1000
 	}
 
-	q := url.Values{}
-	q.Set("limit", strconv.Itoa(limit))
-	if after != "" {
-		q.Set("after", after)
-	}
+	return r.members(ctx, pagination.Cursor{After: after, Limit: limit})
+}
 
-	e := endpoint.ListGuildMembers(r.guildID, q.Encode())
+// members fetches a page of guild members.
+func (r *GuildResource) members(ctx context.Context, c pagination.Cursor) ([]GuildMember, error) {
+	e := endpoint.ListGuildMembers(r.guildID, c.Values().Encode())
 	resp, err := r.client.doReq(ctx, e, nil)
 	if err != nil {
 		return nil, err
@@ -178,3 +230,31 @@ func (r *GuildResource) ModifyMemberWithReason(ctx context.Context, userID strin
 	}
 	return nil
 }
+
+// MuteMember is like MuteMemberWithReason but with no particular reason.
+func (r *GuildResource) MuteMember(ctx context.Context, userID string, mute bool) error {
+	return r.MuteMemberWithReason(ctx, userID, mute, "")
+}
+
+// MuteMemberWithReason server mutes or unmutes a guild member in voice channels.
+// The member must be connected to a voice channel. Requires the 'MUTE_MEMBERS'
+// permission. Fires a Guild Member Update and a Voice State Update Gateway event.
+// The given reason will be set in the audit log entry for this action.
+func (r *GuildResource) MuteMemberWithReason(ctx context.Context, userID string, mute bool, reason string) error {
+	settings := guild.NewMemberSettings(guild.WithMute(mute))
+	return r.ModifyMemberWithReason(ctx, userID, settings, reason)
+}
+
+// DeafenMember is like DeafenMemberWithReason but with no particular reason.
+func (r *GuildResource) DeafenMember(ctx context.Context, userID string, deaf bool) error {
+	return r.DeafenMemberWithReason(ctx, userID, deaf, "")
+}
+
+// DeafenMemberWithReason server deafens or undeafens a guild member in voice channels.
+// The member must be connected to a voice channel. Requires the 'DEAFEN_MEMBERS'
+// permission. Fires a Guild Member Update and a Voice State Update Gateway event.
+// The given reason will be set in the audit log entry for this action.
+func (r *GuildResource) DeafenMemberWithReason(ctx context.Context, userID string, deaf bool, reason string) error {
+	settings := guild.NewMemberSettings(guild.WithDeaf(deaf))
+	return r.ModifyMemberWithReason(ctx, userID, settings, reason)
+}
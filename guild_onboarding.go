@@ -0,0 +1,97 @@
+package harmony
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/skwair/harmony/guild"
+	"github.com/skwair/harmony/internal/endpoint"
+)
+
+// GuildOnboarding is the onboarding flow of a guild, shown to new members.
+type GuildOnboarding struct {
+	GuildID           string               `json:"guild_id"`
+	Prompts           []OnboardingPrompt   `json:"prompts"`
+	DefaultChannelIDs []string             `json:"default_channel_ids"`
+	Enabled           bool                 `json:"enabled"`
+	Mode              guild.OnboardingMode `json:"mode"`
+}
+
+// OnboardingPrompt is a question asked to new members during onboarding.
+type OnboardingPrompt struct {
+	ID           string                   `json:"id"`
+	Type         guild.PromptType         `json:"type"`
+	Options      []OnboardingPromptOption `json:"options"`
+	Title        string                   `json:"title"`
+	SingleSelect bool                     `json:"single_select"`
+	Required     bool                     `json:"required"`
+	InOnboarding bool                     `json:"in_onboarding"`
+}
+
+// OnboardingPromptOption is an option available within an onboarding prompt.
+type OnboardingPromptOption struct {
+	ID          string   `json:"id"`
+	ChannelIDs  []string `json:"channel_ids"`
+	RoleIDs     []string `json:"role_ids"`
+	Emoji       *Emoji   `json:"emoji"`
+	Title       string   `json:"title"`
+	Description *string  `json:"description"`
+}
+
+// Onboarding returns the onboarding of the guild.
+func (r *GuildResource) Onboarding(ctx context.Context) (*GuildOnboarding, error) {
+	e := endpoint.GetGuildOnboarding(r.guildID)
+	resp, err := r.client.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var o GuildOnboarding
+	if err = json.NewDecoder(resp.Body).Decode(&o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// ModifyOnboarding is like ModifyOnboardingWithReason but with no particular reason.
+func (r *GuildResource) ModifyOnboarding(ctx context.Context, settings *guild.OnboardingSettings) (*GuildOnboarding, error) {
+	return r.ModifyOnboardingWithReason(ctx, settings, "")
+}
+
+// ModifyOnboardingWithReason modifies the onboarding of the guild. Settings are
+// validated before being sent, see guild.OnboardingSettings.Validate. Requires
+// the 'MANAGE_GUILD' and 'MANAGE_ROLES' permissions.
+// The given reason will be set in the audit log entry for this action.
+func (r *GuildResource) ModifyOnboardingWithReason(ctx context.Context, settings *guild.OnboardingSettings, reason string) (*GuildOnboarding, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	e := endpoint.ModifyGuildOnboarding(r.guildID)
+	resp, err := r.client.doReqWithHeader(ctx, e, jsonPayload(b), reasonHeader(reason))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var o GuildOnboarding
+	if err = json.NewDecoder(resp.Body).Decode(&o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
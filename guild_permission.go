@@ -2,80 +2,36 @@ package harmony
 
 import "github.com/skwair/harmony/permission"
 
-// computeBasePermissions returns the base permissions a member has in a given guild.
-func computeBasePermissions(g *Guild, m *GuildMember) (permissions int) {
-	if g.OwnerID == m.User.ID {
-		return permission.All
+// permissionGuild returns the guild as needed to compute permissions.
+func (g *Guild) permissionGuild() *permission.Guild {
+	pg := &permission.Guild{
+		ID:      g.ID,
+		OwnerID: g.OwnerID,
+		Roles:   make([]permission.Role, len(g.Roles)),
 	}
-
-	// Role '@everyone' has the same ID as the guild ID
-	// and is always present, so no need to check for nil.
-	roleEveryone := roleByID(g.Roles, g.ID)
-	permissions = roleEveryone.Permissions
-
-	for _, id := range m.Roles {
-		role := roleByID(g.Roles, id)
-		if role != nil {
-			permissions |= role.Permissions
-		}
-	}
-
-	if permission.Contains(permissions, permission.Administrator) {
-		return permission.All
+	for i, r := range g.Roles {
+		pg.Roles[i] = permission.Role{ID: r.ID, Position: r.Position, Permissions: r.Permissions}
 	}
-	return permissions
+	return pg
 }
 
-func computeOverwrites(ch *Channel, m *GuildMember, basePermissions int) (permissions int) {
-	// Administrator can not be overridden.
-	if permission.Contains(basePermissions, permission.Administrator) {
-		return permission.All
-	}
-
-	permissions = basePermissions
-
-	po := overwriteByID(ch.PermissionOverwrites, ch.GuildID)
-	if po != nil {
-		permissions &= ^po.Deny
-		permissions |= po.Allow
+// permissionMember returns the member as needed to compute permissions.
+func (m *GuildMember) permissionMember() *permission.Member {
+	pm := &permission.Member{Roles: m.Roles}
+	if m.User != nil {
+		pm.UserID = m.User.ID
 	}
-
-	pos := ch.PermissionOverwrites
-	allow := permission.None
-	deny := permission.None
-	for _, id := range m.Roles {
-		por := overwriteByID(pos, id)
-		if por != nil {
-			allow |= por.Allow
-			deny |= por.Deny
-		}
-	}
-	permissions &= ^deny
-	permissions |= allow
-
-	pom := overwriteByID(ch.PermissionOverwrites, m.User.ID)
-	if pom != nil {
-		permissions &= ^pom.Deny
-		permissions |= pom.Allow
-	}
-
-	return permissions
-}
-
-func roleByID(roles []Role, id string) *Role {
-	for i := 0; i < len(roles); i++ {
-		if roles[i].ID == id {
-			return &roles[i]
-		}
+	if m.CommunicationDisabledUntil != nil {
+		pm.CommunicationDisabledUntil = *m.CommunicationDisabledUntil
 	}
-	return nil
+	return pm
 }
 
-func overwriteByID(po []permission.Overwrite, id string) *permission.Overwrite {
-	for i := 0; i < len(po); i++ {
-		if po[i].ID == id {
-			return &po[i]
-		}
+// permissionChannel returns the channel as needed to compute permissions,
+// or nil if ch is nil.
+func permissionChannel(ch *Channel) *permission.Channel {
+	if ch == nil {
+		return nil
 	}
-	return nil
+	return &permission.Channel{Overwrites: ch.PermissionOverwrites}
 }
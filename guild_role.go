@@ -20,9 +20,60 @@ type Role struct {
 	Color       int    `json:"color"`    // Integer representation of hexadecimal color code.
 	Hoist       bool   `json:"hoist"`    // Whether this role is pinned in the user listing.
 	Position    int    `json:"position"` // Integer	position of this role.
-	Permissions int    `json:"permissions"`
+	Permissions uint64 `json:"permissions,string"`
 	Managed     bool   `json:"managed"` // Whether this role is managed by an integration.
 	Mentionable bool   `json:"mentionable"`
+	// Icon hash of this role, if it has one.
+	Icon string `json:"icon,omitempty"`
+	// Standard emoji shown as the icon of this role, if it has one.
+	UnicodeEmoji string `json:"unicode_emoji,omitempty"`
+	// Tags of this role, set if it is managed by a bot,
+	// an integration or if it is a special role.
+	Tags *role.Tags `json:"tags,omitempty"`
+}
+
+// IsBotManaged returns whether this role is the role of a bot,
+// automatically created when the bot joined the guild.
+func (r *Role) IsBotManaged() bool {
+	return r.Tags != nil && r.Tags.BotID != nil
+}
+
+// IsIntegrationManaged returns whether this role is managed by an
+// integration, such as Twitch or YouTube subscriptions.
+func (r *Role) IsIntegrationManaged() bool {
+	return r.Tags != nil && r.Tags.IntegrationID != nil
+}
+
+// IsPremiumSubscriber returns whether this role is the Booster role of the guild.
+func (r *Role) IsPremiumSubscriber() bool {
+	return r.Tags != nil && r.Tags.PremiumSubscriber
+}
+
+// IsPurchasable returns whether this role is a role subscription
+// that is available for purchase.
+func (r *Role) IsPurchasable() bool {
+	return r.Tags != nil && r.Tags.SubscriptionListingID != nil && r.Tags.AvailableForPurchase
+}
+
+// IsLinked returns whether this role is a linked role, granted
+// through connections.
+func (r *Role) IsLinked() bool {
+	return r.Tags != nil && r.Tags.GuildConnections
+}
+
+// IsAssignable returns whether this role can be manually assigned to members.
+// Roles managed by bots, integrations, boosts or subscriptions can not.
+func (r *Role) IsAssignable() bool {
+	if r.Managed {
+		return false
+	}
+	if r.Tags == nil {
+		return true
+	}
+	return r.Tags.BotID == nil &&
+		r.Tags.IntegrationID == nil &&
+		!r.Tags.PremiumSubscriber &&
+		r.Tags.SubscriptionListingID == nil
 }
 
 // Roles returns a list of roles for the guild. Requires the 'MANAGE_ROLES'
@@ -51,10 +102,15 @@ func (r *GuildResource) NewRole(ctx context.Context, settings *role.Settings) (*
 	return r.NewRoleWithReason(ctx, settings, "")
 }
 
-// NewRole creates a new role for the guild. Requires the 'MANAGE_ROLES'
-// permission. Fires a Guild Role Create Gateway event.
+// NewRoleWithReason creates a new role for the guild. Requires the 'MANAGE_ROLES'
+// permission. The settings are validated before being sent, see role.Settings.Validate.
+// Fires a Guild Role Create Gateway event.
 // The given reason will be set in the audit log entry for this action.
 func (r *GuildResource) NewRoleWithReason(ctx context.Context, settings *role.Settings, reason string) (*Role, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
 	b, err := json.Marshal(settings)
 	if err != nil {
 		return nil, err
@@ -116,10 +172,15 @@ func (r *GuildResource) ModifyRole(ctx context.Context, id string, settings *rol
 	return r.ModifyRoleWithReason(ctx, id, settings, "")
 }
 
-// ModifyRole modifies a guild role. Requires the 'MANAGE_ROLES' permission.
+// ModifyRoleWithReason modifies a guild role. Requires the 'MANAGE_ROLES' permission.
+// The settings are validated before being sent, see role.Settings.Validate.
 // Fires a Guild Role Update Gateway event.
 // The given reason will be set in the audit log entry for this action.
 func (r *GuildResource) ModifyRoleWithReason(ctx context.Context, id string, settings *role.Settings, reason string) (*Role, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
 	b, err := json.Marshal(settings)
 	if err != nil {
 		return nil, err
@@ -148,7 +209,7 @@ func (r *GuildResource) DeleteRole(ctx context.Context, id string) error {
 	return r.DeleteRoleWithReason(ctx, id, "")
 }
 
-// DeleteRole deletes a guild role. Requires the 'MANAGE_ROLES' permission.
+// DeleteRoleWithReason deletes a guild role. Requires the 'MANAGE_ROLES' permission.
 // Fires a Guild Role Delete Gateway event.
 // The given reason will be set in the audit log entry for this action.
 func (r *GuildResource) DeleteRoleWithReason(ctx context.Context, id, reason string) error {
@@ -170,7 +231,7 @@ func (r *GuildResource) AddMemberRole(ctx context.Context, userID, roleID string
 	return r.AddMemberRoleWithReason(ctx, userID, roleID, "")
 }
 
-// AddMemberRole adds a role to a guild member. Requires the 'MANAGE_ROLES'
+// AddMemberRoleWithReason adds a role to a guild member. Requires the 'MANAGE_ROLES'
 // permission. Fires a Guild Member Update Gateway event.
 // The given reason will be set in the audit log entry for this action.
 func (r *GuildResource) AddMemberRoleWithReason(ctx context.Context, userID, roleID, reason string) error {
@@ -187,11 +248,17 @@ func (r *GuildResource) AddMemberRoleWithReason(ctx context.Context, userID, rol
 	return nil
 }
 
-// RemoveMemberRole removes a role from a guild member. Requires the
-// 'MANAGE_ROLES' permission. Fires a Guild Member Update Gateway event.
+// RemoveMemberRole is like RemoveMemberRoleWithReason but with no particular reason.
 func (r *GuildResource) RemoveMemberRole(ctx context.Context, userID, roleID string) error {
+	return r.RemoveMemberRoleWithReason(ctx, userID, roleID, "")
+}
+
+// RemoveMemberRoleWithReason removes a role from a guild member. Requires the
+// 'MANAGE_ROLES' permission. Fires a Guild Member Update Gateway event.
+// The given reason will be set in the audit log entry for this action.
+func (r *GuildResource) RemoveMemberRoleWithReason(ctx context.Context, userID, roleID, reason string) error {
 	e := endpoint.RemoveGuildMemberRole(r.guildID, userID, roleID)
-	resp, err := r.client.doReq(ctx, e, nil)
+	resp, err := r.client.doReqWithHeader(ctx, e, nil, reasonHeader(reason))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,51 @@
+package harmony
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/skwair/harmony/internal/endpoint"
+	"github.com/skwair/harmony/internal/pagination"
+)
+
+// ScheduledEventUser is a user interested in a guild scheduled event.
+type ScheduledEventUser struct {
+	GuildScheduledEventID string `json:"guild_scheduled_event_id"`
+	User                  *User  `json:"user"`
+	// Guild member of the user, only set when requested.
+	Member *GuildMember `json:"member"`
+}
+
+// ScheduledEventUsers returns up to limit users interested in the given scheduled
+// event of the guild, sorted by ID, after the given user ID if not empty. limit must
+// be between 1 and 100. If withMember is true, the guild member of users is also
+// returned, if they are still in the guild.
+func (r *GuildResource) ScheduledEventUsers(ctx context.Context, eventID string, withMember bool, limit int, after string) ([]ScheduledEventUser, error) {
+	c := pagination.Cursor{After: after, Limit: limit}
+	return r.scheduledEventUsers(ctx, eventID, withMember, c)
+}
+
+func (r *GuildResource) scheduledEventUsers(ctx context.Context, eventID string, withMember bool, c pagination.Cursor) ([]ScheduledEventUser, error) {
+	q := c.Values()
+	if withMember {
+		q.Set("with_member", "true")
+	}
+
+	e := endpoint.GetGuildScheduledEventUsers(r.guildID, eventID, q.Encode())
+	resp, err := r.client.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var users []ScheduledEventUser
+	if err = json.NewDecoder(resp.Body).Decode(&users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,318 @@
+package harmony
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/skwair/harmony/internal/endpoint"
+	"github.com/skwair/harmony/sticker"
+)
+
+// Sticker represents a sticker that can be sent in messages.
+type Sticker struct {
+	ID string `json:"id"`
+	// For standard stickers, ID of the pack the sticker is from.
+	PackID      string `json:"pack_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Autocomplete and suggestion tags of the sticker.
+	Tags       string         `json:"tags"`
+	Type       sticker.Type   `json:"type"`
+	FormatType sticker.Format `json:"format_type"`
+	// Whether this guild sticker can be used, may be false
+	// if the guild lost boosts.
+	Available bool   `json:"available"`
+	GuildID   string `json:"guild_id"`
+	// User that uploaded the guild sticker, only set with
+	// the 'MANAGE_GUILD_EXPRESSIONS' permission.
+	User *User `json:"user"`
+	// Sort order of the standard sticker within its pack.
+	SortValue int `json:"sort_value"`
+}
+
+// URL returns the URL of the file of this sticker.
+func (s *Sticker) URL() string {
+	return stickerURL(s.ID, s.FormatType)
+}
+
+// StickerItem is the smallest amount of data required to render a sticker,
+// sent with messages.
+type StickerItem struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	FormatType sticker.Format `json:"format_type"`
+}
+
+// URL returns the URL of the file of this sticker.
+func (s *StickerItem) URL() string {
+	return stickerURL(s.ID, s.FormatType)
+}
+
+func stickerURL(id string, format sticker.Format) string {
+	ext := "png"
+	switch format {
+	case sticker.FormatLottie:
+		ext = "json"
+	case sticker.FormatGIF:
+		ext = "gif"
+	}
+	return fmt.Sprintf("%s/stickers/%s.%s", cdnURL, id, ext)
+}
+
+// StickerPack is a pack of standard stickers.
+type StickerPack struct {
+	ID             string    `json:"id"`
+	Stickers       []Sticker `json:"stickers"`
+	Name           string    `json:"name"`
+	SKUID          string    `json:"sku_id"`
+	CoverStickerID string    `json:"cover_sticker_id"`
+	Description    string    `json:"description"`
+	BannerAssetID  string    `json:"banner_asset_id"`
+}
+
+// Sticker returns the sticker with the given ID.
+func (c *Client) Sticker(ctx context.Context, id string) (*Sticker, error) {
+	e := endpoint.GetSticker(id)
+	return c.getSticker(ctx, e)
+}
+
+// StickerPacks returns the packs of standard stickers.
+func (c *Client) StickerPacks(ctx context.Context) ([]StickerPack, error) {
+	e := endpoint.ListStickerPacks()
+	resp, err := c.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var body struct {
+		StickerPacks []StickerPack `json:"sticker_packs"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.StickerPacks, nil
+}
+
+// Stickers returns the list of stickers of the guild.
+func (r *GuildResource) Stickers(ctx context.Context) ([]Sticker, error) {
+	e := endpoint.ListGuildStickers(r.guildID)
+	resp, err := r.client.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var stickers []Sticker
+	if err = json.NewDecoder(resp.Body).Decode(&stickers); err != nil {
+		return nil, err
+	}
+	return stickers, nil
+}
+
+// Sticker returns a sticker from the guild.
+func (r *GuildResource) Sticker(ctx context.Context, stickerID string) (*Sticker, error) {
+	e := endpoint.GetGuildSticker(r.guildID, stickerID)
+	return r.client.getSticker(ctx, e)
+}
+
+func (c *Client) getSticker(ctx context.Context, e *endpoint.Endpoint) (*Sticker, error) {
+	resp, err := c.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var s Sticker
+	if err = json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// NewSticker is like NewStickerWithReason but with no particular reason.
+func (r *GuildResource) NewSticker(ctx context.Context, settings *sticker.Settings, file *File) (*Sticker, error) {
+	return r.NewStickerWithReason(ctx, settings, file, "")
+}
+
+// NewStickerWithReason uploads a new sticker to the guild. Settings must have a name
+// and tags. The file must be a PNG, APNG or GIF image, or a Lottie JSON animation,
+// of at most MaxStickerSize bytes, its format being detected from its name. It is
+// closed once uploaded. Requires the 'MANAGE_GUILD_EXPRESSIONS' permission. Fires a
+// Guild Stickers Update Gateway event.
+// The given reason will be set in the audit log entry for this action.
+func (r *GuildResource) NewStickerWithReason(ctx context.Context, settings *sticker.Settings, file *File, reason string) (*Sticker, error) {
+	defer file.reader.Close()
+
+	if settings.Name == nil || settings.Tags == nil {
+		return nil, errors.New("invalid sticker settings: name and tags are required")
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	b, contentType, err := stickerMultipart(settings, file)
+	if err != nil {
+		return nil, err
+	}
+
+	e := endpoint.CreateGuildSticker(r.guildID)
+	resp, err := r.client.doReqWithHeader(ctx, e, customPayload(b, contentType), reasonHeader(reason))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, apiError(resp)
+	}
+
+	var s Sticker
+	if err = json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// stickerMultipart generates the multipart body of a sticker upload, with
+// the given settings as form fields. It returns the raw generated body
+// along the content type of this body.
+func stickerMultipart(settings *sticker.Settings, file *File) ([]byte, string, error) {
+	var contentType string
+	switch strings.ToLower(path.Ext(file.name)) {
+	case ".png", ".apng":
+		contentType = "image/png"
+	case ".gif":
+		contentType = "image/gif"
+	case ".json":
+		contentType = "application/json"
+	default:
+		return nil, "", fmt.Errorf("unsupported sticker file %q: must be a PNG, APNG, GIF or Lottie JSON file", file.name)
+	}
+
+	// Optional fields are marshaled as their values.
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return nil, "", err
+	}
+	var fields map[string]string
+	if err = json.Unmarshal(b, &fields); err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err = w.WriteField(name, fields[name]); err != nil {
+			return nil, "", err
+		}
+	}
+
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, file.name))
+	h.Set("Content-Type", contentType)
+	pw, err := w.CreatePart(h)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Read one more byte than allowed to detect files that are too large.
+	n, err := io.Copy(pw, io.LimitReader(file.reader, MaxStickerSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if n > MaxStickerSize {
+		return nil, "", ErrImageTooLarge
+	}
+
+	if err = w.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+// ModifySticker is like ModifyStickerWithReason but with no particular reason.
+func (r *GuildResource) ModifySticker(ctx context.Context, stickerID string, settings *sticker.Settings) (*Sticker, error) {
+	return r.ModifyStickerWithReason(ctx, stickerID, settings, "")
+}
+
+// ModifyStickerWithReason modifies the given sticker of the guild. Requires the
+// 'MANAGE_GUILD_EXPRESSIONS' permission. Fires a Guild Stickers Update Gateway event.
+// The given reason will be set in the audit log entry for this action.
+func (r *GuildResource) ModifyStickerWithReason(ctx context.Context, stickerID string, settings *sticker.Settings, reason string) (*Sticker, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	e := endpoint.ModifyGuildSticker(r.guildID, stickerID)
+	resp, err := r.client.doReqWithHeader(ctx, e, jsonPayload(b), reasonHeader(reason))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var s Sticker
+	if err = json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// DeleteSticker is like DeleteStickerWithReason but with no particular reason.
+func (r *GuildResource) DeleteSticker(ctx context.Context, stickerID string) error {
+	return r.DeleteStickerWithReason(ctx, stickerID, "")
+}
+
+// DeleteStickerWithReason deletes the given sticker of the guild. Requires the
+// 'MANAGE_GUILD_EXPRESSIONS' permission. Fires a Guild Stickers Update Gateway event.
+// The given reason will be set in the audit log entry for this action.
+func (r *GuildResource) DeleteStickerWithReason(ctx context.Context, stickerID, reason string) error {
+	e := endpoint.DeleteGuildSticker(r.guildID, stickerID)
+	resp, err := r.client.doReqWithHeader(ctx, e, nil, reasonHeader(reason))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return apiError(resp)
+	}
+	return nil
+}
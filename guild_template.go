@@ -0,0 +1,187 @@
+package harmony
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/skwair/harmony/internal/endpoint"
+)
+
+// GuildTemplate is a snapshot of the structure of a guild, its roles, channels
+// and settings, that can be used to create new guilds.
+type GuildTemplate struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Number of times the template was used to create a guild.
+	UsageCount int       `json:"usage_count"`
+	CreatorID  string    `json:"creator_id"`
+	Creator    *User     `json:"creator"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	// ID of the guild the template was created from.
+	SourceGuildID string `json:"source_guild_id"`
+	// Snapshot of the guild the template was created from, without IDs.
+	SerializedSourceGuild *Guild `json:"serialized_source_guild"`
+	// IsDirty is true if the source guild changed since the template was last synced.
+	IsDirty bool `json:"is_dirty"`
+}
+
+// TemplateResource is a resource that allows to perform various actions on a Discord guild template.
+// Create one with Client.Template.
+type TemplateResource struct {
+	code   string
+	client *Client
+}
+
+// Template returns a new template resource to use the guild template with the given code.
+func (c *Client) Template(code string) *TemplateResource {
+	return &TemplateResource{code: code, client: c}
+}
+
+// Get returns the guild template.
+func (r *TemplateResource) Get(ctx context.Context) (*GuildTemplate, error) {
+	e := endpoint.GetGuildTemplate(r.code)
+	resp, err := r.client.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var t GuildTemplate
+	if err = json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CreateGuild creates a new guild with the given name from the template. Like
+// Client.NewGuild, this endpoint can be used only by bots in less than 10 guilds
+// and the bot is the owner of the created guild.
+// Returns the created guild on success. Fires a Guild Create Gateway event.
+func (r *TemplateResource) CreateGuild(ctx context.Context, name string) (*Guild, error) {
+	st := struct {
+		Name string `json:"name"`
+	}{
+		Name: name,
+	}
+	b, err := json.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+
+	e := endpoint.CreateGuildFromTemplate(r.code)
+	resp, err := r.client.doReq(ctx, e, jsonPayload(b))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, apiError(resp)
+	}
+
+	var g Guild
+	if err = json.NewDecoder(resp.Body).Decode(&g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// Templates returns the templates of the guild. Requires the 'MANAGE_GUILD' permission.
+func (r *GuildResource) Templates(ctx context.Context) ([]GuildTemplate, error) {
+	e := endpoint.GetGuildTemplates(r.guildID)
+	resp, err := r.client.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var templates []GuildTemplate
+	if err = json.NewDecoder(resp.Body).Decode(&templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// NewTemplate creates a template of the guild with the given name and
+// description, which can be empty. Requires the 'MANAGE_GUILD' permission.
+// Returns the created template on success.
+func (r *GuildResource) NewTemplate(ctx context.Context, name, description string) (*GuildTemplate, error) {
+	st := struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+	}{
+		Name:        name,
+		Description: description,
+	}
+	b, err := json.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+
+	e := endpoint.CreateGuildTemplate(r.guildID)
+	resp, err := r.client.doReq(ctx, e, jsonPayload(b))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var t GuildTemplate
+	if err = json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SyncTemplate updates the template with the given code to the current
+// structure of the guild. Requires the 'MANAGE_GUILD' permission.
+// Returns the updated template on success.
+func (r *GuildResource) SyncTemplate(ctx context.Context, code string) (*GuildTemplate, error) {
+	e := endpoint.SyncGuildTemplate(r.guildID, code)
+	resp, err := r.client.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var t GuildTemplate
+	if err = json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DeleteTemplate deletes the template with the given code.
+// Requires the 'MANAGE_GUILD' permission.
+func (r *GuildResource) DeleteTemplate(ctx context.Context, code string) error {
+	e := endpoint.DeleteGuildTemplate(r.guildID, code)
+	resp, err := r.client.doReq(ctx, e, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return apiError(resp)
+	}
+	return nil
+}
@@ -0,0 +1,722 @@
+package guildsync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/guild"
+	"github.com/skwair/harmony/permission"
+	"github.com/skwair/harmony/role"
+)
+
+// DefaultReason is the audit log reason of the changes applied by plans.
+const DefaultReason = "Guild sync"
+
+// Action is the action of a change.
+type Action int
+
+// Actions of changes.
+const (
+	ActionCreate Action = iota
+	ActionUpdate
+	ActionDelete
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionCreate:
+		return "create"
+	case ActionUpdate:
+		return "update"
+	case ActionDelete:
+		return "delete"
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// Kinds of objects changes apply to.
+const (
+	KindSettings = "settings"
+	KindRole     = "role"
+	KindChannel  = "channel"
+	KindEmoji    = "emoji"
+)
+
+// Change is a change to apply to a guild so it matches a snapshot.
+type Change struct {
+	Action Action
+	// Kind of the object the change applies to, one of the Kind* constants.
+	Kind string
+	// Name of the object, or reference for channels.
+	Name string
+	// Details describe what is modified by updates, such as `topic: "a" -> "b"`.
+	Details []string
+
+	apply func(ctx context.Context, a *applier) error
+}
+
+func (c *Change) String() string {
+	s := fmt.Sprintf("%s %s %q", c.Action, c.Kind, c.Name)
+	if len(c.Details) > 0 {
+		s += " (" + strings.Join(c.Details, ", ") + ")"
+	}
+	return s
+}
+
+// Plan is the set of changes to apply to a guild so it matches a snapshot.
+// Create one with NewPlan.
+type Plan struct {
+	// Changes, in the order they are applied.
+	Changes []Change
+
+	applier *applier
+}
+
+// PlanOption is a function that configures a Plan.
+type PlanOption func(*planOptions)
+
+type planOptions struct {
+	prune  bool
+	reason string
+}
+
+// WithPrune sets whether roles, channels and emojis of the guild that are not
+// in the snapshot are deleted. They are kept by default.
+func WithPrune(yes bool) PlanOption {
+	return func(o *planOptions) {
+		o.prune = yes
+	}
+}
+
+// WithReason sets the audit log reason of the changes. Defaults to DefaultReason.
+func WithReason(reason string) PlanOption {
+	return func(o *planOptions) {
+		o.reason = reason
+	}
+}
+
+// NewPlan compares the given snapshot with the current structure of the given
+// guild and returns the changes to apply so they match. The guild is not
+// modified until the plan is applied.
+func NewPlan(ctx context.Context, c *harmony.Client, guildID string, snap *Snapshot, opts ...PlanOption) (*Plan, error) {
+	o := &planOptions{reason: DefaultReason}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if len(snap.Roles) == 0 || snap.Roles[0].Name != EveryoneRole {
+		return nil, fmt.Errorf("guildsync: the first role of the snapshot must be %s", EveryoneRole)
+	}
+
+	g, err := c.Guild(guildID).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get guild: %w", err)
+	}
+	roles, err := c.Guild(guildID).Roles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get roles: %w", err)
+	}
+	channels, err := c.Guild(guildID).Channels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get channels: %w", err)
+	}
+	emojis, err := c.Guild(guildID).Emojis(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get emojis: %w", err)
+	}
+
+	p := &planner{
+		snap:     snap,
+		guildID:  guildID,
+		guild:    g,
+		roles:    sortRoles(guildID, roles),
+		channels: sortChannels(channels),
+		emojis:   emojis,
+		prune:    o.prune,
+		applier: &applier{
+			guild:      c.Guild(guildID),
+			client:     c,
+			reason:     o.reason,
+			roles:      make(map[string]string),
+			channels:   make(map[channelKey]string),
+			roleIDs:    make([]string, len(snap.Roles)),
+			channelIDs: make([]string, len(snap.Channels)),
+		},
+	}
+	p.names = newNames(guildID, p.roles, p.channels)
+
+	p.planRoles()
+	p.planChannels()
+	p.planEmojis()
+	p.planSettings()
+	if p.prune {
+		p.planDeletions()
+	}
+
+	return &Plan{Changes: p.changes, applier: p.applier}, nil
+}
+
+// Empty returns whether there is no change to apply.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// String returns a human readable description of the plan, one change per line.
+func (p *Plan) String() string {
+	if p.Empty() {
+		return "no changes\n"
+	}
+
+	var b strings.Builder
+	for i := range p.Changes {
+		b.WriteString(p.Changes[i].String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Apply applies the changes of the plan, in order. It stops at the first change
+// that can not be applied, leaving the guild partially synchronized. Computing
+// and applying a new plan resumes the synchronization.
+func (p *Plan) Apply(ctx context.Context) error {
+	for i := range p.Changes {
+		if err := p.Changes[i].apply(ctx, p.applier); err != nil {
+			return fmt.Errorf("could not %s: %w", p.Changes[i].String(), err)
+		}
+	}
+	return nil
+}
+
+// channelKey identifies a channel across guilds.
+type channelKey struct {
+	ref string
+	typ channel.Type
+}
+
+// applier applies changes, resolving the names used in
+// snapshots to the IDs of the target guild.
+type applier struct {
+	guild  *harmony.GuildResource
+	client *harmony.Client
+	reason string
+
+	// IDs by name. When names are duplicated, the first role or
+	// channel of the snapshot with the name is used.
+	roles    map[string]string
+	channels map[channelKey]string
+	// IDs of the roles and channels of the snapshot,
+	// by index, set as they are matched or created.
+	roleIDs    []string
+	channelIDs []string
+}
+
+func (a *applier) setRole(i int, name, id string) {
+	a.roleIDs[i] = id
+	if _, ok := a.roles[name]; !ok {
+		a.roles[name] = id
+	}
+}
+
+func (a *applier) setChannel(i int, key channelKey, id string) {
+	a.channelIDs[i] = id
+	if _, ok := a.channels[key]; !ok {
+		a.channels[key] = id
+	}
+}
+
+// overwrites resolves the given overwrites to permission overwrites.
+func (a *applier) overwrites(overwrites []Overwrite) ([]permission.Overwrite, error) {
+	perms := make([]permission.Overwrite, 0, len(overwrites))
+	for _, o := range overwrites {
+		po := permission.Overwrite{Allow: o.Allow, Deny: o.Deny}
+		if o.Member != "" {
+			po.Type, po.ID = "member", o.Member
+		} else {
+			id, ok := a.roles[o.Role]
+			if !ok {
+				return nil, fmt.Errorf("unknown role %q", o.Role)
+			}
+			po.Type, po.ID = "role", id
+		}
+		perms = append(perms, po)
+	}
+	return perms, nil
+}
+
+// channelRef resolves the given channel reference to the ID of a channel
+// of the given type, or to an empty string if the reference is empty.
+func (a *applier) channelRef(ref string, typ channel.Type) (string, error) {
+	if ref == "" {
+		return "", nil
+	}
+	id, ok := a.channels[channelKey{ref: ref, typ: typ}]
+	if !ok {
+		return "", fmt.Errorf("unknown channel %q", ref)
+	}
+	return id, nil
+}
+
+// planner computes the changes of a plan.
+type planner struct {
+	snap     *Snapshot
+	guildID  string
+	guild    *harmony.Guild
+	roles    []harmony.Role
+	channels []harmony.Channel
+	emojis   []harmony.Emoji
+	names    *names
+	prune    bool
+
+	applier *applier
+	changes []Change
+
+	// Indexes of the roles and channels of the guild
+	// that match one in the snapshot.
+	matchedRoles    map[int]bool
+	matchedChannels map[int]bool
+}
+
+func (p *planner) add(c Change) {
+	p.changes = append(p.changes, c)
+}
+
+func (p *planner) planRoles() {
+	p.matchedRoles = make(map[int]bool)
+	byName := make(map[string][]int)
+	for i := range p.roles {
+		name := exportRole(p.guildID, &p.roles[i]).Name
+		byName[name] = append(byName[name], i)
+	}
+
+	// Whether the roles of the guild are in the same order as in the snapshot.
+	reorder := false
+	last := -1
+	for i := range p.snap.Roles {
+		i, want := i, p.snap.Roles[i]
+
+		candidates := byName[want.Name]
+		if len(candidates) == 0 {
+			reorder = true
+			p.add(Change{
+				Action: ActionCreate,
+				Kind:   KindRole,
+				Name:   want.Name,
+				apply: func(ctx context.Context, a *applier) error {
+					r, err := a.guild.NewRoleWithReason(ctx, roleSettings(&want, nil), a.reason)
+					if err != nil {
+						return err
+					}
+					a.setRole(i, want.Name, r.ID)
+					return nil
+				},
+			})
+			continue
+		}
+
+		idx := candidates[0]
+		byName[want.Name] = candidates[1:]
+		p.matchedRoles[idx] = true
+		if idx < last {
+			reorder = true
+		}
+		last = idx
+
+		have := &p.roles[idx]
+		p.applier.setRole(i, want.Name, have.ID)
+		if details := roleDetails(have, &want); len(details) > 0 {
+			id := have.ID
+			p.add(Change{
+				Action:  ActionUpdate,
+				Kind:    KindRole,
+				Name:    want.Name,
+				Details: details,
+				apply: func(ctx context.Context, a *applier) error {
+					_, err := a.guild.ModifyRoleWithReason(ctx, id, roleSettings(&want, have), a.reason)
+					return err
+				},
+			})
+		}
+	}
+
+	if reorder && len(p.snap.Roles) > 1 {
+		p.add(Change{
+			Action:  ActionUpdate,
+			Kind:    KindRole,
+			Name:    "*",
+			Details: []string{"positions"},
+			apply: func(ctx context.Context, a *applier) error {
+				// The @everyone role is always the lowest one and can not be moved.
+				var pos []harmony.RolePosition
+				for i := 1; i < len(a.roleIDs); i++ {
+					pos = append(pos, harmony.RolePosition{ID: a.roleIDs[i], Position: i})
+				}
+				_, err := a.guild.ModifyRolePositions(ctx, pos)
+				return err
+			},
+		})
+	}
+}
+
+func roleDetails(have *harmony.Role, want *Role) []string {
+	var details []string
+	if have.Color != want.Color {
+		details = append(details, fmt.Sprintf("color: %#06x -> %#06x", have.Color, want.Color))
+	}
+	if have.Hoist != want.Hoist {
+		details = append(details, fmt.Sprintf("hoist: %t -> %t", have.Hoist, want.Hoist))
+	}
+	if have.Mentionable != want.Mentionable {
+		details = append(details, fmt.Sprintf("mentionable: %t -> %t", have.Mentionable, want.Mentionable))
+	}
+	if have.Permissions != want.Permissions {
+		details = append(details, fmt.Sprintf("permissions: %d -> %d", have.Permissions, want.Permissions))
+	}
+	return details
+}
+
+// roleSettings returns the settings to create the given role, or to update
+// the role have so it matches the given one if have is not nil.
+func roleSettings(want *Role, have *harmony.Role) *role.Settings {
+	s := role.NewSettings()
+	if have == nil {
+		role.WithName(want.Name)(s)
+	}
+	if have == nil || have.Color != want.Color {
+		role.WithColor(want.Color)(s)
+	}
+	if have == nil || have.Hoist != want.Hoist {
+		role.WithHoist(want.Hoist)(s)
+	}
+	if have == nil || have.Mentionable != want.Mentionable {
+		role.WithMentionable(want.Mentionable)(s)
+	}
+	if have == nil || have.Permissions != want.Permissions {
+		role.WithPermissions(want.Permissions)(s)
+	}
+	return s
+}
+
+func (p *planner) planChannels() {
+	p.matchedChannels = make(map[int]bool)
+	byKey := make(map[channelKey][]int)
+	for i := range p.channels {
+		ch := &p.channels[i]
+		key := channelKey{ref: ref(p.names.parent(ch), ch.Name), typ: ch.Type}
+		byKey[key] = append(byKey[key], i)
+	}
+
+	reorder := false
+	last := -1
+	for i := range p.snap.Channels {
+		i, want := i, p.snap.Channels[i]
+		key := channelKey{ref: want.Ref(), typ: want.Type}
+
+		candidates := byKey[key]
+		if len(candidates) == 0 {
+			reorder = true
+			p.add(Change{
+				Action: ActionCreate,
+				Kind:   KindChannel,
+				Name:   want.Ref(),
+				apply: func(ctx context.Context, a *applier) error {
+					s, err := a.channelSettings(&want, nil)
+					if err != nil {
+						return err
+					}
+					ch, err := a.guild.NewChannelWithReason(ctx, s, a.reason)
+					if err != nil {
+						return err
+					}
+					a.setChannel(i, key, ch.ID)
+					return nil
+				},
+			})
+			continue
+		}
+
+		idx := candidates[0]
+		byKey[key] = candidates[1:]
+		p.matchedChannels[idx] = true
+		if idx < last {
+			reorder = true
+		}
+		last = idx
+
+		have := p.names.exportChannel(&p.channels[idx])
+		id := p.channels[idx].ID
+		p.applier.setChannel(i, key, id)
+		if details := channelDetails(&have, &want); len(details) > 0 {
+			p.add(Change{
+				Action:  ActionUpdate,
+				Kind:    KindChannel,
+				Name:    want.Ref(),
+				Details: details,
+				apply: func(ctx context.Context, a *applier) error {
+					s, err := a.channelSettings(&want, &have)
+					if err != nil {
+						return err
+					}
+					_, err = a.client.Channel(id).ModifyWithReason(ctx, s, a.reason)
+					return err
+				},
+			})
+		}
+	}
+
+	if reorder && len(p.snap.Channels) > 0 {
+		p.add(Change{
+			Action:  ActionUpdate,
+			Kind:    KindChannel,
+			Name:    "*",
+			Details: []string{"positions"},
+			apply: func(ctx context.Context, a *applier) error {
+				pos := make([]harmony.ChannelPosition, len(a.channelIDs))
+				for i, id := range a.channelIDs {
+					pos[i] = harmony.ChannelPosition{ID: id, Position: i}
+				}
+				return a.guild.ModifyChannelPosition(ctx, pos)
+			},
+		})
+	}
+}
+
+func channelDetails(have, want *Channel) []string {
+	var details []string
+	if have.Topic != want.Topic {
+		details = append(details, fmt.Sprintf("topic: %q -> %q", have.Topic, want.Topic))
+	}
+	if have.NSFW != want.NSFW {
+		details = append(details, fmt.Sprintf("nsfw: %t -> %t", have.NSFW, want.NSFW))
+	}
+	if have.Bitrate != want.Bitrate {
+		details = append(details, fmt.Sprintf("bitrate: %d -> %d", have.Bitrate, want.Bitrate))
+	}
+	if have.UserLimit != want.UserLimit {
+		details = append(details, fmt.Sprintf("user limit: %d -> %d", have.UserLimit, want.UserLimit))
+	}
+	if have.RateLimitPerUser != want.RateLimitPerUser {
+		details = append(details, fmt.Sprintf("rate limit per user: %d -> %d", have.RateLimitPerUser, want.RateLimitPerUser))
+	}
+	if !sameOverwrites(have.Overwrites, want.Overwrites) {
+		details = append(details, "permission overwrites")
+	}
+	return details
+}
+
+func sameOverwrites(a, b []Overwrite) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	b = append([]Overwrite(nil), b...)
+	sort.Slice(b, func(i, j int) bool {
+		if b[i].Role != b[j].Role {
+			return b[i].Role < b[j].Role
+		}
+		return b[i].Member < b[j].Member
+	})
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// channelSettings returns the settings to create the given channel, or to
+// update the channel have so it matches the given one if have is not nil.
+func (a *applier) channelSettings(want, have *Channel) (*channel.Settings, error) {
+	s := channel.NewSettings()
+	if have == nil {
+		channel.WithName(want.Name)(s)
+		channel.WithType(want.Type)(s)
+		parent, err := a.channelRef(want.Parent, channel.TypeGuildCategory)
+		if err != nil {
+			return nil, err
+		}
+		if parent != "" {
+			channel.WithParent(parent)(s)
+		}
+	}
+
+	if (have == nil && want.Topic != "") || (have != nil && have.Topic != want.Topic) {
+		channel.WithTopic(want.Topic)(s)
+	}
+	if (have == nil && want.NSFW) || (have != nil && have.NSFW != want.NSFW) {
+		channel.WithNSFW(want.NSFW)(s)
+	}
+	if (have == nil && want.Bitrate != 0) || (have != nil && have.Bitrate != want.Bitrate) {
+		channel.WithBitrate(want.Bitrate)(s)
+	}
+	if (have == nil && want.UserLimit != 0) || (have != nil && have.UserLimit != want.UserLimit) {
+		channel.WithUserLimit(want.UserLimit)(s)
+	}
+	if (have == nil && want.RateLimitPerUser != 0) || (have != nil && have.RateLimitPerUser != want.RateLimitPerUser) {
+		channel.WithRateLimitPerUser(want.RateLimitPerUser)(s)
+	}
+	if (have == nil && len(want.Overwrites) > 0) || (have != nil && !sameOverwrites(have.Overwrites, want.Overwrites)) {
+		perms, err := a.overwrites(want.Overwrites)
+		if err != nil {
+			return nil, err
+		}
+		channel.WithPermissions(perms)(s)
+	}
+
+	return s, nil
+}
+
+func (p *planner) planEmojis() {
+	existing := make(map[string]bool)
+	for i := range p.emojis {
+		existing[p.emojis[i].Name] = true
+	}
+
+	for _, e := range p.snap.Emojis {
+		if existing[e.Name] {
+			continue
+		}
+
+		e := e
+		p.add(Change{
+			Action: ActionCreate,
+			Kind:   KindEmoji,
+			Name:   e.Name,
+			apply: func(ctx context.Context, a *applier) error {
+				_, err := a.guild.NewEmojiWithReason(ctx, e.Name, e.Image, nil, a.reason)
+				return err
+			},
+		})
+	}
+}
+
+func (p *planner) planSettings() {
+	want := p.snap.Settings
+	g := p.guild
+	afk := p.names.channelRef(g.AFKChannelID)
+	system := p.names.channelRef(g.SystemChannelID)
+
+	var details []string
+	if g.Name != want.Name {
+		details = append(details, fmt.Sprintf("name: %q -> %q", g.Name, want.Name))
+	}
+	if g.VerificationLevel != want.VerificationLevel {
+		details = append(details, fmt.Sprintf("verification level: %d -> %d", g.VerificationLevel, want.VerificationLevel))
+	}
+	if g.DefaultMessageNotifications != want.DefaultMessageNotifications {
+		details = append(details, fmt.Sprintf("default message notifications: %d -> %d", g.DefaultMessageNotifications, want.DefaultMessageNotifications))
+	}
+	if g.ExplicitContentFilter != want.ExplicitContentFilter {
+		details = append(details, fmt.Sprintf("explicit content filter: %d -> %d", g.ExplicitContentFilter, want.ExplicitContentFilter))
+	}
+	if afk != want.AFKChannel {
+		details = append(details, fmt.Sprintf("AFK channel: %q -> %q", afk, want.AFKChannel))
+	}
+	if g.AFKTimeout != want.AFKTimeout {
+		details = append(details, fmt.Sprintf("AFK timeout: %d -> %d", g.AFKTimeout, want.AFKTimeout))
+	}
+	if system != want.SystemChannel {
+		details = append(details, fmt.Sprintf("system channel: %q -> %q", system, want.SystemChannel))
+	}
+	if want.PreferredLocale != "" && g.PreferredLocale != want.PreferredLocale {
+		details = append(details, fmt.Sprintf("preferred locale: %q -> %q", g.PreferredLocale, want.PreferredLocale))
+	}
+	if len(details) == 0 {
+		return
+	}
+
+	p.add(Change{
+		Action:  ActionUpdate,
+		Kind:    KindSettings,
+		Name:    want.Name,
+		Details: details,
+		apply: func(ctx context.Context, a *applier) error {
+			afkID, err := a.channelRef(want.AFKChannel, channel.TypeGuildVoice)
+			if err != nil {
+				return err
+			}
+			systemID, err := a.channelRef(want.SystemChannel, channel.TypeGuildText)
+			if err != nil {
+				return err
+			}
+
+			opts := []guild.Setting{
+				guild.WithName(want.Name),
+				guild.WithVerificationLevel(want.VerificationLevel),
+				guild.WithDefaultMessageNotifications(want.DefaultMessageNotifications),
+				guild.WithExplicitContentFilter(want.ExplicitContentFilter),
+				guild.WithAFKChannel(afkID),
+				guild.WithAFKTimeout(guild.AFKTimeout(want.AFKTimeout)),
+				guild.WithSystemChannel(systemID),
+			}
+			if want.PreferredLocale != "" {
+				opts = append(opts, guild.WithPreferredLocale(want.PreferredLocale))
+			}
+			_, err = a.guild.ModifyWithReason(ctx, guild.NewSettings(opts...), a.reason)
+			return err
+		},
+	})
+}
+
+// planDeletions deletes the channels, roles and emojis of the guild that are
+// not in the snapshot. Channels are deleted before categories so they are not
+// moved out of them in the meantime.
+func (p *planner) planDeletions() {
+	for _, categories := range []bool{false, true} {
+		for i := range p.channels {
+			ch := &p.channels[i]
+			if p.matchedChannels[i] || (ch.Type == channel.TypeGuildCategory) != categories {
+				continue
+			}
+			id := ch.ID
+			p.add(Change{
+				Action: ActionDelete,
+				Kind:   KindChannel,
+				Name:   ref(p.names.parent(ch), ch.Name),
+				apply: func(ctx context.Context, a *applier) error {
+					_, err := a.client.Channel(id).DeleteWithReason(ctx, a.reason)
+					return err
+				},
+			})
+		}
+	}
+
+	for i := range p.roles {
+		r := &p.roles[i]
+		if p.matchedRoles[i] || r.ID == p.guildID {
+			continue
+		}
+		id := r.ID
+		p.add(Change{
+			Action: ActionDelete,
+			Kind:   KindRole,
+			Name:   r.Name,
+			apply: func(ctx context.Context, a *applier) error {
+				return a.guild.DeleteRoleWithReason(ctx, id, a.reason)
+			},
+		})
+	}
+
+	wanted := make(map[string]bool)
+	for _, e := range p.snap.Emojis {
+		wanted[e.Name] = true
+	}
+	for i := range p.emojis {
+		e := &p.emojis[i]
+		if wanted[e.Name] || e.Managed {
+			continue
+		}
+		id := e.ID
+		p.add(Change{
+			Action: ActionDelete,
+			Kind:   KindEmoji,
+			Name:   e.Name,
+			apply: func(ctx context.Context, a *applier) error {
+				return a.guild.DeleteEmojiWithReason(ctx, id, a.reason)
+			},
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package guildsync
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/harmonytest"
+	"github.com/skwair/harmony/permission"
+)
+
+const guildID = "1"
+
+// newServer returns a test server serving the structure of a guild with
+// a category holding a text channel, a voice channel and a single role.
+func newServer() *harmonytest.Server {
+	srv := harmonytest.NewServer()
+
+	srv.Handle(http.MethodGet, "/guilds/:guild", func(w http.ResponseWriter, r *http.Request) {
+		harmonytest.WriteJSON(w, http.StatusOK, &harmony.Guild{ID: guildID, Name: "Guild", AFKTimeout: 300})
+	})
+	srv.Handle(http.MethodGet, "/guilds/:guild/roles", func(w http.ResponseWriter, r *http.Request) {
+		harmonytest.WriteJSON(w, http.StatusOK, []harmony.Role{
+			{ID: "2", Name: "mod", Position: 1, Color: 0xff0000},
+			{ID: guildID, Name: "@everyone", Position: 0, Permissions: 1024},
+		})
+	})
+	srv.Handle(http.MethodGet, "/guilds/:guild/channels", func(w http.ResponseWriter, r *http.Request) {
+		harmonytest.WriteJSON(w, http.StatusOK, []harmony.Channel{
+			{ID: "11", Name: "chat", Type: channel.TypeGuildText, ParentID: "10", Topic: "hi"},
+			{ID: "12", Name: "old", Type: channel.TypeGuildVoice, Position: 1},
+			{ID: "10", Name: "general", Type: channel.TypeGuildCategory},
+		})
+	})
+	srv.Handle(http.MethodGet, "/guilds/:guild/emojis", func(w http.ResponseWriter, r *http.Request) {
+		harmonytest.WriteJSON(w, http.StatusOK, []harmony.Emoji{})
+	})
+
+	return srv
+}
+
+func TestNewPlan(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		edit     func(s *Snapshot)
+		opts     []PlanOption
+		expected string
+	}{
+		{
+			name:     "in sync",
+			edit:     func(s *Snapshot) {},
+			expected: "no changes\n",
+		},
+		{
+			name:     "update role",
+			edit:     func(s *Snapshot) { s.Roles[1].Color = 0x00ff00 },
+			expected: "update role \"mod\" (color: 0xff0000 -> 0x00ff00)\n",
+		},
+		{
+			name:     "create role",
+			edit:     func(s *Snapshot) { s.Roles = append(s.Roles, Role{Name: "admin"}) },
+			expected: "create role \"admin\"\nupdate role \"*\" (positions)\n",
+		},
+		{
+			name:     "update channel",
+			edit:     func(s *Snapshot) { s.Channels[1].Topic = "bye" },
+			expected: "update channel \"general/chat\" (topic: \"hi\" -> \"bye\")\n",
+		},
+		{
+			name: "update settings",
+			edit: func(s *Snapshot) {
+				s.Settings.Name = "Other"
+				s.Settings.AFKChannel = "old"
+			},
+			expected: "update settings \"Other\" (name: \"Guild\" -> \"Other\", AFK channel: \"\" -> \"old\")\n",
+		},
+		{
+			name:     "missing channel kept",
+			edit:     func(s *Snapshot) { s.Channels = s.Channels[:2] },
+			expected: "no changes\n",
+		},
+		{
+			name: "missing channel and role pruned",
+			edit: func(s *Snapshot) {
+				s.Channels = s.Channels[:2]
+				s.Roles = s.Roles[:1]
+			},
+			opts:     []PlanOption{WithPrune(true)},
+			expected: "delete channel \"old\"\ndelete role \"mod\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			snap, err := Export(ctx, c, guildID)
+			if err != nil {
+				t.Fatalf("could not export guild: %v", err)
+			}
+			tt.edit(snap)
+
+			plan, err := NewPlan(ctx, c, guildID, snap, tt.opts...)
+			if err != nil {
+				t.Fatalf("could not compute plan: %v", err)
+			}
+			if s := plan.String(); s != tt.expected {
+				t.Errorf("expected plan:\n%sgot:\n%s", tt.expected, s)
+			}
+		})
+	}
+}
+
+func TestNewPlanEveryoneRole(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	snap := &Snapshot{Roles: []Role{{Name: "mod"}}}
+	if _, err = NewPlan(context.Background(), c, guildID, snap); err == nil {
+		t.Error("expected an error for a snapshot not starting with the @everyone role")
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	srv.Handle(http.MethodPost, "/guilds/:guild/roles", func(w http.ResponseWriter, r *http.Request) {
+		harmonytest.WriteJSON(w, http.StatusOK, &harmony.Role{ID: "3", Name: "admin"})
+	})
+	srv.Handle(http.MethodPatch, "/guilds/:guild/roles", func(w http.ResponseWriter, r *http.Request) {
+		harmonytest.WriteJSON(w, http.StatusOK, []harmony.Role{})
+	})
+	srv.Handle(http.MethodPost, "/guilds/:guild/channels", func(w http.ResponseWriter, r *http.Request) {
+		harmonytest.WriteJSON(w, http.StatusCreated, &harmony.Channel{ID: "13", Name: "news"})
+	})
+	srv.Handle(http.MethodPatch, "/guilds/:guild/channels", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	snap, err := Export(ctx, c, guildID)
+	if err != nil {
+		t.Fatalf("could not export guild: %v", err)
+	}
+	snap.Roles = append(snap.Roles, Role{Name: "admin"})
+	snap.Channels = append(snap.Channels, Channel{
+		Name:       "news",
+		Type:       channel.TypeGuildText,
+		Parent:     "general",
+		Overwrites: []Overwrite{{Role: "admin", Allow: 2048}},
+	})
+
+	plan, err := NewPlan(ctx, c, guildID, snap, WithReason("sync"))
+	if err != nil {
+		t.Fatalf("could not compute plan: %v", err)
+	}
+	srv.ResetRequests()
+	if err = plan.Apply(ctx); err != nil {
+		t.Fatalf("could not apply plan: %v", err)
+	}
+
+	req, err := srv.WaitRequest(ctx, harmonytest.MatchRoute(http.MethodPost, "/guilds/:guild/roles"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason := req.Header.Get("X-Audit-Log-Reason"); reason != "sync" {
+		t.Errorf("expected audit log reason to be %q, got %q", "sync", reason)
+	}
+
+	// The new channel refers to the category and role by their ID.
+	req, err = srv.WaitRequest(ctx, harmonytest.MatchRoute(http.MethodPost, "/guilds/:guild/channels"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created struct {
+		ParentID   string                 `json:"parent_id"`
+		Overwrites []permission.Overwrite `json:"permission_overwrites"`
+	}
+	if err = req.Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if created.ParentID != "10" {
+		t.Errorf("expected parent ID to be 10, got %q", created.ParentID)
+	}
+	if len(created.Overwrites) != 1 || created.Overwrites[0].ID != "3" || created.Overwrites[0].Type != "role" {
+		t.Errorf("expected a single overwrite for role 3, got %+v", created.Overwrites)
+	}
+
+	req, err = srv.WaitRequest(ctx, harmonytest.MatchRoute(http.MethodPatch, "/guilds/:guild/roles"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roles []harmony.RolePosition
+	if err = req.Decode(&roles); err != nil {
+		t.Fatal(err)
+	}
+	expected := []harmony.RolePosition{{ID: "2", Position: 1}, {ID: "3", Position: 2}}
+	if !reflect.DeepEqual(roles, expected) {
+		t.Errorf("expected role positions to be %+v, got %+v", expected, roles)
+	}
+}
@@ -0,0 +1,366 @@
+/*
+Package guildsync exports the structure of a guild (its settings, roles, channels,
+permission overwrites and emojis) to a declarative snapshot, and re-applies
+snapshots to guilds. It can be used to back up a guild or to replicate its
+structure to other guilds:
+
+	snap, err := guildsync.Export(ctx, client, sourceGuildID)
+	if err != nil {
+		// Handle error.
+	}
+	err = snap.Save(f)
+
+Applying a snapshot is done in two steps. First, a Plan is computed by comparing
+the snapshot with the current structure of the target guild. It can be reviewed,
+then applied:
+
+	snap, err := guildsync.Load(f)
+	if err != nil {
+		// Handle error.
+	}
+	plan, err := guildsync.NewPlan(ctx, client, targetGuildID, snap)
+	if err != nil {
+		// Handle error.
+	}
+	fmt.Print(plan)
+	err = plan.Apply(ctx)
+
+Since IDs differ between guilds, roles are identified by their name and channels by
+their name, type and category. Members and messages are never exported. Roles that
+are managed by bots or integrations can not be created and are not exported.
+*/
+package guildsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/guild"
+)
+
+// Version is the version of the snapshot format written by this package.
+const Version = 1
+
+// EveryoneRole is the name of the @everyone role in snapshots.
+const EveryoneRole = "@everyone"
+
+// Snapshot is the declarative description of the structure of a guild.
+type Snapshot struct {
+	Version  int      `json:"version"`
+	Settings Settings `json:"settings"`
+	// Roles of the guild, from the lowest to the highest. The first
+	// one is always the @everyone role.
+	Roles []Role `json:"roles"`
+	// Channels of the guild, categories first, then in the order they appear in.
+	Channels []Channel `json:"channels"`
+	Emojis   []Emoji   `json:"emojis,omitempty"`
+}
+
+// Settings are the settings of a guild.
+type Settings struct {
+	Name                        string                         `json:"name"`
+	VerificationLevel           guild.VerificationLevel        `json:"verification_level"`
+	DefaultMessageNotifications guild.DefaultNotificationLevel `json:"default_message_notifications"`
+	ExplicitContentFilter       guild.ExplicitContentFilter    `json:"explicit_content_filter"`
+	// AFKChannel is the reference of a voice channel, see Channel.Ref.
+	AFKChannel string `json:"afk_channel,omitempty"`
+	AFKTimeout int    `json:"afk_timeout"`
+	// SystemChannel is the reference of a text channel, see Channel.Ref.
+	SystemChannel   string `json:"system_channel,omitempty"`
+	PreferredLocale string `json:"preferred_locale,omitempty"`
+}
+
+// Role is a role of a guild.
+type Role struct {
+	Name        string `json:"name"`
+	Color       int    `json:"color,omitempty"`
+	Hoist       bool   `json:"hoist,omitempty"`
+	Mentionable bool   `json:"mentionable,omitempty"`
+	Permissions uint64 `json:"permissions"`
+}
+
+// Channel is a channel of a guild. Threads are not exported.
+type Channel struct {
+	Name string       `json:"name"`
+	Type channel.Type `json:"type"`
+	// Parent is the name of the category of the channel, if any.
+	Parent           string      `json:"parent,omitempty"`
+	Topic            string      `json:"topic,omitempty"`
+	NSFW             bool        `json:"nsfw,omitempty"`
+	Bitrate          int         `json:"bitrate,omitempty"`
+	UserLimit        int         `json:"user_limit,omitempty"`
+	RateLimitPerUser int         `json:"rate_limit_per_user,omitempty"`
+	Overwrites       []Overwrite `json:"overwrites,omitempty"`
+}
+
+// Ref returns the reference of the channel, used to refer to it in settings:
+// its name, prefixed with the name of its category and a slash if any.
+func (c *Channel) Ref() string {
+	return ref(c.Parent, c.Name)
+}
+
+func ref(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "/" + name
+}
+
+// Overwrite is a permission overwrite of a channel, for either a role or a member.
+type Overwrite struct {
+	// Name of the role the overwrite applies to.
+	Role string `json:"role,omitempty"`
+	// ID of the member the overwrite applies to.
+	Member string `json:"member,omitempty"`
+	Allow  uint64 `json:"allow"`
+	Deny   uint64 `json:"deny"`
+}
+
+// Emoji is a custom emoji of a guild.
+type Emoji struct {
+	Name string `json:"name"`
+	// Image of the emoji, encoded in the Data URI scheme. See harmony.ImageData.
+	Image string `json:"image"`
+}
+
+// Load reads a snapshot previously written with Save from r.
+func Load(r io.Reader) (*Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, err
+	}
+
+	if s.Version != Version {
+		return nil, fmt.Errorf("guildsync: unsupported snapshot version %d", s.Version)
+	}
+	return &s, nil
+}
+
+// Save writes the snapshot to w, as indented JSON.
+func (s *Snapshot) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// Export returns a snapshot of the structure of the given guild. It requires
+// the 'MANAGE_ROLES', 'MANAGE_CHANNELS' and 'MANAGE_EMOJIS' permissions.
+func Export(ctx context.Context, c *harmony.Client, guildID string) (*Snapshot, error) {
+	g, err := c.Guild(guildID).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get guild: %w", err)
+	}
+	roles, err := c.Guild(guildID).Roles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get roles: %w", err)
+	}
+	channels, err := c.Guild(guildID).Channels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get channels: %w", err)
+	}
+	emojis, err := c.Guild(guildID).Emojis(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get emojis: %w", err)
+	}
+
+	roles = sortRoles(guildID, roles)
+	channels = sortChannels(channels)
+	names := newNames(guildID, roles, channels)
+
+	s := &Snapshot{
+		Version: Version,
+		Settings: Settings{
+			Name:                        g.Name,
+			VerificationLevel:           g.VerificationLevel,
+			DefaultMessageNotifications: g.DefaultMessageNotifications,
+			ExplicitContentFilter:       g.ExplicitContentFilter,
+			AFKChannel:                  names.channelRef(g.AFKChannelID),
+			AFKTimeout:                  g.AFKTimeout,
+			SystemChannel:               names.channelRef(g.SystemChannelID),
+			PreferredLocale:             g.PreferredLocale,
+		},
+	}
+
+	for i := range roles {
+		s.Roles = append(s.Roles, exportRole(guildID, &roles[i]))
+	}
+	for i := range channels {
+		s.Channels = append(s.Channels, names.exportChannel(&channels[i]))
+	}
+
+	for i := range emojis {
+		if emojis[i].Managed {
+			continue
+		}
+		img, err := emojiImage(ctx, &emojis[i])
+		if err != nil {
+			return nil, fmt.Errorf("could not get image of emoji %q: %w", emojis[i].Name, err)
+		}
+		s.Emojis = append(s.Emojis, Emoji{Name: emojis[i].Name, Image: img})
+	}
+
+	return s, nil
+}
+
+func exportRole(guildID string, r *harmony.Role) Role {
+	name := r.Name
+	if r.ID == guildID {
+		name = EveryoneRole
+	}
+	return Role{
+		Name:        name,
+		Color:       r.Color,
+		Hoist:       r.Hoist,
+		Mentionable: r.Mentionable,
+		Permissions: r.Permissions,
+	}
+}
+
+// emojiImage downloads the image of the given emoji from Discord's CDN.
+func emojiImage(ctx context.Context, e *harmony.Emoji) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.URL(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return harmony.ImageData(resp.Body, harmony.MaxEmojiSize)
+}
+
+// sortRoles returns the roles that can be synchronized, from the lowest to the
+// highest, starting with the @everyone role. Managed roles are left out.
+func sortRoles(guildID string, roles []harmony.Role) []harmony.Role {
+	var sorted []harmony.Role
+	for i := range roles {
+		if roles[i].ID == guildID || roles[i].IsAssignable() {
+			sorted = append(sorted, roles[i])
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if (sorted[i].ID == guildID) != (sorted[j].ID == guildID) {
+			return sorted[i].ID == guildID
+		}
+		if sorted[i].Position != sorted[j].Position {
+			return sorted[i].Position < sorted[j].Position
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
+
+// sortChannels returns the channels that can be synchronized, categories first,
+// then in the order they appear in. Threads are left out.
+func sortChannels(channels []harmony.Channel) []harmony.Channel {
+	var sorted []harmony.Channel
+	for i := range channels {
+		if !channel.IsThread(channels[i].Type) {
+			sorted = append(sorted, channels[i])
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ci, cj := sorted[i].Type == channel.TypeGuildCategory, sorted[j].Type == channel.TypeGuildCategory
+		if ci != cj {
+			return ci
+		}
+		if sorted[i].Position != sorted[j].Position {
+			return sorted[i].Position < sorted[j].Position
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
+
+// names resolves the IDs of roles and channels of a guild to their names.
+type names struct {
+	roles    map[string]string // Role names by ID.
+	channels map[string]*harmony.Channel
+}
+
+func newNames(guildID string, roles []harmony.Role, channels []harmony.Channel) *names {
+	n := &names{
+		roles:    make(map[string]string),
+		channels: make(map[string]*harmony.Channel),
+	}
+	for i := range roles {
+		n.roles[roles[i].ID] = exportRole(guildID, &roles[i]).Name
+	}
+	for i := range channels {
+		n.channels[channels[i].ID] = &channels[i]
+	}
+	return n
+}
+
+// channelRef returns the reference of the channel with the given ID, if any.
+func (n *names) channelRef(id *string) string {
+	if id == nil {
+		return ""
+	}
+	ch, ok := n.channels[*id]
+	if !ok {
+		return ""
+	}
+	return ref(n.parent(ch), ch.Name)
+}
+
+func (n *names) parent(ch *harmony.Channel) string {
+	if p, ok := n.channels[ch.ParentID]; ok {
+		return p.Name
+	}
+	return ""
+}
+
+func (n *names) exportChannel(ch *harmony.Channel) Channel {
+	return Channel{
+		Name:             ch.Name,
+		Type:             ch.Type,
+		Parent:           n.parent(ch),
+		Topic:            ch.Topic,
+		NSFW:             ch.NSFW,
+		Bitrate:          ch.Bitrate,
+		UserLimit:        ch.UserLimit,
+		RateLimitPerUser: ch.RateLimitPerUser,
+		Overwrites:       n.exportOverwrites(ch),
+	}
+}
+
+// exportOverwrites returns the overwrites of the given channel, sorted so
+// they can be compared. Overwrites of managed roles are left out.
+func (n *names) exportOverwrites(ch *harmony.Channel) []Overwrite {
+	var overwrites []Overwrite
+	for _, o := range ch.PermissionOverwrites {
+		ow := Overwrite{Allow: o.Allow, Deny: o.Deny}
+		if o.Type == "member" {
+			ow.Member = o.ID
+		} else {
+			name, ok := n.roles[o.ID]
+			if !ok {
+				continue
+			}
+			ow.Role = name
+		}
+		overwrites = append(overwrites, ow)
+	}
+
+	sort.Slice(overwrites, func(i, j int) bool {
+		if overwrites[i].Role != overwrites[j].Role {
+			return overwrites[i].Role < overwrites[j].Role
+		}
+		return overwrites[i].Member < overwrites[j].Member
+	})
+	return overwrites
+}
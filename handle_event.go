@@ -12,16 +12,32 @@ import (
 func (c *Client) handleEvent(p *payload.Payload) error {
 	switch p.Op {
 	case gatewayOpcodeDispatch:
+		if c.isReplayedEvent(p) {
+			c.logger.Debugf("dropping replayed event %s (seq=%d)", p.T, p.S)
+			return nil
+		}
 		c.sequence.Store(p.S)
+		c.observeEvent(p.T)
 
 		// Those two events should be sent through the payloads channel if the
 		// client is currently connecting to a voice channel so the JoinVoiceChannel
 		// method can receive them.
 		if (p.T == eventVoiceStateUpdate || p.T == eventVoiceServerUpdate) &&
 			c.isConnectingToVoice() {
-			c.voicePayloads <- p
+			select {
+			case c.voicePayloads <- p:
+			default:
+				c.logger.Warnf("dropping %s payload: too many pending voice payloads", p.T)
+			}
 		}
 
+		if c.isFilteredEvent(p) {
+			c.recordFilteredEvent(p.T)
+			return nil
+		}
+
+		c.runHandler(eventRaw, &RawEvent{Type: p.T, Data: p.D})
+
 		if err := c.dispatch(p.T, p.D); err != nil {
 			return err
 		}
@@ -52,7 +68,7 @@ func (c *Client) handleEvent(p *payload.Payload) error {
 			// Invalid Session payload and are expected to wait a bit before
 			// sending a fresh Identify payload.
 			// https://discord.com/developers/docs/topics/gateway#resuming.
-			time.Sleep(time.Duration(rand.Intn(5)+1) * time.Second)
+			c.clock.Sleep(time.Duration(rand.Intn(5)+1) * time.Second)
 
 			c.resetGatewaySession()
 			if err := c.identify(c.ctx); err != nil {
@@ -64,10 +80,46 @@ func (c *Client) handleEvent(p *payload.Payload) error {
 		// Handled by Connect()
 
 	case gatewayOpcodeHeartbeatACK:
+		rtt := c.clock.Since(time.Unix(0, c.lastHeartbeatSend.Load()))
+		c.latency.Store(int64(rtt))
 		if c.withStateTracking {
-			c.State.setRTT(time.Since(time.Unix(0, c.lastHeartbeatSend.Load())))
+			c.State.setRTT(rtt)
 		}
-		c.lastHeartbeatACK.Store(time.Now().UnixNano())
+		c.lastHeartbeatACK.Store(c.clock.Now().UnixNano())
+
+	default:
+		c.recordUnknownOpcode(p.Op)
+		c.logger.Infof("unrecognized opcode %d: %s", p.Op, string(p.D))
 	}
 	return nil
 }
+
+// isFilteredEvent reports whether the given Dispatch event is dropped by the
+// event filter of the client, if any.
+func (c *Client) isFilteredEvent(p *payload.Payload) bool {
+	if c.eventFilter == nil {
+		return false
+	}
+
+	switch p.T {
+	case eventReady, eventResumed, eventVoiceStateUpdate, eventVoiceServerUpdate:
+		return false
+	}
+	return !c.eventFilter.Keep(p.T, p.D)
+}
+
+// isReplayedEvent reports whether the given Dispatch event has already been handled
+// during the current session, in which case it is a replay from the Gateway.
+func (c *Client) isReplayedEvent(p *payload.Payload) bool {
+	if !c.eventDeduplication || p.S == 0 {
+		return false
+	}
+
+	if c.sessionID == c.lastDispatchedSession && p.S <= c.lastDispatchedSeq {
+		return true
+	}
+
+	c.lastDispatchedSession = c.sessionID
+	c.lastDispatchedSeq = p.S
+	return false
+}
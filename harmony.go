@@ -63,6 +63,18 @@ To register handlers for other types of events, see Client.On* methods.
 Note that your handlers are called in their own goroutine, meaning
 whatever you do inside of them won't block future events.
 
+Each OnXxx method has an OnXxxCtx variant whose handler is given a context,
+canceled when the client disconnects, so long running handlers can stop
+their work on shutdown:
+
+	client.OnMessageCreateCtx(func(ctx context.Context, msg *harmony.Message) {
+		_, err := client.Channel(msg.ChannelID).SendMessage(ctx, "pong")
+		// ...
+	})
+
+Only one handler can be registered per event: registering an OnXxxCtx handler
+replaces the OnXxx one and vice versa.
+
 Using the state
 
 When connecting to Discord, a session state is created with initial data
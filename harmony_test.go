@@ -234,7 +234,7 @@ func TestHarmony(t *testing.T) {
 	var testRole *harmony.Role
 
 	t.Run("new role", func(t *testing.T) {
-		perms := permission.ReadMessageHistory | permission.SendMessages
+		perms := uint64(permission.ReadMessageHistory | permission.SendMessages)
 
 		settings := role.NewSettings(
 			role.WithName("test-role"),
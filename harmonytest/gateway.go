@@ -0,0 +1,304 @@
+package harmonytest
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/internal/payload"
+	"nhooyr.io/websocket"
+)
+
+// Gateway opcodes, see https://discord.com/developers/docs/topics/opcodes-and-status-codes#gateway.
+const (
+	opDispatch            = 0
+	opHeartbeat           = 1
+	opIdentify            = 2
+	opVoiceStateUpdate    = 4
+	opResume              = 6
+	opReconnect           = 7
+	opRequestGuildMembers = 8
+	opInvalidSession      = 9
+	opHello               = 10
+	opHeartbeatACK        = 11
+)
+
+const (
+	// heartbeatInterval is sent to clients in Hello payloads, in milliseconds.
+	heartbeatInterval = 41250
+	// closeAuthenticationFailed is the close code sent when a client identifies with the wrong token.
+	closeAuthenticationFailed = 4004
+)
+
+// GatewayPayload is a payload received by a Server from a client over the Gateway.
+type GatewayPayload struct {
+	Op   int
+	Data json.RawMessage
+}
+
+// GatewayPayloads returns the payloads received by the server over the Gateway
+// since it started, including Identify and heartbeat payloads.
+func (s *Server) GatewayPayloads() []*GatewayPayload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ps := make([]*GatewayPayload, len(s.received))
+	copy(ps, s.received)
+	return ps
+}
+
+// Dispatch sends an event of the given type with the given data to all clients
+// connected to the Gateway. data is encoded to JSON.
+func (s *Server) Dispatch(eventType string, data interface{}) error {
+	d, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	s.seq++
+	p := &payload.Payload{Op: opDispatch, S: s.seq, T: eventType, D: d}
+	for gc := range s.conns {
+		// A client that can not receive the event
+		// is disconnected by its read loop.
+		_ = gc.send(p)
+	}
+	return nil
+}
+
+// Reconnect asks all clients connected to the Gateway to reconnect,
+// which they do by resuming their session.
+func (s *Server) Reconnect() {
+	s.broadcast(&payload.Payload{Op: opReconnect, D: json.RawMessage("null")})
+}
+
+// InvalidateSession tells all clients connected to the Gateway that their session
+// is invalid. If resumable is false, they have to identify again, which they do
+// after waiting a few seconds.
+func (s *Server) InvalidateSession(resumable bool) {
+	s.broadcast(&payload.Payload{Op: opInvalidSession, D: json.RawMessage(strconv.FormatBool(resumable))})
+}
+
+// Connections returns the number of clients currently connected to the Gateway.
+func (s *Server) Connections() int {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	return len(s.conns)
+}
+
+func (s *Server) broadcast(p *payload.Payload) {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	for gc := range s.conns {
+		_ = gc.send(p)
+	}
+}
+
+func (s *Server) closeConns() {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	for gc := range s.conns {
+		_ = gc.conn.Close(websocket.StatusGoingAway, "server closed")
+		delete(s.conns, gc)
+	}
+}
+
+// gatewayConn is a connection of a client to the Gateway.
+type gatewayConn struct {
+	conn *websocket.Conn
+
+	mu  sync.Mutex // Orders writes, which share the compression stream.
+	zw  *zlib.Writer
+	buf bytes.Buffer
+}
+
+// send sends the given payload to the client, compressed
+// with zlib-stream compression if it asked for it.
+func (gc *gatewayConn) send(p *payload.Payload) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if gc.zw == nil {
+		return gc.conn.Write(ctx, websocket.MessageText, b)
+	}
+
+	gc.buf.Reset()
+	if _, err = gc.zw.Write(b); err != nil {
+		return err
+	}
+	// Flushing ends the message with the zlib
+	// suffix clients wait for to decompress it.
+	if err = gc.zw.Flush(); err != nil {
+		return err
+	}
+	return gc.conn.Write(ctx, websocket.MessageBinary, gc.buf.Bytes())
+}
+
+// serveGateway handles a connection of a client to the Gateway, until it closes it.
+func (s *Server) serveGateway(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn.SetReadLimit(1 << 20)
+
+	gc := &gatewayConn{conn: conn}
+	if r.URL.Query().Get("compress") == "zlib-stream" {
+		gc.zw = zlib.NewWriter(&gc.buf)
+	}
+	defer func() {
+		s.connsMu.Lock()
+		delete(s.conns, gc)
+		s.connsMu.Unlock()
+		_ = conn.Close(websocket.StatusNormalClosure, "")
+	}()
+
+	hello := json.RawMessage(`{"heartbeat_interval":` + strconv.Itoa(heartbeatInterval) + `}`)
+	if err = gc.send(&payload.Payload{Op: opHello, D: hello}); err != nil {
+		return
+	}
+
+	ctx := r.Context()
+	for {
+		var p payload.Payload
+		_, b, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		if err = json.Unmarshal(b, &p); err != nil {
+			_ = conn.Close(websocket.StatusUnsupportedData, "invalid payload")
+			return
+		}
+
+		s.mu.Lock()
+		s.received = append(s.received, &GatewayPayload{Op: p.Op, Data: p.D})
+		s.mu.Unlock()
+
+		switch p.Op {
+		case opHeartbeat:
+			err = gc.send(&payload.Payload{Op: opHeartbeatACK, D: json.RawMessage("null")})
+
+		case opIdentify:
+			var identify struct {
+				Token string `json:"token"`
+			}
+			if err = json.Unmarshal(p.D, &identify); err != nil || identify.Token != "Bot "+s.token {
+				_ = conn.Close(closeAuthenticationFailed, "Authentication failed.")
+				return
+			}
+			err = s.ready(gc)
+
+		case opResume:
+			s.connsMu.Lock()
+			s.conns[gc] = struct{}{}
+			s.seq++
+			err = gc.send(&payload.Payload{Op: opDispatch, S: s.seq, T: "RESUMED", D: json.RawMessage("{}")})
+			s.connsMu.Unlock()
+
+		case opRequestGuildMembers:
+			err = s.sendMembers(gc, p.D)
+
+		case opVoiceStateUpdate:
+			err = s.updateVoiceState(gc, p.D)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// ready sends the Ready event to the given connection, followed by a Guild
+// Create event for each guild. The connection then receives dispatched events.
+func (s *Server) ready(gc *gatewayConn) error {
+	s.mu.Lock()
+	partials := make([]harmony.PartialGuild, 0, len(s.guilds))
+	guilds := make([]*harmony.Guild, 0, len(s.guilds))
+	for id := range s.guilds {
+		partials = append(partials, harmony.PartialGuild{ID: id})
+		guilds = append(guilds, s.guildCreateLocked(id))
+	}
+	s.mu.Unlock()
+
+	rdy := &harmony.Ready{
+		V:               6,
+		User:            s.user,
+		PrivateChannels: []harmony.Channel{},
+		Guilds:          partials,
+		SessionID:       strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	if err := s.sendEventLocked(gc, "READY", rdy); err != nil {
+		return err
+	}
+	for _, g := range guilds {
+		if err := s.sendEventLocked(gc, "GUILD_CREATE", g); err != nil {
+			return err
+		}
+	}
+	s.conns[gc] = struct{}{}
+	return nil
+}
+
+// sendMembers answers a Request Guild Members payload with a single chunk
+// holding the members of the guild, as they were added with AddGuild.
+func (s *Server) sendMembers(gc *gatewayConn, d json.RawMessage) error {
+	var req struct {
+		GuildID string `json:"guild_id"`
+		Nonce   string `json:"nonce"`
+	}
+	if err := json.Unmarshal(d, &req); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	members := []harmony.GuildMember{}
+	if g, ok := s.guilds[req.GuildID]; ok {
+		members = append(members, g.Members...)
+	}
+	s.mu.Unlock()
+
+	chunk := map[string]interface{}{
+		"guild_id":    req.GuildID,
+		"members":     members,
+		"chunk_index": 0,
+		"chunk_count": 1,
+		"nonce":       req.Nonce,
+	}
+
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	return s.sendEventLocked(gc, "GUILD_MEMBERS_CHUNK", chunk)
+}
+
+// sendEventLocked sends an event to a single connection. s.connsMu must be held.
+func (s *Server) sendEventLocked(gc *gatewayConn, eventType string, data interface{}) error {
+	d, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	s.seq++
+	return gc.send(&payload.Payload{Op: opDispatch, S: s.seq, T: eventType, D: d})
+}
@@ -0,0 +1,436 @@
+/*
+Package harmonytest provides an in-process fake of Discord's REST API and
+Gateway, so bots built with Harmony can be tested without connecting to Discord.
+
+A Server is primed with guilds, channels and messages, serves them to clients and
+records the requests they send. It implements the most common endpoints, other
+ones can be added with Handle:
+
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	srv.AddGuild(&harmony.Guild{ID: "1", Name: "test"})
+	srv.AddChannel(&harmony.Channel{ID: "2", GuildID: "1", Name: "general"})
+
+	client, err := srv.NewClient()
+	if err != nil {
+		// Handle error
+	}
+	client.OnMessageCreate(func(m *harmony.Message) {
+		if m.Content == "!ping" {
+			client.Channel(m.ChannelID).SendMessage(context.Background(), "pong")
+		}
+	})
+	if err = client.Connect(ctx); err != nil {
+		// Handle error
+	}
+	defer client.Disconnect()
+
+	// Fixtures added while clients are connected are sent to them as events.
+	srv.AddMessage(&harmony.Message{ChannelID: "2", Content: "!ping", Author: &harmony.User{ID: "3"}})
+
+	req, err := srv.WaitRequest(ctx, harmonytest.MatchRoute(http.MethodPost, "/channels/2/messages"))
+
+The Server also runs a fake voice server, so voice channels can be joined. Voice
+connections must be configured to trust its TLS certificate:
+
+	conn, err := client.JoinVoiceChannel(ctx, "1", "4", false, false,
+		harmony.WithVoiceConnectionOptions(srv.VoiceConnectionOptions()...),
+	)
+*/
+package harmonytest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/skwair/harmony"
+)
+
+const (
+	// DefaultToken is the bot token accepted by a Server unless WithToken is used.
+	DefaultToken = "harmonytest"
+	// discordEpoch is the first second of 2015, in milliseconds since the Unix epoch.
+	discordEpoch = 1420070400000
+)
+
+// Server is a fake Discord server, serving the REST API and the Gateway over HTTP.
+// Create one with NewServer.
+type Server struct {
+	// URL is the base URL of the REST API of the server,
+	// used by clients created with NewClient.
+	URL string
+
+	srv   *httptest.Server
+	voice *voiceServer
+	token string
+	user  *harmony.User
+
+	mu       sync.Mutex
+	lastID   int64
+	guilds   map[string]*harmony.Guild
+	channels map[string]*harmony.Channel
+	messages map[string][]*harmony.Message // By channel ID, oldest first.
+	routes   []route
+	requests []*Request
+	received []*GatewayPayload
+	// notify is closed and replaced each time a request is recorded.
+	notify chan struct{}
+
+	connsMu sync.Mutex
+	conns   map[*gatewayConn]struct{}
+	seq     int64
+}
+
+// Option is a function that configures a Server.
+type Option func(*Server)
+
+// WithToken sets the bot token clients must authenticate with.
+// Defaults to DefaultToken.
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithUser sets the user of the bot, sent to clients when they connect to the
+// Gateway and as the author of the messages they send.
+// Defaults to a bot user named "harmonytest".
+func WithUser(u *harmony.User) Option {
+	return func(s *Server) {
+		s.user = u
+	}
+}
+
+// NewServer starts and returns a new Server. It must be closed with Close.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		token:    DefaultToken,
+		guilds:   make(map[string]*harmony.Guild),
+		channels: make(map[string]*harmony.Channel),
+		messages: make(map[string][]*harmony.Message),
+		notify:   make(chan struct{}),
+		conns:    make(map[*gatewayConn]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.user == nil {
+		s.user = &harmony.User{
+			ID:            s.newID(),
+			Username:      "harmonytest",
+			Discriminator: "0000",
+			Bot:           true,
+		}
+	}
+
+	s.registerRoutes()
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	s.voice = newVoiceServer()
+	return s
+}
+
+// Close disconnects all clients and shuts down the server.
+func (s *Server) Close() {
+	s.closeConns()
+	s.srv.Close()
+	s.voice.close()
+}
+
+// Token returns the bot token clients must authenticate with.
+func (s *Server) Token() string {
+	return s.token
+}
+
+// User returns the user of the bot.
+func (s *Server) User() *harmony.User {
+	return s.user
+}
+
+// NewClient returns a new client using the server instead of Discord.
+// The given options are applied after the ones configuring the server.
+func (s *Server) NewClient(opts ...harmony.ClientOption) (*harmony.Client, error) {
+	return harmony.NewClient(s.token, append(s.ClientOptions(), opts...)...)
+}
+
+// ClientOptions returns the options to give to harmony.NewClient so the
+// client uses the server instead of Discord. The token of the client
+// must be the one returned by Token.
+func (s *Server) ClientOptions() []harmony.ClientOption {
+	return []harmony.ClientOption{harmony.WithBaseURL(s.URL)}
+}
+
+// AddGuild adds the given guild to the server, along with its channels.
+// It is sent to clients in a Guild Create event.
+func (s *Server) AddGuild(g *harmony.Guild) {
+	s.mu.Lock()
+	g = cloneGuild(g)
+	if g.ID == "" {
+		g.ID = s.newIDLocked()
+	}
+	s.guilds[g.ID] = g
+	for i := range g.Channels {
+		ch := g.Channels[i]
+		ch.GuildID = g.ID
+		s.channels[ch.ID] = &ch
+	}
+	g.Channels = nil
+	created := s.guildCreateLocked(g.ID)
+	s.mu.Unlock()
+
+	_ = s.Dispatch("GUILD_CREATE", created)
+}
+
+// AddChannel adds the given channel to the server.
+// It is sent to connected clients in a Channel Create event.
+func (s *Server) AddChannel(ch *harmony.Channel) {
+	s.mu.Lock()
+	c := *ch
+	if c.ID == "" {
+		c.ID = s.newIDLocked()
+	}
+	s.channels[c.ID] = &c
+	s.mu.Unlock()
+
+	_ = s.Dispatch("CHANNEL_CREATE", &c)
+}
+
+// AddMessage adds the given message to its channel on the server. Its ID and
+// timestamp are set if they are not. It is sent to connected clients in a
+// Message Create event, which makes it useful to simulate users sending
+// messages to the bot.
+func (s *Server) AddMessage(m *harmony.Message) {
+	s.addMessage(m)
+}
+
+// addMessage is like AddMessage but returns the message as it was added.
+func (s *Server) addMessage(m *harmony.Message) *harmony.Message {
+	s.mu.Lock()
+	msg := *m
+	if msg.ID == "" {
+		msg.ID = s.newIDLocked()
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now().UTC()
+	}
+	if ch, ok := s.channels[msg.ChannelID]; ok && msg.GuildID == "" {
+		msg.GuildID = ch.GuildID
+	}
+	stored := msg
+	s.messages[msg.ChannelID] = append(s.messages[msg.ChannelID], &stored)
+	s.mu.Unlock()
+
+	_ = s.Dispatch("MESSAGE_CREATE", &msg)
+	return &msg
+}
+
+// Guild returns the guild with the given ID, or nil if there is none.
+func (s *Server) Guild(id string) *harmony.Guild {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.guilds[id]
+	if !ok {
+		return nil
+	}
+	return cloneGuild(g)
+}
+
+// Channel returns the channel with the given ID, or nil if there is none.
+func (s *Server) Channel(id string) *harmony.Channel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch, ok := s.channels[id]
+	if !ok {
+		return nil
+	}
+	c := *ch
+	return &c
+}
+
+// Messages returns the messages of the given channel, oldest first,
+// including the ones sent by clients.
+func (s *Server) Messages(channelID string) []harmony.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs := make([]harmony.Message, 0, len(s.messages[channelID]))
+	for _, m := range s.messages[channelID] {
+		msgs = append(msgs, *m)
+	}
+	return msgs
+}
+
+// Request is a REST request received by a Server.
+type Request struct {
+	Method string
+	// Path of the request, relative to the URL of the server.
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Decode decodes the JSON body of the request into v. For multipart
+// requests, it decodes their "payload_json" part instead.
+func (r *Request) Decode(v interface{}) error {
+	b := r.Body
+	if payload, _, err := parseMultipart(r.Header.Get("Content-Type"), r.Body); err == nil {
+		b = payload
+	}
+	return json.Unmarshal(b, v)
+}
+
+// Requests returns the REST requests received by the server
+// since it started or since the last call to ResetRequests.
+func (s *Server) Requests() []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reqs := make([]*Request, len(s.requests))
+	copy(reqs, s.requests)
+	return reqs
+}
+
+// ResetRequests forgets the REST requests received by the server so far.
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = nil
+}
+
+// MatchRoute returns a function matching requests with the given method
+// and route, for WaitRequest. See Handle for the syntax of routes.
+func MatchRoute(method, route string) func(*Request) bool {
+	segments := splitPath(route)
+	return func(r *Request) bool {
+		_, ok := matchSegments(segments, splitPath(r.Path))
+		return r.Method == method && ok
+	}
+}
+
+// WaitRequest waits until the server receives a request for which match
+// returns true and returns it. Requests received before it was called are
+// considered first, so it does not matter whether the request is sent before
+// or after calling WaitRequest. It returns an error if ctx is done first.
+func (s *Server) WaitRequest(ctx context.Context, match func(*Request) bool) (*Request, error) {
+	seen := 0
+	for {
+		s.mu.Lock()
+		reqs := s.requests[seen:]
+		notify := s.notify
+		s.mu.Unlock()
+
+		for _, r := range reqs {
+			if match(r) {
+				return r, nil
+			}
+		}
+		seen += len(reqs)
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// recordRequest records the given request and restores its body.
+func (s *Server) recordRequest(r *http.Request) error {
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, &Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   b,
+	})
+	close(s.notify)
+	s.notify = make(chan struct{})
+	return nil
+}
+
+// guildCreateLocked returns the guild with the given ID as sent
+// in Guild Create events, with its channels. s.mu must be held.
+func (s *Server) guildCreateLocked(id string) *harmony.Guild {
+	g := cloneGuild(s.guilds[id])
+	g.Channels = s.guildChannelsLocked(id)
+	if g.JoinedAt.IsZero() {
+		g.JoinedAt = time.Now().UTC()
+	}
+	return g
+}
+
+// guildChannelsLocked returns the channels of the given
+// guild, sorted by position. s.mu must be held.
+func (s *Server) guildChannelsLocked(guildID string) []harmony.Channel {
+	chs := make([]harmony.Channel, 0)
+	for _, ch := range s.channels {
+		if ch.GuildID == guildID {
+			chs = append(chs, *ch)
+		}
+	}
+	sort.Slice(chs, func(i, j int) bool {
+		if chs[i].Position != chs[j].Position {
+			return chs[i].Position < chs[j].Position
+		}
+		return idLess(chs[i].ID, chs[j].ID)
+	})
+	return chs
+}
+
+// newID returns a new unique snowflake.
+func (s *Server) newID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.newIDLocked()
+}
+
+// newIDLocked is like newID but s.mu must be held.
+func (s *Server) newIDLocked() string {
+	id := (time.Now().UnixNano()/int64(time.Millisecond) - discordEpoch) << 22
+	if id <= s.lastID {
+		id = s.lastID + 1
+	}
+	s.lastID = id
+	return strconv.FormatInt(id, 10)
+}
+
+// idLess reports whether the snowflake a is lower than b.
+func idLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// cloneGuild returns a shallow copy of the given guild and of its channels.
+func cloneGuild(g *harmony.Guild) *harmony.Guild {
+	c := *g
+	c.Channels = append([]harmony.Channel(nil), g.Channels...)
+	return &c
+}
@@ -0,0 +1,260 @@
+package harmonytest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+)
+
+func TestServerMessages(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.AddChannel(&harmony.Channel{ID: "2", GuildID: "1", Name: "general"})
+	srv.AddMessage(&harmony.Message{ChannelID: "2", Content: "first", Author: &harmony.User{ID: "3"}})
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ch := c.Channel("2")
+	sent, err := ch.SendMessage(ctx, "second")
+	if err != nil {
+		t.Fatalf("could not send message: %v", err)
+	}
+	if sent.Author == nil || sent.Author.ID != srv.User().ID || sent.GuildID != "1" {
+		t.Errorf("expected the message to be authored by the bot in guild 1, got %+v", sent)
+	}
+
+	if _, err = ch.EditMessage(ctx, sent.ID, "edited"); err != nil {
+		t.Fatalf("could not edit message: %v", err)
+	}
+	msg, err := ch.Message(ctx, sent.ID)
+	if err != nil {
+		t.Fatalf("could not get message: %v", err)
+	}
+	if msg.Content != "edited" || msg.EditedTimestamp.IsZero() {
+		t.Errorf("expected the message to be edited, got %+v", msg)
+	}
+
+	// Messages are listed newest first.
+	msgs, err := ch.Messages(ctx, "<"+sent.ID, 10)
+	if err != nil {
+		t.Fatalf("could not get messages: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].Content != "first" {
+		t.Errorf("expected the first message only, got %+v", msgs)
+	}
+
+	if err = ch.DeleteMessage(ctx, sent.ID); err != nil {
+		t.Fatalf("could not delete message: %v", err)
+	}
+	if msgs := srv.Messages("2"); len(msgs) != 1 || msgs[0].Content != "first" {
+		t.Errorf("expected the first message to be left, got %+v", msgs)
+	}
+
+	if _, err = c.Channel("4").SendMessage(ctx, "nowhere"); err == nil {
+		t.Error("expected an error when sending a message to an unknown channel")
+	}
+}
+
+func TestServerHandle(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.AddChannel(&harmony.Channel{ID: "2", Name: "general"})
+	srv.Handle(http.MethodGet, "/channels/:channel", func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, &harmony.Channel{ID: Param(r, "channel"), Name: "custom"})
+	})
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	ch, err := c.Channel("2").Get(context.Background())
+	if err != nil {
+		t.Fatalf("could not get channel: %v", err)
+	}
+	if ch.ID != "2" || ch.Name != "custom" {
+		t.Errorf("expected the channel to be served by the custom handler, got %+v", ch)
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.Handle(http.MethodGet, "/channels/:channel", func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, http.StatusNotFound, 10003, "Unknown Channel")
+	})
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	_, err = c.Channel("2").Get(context.Background())
+	var apiErr harmony.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an API error, got %v", err)
+	}
+	if apiErr.HTTPCode != http.StatusNotFound || apiErr.Code != 10003 || apiErr.Message != "Unknown Channel" {
+		t.Errorf("expected a 404 Unknown Channel error, got %+v", apiErr)
+	}
+}
+
+func TestServerGateway(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.AddGuild(&harmony.Guild{ID: "1", Name: "test", Channels: []harmony.Channel{{ID: "2", Name: "general"}}})
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	guilds := make(chan *harmony.Guild, 1)
+	c.OnGuildCreate(func(g *harmony.Guild) { guilds <- g })
+	messages := make(chan *harmony.Message, 1)
+	c.OnMessageCreate(func(m *harmony.Message) { messages <- m })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = c.Connect(ctx); err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer c.Disconnect()
+
+	select {
+	case g := <-guilds:
+		if g.ID != "1" || len(g.Channels) != 1 || g.Channels[0].GuildID != "1" {
+			t.Errorf("expected guild 1 with its channel, got %+v", g)
+		}
+	case <-ctx.Done():
+		t.Fatal("expected a Guild Create event")
+	}
+
+	srv.AddMessage(&harmony.Message{ChannelID: "2", Content: "hello"})
+	select {
+	case m := <-messages:
+		if m.Content != "hello" || m.GuildID != "1" {
+			t.Errorf("expected the message to be sent in guild 1, got %+v", m)
+		}
+	case <-ctx.Done():
+		t.Fatal("expected a Message Create event")
+	}
+
+	if n := srv.Connections(); n != 1 {
+		t.Errorf("expected a single connection to the Gateway, got %d", n)
+	}
+	if !identified(srv) {
+		t.Error("expected the client to identify to the Gateway")
+	}
+}
+
+func TestServerToken(t *testing.T) {
+	srv := NewServer(WithToken("secret"))
+	defer srv.Close()
+
+	c, err := harmony.NewClient("wrong", srv.ClientOptions()...)
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err = c.CurrentUser().Get(ctx); err == nil {
+		t.Error("expected REST requests with the wrong token to fail")
+	}
+	if err = c.Connect(ctx); err == nil {
+		c.Disconnect()
+		t.Error("expected connecting with the wrong token to fail")
+	}
+}
+
+func TestWaitRequest(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Requests sent before WaitRequest is called are matched too.
+	_ = c.Channel("2").TriggerTyping(ctx)
+	req, err := srv.WaitRequest(ctx, MatchRoute(http.MethodPost, "/channels/:channel/typing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Path != "/channels/2/typing" {
+		t.Errorf("expected path to be /channels/2/typing, got %s", req.Path)
+	}
+
+	srv.ResetRequests()
+	if reqs := srv.Requests(); len(reqs) != 0 {
+		t.Errorf("expected no requests once reset, got %d", len(reqs))
+	}
+
+	short, cancelShort := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancelShort()
+	if _, err = srv.WaitRequest(short, MatchRoute(http.MethodPost, "/channels/:channel/typing")); err == nil {
+		t.Error("expected an error once the context is done")
+	}
+}
+
+func TestMatchRoute(t *testing.T) {
+	tests := []struct {
+		route    string
+		path     string
+		params   map[string]string
+		expected bool
+	}{
+		{route: "/channels/:channel", path: "/channels/1", params: map[string]string{"channel": "1"}, expected: true},
+		{route: "/channels/:channel/messages/:message", path: "/channels/1/messages/2?wait=true", params: map[string]string{"channel": "1", "message": "2"}, expected: true},
+		{route: "/users/@me", path: "/users/@me", params: map[string]string{}, expected: true},
+		{route: "/channels/:channel", path: "/channels/1/messages", expected: false},
+		{route: "/channels/:channel/messages", path: "/guilds/1/messages", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			params, ok := matchSegments(splitPath(tt.route), splitPath(tt.path))
+			if ok != tt.expected {
+				t.Fatalf("expected match to be %t, got %t", tt.expected, ok)
+			}
+			if ok && !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("expected params to be %v, got %v", tt.params, params)
+			}
+			if match := MatchRoute(http.MethodGet, tt.route)(&Request{Method: http.MethodGet, Path: tt.path}); match != tt.expected {
+				t.Errorf("expected MatchRoute to return %t, got %t", tt.expected, match)
+			}
+		})
+	}
+}
+
+// identified returns whether a client sent an Identify payload to the server.
+func identified(srv *Server) bool {
+	for _, p := range srv.GatewayPayloads() {
+		if p.Op == opIdentify {
+			return true
+		}
+	}
+	return false
+}
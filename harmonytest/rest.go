@@ -0,0 +1,446 @@
+package harmonytest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/embed"
+	"github.com/skwair/harmony/message"
+)
+
+// JSON error codes sent by the server, see
+// https://discord.com/developers/docs/topics/opcodes-and-status-codes#json.
+const (
+	codeUnknownChannel = 10003
+	codeUnknownGuild   = 10004
+	codeUnknownMessage = 10008
+)
+
+// route is a REST endpoint served by a Server.
+type route struct {
+	method   string
+	segments []string
+	h        http.HandlerFunc
+}
+
+type paramsKey struct{}
+
+// Handle registers the handler for the given method and route, replacing
+// the one implemented by the server if any. Routes are paths relative to
+// the URL of the server, where segments starting with ':' match any value,
+// for instance "/channels/:channel/messages". Their values are given by
+// Param. Requests are recorded before they are handled.
+func (s *Server) Handle(method, route string, h http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handleLocked(method, route, h)
+}
+
+func (s *Server) handleLocked(method, path string, h http.HandlerFunc) {
+	r := route{method: method, segments: splitPath(path), h: h}
+	// Custom routes are matched first.
+	s.routes = append([]route{r}, s.routes...)
+}
+
+// Param returns the value of the given placeholder of the
+// route of a request handled by a handler given to Handle.
+func Param(r *http.Request, name string) string {
+	params, _ := r.Context().Value(paramsKey{}).(map[string]string)
+	return params[strings.TrimPrefix(name, ":")]
+}
+
+// WriteJSON writes v as the JSON body of a response with the given status code.
+func WriteJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// WriteError writes an error response like the ones sent by Discord,
+// with the given status code, JSON error code and message.
+func WriteError(w http.ResponseWriter, statusCode, code int, msg string) {
+	// Not a harmony.APIError, whose HTTP code would be sent as well.
+	WriteJSON(w, statusCode, map[string]interface{}{"code": code, "message": msg})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/gateway/connect" {
+		s.serveGateway(w, r)
+		return
+	}
+
+	if err := s.recordRequest(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, "/gateway") && r.Header.Get("Authorization") != "Bot "+s.token {
+		WriteError(w, http.StatusUnauthorized, 0, "401: Unauthorized")
+		return
+	}
+
+	segments := splitPath(r.URL.Path)
+	s.mu.Lock()
+	routes := s.routes
+	s.mu.Unlock()
+
+	for _, rt := range routes {
+		if rt.method != r.Method {
+			continue
+		}
+		if params, ok := matchSegments(rt.segments, segments); ok {
+			rt.h(w, r.WithContext(context.WithValue(r.Context(), paramsKey{}, params)))
+			return
+		}
+	}
+	WriteError(w, http.StatusNotFound, 0, "404: Not Found")
+}
+
+// registerRoutes registers the endpoints implemented by the server.
+func (s *Server) registerRoutes() {
+	s.handleLocked(http.MethodGet, "/gateway", s.getGateway)
+	s.handleLocked(http.MethodGet, "/gateway/bot", s.getGateway)
+	s.handleLocked(http.MethodGet, "/users/@me", s.getCurrentUser)
+	s.handleLocked(http.MethodGet, "/users/@me/guilds", s.getCurrentUserGuilds)
+	s.handleLocked(http.MethodGet, "/guilds/:guild", s.getGuild)
+	s.handleLocked(http.MethodGet, "/guilds/:guild/channels", s.getGuildChannels)
+	s.handleLocked(http.MethodGet, "/channels/:channel", s.getChannel)
+	s.handleLocked(http.MethodDelete, "/channels/:channel", s.deleteChannel)
+	s.handleLocked(http.MethodGet, "/channels/:channel/messages", s.getMessages)
+	s.handleLocked(http.MethodPost, "/channels/:channel/messages", s.createMessage)
+	s.handleLocked(http.MethodGet, "/channels/:channel/messages/:message", s.getMessage)
+	s.handleLocked(http.MethodPatch, "/channels/:channel/messages/:message", s.editMessage)
+	s.handleLocked(http.MethodDelete, "/channels/:channel/messages/:message", s.deleteMessage)
+	s.handleLocked(http.MethodPut, "/channels/:channel/messages/:message/reactions/:emoji/@me", s.noContent)
+	s.handleLocked(http.MethodDelete, "/channels/:channel/messages/:message/reactions/:emoji/@me", s.noContent)
+	s.handleLocked(http.MethodPost, "/channels/:channel/typing", s.noContent)
+}
+
+func (s *Server) getGateway(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, &harmony.GatewayBotInfo{
+		URL:    "ws" + strings.TrimPrefix(s.URL, "http") + "/gateway/connect",
+		Shards: 1,
+		SessionStartLimit: harmony.SessionStartLimit{
+			Total:          1000,
+			Remaining:      1000,
+			ResetAfter:     int(24 * time.Hour / time.Millisecond),
+			MaxConcurrency: 1,
+		},
+	})
+}
+
+func (s *Server) getCurrentUser(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, s.user)
+}
+
+func (s *Server) getCurrentUserGuilds(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	guilds := make([]harmony.PartialGuild, 0, len(s.guilds))
+	for _, g := range s.guilds {
+		guilds = append(guilds, harmony.PartialGuild{ID: g.ID, Name: g.Name, Owner: g.OwnerID == s.user.ID})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(guilds, func(i, j int) bool { return idLess(guilds[i].ID, guilds[j].ID) })
+	WriteJSON(w, http.StatusOK, guilds)
+}
+
+func (s *Server) getGuild(w http.ResponseWriter, r *http.Request) {
+	g := s.Guild(Param(r, "guild"))
+	if g == nil {
+		WriteError(w, http.StatusNotFound, codeUnknownGuild, "Unknown Guild")
+		return
+	}
+	WriteJSON(w, http.StatusOK, g)
+}
+
+func (s *Server) getGuildChannels(w http.ResponseWriter, r *http.Request) {
+	id := Param(r, "guild")
+
+	s.mu.Lock()
+	_, ok := s.guilds[id]
+	chs := s.guildChannelsLocked(id)
+	s.mu.Unlock()
+
+	if !ok {
+		WriteError(w, http.StatusNotFound, codeUnknownGuild, "Unknown Guild")
+		return
+	}
+	WriteJSON(w, http.StatusOK, chs)
+}
+
+func (s *Server) getChannel(w http.ResponseWriter, r *http.Request) {
+	ch := s.Channel(Param(r, "channel"))
+	if ch == nil {
+		WriteError(w, http.StatusNotFound, codeUnknownChannel, "Unknown Channel")
+		return
+	}
+	WriteJSON(w, http.StatusOK, ch)
+}
+
+func (s *Server) deleteChannel(w http.ResponseWriter, r *http.Request) {
+	id := Param(r, "channel")
+
+	s.mu.Lock()
+	ch, ok := s.channels[id]
+	delete(s.channels, id)
+	delete(s.messages, id)
+	s.mu.Unlock()
+
+	if !ok {
+		WriteError(w, http.StatusNotFound, codeUnknownChannel, "Unknown Channel")
+		return
+	}
+	_ = s.Dispatch("CHANNEL_DELETE", ch)
+	WriteJSON(w, http.StatusOK, ch)
+}
+
+// getMessages returns the messages of a channel, newest first. It supports
+// the "before", "after" and "limit" query parameters but not "around".
+func (s *Server) getMessages(w http.ResponseWriter, r *http.Request) {
+	id := Param(r, "channel")
+	if s.Channel(id) == nil {
+		WriteError(w, http.StatusNotFound, codeUnknownChannel, "Unknown Channel")
+		return
+	}
+
+	q := r.URL.Query()
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	before, after := q.Get("before"), q.Get("after")
+
+	all := s.Messages(id)
+	msgs := make([]harmony.Message, 0, limit)
+	for i := len(all) - 1; i >= 0 && len(msgs) < limit; i-- {
+		m := all[i]
+		if (before != "" && !idLess(m.ID, before)) || (after != "" && !idLess(after, m.ID)) {
+			continue
+		}
+		msgs = append(msgs, m)
+	}
+	WriteJSON(w, http.StatusOK, msgs)
+}
+
+func (s *Server) getMessage(w http.ResponseWriter, r *http.Request) {
+	m := s.findMessage(Param(r, "channel"), Param(r, "message"))
+	if m == nil {
+		WriteError(w, http.StatusNotFound, codeUnknownMessage, "Unknown Message")
+		return
+	}
+	WriteJSON(w, http.StatusOK, m)
+}
+
+// messageBody is the body of requests creating or editing messages.
+type messageBody struct {
+	harmony.Message
+	// Embed is the single embed of the message, as sent by Harmony.
+	Embed *embed.Embed `json:"embed"`
+}
+
+// decodeMessage decodes the message in the body of the given request,
+// which is either JSON or multipart with files.
+func decodeMessage(r *http.Request) (*harmony.Message, error) {
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []message.Attachment
+	if payload, fs, err := parseMultipart(r.Header.Get("Content-Type"), b); err == nil {
+		b, files = payload, fs
+	}
+
+	var body messageBody
+	if err = json.Unmarshal(b, &body); err != nil {
+		return nil, err
+	}
+	m := body.Message
+	if body.Embed != nil {
+		m.Embeds = append(m.Embeds, *body.Embed)
+	}
+	m.Attachments = append(m.Attachments, files...)
+	return &m, nil
+}
+
+// createMessage creates a message authored by the bot and
+// sends it back to connected clients, as Discord does.
+func (s *Server) createMessage(w http.ResponseWriter, r *http.Request) {
+	id := Param(r, "channel")
+	ch := s.Channel(id)
+	if ch == nil {
+		WriteError(w, http.StatusNotFound, codeUnknownChannel, "Unknown Channel")
+		return
+	}
+
+	m, err := decodeMessage(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, 50109, "The request body contains invalid JSON.")
+		return
+	}
+	for i := range m.Attachments {
+		m.Attachments[i].ID = s.newID()
+	}
+	m.ID = ""
+	m.ChannelID = id
+	m.GuildID = ch.GuildID
+	m.Author = s.user
+	m.Timestamp = time.Time{}
+
+	WriteJSON(w, http.StatusOK, s.addMessage(m))
+}
+
+func (s *Server) editMessage(w http.ResponseWriter, r *http.Request) {
+	channelID, id := Param(r, "channel"), Param(r, "message")
+
+	edit, err := decodeMessage(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, 50109, "The request body contains invalid JSON.")
+		return
+	}
+
+	s.mu.Lock()
+	var edited *harmony.Message
+	for _, m := range s.messages[channelID] {
+		if m.ID == id {
+			if edit.Content != "" {
+				m.Content = edit.Content
+			}
+			if len(edit.Embeds) > 0 {
+				m.Embeds = edit.Embeds
+			}
+			if edit.Components != nil {
+				m.Components = edit.Components
+			}
+			m.EditedTimestamp = time.Now().UTC()
+			c := *m
+			edited = &c
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if edited == nil {
+		WriteError(w, http.StatusNotFound, codeUnknownMessage, "Unknown Message")
+		return
+	}
+	_ = s.Dispatch("MESSAGE_UPDATE", edited)
+	WriteJSON(w, http.StatusOK, edited)
+}
+
+func (s *Server) deleteMessage(w http.ResponseWriter, r *http.Request) {
+	channelID, id := Param(r, "channel"), Param(r, "message")
+
+	s.mu.Lock()
+	var deleted *harmony.Message
+	msgs := s.messages[channelID]
+	for i, m := range msgs {
+		if m.ID == id {
+			deleted = m
+			s.messages[channelID] = append(msgs[:i:i], msgs[i+1:]...)
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if deleted == nil {
+		WriteError(w, http.StatusNotFound, codeUnknownMessage, "Unknown Message")
+		return
+	}
+	_ = s.Dispatch("MESSAGE_DELETE", map[string]string{
+		"id":         deleted.ID,
+		"channel_id": deleted.ChannelID,
+		"guild_id":   deleted.GuildID,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) noContent(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findMessage returns a copy of the given message, or nil if there is none.
+func (s *Server) findMessage(channelID, id string) *harmony.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range s.messages[channelID] {
+		if m.ID == id {
+			c := *m
+			return &c
+		}
+	}
+	return nil
+}
+
+// parseMultipart returns the "payload_json" part of the given multipart
+// body and describes the files it holds as attachments.
+func parseMultipart(contentType string, body []byte) ([]byte, []message.Attachment, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, nil, errors.New("not a multipart body")
+	}
+
+	var (
+		payload []byte
+		files   []message.Attachment
+	)
+	mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		b, err := ioutil.ReadAll(part)
+		if err != nil {
+			return nil, nil, err
+		}
+		if part.FormName() == "payload_json" {
+			payload = b
+		} else if part.FileName() != "" {
+			files = append(files, message.Attachment{Filename: part.FileName(), Size: len(b)})
+		}
+	}
+	return payload, files, nil
+}
+
+// splitPath returns the segments of the given path, without its query.
+func splitPath(path string) []string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// matchSegments reports whether the segments of a path match the ones of a
+// route and returns the values of the placeholders of the route.
+func matchSegments(route, path []string) (map[string]string, bool) {
+	if len(route) != len(path) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, seg := range route {
+		if strings.HasPrefix(seg, ":") {
+			params[seg[1:]] = path[i]
+		} else if seg != path[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
@@ -0,0 +1,148 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+)
+
+// EnvTemplate is the environment variable holding the code of the guild
+// template disposable sandbox guilds are created from, see Main.
+const EnvTemplate = "HARMONY_TEST_TEMPLATE"
+
+// provisionTimeout is how long creating a guild from a template can take,
+// including the time for its channels to be created.
+const provisionTimeout = time.Minute
+
+// Guild is a disposable sandbox guild, created from a template with NewGuild.
+type Guild struct {
+	// ID of the guild.
+	ID string
+
+	client *harmony.Client
+}
+
+// Main runs the tests of a package, usually from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(sandbox.Main(m))
+//	}
+//
+// If the HARMONY_TEST_TEMPLATE environment variable is set and HARMONY_TEST_GUILD_ID
+// is not, it creates a disposable guild from this template before running the tests
+// and deletes it after, so tests run against a known structure. Sandboxes created
+// with New use this guild. Otherwise, it only runs the tests.
+func Main(m *testing.M) int {
+	token, code := os.Getenv(EnvToken), os.Getenv(EnvTemplate)
+	if token == "" || code == "" || os.Getenv(EnvGuildID) != "" {
+		return m.Run()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), provisionTimeout)
+	g, err := NewGuild(ctx, token, code)
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: could not create guild from template %q: %v\n", code, err)
+		return 1
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := g.Delete(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "sandbox: could not delete guild %s: %v\n", g.ID, err)
+		}
+	}()
+
+	if err = os.Setenv(EnvGuildID, g.ID); err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: could not set %s: %v\n", EnvGuildID, err)
+		return 1
+	}
+	defer os.Unsetenv(EnvGuildID)
+
+	return m.Run()
+}
+
+// NewGuild creates a disposable guild from the template with the given code,
+// using the given bot token and client options. The bot owns the guild and must
+// be in less than 10 guilds. It waits until the channels of the template are
+// created. Disposable guilds left over by test runs that crashed are deleted
+// first, once they are an hour old. The guild must be deleted with Delete.
+func NewGuild(ctx context.Context, token, code string, opts ...harmony.ClientOption) (*Guild, error) {
+	client, err := harmony.NewClient(token, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = purgeStaleGuilds(ctx, client); err != nil {
+		return nil, fmt.Errorf("could not purge stale guilds: %w", err)
+	}
+
+	tmpl, err := client.Template(code).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get template: %w", err)
+	}
+
+	name, err := newName("guild")
+	if err != nil {
+		return nil, err
+	}
+	created, err := client.Template(code).CreateGuild(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	g := &Guild{ID: created.ID, client: client}
+
+	if tmpl.SerializedSourceGuild != nil {
+		if err = g.waitChannels(ctx, len(tmpl.SerializedSourceGuild.Channels)); err != nil {
+			_ = g.Delete(context.Background())
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// Delete deletes the guild.
+func (g *Guild) Delete(ctx context.Context) error {
+	return g.client.Guild(g.ID).Delete(ctx)
+}
+
+// waitChannels waits until the guild has at least n channels.
+func (g *Guild) waitChannels(ctx context.Context, n int) error {
+	for {
+		chs, err := g.client.Guild(g.ID).Channels(ctx)
+		if err != nil {
+			return err
+		}
+		if len(chs) >= n {
+			return nil
+		}
+
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return fmt.Errorf("guild has %d channels out of %d: %w", len(chs), n, ctx.Err())
+		}
+	}
+}
+
+// purgeStaleGuilds deletes the disposable guilds owned by
+// the bot that were left over by test runs that crashed.
+func purgeStaleGuilds(ctx context.Context, client *harmony.Client) error {
+	guilds, err := client.CurrentUser().Guilds(ctx)
+	if err != nil {
+		return err
+	}
+	for _, g := range guilds {
+		if g.Owner && strings.HasPrefix(g.Name, NamePrefix+"guild-") && isStale(g.ID, g.Name) {
+			if err = client.Guild(g.ID).Delete(ctx); err != nil && !isNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,347 @@
+/*
+Package sandbox provides fixtures to test code built with Harmony against a
+real Discord guild dedicated to testing, the sandbox guild.
+
+The bot token and the ID of the guild are read from the HARMONY_TEST_BOT_TOKEN
+and HARMONY_TEST_GUILD_ID environment variables. Tests are skipped if they are
+not set, so integration tests can live next to unit tests, usually behind a
+build tag:
+
+	// +build e2e
+
+	func TestWelcome(t *testing.T) {
+		sb := sandbox.New(t)
+		defer sb.Close()
+
+		ch := sb.TextChannel()
+		if err := sendWelcome(sb.Client, ch.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+Resources created by a Sandbox are named with NamePrefix and deleted by Close,
+in the reverse order they were created. Resources left over by tests that
+crashed are deleted by New once they are an hour old. The bot must have the
+Administrator permission in the sandbox guild, which must not be used for
+anything else.
+
+Instead of a long lived sandbox guild, tests can run against a disposable
+guild created from a guild template when they start and deleted when they end,
+see Main.
+*/
+package sandbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/invite"
+	"github.com/skwair/harmony/role"
+)
+
+const (
+	// EnvToken is the environment variable holding the token of the bot.
+	EnvToken = "HARMONY_TEST_BOT_TOKEN"
+	// EnvGuildID is the environment variable holding the ID of the sandbox guild.
+	EnvGuildID = "HARMONY_TEST_GUILD_ID"
+
+	// NamePrefix starts the name of all resources created by a Sandbox.
+	NamePrefix = "harmony-e2e-"
+
+	// staleAfter is the age after which leftover resources are deleted.
+	staleAfter = time.Hour
+	// timeout of each request sent by fixtures.
+	timeout = 30 * time.Second
+)
+
+// Sandbox gives access to the sandbox guild and creates resources in it that
+// are deleted when the test ends. Create one with New and close it with Close.
+type Sandbox struct {
+	// Client of the bot. It is not connected to the Gateway, see Connect.
+	Client *harmony.Client
+	// GuildID is the ID of the sandbox guild.
+	GuildID string
+
+	tb testing.TB
+
+	mu       sync.Mutex
+	cleanups []cleanup
+}
+
+// cleanup deletes a resource created by a Sandbox.
+type cleanup struct {
+	what string
+	fn   func(ctx context.Context) error
+}
+
+// New returns a Sandbox for the current test, skipping it if the environment
+// variables configuring the sandbox guild are not set. The given options are
+// used to create its client.
+func New(tb testing.TB, opts ...harmony.ClientOption) *Sandbox {
+	tb.Helper()
+
+	token, guildID := os.Getenv(EnvToken), os.Getenv(EnvGuildID)
+	if token == "" || guildID == "" {
+		tb.Skipf("%s and %s must be set to run tests against a sandbox guild", EnvToken, EnvGuildID)
+	}
+
+	client, err := harmony.NewClient(token, opts...)
+	if err != nil {
+		tb.Fatalf("could not create client: %v", err)
+	}
+
+	sb := &Sandbox{
+		Client:  client,
+		GuildID: guildID,
+		tb:      tb,
+	}
+	sb.purgeStale()
+	return sb
+}
+
+// Guild returns the resource of the sandbox guild.
+func (sb *Sandbox) Guild() *harmony.GuildResource {
+	return sb.Client.Guild(sb.GuildID)
+}
+
+// Name returns a new unique name for a resource of the given kind, such as
+// "channel", starting with NamePrefix.
+func (sb *Sandbox) Name(kind string) string {
+	name, err := newName(kind)
+	if err != nil {
+		sb.tb.Fatalf("could not generate name: %v", err)
+	}
+	return name
+}
+
+// newName returns a new unique name for a resource of the given kind.
+func newName(kind string) (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return NamePrefix + kind + "-" + hex.EncodeToString(b), nil
+}
+
+// Connect connects the client to the Gateway. It is disconnected by Close.
+func (sb *Sandbox) Connect() {
+	sb.tb.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := sb.Client.Connect(ctx); err != nil {
+		sb.tb.Fatalf("could not connect to the Gateway: %v", err)
+	}
+	sb.Defer("gateway connection", func(context.Context) error {
+		sb.Client.Disconnect()
+		return nil
+	})
+}
+
+// Defer registers a function deleting the given resource when the sandbox is
+// closed, for resources that are not created by fixtures. Errors caused by
+// resources that were already deleted are ignored.
+func (sb *Sandbox) Defer(what string, fn func(ctx context.Context) error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	sb.cleanups = append(sb.cleanups, cleanup{what: what, fn: fn})
+}
+
+// Close deletes all resources created by the sandbox, in the reverse
+// order they were created. Failures are reported as test errors.
+func (sb *Sandbox) Close() {
+	sb.tb.Helper()
+
+	sb.mu.Lock()
+	cleanups := sb.cleanups
+	sb.cleanups = nil
+	sb.mu.Unlock()
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		c := cleanups[i]
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		if err := c.fn(ctx); err != nil && !isNotFound(err) {
+			sb.tb.Errorf("could not delete %s: %v", c.what, err)
+		}
+		cancel()
+	}
+}
+
+// TextChannel creates a text channel in the sandbox guild. The given settings
+// are applied after the default ones, which set its name and type.
+func (sb *Sandbox) TextChannel(settings ...channel.Setting) *harmony.Channel {
+	sb.tb.Helper()
+
+	return sb.channel(channel.TypeGuildText, settings)
+}
+
+// VoiceChannel creates a voice channel in the sandbox guild. The given settings
+// are applied after the default ones, which set its name and type.
+func (sb *Sandbox) VoiceChannel(settings ...channel.Setting) *harmony.Channel {
+	sb.tb.Helper()
+
+	return sb.channel(channel.TypeGuildVoice, settings)
+}
+
+// Category creates a channel category in the sandbox guild. The given settings
+// are applied after the default ones, which set its name and type.
+func (sb *Sandbox) Category(settings ...channel.Setting) *harmony.Channel {
+	sb.tb.Helper()
+
+	return sb.channel(channel.TypeGuildCategory, settings)
+}
+
+func (sb *Sandbox) channel(typ channel.Type, settings []channel.Setting) *harmony.Channel {
+	sb.tb.Helper()
+
+	opts := append([]channel.Setting{
+		channel.WithName(sb.Name("channel")),
+		channel.WithType(typ),
+	}, settings...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ch, err := sb.Guild().NewChannel(ctx, channel.NewSettings(opts...))
+	if err != nil {
+		sb.tb.Fatalf("could not create channel: %v", err)
+	}
+	sb.Defer(fmt.Sprintf("channel %q", ch.Name), func(ctx context.Context) error {
+		_, err := sb.Client.Channel(ch.ID).Delete(ctx)
+		return err
+	})
+	return ch
+}
+
+// Role creates a role in the sandbox guild. The given settings are
+// applied after the default one, which sets its name.
+func (sb *Sandbox) Role(settings ...role.Setting) *harmony.Role {
+	sb.tb.Helper()
+
+	opts := append([]role.Setting{role.WithName(sb.Name("role"))}, settings...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	r, err := sb.Guild().NewRole(ctx, role.NewSettings(opts...))
+	if err != nil {
+		sb.tb.Fatalf("could not create role: %v", err)
+	}
+	sb.Defer(fmt.Sprintf("role %q", r.Name), func(ctx context.Context) error {
+		return sb.Guild().DeleteRole(ctx, r.ID)
+	})
+	return r
+}
+
+// Message sends a message with the given content to the given channel.
+func (sb *Sandbox) Message(channelID, content string) *harmony.Message {
+	sb.tb.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	m, err := sb.Client.Channel(channelID).SendMessage(ctx, content)
+	if err != nil {
+		sb.tb.Fatalf("could not send message: %v", err)
+	}
+	sb.Defer("message "+m.ID, func(ctx context.Context) error {
+		return sb.Client.Channel(channelID).DeleteMessage(ctx, m.ID)
+	})
+	return m
+}
+
+// Webhook creates a webhook in the given channel.
+func (sb *Sandbox) Webhook(channelID string) *harmony.Webhook {
+	sb.tb.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	wh, err := sb.Client.Channel(channelID).NewWebhook(ctx, sb.Name("webhook"), "")
+	if err != nil {
+		sb.tb.Fatalf("could not create webhook: %v", err)
+	}
+	sb.Defer(fmt.Sprintf("webhook %q", wh.Name), func(ctx context.Context) error {
+		return sb.Client.Webhook(wh.ID).Delete(ctx)
+	})
+	return wh
+}
+
+// Invite creates an invite to the given channel.
+func (sb *Sandbox) Invite(channelID string, settings ...invite.Setting) *harmony.Invite {
+	sb.tb.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	i, err := sb.Client.Channel(channelID).NewInvite(ctx, invite.NewSettings(settings...))
+	if err != nil {
+		sb.tb.Fatalf("could not create invite: %v", err)
+	}
+	sb.Defer("invite "+i.Code, func(ctx context.Context) error {
+		_, err := sb.Client.Invite(i.Code).Delete(ctx, "")
+		return err
+	})
+	return i
+}
+
+// purgeStale deletes the channels and roles left over in the sandbox guild by
+// tests that did not close their sandbox. Recent ones are kept since they can
+// belong to tests running concurrently.
+func (sb *Sandbox) purgeStale() {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	chs, err := sb.Guild().Channels(ctx)
+	if err != nil {
+		sb.tb.Logf("could not list channels to purge: %v", err)
+	}
+	for _, ch := range chs {
+		if isStale(ch.ID, ch.Name) {
+			if _, err = sb.Client.Channel(ch.ID).Delete(ctx); err != nil && !isNotFound(err) {
+				sb.tb.Logf("could not delete stale channel %q: %v", ch.Name, err)
+			}
+		}
+	}
+
+	roles, err := sb.Guild().Roles(ctx)
+	if err != nil {
+		sb.tb.Logf("could not list roles to purge: %v", err)
+	}
+	for _, r := range roles {
+		if isStale(r.ID, r.Name) {
+			if err = sb.Guild().DeleteRole(ctx, r.ID); err != nil && !isNotFound(err) {
+				sb.tb.Logf("could not delete stale role %q: %v", r.Name, err)
+			}
+		}
+	}
+}
+
+// isStale reports whether the resource with the given ID and name
+// was created by a Sandbox that did not delete it.
+func isStale(id, name string) bool {
+	if !strings.HasPrefix(name, NamePrefix) {
+		return false
+	}
+	created, err := harmony.CreationTimeOf(id)
+	return err == nil && time.Since(created) > staleAfter
+}
+
+// isNotFound reports whether err is caused by a resource that does not exist.
+func isNotFound(err error) bool {
+	var apiErr harmony.APIError
+	return errors.As(err, &apiErr) && apiErr.HTTPCode == http.StatusNotFound
+}
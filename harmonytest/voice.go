@@ -0,0 +1,211 @@
+package harmonytest
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"nhooyr.io/websocket"
+
+	"github.com/skwair/harmony/internal/payload"
+	"github.com/skwair/harmony/voice"
+)
+
+// Voice opcodes, see https://discord.com/developers/docs/topics/opcodes-and-status-codes#voice.
+const (
+	voiceOpIdentify           = 0
+	voiceOpSelectProtocol     = 1
+	voiceOpReady              = 2
+	voiceOpHeartbeat          = 3
+	voiceOpSessionDescription = 4
+	voiceOpHeartbeatACK       = 6
+	voiceOpHello              = 8
+)
+
+const (
+	// voiceToken is the token sent to clients in Voice Server Update events.
+	voiceToken = "harmonytest-voice-token"
+	// ipDiscoverySize is the size of IP discovery packets.
+	ipDiscoverySize = 70
+	// udpHeartbeatSize is the size of UDP heartbeat packets.
+	udpHeartbeatSize = 8
+)
+
+// voiceServer is a fake voice server. It accepts voice connections over a TLS
+// websocket, since clients always dial voice servers with wss, and answers IP
+// discovery and heartbeats over UDP. Audio packets are ignored.
+type voiceServer struct {
+	srv  *httptest.Server
+	udp  *net.UDPConn
+	ssrc uint32
+}
+
+// newVoiceServer starts and returns a new voiceServer.
+// It must be closed with close.
+func newVoiceServer() *voiceServer {
+	udp, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		panic("harmonytest: could not listen on UDP: " + err.Error())
+	}
+
+	v := &voiceServer{udp: udp}
+	v.srv = httptest.NewTLSServer(http.HandlerFunc(v.serveWebsocket))
+	go v.serveUDP()
+	return v
+}
+
+// close shuts down the voice server.
+func (v *voiceServer) close() {
+	v.srv.Close()
+	_ = v.udp.Close()
+}
+
+// endpoint returns the endpoint of the voice server, as sent in Voice Server Update events.
+func (v *voiceServer) endpoint() string {
+	return v.srv.Listener.Addr().String()
+}
+
+// VoiceConnectionOptions returns the options to give to harmony.WithVoiceConnectionOptions
+// so voice connections joined with JoinVoiceChannel trust the fake voice server of s.
+func (s *Server) VoiceConnectionOptions() []voice.ConnectionOption {
+	return []voice.ConnectionOption{voice.WithHTTPClient(s.voice.srv.Client())}
+}
+
+// updateVoiceState answers a Voice State Update payload with a Voice State Update
+// event and, unless the payload leaves a voice channel, a Voice Server Update
+// event pointing to the fake voice server of s.
+func (s *Server) updateVoiceState(gc *gatewayConn, d json.RawMessage) error {
+	var req struct {
+		GuildID   string  `json:"guild_id"`
+		ChannelID *string `json:"channel_id"`
+		SelfMute  bool    `json:"self_mute"`
+		SelfDeaf  bool    `json:"self_deaf"`
+	}
+	if err := json.Unmarshal(d, &req); err != nil {
+		return err
+	}
+
+	state := map[string]interface{}{
+		"guild_id":   req.GuildID,
+		"channel_id": req.ChannelID,
+		"user_id":    s.user.ID,
+		"session_id": strconv.FormatInt(time.Now().UnixNano(), 36),
+		"self_mute":  req.SelfMute,
+		"self_deaf":  req.SelfDeaf,
+	}
+
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	if err := s.sendEventLocked(gc, "VOICE_STATE_UPDATE", state); err != nil {
+		return err
+	}
+	if req.ChannelID == nil {
+		return nil
+	}
+
+	server := map[string]interface{}{
+		"token":    voiceToken,
+		"guild_id": req.GuildID,
+		"endpoint": s.voice.endpoint(),
+	}
+	return s.sendEventLocked(gc, "VOICE_SERVER_UPDATE", server)
+}
+
+// serveWebsocket performs the voice handshake with a client, then
+// acknowledges its heartbeats until it disconnects.
+func (v *voiceServer) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "")
+
+	ctx := r.Context()
+	hello := map[string]interface{}{"heartbeat_interval": heartbeatInterval}
+	if err = sendVoicePayload(ctx, conn, voiceOpHello, hello); err != nil {
+		return
+	}
+
+	for {
+		var p payload.Payload
+		_, b, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		if err = json.Unmarshal(b, &p); err != nil {
+			_ = conn.Close(websocket.StatusUnsupportedData, "invalid payload")
+			return
+		}
+
+		switch p.Op {
+		case voiceOpIdentify:
+			ready := map[string]interface{}{
+				"ssrc":  atomic.AddUint32(&v.ssrc, 1),
+				"ip":    "127.0.0.1",
+				"port":  v.udp.LocalAddr().(*net.UDPAddr).Port,
+				"modes": []string{"xsalsa20_poly1305"},
+			}
+			err = sendVoicePayload(ctx, conn, voiceOpReady, ready)
+
+		case voiceOpSelectProtocol:
+			desc := map[string]interface{}{
+				"mode":       "xsalsa20_poly1305",
+				"secret_key": make([]byte, 32),
+			}
+			err = sendVoicePayload(ctx, conn, voiceOpSessionDescription, desc)
+
+		case voiceOpHeartbeat:
+			err = sendVoicePayload(ctx, conn, voiceOpHeartbeatACK, p.D)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// serveUDP answers IP discovery requests with the address they were sent from
+// and echoes UDP heartbeats, until the voice server is closed.
+func (v *voiceServer) serveUDP() {
+	b := make([]byte, 1500)
+	for {
+		n, addr, err := v.udp.ReadFromUDP(b)
+		if err != nil {
+			return
+		}
+
+		switch n {
+		case ipDiscoverySize:
+			resp := make([]byte, ipDiscoverySize)
+			copy(resp, b[:4])
+			copy(resp[4:], addr.IP.String())
+			binary.LittleEndian.PutUint16(resp[ipDiscoverySize-2:], uint16(addr.Port))
+			_, _ = v.udp.WriteToUDP(resp, addr)
+
+		case udpHeartbeatSize:
+			_, _ = v.udp.WriteToUDP(b[:n], addr)
+		}
+	}
+}
+
+// sendVoicePayload sends a payload with the given opcode and data over a voice websocket connection.
+func sendVoicePayload(ctx context.Context, conn *websocket.Conn, op int, data interface{}) error {
+	d, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(&payload.Payload{Op: op, D: d})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return conn.Write(ctx, websocket.MessageText, b)
+}
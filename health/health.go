@@ -0,0 +1,209 @@
+/*
+Package health provides an http.Handler reporting the health of harmony
+clients, designed to be used as liveness and readiness probes (for instance
+by Kubernetes):
+
+	h := health.NewHandler(client)
+	http.Handle("/healthz/", http.StripPrefix("/healthz", h))
+
+With a harmony.ShardManager, whose clients are only created once it connects,
+use NewShardHandler instead:
+
+	h := health.NewShardHandler(manager)
+
+This exposes three endpoints, all responding with JSON:
+
+	- /livez always responds with 200 OK as long as the process is up.
+	- /readyz responds with 200 OK if there is at least one client, all shards
+	  have a client, and all clients are connected to the Gateway, received
+	  their Ready event and recently received a heartbeat acknowledgement.
+	  It responds with 503 Service Unavailable if not.
+	- /status responds with 200 OK and the detailed status of each shard.
+*/
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/clock"
+)
+
+// DefaultMaxHeartbeatAge is the default maximum age of the last heartbeat
+// acknowledgement of a ready client. The Gateway asks clients to send a
+// heartbeat about every 41 seconds, so this allows a couple to be missed.
+const DefaultMaxHeartbeatAge = 2 * time.Minute
+
+// ShardStatus is the status of a single client (or shard).
+type ShardStatus struct {
+	// Shard is the ID of the shard and Total the total number of
+	// shards. Both are 0 if sharding is not enabled.
+	Shard int `json:"shard"`
+	Total int `json:"total"`
+	// Connected is true if the client is connected to the Gateway
+	// and received its Ready event.
+	Connected    bool `json:"connected"`
+	Reconnecting bool `json:"reconnecting"`
+	// LastHeartbeatACK is the time of the last heartbeat acknowledged
+	// by the Gateway. Omitted if no heartbeat was acknowledged yet.
+	LastHeartbeatACK *time.Time `json:"last_heartbeat_ack,omitempty"`
+	// HeartbeatStale is true if the last heartbeat was acknowledged
+	// too long ago, see WithMaxHeartbeatAge.
+	HeartbeatStale bool `json:"heartbeat_stale,omitempty"`
+}
+
+// Status is the status reported by the handler.
+type Status struct {
+	// Status is either "ok" or "unavailable".
+	Status string        `json:"status"`
+	Shards []ShardStatus `json:"shards,omitempty"`
+}
+
+// ShardProvider provides the clients whose health is reported,
+// typically one per shard. *harmony.ShardManager implements it.
+type ShardProvider interface {
+	Shards() []*harmony.Client
+}
+
+// Clients is a ShardProvider providing a fixed set of clients.
+type Clients []*harmony.Client
+
+// Shards implements the ShardProvider interface.
+func (c Clients) Shards() []*harmony.Client {
+	return c
+}
+
+// Handler is an http.Handler reporting the health of a set of clients.
+type Handler struct {
+	shards          ShardProvider
+	maxHeartbeatAge time.Duration
+	clock           clock.Clock
+}
+
+// Option is a function that configures a Handler.
+type Option func(*Handler)
+
+// WithMaxHeartbeatAge sets the maximum age of the last heartbeat
+// acknowledgement of a client for it to be ready. Clients that did not
+// receive any acknowledgement yet, such as the ones that just connected,
+// are not affected. Defaults to DefaultMaxHeartbeatAge.
+func WithMaxHeartbeatAge(d time.Duration) Option {
+	return func(h *Handler) {
+		h.maxHeartbeatAge = d
+	}
+}
+
+// WithClock sets the clock used by the handler to compute the age of
+// heartbeat acknowledgements, mainly for testing purposes. It should be
+// the one of the clients. Defaults to the system clock.
+func WithClock(c clock.Clock) Option {
+	return func(h *Handler) {
+		h.clock = c
+	}
+}
+
+// NewHandler returns a new Handler reporting the health of the given clients,
+// typically one per shard.
+func NewHandler(clients ...*harmony.Client) *Handler {
+	return NewShardHandler(Clients(clients))
+}
+
+// NewShardHandler returns a new Handler reporting the health of the clients
+// returned by the given provider each time it is called.
+func NewShardHandler(p ShardProvider, opts ...Option) *Handler {
+	h := &Handler{
+		shards:          p,
+		maxHeartbeatAge: DefaultMaxHeartbeatAge,
+		clock:           clock.New(),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/livez"):
+		h.Live(w, r)
+	case strings.HasSuffix(r.URL.Path, "/readyz"):
+		h.Ready(w, r)
+	case strings.HasSuffix(r.URL.Path, "/status"):
+		h.Status(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Live reports that the process is up.
+func (h *Handler) Live(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &Status{Status: "ok"})
+}
+
+// Ready reports whether all clients are connected to the Gateway, see the
+// package documentation.
+func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
+	s := h.status()
+
+	code := http.StatusOK
+	if s.Status != "ok" {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, s)
+}
+
+// Status reports the detailed status of all clients. Unlike Ready,
+// it always responds with 200 OK.
+func (h *Handler) Status(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.status())
+}
+
+func (h *Handler) status() *Status {
+	s := &Status{Status: "ok"}
+
+	clients := h.shards.Shards()
+	if len(clients) == 0 {
+		s.Status = "unavailable"
+	}
+
+	now := h.clock.Now()
+	for _, c := range clients {
+		shard, total := c.Shard()
+		ss := ShardStatus{
+			Shard:        shard,
+			Total:        total,
+			Connected:    c.Connected(),
+			Reconnecting: c.Reconnecting(),
+		}
+		if ack := c.LastHeartbeatACK(); !ack.IsZero() {
+			ss.LastHeartbeatACK = &ack
+			ss.HeartbeatStale = now.Sub(ack) > h.maxHeartbeatAge
+		}
+
+		// Clients of a shard manager are created as shards
+		// start, some shards may not have a client yet.
+		if !ss.Connected || ss.HeartbeatStale || len(clients) < total {
+			s.Status = "unavailable"
+		}
+		s.Shards = append(s.Shards, ss)
+	}
+
+	return s
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+var _ ShardProvider = (*harmony.ShardManager)(nil)
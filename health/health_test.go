@@ -0,0 +1,109 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/clock"
+	"github.com/skwair/harmony/harmonytest"
+)
+
+// shards is a ShardProvider whose clients can change.
+type shards struct {
+	clients []*harmony.Client
+}
+
+func (s *shards) Shards() []*harmony.Client {
+	return s.clients
+}
+
+func TestReady(t *testing.T) {
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	clk := clock.NewMock(time.Unix(0, 0))
+	c, err := srv.NewClient(harmony.WithClock(clk))
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	disconnected, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = c.Connect(ctx); err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer c.Disconnect()
+
+	// Wait for the heartbeater to start, then make it send a heartbeat.
+	for clk.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clk.Add(time.Minute)
+	for c.LastHeartbeatACK().IsZero() {
+		select {
+		case <-time.After(time.Millisecond):
+		case <-ctx.Done():
+			t.Fatal("expected a heartbeat to be acknowledged")
+		}
+	}
+	ack := c.LastHeartbeatACK()
+
+	tests := []struct {
+		name     string
+		clients  []*harmony.Client
+		now      time.Time
+		expected int
+	}{
+		{name: "no clients", expected: http.StatusServiceUnavailable},
+		{name: "connected", clients: []*harmony.Client{c}, now: ack, expected: http.StatusOK},
+		{name: "recent heartbeat", clients: []*harmony.Client{c}, now: ack.Add(DefaultMaxHeartbeatAge), expected: http.StatusOK},
+		{name: "stale heartbeat", clients: []*harmony.Client{c}, now: ack.Add(DefaultMaxHeartbeatAge + 1), expected: http.StatusServiceUnavailable},
+		{name: "disconnected", clients: []*harmony.Client{c, disconnected}, now: ack, expected: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewShardHandler(&shards{clients: tt.clients}, WithClock(clock.NewMock(tt.now)))
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if w.Code != tt.expected {
+				t.Errorf("expected status code %d, got %d: %s", tt.expected, w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestReadyMissingShards(t *testing.T) {
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	// The first of two shards is connected, the second one has no client yet.
+	c, err := srv.NewClient(harmony.WithSharding(0, 2))
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = c.Connect(ctx); err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer c.Disconnect()
+
+	w := httptest.NewRecorder()
+	NewHandler(c).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status code %d, got %d: %s", http.StatusServiceUnavailable, w.Code, w.Body)
+	}
+}
@@ -14,6 +14,7 @@ func (c *Client) heartbeat(every time.Duration) {
 	defer c.logger.Debug("stopped gateway heartbeater")
 
 	heartbeat.Run(
+		c.clock,
 		every,
 		c.sendHeartbeatPayload,
 		c.lastHeartbeatACK,
@@ -29,6 +30,6 @@ func (c *Client) sendHeartbeatPayload() error {
 	if seq := c.sequence.Load(); seq != 0 {
 		sequence = &seq
 	}
-	c.lastHeartbeatSend.Store(time.Now().UnixNano())
+	c.lastHeartbeatSend.Store(c.clock.Now().UnixNano())
 	return c.sendPayload(c.ctx, gatewayOpcodeHeartbeat, sequence)
 }
@@ -8,22 +8,26 @@ import (
 
 // identify is used to trigger the initial handshake with the gateway.
 type identify struct {
-	Token              string            `json:"token"`
-	Properties         map[string]string `json:"properties"`
-	Compress           bool              `json:"compress,omitempty"`
-	LargeThreshold     int               `json:"large_threshold,omitempty"`
-	Shard              *[2]int           `json:"shard,omitempty"`
-	Presence           *Status           `json:"presence,omitempty"`
-	GuildSubscriptions bool              `json:"guild_subscriptions"`
-	Intents            GatewayIntent     `json:"intents"`
+	Token          string            `json:"token"`
+	Properties     map[string]string `json:"properties"`
+	Compress       bool              `json:"compress,omitempty"`
+	LargeThreshold int               `json:"large_threshold,omitempty"`
+	Shard          *[2]int           `json:"shard,omitempty"`
+	Presence       *Status           `json:"presence,omitempty"`
+	Intents        GatewayIntent     `json:"intents"`
 }
 
 // Status is sent by the client to indicate a presence or status update.
+// Create one with NewStatus.
 type Status struct {
-	Since  int       `json:"since"`
-	Game   *Activity `json:"game"`
-	Status string    `json:"status"`
-	AFK    bool      `json:"afk"`
+	// Unix time, in milliseconds, since when the client is idle, if AFK.
+	Since int64 `json:"since"`
+	// Deprecated: use Activities instead.
+	Game       *Activity  `json:"game,omitempty"`
+	Activities []Activity `json:"activities"`
+	// Either StatusOnline, StatusDND, StatusIdle, StatusInvisible or StatusOffline.
+	Status string `json:"status"`
+	AFK    bool   `json:"afk"`
 }
 
 // identify sends an Identify payload to the Gateway.
@@ -34,10 +38,10 @@ func (c *Client) identify(ctx context.Context) error {
 			"$os":      strings.Title(runtime.GOOS),
 			"$browser": "github.com/skwair/harmony",
 		},
-		Compress:           true,
-		LargeThreshold:     c.largeThreshold,
-		GuildSubscriptions: c.guildSubscriptions,
-		Intents:            c.intents,
+		Compress:       !c.compression,
+		LargeThreshold: c.largeThreshold,
+		Presence:       c.initialPresence,
+		Intents:        c.intents,
 	}
 
 	if c.shard[1] != 0 {
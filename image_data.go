@@ -0,0 +1,68 @@
+package harmony
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+)
+
+// Maximum sizes of images accepted by Discord, to be used with ImageData
+// and ImageDataFromFile.
+const (
+	MaxAvatarSize  = 10 * 1024 * 1024
+	MaxBannerSize  = 10 * 1024 * 1024
+	MaxEmojiSize   = 256 * 1024
+	MaxStickerSize = 512 * 1024
+)
+
+var supportedImageContentTypes = []string{
+	"image/png",
+	"image/gif",
+	"image/jpeg",
+}
+
+// ImageData reads an image from r and returns it encoded in the Data URI scheme
+// expected by Discord when setting avatars, banners, webhook avatars or emojis,
+// such as:
+//
+//     data:image/jpeg;base64,BASE64_ENCODED_JPEG_IMAGE_DATA
+//
+// The content type of the image is detected from its content and must be either
+// PNG, JPEG or GIF. If the image is larger than maxSize bytes, ErrImageTooLarge
+// is returned. A maxSize of 0 or less disables this check.
+func ImageData(r io.Reader, maxSize int64) (string, error) {
+	if maxSize > 0 {
+		// Read one more byte than allowed to detect images that are too large.
+		r = io.LimitReader(r, maxSize+1)
+	}
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	if maxSize > 0 && int64(len(b)) > maxSize {
+		return "", ErrImageTooLarge
+	}
+
+	ct := http.DetectContentType(b)
+	if !stringsContains(supportedImageContentTypes, ct) {
+		return "", fmt.Errorf("unsupported image Content-Type: %q", ct)
+	}
+
+	return "data:" + ct + ";base64," + base64.StdEncoding.EncodeToString(b), nil
+}
+
+// ImageDataFromFile is like ImageData but reads the image from a local, on disk file.
+func ImageDataFromFile(filepath string, maxSize int64) (string, error) {
+	f, err := os.Open(filepath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	return ImageData(f, maxSize)
+}
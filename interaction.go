@@ -0,0 +1,171 @@
+package harmony
+
+import (
+	"encoding/json"
+
+	"github.com/skwair/harmony/command"
+	"github.com/skwair/harmony/component"
+	"github.com/skwair/harmony/message"
+)
+
+// InteractionType is the type of an interaction.
+type InteractionType int
+
+// List of interaction types.
+const (
+	InteractionTypePing                           InteractionType = 1
+	InteractionTypeApplicationCommand             InteractionType = 2
+	InteractionTypeMessageComponent               InteractionType = 3
+	InteractionTypeApplicationCommandAutocomplete InteractionType = 4
+	InteractionTypeModalSubmit                    InteractionType = 5
+)
+
+// Interaction is sent when a user uses an application command, interacts with a
+// message component or submits a modal. It must be responded to within 3 seconds,
+// see Client.Interaction.
+type Interaction struct {
+	ID            string          `json:"id"`
+	ApplicationID string          `json:"application_id"`
+	Type          InteractionType `json:"type"`
+	// Data of the interaction, set for all types except pings.
+	Data      *InteractionData `json:"data"`
+	GuildID   string           `json:"guild_id"`
+	ChannelID string           `json:"channel_id"`
+	// Partial channel the interaction was triggered in, see NSFW.
+	Channel *Channel `json:"channel"`
+	// Member that triggered the interaction, when it was triggered in a guild.
+	Member *GuildMember `json:"member"`
+	// User that triggered the interaction, when it was triggered in a DM.
+	User *User `json:"user"`
+	// Token used to respond to the interaction, valid for 15 minutes.
+	Token   string `json:"token"`
+	Version int    `json:"version"`
+	// Message the component was attached to, for message component interactions.
+	Message *Message `json:"message"`
+	// Permissions of the application in the channel, as a bit set.
+	AppPermissions string `json:"app_permissions"`
+	// Locale of the user that triggered the interaction.
+	Locale string `json:"locale"`
+	// Preferred locale of the guild, if triggered in a guild.
+	GuildLocale string `json:"guild_locale"`
+}
+
+// Author returns the user that triggered the interaction, in a guild or in a DM.
+func (i *Interaction) Author() *User {
+	if i.Member != nil {
+		return i.Member.User
+	}
+	return i.User
+}
+
+// InteractionData is the data of an interaction.
+type InteractionData struct {
+	// Following fields are set for application command interactions.
+
+	ID       string                   `json:"id"`
+	Name     string                   `json:"name"`
+	Type     command.Type             `json:"type"`
+	Resolved *InteractionResolvedData `json:"resolved"`
+	Options  []InteractionDataOption  `json:"options"`
+	GuildID  string                   `json:"guild_id"`
+	// ID of the user or message the command was used on,
+	// for user and message commands.
+	TargetID string `json:"target_id"`
+
+	// Following fields are set for message component and modal submit interactions.
+
+	CustomID      string         `json:"custom_id"`
+	ComponentType component.Type `json:"component_type"`
+	// Values picked by the user, for select menus.
+	Values []string `json:"values"`
+	// Components of the submitted modal, holding the text
+	// inputs filled in by the user, for modal submit interactions.
+	Components []component.ActionRow `json:"components"`
+}
+
+// TextInputValue returns the value typed by the user in the text input with
+// the given custom ID of a submitted modal. It returns an empty string if
+// there is no such text input.
+func (d *InteractionData) TextInputValue(customID string) string {
+	for _, row := range d.Components {
+		for _, c := range row.Components {
+			if input, ok := c.(*component.TextInput); ok && input.CustomID == customID {
+				return input.Value
+			}
+		}
+	}
+	return ""
+}
+
+// Option returns the option with the given name, or nil if it was not set.
+func (d *InteractionData) Option(name string) *InteractionDataOption {
+	return findOption(d.Options, name)
+}
+
+// InteractionDataOption is the value of an option given by the user to an application command.
+type InteractionDataOption struct {
+	Name string             `json:"name"`
+	Type command.OptionType `json:"type"`
+	// Raw value of the option. Use the typed accessors to read it.
+	Value json.RawMessage `json:"value"`
+	// Options of a sub command or sub command group.
+	Options []InteractionDataOption `json:"options"`
+	// Whether this option is the one the user is currently
+	// typing, for autocomplete interactions.
+	Focused bool `json:"focused"`
+}
+
+// Option returns the sub option with the given name, or nil if it was not set.
+func (o *InteractionDataOption) Option(name string) *InteractionDataOption {
+	return findOption(o.Options, name)
+}
+
+// String returns the value of a string option. For user, channel, role, mentionable
+// and attachment options, it is the ID of the entity, see InteractionResolvedData.
+func (o *InteractionDataOption) String() string {
+	var s string
+	_ = json.Unmarshal(o.Value, &s)
+	return s
+}
+
+// Int returns the value of an integer option.
+func (o *InteractionDataOption) Int() int {
+	var i int
+	_ = json.Unmarshal(o.Value, &i)
+	return i
+}
+
+// Float returns the value of a number option.
+func (o *InteractionDataOption) Float() float64 {
+	var f float64
+	_ = json.Unmarshal(o.Value, &f)
+	return f
+}
+
+// Bool returns the value of a boolean option.
+func (o *InteractionDataOption) Bool() bool {
+	var b bool
+	_ = json.Unmarshal(o.Value, &b)
+	return b
+}
+
+func findOption(opts []InteractionDataOption, name string) *InteractionDataOption {
+	for i := range opts {
+		if opts[i].Name == name {
+			return &opts[i]
+		}
+	}
+	return nil
+}
+
+// InteractionResolvedData holds the entities referenced by the options
+// of an application command, by ID.
+type InteractionResolvedData struct {
+	Users map[string]User `json:"users"`
+	// Members lack the User field, see Users.
+	Members     map[string]GuildMember        `json:"members"`
+	Roles       map[string]Role               `json:"roles"`
+	Channels    map[string]Channel            `json:"channels"`
+	Messages    map[string]Message            `json:"messages"`
+	Attachments map[string]message.Attachment `json:"attachments"`
+}
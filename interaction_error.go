@@ -0,0 +1,159 @@
+package harmony
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DefaultInternalErrorMessage is the message shown to users by
+// InteractionResource.RespondError for errors that are not user facing,
+// unless ErrorMessages define an InternalErrorKey message for their locale.
+const DefaultInternalErrorMessage = "Something went wrong, please try again later."
+
+// InternalErrorKey is the key of the message shown to users for errors
+// that are not user facing, see ErrorMessages.
+const InternalErrorKey = "internal"
+
+// UserFacingError is an error meant to be shown to the user that triggered an
+// interaction, for instance because of an invalid option, as opposed to internal
+// errors whose details should not be shown to users. Respond to an interaction
+// with InteractionResource.RespondError to show it in the locale of the user.
+type UserFacingError struct {
+	// Key of the message in ErrorMessages, to show it in the locale of the user.
+	Key string
+	// Data given to the template of the message.
+	Data interface{}
+	// Message shown if there is no message for Key in the locale of the user.
+	Message string
+	// Underlying error, if any. It is never shown to users.
+	Err error
+}
+
+// NewUserFacingError returns a new user facing error shown with the message with
+// the given key, or with the given fallback message if there is none.
+func NewUserFacingError(key, fallback string, data interface{}) *UserFacingError {
+	return &UserFacingError{Key: key, Data: data, Message: fallback}
+}
+
+func (e *UserFacingError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Key
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", msg, e.Err)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error, if any.
+func (e *UserFacingError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorMessages are the messages shown to users for user facing errors, by locale.
+// Messages are text/template templates executed with the Data of the error. Set
+// them with WithErrorMessages. It is not safe to add messages while in use.
+type ErrorMessages struct {
+	defaultLocale string
+	templates     map[string]map[string]*template.Template // Templates by locale, then by key.
+}
+
+// NewErrorMessages returns new empty error messages. The given locale is used
+// when there is no message for the locale of the user, for instance "en-US".
+func NewErrorMessages(defaultLocale string) *ErrorMessages {
+	return &ErrorMessages{
+		defaultLocale: defaultLocale,
+		templates:     make(map[string]map[string]*template.Template),
+	}
+}
+
+// Add adds the message with the given key in the given locale, for instance
+// "fr" or "pt-BR". It returns an error if the message is not a valid template.
+func (m *ErrorMessages) Add(locale, key, text string) error {
+	tmpl, err := template.New(key).Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid error message %q for locale %q: %w", key, locale, err)
+	}
+
+	if m.templates[locale] == nil {
+		m.templates[locale] = make(map[string]*template.Template)
+	}
+	m.templates[locale][key] = tmpl
+	return nil
+}
+
+// Render returns the message to show to a user with the given locale for the
+// given error. User facing errors are shown with their message, in the locale
+// of the user if possible. Other errors are shown with the InternalErrorKey
+// message, or DefaultInternalErrorMessage.
+func (m *ErrorMessages) Render(locale string, err error) string {
+	var ufe *UserFacingError
+	if !errors.As(err, &ufe) {
+		if msg, ok := m.render(locale, InternalErrorKey, nil); ok {
+			return msg
+		}
+		return DefaultInternalErrorMessage
+	}
+
+	if msg, ok := m.render(locale, ufe.Key, ufe.Data); ok {
+		return msg
+	}
+	if ufe.Message != "" {
+		return ufe.Message
+	}
+	return DefaultInternalErrorMessage
+}
+
+// render executes the template of the message with the given key, looking for
+// it in the given locale, its language ("pt" for "pt-BR") and the default locale.
+func (m *ErrorMessages) render(locale, key string, data interface{}) (string, bool) {
+	if m == nil || key == "" {
+		return "", false
+	}
+
+	locales := []string{locale}
+	if i := strings.IndexByte(locale, '-'); i > 0 {
+		locales = append(locales, locale[:i])
+	}
+	locales = append(locales, m.defaultLocale)
+
+	for _, l := range locales {
+		tmpl, ok := m.templates[l][key]
+		if !ok {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			continue
+		}
+		return buf.String(), true
+	}
+	return "", false
+}
+
+// RespondError responds to the interaction with an ephemeral message describing
+// err for the user, rendered with the error messages of the client in the locale
+// of the user. See ErrorMessages.Render for how the message is chosen.
+func (r *InteractionResource) RespondError(ctx context.Context, err error) error {
+	return r.RespondEphemeral(ctx, WithContent(r.errorMessage(err)))
+}
+
+// FollowUpError is like RespondError but sends a follow-up message, for
+// interactions that were already responded to or deferred.
+func (r *InteractionResource) FollowUpError(ctx context.Context, err error) (*Message, error) {
+	return r.FollowUpEphemeral(ctx, WithContent(r.errorMessage(err)))
+}
+
+func (r *InteractionResource) errorMessage(err error) string {
+	locale := r.locale
+	if locale == "" {
+		locale = r.guildLocale
+	}
+	return r.client.errorMessages.Render(locale, err)
+}
@@ -0,0 +1,88 @@
+package harmony
+
+import (
+	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/guild"
+)
+
+// DefaultNSFWRequiredMessage is the message shown to users by the middleware
+// returned by RequireNSFW, unless ErrorMessages define an NSFWRequiredKey
+// message for their locale.
+const DefaultNSFWRequiredMessage = "This command can only be used in age-restricted channels."
+
+// NSFWRequiredKey is the key of the message shown to users that use a command
+// restricted to age-restricted channels elsewhere, see ErrorMessages.
+const NSFWRequiredKey = "nsfw_required"
+
+// NSFW reports whether the interaction was triggered in an age-restricted (NSFW)
+// channel, as reported by Discord. Threads do not carry this information, use
+// Client.InteractionNSFW to take their parent channel into account.
+func (i *Interaction) NSFW() bool {
+	return i.Channel != nil && i.Channel.NSFW
+}
+
+// InteractionNSFW reports whether the given interaction was triggered in an
+// age-restricted context: an NSFW channel, a thread of an NSFW channel or an
+// age-restricted guild. Parent channels and guilds are looked up in the State,
+// so only the channel itself is checked if state tracking is disabled.
+func (c *Client) InteractionNSFW(i *Interaction) bool {
+	if i.NSFW() {
+		return true
+	}
+	if c.State == nil {
+		return false
+	}
+
+	if i.Channel != nil && channel.IsThread(i.Channel.Type) && i.Channel.ParentID != "" {
+		if parent := c.State.Channel(i.Channel.ParentID); parent != nil && parent.NSFW {
+			return true
+		}
+	}
+	if i.GuildID != "" {
+		if g := c.State.Guild(i.GuildID); g != nil && g.NSFWLevel == guild.NSFWLevelAgeRestricted {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireNSFW returns a middleware restricting the application commands with the
+// given names to age-restricted contexts, see InteractionNSFW. When one of them
+// is used elsewhere, its handler is not called and the interaction is responded
+// to with an ephemeral message, rendered with the NSFWRequiredKey error message
+// in the locale of the user or DefaultNSFWRequiredMessage. Autocomplete requests
+// of those commands get no choices.
+//
+// Commands created with the NSFW field set are age-gated by Discord itself. This
+// middleware is useful to customize the message shown to users or to restrict
+// commands that are also used in safe channels through their options.
+func (c *Client) RequireNSFW(commands ...string) Middleware {
+	restricted := make(map[string]bool, len(commands))
+	for _, name := range commands {
+		restricted[name] = true
+	}
+
+	return func(next EventHandler) EventHandler {
+		return func(e *Event) {
+			i, ok := e.Data.(*Interaction)
+			if !ok || i.Data == nil || !restricted[i.Data.Name] || c.InteractionNSFW(i) {
+				next(e)
+				return
+			}
+
+			var err error
+			switch i.Type {
+			case InteractionTypeApplicationCommand:
+				err = c.Interaction(i).RespondError(e.Context(), NewUserFacingError(NSFWRequiredKey, DefaultNSFWRequiredMessage, nil))
+			case InteractionTypeApplicationCommandAutocomplete:
+				err = c.Interaction(i).Autocomplete(e.Context(), nil)
+			default:
+				next(e)
+				return
+			}
+			if err != nil {
+				c.logger.Errorf("could not respond to age-restricted command %q: %v", i.Data.Name, err)
+			}
+		}
+	}
+}
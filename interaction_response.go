@@ -0,0 +1,381 @@
+package harmony
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/skwair/harmony/command"
+	"github.com/skwair/harmony/component"
+	"github.com/skwair/harmony/embed"
+	"github.com/skwair/harmony/internal/endpoint"
+	"github.com/skwair/harmony/message"
+)
+
+// InteractionResponseType is the type of a response to an interaction.
+type InteractionResponseType int
+
+// List of interaction response types.
+const (
+	InteractionResponseTypePong                             InteractionResponseType = 1
+	InteractionResponseTypeChannelMessageWithSource         InteractionResponseType = 4
+	InteractionResponseTypeDeferredChannelMessageWithSource InteractionResponseType = 5
+	InteractionResponseTypeDeferredUpdateMessage            InteractionResponseType = 6
+	InteractionResponseTypeUpdateMessage                    InteractionResponseType = 7
+	InteractionResponseTypeAutocompleteResult               InteractionResponseType = 8
+	InteractionResponseTypeModal                            InteractionResponseType = 9
+)
+
+// InteractionResource is a resource that allows to respond to an interaction
+// and to manage the messages sent in response to it.
+type InteractionResource struct {
+	interactionID string
+	applicationID string
+	token         string
+	locale        string
+	guildLocale   string
+	client        *Client
+}
+
+// Interaction returns a new interaction resource to respond to the given interaction.
+// An interaction must be responded to, or deferred, within 3 seconds. Its token then
+// remains valid for 15 minutes to edit the response or send follow-up messages.
+func (c *Client) Interaction(i *Interaction) *InteractionResource {
+	return &InteractionResource{
+		interactionID: i.ID,
+		applicationID: i.ApplicationID,
+		token:         i.Token,
+		locale:        i.Locale,
+		guildLocale:   i.GuildLocale,
+		client:        c,
+	}
+}
+
+// Respond responds to the interaction with a message.
+func (r *InteractionResource) Respond(ctx context.Context, opts ...MessageOption) error {
+	return r.RespondWith(ctx, NewMessageResponse(opts...))
+}
+
+// RespondEphemeral is like Respond but the message is only visible to the user
+// that triggered the interaction.
+func (r *InteractionResource) RespondEphemeral(ctx context.Context, opts ...MessageOption) error {
+	return r.RespondWith(ctx, NewEphemeralResponse(opts...))
+}
+
+// Defer acknowledges the interaction and shows a loading state to the user, leaving
+// up to 15 minutes to send the actual response with EditResponse. If ephemeral is
+// true, the response will only be visible to the user that triggered the interaction.
+func (r *InteractionResource) Defer(ctx context.Context, ephemeral bool) error {
+	return r.RespondWith(ctx, NewDeferredResponse(ephemeral))
+}
+
+// DeferUpdate acknowledges a message component interaction without showing a loading
+// state, leaving up to 15 minutes to edit the message with EditResponse.
+func (r *InteractionResource) DeferUpdate(ctx context.Context) error {
+	return r.RespondWith(ctx, NewDeferredUpdateResponse())
+}
+
+// Update responds to a message component interaction by editing
+// the message the component was attached to.
+func (r *InteractionResource) Update(ctx context.Context, opts ...MessageOption) error {
+	return r.RespondWith(ctx, NewUpdateResponse(opts...))
+}
+
+// Autocomplete responds to an autocomplete interaction with
+// the given choices, up to 25.
+func (r *InteractionResource) Autocomplete(ctx context.Context, choices []command.Choice) error {
+	return r.RespondWith(ctx, NewAutocompleteResponse(choices))
+}
+
+// RespondModal responds to an application command or message component interaction
+// by showing a modal with the given custom ID and title to the user. The rows
+// hold the text inputs of the modal, one per row and up to 5. Once the user submits
+// the modal, a modal submit interaction is sent with the same custom ID and the
+// values typed by the user, see InteractionData.TextInputValue.
+func (r *InteractionResource) RespondModal(ctx context.Context, customID, title string, rows ...component.ActionRow) error {
+	return r.RespondWith(ctx, NewModalResponse(customID, title, rows...))
+}
+
+// RespondWith responds to the interaction with the given response.
+func (r *InteractionResource) RespondWith(ctx context.Context, res *InteractionResponse) error {
+	payload, err := res.payload()
+	if err != nil {
+		return err
+	}
+
+	e := endpoint.CreateInteractionResponse(r.interactionID, r.token)
+	resp, err := r.client.doReq(ctx, e, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return apiError(resp)
+	}
+	return nil
+}
+
+// Response returns the message sent in response to the interaction.
+func (r *InteractionResource) Response(ctx context.Context) (*Message, error) {
+	e := endpoint.GetInteractionMessage(r.applicationID, r.token, "@original")
+	return r.message(ctx, e, nil)
+}
+
+// EditResponse edits the message sent in response to the interaction, or sends
+// the actual response of a deferred interaction.
+func (r *InteractionResource) EditResponse(ctx context.Context, opts ...MessageOption) (*Message, error) {
+	return r.EditFollowUp(ctx, "@original", opts...)
+}
+
+// DeleteResponse deletes the message sent in response to the interaction.
+func (r *InteractionResource) DeleteResponse(ctx context.Context) error {
+	return r.DeleteFollowUp(ctx, "@original")
+}
+
+// FollowUp sends a follow-up message for the interaction.
+func (r *InteractionResource) FollowUp(ctx context.Context, opts ...MessageOption) (*Message, error) {
+	e := endpoint.CreateFollowupMessage(r.applicationID, r.token)
+	return r.message(ctx, e, newInteractionMessage(opts, 0))
+}
+
+// FollowUpEphemeral is like FollowUp but the message is only visible to the
+// user that triggered the interaction.
+func (r *InteractionResource) FollowUpEphemeral(ctx context.Context, opts ...MessageOption) (*Message, error) {
+	e := endpoint.CreateFollowupMessage(r.applicationID, r.token)
+	return r.message(ctx, e, newInteractionMessage(opts, message.FlagEphemeral))
+}
+
+// EditFollowUp edits a follow-up message of the interaction.
+func (r *InteractionResource) EditFollowUp(ctx context.Context, messageID string, opts ...MessageOption) (*Message, error) {
+	e := endpoint.EditInteractionMessage(r.applicationID, r.token, messageID)
+	return r.message(ctx, e, newInteractionMessage(opts, 0))
+}
+
+// DeleteFollowUp deletes a follow-up message of the interaction.
+func (r *InteractionResource) DeleteFollowUp(ctx context.Context, messageID string) error {
+	e := endpoint.DeleteInteractionMessage(r.applicationID, r.token, messageID)
+	resp, err := r.client.doReq(ctx, e, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return apiError(resp)
+	}
+	return nil
+}
+
+func (r *InteractionResource) message(ctx context.Context, e *endpoint.Endpoint, msg *interactionMessage) (*Message, error) {
+	var payload *requestPayload
+	if msg != nil {
+		if err := embed.ValidateAll(msg.Embeds); err != nil {
+			return nil, err
+		}
+
+		var err error
+		payload, err = interactionPayload(msg, msg.files)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := r.client.doReq(ctx, e, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var m Message
+	if err = json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// InteractionResponse is a response to an interaction. Send it with
+// InteractionResource.RespondWith, or return it to Discord when receiving
+// interactions over HTTP, see the interactions package.
+type InteractionResponse struct {
+	typ  InteractionResponseType
+	data *interactionMessage
+}
+
+// NewMessageResponse returns a response to an interaction with a message.
+func NewMessageResponse(opts ...MessageOption) *InteractionResponse {
+	return &InteractionResponse{
+		typ:  InteractionResponseTypeChannelMessageWithSource,
+		data: newInteractionMessage(opts, 0),
+	}
+}
+
+// NewEphemeralResponse is like NewMessageResponse but the message is only
+// visible to the user that triggered the interaction.
+func NewEphemeralResponse(opts ...MessageOption) *InteractionResponse {
+	return &InteractionResponse{
+		typ:  InteractionResponseTypeChannelMessageWithSource,
+		data: newInteractionMessage(opts, message.FlagEphemeral),
+	}
+}
+
+// NewDeferredResponse returns a response acknowledging an interaction and showing
+// a loading state to the user, see InteractionResource.Defer.
+func NewDeferredResponse(ephemeral bool) *InteractionResponse {
+	res := &InteractionResponse{typ: InteractionResponseTypeDeferredChannelMessageWithSource}
+	if ephemeral {
+		res.data = &interactionMessage{Flags: message.FlagEphemeral}
+	}
+	return res
+}
+
+// NewDeferredUpdateResponse returns a response acknowledging a message component
+// interaction without showing a loading state, see InteractionResource.DeferUpdate.
+func NewDeferredUpdateResponse() *InteractionResponse {
+	return &InteractionResponse{typ: InteractionResponseTypeDeferredUpdateMessage}
+}
+
+// NewUpdateResponse returns a response to a message component interaction
+// editing the message the component was attached to.
+func NewUpdateResponse(opts ...MessageOption) *InteractionResponse {
+	return &InteractionResponse{
+		typ:  InteractionResponseTypeUpdateMessage,
+		data: newInteractionMessage(opts, 0),
+	}
+}
+
+// NewAutocompleteResponse returns a response to an autocomplete
+// interaction with the given choices, up to 25.
+func NewAutocompleteResponse(choices []command.Choice) *InteractionResponse {
+	if choices == nil {
+		choices = []command.Choice{}
+	}
+	return &InteractionResponse{
+		typ:  InteractionResponseTypeAutocompleteResult,
+		data: &interactionMessage{Choices: &choices},
+	}
+}
+
+// NewModalResponse returns a response to an application command or message
+// component interaction showing a modal, see InteractionResource.RespondModal.
+func NewModalResponse(customID, title string, rows ...component.ActionRow) *InteractionResponse {
+	if rows == nil {
+		rows = []component.ActionRow{}
+	}
+	return &InteractionResponse{
+		typ: InteractionResponseTypeModal,
+		data: &interactionMessage{
+			CustomID:   customID,
+			Title:      title,
+			Components: &rows,
+		},
+	}
+}
+
+// NewPongResponse returns a response to a ping interaction, sent by Discord
+// to check that an interactions endpoint URL is up.
+func NewPongResponse() *InteractionResponse {
+	return &InteractionResponse{typ: InteractionResponseTypePong}
+}
+
+// Body returns the body of the HTTP request or response carrying this response,
+// along with its content type. The body is multipart if files are attached.
+func (r *InteractionResponse) Body() ([]byte, string, error) {
+	p, err := r.payload()
+	if err != nil {
+		return nil, "", err
+	}
+	return p.body, p.contentType, nil
+}
+
+// payload validates the response and returns the request payload carrying it.
+func (r *InteractionResponse) payload() (*requestPayload, error) {
+	var files []File
+	if r.data != nil {
+		if err := embed.ValidateAll(r.data.Embeds); err != nil {
+			return nil, err
+		}
+		files = r.data.files
+	}
+	return interactionPayload(r, files)
+}
+
+// json implements the multipartPayload interface.
+func (r *InteractionResponse) json() ([]byte, error) {
+	return json.Marshal(struct {
+		Type InteractionResponseType `json:"type"`
+		Data *interactionMessage     `json:"data,omitempty"`
+	}{
+		Type: r.typ,
+		Data: r.data,
+	})
+}
+
+// interactionMessage is a message sent in response to an interaction.
+type interactionMessage struct {
+	Content string            `json:"content,omitempty"`
+	TTS     bool              `json:"tts,omitempty"`
+	Embeds  []embed.Embed     `json:"embeds,omitempty"`
+	Flags   message.Flag      `json:"flags,omitempty"`
+	Choices *[]command.Choice `json:"choices,omitempty"`
+
+	AllowedMentions *message.AllowedMentions `json:"allowed_mentions,omitempty"`
+
+	Components *[]component.ActionRow `json:"components,omitempty"`
+
+	// Custom ID and title of modals.
+	CustomID string `json:"custom_id,omitempty"`
+	Title    string `json:"title,omitempty"`
+
+	files []File
+}
+
+// json implements the multipartPayload interface.
+func (m *interactionMessage) json() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// newInteractionMessage returns an interaction message built from the given message options.
+func newInteractionMessage(opts []MessageOption, flags message.Flag) *interactionMessage {
+	var msg createMessage
+	for _, opt := range opts {
+		opt(&msg)
+	}
+
+	m := &interactionMessage{
+		Content:         msg.Content,
+		TTS:             msg.TTS,
+		Flags:           flags,
+		Components:      msg.Components,
+		AllowedMentions: msg.AllowedMentions,
+		files:           msg.files,
+	}
+	if msg.Embed != nil {
+		if msg.Embed.Type == "" {
+			msg.Embed.Type = "rich"
+		}
+		m.Embeds = []embed.Embed{*msg.Embed}
+	}
+	return m
+}
+
+// interactionPayload returns the request payload for p, as multipart if there are files.
+func interactionPayload(p multipartPayload, files []File) (*requestPayload, error) {
+	if len(files) > 0 {
+		b, contentType, err := multipartFromFiles(p, files...)
+		if err != nil {
+			return nil, err
+		}
+		return customPayload(b, contentType), nil
+	}
+
+	b, err := p.json()
+	if err != nil {
+		return nil, err
+	}
+	return jsonPayload(b), nil
+}
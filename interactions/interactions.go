@@ -0,0 +1,170 @@
+/*
+Package interactions allows to receive interactions over HTTP, at the interactions
+endpoint URL of an application, instead of through a Gateway connection:
+
+	h := interactions.NewHTTPHandler(publicKey, func(ctx context.Context, i *harmony.Interaction) *harmony.InteractionResponse {
+		return harmony.NewMessageResponse(harmony.WithContent("pong"))
+	})
+	http.Handle("/interactions", h)
+
+The public key is shown in the developer portal, next to the interactions endpoint
+URL. Once this URL is set, Discord stops sending interactions through the Gateway.
+Follow-up messages can still be sent with a harmony.Client that is not connected,
+see harmony.Client.Interaction.
+
+Applications using their own router can verify the signature of requests with
+the Verify middleware and decode interactions themselves:
+
+	r.With(interactions.Verify(publicKey)).Post("/interactions", handleInteraction)
+*/
+package interactions
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/skwair/harmony"
+)
+
+// maxBodySize is the maximum size of the body of requests sent by Discord.
+const maxBodySize = 4 << 20
+
+// HandlerFunc handles an interaction received over HTTP and returns the response
+// sent back to Discord. It must return within 3 seconds, defer the response with
+// harmony.NewDeferredResponse for longer work. The context is canceled if Discord
+// stops waiting for the response.
+type HandlerFunc func(ctx context.Context, i *harmony.Interaction) *harmony.InteractionResponse
+
+// Option is a function that configures an HTTP handler.
+type Option func(*handler)
+
+// WithErrorHandler sets the function called when an interaction
+// can not be responded to, for instance because the handler function
+// returned an invalid response. Errors are ignored by default.
+func WithErrorHandler(f func(err error)) Option {
+	return func(h *handler) {
+		h.onError = f
+	}
+}
+
+type handler struct {
+	handle  HandlerFunc
+	onError func(error)
+}
+
+// NewHTTPHandler returns an http.Handler receiving interactions sent by Discord. It
+// verifies the signature of requests with the given hex encoded public key of the
+// application, responds to pings and passes other interactions to f. It panics if
+// the public key is invalid.
+func NewHTTPHandler(publicKey string, f HandlerFunc, opts ...Option) http.Handler {
+	verify := Verify(publicKey)
+	if f == nil {
+		panic("interactions: nil handler function")
+	}
+
+	h := &handler{
+		handle:  f,
+		onError: func(error) {},
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return verify(h)
+}
+
+// Verify returns a middleware rejecting requests that are not signed by Discord
+// with the private key matching the given hex encoded public key, so interactions
+// can be received with any router. The body of verified requests can be read
+// again by the next handler. It panics if the public key is invalid.
+func Verify(publicKey string) func(http.Handler) http.Handler {
+	key, err := hex.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		panic("interactions: invalid public key")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize))
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+
+			// Discord checks that requests with an invalid signature are rejected.
+			if !verify(key, r.Header, body) {
+				http.Error(w, "invalid request signature", http.StatusUnauthorized)
+				return
+			}
+
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var i harmony.Interaction
+	if err := json.NewDecoder(r.Body).Decode(&i); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	var res *harmony.InteractionResponse
+	if i.Type == harmony.InteractionTypePing {
+		res = harmony.NewPongResponse()
+	} else {
+		res = h.handle(r.Context(), &i)
+	}
+	if res == nil {
+		h.onError(fmt.Errorf("interactions: no response to interaction %s", i.ID))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	b, contentType, err := res.Body()
+	if err != nil {
+		h.onError(fmt.Errorf("interactions: could not respond to interaction %s: %w", i.ID, err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if _, err = w.Write(b); err != nil {
+		h.onError(fmt.Errorf("interactions: could not respond to interaction %s: %w", i.ID, err))
+	}
+}
+
+// verify reports whether the signature of a request with the given
+// header and body is valid for the given public key.
+func verify(publicKey ed25519.PublicKey, header http.Header, body []byte) bool {
+	sig, err := hex.DecodeString(header.Get("X-Signature-Ed25519"))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+
+	timestamp := header.Get("X-Signature-Timestamp")
+	if timestamp == "" {
+		return false
+	}
+
+	msg := make([]byte, 0, len(timestamp)+len(body))
+	msg = append(msg, timestamp...)
+	msg = append(msg, body...)
+	return ed25519.Verify(publicKey, msg, sig)
+}
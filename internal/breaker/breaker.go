@@ -0,0 +1,132 @@
+// Package breaker implements circuit breakers for families of REST routes,
+// failing requests fast while Discord is having trouble serving them.
+package breaker
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skwair/harmony/clock"
+	"github.com/skwair/harmony/internal/endpoint"
+)
+
+type state int
+
+const (
+	// Requests are allowed.
+	stateClosed state = iota
+	// Requests fail fast until the cooldown expires.
+	stateOpen
+	// The cooldown expired, a single request is allowed to probe recovery.
+	stateHalfOpen
+)
+
+type circuit struct {
+	state     state
+	failures  int // Consecutive failures.
+	openUntil time.Time
+	probing   bool
+}
+
+// Breaker tracks a circuit per family of routes. A circuit opens after a number
+// of consecutive failures, rejecting requests for a cooldown period. A single
+// probe request is then allowed: its success closes the circuit, its failure
+// opens it again. Create one with New.
+type Breaker struct {
+	mu        sync.Mutex
+	clock     clock.Clock
+	threshold int
+	cooldown  time.Duration
+	circuits  map[string]*circuit
+}
+
+// New returns a new Breaker opening circuits after threshold consecutive failures
+// for the given cooldown.
+func New(clk clock.Clock, threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		clock:     clk,
+		threshold: threshold,
+		cooldown:  cooldown,
+		circuits:  make(map[string]*circuit),
+	}
+}
+
+// Allow reports whether a request to the route with the given key can be sent.
+// If it can not, it also returns how long until a request is allowed again. Once
+// an allowed request completes, Record must be called with its result, or Cancel
+// if it was not sent or was interrupted.
+func (b *Breaker) Allow(key string) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[Family(key)]
+	if !ok {
+		return 0, true
+	}
+
+	switch c.state {
+	case stateOpen:
+		if d := c.openUntil.Sub(b.clock.Now()); d > 0 {
+			return d, false
+		}
+		c.state = stateHalfOpen
+		fallthrough
+	case stateHalfOpen:
+		if c.probing {
+			// Retry once the probe is likely to be done.
+			return b.cooldown, false
+		}
+		c.probing = true
+	}
+	return 0, true
+}
+
+// Record records the result of a request to the route with the given key.
+func (b *Breaker) Record(key string, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	family := Family(key)
+	c, ok := b.circuits[family]
+	if !failed {
+		// Forget healthy circuits so the map does not grow indefinitely.
+		if ok {
+			delete(b.circuits, family)
+		}
+		return
+	}
+
+	if !ok {
+		c = &circuit{}
+		b.circuits[family] = c
+	}
+	c.failures++
+	c.probing = false
+	if c.state == stateHalfOpen || c.failures >= b.threshold {
+		c.state = stateOpen
+		c.openUntil = b.clock.Now().Add(b.cooldown)
+	}
+}
+
+// Cancel must be called instead of Record when an allowed request
+// was not sent or did not complete, so another one can probe recovery.
+func (b *Breaker) Cancel(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.circuits[Family(key)]; ok {
+		c.probing = false
+	}
+}
+
+// Family returns the family of the route with the given key: the first three
+// segments of its route, see endpoint.Route. For instance, the family of
+// "/channels/1234/messages/5678" is "/channels/:id/messages".
+func Family(key string) string {
+	parts := strings.SplitN(strings.TrimPrefix(endpoint.Route(key), "/"), "/", 4)
+	if len(parts) > 3 {
+		parts = parts[:3]
+	}
+	return "/" + strings.Join(parts, "/")
+}
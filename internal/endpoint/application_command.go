@@ -0,0 +1,83 @@
+package endpoint
+
+import "net/http"
+
+func GetGlobalApplicationCommands(appID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/applications/" + appID + "/commands",
+		Key:    "/applications/" + appID + "/commands",
+	}
+}
+
+func CreateGlobalApplicationCommand(appID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPost,
+		Path:   "/applications/" + appID + "/commands",
+		Key:    "/applications/" + appID + "/commands",
+	}
+}
+
+func EditGlobalApplicationCommand(appID, cmdID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPatch,
+		Path:   "/applications/" + appID + "/commands/" + cmdID,
+		Key:    "/applications/" + appID + "/commands",
+	}
+}
+
+func DeleteGlobalApplicationCommand(appID, cmdID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodDelete,
+		Path:   "/applications/" + appID + "/commands/" + cmdID,
+		Key:    "/applications/" + appID + "/commands",
+	}
+}
+
+func BulkOverwriteGlobalApplicationCommands(appID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPut,
+		Path:   "/applications/" + appID + "/commands",
+		Key:    "/applications/" + appID + "/commands",
+	}
+}
+
+func GetGuildApplicationCommands(appID, guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/applications/" + appID + "/guilds/" + guildID + "/commands",
+		Key:    "/applications/" + appID + "/guilds/" + guildID + "/commands",
+	}
+}
+
+func CreateGuildApplicationCommand(appID, guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPost,
+		Path:   "/applications/" + appID + "/guilds/" + guildID + "/commands",
+		Key:    "/applications/" + appID + "/guilds/" + guildID + "/commands",
+	}
+}
+
+func EditGuildApplicationCommand(appID, guildID, cmdID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPatch,
+		Path:   "/applications/" + appID + "/guilds/" + guildID + "/commands/" + cmdID,
+		Key:    "/applications/" + appID + "/guilds/" + guildID + "/commands",
+	}
+}
+
+func DeleteGuildApplicationCommand(appID, guildID, cmdID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodDelete,
+		Path:   "/applications/" + appID + "/guilds/" + guildID + "/commands/" + cmdID,
+		Key:    "/applications/" + appID + "/guilds/" + guildID + "/commands",
+	}
+}
+
+func BulkOverwriteGuildApplicationCommands(appID, guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPut,
+		Path:   "/applications/" + appID + "/guilds/" + guildID + "/commands",
+		Key:    "/applications/" + appID + "/guilds/" + guildID + "/commands",
+	}
+}
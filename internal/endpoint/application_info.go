@@ -9,3 +9,11 @@ func GetApplicationInfo() *Endpoint {
 		Key:    "/oauth2/applications/@me",
 	}
 }
+
+func EditCurrentApplication() *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPatch,
+		Path:   "/applications/@me",
+		Key:    "/applications/@me",
+	}
+}
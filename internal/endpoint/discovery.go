@@ -0,0 +1,23 @@
+package endpoint
+
+import "net/http"
+
+func ListDiscoveryCategories(query string) *Endpoint {
+	if query != "" {
+		query = "?" + query
+	}
+
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/discovery/categories" + query,
+		Key:    "/discovery/categories",
+	}
+}
+
+func ValidateDiscoverySearchTerm(query string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/discovery/valid-term?" + query,
+		Key:    "/discovery/valid-term",
+	}
+}
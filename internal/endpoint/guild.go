@@ -10,10 +10,14 @@ func CreateGuild() *Endpoint {
 	}
 }
 
-func GetGuild(guildID string) *Endpoint {
+func GetGuild(guildID, query string) *Endpoint {
+	if query != "" {
+		query = "?" + query
+	}
+
 	return &Endpoint{
 		Method: http.MethodGet,
-		Path:   "/guilds/" + guildID,
+		Path:   "/guilds/" + guildID + query,
 		Key:    "/guilds/" + guildID,
 	}
 }
@@ -106,22 +110,22 @@ func ModifyCurrentUserNick(guildID string) *Endpoint {
 	}
 }
 
-func GetGuildBans(guildID string) *Endpoint {
+func GetGuildBans(guildID, query string) *Endpoint {
+	if query != "" {
+		query = "?" + query
+	}
+
 	return &Endpoint{
 		Method: http.MethodGet,
-		Path:   "/guilds/" + guildID + "/bans",
+		Path:   "/guilds/" + guildID + "/bans" + query,
 		Key:    "/guilds/" + guildID + "/bans",
 	}
 }
 
-func CreateGuildBan(guildID, userID, query string) *Endpoint {
-	if query != "" {
-		query = "?" + query
-	}
-
+func CreateGuildBan(guildID, userID string) *Endpoint {
 	return &Endpoint{
 		Method: http.MethodPut,
-		Path:   "/guilds/" + guildID + "/bans/" + userID + query,
+		Path:   "/guilds/" + guildID + "/bans/" + userID,
 		Key:    "/guilds/" + guildID + "/bans",
 	}
 }
@@ -229,3 +233,59 @@ func GetGuildVanityURL(guildID string) *Endpoint {
 		Key:    "/guilds/" + guildID + "/vanity-url",
 	}
 }
+
+func GetGuildOnboarding(guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/guilds/" + guildID + "/onboarding",
+		Key:    "/guilds/" + guildID + "/onboarding",
+	}
+}
+
+func ModifyGuildOnboarding(guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPut,
+		Path:   "/guilds/" + guildID + "/onboarding",
+		Key:    "/guilds/" + guildID + "/onboarding",
+	}
+}
+
+func GetGuildDiscoveryMetadata(guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/guilds/" + guildID + "/discovery-metadata",
+		Key:    "/guilds/" + guildID + "/discovery-metadata",
+	}
+}
+
+func ModifyGuildDiscoveryMetadata(guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPatch,
+		Path:   "/guilds/" + guildID + "/discovery-metadata",
+		Key:    "/guilds/" + guildID + "/discovery-metadata",
+	}
+}
+
+func AddGuildDiscoverySubcategory(guildID, categoryID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPost,
+		Path:   "/guilds/" + guildID + "/discovery-categories/" + categoryID,
+		Key:    "/guilds/" + guildID + "/discovery-categories",
+	}
+}
+
+func RemoveGuildDiscoverySubcategory(guildID, categoryID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodDelete,
+		Path:   "/guilds/" + guildID + "/discovery-categories/" + categoryID,
+		Key:    "/guilds/" + guildID + "/discovery-categories",
+	}
+}
+
+func ModifyGuildIncidentActions(guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPut,
+		Path:   "/guilds/" + guildID + "/incident-actions",
+		Key:    "/guilds/" + guildID + "/incident-actions",
+	}
+}
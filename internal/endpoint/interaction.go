@@ -0,0 +1,43 @@
+package endpoint
+
+import "net/http"
+
+func CreateInteractionResponse(interactionID, token string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPost,
+		Path:   "/interactions/" + interactionID + "/" + token + "/callback",
+		Key:    "/interactions/" + interactionID + "/" + token + "/callback",
+	}
+}
+
+func GetInteractionMessage(appID, token, messageID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/webhooks/" + appID + "/" + token + "/messages/" + messageID,
+		Key:    "/webhooks/" + appID + "/" + token + "/messages",
+	}
+}
+
+func EditInteractionMessage(appID, token, messageID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPatch,
+		Path:   "/webhooks/" + appID + "/" + token + "/messages/" + messageID,
+		Key:    "/webhooks/" + appID + "/" + token + "/messages",
+	}
+}
+
+func DeleteInteractionMessage(appID, token, messageID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodDelete,
+		Path:   "/webhooks/" + appID + "/" + token + "/messages/" + messageID,
+		Key:    "/webhooks/" + appID + "/" + token + "/messages",
+	}
+}
+
+func CreateFollowupMessage(appID, token string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPost,
+		Path:   "/webhooks/" + appID + "/" + token,
+		Key:    "/webhooks/" + appID + "/" + token,
+	}
+}
@@ -0,0 +1,99 @@
+package endpoint
+
+import "strings"
+
+// majorParameters are the path segments whose following ID is a major
+// parameter, meaning routes with different values do not share rate limits.
+var majorParameters = map[string]bool{
+	"channels": true,
+	"guilds":   true,
+	"webhooks": true,
+}
+
+// Route returns the route of the given path: the path without its query string
+// and with its IDs, tokens, emojis and codes replaced by placeholders. For
+// instance, the route of "/channels/1234/messages/5678" is
+// "/channels/:id/messages/:id". Routes are bounded in number and never hold
+// tokens.
+func Route(path string) string {
+	path, _ = splitQuery(path)
+
+	segments := strings.Split(path, "/")
+	route := make([]string, len(segments))
+	for i, seg := range segments {
+		route[i] = seg
+		if i == 0 {
+			continue
+		}
+
+		switch prev := segments[i-1]; {
+		case isToken(segments, i):
+			route[i] = ":token"
+		case prev == "reactions":
+			route[i] = ":emoji"
+		case prev == "invites" || prev == "templates":
+			route[i] = ":code"
+		case IsID(seg):
+			route[i] = ":id"
+		}
+	}
+	return strings.Join(route, "/")
+}
+
+// MajorParameter returns the major parameter of the given path, such as
+// "channels/1234" for "/channels/1234/messages", or an empty string if
+// it has none. Routes with different major parameters do not share
+// rate limits, even though they are in the same bucket.
+func MajorParameter(path string) string {
+	path, _ = splitQuery(path)
+
+	segments := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+	if len(segments) < 2 || !majorParameters[segments[0]] {
+		return ""
+	}
+	return segments[0] + "/" + segments[1]
+}
+
+// RedactTokens returns the given path with its webhook
+// and interaction tokens replaced by replacement.
+func RedactTokens(path, replacement string) string {
+	path, query := splitQuery(path)
+
+	segments := strings.Split(path, "/")
+	for i := range segments {
+		if isToken(segments, i) {
+			segments[i] = replacement
+		}
+	}
+	return strings.Join(segments, "/") + query
+}
+
+// IsID reports whether the given path segment is a snowflake ID.
+func IsID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isToken reports whether the i-th segment of a path is a
+// token, which follows the ID of a webhook or an interaction.
+func isToken(segments []string, i int) bool {
+	return i >= 2 &&
+		(segments[i-2] == "webhooks" || segments[i-2] == "interactions") &&
+		IsID(segments[i-1])
+}
+
+// splitQuery splits the given path into the path
+// itself and its query string, including the "?".
+func splitQuery(path string) (string, string) {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		return path[:i], path[i:]
+	}
+	return path, ""
+}
@@ -0,0 +1,75 @@
+package endpoint
+
+import "testing"
+
+func TestRoute(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "IDs", path: "/channels/1/messages/2", expected: "/channels/:id/messages/:id"},
+		{name: "query", path: "/guilds/1/members?limit=1000", expected: "/guilds/:id/members"},
+		{name: "webhook", path: "/webhooks/1/token?wait=true", expected: "/webhooks/:id/:token"},
+		{name: "interaction", path: "/interactions/1/token/callback", expected: "/interactions/:id/:token/callback"},
+		{name: "webhook without ID", path: "/webhooks/abc/token", expected: "/webhooks/abc/token"},
+		{name: "emoji", path: "/channels/1/messages/2/reactions/%F0%9F%91%8D/@me", expected: "/channels/:id/messages/:id/reactions/:emoji/@me"},
+		{name: "invite", path: "/invites/abc", expected: "/invites/:code"},
+		{name: "template", path: "/guilds/templates/abc", expected: "/guilds/templates/:code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if route := Route(tt.path); route != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, route)
+			}
+		})
+	}
+}
+
+func TestMajorParameter(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "channel", path: "/channels/1/messages/2", expected: "channels/1"},
+		{name: "guild", path: "/guilds/1", expected: "guilds/1"},
+		{name: "webhook", path: "/webhooks/1/token?wait=true", expected: "webhooks/1"},
+		{name: "query", path: "/guilds/1?with_counts=true", expected: "guilds/1"},
+		{name: "not major", path: "/users/1", expected: ""},
+		{name: "no parameter", path: "/channels", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if param := MajorParameter(tt.path); param != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, param)
+			}
+		})
+	}
+}
+
+func TestRedactTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "no token", path: "/channels/1/messages", expected: "/channels/1/messages"},
+		{name: "webhook", path: "/webhooks/1/token", expected: "/webhooks/1/x"},
+		{name: "webhook with query", path: "/webhooks/1/token?wait=true", expected: "/webhooks/1/x?wait=true"},
+		{name: "webhook message", path: "/webhooks/1/token/messages/2", expected: "/webhooks/1/x/messages/2"},
+		{name: "webhook without token", path: "/webhooks/1", expected: "/webhooks/1"},
+		{name: "interaction", path: "/interactions/1/token/callback", expected: "/interactions/1/x/callback"},
+		{name: "not an ID", path: "/webhooks/abc/token", expected: "/webhooks/abc/token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if path := RedactTokens(tt.path, "x"); path != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, path)
+			}
+		})
+	}
+}
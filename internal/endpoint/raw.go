@@ -0,0 +1,23 @@
+package endpoint
+
+import "strings"
+
+// Raw returns an endpoint for the given method and path, which may contain
+// a query string. Its rate limit key is derived from the path: it is kept
+// up to the first ID that is not a major parameter.
+func Raw(method, path string) *Endpoint {
+	key, _ := splitQuery(path)
+	segments := strings.Split(strings.Trim(key, "/"), "/")
+	for i := 1; i < len(segments); i++ {
+		if IsID(segments[i]) && !majorParameters[segments[i-1]] {
+			segments = segments[:i]
+			break
+		}
+	}
+
+	return &Endpoint{
+		Method: method,
+		Path:   path,
+		Key:    "/" + strings.Join(segments, "/"),
+	}
+}
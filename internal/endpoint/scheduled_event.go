@@ -0,0 +1,15 @@
+package endpoint
+
+import "net/http"
+
+func GetGuildScheduledEventUsers(guildID, eventID, query string) *Endpoint {
+	if query != "" {
+		query = "?" + query
+	}
+
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/guilds/" + guildID + "/scheduled-events/" + eventID + "/users" + query,
+		Key:    "/guilds/" + guildID + "/scheduled-events",
+	}
+}
@@ -0,0 +1,51 @@
+package endpoint
+
+import "net/http"
+
+func CreateStageInstance() *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPost,
+		Path:   "/stage-instances",
+		Key:    "/stage-instances",
+	}
+}
+
+func GetStageInstance(chID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/stage-instances/" + chID,
+		Key:    "/stage-instances/" + chID,
+	}
+}
+
+func ModifyStageInstance(chID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPatch,
+		Path:   "/stage-instances/" + chID,
+		Key:    "/stage-instances/" + chID,
+	}
+}
+
+func DeleteStageInstance(chID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodDelete,
+		Path:   "/stage-instances/" + chID,
+		Key:    "/stage-instances/" + chID,
+	}
+}
+
+func ModifyCurrentUserVoiceState(guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPatch,
+		Path:   "/guilds/" + guildID + "/voice-states/@me",
+		Key:    "/guilds/" + guildID + "/voice-states",
+	}
+}
+
+func ModifyUserVoiceState(guildID, userID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPatch,
+		Path:   "/guilds/" + guildID + "/voice-states/" + userID,
+		Key:    "/guilds/" + guildID + "/voice-states",
+	}
+}
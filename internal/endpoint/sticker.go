@@ -0,0 +1,59 @@
+package endpoint
+
+import "net/http"
+
+func GetSticker(stickerID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/stickers/" + stickerID,
+		Key:    "/stickers",
+	}
+}
+
+func ListStickerPacks() *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/sticker-packs",
+		Key:    "/sticker-packs",
+	}
+}
+
+func ListGuildStickers(guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/guilds/" + guildID + "/stickers",
+		Key:    "/guilds/" + guildID + "/stickers",
+	}
+}
+
+func GetGuildSticker(guildID, stickerID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/guilds/" + guildID + "/stickers/" + stickerID,
+		Key:    "/guilds/" + guildID + "/stickers",
+	}
+}
+
+func CreateGuildSticker(guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPost,
+		Path:   "/guilds/" + guildID + "/stickers",
+		Key:    "/guilds/" + guildID + "/stickers",
+	}
+}
+
+func ModifyGuildSticker(guildID, stickerID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPatch,
+		Path:   "/guilds/" + guildID + "/stickers/" + stickerID,
+		Key:    "/guilds/" + guildID + "/stickers",
+	}
+}
+
+func DeleteGuildSticker(guildID, stickerID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodDelete,
+		Path:   "/guilds/" + guildID + "/stickers/" + stickerID,
+		Key:    "/guilds/" + guildID + "/stickers",
+	}
+}
@@ -0,0 +1,51 @@
+package endpoint
+
+import "net/http"
+
+func GetGuildTemplate(code string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/guilds/templates/" + code,
+		Key:    "/guilds/templates",
+	}
+}
+
+func CreateGuildFromTemplate(code string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPost,
+		Path:   "/guilds/templates/" + code,
+		Key:    "/guilds/templates",
+	}
+}
+
+func GetGuildTemplates(guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/guilds/" + guildID + "/templates",
+		Key:    "/guilds/" + guildID + "/templates",
+	}
+}
+
+func CreateGuildTemplate(guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPost,
+		Path:   "/guilds/" + guildID + "/templates",
+		Key:    "/guilds/" + guildID + "/templates",
+	}
+}
+
+func SyncGuildTemplate(guildID, code string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPut,
+		Path:   "/guilds/" + guildID + "/templates/" + code,
+		Key:    "/guilds/" + guildID + "/templates",
+	}
+}
+
+func DeleteGuildTemplate(guildID, code string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodDelete,
+		Path:   "/guilds/" + guildID + "/templates/" + code,
+		Key:    "/guilds/" + guildID + "/templates",
+	}
+}
@@ -0,0 +1,103 @@
+package endpoint
+
+import "net/http"
+
+func StartThreadFromMessage(chID, msgID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPost,
+		Path:   "/channels/" + chID + "/messages/" + msgID + "/threads",
+		Key:    "/channels/" + chID + "/threads",
+	}
+}
+
+func StartThreadWithoutMessage(chID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPost,
+		Path:   "/channels/" + chID + "/threads",
+		Key:    "/channels/" + chID + "/threads",
+	}
+}
+
+func JoinThread(chID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPut,
+		Path:   "/channels/" + chID + "/thread-members/@me",
+		Key:    "/channels/" + chID + "/thread-members",
+	}
+}
+
+func AddThreadMember(chID, userID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPut,
+		Path:   "/channels/" + chID + "/thread-members/" + userID,
+		Key:    "/channels/" + chID + "/thread-members",
+	}
+}
+
+func LeaveThread(chID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodDelete,
+		Path:   "/channels/" + chID + "/thread-members/@me",
+		Key:    "/channels/" + chID + "/thread-members",
+	}
+}
+
+func RemoveThreadMember(chID, userID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodDelete,
+		Path:   "/channels/" + chID + "/thread-members/" + userID,
+		Key:    "/channels/" + chID + "/thread-members",
+	}
+}
+
+func ListThreadMembers(chID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/channels/" + chID + "/thread-members",
+		Key:    "/channels/" + chID + "/thread-members",
+	}
+}
+
+func ListActiveGuildThreads(guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/guilds/" + guildID + "/threads/active",
+		Key:    "/guilds/" + guildID + "/threads/active",
+	}
+}
+
+func ListPublicArchivedThreads(chID, query string) *Endpoint {
+	if query != "" {
+		query = "?" + query
+	}
+
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/channels/" + chID + "/threads/archived/public" + query,
+		Key:    "/channels/" + chID + "/threads/archived/public",
+	}
+}
+
+func ListPrivateArchivedThreads(chID, query string) *Endpoint {
+	if query != "" {
+		query = "?" + query
+	}
+
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/channels/" + chID + "/threads/archived/private" + query,
+		Key:    "/channels/" + chID + "/threads/archived/private",
+	}
+}
+
+func ListJoinedPrivateArchivedThreads(chID, query string) *Endpoint {
+	if query != "" {
+		query = "?" + query
+	}
+
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/channels/" + chID + "/users/@me/threads/archived/private" + query,
+		Key:    "/channels/" + chID + "/users/@me/threads/archived/private",
+	}
+}
@@ -57,3 +57,11 @@ func GetUserConnections() *Endpoint {
 		Key:    "/users/@me/connections",
 	}
 }
+
+func GetCurrentUserGuildMember(guildID string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodGet,
+		Path:   "/users/@me/guilds/" + guildID + "/member",
+		Key:    "/users/@me/guilds/" + guildID + "/member",
+	}
+}
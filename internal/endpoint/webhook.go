@@ -81,3 +81,43 @@ func ExecuteWebhook(whID, token, query string) *Endpoint {
 		Key:    "/webhooks/" + whID,
 	}
 }
+
+func ExecuteSlackWebhook(whID, token, query string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPost,
+		Path:   "/webhooks/" + whID + "/" + token + "/slack?" + query,
+		Key:    "/webhooks/" + whID,
+	}
+}
+
+func ExecuteGitHubWebhook(whID, token, query string) *Endpoint {
+	return &Endpoint{
+		Method: http.MethodPost,
+		Path:   "/webhooks/" + whID + "/" + token + "/github?" + query,
+		Key:    "/webhooks/" + whID,
+	}
+}
+
+func EditWebhookMessage(whID, token, messageID, query string) *Endpoint {
+	if query != "" {
+		query = "?" + query
+	}
+
+	return &Endpoint{
+		Method: http.MethodPatch,
+		Path:   "/webhooks/" + whID + "/" + token + "/messages/" + messageID + query,
+		Key:    "/webhooks/" + whID + "/messages",
+	}
+}
+
+func DeleteWebhookMessage(whID, token, messageID, query string) *Endpoint {
+	if query != "" {
+		query = "?" + query
+	}
+
+	return &Endpoint{
+		Method: http.MethodDelete,
+		Path:   "/webhooks/" + whID + "/" + token + "/messages/" + messageID + query,
+		Key:    "/webhooks/" + whID + "/messages",
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"go.uber.org/atomic"
+
+	"github.com/skwair/harmony/clock"
 )
 
 // Hearbeater is a function that sends a heartbeat.
@@ -15,13 +17,14 @@ type Hearbeater func() error
 // It can be stopped by closing the stop channel and will report any error
 // that occurs using the given errReporter.
 func Run(
+	clk clock.Clock,
 	every time.Duration,
 	h Hearbeater,
 	lastHeartbeatACK *atomic.Int64,
 	stop chan struct{},
 	errReporter func(err error),
 ) {
-	ticker := time.NewTicker(every)
+	ticker := clk.NewTicker(every)
 	defer ticker.Stop()
 
 	first := true
@@ -30,8 +33,8 @@ func Run(
 		// last heartbeat we sent, we should consider the
 		// connection as stale and return an error.
 		lastACK := time.Unix(0, lastHeartbeatACK.Load())
-		if !first && time.Since(lastACK) > every {
-			errReporter(fmt.Errorf("no heartbeat received since %v (%v ago)", lastACK, time.Since(lastACK)))
+		if !first && clk.Since(lastACK) > every {
+			errReporter(fmt.Errorf("no heartbeat received since %v (%v ago)", lastACK, clk.Since(lastACK)))
 			return
 		}
 
@@ -48,7 +51,7 @@ func Run(
 		select {
 		case <-stop:
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 		}
 	}
 }
@@ -57,13 +60,14 @@ func Run(
 // It can be stopped by closing the stop channel and will report any error that
 // occurs using the given errReporter.
 func RunUDP(
+	clk clock.Clock,
 	every time.Duration,
 	h Hearbeater,
 	lastUDPHeartbeatACK *atomic.Int64,
 	stop chan struct{},
 	errReporter func(err error),
 ) {
-	ticker := time.NewTicker(every)
+	ticker := clk.NewTicker(every)
 	defer ticker.Stop()
 
 	first := true
@@ -75,8 +79,8 @@ func RunUDP(
 		// not be the best idea. Maybe consider adding a threshold
 		// before assuming the connection is down?
 		lastACK := time.Unix(0, lastUDPHeartbeatACK.Load())
-		if !first && time.Since(lastACK) > every {
-			errReporter(fmt.Errorf("no UDP heartbeat received since %v (%v ago)", lastACK, time.Since(lastACK)))
+		if !first && clk.Since(lastACK) > every {
+			errReporter(fmt.Errorf("no UDP heartbeat received since %v (%v ago)", lastACK, clk.Since(lastACK)))
 			return
 		}
 
@@ -99,7 +103,7 @@ func RunUDP(
 		select {
 		case <-stop:
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 		}
 	}
 }
@@ -0,0 +1,51 @@
+// Package intern deduplicates strings, so equal strings decoded from different
+// Gateway events share the same memory.
+package intern
+
+import "sync"
+
+// Pool holds a single copy of each string it is given. Strings are never
+// removed from it, so it should only hold strings with a bounded number of
+// distinct values, such as IDs of cached entities. It is safe for concurrent
+// use.
+type Pool struct {
+	mu      sync.Mutex
+	strings map[string]string
+}
+
+// New returns a new empty pool.
+func New() *Pool {
+	return &Pool{strings: make(map[string]string)}
+}
+
+// String returns the copy of s held by the pool, adding s to the pool if it
+// does not hold it yet.
+func (p *Pool) String(s string) string {
+	if s == "" {
+		return s
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if interned, ok := p.strings[s]; ok {
+		return interned
+	}
+	p.strings[s] = s
+	return s
+}
+
+// Strings replaces the strings of the given slice with their copies held by the pool.
+func (p *Pool) Strings(ss []string) {
+	for i := range ss {
+		ss[i] = p.String(ss[i])
+	}
+}
+
+// Len returns the number of strings held by the pool.
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.strings)
+}
@@ -0,0 +1,113 @@
+// Package pagination provides the primitives used to iterate over the
+// paginated list endpoints of the Discord API.
+package pagination
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// Direction is the direction in which an Iterator walks through a list.
+type Direction int
+
+const (
+	// Forward fetches pages of items that come after the cursor.
+	Forward Direction = iota
+	// Backward fetches pages of items that come before the cursor.
+	Backward
+)
+
+// Cursor is the position of a page in a list.
+type Cursor struct {
+	// Before and After are the IDs of the items to fetch
+	// items before or after. Empty values are omitted.
+	Before string
+	After  string
+	// Limit is the maximum number of items of the page.
+	// Zero leaves it to the default of the endpoint.
+	Limit int
+}
+
+// Values returns the query parameters for this cursor.
+func (c Cursor) Values() url.Values {
+	q := url.Values{}
+	if c.Before != "" {
+		q.Set("before", c.Before)
+	}
+	if c.After != "" {
+		q.Set("after", c.After)
+	}
+	if c.Limit > 0 {
+		q.Set("limit", strconv.Itoa(c.Limit))
+	}
+	return q
+}
+
+// Fetcher fetches the page at the given cursor. It returns the number of
+// items in the page and the ID of the last one, used to move the cursor.
+type Fetcher func(ctx context.Context, c Cursor) (n int, lastID string, err error)
+
+// Iterator walks through a paginated list, one item at a time. It does not
+// hold items itself, callers keep the last page fetched and use Index to
+// get the current item.
+type Iterator struct {
+	dir    Direction
+	cursor Cursor
+	fetch  Fetcher
+
+	n    int // Number of items in the current page.
+	i    int // Index of the current item in the page.
+	done bool
+	err  error
+}
+
+// New returns an iterator that starts at the given cursor and walks in the given direction.
+func New(dir Direction, cursor Cursor, fetch Fetcher) *Iterator {
+	return &Iterator{
+		dir:    dir,
+		cursor: cursor,
+		fetch:  fetch,
+		i:      -1,
+	}
+}
+
+// Next advances the iterator to the next item, fetching the next page if needed.
+// It returns false when there are no more items or an error occurred.
+func (it *Iterator) Next(ctx context.Context) bool {
+	it.i++
+	if it.i < it.n {
+		return true
+	}
+	if it.done || it.err != nil {
+		return false
+	}
+
+	n, lastID, err := it.fetch(ctx, it.cursor)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	// A short page means this was the last one.
+	if n == 0 || lastID == "" || (it.cursor.Limit > 0 && n < it.cursor.Limit) {
+		it.done = true
+	}
+
+	if it.dir == Backward {
+		it.cursor.Before = lastID
+	} else {
+		it.cursor.After = lastID
+	}
+	it.n, it.i = n, 0
+	return n > 0
+}
+
+// Index returns the index of the current item in the last page fetched.
+func (it *Iterator) Index() int {
+	return it.i
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
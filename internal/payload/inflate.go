@@ -0,0 +1,128 @@
+package payload
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"nhooyr.io/websocket"
+)
+
+// zlibSuffix ends each message that completes a payload when
+// receiving payloads with zlib-stream compression.
+var zlibSuffix = []byte{0x00, 0x00, 0xff, 0xff}
+
+// Inflater decompresses payloads received with zlib-stream compression, where
+// all messages of a connection are part of a single zlib stream. A payload
+// can be split across several messages, the last one ending with a zlib
+// sync flush. An Inflater must be used for a single connection and closed
+// with Close once the connection is closed. Create one with NewInflater.
+type Inflater struct {
+	w       *io.PipeWriter
+	buf     bytes.Buffer // Messages of a payload received so far.
+	results chan result
+}
+
+type result struct {
+	p   *Payload
+	err error
+}
+
+// NewInflater returns a new Inflater, ready to decompress
+// the messages of a new connection.
+func NewInflater() *Inflater {
+	r, w := io.Pipe()
+	i := &Inflater{
+		w: w,
+		// Buffered so the decoder can read the end of a message
+		// before its payload is consumed by Inflate.
+		results: make(chan result, 1),
+	}
+	go i.run(r)
+	return i
+}
+
+// run decodes payloads from the decompressed stream until it is closed.
+// The stream can not be decompressed synchronously because the zlib reader
+// fails for good when it runs out of input, which happens after each payload.
+func (i *Inflater) run(r *io.PipeReader) {
+	var err error
+	defer func() {
+		// Make writes fail instead of blocking forever.
+		r.CloseWithError(err)
+		select {
+		case i.results <- result{err: err}:
+		default:
+		}
+	}()
+
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return
+	}
+
+	dec := json.NewDecoder(zr)
+	for {
+		var p Payload
+		if err = dec.Decode(&p); err != nil {
+			return
+		}
+		i.results <- result{p: &p}
+	}
+}
+
+// Inflate decompresses the given message. It returns a nil payload and no
+// error if the message does not complete a payload.
+func (i *Inflater) Inflate(msg []byte) (*Payload, error) {
+	i.buf.Write(msg)
+	if !bytes.HasSuffix(i.buf.Bytes(), zlibSuffix) {
+		return nil, nil
+	}
+	defer i.buf.Reset()
+
+	if _, err := i.w.Write(i.buf.Bytes()); err != nil {
+		return nil, err
+	}
+	res := <-i.results
+	return res.p, res.err
+}
+
+// Close stops the Inflater.
+func (i *Inflater) Close() error {
+	return i.w.Close()
+}
+
+// RecvStream is like Recv but for connections using zlib-stream compression,
+// decompressing messages with the given Inflater.
+func RecvStream(ctx context.Context, connRMu *sync.Mutex, conn *websocket.Conn, i *Inflater) (*Payload, error) {
+	// Hold the lock until a whole payload is received
+	// since it can be split across several messages.
+	connRMu.Lock()
+	defer connRMu.Unlock()
+
+	for {
+		typ, b, err := conn.Read(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if typ != websocket.MessageBinary {
+			var p Payload
+			if err = json.Unmarshal(b, &p); err != nil {
+				return nil, err
+			}
+			return &p, nil
+		}
+
+		p, err := i.Inflate(b)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			return p, nil
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package payload
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// compressStream compresses the given payloads as a single zlib stream, as sent
+// by the Gateway with zlib-stream compression. Each payload is split into the
+// given number of messages, the last one ending with a sync flush.
+func compressStream(t *testing.T, payloads []*Payload, split int) [][]byte {
+	t.Helper()
+
+	var (
+		buf  bytes.Buffer
+		msgs [][]byte
+	)
+	zw := zlib.NewWriter(&buf)
+	for _, p := range payloads {
+		b, err := json.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = zw.Write(b); err != nil {
+			t.Fatal(err)
+		}
+		if err = zw.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		compressed := append([]byte(nil), buf.Bytes()...)
+		buf.Reset()
+
+		size := (len(compressed) + split - 1) / split
+		for len(compressed) > size {
+			msgs = append(msgs, compressed[:size])
+			compressed = compressed[size:]
+		}
+		msgs = append(msgs, compressed)
+	}
+	return msgs
+}
+
+func TestInflater(t *testing.T) {
+	hello := &Payload{Op: 10, D: json.RawMessage(`{"heartbeat_interval":41250}`)}
+	ready := &Payload{Op: 0, D: json.RawMessage(`{"v":9,"session_id":"abc"}`), S: 1, T: "READY"}
+	ack := &Payload{Op: 11, D: json.RawMessage(`null`)}
+
+	tests := []struct {
+		name     string
+		payloads []*Payload
+		split    int
+	}{
+		{name: "single message", payloads: []*Payload{hello}, split: 1},
+		{name: "split message", payloads: []*Payload{ready}, split: 3},
+		{name: "several payloads", payloads: []*Payload{hello, ready, ack}, split: 1},
+		{name: "several split payloads", payloads: []*Payload{hello, ready, ack}, split: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := NewInflater()
+			defer i.Close()
+
+			var got []*Payload
+			for _, msg := range compressStream(t, tt.payloads, tt.split) {
+				p, err := i.Inflate(msg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if p != nil {
+					got = append(got, p)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.payloads) {
+				t.Errorf("expected payloads %v, got %v", tt.payloads, got)
+			}
+		})
+	}
+}
+
+func TestInflaterErrors(t *testing.T) {
+	var invalid bytes.Buffer
+	zw := zlib.NewWriter(&invalid)
+	_, _ = zw.Write([]byte(`{"op":}`))
+	_ = zw.Flush()
+
+	tests := []struct {
+		name string
+		msg  []byte
+	}{
+		{name: "invalid zlib header", msg: append([]byte("not zlib"), zlibSuffix...)},
+		{name: "invalid payload", msg: invalid.Bytes()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := NewInflater()
+			defer i.Close()
+
+			if p, err := i.Inflate(tt.msg); err == nil {
+				t.Errorf("expected an error, got payload %v", p)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+// Package proxy builds HTTP clients used to dial websockets through proxies.
+package proxy
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Client returns an HTTP client sending requests through the proxy with the given
+// URL, or directly if it is nil. Websocket connections to wss:// URLs are tunneled
+// with a CONNECT request through HTTP and HTTPS proxies. SOCKS5 proxies are used
+// for URLs with the socks5 scheme.
+func Client(u *url.URL) *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyURL(u)
+	return &http.Client{Transport: t}
+}
@@ -1,91 +1,97 @@
 package rate
 
 import (
-	"net/http"
-	"strconv"
 	"sync"
 	"time"
 )
 
-// bucket implements a leaky bucket.
+// bucket tracks the rate limit of a bucket of routes, as reported by Discord.
 type bucket struct {
 	mu sync.Mutex
 
-	// Whether this bucket is enabled or not. If disabled (the default),
-	// lockAndWait will always return immediately. This is used for endpoints
-	// that do not have a specific rate limit (besides the global rate limit).
-	enabled bool
-
-	// Maximum number of tokens this bucket can hold.
+	// Whether the limit of this bucket is known, meaning at least one
+	// response was received for it. Until then, requests are sent one
+	// at a time so the limit is learned before sending more.
+	known bool
+	// Whether a request is in flight to learn the limit of this bucket,
+	// and the channel closed when it completes.
+	probing bool
+	learned chan struct{}
+
+	// Maximum number of requests in the bucket, 0 if the
+	// routes of this bucket are not rate limited.
 	limit int
-	// Remaining tokens in the bucket.
+	// Remaining number of requests before reset.
 	remaining int
-	// Unix timestamp for when this bucket
-	// refills to its maximum capacity.
-	reset int64
+	// Time at which the bucket refills to its limit.
+	reset time.Time
 }
 
-// lockAndWait locks the bucket, returning immediately after if the bucket is disabled.
-// If it is enabled, il will decrement the remaining tokens in the bucket by one if there
-// is at least one token remaining, else it will wait for the bucket to refill before
-// doing so.
-func (b *bucket) lockAndWait() {
-	b.mu.Lock()
+func newBucket() *bucket {
+	return &bucket{learned: make(chan struct{})}
+}
 
-	if !b.enabled {
-		return
+// reserve reserves a request in the bucket. If the request can be sent, it returns
+// a zero duration and a nil channel. Otherwise, it returns either the duration to
+// wait for the bucket to refill or a channel closed when the limit of the bucket
+// is learned, after which reserve must be called again.
+func (b *bucket) reserve(now time.Time) (time.Duration, <-chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.known {
+		if b.probing {
+			return 0, b.learned
+		}
+		b.probing = true
+		return 0, nil
 	}
 
-	// Reset time is in the past, refill the bucket to its maximum capacity.
-	if time.Unix(b.reset, 0).Before(time.Now()) {
-		b.remaining = b.limit
+	if b.limit == 0 {
+		return 0, nil
 	}
-
-	if b.remaining == 0 {
-		// We are out of tokens in this bucket, wait until it refills.
-		time.Sleep(time.Until(time.Unix(b.reset, 0)))
+	if !b.reset.After(now) {
 		b.remaining = b.limit
 	}
-
-	b.remaining--
-}
-
-// update updates the bucket by parsing the given HTTP header.
-// If none is present, the bucket is disabled.
-// NOTE: errors are discarded for now, consider returning them.
-func (b *bucket) update(header http.Header) {
-	var set bool
-
-	if limit := header.Get("X-RateLimit-Limit"); limit != "" {
-		l, _ := strconv.ParseInt(limit, 10, 64)
-		b.limit = int(l)
-		set = true
+	if b.remaining > 0 {
+		b.remaining--
+		return 0, nil
 	}
+	return b.reset.Sub(now), nil
+}
 
-	if remaining := header.Get("X-RateLimit-Remaining"); remaining != "" {
-		r, _ := strconv.ParseInt(remaining, 10, 64)
-		b.remaining = int(r)
-		set = true
-	}
+// update updates the bucket with the given limits.
+func (b *bucket) update(limit, remaining int, reset time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 
-	if reset := header.Get("X-RateLimit-Reset"); reset != "" {
-		r, _ := strconv.ParseInt(reset, 10, 64)
-		b.reset = r
-		set = true
+	// Responses of concurrent requests can arrive out of order, only
+	// keep the lowest remaining count for a given reset window.
+	sameWindow := b.known && b.reset.Sub(reset) < time.Second && reset.Sub(b.reset) < time.Second
+	if !sameWindow || remaining < b.remaining {
+		b.remaining = remaining
 	}
-
-	// If one of the header was set, enable this bucket.
-	b.enabled = set
+	b.limit = limit
+	b.reset = reset
+	b.known = true
+	b.release()
 }
 
-// unlock unlocks the bucket without updating it.
-func (b *bucket) unlock() {
-	b.mu.Unlock()
-}
+// cancel releases the request sent to learn the limit of the bucket, if any,
+// without updating the bucket, so another request can try.
+func (b *bucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 
-// updateAndUnlock calls update with the given header, then unlocks the bucket.
-func (b *bucket) updateAndUnlock(header http.Header) {
-	b.update(header)
+	b.release()
+}
 
-	b.unlock()
+// release wakes up requests waiting for the limit of the bucket to be learned.
+// The caller must hold the lock.
+func (b *bucket) release() {
+	if b.probing {
+		close(b.learned)
+		b.learned = make(chan struct{})
+		b.probing = false
+	}
 }
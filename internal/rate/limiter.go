@@ -1,66 +1,204 @@
 package rate
 
 import (
+	"context"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
+
+	"github.com/skwair/harmony/clock"
+	"github.com/skwair/harmony/internal/endpoint"
+	"github.com/skwair/harmony/ratelimit"
 )
 
-// Limiter holds a collection of buckets to track global and per-route
-// rate limits. Create one with NewLimiter.
+// Limiter tracks global and per-bucket rate limits. Routes are mapped to the
+// buckets Discord reports in the X-RateLimit-Bucket header of responses, so
+// routes sharing a bucket share their limit. Create one with NewLimiter.
 type Limiter struct {
-	mu sync.Mutex
+	mu    sync.Mutex
+	clock clock.Clock
 
-	global  *bucket
+	// Buckets of known routes, by route.
+	routes map[string]string
+	// Buckets by ID. Routes whose bucket is not known yet
+	// use a provisional bucket identified by the route.
 	buckets map[string]*bucket
+	// Time until which all requests are rate limited.
+	globalUntil time.Time
+
+	// Where the state of the limiter is saved, if persisted. See Persist.
+	store         ratelimit.Store
+	saveInterval  time.Duration
+	onSaveError   func(error)
+	saveScheduled bool
 }
 
-// NewLimiter returns an initialized and ready to use Limiter.
-func NewLimiter() *Limiter {
+// NewLimiter returns an initialized and ready to use Limiter
+// using the given clock to wait for buckets to refill.
+func NewLimiter(clk clock.Clock) *Limiter {
 	return &Limiter{
-		global:  &bucket{},
+		clock:   clk,
+		routes:  make(map[string]string),
 		buckets: make(map[string]*bucket),
 	}
 }
 
 // Wait waits for a request to be theoretically safe to be sent (meaning it should
-// not result in a 429 TO MANY REQUESTS) given the requested endpoint's key.
-func (r *Limiter) Wait(key string) {
-	r.mu.Lock()
-
-	if r.global.enabled {
-		r.mu.Unlock()
-		r.global.lockAndWait()
-	} else {
-		_, ok := r.buckets[key]
-		if !ok {
-			r.buckets[key] = &bucket{}
+// not result in a 429 TOO MANY REQUESTS) given the requested endpoint's method and
+// key. It returns an error if ctx is done before. Once the request completes, Update
+// must be called with the headers of its response, or Cancel if it failed.
+func (l *Limiter) Wait(ctx context.Context, method, key string) error {
+	route := method + " " + key
+	for {
+		l.mu.Lock()
+		global := l.globalUntil.Sub(l.clock.Now())
+		b := l.bucket(route, key)
+		l.mu.Unlock()
+
+		if global > 0 {
+			if err := l.sleep(ctx, global); err != nil {
+				return err
+			}
+			continue
+		}
+
+		d, learned := b.reserve(l.clock.Now())
+		switch {
+		case learned != nil:
+			select {
+			case <-learned:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case d > 0:
+			if err := l.sleep(ctx, d); err != nil {
+				return err
+			}
+		default:
+			return nil
 		}
-		b := r.buckets[key]
-		r.mu.Unlock()
-		b.lockAndWait()
 	}
 }
 
-// Update updates the rate limit for an endpoint given its key.
-func (r *Limiter) Update(key string, header http.Header) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	if r.global.enabled {
-		// We were globally rate limited and still are.
-		if global := header.Get("X-RateLimit-Global"); global != "" {
-			r.global.updateAndUnlock(header)
-		} else { // We were globally rate limited but not anymore.
-			r.global.unlock()
-			r.buckets[key].update(header)
+// Update updates the rate limit of the bucket of an endpoint given its method,
+// key and the headers of the response to a request sent to this endpoint.
+func (l *Limiter) Update(method, key string, header http.Header) {
+	route := method + " " + key
+	now := l.clock.Now()
+
+	l.mu.Lock()
+	if header.Get("X-RateLimit-Global") != "" {
+		if retryAfter := parseSeconds(header.Get("Retry-After")); retryAfter > 0 {
+			l.globalUntil = now.Add(retryAfter)
+		}
+	}
+
+	provisional := l.buckets[route]
+	var b *bucket
+	var unlimited bool
+	if hash := header.Get("X-RateLimit-Bucket"); hash != "" {
+		id := hash + ":" + endpoint.MajorParameter(key)
+		l.routes[route] = id
+		delete(l.buckets, route)
+		b = l.buckets[id]
+		if b == nil {
+			b = newBucket()
+			l.buckets[id] = b
 		}
-	} else {
-		// We were not globally rate limited but now we are.
-		if global := header.Get("X-RateLimit-Global"); global != "" {
-			r.global.update(header)
-			r.buckets[key].unlock()
-		} else { // We were not globally rate limited and still are not.
-			r.buckets[key].updateAndUnlock(header)
+	} else if _, ok := l.routes[route]; !ok {
+		// Discord does not send any bucket for routes that are not rate
+		// limited, keep using the provisional bucket for this route.
+		b = provisional
+		unlimited = true
+	}
+	l.mu.Unlock()
+
+	if b == nil {
+		b = l.bucketOf(route, key)
+	}
+
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil && !unlimited {
+		// Some responses, such as errors, come without rate limit headers.
+		// They must not make the bucket of a rate limited route unlimited.
+		b.cancel()
+		if provisional != nil && provisional != b {
+			provisional.cancel()
 		}
+		l.changed()
+		return
+	}
+	remaining, _ := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	// Use the relative reset time instead of X-RateLimit-Reset
+	// so our limits are not affected by clock desynchronization.
+	reset := now.Add(parseSeconds(header.Get("X-RateLimit-Reset-After")))
+	b.update(limit, remaining, reset)
+
+	// Requests waiting on the provisional bucket of this route can now
+	// use the bucket reported by Discord.
+	if provisional != nil && provisional != b {
+		provisional.cancel()
+	}
+
+	l.changed()
+}
+
+// Cancel releases the request sent to the given endpoint without
+// updating its rate limit, typically because it could not be sent.
+func (l *Limiter) Cancel(method, key string) {
+	l.bucketOf(method+" "+key, key).cancel()
+}
+
+// Limit makes all requests wait for the given duration before being sent,
+// typically after receiving a 429 TOO MANY REQUESTS for the global rate limit.
+func (l *Limiter) Limit(d time.Duration) {
+	l.mu.Lock()
+	if until := l.clock.Now().Add(d); until.After(l.globalUntil) {
+		l.globalUntil = until
+	}
+	l.mu.Unlock()
+
+	l.changed()
+}
+
+func (l *Limiter) bucketOf(route, key string) *bucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.bucket(route, key)
+}
+
+// bucket returns the bucket of the given route, creating a provisional
+// one if its bucket is not known yet. The caller must hold the lock.
+func (l *Limiter) bucket(route, key string) *bucket {
+	id, ok := l.routes[route]
+	if !ok {
+		id = route
+	}
+
+	b, ok := l.buckets[id]
+	if !ok {
+		b = newBucket()
+		l.buckets[id] = b
+	}
+	return b
+}
+
+func (l *Limiter) sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-l.clock.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parseSeconds parses a decimal number of seconds, such as "1.5".
+func parseSeconds(s string) time.Duration {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return 0
 	}
+	return time.Duration(f * float64(time.Second))
 }
@@ -0,0 +1,251 @@
+package rate
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony/clock"
+)
+
+// rateLimitHeader returns the headers of a response to a request sent to a route
+// of the given bucket. Empty values are not set.
+func rateLimitHeader(bucket, limit, remaining, resetAfter string) http.Header {
+	h := make(http.Header)
+	for k, v := range map[string]string{
+		"X-RateLimit-Bucket":      bucket,
+		"X-RateLimit-Limit":       limit,
+		"X-RateLimit-Remaining":   remaining,
+		"X-RateLimit-Reset-After": resetAfter,
+	} {
+		if v != "" {
+			h.Set(k, v)
+		}
+	}
+	return h
+}
+
+func TestLimiterUpdateWithoutLimit(t *testing.T) {
+	const (
+		method = http.MethodGet
+		key    = "/channels/1/messages"
+	)
+
+	tests := []struct {
+		name      string
+		responses []http.Header
+
+		known     bool
+		limit     int
+		remaining int
+	}{
+		{
+			name:      "rate limited",
+			responses: []http.Header{rateLimitHeader("abc", "5", "4", "1")},
+			known:     true,
+			limit:     5,
+			remaining: 4,
+		},
+		{
+			name:      "not rate limited",
+			responses: []http.Header{rateLimitHeader("", "", "", "")},
+			known:     true,
+			limit:     0,
+			remaining: 0,
+		},
+		{
+			name: "missing headers after rate limited",
+			responses: []http.Header{
+				rateLimitHeader("abc", "5", "0", "10"),
+				rateLimitHeader("", "", "", ""),
+			},
+			known:     true,
+			limit:     5,
+			remaining: 0,
+		},
+		{
+			name: "missing limit with bucket",
+			responses: []http.Header{
+				rateLimitHeader("abc", "5", "3", "10"),
+				rateLimitHeader("abc", "", "", ""),
+			},
+			known:     true,
+			limit:     5,
+			remaining: 3,
+		},
+		{
+			name:      "missing limit on first response with bucket",
+			responses: []http.Header{rateLimitHeader("abc", "", "", "")},
+			known:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLimiter(clock.NewMock(time.Unix(0, 0)))
+			for _, h := range tt.responses {
+				l.Update(method, key, h)
+			}
+
+			b := l.bucketOf(method+" "+key, key)
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			if b.known != tt.known {
+				t.Errorf("expected known to be %t, got %t", tt.known, b.known)
+			}
+			if b.probing {
+				t.Error("expected the bucket not to be probing")
+			}
+			if b.limit != tt.limit {
+				t.Errorf("expected limit to be %d, got %d", tt.limit, b.limit)
+			}
+			if b.remaining != tt.remaining {
+				t.Errorf("expected remaining to be %d, got %d", tt.remaining, b.remaining)
+			}
+		})
+	}
+}
+
+func TestLimiterBucketRemapping(t *testing.T) {
+	type response struct {
+		method, key string
+		header      http.Header
+	}
+
+	tests := []struct {
+		name      string
+		responses []response
+
+		// Whether the routes of the first and last responses share a bucket.
+		shared bool
+		// ID of the bucket of the route of the last response.
+		bucket    string
+		remaining int
+	}{
+		{
+			name: "same bucket",
+			responses: []response{
+				{http.MethodGet, "/channels/1/messages", rateLimitHeader("abc", "5", "4", "1")},
+				{http.MethodPost, "/channels/1/messages", rateLimitHeader("abc", "5", "3", "1")},
+			},
+			shared:    true,
+			bucket:    "abc:channels/1",
+			remaining: 3,
+		},
+		{
+			name: "same bucket with different major parameters",
+			responses: []response{
+				{http.MethodGet, "/channels/1/messages", rateLimitHeader("abc", "5", "4", "1")},
+				{http.MethodGet, "/channels/2/messages", rateLimitHeader("abc", "5", "3", "1")},
+			},
+			shared:    false,
+			bucket:    "abc:channels/2",
+			remaining: 3,
+		},
+		{
+			name: "same bucket without major parameter",
+			responses: []response{
+				{http.MethodGet, "/users/@me", rateLimitHeader("abc", "5", "4", "1")},
+				{http.MethodGet, "/users/@me/guilds", rateLimitHeader("abc", "5", "3", "1")},
+			},
+			shared:    true,
+			bucket:    "abc:",
+			remaining: 3,
+		},
+		{
+			name: "different buckets",
+			responses: []response{
+				{http.MethodGet, "/channels/1/messages", rateLimitHeader("abc", "5", "4", "1")},
+				{http.MethodPost, "/channels/1/messages", rateLimitHeader("def", "5", "3", "1")},
+			},
+			shared:    false,
+			bucket:    "def:channels/1",
+			remaining: 3,
+		},
+		{
+			name: "bucket changed",
+			responses: []response{
+				{http.MethodGet, "/channels/1/messages", rateLimitHeader("abc", "5", "4", "1")},
+				{http.MethodGet, "/channels/1/messages", rateLimitHeader("def", "10", "9", "1")},
+			},
+			shared:    true,
+			bucket:    "def:channels/1",
+			remaining: 9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLimiter(clock.NewMock(time.Unix(0, 0)))
+			for _, r := range tt.responses {
+				l.Update(r.method, r.key, r.header)
+			}
+
+			first, last := tt.responses[0], tt.responses[len(tt.responses)-1]
+			firstRoute, lastRoute := first.method+" "+first.key, last.method+" "+last.key
+
+			if shared := l.bucketOf(firstRoute, first.key) == l.bucketOf(lastRoute, last.key); shared != tt.shared {
+				t.Errorf("expected routes to share a bucket to be %t, got %t", tt.shared, shared)
+			}
+
+			l.mu.Lock()
+			bucket := l.routes[lastRoute]
+			_, provisional := l.buckets[lastRoute]
+			l.mu.Unlock()
+
+			if bucket != tt.bucket {
+				t.Errorf("expected bucket to be %q, got %q", tt.bucket, bucket)
+			}
+			if provisional {
+				t.Error("expected the provisional bucket of the route to be removed")
+			}
+
+			b := l.bucketOf(lastRoute, last.key)
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			if b.remaining != tt.remaining {
+				t.Errorf("expected remaining to be %d, got %d", tt.remaining, b.remaining)
+			}
+		})
+	}
+}
+
+func TestLimiterBucketRemappingReleasesWaiters(t *testing.T) {
+	const (
+		method = http.MethodGet
+		key    = "/channels/1/messages"
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	l := NewLimiter(clock.NewMock(time.Unix(0, 0)))
+	// The first request learns the limit of the route.
+	if err := l.Wait(ctx, method, key); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second one waits on the provisional bucket of the route
+	// until the response to the first one is received.
+	errs := make(chan error, 1)
+	go func() {
+		errs <- l.Wait(ctx, method, key)
+	}()
+
+	l.Update(method, key, rateLimitHeader("abc", "5", "4", "1"))
+
+	if err := <-errs; err != nil {
+		t.Fatalf("expected waiting request to be released, got %v", err)
+	}
+
+	b := l.bucketOf(method+" "+key, key)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.remaining != 3 {
+		t.Errorf("expected remaining to be 3, got %d", b.remaining)
+	}
+}
@@ -0,0 +1,106 @@
+package rate
+
+import (
+	"time"
+
+	"github.com/skwair/harmony/ratelimit"
+)
+
+// Persist makes the limiter save its state to the given store when it changes,
+// at most once per interval. Errors are passed to onError, if not nil.
+func (l *Limiter) Persist(store ratelimit.Store, interval time.Duration, onError func(error)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.store = store
+	l.saveInterval = interval
+	l.onSaveError = onError
+}
+
+// Restore restores the state of the limiter from the given snapshot.
+// Buckets that are already refilled are ignored.
+func (l *Limiter) Restore(snap *ratelimit.Snapshot) {
+	now := l.clock.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if snap.GlobalUntil.After(l.globalUntil) {
+		l.globalUntil = snap.GlobalUntil
+	}
+
+	for id, sb := range snap.Buckets {
+		if !sb.Reset.After(now) {
+			continue
+		}
+		b := newBucket()
+		b.known = true
+		b.limit = sb.Limit
+		b.remaining = sb.Remaining
+		b.reset = sb.Reset
+		l.buckets[id] = b
+	}
+	for route, id := range snap.Routes {
+		if _, ok := l.buckets[id]; ok {
+			l.routes[route] = id
+		}
+	}
+}
+
+// Snapshot returns the current state of the limiter.
+func (l *Limiter) Snapshot() *ratelimit.Snapshot {
+	now := l.clock.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	snap := &ratelimit.Snapshot{
+		Buckets: make(map[string]ratelimit.Bucket),
+		Routes:  make(map[string]string),
+	}
+	if l.globalUntil.After(now) {
+		snap.GlobalUntil = l.globalUntil
+	}
+
+	for id, b := range l.buckets {
+		b.mu.Lock()
+		if b.known && b.limit > 0 && b.reset.After(now) {
+			snap.Buckets[id] = ratelimit.Bucket{
+				Limit:     b.limit,
+				Remaining: b.remaining,
+				Reset:     b.reset,
+			}
+		}
+		b.mu.Unlock()
+	}
+	for route, id := range l.routes {
+		if _, ok := snap.Buckets[id]; ok {
+			snap.Routes[route] = id
+		}
+	}
+	return snap
+}
+
+// changed schedules a save of the state of the limiter, if it is persisted
+// and no save is already scheduled.
+func (l *Limiter) changed() {
+	l.mu.Lock()
+	if l.store == nil || l.saveScheduled {
+		l.mu.Unlock()
+		return
+	}
+	l.saveScheduled = true
+	l.mu.Unlock()
+
+	go func() {
+		<-l.clock.After(l.saveInterval)
+
+		l.mu.Lock()
+		l.saveScheduled = false
+		l.mu.Unlock()
+
+		if err := l.store.Save(l.Snapshot()); err != nil && l.onSaveError != nil {
+			l.onSaveError(err)
+		}
+	}()
+}
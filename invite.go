@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/skwair/harmony/internal/endpoint"
+	"github.com/skwair/harmony/invite"
 )
 
 // Invite represents a code that when used, adds a user to a guild or group DM channel.
@@ -19,9 +20,22 @@ type Invite struct {
 	ApproximatePresenceCount int      `json:"approximate_presence_count,omitempty"`
 	ApproximateMemberCount   int      `json:"approximate_member_count,omitempty"`
 
+	// Set for invites to a stream or an embedded activity in a voice channel.
+	TargetType        invite.TargetType  `json:"target_type,omitempty"`
+	TargetUser        *User              `json:"target_user,omitempty"`
+	TargetApplication *InviteApplication `json:"target_application,omitempty"`
+
 	InviteMetadata
 }
 
+// InviteApplication is the partial application an invite points to.
+type InviteApplication struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Icon        string `json:"icon,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
 // InviteMetadata contains additional information about an Invite.
 type InviteMetadata struct {
 	Inviter   *User     `json:"inviter,omitempty"`
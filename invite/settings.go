@@ -8,12 +8,28 @@ type Settings struct {
 	MaxUses   *optional.Int  `json:"max_uses,omitempty"`
 	Temporary *optional.Bool `json:"temporary,omitempty"`
 	Unique    *optional.Bool `json:"unique,omitempty"`
+
+	TargetType          *optional.Int    `json:"target_type,omitempty"`
+	TargetUserID        *optional.String `json:"target_user_id,omitempty"`
+	TargetApplicationID *optional.String `json:"target_application_id,omitempty"`
 }
 
-// Setting is a function that configures a guild role.
+// TargetType is the type of target of a voice channel invite.
+type TargetType int
+
+// Types of targets of voice channel invites.
+const (
+	// TargetTypeStream invites to watch the stream of a user.
+	TargetTypeStream TargetType = 1
+	// TargetTypeEmbeddedApplication invites to join an
+	// embedded activity, such as Watch Together.
+	TargetTypeEmbeddedApplication TargetType = 2
+)
+
+// Setting is a function that configures a channel invite.
 type Setting func(*Settings)
 
-// NewSettings returns new Settings to modify a a guild role.
+// NewSettings returns new Settings to create a channel invite.
 func NewSettings(opts ...Setting) *Settings {
 	s := &Settings{}
 
@@ -52,3 +68,22 @@ func WithUnique(yes bool) Setting {
 		s.Unique = optional.NewBool(yes)
 	}
 }
+
+// WithTargetStream makes this invite point to the stream of the given user,
+// who must be streaming in the voice channel of the invite.
+func WithTargetStream(userID string) Setting {
+	return func(s *Settings) {
+		s.TargetType = optional.NewInt(int(TargetTypeStream))
+		s.TargetUserID = optional.NewString(userID)
+	}
+}
+
+// WithTargetEmbeddedApplication makes this invite launch the embedded
+// activity of the given application in the voice channel of the invite.
+// The application must have the EMBEDDED flag.
+func WithTargetEmbeddedApplication(applicationID string) Setting {
+	return func(s *Settings) {
+		s.TargetType = optional.NewInt(int(TargetTypeEmbeddedApplication))
+		s.TargetApplicationID = optional.NewString(applicationID)
+	}
+}
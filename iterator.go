@@ -0,0 +1,361 @@
+package harmony
+
+import (
+	"context"
+
+	"github.com/skwair/harmony/audit"
+	"github.com/skwair/harmony/internal/pagination"
+)
+
+// All iterators share the same usage, fetching pages lazily as they go:
+//
+//	it := client.Channel(id).IterateMessages("", 100)
+//	for it.Next(ctx) {
+//		msg := it.Message()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+
+// MessageIterator iterates over the messages of a channel.
+type MessageIterator struct {
+	it   *pagination.Iterator
+	page []Message
+	max  int // Maximum number of messages, 0 if unlimited.
+	n    int // Number of messages iterated over so far.
+}
+
+// IterateMessages returns an iterator over the messages of the channel sent before the given
+// message ID, or over all messages if before is empty. pageSize is the number of messages
+// fetched per request, between 1 and 100. See Messages for the required permissions.
+func (r *ChannelResource) IterateMessages(before string, pageSize int) *MessageIterator {
+	return r.MessagesIterator(MessagesBefore(before), MessagesPageSize(pageSize))
+}
+
+// MessageIteratorOption is a function that configures an iterator over messages.
+type MessageIteratorOption func(*messageIteratorOptions)
+
+type messageIteratorOptions struct {
+	before, after, around string
+	pageSize              int
+	max                   int
+}
+
+// MessagesBefore makes the iterator walk backward through the history of the
+// channel, from the message sent before the given message ID to the oldest one.
+// This is the default, starting from the most recent message.
+func MessagesBefore(id string) MessageIteratorOption {
+	return func(o *messageIteratorOptions) {
+		o.before, o.after, o.around = id, "", ""
+	}
+}
+
+// MessagesAfter makes the iterator walk forward through the history of the channel,
+// from the message sent after the given message ID to the most recent one. Use "0"
+// to start from the first message of the channel.
+func MessagesAfter(id string) MessageIteratorOption {
+	return func(o *messageIteratorOptions) {
+		o.before, o.after, o.around = "", id, ""
+	}
+}
+
+// MessagesAround makes the iterator return the messages sent around the given
+// message ID, from the most recent to the oldest. They are fetched with a single
+// request, so at most 100 messages are returned.
+func MessagesAround(id string) MessageIteratorOption {
+	return func(o *messageIteratorOptions) {
+		o.before, o.after, o.around = "", "", id
+	}
+}
+
+// MessagesPageSize sets the number of messages fetched per request,
+// between 1 and 100. Defaults to 100.
+func MessagesPageSize(size int) MessageIteratorOption {
+	return func(o *messageIteratorOptions) {
+		o.pageSize = size
+	}
+}
+
+// MessagesMax sets the maximum number of messages returned by the iterator.
+// Defaults to 0, which iterates over the whole history.
+func MessagesMax(max int) MessageIteratorOption {
+	return func(o *messageIteratorOptions) {
+		o.max = max
+	}
+}
+
+// MessagesIterator returns an iterator over the messages of the channel, fetching
+// pages of messages as needed, which is useful to export or purge the history of a
+// channel. By default, it walks from the most recent message to the oldest one.
+// Requests wait for rate limits like any other request. See Messages for the
+// required permissions.
+func (r *ChannelResource) MessagesIterator(opts ...MessageIteratorOption) *MessageIterator {
+	o := &messageIteratorOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	mi := &MessageIterator{max: o.max}
+	pageSize := clampPageSize(o.pageSize, 100)
+	if o.max > 0 && o.max < pageSize {
+		pageSize = o.max
+	}
+
+	if o.around != "" {
+		c := pagination.Cursor{Limit: pageSize}
+		mi.it = pagination.New(pagination.Backward, c, func(ctx context.Context, c pagination.Cursor) (int, string, error) {
+			q := c.Values()
+			q.Set("around", o.around)
+			msgs, err := r.messages(ctx, q)
+			if err != nil {
+				return 0, "", err
+			}
+			mi.page = msgs
+			// Around is a single page, an empty last ID stops the iteration.
+			return len(msgs), "", nil
+		})
+		return mi
+	}
+
+	dir := pagination.Backward
+	if o.after != "" {
+		dir = pagination.Forward
+	}
+	c := pagination.Cursor{Before: o.before, After: o.after, Limit: pageSize}
+	mi.it = pagination.New(dir, c, func(ctx context.Context, c pagination.Cursor) (int, string, error) {
+		msgs, err := r.messages(ctx, c.Values())
+		if err != nil {
+			return 0, "", err
+		}
+		// Messages are sent from the most recent to the oldest,
+		// reverse them when walking forward.
+		if dir == pagination.Forward && len(msgs) > 1 && olderID(msgs[len(msgs)-1].ID, msgs[0].ID) {
+			for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+				msgs[i], msgs[j] = msgs[j], msgs[i]
+			}
+		}
+		mi.page = msgs
+		if len(msgs) == 0 {
+			return 0, "", nil
+		}
+		return len(msgs), msgs[len(msgs)-1].ID, nil
+	})
+	return mi
+}
+
+// Next advances to the next message. It returns false when there are no more messages or an error occurred.
+func (mi *MessageIterator) Next(ctx context.Context) bool {
+	if mi.max > 0 && mi.n >= mi.max {
+		return false
+	}
+	if !mi.it.Next(ctx) {
+		return false
+	}
+	mi.n++
+	return true
+}
+
+// Message returns the current message.
+func (mi *MessageIterator) Message() *Message { return &mi.page[mi.it.Index()] }
+
+// Err returns the error that stopped the iteration, if any.
+func (mi *MessageIterator) Err() error { return mi.it.Err() }
+
+// UserIterator iterates over a list of users.
+type UserIterator struct {
+	it   *pagination.Iterator
+	page []User
+}
+
+// IterateReactions returns an iterator over the users that reacted to a message with the
+// given emoji. pageSize is the number of users fetched per request, between 1 and 100.
+func (r *ChannelResource) IterateReactions(messageID, emoji string, pageSize int) *UserIterator {
+	ui := &UserIterator{}
+	c := pagination.Cursor{Limit: clampPageSize(pageSize, 100)}
+	ui.it = pagination.New(pagination.Forward, c, func(ctx context.Context, c pagination.Cursor) (int, string, error) {
+		users, err := r.reactions(ctx, messageID, emoji, c)
+		if err != nil {
+			return 0, "", err
+		}
+		ui.page = users
+		if len(users) == 0 {
+			return 0, "", nil
+		}
+		return len(users), users[len(users)-1].ID, nil
+	})
+	return ui
+}
+
+// Next advances to the next user. It returns false when there are no more users or an error occurred.
+func (ui *UserIterator) Next(ctx context.Context) bool { return ui.it.Next(ctx) }
+
+// User returns the current user.
+func (ui *UserIterator) User() *User { return &ui.page[ui.it.Index()] }
+
+// Err returns the error that stopped the iteration, if any.
+func (ui *UserIterator) Err() error { return ui.it.Err() }
+
+// BanIterator iterates over the bans of a guild.
+type BanIterator struct {
+	it   *pagination.Iterator
+	page []Ban
+}
+
+// IterateBans returns an iterator over the bans of the guild. pageSize is the number
+// of bans fetched per request, between 1 and 1000. Requires the 'BAN_MEMBERS' permission.
+func (r *GuildResource) IterateBans(pageSize int) *BanIterator {
+	bi := &BanIterator{}
+	c := pagination.Cursor{Limit: clampPageSize(pageSize, 1000)}
+	bi.it = pagination.New(pagination.Forward, c, func(ctx context.Context, c pagination.Cursor) (int, string, error) {
+		bans, err := r.bans(ctx, c)
+		if err != nil {
+			return 0, "", err
+		}
+		bi.page = bans
+		if len(bans) == 0 || bans[len(bans)-1].User == nil {
+			return len(bans), "", nil
+		}
+		return len(bans), bans[len(bans)-1].User.ID, nil
+	})
+	return bi
+}
+
+// Next advances to the next ban. It returns false when there are no more bans or an error occurred.
+func (bi *BanIterator) Next(ctx context.Context) bool { return bi.it.Next(ctx) }
+
+// Ban returns the current ban.
+func (bi *BanIterator) Ban() *Ban { return &bi.page[bi.it.Index()] }
+
+// Err returns the error that stopped the iteration, if any.
+func (bi *BanIterator) Err() error { return bi.it.Err() }
+
+// GuildMemberIterator iterates over the members of a guild.
+type GuildMemberIterator struct {
+	it   *pagination.Iterator
+	page []GuildMember
+}
+
+// IterateMembers returns an iterator over the members of the guild. pageSize is the
+// number of members fetched per request, between 1 and 1000.
+func (r *GuildResource) IterateMembers(pageSize int) *GuildMemberIterator {
+	mi := &GuildMemberIterator{}
+	c := pagination.Cursor{Limit: clampPageSize(pageSize, 1000)}
+	mi.it = pagination.New(pagination.Forward, c, func(ctx context.Context, c pagination.Cursor) (int, string, error) {
+		members, err := r.members(ctx, c)
+		if err != nil {
+			return 0, "", err
+		}
+		mi.page = members
+		if len(members) == 0 || members[len(members)-1].User == nil {
+			return len(members), "", nil
+		}
+		return len(members), members[len(members)-1].User.ID, nil
+	})
+	return mi
+}
+
+// Next advances to the next member. It returns false when there are no more members or an error occurred.
+func (mi *GuildMemberIterator) Next(ctx context.Context) bool { return mi.it.Next(ctx) }
+
+// Member returns the current member.
+func (mi *GuildMemberIterator) Member() *GuildMember { return &mi.page[mi.it.Index()] }
+
+// Err returns the error that stopped the iteration, if any.
+func (mi *GuildMemberIterator) Err() error { return mi.it.Err() }
+
+// ScheduledEventUserIterator iterates over the users interested in a guild scheduled event.
+type ScheduledEventUserIterator struct {
+	it   *pagination.Iterator
+	page []ScheduledEventUser
+}
+
+// IterateScheduledEventUsers returns an iterator over the users interested in the given
+// scheduled event of the guild. If withMember is true, the guild member of users is also
+// returned, if they are still in the guild. pageSize is the number of users fetched per
+// request, between 1 and 100.
+func (r *GuildResource) IterateScheduledEventUsers(eventID string, withMember bool, pageSize int) *ScheduledEventUserIterator {
+	ui := &ScheduledEventUserIterator{}
+	c := pagination.Cursor{Limit: clampPageSize(pageSize, 100)}
+	ui.it = pagination.New(pagination.Forward, c, func(ctx context.Context, c pagination.Cursor) (int, string, error) {
+		users, err := r.scheduledEventUsers(ctx, eventID, withMember, c)
+		if err != nil {
+			return 0, "", err
+		}
+		ui.page = users
+		if len(users) == 0 || users[len(users)-1].User == nil {
+			return len(users), "", nil
+		}
+		return len(users), users[len(users)-1].User.ID, nil
+	})
+	return ui
+}
+
+// Next advances to the next user. It returns false when there are no more users or an error occurred.
+func (ui *ScheduledEventUserIterator) Next(ctx context.Context) bool { return ui.it.Next(ctx) }
+
+// User returns the current user.
+func (ui *ScheduledEventUserIterator) User() *ScheduledEventUser { return &ui.page[ui.it.Index()] }
+
+// Err returns the error that stopped the iteration, if any.
+func (ui *ScheduledEventUserIterator) Err() error { return ui.it.Err() }
+
+// AuditLogIterator iterates over the entries of the audit log of a guild, from the most
+// recent to the oldest.
+type AuditLogIterator struct {
+	it  *pagination.Iterator
+	log *audit.Log
+}
+
+// IterateAuditLog returns an iterator over the entries of the audit log of the guild,
+// following the before cursor of each page. audit.WithBefore sets where the iteration
+// starts and audit.WithLimit sets the number of entries fetched per request.
+// Requires the 'VIEW_AUDIT_LOG' permission.
+func (r *GuildResource) IterateAuditLog(opts ...AuditLogOption) *AuditLogIterator {
+	query := audit.NewQuery(opts...)
+
+	ai := &AuditLogIterator{}
+	c := pagination.Cursor{Before: query.Before, Limit: clampPageSize(query.Limit, 100)}
+	ai.it = pagination.New(pagination.Backward, c, func(ctx context.Context, c pagination.Cursor) (int, string, error) {
+		log, err := r.auditLog(ctx, query, c)
+		if err != nil {
+			return 0, "", err
+		}
+		ai.log = log
+		if len(log.Entries) == 0 {
+			return 0, "", nil
+		}
+		return len(log.Entries), log.Entries[len(log.Entries)-1].EntryID(), nil
+	})
+	return ai
+}
+
+// Next advances to the next entry. It returns false when there are no more entries or an error occurred.
+func (ai *AuditLogIterator) Next(ctx context.Context) bool { return ai.it.Next(ctx) }
+
+// Entry returns the current entry.
+func (ai *AuditLogIterator) Entry() audit.LogEntry { return ai.log.Entries[ai.it.Index()] }
+
+// Log returns the page of the audit log the current entry belongs to,
+// which also holds the users and webhooks referenced by its entries.
+func (ai *AuditLogIterator) Log() *audit.Log { return ai.log }
+
+// Err returns the error that stopped the iteration, if any.
+func (ai *AuditLogIterator) Err() error { return ai.it.Err() }
+
+// olderID reports whether the snowflake a is older than the snowflake b.
+func olderID(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// clampPageSize returns the given page size bounded to [1, max],
+// or max if it is not set.
+func clampPageSize(size, max int) int {
+	if size < 1 || size > max {
+		return max
+	}
+	return size
+}
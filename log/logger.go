@@ -98,7 +98,8 @@ const (
 	// and logs every websocket message. Very useful for debugging or developing
 	// new features.
 	// Beware of debug level as it is very chatty and it will log sensitive
-	// information such as bot tokens, voice connections secret keys, etc.
+	// information such as message contents, voice connections secret keys,
+	// etc. Bot tokens are redacted, see harmony.WithPayloadLogging.
 	LevelDebug Level = 3
 	// LevelInfo is here to notify that something happened. There's generally nothing to do
 	// about them, they are just here to inform about an event.
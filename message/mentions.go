@@ -0,0 +1,47 @@
+package message
+
+import "encoding/json"
+
+// MentionType is a type of mention that can be allowed in a message.
+type MentionType string
+
+// Types of mentions that can be allowed with AllowedMentions.Parse.
+const (
+	MentionRoles    MentionType = "roles"
+	MentionUsers    MentionType = "users"
+	MentionEveryone MentionType = "everyone"
+)
+
+// AllowedMentions controls which mentions in the content of a message actually
+// notify users. Mentions that are not allowed are still displayed. The zero value
+// allows no mention at all, see NoMentions.
+type AllowedMentions struct {
+	// Types of mentions to allow. Users and roles mentioned with MentionUsers
+	// or MentionRoles can not be listed in Users or Roles at the same time.
+	Parse []MentionType `json:"parse"`
+	// IDs of the roles that can be mentioned, up to 100.
+	Roles []string `json:"roles,omitempty"`
+	// IDs of the users that can be mentioned, up to 100.
+	Users []string `json:"users,omitempty"`
+	// Whether to mention the author of the message being replied to.
+	RepliedUser bool `json:"replied_user,omitempty"`
+}
+
+// NoMentions returns allowed mentions that suppress all mentions, which is
+// useful when sending content provided by users.
+func NoMentions() *AllowedMentions {
+	return &AllowedMentions{}
+}
+
+// MarshalJSON implements the json.Marshaler interface. A nil Parse is sent
+// as an empty list, otherwise Discord would use its default behavior of
+// allowing all mentions.
+func (m *AllowedMentions) MarshalJSON() ([]byte, error) {
+	type allowedMentions AllowedMentions
+
+	am := allowedMentions(*m)
+	if am.Parse == nil {
+		am.Parse = []MentionType{}
+	}
+	return json.Marshal(am)
+}
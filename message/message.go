@@ -21,6 +21,52 @@ const (
 	TypeChannelFollowAdd
 )
 
+// Message types that were added after the initial ones. Some values are
+// not used by Discord, hence the explicit values.
+const (
+	TypeGuildDiscoveryDisqualified              Type = 14
+	TypeGuildDiscoveryRequalified               Type = 15
+	TypeGuildDiscoveryGracePeriodInitialWarning Type = 16
+	TypeGuildDiscoveryGracePeriodFinalWarning   Type = 17
+	TypeThreadCreated                           Type = 18
+	TypeReply                                   Type = 19
+	TypeChatInputCommand                        Type = 20
+	TypeThreadStarterMessage                    Type = 21
+	TypeGuildInviteReminder                     Type = 22
+	TypeContextMenuCommand                      Type = 23
+	TypeAutoModerationAction                    Type = 24
+	TypeRoleSubscriptionPurchase                Type = 25
+	TypeInteractionPremiumUpsell                Type = 26
+	TypeStageStart                              Type = 27
+	TypeStageEnd                                Type = 28
+	TypeStageSpeaker                            Type = 29
+	TypeStageTopic                              Type = 31
+	TypeGuildApplicationPremiumSubscription     Type = 32
+	TypeGuildIncidentAlertModeEnabled           Type = 36
+	TypeGuildIncidentAlertModeDisabled          Type = 37
+	TypeGuildIncidentReportRaid                 Type = 38
+	TypeGuildIncidentReportFalseAlarm           Type = 39
+	TypePurchaseNotification                    Type = 44
+	TypePollResult                              Type = 46
+)
+
+// Aliases matching the names used by Discord's documentation.
+const (
+	TypeUserJoin   = TypeGuildMemberJoin
+	TypeGuildBoost = TypeUserPremiumGuildSubscription
+)
+
+// IsSystem returns whether messages of this type are generated by Discord
+// rather than sent by a user, a bot or a webhook.
+func (t Type) IsSystem() bool {
+	switch t {
+	case TypeDefault, TypeReply, TypeChatInputCommand, TypeContextMenuCommand, TypeThreadStarterMessage:
+		return false
+	default:
+		return true
+	}
+}
+
 // Flag describes extra features a message can have.
 type Flag int
 
@@ -31,6 +77,16 @@ const (
 	FlagIsCrosspost Flag = 1 << 1
 	// Do not include any embeds when serializing this message.
 	FlagSuppressEmbeds Flag = 1 << 2
+	// The source message for this crosspost has been deleted (via Channel Following).
+	FlagSourceMessageDeleted Flag = 1 << 3
+	// This message came from the urgent message system.
+	FlagUrgent Flag = 1 << 4
+	// This message has an associated thread, with the same ID as the message.
+	FlagHasThread Flag = 1 << 5
+	// This message is only visible to the user who invoked the interaction.
+	FlagEphemeral Flag = 1 << 6
+	// This message is an interaction response and the bot is "thinking".
+	FlagLoading Flag = 1 << 7
 )
 
 // Attachment is a file attached to a message.
@@ -47,6 +103,21 @@ type Attachment struct {
 // Reference is a reference to an original message.
 type Reference struct {
 	MessageID string `json:"message_id"`
-	ChannelID string `json:"channel_id"`
-	GuildID   string `json:"guild_id"`
+	ChannelID string `json:"channel_id,omitempty"`
+	GuildID   string `json:"guild_id,omitempty"`
+	// When replying, whether to fail if the referenced message does not exist
+	// instead of sending a regular message. Defaults to true when nil.
+	FailIfNotExists *bool `json:"fail_if_not_exists,omitempty"`
+}
+
+// RoleSubscriptionData is set on messages of type TypeRoleSubscriptionPurchase.
+type RoleSubscriptionData struct {
+	// ID of the SKU and listing that the user is subscribed to.
+	RoleSubscriptionListingID string `json:"role_subscription_listing_id"`
+	// Name of the tier that the user is subscribed to.
+	TierName string `json:"tier_name"`
+	// Cumulative number of months that the user has been subscribed for.
+	TotalMonthsSubscribed int `json:"total_months_subscribed"`
+	// Whether this notification is for a renewal rather than a new purchase.
+	IsRenewal bool `json:"is_renewal"`
 }
@@ -0,0 +1,139 @@
+package harmony
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/skwair/harmony/message"
+)
+
+// userJoinMessages are the messages the official client rotates through when
+// displaying a message of type message.TypeGuildMemberJoin. The one to display
+// is chosen based on the timestamp of the message.
+var userJoinMessages = []string{
+	"%s joined the party.",
+	"%s is here.",
+	"Welcome, %s. We hope you brought pizza.",
+	"A wild %s appeared.",
+	"%s just landed.",
+	"%s just slid into the server.",
+	"%s just showed up!",
+	"Welcome %s. Say hi!",
+	"%s hopped into the server.",
+	"Everyone welcome %s!",
+	"Glad you're here, %s.",
+	"Good to see you, %s.",
+	"Yay you made it, %s!",
+}
+
+// SystemContent returns the text the official Discord client displays for
+// this message. For messages that are not system messages (see
+// message.Type.IsSystem), this is simply the content of the message.
+// Messages of unknown types also return their content as is.
+func (m *Message) SystemContent() string {
+	author := "Someone"
+	if m.Author != nil {
+		author = m.Author.Username
+	}
+
+	mentioned := "someone"
+	if len(m.Mentions) > 0 {
+		mentioned = m.Mentions[0].Username
+	}
+
+	switch m.Type {
+	case message.TypeRecipientAdd:
+		return fmt.Sprintf("%s added %s to the group.", author, mentioned)
+	case message.TypeRecipientRemove:
+		if len(m.Mentions) > 0 && m.Author != nil && m.Mentions[0].ID == m.Author.ID {
+			return fmt.Sprintf("%s left the group.", author)
+		}
+		return fmt.Sprintf("%s removed %s from the group.", author, mentioned)
+	case message.TypeCall:
+		return fmt.Sprintf("%s started a call.", author)
+	case message.TypeChannelNameChange:
+		return fmt.Sprintf("%s changed the channel name: %s", author, m.Content)
+	case message.TypeChannelIconChange:
+		return fmt.Sprintf("%s changed the channel icon.", author)
+	case message.TypeChannelPinnedMessage:
+		return fmt.Sprintf("%s pinned a message to this channel.", author)
+	case message.TypeGuildMemberJoin:
+		i := m.Timestamp.UnixNano() / 1e6 % int64(len(userJoinMessages))
+		return fmt.Sprintf(userJoinMessages[i], author)
+	case message.TypeUserPremiumGuildSubscription:
+		return boostMessage(author, m.Content)
+	case message.TypeUserPremiumGuildSubscriptionTier1,
+		message.TypeUserPremiumGuildSubscriptionTier2,
+		message.TypeUserPremiumGuildSubscriptionTier3:
+		lvl := int(m.Type-message.TypeUserPremiumGuildSubscriptionTier1) + 1
+		return fmt.Sprintf("%s This server has achieved Level %d!", boostMessage(author, m.Content), lvl)
+	case message.TypeChannelFollowAdd:
+		return fmt.Sprintf("%s has added %s to this channel. Its most important updates will show up here.", author, m.Content)
+	case message.TypeGuildDiscoveryDisqualified:
+		return "This server has been removed from Server Discovery because it no longer passes all the requirements. Check Server Settings for more details."
+	case message.TypeGuildDiscoveryRequalified:
+		return "This server is eligible for Server Discovery again and has been automatically relisted!"
+	case message.TypeGuildDiscoveryGracePeriodInitialWarning:
+		return "This server has failed Discovery activity requirements for 1 week. If this server fails for 4 weeks in a row, it will be automatically removed from Discovery."
+	case message.TypeGuildDiscoveryGracePeriodFinalWarning:
+		return "This server has failed Discovery activity requirements for 3 weeks in a row. If this server fails for 1 more week, it will be removed from Discovery."
+	case message.TypeThreadCreated:
+		return fmt.Sprintf("%s started a thread: %s. See all threads.", author, m.Content)
+	case message.TypeGuildInviteReminder:
+		return "Wondering who to invite? Start by inviting anyone who can help you build the server!"
+	case message.TypeAutoModerationAction:
+		return "AutoMod has blocked a message in this channel."
+	case message.TypeRoleSubscriptionPurchase:
+		if m.RoleSubscriptionData == nil {
+			return fmt.Sprintf("%s joined a role subscription.", author)
+		}
+		verb := "joined"
+		if m.RoleSubscriptionData.IsRenewal {
+			verb = "renewed"
+		}
+		return fmt.Sprintf("%s %s %s and has been a subscriber for %s!",
+			author, verb, m.RoleSubscriptionData.TierName, plural(m.RoleSubscriptionData.TotalMonthsSubscribed, "month"))
+	case message.TypeStageStart:
+		return fmt.Sprintf("%s started %s", author, m.Content)
+	case message.TypeStageEnd:
+		return fmt.Sprintf("%s ended %s", author, m.Content)
+	case message.TypeStageSpeaker:
+		return fmt.Sprintf("%s is now a speaker.", author)
+	case message.TypeStageTopic:
+		return fmt.Sprintf("%s changed the Stage topic: %s", author, m.Content)
+	case message.TypeGuildApplicationPremiumSubscription:
+		app := "an application"
+		if m.Application != nil && m.Application.Name != "" {
+			app = m.Application.Name
+		}
+		return fmt.Sprintf("%s upgraded %s to premium for this server!", author, app)
+	case message.TypeGuildIncidentAlertModeEnabled:
+		return fmt.Sprintf("%s enabled security actions until %s.", author, m.Content)
+	case message.TypeGuildIncidentAlertModeDisabled:
+		return fmt.Sprintf("%s disabled security actions.", author)
+	case message.TypeGuildIncidentReportRaid:
+		return fmt.Sprintf("%s reported a raid in this server.", author)
+	case message.TypeGuildIncidentReportFalseAlarm:
+		return fmt.Sprintf("%s reported a false alarm in this server.", author)
+	default:
+		return m.Content
+	}
+}
+
+// boostMessage returns the text displayed when a user boosts a guild. The
+// content of boost messages holds the number of boosts if greater than one.
+func boostMessage(author, content string) string {
+	if n, err := strconv.Atoi(strings.TrimSpace(content)); err == nil && n > 1 {
+		return fmt.Sprintf("%s just boosted the server %d times!", author, n)
+	}
+	return fmt.Sprintf("%s just boosted the server!", author)
+}
+
+// plural returns n followed by word, adding an "s" to word if n is not 1.
+func plural(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return strconv.Itoa(n) + " " + word + "s"
+}
@@ -0,0 +1,63 @@
+package harmony
+
+import (
+	"time"
+
+	"github.com/skwair/harmony/internal/endpoint"
+)
+
+// MetricsCollector is the interface to implement in order to export metrics
+// about a Client to a monitoring system. See the metrics package for an
+// implementation exposing them to Prometheus. All methods must be safe for
+// concurrent use and should return quickly since they are called inline.
+type MetricsCollector interface {
+	// ObserveEvent is called each time a Gateway event is received,
+	// with its type (e.g. "MESSAGE_CREATE").
+	ObserveEvent(eventType string)
+
+	// ObserveRequest is called each time a response to a REST request is
+	// received, including responses to requests that are retried because
+	// they were rate limited (429). route is the path of the request with
+	// IDs and tokens replaced by placeholders (e.g. "/channels/:id/messages"),
+	// so it can be used as a label. statusCode is 0 if the request failed
+	// without a response.
+	ObserveRequest(method, route string, statusCode int, latency time.Duration)
+
+	// ObserveReconnect is called after each attempt to reconnect to the Gateway.
+	ObserveReconnect(success bool)
+
+	// ObserveVoicePackets is called each time audio packets are sent
+	// or received by a voice connection, with their number and size.
+	ObserveVoicePackets(sent bool, packets, bytes int)
+}
+
+// WithMetricsCollector sets the MetricsCollector metrics about the client are
+// sent to. It is also used by voice connections established by the client.
+// Defaults to nil, no metrics are collected.
+func WithMetricsCollector(m MetricsCollector) ClientOption {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+// observeEvent records a Gateway event of the given type, if metrics are enabled.
+func (c *Client) observeEvent(typ string) {
+	if c.metrics != nil {
+		c.metrics.ObserveEvent(typ)
+	}
+}
+
+// observeRequest records a response to a request to the given endpoint,
+// if metrics are enabled.
+func (c *Client) observeRequest(e *endpoint.Endpoint, statusCode int, latency time.Duration) {
+	if c.metrics != nil {
+		c.metrics.ObserveRequest(e.Method, endpoint.Route(e.Path), statusCode, latency)
+	}
+}
+
+// observeReconnect records an attempt to reconnect to the Gateway, if metrics are enabled.
+func (c *Client) observeReconnect(success bool) {
+	if c.metrics != nil {
+		c.metrics.ObserveReconnect(success)
+	}
+}
@@ -0,0 +1,326 @@
+/*
+Package metrics provides a harmony.MetricsCollector exposing metrics about a
+Client to Prometheus (https://prometheus.io). It writes the Prometheus text
+format directly so it does not require any additional dependency:
+
+	collector := metrics.New()
+
+	client, err := harmony.NewClient(token, harmony.WithMetricsCollector(collector))
+	if err != nil {
+		// Handle error
+	}
+
+	http.Handle("/metrics", collector)
+
+The following metrics are exposed, prefixed with the namespace of the collector
+("harmony" by default):
+
+	gateway_events_total{type}                       Gateway events received.
+	gateway_reconnects_total{result}                 Attempts to reconnect to the Gateway.
+	rest_requests_total{method,route,status}         Responses to REST requests.
+	rest_rate_limited_total{method,route}            REST requests rate limited (429).
+	rest_request_duration_seconds{method,route}      Histogram of REST request latencies.
+	voice_packets_total{direction}                   Audio packets sent and received.
+	voice_bytes_total{direction}                     Size of audio packets sent and received.
+
+Other monitoring systems can be supported by implementing harmony.MetricsCollector.
+*/
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skwair/harmony"
+)
+
+// DefaultBuckets are the default buckets of the REST request latency histogram, in seconds.
+var DefaultBuckets = []float64{.025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var _ harmony.MetricsCollector = (*Collector)(nil)
+
+// Collector is a harmony.MetricsCollector that keeps metrics in memory and
+// exposes them in the Prometheus text format. It is an http.Handler, meant
+// to be scraped by Prometheus.
+type Collector struct {
+	namespace string
+	buckets   []float64
+
+	mu          sync.Mutex
+	events      map[string]uint64
+	reconnects  map[bool]uint64
+	requests    map[requestKey]uint64
+	rateLimited map[routeKey]uint64
+	latencies   map[routeKey]*histogram
+	packets     map[bool]uint64
+	bytes       map[bool]uint64
+}
+
+type routeKey struct {
+	method, route string
+}
+
+type requestKey struct {
+	routeKey
+	status int
+}
+
+type histogram struct {
+	counts []uint64 // One per bucket, not cumulative.
+	count  uint64
+	sum    float64
+}
+
+// Option is a function that configures a Collector.
+type Option func(*Collector)
+
+// WithNamespace sets the prefix of the name of all metrics.
+// Defaults to "harmony".
+func WithNamespace(ns string) Option {
+	return func(c *Collector) {
+		c.namespace = ns
+	}
+}
+
+// WithBuckets sets the upper bounds of the buckets of the REST request
+// latency histogram, in seconds, in increasing order.
+// Defaults to DefaultBuckets.
+func WithBuckets(buckets []float64) Option {
+	return func(c *Collector) {
+		c.buckets = buckets
+	}
+}
+
+// New returns a new Collector.
+func New(opts ...Option) *Collector {
+	c := &Collector{
+		namespace:   "harmony",
+		buckets:     DefaultBuckets,
+		events:      make(map[string]uint64),
+		reconnects:  make(map[bool]uint64),
+		requests:    make(map[requestKey]uint64),
+		rateLimited: make(map[routeKey]uint64),
+		latencies:   make(map[routeKey]*histogram),
+		packets:     make(map[bool]uint64),
+		bytes:       make(map[bool]uint64),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// ObserveEvent implements harmony.MetricsCollector.
+func (c *Collector) ObserveEvent(eventType string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.events[eventType]++
+}
+
+// ObserveRequest implements harmony.MetricsCollector.
+func (c *Collector) ObserveRequest(method, route string, statusCode int, latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rk := routeKey{method: method, route: route}
+	c.requests[requestKey{routeKey: rk, status: statusCode}]++
+	if statusCode == http.StatusTooManyRequests {
+		c.rateLimited[rk]++
+	}
+
+	h, ok := c.latencies[rk]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(c.buckets))}
+		c.latencies[rk] = h
+	}
+	s := latency.Seconds()
+	if i := sort.SearchFloat64s(c.buckets, s); i < len(c.buckets) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += s
+}
+
+// ObserveReconnect implements harmony.MetricsCollector.
+func (c *Collector) ObserveReconnect(success bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reconnects[success]++
+}
+
+// ObserveVoicePackets implements harmony.MetricsCollector.
+func (c *Collector) ObserveVoicePackets(sent bool, packets, bytes int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.packets[sent] += uint64(packets)
+	c.bytes[sent] += uint64(bytes)
+}
+
+// ServeHTTP writes the metrics of the collector in the Prometheus text format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = c.WriteTo(w)
+}
+
+// WriteTo writes the metrics of the collector to w in the Prometheus text format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	c.write(bw)
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// write writes all metrics to w. c.mu must be held.
+func (c *Collector) write(w *bufio.Writer) {
+	name := c.metricName("gateway_events_total")
+	writeHeader(w, name, "counter", "Gateway events received, by type.")
+	types := make([]string, 0, len(c.events))
+	for t := range c.events {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		writeSample(w, name, labels("type", t), float64(c.events[t]))
+	}
+
+	name = c.metricName("gateway_reconnects_total")
+	writeHeader(w, name, "counter", "Attempts to reconnect to the Gateway, by result.")
+	writeSample(w, name, labels("result", "success"), float64(c.reconnects[true]))
+	writeSample(w, name, labels("result", "failure"), float64(c.reconnects[false]))
+
+	name = c.metricName("rest_requests_total")
+	writeHeader(w, name, "counter", "Responses to REST requests, by method, route and status code.")
+	requests := make([]requestKey, 0, len(c.requests))
+	for k := range c.requests {
+		requests = append(requests, k)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		if requests[i].routeKey != requests[j].routeKey {
+			return requests[i].routeKey.less(requests[j].routeKey)
+		}
+		return requests[i].status < requests[j].status
+	})
+	for _, k := range requests {
+		l := labels("method", k.method, "route", k.route, "status", strconv.Itoa(k.status))
+		writeSample(w, name, l, float64(c.requests[k]))
+	}
+
+	name = c.metricName("rest_rate_limited_total")
+	writeHeader(w, name, "counter", "REST requests rate limited (429), by method and route.")
+	for _, k := range sortedRoutes(c.rateLimited) {
+		writeSample(w, name, labels("method", k.method, "route", k.route), float64(c.rateLimited[k]))
+	}
+
+	name = c.metricName("rest_request_duration_seconds")
+	writeHeader(w, name, "histogram", "Latency of REST requests, by method and route.")
+	routes := make([]routeKey, 0, len(c.latencies))
+	for k := range c.latencies {
+		routes = append(routes, k)
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].less(routes[j]) })
+	for _, k := range routes {
+		h := c.latencies[k]
+		l := labels("method", k.method, "route", k.route)
+		var cumulative uint64
+		for i, upper := range c.buckets {
+			cumulative += h.counts[i]
+			writeSample(w, name+"_bucket", l+`,le="`+formatFloat(upper)+`"`, float64(cumulative))
+		}
+		writeSample(w, name+"_bucket", l+`,le="+Inf"`, float64(h.count))
+		writeSample(w, name+"_sum", l, h.sum)
+		writeSample(w, name+"_count", l, float64(h.count))
+	}
+
+	name = c.metricName("voice_packets_total")
+	writeHeader(w, name, "counter", "Audio packets sent and received by voice connections.")
+	writeSample(w, name, labels("direction", "sent"), float64(c.packets[true]))
+	writeSample(w, name, labels("direction", "received"), float64(c.packets[false]))
+
+	name = c.metricName("voice_bytes_total")
+	writeHeader(w, name, "counter", "Size of audio packets sent and received by voice connections, in bytes.")
+	writeSample(w, name, labels("direction", "sent"), float64(c.bytes[true]))
+	writeSample(w, name, labels("direction", "received"), float64(c.bytes[false]))
+}
+
+func (c *Collector) metricName(name string) string {
+	if c.namespace == "" {
+		return name
+	}
+	return c.namespace + "_" + name
+}
+
+func (k routeKey) less(o routeKey) bool {
+	if k.route != o.route {
+		return k.route < o.route
+	}
+	return k.method < o.method
+}
+
+func sortedRoutes(m map[routeKey]uint64) []routeKey {
+	routes := make([]routeKey, 0, len(m))
+	for k := range m {
+		routes = append(routes, k)
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].less(routes[j]) })
+	return routes
+}
+
+func writeHeader(w *bufio.Writer, name, typ, help string) {
+	w.WriteString("# HELP " + name + " " + help + "\n")
+	w.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+// writeSample writes a sample of the given metric. l are its labels,
+// formatted by the labels function, without braces.
+func writeSample(w *bufio.Writer, name, l string, v float64) {
+	w.WriteString(name)
+	if l != "" {
+		w.WriteString("{" + l + "}")
+	}
+	w.WriteString(" " + formatFloat(v) + "\n")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels formats the given label names and values, which alternate.
+func labels(kv ...string) string {
+	var sb strings.Builder
+	for i := 0; i+1 < len(kv); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(kv[i] + `="` + labelEscaper.Replace(kv[i+1]) + `"`)
+	}
+	return sb.String()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
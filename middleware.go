@@ -0,0 +1,44 @@
+package harmony
+
+// EventHandler handles an event received from the Gateway, see Middleware.
+type EventHandler func(e *Event)
+
+// Middleware wraps the handlers of events registered with OnXxx methods, for
+// instance to log events, recover from panics, collect metrics or filter events.
+// It returns a handler calling next to continue handling the event, or not
+// calling it to drop the event. See Client.UseMiddleware.
+type Middleware func(next EventHandler) EventHandler
+
+// UseMiddleware adds a middleware wrapping event handlers registered with OnXxx
+// methods. Middlewares are called in the order they are added, the first one
+// being the outermost. They run in the goroutine of the handler, each time an
+// event for which a handler is registered is received. Events sent on channels
+// returned by Events are not affected by middlewares.
+func (c *Client) UseMiddleware(m Middleware) {
+	if m == nil {
+		panic("harmony: trying to use a nil middleware")
+	}
+
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+
+	// Copy the list so handlers being called keep using the previous one.
+	middlewares := make([]Middleware, 0, len(c.middlewares)+1)
+	middlewares = append(middlewares, c.middlewares...)
+	c.middlewares = append(middlewares, m)
+}
+
+// chain returns an event handler calling the given middlewares and then h.
+func chain(middlewares []Middleware, h handler) EventHandler {
+	next := EventHandler(func(e *Event) {
+		if ch, ok := h.(contextHandler); ok {
+			ch.handleContext(e.Context(), e.Data)
+			return
+		}
+		h.handle(e.Data)
+	})
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		next = middlewares[i](next)
+	}
+	return next
+}
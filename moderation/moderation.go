@@ -0,0 +1,404 @@
+/*
+Package moderation provides helpers to moderate guild members: warnings,
+mutes, kicks and bans, with optional direct message notifications and audit
+log reasons filled automatically, as well as per guild escalation policies
+applying sanctions automatically as warnings accumulate:
+
+	m := moderation.New(client)
+	m.SetPolicy(guildID, &moderation.Policy{
+		Steps: []moderation.Step{
+			{Warnings: 3, Action: moderation.ActionMute, Duration: time.Hour},
+			{Warnings: 5, Action: moderation.ActionBan},
+		},
+		Window: 30 * 24 * time.Hour,
+	})
+
+	// Later, in a command handler:
+	res, err := m.Warn(ctx, guildID, userID,
+		moderation.WithReason("spam"),
+		moderation.WithModerator(moderatorName),
+		moderation.WithDMNotify(true),
+	)
+
+Warnings and temporary bans are tracked in memory and are lost when the
+program stops.
+*/
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/clock"
+	"github.com/skwair/harmony/guild"
+)
+
+// Action is a moderation action.
+type Action string
+
+// List of moderation actions.
+const (
+	ActionWarn Action = "warn"
+	ActionMute Action = "mute"
+	ActionKick Action = "kick"
+	ActionBan  Action = "ban"
+)
+
+// past returns the past tense of the action, as shown to users.
+func (a Action) past() string {
+	switch a {
+	case ActionWarn:
+		return "warned"
+	case ActionMute:
+		return "muted"
+	case ActionKick:
+		return "kicked"
+	case ActionBan:
+		return "banned"
+	}
+	return string(a)
+}
+
+// Moderator applies moderation actions and escalation policies. Pending unbans
+// are canceled once the context of its client is done, see harmony.WithContext.
+// It is safe for concurrent use. Create one with New.
+type Moderator struct {
+	client *harmony.Client
+	clock  clock.Clock
+
+	mu       sync.Mutex
+	policies map[string]*Policy
+	warnings warnings
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Option is a function that configures a Moderator.
+type Option func(*Moderator)
+
+// WithClock sets the clock used by the moderator, mainly for testing purposes.
+// Defaults to the clock of the client.
+func WithClock(c clock.Clock) Option {
+	return func(m *Moderator) {
+		m.clock = c
+	}
+}
+
+// New returns a new Moderator acting through the given client.
+func New(c *harmony.Client, opts ...Option) *Moderator {
+	m := &Moderator{
+		client:   c,
+		clock:    c.Clock(),
+		policies: make(map[string]*Policy),
+		warnings: make(warnings),
+		closed:   make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if done := c.Context().Done(); done != nil {
+		go m.closeOnDone(done)
+	}
+
+	return m
+}
+
+// SetPolicy sets the escalation policy of the given guild. A nil policy
+// disables escalation for this guild.
+func (m *Moderator) SetPolicy(guildID string, p *Policy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if p == nil {
+		delete(m.policies, guildID)
+		return
+	}
+	m.policies[guildID] = p
+}
+
+// Close cancels pending unbans of temporary bans.
+func (m *Moderator) Close() {
+	m.closeOnce.Do(func() { close(m.closed) })
+}
+
+// closeOnDone closes the moderator once done is closed.
+func (m *Moderator) closeOnDone(done <-chan struct{}) {
+	select {
+	case <-done:
+		m.Close()
+	case <-m.closed:
+	}
+}
+
+// ActionOption is a function that configures a moderation action.
+type ActionOption func(*action)
+
+type action struct {
+	reason    string
+	moderator string
+	duration  time.Duration
+	notify    bool
+	delDays   int
+}
+
+// WithReason sets the reason of a moderation action. It is included in
+// the audit log and in the direct message sent to the user, if any.
+func WithReason(reason string) ActionOption {
+	return func(a *action) {
+		a.reason = reason
+	}
+}
+
+// WithModerator sets the name of the moderator responsible for an
+// action, which is included in the audit log reason.
+func WithModerator(name string) ActionOption {
+	return func(a *action) {
+		a.moderator = name
+	}
+}
+
+// WithDuration sets the duration of a mute, which is mandatory, or of a ban,
+// making it temporary. It is ignored for other actions.
+func WithDuration(d time.Duration) ActionOption {
+	return func(a *action) {
+		a.duration = d
+	}
+}
+
+// WithDMNotify sets whether the user is notified of the action by direct message.
+// Users that do not accept direct messages from the bot are not notified, and the
+// action is still applied.
+func WithDMNotify(yes bool) ActionOption {
+	return func(a *action) {
+		a.notify = yes
+	}
+}
+
+// WithDeleteMessageDays sets the number of days (0-7) of messages to
+// delete when banning a user. It is ignored for other actions.
+func WithDeleteMessageDays(days int) ActionOption {
+	return func(a *action) {
+		a.delDays = days
+	}
+}
+
+func newAction(opts []ActionOption) *action {
+	a := &action{}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// auditReason returns the reason set in the audit log for the given action.
+func (a *action) auditReason(act Action) string {
+	reason := "Moderation: " + string(act)
+	if a.duration > 0 {
+		reason += " for " + a.duration.String()
+	}
+	if a.reason != "" {
+		reason += ": " + a.reason
+	}
+	if a.moderator != "" {
+		reason += " (by " + a.moderator + ")"
+	}
+	return reason
+}
+
+// Result is the result of a warning.
+type Result struct {
+	// Warnings is the number of warnings of the user
+	// that count towards escalation, including this one.
+	Warnings int
+	// Escalation is the step of the escalation policy of the
+	// guild that was applied, nil if there was none.
+	Escalation *Step
+}
+
+// Warn warns a user. Warnings have no effect by themselves but are counted to apply
+// the escalation policy of the guild, if any. If applying the escalation fails, the
+// returned result is still valid and the error says so.
+func (m *Moderator) Warn(ctx context.Context, guildID, userID string, opts ...ActionOption) (*Result, error) {
+	a := newAction(opts)
+
+	now := m.clock.Now()
+	m.mu.Lock()
+	policy := m.policies[guildID]
+	var since time.Time
+	if policy != nil && policy.Window > 0 {
+		since = now.Add(-policy.Window)
+	}
+	res := &Result{Warnings: m.warnings.add(guildID, userID, now, since)}
+	if policy != nil {
+		res.Escalation = policy.step(res.Warnings)
+	}
+	m.mu.Unlock()
+
+	if a.notify {
+		m.notify(ctx, guildID, userID, ActionWarn, a)
+	}
+	if res.Escalation == nil {
+		return res, nil
+	}
+
+	esc := &action{
+		reason:    fmt.Sprintf("%d warnings", res.Warnings),
+		moderator: a.moderator,
+		duration:  res.Escalation.Duration,
+		notify:    a.notify,
+	}
+	if a.reason != "" {
+		esc.reason += ", last one for " + a.reason
+	}
+	if err := m.apply(ctx, guildID, userID, res.Escalation.Action, esc); err != nil {
+		return res, fmt.Errorf("could not apply escalation: %w", err)
+	}
+	return res, nil
+}
+
+// Warnings returns the number of warnings of the given user that currently
+// count towards escalation.
+func (m *Moderator) Warnings(guildID, userID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var since time.Time
+	if p := m.policies[guildID]; p != nil && p.Window > 0 {
+		since = m.clock.Now().Add(-p.Window)
+	}
+
+	n := 0
+	for _, t := range m.warnings[guildID][userID] {
+		if t.After(since) {
+			n++
+		}
+	}
+	return n
+}
+
+// ClearWarnings forgets all warnings of the given user.
+func (m *Moderator) ClearWarnings(guildID, userID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.warnings.reset(guildID, userID)
+}
+
+// Mute times out a user for the duration set with WithDuration, which is
+// mandatory and can not exceed guild.MaxTimeout.
+// Requires the 'MODERATE_MEMBERS' permission.
+func (m *Moderator) Mute(ctx context.Context, guildID, userID string, opts ...ActionOption) error {
+	return m.apply(ctx, guildID, userID, ActionMute, newAction(opts))
+}
+
+// Unmute removes the timeout of a user.
+// Requires the 'MODERATE_MEMBERS' permission.
+func (m *Moderator) Unmute(ctx context.Context, guildID, userID string, opts ...ActionOption) error {
+	a := newAction(opts)
+	settings := guild.NewMemberSettings(guild.WithTimeoutUntil(time.Time{}))
+	return m.client.Guild(guildID).ModifyMemberWithReason(ctx, userID, settings, a.auditReason("unmute"))
+}
+
+// Kick removes a user from the guild. Requires the 'KICK_MEMBERS' permission.
+func (m *Moderator) Kick(ctx context.Context, guildID, userID string, opts ...ActionOption) error {
+	return m.apply(ctx, guildID, userID, ActionKick, newAction(opts))
+}
+
+// Ban bans a user from the guild, temporarily if a duration is set with WithDuration.
+// Banning a user clears their warnings. Requires the 'BAN_MEMBERS' permission.
+func (m *Moderator) Ban(ctx context.Context, guildID, userID string, opts ...ActionOption) error {
+	return m.apply(ctx, guildID, userID, ActionBan, newAction(opts))
+}
+
+func (m *Moderator) apply(ctx context.Context, guildID, userID string, act Action, a *action) error {
+	g := m.client.Guild(guildID)
+
+	switch act {
+	case ActionMute:
+		if a.duration <= 0 || a.duration > guild.MaxTimeout {
+			return fmt.Errorf("mute duration must be between 0 and %s, got %s", guild.MaxTimeout, a.duration)
+		}
+		settings := guild.NewMemberSettings(guild.WithTimeoutUntil(m.clock.Now().Add(a.duration)))
+		if err := g.ModifyMemberWithReason(ctx, userID, settings, a.auditReason(act)); err != nil {
+			return err
+		}
+		if a.notify {
+			m.notify(ctx, guildID, userID, act, a)
+		}
+		return nil
+
+	case ActionKick:
+		// Users can not be notified once they left the guild.
+		if a.notify {
+			m.notify(ctx, guildID, userID, act, a)
+		}
+		return g.KickWithReason(ctx, userID, a.auditReason(act))
+
+	case ActionBan:
+		if a.notify {
+			m.notify(ctx, guildID, userID, act, a)
+		}
+		if err := g.BanWithReason(ctx, userID, a.delDays, a.auditReason(act)); err != nil {
+			return err
+		}
+
+		m.mu.Lock()
+		m.warnings.reset(guildID, userID)
+		m.mu.Unlock()
+
+		if a.duration > 0 {
+			go m.unbanAfter(guildID, userID, a)
+		}
+		return nil
+	}
+
+	return errors.New("unknown moderation action " + string(act))
+}
+
+// unbanAfter unbans the given user once the duration of their ban expired.
+func (m *Moderator) unbanAfter(guildID, userID string, a *action) {
+	select {
+	case <-m.closed:
+		return
+	case <-m.clock.After(a.duration):
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// There is nothing to report the error to.
+	_ = m.client.Guild(guildID).UnbanWithReason(ctx, userID, "Moderation: temporary ban expired")
+}
+
+// notify sends a direct message to the user, telling them about the action.
+// Errors are ignored, since users may not accept direct messages.
+func (m *Moderator) notify(ctx context.Context, guildID, userID string, act Action, a *action) {
+	dm, err := m.client.CurrentUser().NewDM(ctx, userID)
+	if err != nil {
+		return
+	}
+
+	guildName := "a server"
+	if m.client.State != nil {
+		if g := m.client.State.Guild(guildID); g != nil {
+			guildName = "**" + g.Name + "**"
+		}
+	}
+
+	msg := fmt.Sprintf("You have been %s in %s", act.past(), guildName)
+	if a.duration > 0 && (act == ActionMute || act == ActionBan) {
+		msg += " for " + a.duration.String()
+	}
+	if a.reason != "" {
+		msg += ". Reason: " + a.reason
+	}
+
+	_, _ = m.client.Channel(dm.ID).Send(ctx, harmony.WithContent(msg))
+}
@@ -0,0 +1,251 @@
+package moderation
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/clock"
+	"github.com/skwair/harmony/harmonytest"
+)
+
+// newServer returns a test server accepting the requests sent by a Moderator.
+// Direct messages are sent to the channel with ID "dm".
+func newServer() *harmonytest.Server {
+	srv := harmonytest.NewServer()
+
+	noContent := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+	srv.Handle(http.MethodPatch, "/guilds/:guild/members/:user", noContent)
+	srv.Handle(http.MethodDelete, "/guilds/:guild/members/:user", noContent)
+	srv.Handle(http.MethodPut, "/guilds/:guild/bans/:user", noContent)
+	srv.Handle(http.MethodDelete, "/guilds/:guild/bans/:user", noContent)
+	srv.Handle(http.MethodPost, "/users/@me/channels", func(w http.ResponseWriter, r *http.Request) {
+		harmonytest.WriteJSON(w, http.StatusOK, &harmony.Channel{ID: "dm"})
+	})
+	srv.AddChannel(&harmony.Channel{ID: "dm"})
+
+	return srv
+}
+
+func TestWarnEscalation(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	clk := clock.NewMock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	m := New(c, WithClock(clk))
+	m.SetPolicy("1", &Policy{Steps: []Step{
+		{Warnings: 3, Action: ActionBan},
+		{Warnings: 2, Action: ActionMute, Duration: time.Hour},
+	}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		warnings int
+		action   Action
+	}{
+		{warnings: 1},
+		{warnings: 2, action: ActionMute},
+		{warnings: 3, action: ActionBan},
+	}
+	for _, tt := range tests {
+		res, err := m.Warn(ctx, "1", "2", WithReason("spam"), WithModerator("mod"))
+		if err != nil {
+			t.Fatalf("could not warn user: %v", err)
+		}
+		if res.Warnings != tt.warnings {
+			t.Errorf("expected %d warnings, got %d", tt.warnings, res.Warnings)
+		}
+		if action := escalation(res); action != tt.action {
+			t.Errorf("expected escalation to be %q after %d warnings, got %q", tt.action, tt.warnings, action)
+		}
+	}
+
+	req, err := srv.WaitRequest(ctx, harmonytest.MatchRoute(http.MethodPatch, "/guilds/1/members/2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mute struct {
+		CommunicationDisabledUntil string `json:"communication_disabled_until"`
+	}
+	if err = req.Decode(&mute); err != nil {
+		t.Fatal(err)
+	}
+	if mute.CommunicationDisabledUntil != "2022-01-01T01:00:00Z" {
+		t.Errorf("expected the user to be muted until 2022-01-01T01:00:00Z, got %q", mute.CommunicationDisabledUntil)
+	}
+	expected := "Moderation: mute for 1h0m0s: 2 warnings, last one for spam (by mod)"
+	if reason := req.Header.Get("X-Audit-Log-Reason"); reason != expected {
+		t.Errorf("expected audit log reason %q, got %q", expected, reason)
+	}
+
+	if _, err = srv.WaitRequest(ctx, harmonytest.MatchRoute(http.MethodPut, "/guilds/1/bans/2")); err != nil {
+		t.Fatal(err)
+	}
+	if n := m.Warnings("1", "2"); n != 0 {
+		t.Errorf("expected warnings to be cleared once banned, got %d", n)
+	}
+}
+
+func TestWarningsWindow(t *testing.T) {
+	c, err := harmony.NewClient("token")
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	clk := clock.NewMock(time.Unix(0, 0))
+	m := New(c, WithClock(clk))
+	m.SetPolicy("1", &Policy{Window: time.Hour})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err = m.Warn(ctx, "1", "2"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := m.Warnings("1", "2"); n != 2 {
+		t.Errorf("expected 2 warnings, got %d", n)
+	}
+
+	clk.Add(time.Hour)
+	if n := m.Warnings("1", "2"); n != 0 {
+		t.Errorf("expected warnings to expire after the window, got %d", n)
+	}
+	res, err := m.Warn(ctx, "1", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Warnings != 1 {
+		t.Errorf("expected expired warnings not to count, got %d", res.Warnings)
+	}
+
+	m.ClearWarnings("1", "2")
+	if n := m.Warnings("1", "2"); n != 0 {
+		t.Errorf("expected warnings to be cleared, got %d", n)
+	}
+}
+
+func TestTemporaryBan(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	clk := clock.NewMock(time.Unix(0, 0))
+	m := New(c, WithClock(clk))
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = m.Ban(ctx, "1", "2", WithDuration(time.Hour), WithDMNotify(true), WithReason("spam")); err != nil {
+		t.Fatalf("could not ban user: %v", err)
+	}
+
+	// The user is notified before being banned.
+	if msgs := srv.Messages("dm"); len(msgs) != 1 || msgs[0].Content != "You have been banned in a server for 1h0m0s. Reason: spam" {
+		t.Errorf("expected the user to be notified of the ban, got %+v", msgs)
+	}
+
+	for clk.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clk.Add(time.Hour)
+
+	req, err := srv.WaitRequest(ctx, harmonytest.MatchRoute(http.MethodDelete, "/guilds/1/bans/2"))
+	if err != nil {
+		t.Fatalf("expected the user to be unbanned once the ban expired: %v", err)
+	}
+	if reason := req.Header.Get("X-Audit-Log-Reason"); reason != "Moderation: temporary ban expired" {
+		t.Errorf("expected audit log reason of the unban to be set, got %q", reason)
+	}
+}
+
+func TestMuteDuration(t *testing.T) {
+	c, err := harmony.NewClient("token")
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	m := New(c)
+
+	for _, d := range []time.Duration{0, -time.Second, 29 * 24 * time.Hour} {
+		if err = m.Mute(context.Background(), "1", "2", WithDuration(d)); err == nil {
+			t.Errorf("expected an error for a mute of %s", d)
+		}
+	}
+}
+
+func TestPolicyStep(t *testing.T) {
+	p := &Policy{Steps: []Step{
+		{Warnings: 5, Action: ActionBan},
+		{Warnings: 0, Action: ActionKick},
+		{Warnings: 3, Action: ActionMute},
+	}}
+
+	tests := []struct {
+		warnings int
+		expected Action
+	}{
+		{warnings: 1},
+		{warnings: 3, expected: ActionMute},
+		{warnings: 4, expected: ActionMute},
+		{warnings: 5, expected: ActionBan},
+		{warnings: 10, expected: ActionBan},
+	}
+
+	for _, tt := range tests {
+		var action Action
+		if s := p.step(tt.warnings); s != nil {
+			action = s.Action
+		}
+		if action != tt.expected {
+			t.Errorf("expected step for %d warnings to be %q, got %q", tt.warnings, tt.expected, action)
+		}
+	}
+}
+
+func TestAuditReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []ActionOption
+		expected string
+	}{
+		{name: "none", expected: "Moderation: kick"},
+		{name: "reason", opts: []ActionOption{WithReason("spam")}, expected: "Moderation: kick: spam"},
+		{name: "duration", opts: []ActionOption{WithDuration(time.Minute)}, expected: "Moderation: kick for 1m0s"},
+		{
+			name:     "all",
+			opts:     []ActionOption{WithReason("spam"), WithModerator("mod"), WithDuration(time.Minute)},
+			expected: "Moderation: kick for 1m0s: spam (by mod)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if reason := newAction(tt.opts).auditReason(ActionKick); reason != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, reason)
+			}
+		})
+	}
+}
+
+// escalation returns the action of the escalation of the given result, if any.
+func escalation(res *Result) Action {
+	if res.Escalation == nil {
+		return ""
+	}
+	return res.Escalation.Action
+}
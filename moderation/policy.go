@@ -0,0 +1,72 @@
+package moderation
+
+import (
+	"sort"
+	"time"
+)
+
+// Step is a step of an escalation policy: the action automatically taken
+// against a user once they received a given number of warnings.
+type Step struct {
+	// Warnings is the number of warnings that triggers this step.
+	Warnings int
+	// Action taken, either ActionMute, ActionKick or ActionBan.
+	Action Action
+	// Duration of mutes, and of bans for temporary bans.
+	Duration time.Duration
+}
+
+// Policy is an escalation policy, configured per guild with Moderator.SetPolicy.
+type Policy struct {
+	// Steps of the policy, in any order. Each time a user is warned, the step
+	// with the highest number of warnings lower than or equal to the number of
+	// warnings of the user is applied, if any. With steps at 3 and 5 warnings,
+	// the first step is applied on the 3rd and 4th warnings and the second on
+	// every warning from the 5th.
+	Steps []Step
+	// Window is the duration during which warnings count towards escalation.
+	// Warnings never expire if it is 0.
+	Window time.Duration
+}
+
+// step returns the step to apply for the given number of warnings, if any.
+func (p *Policy) step(warnings int) *Step {
+	steps := append([]Step(nil), p.Steps...)
+	sort.Slice(steps, func(i, j int) bool { return steps[i].Warnings > steps[j].Warnings })
+
+	for _, s := range steps {
+		if s.Warnings > 0 && s.Warnings <= warnings {
+			return &s
+		}
+	}
+	return nil
+}
+
+// warnings records the times at which users were warned, by guild and user.
+type warnings map[string]map[string][]time.Time
+
+// add records a warning for the given user and returns the number of warnings
+// given to this user after the given time.
+func (w warnings) add(guildID, userID string, now, since time.Time) int {
+	users, ok := w[guildID]
+	if !ok {
+		users = make(map[string][]time.Time)
+		w[guildID] = users
+	}
+
+	var active []time.Time
+	for _, t := range users[userID] {
+		if t.After(since) {
+			active = append(active, t)
+		}
+	}
+	active = append(active, now)
+	users[userID] = active
+
+	return len(active)
+}
+
+// reset forgets the warnings of the given user.
+func (w warnings) reset(guildID, userID string) {
+	delete(w[guildID], userID)
+}
@@ -0,0 +1,321 @@
+/*
+Package oauth2d implements the OAuth2 flows of Discord, so web dashboards built
+next to bots can authenticate users and act on their behalf without another
+library:
+
+	cfg := &oauth2d.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  "https://example.com/callback",
+		Scopes:       []string{harmony.ScopeIdentify, harmony.ScopeGuilds},
+	}
+
+	// Redirect users to the authorization URL.
+	http.Redirect(w, r, cfg.AuthURL(state), http.StatusFound)
+
+	// Then, in the handler of the redirect URL.
+	token, err := cfg.Exchange(ctx, r.URL.Query().Get("code"))
+	if err != nil {
+		// Handle error.
+	}
+	user, err := cfg.CurrentUser(ctx, token)
+
+Tokens can be stored and refreshed with Refresh once expired. Calls made on
+behalf of users that are not covered by this package can be sent with the
+harmony.Client returned by Config.Client.
+*/
+package oauth2d
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skwair/harmony"
+)
+
+// OAuth2 scopes that are not used by harmony.Client,
+// see the harmony.ScopeXxx constants for the others.
+const (
+	ScopeBot                   = "bot"
+	ScopeEmail                 = "email"
+	ScopeApplicationsCommands  = "applications.commands"
+	ScopeGuildsJoin            = "guilds.join"
+	ScopeWebhookIncoming       = "webhook.incoming"
+	ScopeRoleConnectionsWrite  = "role_connections.write"
+	ScopeApplicationsBuildRead = "applications.builds.read"
+)
+
+const defaultAPIURL = "https://discord.com/api"
+
+// Config is the OAuth2 configuration of an application, shown in the OAuth2
+// tab of the developer portal.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the URL users are redirected to once they authorized the
+	// application. It must be registered in the developer portal.
+	RedirectURL string
+	// Scopes requested when authorizing the application.
+	Scopes []string
+
+	// HTTPClient is the client used to send requests.
+	// Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// APIURL is the base URL of the API, mostly useful for testing.
+	// Defaults to https://discord.com/api.
+	APIURL string
+}
+
+// AuthOption is a function that configures an authorization URL.
+type AuthOption func(url.Values)
+
+// WithPermissions sets the permissions requested for the bot when
+// authorizing the application with the "bot" scope.
+func WithPermissions(permissions uint64) AuthOption {
+	return func(q url.Values) {
+		q.Set("permissions", strconv.FormatUint(permissions, 10))
+	}
+}
+
+// WithGuild preselects the guild the bot is added to when authorizing the
+// application with the "bot" scope. If lock is true, users can not select
+// another guild.
+func WithGuild(guildID string, lock bool) AuthOption {
+	return func(q url.Values) {
+		q.Set("guild_id", guildID)
+		if lock {
+			q.Set("disable_guild_select", "true")
+		}
+	}
+}
+
+// WithPrompt sets whether users who already authorized the application are
+// asked to authorize it again ("consent") or not ("none").
+// Defaults to "consent".
+func WithPrompt(prompt string) AuthOption {
+	return func(q url.Values) {
+		q.Set("prompt", prompt)
+	}
+}
+
+// AuthURL returns the URL users are sent to in order to authorize the
+// application. state is an opaque value sent back to the redirect URL, which
+// must be checked to protect against CSRF attacks.
+func (c *Config) AuthURL(state string, opts ...AuthOption) string {
+	q := url.Values{}
+	q.Set("client_id", c.ClientID)
+	q.Set("response_type", "code")
+	q.Set("scope", strings.Join(c.Scopes, " "))
+	if c.RedirectURL != "" {
+		q.Set("redirect_uri", c.RedirectURL)
+	}
+	if state != "" {
+		q.Set("state", state)
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	return c.apiURL() + "/oauth2/authorize?" + q.Encode()
+}
+
+// Token is an OAuth2 token granted by a user to the application.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	// Scope is the space separated list of scopes granted to the token.
+	Scope string `json:"scope"`
+	// Expiry is when the access token expires.
+	Expiry time.Time `json:"expiry"`
+}
+
+// Scopes returns the scopes granted to the token.
+func (t *Token) Scopes() []string {
+	return strings.Fields(t.Scope)
+}
+
+// Expired reports whether the access token is expired, or is about to expire
+// in the next minute, in which case it should be refreshed.
+func (t *Token) Expired() bool {
+	return !t.Expiry.IsZero() && time.Now().Add(time.Minute).After(t.Expiry)
+}
+
+// Error is an error returned by the OAuth2 endpoints of Discord, for
+// instance when exchanging an invalid or expired authorization code.
+type Error struct {
+	StatusCode int
+	// Code is the OAuth2 error code, such as "invalid_grant".
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("oauth2d: %d %s: %s", e.StatusCode, e.Code, e.Description)
+	}
+	return fmt.Sprintf("oauth2d: %d %s", e.StatusCode, e.Code)
+}
+
+// Exchange exchanges the authorization code sent to the redirect URL for a token.
+func (c *Config) Exchange(ctx context.Context, code string) (*Token, error) {
+	if code == "" {
+		return nil, errors.New("oauth2d: empty authorization code")
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	if c.RedirectURL != "" {
+		form.Set("redirect_uri", c.RedirectURL)
+	}
+	return c.token(ctx, form)
+}
+
+// Refresh returns a new token from the refresh token of an expired one.
+func (c *Config) Refresh(ctx context.Context, t *Token) (*Token, error) {
+	if t.RefreshToken == "" {
+		return nil, errors.New("oauth2d: token has no refresh token")
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", t.RefreshToken)
+	return c.token(ctx, form)
+}
+
+// Revoke revokes the given token, for instance when users log out.
+func (c *Config) Revoke(ctx context.Context, t *Token) error {
+	form := url.Values{}
+	form.Set("token", t.AccessToken)
+	form.Set("token_type_hint", "access_token")
+
+	resp, err := c.post(ctx, "/oauth2/token/revoke", form)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return oauthError(resp)
+	}
+	return nil
+}
+
+// token requests a token with the given form.
+func (c *Config) token(ctx context.Context, form url.Values) (*Token, error) {
+	resp, err := c.post(ctx, "/oauth2/token", form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, oauthError(resp)
+	}
+
+	var t struct {
+		Token
+		ExpiresIn int `json:"expires_in"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return nil, err
+	}
+	if t.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+	return &t.Token, nil
+}
+
+// post sends the given form to the given OAuth2 endpoint,
+// authenticated with the client credentials.
+func (c *Config) post(ctx context.Context, path string, form url.Values) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, c.apiURL()+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+
+	return c.httpClient().Do(req)
+}
+
+// oauthError returns the error sent in the given response.
+func oauthError(resp *http.Response) error {
+	e := &Error{StatusCode: resp.StatusCode}
+	if err := json.NewDecoder(resp.Body).Decode(e); err != nil || e.Code == "" {
+		e.Code = http.StatusText(resp.StatusCode)
+	}
+	return e
+}
+
+// Client returns a harmony.Client acting on behalf of the user who granted the
+// given token, restricted to the scopes of the token. See harmony.WithBearerToken.
+func (c *Config) Client(t *Token, opts ...harmony.ClientOption) (*harmony.Client, error) {
+	if t == nil || t.AccessToken == "" {
+		return nil, errors.New("oauth2d: a token is mandatory to create a client")
+	}
+
+	defaults := []harmony.ClientOption{
+		harmony.WithBearerToken(t.Scopes()...),
+		harmony.WithHTTPClient(c.httpClient()),
+	}
+	if c.APIURL != "" {
+		defaults = append(defaults, harmony.WithBaseURL(c.APIURL))
+	}
+	return harmony.NewClient(t.AccessToken, append(defaults, opts...)...)
+}
+
+// CurrentUser returns the user who granted the given token.
+// Requires the "identify" scope.
+func (c *Config) CurrentUser(ctx context.Context, t *Token) (*harmony.User, error) {
+	client, err := c.Client(t)
+	if err != nil {
+		return nil, err
+	}
+	return client.CurrentUser().Get(ctx)
+}
+
+// Guilds returns the guilds of the user who granted the given token.
+// Requires the "guilds" scope.
+func (c *Config) Guilds(ctx context.Context, t *Token) ([]harmony.PartialGuild, error) {
+	client, err := c.Client(t)
+	if err != nil {
+		return nil, err
+	}
+	return client.CurrentUser().Guilds(ctx)
+}
+
+// GuildMember returns the member of the given guild of the user who granted
+// the given token. Requires the "guilds.members.read" scope.
+func (c *Config) GuildMember(ctx context.Context, t *Token, guildID string) (*harmony.GuildMember, error) {
+	client, err := c.Client(t)
+	if err != nil {
+		return nil, err
+	}
+	return client.CurrentUser().GuildMember(ctx, guildID)
+}
+
+func (c *Config) apiURL() string {
+	if c.APIURL != "" {
+		return strings.TrimSuffix(c.APIURL, "/")
+	}
+	return defaultAPIURL
+}
+
+func (c *Config) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
@@ -17,6 +17,20 @@ func (b *Bool) MarshalJSON() ([]byte, error) {
 	return json.Marshal(b.b)
 }
 
+// Value returns the value of b and true, or the zero value and false if b
+// is nil or set to nil.
+func (b *Bool) Value() (bool, bool) {
+	if b == nil || b.nil {
+		return false, false
+	}
+	return b.b, true
+}
+
+// IsNil returns whether b is set to nil, as returned by NewNilBool.
+func (b *Bool) IsNil() bool {
+	return b != nil && b.nil
+}
+
 // NewBool returns a new optional bool set to b.
 func NewBool(b bool) *Bool {
 	return &Bool{
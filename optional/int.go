@@ -17,6 +17,20 @@ func (i *Int) MarshalJSON() ([]byte, error) {
 	return json.Marshal(i.i)
 }
 
+// Value returns the value of i and true, or the zero value and false if i
+// is nil or set to nil.
+func (i *Int) Value() (int, bool) {
+	if i == nil || i.nil {
+		return 0, false
+	}
+	return i.i, true
+}
+
+// IsNil returns whether i is set to nil, as returned by NewNilInt.
+func (i *Int) IsNil() bool {
+	return i != nil && i.nil
+}
+
 // NewInt returns a new optional int set to i.
 func NewInt(i int) *Int {
 	return &Int{
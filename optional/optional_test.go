@@ -0,0 +1,65 @@
+package optional
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    func() (interface{}, bool)
+		expected interface{}
+		ok       bool
+	}{
+		{name: "int", value: func() (interface{}, bool) { return NewInt(3).Value() }, expected: 3, ok: true},
+		{name: "zero int", value: func() (interface{}, bool) { return NewInt(0).Value() }, expected: 0, ok: true},
+		{name: "nil int", value: func() (interface{}, bool) { return NewNilInt().Value() }, expected: 0},
+		{name: "unset int", value: func() (interface{}, bool) { return (*Int)(nil).Value() }, expected: 0},
+		{name: "string", value: func() (interface{}, bool) { return NewString("a").Value() }, expected: "a", ok: true},
+		{name: "nil string", value: func() (interface{}, bool) { return NewNilString().Value() }, expected: ""},
+		{name: "unset string", value: func() (interface{}, bool) { return (*String)(nil).Value() }, expected: ""},
+		{name: "bool", value: func() (interface{}, bool) { return NewBool(true).Value() }, expected: true, ok: true},
+		{name: "nil bool", value: func() (interface{}, bool) { return NewNilBool().Value() }, expected: false},
+		{name: "unset bool", value: func() (interface{}, bool) { return (*Bool)(nil).Value() }, expected: false},
+		{name: "string slice", value: func() (interface{}, bool) { return NewStringSlice([]string{"a"}).Value() }, expected: []string{"a"}, ok: true},
+		{name: "nil string slice", value: func() (interface{}, bool) { return NewStringSlice(nil).Value() }, expected: []string(nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ok := tt.value()
+			if ok != tt.ok {
+				t.Errorf("expected ok to be %t, got %t", tt.ok, ok)
+			}
+			if !reflect.DeepEqual(v, tt.expected) {
+				t.Errorf("expected value %#v, got %#v", tt.expected, v)
+			}
+		})
+	}
+}
+
+func TestIsNil(t *testing.T) {
+	tests := []struct {
+		name     string
+		isNil    bool
+		expected bool
+	}{
+		{name: "int", isNil: NewInt(0).IsNil(), expected: false},
+		{name: "nil int", isNil: NewNilInt().IsNil(), expected: true},
+		{name: "unset int", isNil: (*Int)(nil).IsNil(), expected: false},
+		{name: "string", isNil: NewString("").IsNil(), expected: false},
+		{name: "nil string", isNil: NewNilString().IsNil(), expected: true},
+		{name: "bool", isNil: NewBool(false).IsNil(), expected: false},
+		{name: "nil bool", isNil: NewNilBool().IsNil(), expected: true},
+		{name: "nil string slice", isNil: NewNilStringSlice().IsNil(), expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.isNil != tt.expected {
+				t.Errorf("expected IsNil to return %t, got %t", tt.expected, tt.isNil)
+			}
+		})
+	}
+}
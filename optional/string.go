@@ -17,6 +17,20 @@ func (s *String) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s.s)
 }
 
+// Value returns the value of s and true, or the zero value and false if s
+// is nil or set to nil.
+func (s *String) Value() (string, bool) {
+	if s == nil || s.nil {
+		return "", false
+	}
+	return s.s, true
+}
+
+// IsNil returns whether s is set to nil, as returned by NewNilString.
+func (s *String) IsNil() bool {
+	return s != nil && s.nil
+}
+
 // NewString returns a new optional string set to s.
 func NewString(s string) *String {
 	return &String{
@@ -40,12 +54,26 @@ type StringSlice struct {
 // MarshalJSON implements the json.Marshaler interface.
 func (s *StringSlice) MarshalJSON() ([]byte, error) {
 	if s.nil {
-		return []byte(`"null"`), nil
+		return []byte(`null`), nil
 	}
 
 	return json.Marshal(s.ss)
 }
 
+// Value returns the value of s and true, or nil and false if s
+// is nil or set to nil.
+func (s *StringSlice) Value() ([]string, bool) {
+	if s == nil || s.nil {
+		return nil, false
+	}
+	return s.ss, true
+}
+
+// IsNil returns whether s is set to nil, as returned by NewNilStringSlice.
+func (s *StringSlice) IsNil() bool {
+	return s != nil && s.nil
+}
+
 // NewStringSlice returns a new optional string slice set to ss.
 // If ss is nil, it is equivalent to NewNilStringSlice.
 func NewStringSlice(ss []string) *StringSlice {
@@ -15,18 +15,30 @@ func (c *Client) sendPayload(ctx context.Context, op int, d interface{}) error {
 		return err
 	}
 	p := &payload.Payload{Op: op, D: b}
-	c.logger.Debugf("sent payload: %s", p)
+	if c.logsPayloads() {
+		c.logPayload("sent payload: %s", c.redactPayload(p))
+	}
 	return payload.Send(ctx, c.conn, p)
 }
 
 // recvPayload receives a single Payload from the Gateway.
 func (c *Client) recvPayload() (*payload.Payload, error) {
-	p, err := payload.Recv(c.ctx, &c.connRMu, c.conn)
+	var (
+		p   *payload.Payload
+		err error
+	)
+	if c.inflater != nil {
+		p, err = payload.RecvStream(c.ctx, &c.connRMu, c.conn, c.inflater)
+	} else {
+		p, err = payload.Recv(c.ctx, &c.connRMu, c.conn)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	c.logger.Debugf("received payload: %s", p)
+	if c.logsPayloads() {
+		c.logPayload("received payload: %s", c.redactPayload(p))
+	}
 
 	return p, nil
 }
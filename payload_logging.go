@@ -0,0 +1,222 @@
+package harmony
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"time"
+
+	"github.com/skwair/harmony/internal/endpoint"
+	"github.com/skwair/harmony/internal/payload"
+	"github.com/skwair/harmony/log"
+)
+
+// redacted replaces values that are not logged.
+const redacted = "[REDACTED]"
+
+// tokenFields are the JSON fields holding secrets, they are always redacted.
+var tokenFields = []string{"token", "access_token", "refresh_token", "client_secret", "password"}
+
+// contentFields are the JSON fields holding user content, they are
+// redacted unless PayloadLogUserContent is set to true.
+var contentFields = []string{"content", "embeds", "attachments"}
+
+// payloadLogging configures how REST and Gateway payloads are logged.
+type payloadLogging struct {
+	level         log.Level
+	redactContent bool
+	fields        map[string]bool
+}
+
+// PayloadLoggingOption is a function that configures payload logging, see WithPayloadLogging.
+type PayloadLoggingOption func(*payloadLogging)
+
+// PayloadLogUserContent sets whether user content, such as the content, embeds
+// and attachments of messages, is logged as is. Defaults to false, it is redacted.
+func PayloadLogUserContent(y bool) PayloadLoggingOption {
+	return func(pl *payloadLogging) {
+		pl.redactContent = !y
+	}
+}
+
+// PayloadLogRedactFields adds JSON fields whose values are redacted from logged
+// payloads, at any depth, in addition to tokens and user content.
+func PayloadLogRedactFields(fields ...string) PayloadLoggingOption {
+	return func(pl *payloadLogging) {
+		for _, f := range fields {
+			pl.fields[f] = true
+		}
+	}
+}
+
+// WithPayloadLogging logs REST requests and responses along with Gateway payloads
+// sent and received at the given level, so they can be inspected without wrapping
+// the HTTP client. Tokens, including those in Authorization headers and webhook
+// URLs, are always redacted. User content is redacted by default as well, see
+// PayloadLogUserContent. Payloads are only logged if the logger of the client
+// has a level greater or equal to the given one, see WithLogger.
+// Defaults to logging payloads at the debug level, without redacting user content.
+func WithPayloadLogging(level log.Level, opts ...PayloadLoggingOption) ClientOption {
+	return func(c *Client) {
+		c.payloadLogging = newPayloadLogging(level, true)
+		for _, opt := range opts {
+			opt(c.payloadLogging)
+		}
+	}
+}
+
+func newPayloadLogging(level log.Level, redactContent bool) *payloadLogging {
+	pl := &payloadLogging{
+		level:         level,
+		redactContent: redactContent,
+		fields:        make(map[string]bool),
+	}
+	for _, f := range tokenFields {
+		pl.fields[f] = true
+	}
+	return pl
+}
+
+// redacts reports whether the value of the given JSON field must be redacted.
+func (pl *payloadLogging) redacts(field string) bool {
+	if pl.fields[field] {
+		return true
+	}
+	if pl.redactContent {
+		for _, f := range contentFields {
+			if f == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// logsPayloads reports whether payloads are logged with the current logger.
+func (c *Client) logsPayloads() bool {
+	return c.logger.Level() >= c.payloadLogging.level
+}
+
+// logPayload logs a payload at the level set with WithPayloadLogging.
+func (c *Client) logPayload(format string, v ...interface{}) {
+	switch c.payloadLogging.level {
+	case log.LevelError:
+		c.logger.Errorf(format, v...)
+	case log.LevelWarn:
+		c.logger.Warnf(format, v...)
+	case log.LevelInfo:
+		c.logger.Infof(format, v...)
+	default:
+		c.logger.Debugf(format, v...)
+	}
+}
+
+// logRequest logs the given request, sent to the given endpoint with the given payload.
+func (c *Client) logRequest(e *endpoint.Endpoint, req *http.Request, p *requestPayload) {
+	head, _ := httputil.DumpRequestOut(req, false)
+
+	var body string
+	if p.hasBody() {
+		body = c.redactBody(p.contentType, p.body)
+	}
+	c.logPayload("--> %s%s", c.redactHead(e, head), body)
+}
+
+// logResponse logs the given response, received after the given latency, to a
+// request to the given endpoint. Its body is read and replaced so it can be
+// read again.
+func (c *Client) logResponse(e *endpoint.Endpoint, resp *http.Response, latency time.Duration) {
+	head, _ := httputil.DumpResponse(resp, false)
+
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		c.logger.Warnf("could not read response body of %s %s for logging: %v", e.Method, e.Path, err)
+	}
+
+	c.logPayload("<-- %s %s (%s)\n%s%s", e.Method, redactPath(e.Path), latency,
+		c.redactHead(e, head), c.redactBody(resp.Header.Get("Content-Type"), b))
+}
+
+// redactHead redacts the token of the client and the token in the path of
+// the given endpoint, if any, from the given dump of HTTP headers.
+func (c *Client) redactHead(e *endpoint.Endpoint, head []byte) string {
+	s := strings.Replace(string(head), c.token, redacted, -1)
+	return strings.Replace(s, e.Path, redactPath(e.Path), 1)
+}
+
+// redactBody returns the given body, of the given content type, as it is logged.
+func (c *Client) redactBody(contentType string, body []byte) string {
+	switch {
+	case len(body) == 0:
+		return ""
+	case strings.HasPrefix(contentType, "application/json"):
+		return string(c.payloadLogging.redactJSON(body))
+	case strings.HasPrefix(contentType, "multipart/"):
+		// Multipart bodies hold files and a JSON payload,
+		// which can not be redacted without parsing them.
+		return fmt.Sprintf("[%s body, %d bytes]", contentType, len(body))
+	default:
+		return string(body)
+	}
+}
+
+// redactPayload returns a copy of the given Gateway payload that can be logged.
+func (c *Client) redactPayload(p *payload.Payload) *payload.Payload {
+	return &payload.Payload{
+		Op: p.Op,
+		S:  p.S,
+		T:  p.T,
+		D:  c.payloadLogging.redactJSON(p.D),
+	}
+}
+
+// redactJSON returns the given JSON document with the values of the redacted
+// fields replaced. It is returned as is if it is not valid JSON.
+func (pl *payloadLogging) redactJSON(data []byte) []byte {
+	if len(data) == 0 {
+		return data
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // Keep snowflakes and other numbers as they are.
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return data
+	}
+
+	b, err := json.Marshal(pl.redactValue(v))
+	if err != nil {
+		return data
+	}
+	return b
+}
+
+func (pl *payloadLogging) redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if field != nil && pl.redacts(k) {
+				v[k] = redacted
+			} else {
+				v[k] = pl.redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = pl.redactValue(v[i])
+		}
+	}
+	return v
+}
+
+// redactPath returns the given request path with
+// webhook and interaction tokens redacted.
+func redactPath(path string) string {
+	return endpoint.RedactTokens(path, redacted)
+}
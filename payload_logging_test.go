@@ -0,0 +1,86 @@
+package harmony
+
+import (
+	"testing"
+
+	"github.com/skwair/harmony/internal/endpoint"
+	"github.com/skwair/harmony/log"
+)
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		redactContent bool
+		opts          []PayloadLoggingOption
+		data          string
+		expected      string
+	}{
+		{
+			name:     "tokens",
+			data:     `{"d":{"token":"abc","session_id":"1"},"password":"p"}`,
+			expected: `{"d":{"session_id":"1","token":"[REDACTED]"},"password":"[REDACTED]"}`,
+		},
+		{
+			name:     "arrays",
+			data:     `[{"access_token":"abc"},{"refresh_token":"def"}]`,
+			expected: `[{"access_token":"[REDACTED]"},{"refresh_token":"[REDACTED]"}]`,
+		},
+		{
+			name:          "user content",
+			redactContent: true,
+			data:          `{"content":"hello","embeds":[{"title":"x"}],"id":"1"}`,
+			expected:      `{"content":"[REDACTED]","embeds":"[REDACTED]","id":"1"}`,
+		},
+		{
+			name:     "user content logged",
+			data:     `{"content":"hello","id":"1"}`,
+			expected: `{"content":"hello","id":"1"}`,
+		},
+		{
+			name:          "null values",
+			redactContent: true,
+			data:          `{"content":null,"token":null}`,
+			expected:      `{"content":null,"token":null}`,
+		},
+		{
+			name:     "custom fields",
+			opts:     []PayloadLoggingOption{PayloadLogRedactFields("nonce")},
+			data:     `{"nonce":"abc","id":"1"}`,
+			expected: `{"id":"1","nonce":"[REDACTED]"}`,
+		},
+		{
+			name:     "numbers",
+			data:     `{"id":123456789012345678901}`,
+			expected: `{"id":123456789012345678901}`,
+		},
+		{
+			name:     "invalid JSON",
+			data:     `{"token":`,
+			expected: `{"token":`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl := newPayloadLogging(log.LevelDebug, tt.redactContent)
+			for _, opt := range tt.opts {
+				opt(pl)
+			}
+
+			if b := pl.redactJSON([]byte(tt.data)); string(b) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, b)
+			}
+		})
+	}
+}
+
+func TestRedactHead(t *testing.T) {
+	c := &Client{token: "bot-token"}
+	e := &endpoint.Endpoint{Method: "POST", Path: "/webhooks/1/token?wait=true"}
+	head := "POST /api/v6/webhooks/1/token?wait=true HTTP/1.1\r\nAuthorization: Bot bot-token\r\n\r\n"
+
+	expected := "POST /api/v6/webhooks/1/[REDACTED]?wait=true HTTP/1.1\r\nAuthorization: Bot [REDACTED]\r\n\r\n"
+	if s := c.redactHead(e, []byte(head)); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+}
@@ -0,0 +1,185 @@
+package permission
+
+import "time"
+
+// Permissions a timed out member keeps, see Compute.
+const timedOutPermissions = ViewChannel | ReadMessageHistory
+
+// Role is a role of a guild, as needed to compute permissions.
+type Role struct {
+	ID          string
+	Position    int
+	Permissions uint64
+}
+
+// Guild is a guild, as needed to compute permissions.
+type Guild struct {
+	ID      string
+	OwnerID string
+	// Roles of the guild, including @everyone which has the ID of the guild.
+	Roles []Role
+}
+
+// Member is a member of a guild, as needed to compute permissions.
+type Member struct {
+	UserID string
+	// IDs of the roles of the member, without @everyone.
+	Roles []string
+	// Time until which the member is timed out, zero if it is not.
+	CommunicationDisabledUntil time.Time
+}
+
+// Channel is a channel of a guild, as needed to compute permissions.
+// Threads have the overwrites of their parent channel.
+type Channel struct {
+	Overwrites []Overwrite
+}
+
+// Compute returns the effective permissions of the given member in the given
+// guild and channel, or in the guild as a whole if channel is nil. It follows
+// https://discord.com/developers/docs/topics/permissions#permission-overwrites:
+//   - the owner of the guild and administrators have all permissions,
+//     regardless of channel overwrites and timeouts;
+//   - otherwise, permissions of the @everyone role and the roles of the
+//     member are combined, then the overwrites of the channel for @everyone,
+//     the roles of the member and the member itself are applied in this order;
+//   - members who can not view a channel have no permissions in it, and
+//     members who can not send messages can not mention everyone, send TTS
+//     messages, embed links nor attach files either;
+//   - timed out members can only view channels and read their history.
+func Compute(member *Member, guild *Guild, channel *Channel) uint64 {
+	if guild.isOwner(member) {
+		return All
+	}
+
+	perms := None
+	if everyone := guild.role(guild.ID); everyone != nil {
+		perms = everyone.Permissions
+	}
+	for _, id := range member.Roles {
+		if r := guild.role(id); r != nil {
+			perms |= r.Permissions
+		}
+	}
+	if Contains(perms, Administrator) {
+		return All
+	}
+
+	if channel != nil {
+		perms = channel.apply(perms, guild.ID, member)
+
+		if perms&ViewChannel == 0 {
+			return None
+		}
+		if perms&SendMessages == 0 {
+			perms &^= MentionEveryone | SendTTSMessages | EmbedLinks | AttachFiles
+		}
+	}
+
+	if member.CommunicationDisabledUntil.After(time.Now()) {
+		perms &= timedOutPermissions
+	}
+	return perms
+}
+
+// Has returns whether permissions, as returned by Compute, allow all the given
+// permissions. Unlike Contains, it considers that administrators have all
+// permissions, including the ones Harmony does not know of.
+func Has(permissions uint64, perms ...uint64) bool {
+	if Contains(permissions, Administrator) {
+		return true
+	}
+	for _, p := range perms {
+		if !Contains(permissions, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// Outranks returns whether the actor member is higher than the target member
+// in the role hierarchy of the given guild, which is required to kick, ban,
+// time out or manage the roles and nickname of the target. The owner of the
+// guild outranks everyone and is outranked by no one. Otherwise, the highest
+// role of the actor must be strictly higher than the highest role of the target.
+func Outranks(guild *Guild, actor, target *Member) bool {
+	if guild.isOwner(target) || actor.UserID == target.UserID {
+		return false
+	}
+	if guild.isOwner(actor) {
+		return true
+	}
+	return guild.highestPosition(actor) > guild.highestPosition(target)
+}
+
+// CanManageRole returns whether the given member is higher than the given role
+// in the role hierarchy of the guild, which is required to assign, remove or
+// edit it, in addition to the ManageRoles permission.
+func CanManageRole(guild *Guild, member *Member, role *Role) bool {
+	if guild.isOwner(member) {
+		return true
+	}
+	return guild.highestPosition(member) > role.Position
+}
+
+// isOwner returns whether the given member owns the guild.
+func (g *Guild) isOwner(m *Member) bool {
+	return m.UserID != "" && m.UserID == g.OwnerID
+}
+
+// role returns the role of the guild with the given ID, or nil if there is none.
+func (g *Guild) role(id string) *Role {
+	for i := range g.Roles {
+		if g.Roles[i].ID == id {
+			return &g.Roles[i]
+		}
+	}
+	return nil
+}
+
+// highestPosition returns the position of the highest role of the given member,
+// which is 0, the position of @everyone, if it has no role.
+func (g *Guild) highestPosition(m *Member) int {
+	highest := 0
+	for _, id := range m.Roles {
+		if r := g.role(id); r != nil && r.Position > highest {
+			highest = r.Position
+		}
+	}
+	return highest
+}
+
+// apply applies the overwrites of the channel for the given member to perms.
+func (ch *Channel) apply(perms uint64, guildID string, m *Member) uint64 {
+	if o := ch.overwrite(guildID); o != nil {
+		perms &^= o.Deny
+		perms |= o.Allow
+	}
+
+	allow, deny := None, None
+	for _, id := range m.Roles {
+		if o := ch.overwrite(id); o != nil {
+			allow |= o.Allow
+			deny |= o.Deny
+		}
+	}
+	perms &^= deny
+	perms |= allow
+
+	if o := ch.overwrite(m.UserID); o != nil {
+		perms &^= o.Deny
+		perms |= o.Allow
+	}
+	return perms
+}
+
+// overwrite returns the overwrite of the channel for the
+// role or member with the given ID, or nil if there is none.
+func (ch *Channel) overwrite(id string) *Overwrite {
+	for i := range ch.Overwrites {
+		if ch.Overwrites[i].ID == id {
+			return &ch.Overwrites[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package permission
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompute(t *testing.T) {
+	const (
+		guildID = "1"
+		ownerID = "10"
+		userID  = "11"
+
+		moderator = "2"
+		admin     = "3"
+		muted     = "4"
+	)
+
+	everyone := ViewChannel | SendMessages | EmbedLinks | ReadMessageHistory
+	guild := &Guild{
+		ID:      guildID,
+		OwnerID: ownerID,
+		Roles: []Role{
+			{ID: guildID, Position: 0, Permissions: everyone},
+			{ID: moderator, Position: 2, Permissions: KickMembers | ManageMessages},
+			{ID: admin, Position: 3, Permissions: Administrator},
+			{ID: muted, Position: 1, Permissions: None},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		member   *Member
+		channel  *Channel
+		expected uint64
+	}{
+		{
+			name:     "owner",
+			member:   &Member{UserID: ownerID},
+			channel:  &Channel{Overwrites: []Overwrite{{Type: "role", ID: guildID, Deny: ViewChannel}}},
+			expected: All,
+		},
+		{
+			name:     "administrator",
+			member:   &Member{UserID: userID, Roles: []string{admin}},
+			channel:  &Channel{Overwrites: []Overwrite{{Type: "member", ID: userID, Deny: ViewChannel}}},
+			expected: All,
+		},
+		{
+			name:     "everyone",
+			member:   &Member{UserID: userID},
+			expected: everyone,
+		},
+		{
+			name:     "roles",
+			member:   &Member{UserID: userID, Roles: []string{moderator, muted, "unknown"}},
+			expected: everyone | KickMembers | ManageMessages,
+		},
+		{
+			name:     "channel without overwrites",
+			member:   &Member{UserID: userID, Roles: []string{moderator}},
+			channel:  &Channel{},
+			expected: everyone | KickMembers | ManageMessages,
+		},
+		{
+			name:   "everyone overwrite",
+			member: &Member{UserID: userID},
+			channel: &Channel{Overwrites: []Overwrite{
+				{Type: "role", ID: guildID, Allow: AddReactions, Deny: EmbedLinks},
+			}},
+			expected: ViewChannel | SendMessages | ReadMessageHistory | AddReactions,
+		},
+		{
+			name:   "role overwrite over everyone overwrite",
+			member: &Member{UserID: userID, Roles: []string{moderator}},
+			channel: &Channel{Overwrites: []Overwrite{
+				{Type: "role", ID: guildID, Deny: EmbedLinks},
+				{Type: "role", ID: moderator, Allow: EmbedLinks},
+			}},
+			expected: everyone | KickMembers | ManageMessages,
+		},
+		{
+			name:   "role overwrites allow over deny",
+			member: &Member{UserID: userID, Roles: []string{moderator, muted}},
+			channel: &Channel{Overwrites: []Overwrite{
+				{Type: "role", ID: muted, Deny: EmbedLinks},
+				{Type: "role", ID: moderator, Allow: EmbedLinks},
+			}},
+			expected: everyone | KickMembers | ManageMessages,
+		},
+		{
+			name:   "member overwrite over role overwrite",
+			member: &Member{UserID: userID, Roles: []string{moderator}},
+			channel: &Channel{Overwrites: []Overwrite{
+				{Type: "role", ID: moderator, Allow: AttachFiles},
+				{Type: "member", ID: userID, Deny: AttachFiles | ManageMessages},
+			}},
+			expected: everyone | KickMembers,
+		},
+		{
+			name:   "can not view channel",
+			member: &Member{UserID: userID, Roles: []string{moderator}},
+			channel: &Channel{Overwrites: []Overwrite{
+				{Type: "role", ID: guildID, Deny: ViewChannel},
+			}},
+			expected: None,
+		},
+		{
+			name:   "can not send messages",
+			member: &Member{UserID: userID},
+			channel: &Channel{Overwrites: []Overwrite{
+				{Type: "role", ID: guildID, Allow: AttachFiles | MentionEveryone | AddReactions, Deny: SendMessages},
+			}},
+			expected: ViewChannel | ReadMessageHistory | AddReactions,
+		},
+		{
+			name:     "timed out",
+			member:   &Member{UserID: userID, Roles: []string{moderator}, CommunicationDisabledUntil: time.Now().Add(time.Hour)},
+			channel:  &Channel{},
+			expected: ViewChannel | ReadMessageHistory,
+		},
+		{
+			name:     "timeout expired",
+			member:   &Member{UserID: userID, Roles: []string{moderator}, CommunicationDisabledUntil: time.Now().Add(-time.Hour)},
+			expected: everyone | KickMembers | ManageMessages,
+		},
+		{
+			name:     "timed out administrator",
+			member:   &Member{UserID: userID, Roles: []string{admin}, CommunicationDisabledUntil: time.Now().Add(time.Hour)},
+			expected: All,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if perms := Compute(tt.member, guild, tt.channel); perms != tt.expected {
+				t.Errorf("expected permissions to be %#x, got %#x", tt.expected, perms)
+			}
+		})
+	}
+}
+
+func TestHas(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions uint64
+		perms       []uint64
+		expected    bool
+	}{
+		{name: "none required", permissions: None, expected: true},
+		{name: "all granted", permissions: ViewChannel | SendMessages, perms: []uint64{ViewChannel, SendMessages}, expected: true},
+		{name: "combined", permissions: ViewChannel | SendMessages, perms: []uint64{ViewChannel | SendMessages}, expected: true},
+		{name: "missing one", permissions: ViewChannel, perms: []uint64{ViewChannel, SendMessages}, expected: false},
+		{name: "administrator", permissions: Administrator, perms: []uint64{BanMembers, 1 << 63}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if has := Has(tt.permissions, tt.perms...); has != tt.expected {
+				t.Errorf("expected Has to return %t, got %t", tt.expected, has)
+			}
+		})
+	}
+}
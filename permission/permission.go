@@ -1,38 +1,76 @@
 package permission
 
-// Set of permissions that can be assigned to Users and Roles.
+import "encoding/json"
+
+// Set of permissions that can be assigned to Users and Roles. Permissions are
+// 64 bits wide and sent by Discord as strings, see Overwrite.
 const (
-	None               = 0x00000000 // Allows nothing.
-	CreateInvite       = 0x00000001 // Allows creation of instant invites.
-	KickMembers        = 0x00000002 // Allows kicking members.
-	BanMembers         = 0x00000004 // Allows banning members.
-	Administrator      = 0x00000008 // Allows *all permissions* and bypasses channel permission overwrites.
-	ManageChannels     = 0x00000010 // Allows management and editing of channels.
-	ManageGuild        = 0x00000020 // Allows management and editing of the guild.
-	AddReactions       = 0x00000040 // Allows for the addition of reactions to messages.
-	ViewAuditLog       = 0x00000080 // Allows for viewing of audit logs.
-	ViewChannel        = 0x00000400 // Allows guild members to view a channel, which includes reading messages in text channels.
-	SendMessages       = 0x00000800 // Allows for sending messages in a channel.
-	SendTTSMessages    = 0x00001000 // Allows for sending of /tts messages.
-	ManageMessages     = 0x00002000 // Allows for deletion of other users messages.
-	EmbedLinks         = 0x00004000 // Links sent by users with this permission will be auto-embedded.
-	AttachFiles        = 0x00008000 // Allows for uploading images and files.
-	ReadMessageHistory = 0x00010000 // Allows for reading of message history.
-	MentionEveryone    = 0x00020000 // Allows for using the @everyone tag to notify all users in a channel, and the @here tag to notify all online users in a channel.
-	UseExternalEmojis  = 0x00040000 // Allows the usage of custom emojis from other servers.
-	Connect            = 0x00100000 // Allows for joining of a voice channel.
-	Speak              = 0x00200000 // Allows for speaking in a voice channel.
-	MuteMembers        = 0x00400000 // Allows for muting members in a voice channel.
-	DeafenMembers      = 0x00800000 // Allows for deafening of members in a voice channel.
-	MoveMembers        = 0x01000000 // Allows for moving of members between voice channels.
-	UseVAD             = 0x02000000 // Allows for using voice-activity-detection in a voice channel.
-	PRIORITY_SPEAKER   = 0x00000100 // Allows for using priority speaker in a voice channel.
-	ChangeNickname     = 0x04000000 // Allows for modification of own nickname.
-	ManageNicknames    = 0x08000000 // Allows for modification of other users nicknames.
-	ManageRoles        = 0x10000000 // Allows management and editing of roles.
-	ManageWebhooks     = 0x20000000 // Allows management and editing of webhooks.
-	ManageEmojis       = 0x40000000 // Allows management and editing of emojis.
-	All                = 0x7FF7FCFF // Equivalent to all permissions, OR'd.
+	None                             uint64 = 0       // Allows nothing.
+	CreateInvite                     uint64 = 1 << 0  // Allows creation of instant invites.
+	KickMembers                      uint64 = 1 << 1  // Allows kicking members.
+	BanMembers                       uint64 = 1 << 2  // Allows banning members.
+	Administrator                    uint64 = 1 << 3  // Allows *all permissions* and bypasses channel permission overwrites.
+	ManageChannels                   uint64 = 1 << 4  // Allows management and editing of channels.
+	ManageGuild                      uint64 = 1 << 5  // Allows management and editing of the guild.
+	AddReactions                     uint64 = 1 << 6  // Allows for the addition of reactions to messages.
+	ViewAuditLog                     uint64 = 1 << 7  // Allows for viewing of audit logs.
+	PrioritySpeaker                  uint64 = 1 << 8  // Allows for using priority speaker in a voice channel.
+	Stream                           uint64 = 1 << 9  // Allows the user to go live.
+	ViewChannel                      uint64 = 1 << 10 // Allows guild members to view a channel, which includes reading messages in text channels.
+	SendMessages                     uint64 = 1 << 11 // Allows for sending messages in a channel.
+	SendTTSMessages                  uint64 = 1 << 12 // Allows for sending of /tts messages.
+	ManageMessages                   uint64 = 1 << 13 // Allows for deletion of other users messages.
+	EmbedLinks                       uint64 = 1 << 14 // Links sent by users with this permission will be auto-embedded.
+	AttachFiles                      uint64 = 1 << 15 // Allows for uploading images and files.
+	ReadMessageHistory               uint64 = 1 << 16 // Allows for reading of message history.
+	MentionEveryone                  uint64 = 1 << 17 // Allows for using the @everyone tag to notify all users in a channel, and the @here tag to notify all online users in a channel.
+	UseExternalEmojis                uint64 = 1 << 18 // Allows the usage of custom emojis from other servers.
+	ViewGuildInsights                uint64 = 1 << 19 // Allows for viewing guild insights.
+	Connect                          uint64 = 1 << 20 // Allows for joining of a voice channel.
+	Speak                            uint64 = 1 << 21 // Allows for speaking in a voice channel.
+	MuteMembers                      uint64 = 1 << 22 // Allows for muting members in a voice channel.
+	DeafenMembers                    uint64 = 1 << 23 // Allows for deafening of members in a voice channel.
+	MoveMembers                      uint64 = 1 << 24 // Allows for moving of members between voice channels.
+	UseVAD                           uint64 = 1 << 25 // Allows for using voice-activity-detection in a voice channel.
+	ChangeNickname                   uint64 = 1 << 26 // Allows for modification of own nickname.
+	ManageNicknames                  uint64 = 1 << 27 // Allows for modification of other users nicknames.
+	ManageRoles                      uint64 = 1 << 28 // Allows management and editing of roles.
+	ManageWebhooks                   uint64 = 1 << 29 // Allows management and editing of webhooks.
+	ManageEmojis                     uint64 = 1 << 30 // Allows management and editing of emojis and stickers.
+	UseApplicationCommands           uint64 = 1 << 31 // Allows members to use application commands.
+	RequestToSpeak                   uint64 = 1 << 32 // Allows for requesting to speak in stage channels.
+	ManageEvents                     uint64 = 1 << 33 // Allows for editing and deleting scheduled events.
+	ManageThreads                    uint64 = 1 << 34 // Allows for deleting and archiving threads, and viewing all private threads.
+	CreatePublicThreads              uint64 = 1 << 35 // Allows for creating public and announcement threads.
+	CreatePrivateThreads             uint64 = 1 << 36 // Allows for creating private threads.
+	UseExternalStickers              uint64 = 1 << 37 // Allows the usage of custom stickers from other servers.
+	SendMessagesInThreads            uint64 = 1 << 38 // Allows for sending messages in threads.
+	UseEmbeddedActivities            uint64 = 1 << 39 // Allows for using activities in a voice channel.
+	ModerateMembers                  uint64 = 1 << 40 // Allows for timing out users.
+	ViewCreatorMonetizationAnalytics uint64 = 1 << 41 // Allows for viewing role subscription insights.
+	UseSoundboard                    uint64 = 1 << 42 // Allows for using the soundboard in a voice channel.
+	CreateGuildExpressions           uint64 = 1 << 43 // Allows for creating emojis, stickers and soundboard sounds.
+	CreateEvents                     uint64 = 1 << 44 // Allows for creating scheduled events.
+	UseExternalSounds                uint64 = 1 << 45 // Allows the usage of custom soundboard sounds from other servers.
+	SendVoiceMessages                uint64 = 1 << 46 // Allows sending voice messages.
+	SendPolls                        uint64 = 1 << 49 // Allows sending polls.
+	UseExternalApps                  uint64 = 1 << 50 // Allows user-installed apps to send public responses.
+
+	// Deprecated: use PrioritySpeaker.
+	PRIORITY_SPEAKER = PrioritySpeaker
+
+	// All is equivalent to all permissions above, OR'd.
+	All = CreateInvite | KickMembers | BanMembers | Administrator | ManageChannels |
+		ManageGuild | AddReactions | ViewAuditLog | PrioritySpeaker | Stream | ViewChannel |
+		SendMessages | SendTTSMessages | ManageMessages | EmbedLinks | AttachFiles |
+		ReadMessageHistory | MentionEveryone | UseExternalEmojis | ViewGuildInsights |
+		Connect | Speak | MuteMembers | DeafenMembers | MoveMembers | UseVAD |
+		ChangeNickname | ManageNicknames | ManageRoles | ManageWebhooks | ManageEmojis |
+		UseApplicationCommands | RequestToSpeak | ManageEvents | ManageThreads |
+		CreatePublicThreads | CreatePrivateThreads | UseExternalStickers |
+		SendMessagesInThreads | UseEmbeddedActivities | ModerateMembers |
+		ViewCreatorMonetizationAnalytics | UseSoundboard | CreateGuildExpressions |
+		CreateEvents | UseExternalSounds | SendVoiceMessages | SendPolls | UseExternalApps
 )
 
 // Overwrite describes a specific permission that overwrites
@@ -40,8 +78,41 @@ const (
 type Overwrite struct {
 	Type  string `json:"type"` // Either "role" or "member".
 	ID    string `json:"id"`   // ID of the role or member, depending on Type.
-	Allow int    `json:"allow"`
-	Deny  int    `json:"deny"`
+	Allow uint64 `json:"allow,string"`
+	Deny  uint64 `json:"deny,string"`
+}
+
+// rawOverwrite is an Overwrite as sent by Discord,
+// whose type is 0 for roles and 1 for members.
+type rawOverwrite struct {
+	Type  int    `json:"type"`
+	ID    string `json:"id"`
+	Allow uint64 `json:"allow,string"`
+	Deny  uint64 `json:"deny,string"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (o Overwrite) MarshalJSON() ([]byte, error) {
+	raw := rawOverwrite{ID: o.ID, Allow: o.Allow, Deny: o.Deny}
+	if o.Type == "member" {
+		raw.Type = 1
+	}
+	return json.Marshal(&raw)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (o *Overwrite) UnmarshalJSON(b []byte) error {
+	var raw rawOverwrite
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	o.Type = "role"
+	if raw.Type == 1 {
+		o.Type = "member"
+	}
+	o.ID, o.Allow, o.Deny = raw.ID, raw.Allow, raw.Deny
+	return nil
 }
 
 // Clone returns a clone of this Overwrite.
@@ -59,6 +130,6 @@ func (o *Overwrite) Clone() *Overwrite {
 }
 
 // Contains returns whether the given permission is set in permissions.
-func Contains(permissions, permission int) bool {
+func Contains(permissions, permission uint64) bool {
 	return permissions&permission == permission
 }
@@ -0,0 +1,51 @@
+package permission
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestOverwriteJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		overwrite Overwrite
+		json      string
+	}{
+		{
+			name:      "role",
+			overwrite: Overwrite{Type: "role", ID: "1", Allow: ViewChannel | SendMessages, Deny: ManageMessages},
+			json:      `{"type":0,"id":"1","allow":"3072","deny":"8192"}`,
+		},
+		{
+			name:      "member",
+			overwrite: Overwrite{Type: "member", ID: "2", Allow: None, Deny: ViewChannel},
+			json:      `{"type":1,"id":"2","allow":"0","deny":"1024"}`,
+		},
+		{
+			name:      "permissions over 32 bits",
+			overwrite: Overwrite{Type: "role", ID: "3", Allow: UseExternalApps | ModerateMembers},
+			json:      `{"type":0,"id":"3","allow":"1126999418470400","deny":"0"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.overwrite)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.json {
+				t.Errorf("expected %s, got %s", tt.json, b)
+			}
+
+			var o Overwrite
+			if err = json.Unmarshal([]byte(tt.json), &o); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(o, tt.overwrite) {
+				t.Errorf("expected %+v, got %+v", tt.overwrite, o)
+			}
+		})
+	}
+}
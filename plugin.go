@@ -0,0 +1,92 @@
+package harmony
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Plugin is a reusable module that hooks into the lifecycle of a Client,
+// for instance to provide music playback or moderation features.
+// Register plugins with WithPlugins.
+//
+// Plugins must not register event handlers with OnXxx methods: a single
+// handler can be registered per event, so the handlers of a plugin would
+// replace the ones of the user, or be replaced by them. Plugins receive
+// events through Client.Subscribe instead, usually called in Start.
+type Plugin interface {
+	// Init is called once when the client is created, after all options
+	// have been applied. It is the place to keep a reference to the client
+	// and to check its configuration.
+	Init(c *Client) error
+	// Start is called once the client is connected to the Gateway, the
+	// first time Connect succeeds. Automatic reconnections do not call it
+	// again. If it returns an error, the client is disconnected.
+	Start(ctx context.Context) error
+	// Stop is called when Disconnect is called, before the connection to
+	// the Gateway is closed.
+	Stop(ctx context.Context) error
+}
+
+// WithPlugins registers plugins on the client. Plugins are initialized in
+// the order they are given and stopped in the reverse order.
+func WithPlugins(plugins ...Plugin) ClientOption {
+	return func(c *Client) {
+		c.plugins = append(c.plugins, plugins...)
+	}
+}
+
+// initPlugins initializes all plugins of the client.
+func (c *Client) initPlugins() error {
+	for _, p := range c.plugins {
+		if err := p.Init(c); err != nil {
+			return fmt.Errorf("could not initialize plugin %T: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// startPlugins starts all plugins of the client if they are not already started.
+// If a plugin fails to start, plugins that were already started are stopped.
+func (c *Client) startPlugins(ctx context.Context) error {
+	c.pluginsMu.Lock()
+	defer c.pluginsMu.Unlock()
+
+	if c.pluginsStarted {
+		return nil
+	}
+
+	for i, p := range c.plugins {
+		if err := p.Start(ctx); err != nil {
+			stopPlugins(ctx, c, c.plugins[:i])
+			return fmt.Errorf("could not start plugin %T: %w", p, err)
+		}
+	}
+	c.pluginsStarted = true
+	return nil
+}
+
+// stopPlugins stops all plugins of the client if they are started.
+func (c *Client) stopPlugins() {
+	c.pluginsMu.Lock()
+	defer c.pluginsMu.Unlock()
+
+	if !c.pluginsStarted {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stopPlugins(ctx, c, c.plugins)
+	c.pluginsStarted = false
+}
+
+// stopPlugins stops the given plugins in reverse order, logging errors.
+func stopPlugins(ctx context.Context, c *Client, plugins []Plugin) {
+	for i := len(plugins) - 1; i >= 0; i-- {
+		if err := plugins[i].Stop(ctx); err != nil {
+			c.logger.Errorf("could not stop plugin %T: %v", plugins[i], err)
+		}
+	}
+}
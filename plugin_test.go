@@ -0,0 +1,75 @@
+package harmony_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/harmonytest"
+)
+
+// typingPlugin is a plugin forwarding the TYPING_START events it receives.
+type typingPlugin struct {
+	client *harmony.Client
+	sub    *harmony.Subscription
+	typing chan *harmony.TypingStart
+}
+
+func (p *typingPlugin) Init(c *harmony.Client) error {
+	p.client = c
+	return nil
+}
+
+func (p *typingPlugin) Start(context.Context) error {
+	p.sub = p.client.Subscribe(func(e *harmony.Event) {
+		if ts, ok := e.Data.(*harmony.TypingStart); ok {
+			p.typing <- ts
+		}
+	})
+	return nil
+}
+
+func (p *typingPlugin) Stop(ctx context.Context) error {
+	return p.sub.Close(ctx)
+}
+
+func TestPluginEventsWithHandlers(t *testing.T) {
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	p := &typingPlugin{typing: make(chan *harmony.TypingStart, 1)}
+	c, err := srv.NewClient(harmony.WithPlugins(p))
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	// The handler of the user does not prevent the plugin from receiving the event.
+	typing := make(chan *harmony.TypingStart, 1)
+	c.OnTypingStart(func(ts *harmony.TypingStart) {
+		typing <- ts
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = c.Connect(ctx); err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer c.Disconnect()
+
+	if err = srv.Dispatch("TYPING_START", &harmony.TypingStart{ChannelID: "1", UserID: "2"}); err != nil {
+		t.Fatalf("could not dispatch event: %v", err)
+	}
+
+	for name, ch := range map[string]chan *harmony.TypingStart{"handler": typing, "plugin": p.typing} {
+		select {
+		case ts := <-ch:
+			if ts.ChannelID != "1" {
+				t.Errorf("expected the %s to receive an event in channel 1, got %s", name, ts.ChannelID)
+			}
+		case <-ctx.Done():
+			t.Fatalf("expected the %s to receive the event", name)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package harmony
+
+import "time"
+
+// Statuses of users, see Status.
+const (
+	StatusOnline    = "online"
+	StatusDND       = "dnd"
+	StatusIdle      = "idle"
+	StatusInvisible = "invisible"
+	StatusOffline   = "offline"
+)
+
+// customStatusName is the name of custom status activities,
+// which is required but not shown.
+const customStatusName = "Custom Status"
+
+// Playing returns an activity displayed as "Playing {name}".
+func Playing(name string) *Activity {
+	return &Activity{Name: name, Type: ActivityPlaying}
+}
+
+// Streaming returns an activity displayed as "Streaming {name}", linking to the
+// given Twitch or YouTube URL.
+func Streaming(name, url string) *Activity {
+	return &Activity{Name: name, Type: ActivityStreaming, URL: url}
+}
+
+// ListeningTo returns an activity displayed as "Listening to {name}".
+func ListeningTo(name string) *Activity {
+	return &Activity{Name: name, Type: ActivityListening}
+}
+
+// Watching returns an activity displayed as "Watching {name}".
+func Watching(name string) *Activity {
+	return &Activity{Name: name, Type: ActivityWatching}
+}
+
+// CompetingIn returns an activity displayed as "Competing in {name}".
+func CompetingIn(name string) *Activity {
+	return &Activity{Name: name, Type: ActivityCompeting}
+}
+
+// CustomStatus returns a custom status activity, displaying the given text
+// as is. Bots can not set the emoji of their custom status.
+func CustomStatus(text string) *Activity {
+	return &Activity{Name: customStatusName, Type: ActivityCustom, State: text}
+}
+
+// PresenceOption is a function that configures a Status.
+type PresenceOption func(*Status)
+
+// WithStatus sets the status of the current user, either StatusOnline,
+// StatusDND, StatusIdle, StatusInvisible or StatusOffline.
+// Defaults to StatusOnline.
+func WithStatus(status string) PresenceOption {
+	return func(s *Status) {
+		s.Status = status
+	}
+}
+
+// WithActivities sets the activities of the current user. Clients only show
+// the first one.
+func WithActivities(activities ...*Activity) PresenceOption {
+	return func(s *Status) {
+		s.Activities = s.Activities[:0]
+		for _, a := range activities {
+			s.Activities = append(s.Activities, *a)
+		}
+	}
+}
+
+// WithAFK marks the current user as AFK since the given time, so users are
+// notified on their other clients.
+func WithAFK(since time.Time) PresenceOption {
+	return func(s *Status) {
+		s.AFK = true
+		s.Since = since.UnixNano() / int64(time.Millisecond)
+	}
+}
+
+// NewStatus returns a new Status, to be used with CurrentUserResource.SetStatus
+// or WithInitialPresence:
+//
+//	status := harmony.NewStatus(
+//		harmony.WithStatus(harmony.StatusIdle),
+//		harmony.WithActivities(harmony.Watching("the logs")),
+//	)
+func NewStatus(opts ...PresenceOption) *Status {
+	s := &Status{
+		Status:     StatusOnline,
+		Activities: []Activity{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// UpdatePresence is a shorthand for SetStatus(NewStatus(opts...)).
+func (r *CurrentUserResource) UpdatePresence(opts ...PresenceOption) error {
+	return r.SetStatus(NewStatus(opts...))
+}
@@ -0,0 +1,109 @@
+/*
+Package ratelimit defines how the state of the REST rate limiter of a Client
+is persisted across restarts, see harmony.WithRateLimitStore.
+
+Without persistence, a bot that restarts in a loop forgets which rate limit
+buckets it exhausted and sends requests that are rejected with 429s, which
+can get its IP address temporarily banned by Cloudflare. FileStore saves the
+state of the rate limiter to a file:
+
+	store := ratelimit.NewFileStore("/var/lib/bot/ratelimits.json")
+
+	client, err := harmony.NewClient(token, harmony.WithRateLimitStore(store))
+
+Other storages, such as a database, can be used by implementing Store.
+*/
+package ratelimit
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Store persists the state of a rate limiter. Each client must have its own
+// store, since the state of a rate limiter is specific to its token.
+type Store interface {
+	// Load returns the last saved state, or nil if there is none.
+	Load() (*Snapshot, error)
+	// Save saves the given state, replacing the previous one.
+	Save(s *Snapshot) error
+}
+
+// Snapshot is the state of a rate limiter at a given time. It only holds the
+// buckets that are not refilled yet, since others do not limit requests.
+type Snapshot struct {
+	// Buckets by ID.
+	Buckets map[string]Bucket `json:"buckets"`
+	// Bucket IDs by route. Routes are an HTTP method and an endpoint key,
+	// separated by a space.
+	Routes map[string]string `json:"routes"`
+	// GlobalUntil is the time until which all requests are rate limited.
+	GlobalUntil time.Time `json:"global_until"`
+}
+
+// Bucket is the rate limit of a bucket of routes.
+type Bucket struct {
+	// Limit is the maximum number of requests in the bucket.
+	Limit int `json:"limit"`
+	// Remaining is the number of requests left before Reset.
+	Remaining int `json:"remaining"`
+	// Reset is the time at which the bucket refills to its limit.
+	Reset time.Time `json:"reset"`
+}
+
+// FileStore is a Store saving the state of a rate limiter to a JSON file.
+type FileStore struct {
+	path string
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore returns a store saving the state of a rate
+// limiter to the file at the given path.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load implements Store. It returns nil if the file does not exist.
+func (s *FileStore) Load() (*Snapshot, error) {
+	b, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snap Snapshot
+	if err = json.Unmarshal(b, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// Save implements Store. The file is replaced atomically,
+// so it is never left half written if the program crashes.
+func (s *FileStore) Save(snap *Snapshot) error {
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(b); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err = f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
@@ -0,0 +1,53 @@
+package harmony
+
+import "time"
+
+// ReconnectPolicy configures how a client reconnects to the Gateway after an
+// error, such as a network failure. See WithReconnectPolicy.
+type ReconnectPolicy struct {
+	// BaseDelay, MaxDelay, Factor and Jitter configure the backoff between
+	// attempts, see WithBackoffStrategy. Zero values keep the current ones.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	Factor    float64
+	Jitter    float64
+	// MaxAttempts is the maximum number of reconnection attempts after an error.
+	// Once exhausted, the client stops trying to reconnect and gives up with an
+	// error matching ErrReconnectAborted, see OnGiveUp. Zero means no limit.
+	MaxAttempts int
+	// OnAttempt, if set, is called before each reconnection attempt, starting at 1,
+	// with the error that caused the disconnection or the one of the previous attempt.
+	// The client stops trying to reconnect if it returns false. It must not block.
+	OnAttempt func(attempt int, err error) bool
+	// OnGiveUp, if set, is called once the client gave up reconnecting, either
+	// with an error matching ErrReconnectAborted or with a fatal error returned
+	// by a reconnection attempt. Before it is called, the client is cleaned up
+	// as if Disconnect was called: plugins are stopped, channels returned by
+	// Events are closed and voice connections are closed. The error is reported
+	// to the ErrorReporter as well, see WithErrorReporter.
+	OnGiveUp func(err error)
+}
+
+// WithReconnectPolicy sets the policy used by the client to reconnect to the
+// Gateway after an error. Fatal errors, such as an invalid token, are never
+// retried, see GatewayCloseError.
+// Defaults to retrying forever with the default backoff strategy.
+func WithReconnectPolicy(p ReconnectPolicy) ClientOption {
+	return func(c *Client) {
+		if p.BaseDelay > 0 {
+			c.backoff.baseDelay = p.BaseDelay
+		}
+		if p.MaxDelay > 0 {
+			c.backoff.maxDelay = p.MaxDelay
+		}
+		if p.Factor > 0 {
+			c.backoff.factor = p.Factor
+		}
+		if p.Jitter > 0 {
+			c.backoff.jitter = p.Jitter
+		}
+		c.maxReconnectAttempts = p.MaxAttempts
+		c.onReconnectAttempt = p.OnAttempt
+		c.onReconnectGiveUp = p.OnGiveUp
+	}
+}
@@ -0,0 +1,52 @@
+package harmony_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/harmonytest"
+)
+
+func TestReconnectPolicyGiveUp(t *testing.T) {
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	gaveUp := make(chan error, 1)
+	c, err := srv.NewClient(harmony.WithReconnectPolicy(harmony.ReconnectPolicy{
+		OnAttempt: func(int, error) bool { return false },
+		OnGiveUp:  func(err error) { gaveUp <- err },
+	}))
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	events, _ := c.Events(100)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = c.Connect(ctx); err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer c.Disconnect()
+
+	// Closing the server drops the connection to the Gateway.
+	srv.Close()
+
+	select {
+	case err = <-gaveUp:
+		if !errors.Is(err, harmony.ErrReconnectAborted) {
+			t.Errorf("expected error to match ErrReconnectAborted, got %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("expected OnGiveUp to be called")
+	}
+
+	for range events {
+		// Drain events received while connected, the
+		// channel is closed before OnGiveUp is called.
+	}
+}
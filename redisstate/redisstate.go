@@ -0,0 +1,573 @@
+/*
+Package redisstate provides a harmony.StateBackend storing the objects cached by
+the State in Redis, so they can be shared by several processes, for instance when
+each one handles some shards:
+
+	b := redisstate.New(client)
+	c, err := harmony.NewClient(token, harmony.WithStateBackend(b))
+
+Objects are stored as JSON in Redis hashes, one per kind of object, except for
+members and presences of guilds which have one hash per guild. To avoid
+depending on a particular Redis library, the backend uses a Client, which is
+easily implemented on top of any of them. For instance, with go-redis:
+
+	type client struct{ *redis.Client }
+
+	func (c client) HGet(ctx context.Context, key, field string) ([]byte, error) {
+		b, err := c.Client.HGet(ctx, key, field).Bytes()
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return b, err
+	}
+
+	func (c client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+		return c.Client.HGetAll(ctx, key).Result()
+	}
+
+	func (c client) HSet(ctx context.Context, key, field string, value []byte) error {
+		return c.Client.HSet(ctx, key, field, value).Err()
+	}
+
+	func (c client) HDel(ctx context.Context, key, field string) error {
+		return c.Client.HDel(ctx, key, field).Err()
+	}
+
+	func (c client) Del(ctx context.Context, key string) error {
+		return c.Client.Del(ctx, key).Err()
+	}
+
+Errors of Redis commands are reported to the error reporter of the client using
+the backend, see harmony.FallibleStateBackend, and can also be handled with
+WithErrorHandler.
+
+Updates made by a State are not atomic across processes: a State should only
+handle events of guilds that are not handled by other processes.
+*/
+package redisstate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/skwair/harmony"
+)
+
+// DefaultKeyPrefix is the default prefix of the keys of the hashes used by the backend.
+const DefaultKeyPrefix = "harmony:state:"
+
+// requestTimeout is the time allowed for a single Redis command.
+const requestTimeout = 5 * time.Second
+
+// Keys of the hashes storing each kind of object, after the prefix.
+const (
+	keyCurrentUser       = "current_user"
+	keyUsers             = "users"
+	keyGuilds            = "guilds"
+	keyUnavailableGuilds = "unavailable_guilds"
+	keyChannels          = "channels"
+	keyDMs               = "dms"
+	keyGroupDMs          = "group_dms"
+	keyPresences         = "presences"
+	keyWebhooks          = "webhooks"
+	keyIntegrations      = "integrations"
+
+	// Members and presences of guilds are stored in one
+	// hash per guild, whose key is followed by its ID.
+	keyMembers        = "members:"
+	keyGuildPresences = "guild_presences:"
+)
+
+// Client is the subset of the commands of a Redis client used by the backend.
+// It must be safe for concurrent use.
+type Client interface {
+	// HGet returns the value of a field in a hash, or nil if there is no such field.
+	HGet(ctx context.Context, key, field string) ([]byte, error)
+	// HGetAll returns all the fields of a hash and their values.
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	HSet(ctx context.Context, key, field string, value []byte) error
+	HDel(ctx context.Context, key, field string) error
+	// Del deletes a whole hash.
+	Del(ctx context.Context, key string) error
+}
+
+// Backend is a harmony.StateBackend storing objects in Redis.
+// Create one with New.
+type Backend struct {
+	client  Client
+	prefix  string
+	onError func(error)
+
+	// Set by the client using the backend, see SetErrorHandler.
+	clientOnError atomic.Value // func(error)
+}
+
+var _ harmony.FallibleStateBackend = (*Backend)(nil)
+
+// Option is a function that configures a Backend.
+type Option func(*Backend)
+
+// WithKeyPrefix sets the prefix of the keys of the hashes used by the backend,
+// for instance to store the states of several applications in the same database.
+// Defaults to DefaultKeyPrefix.
+func WithKeyPrefix(prefix string) Option {
+	return func(b *Backend) {
+		b.prefix = prefix
+	}
+}
+
+// WithErrorHandler sets the function called when a Redis command fails or an
+// object can not be encoded or decoded. Objects that can not be read are treated
+// as missing from the state. Errors are also passed to the client using the
+// backend, see SetErrorHandler.
+func WithErrorHandler(f func(err error)) Option {
+	return func(b *Backend) {
+		b.onError = f
+	}
+}
+
+// New returns a new Backend storing objects through the given client.
+func New(c Client, opts ...Option) *Backend {
+	b := &Backend{
+		client:  c,
+		prefix:  DefaultKeyPrefix,
+		onError: func(error) {},
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// SetErrorHandler implements harmony.FallibleStateBackend. It is called by the
+// client using the backend, use WithErrorHandler to handle errors yourself.
+func (b *Backend) SetErrorHandler(f func(err error)) {
+	b.clientOnError.Store(f)
+}
+
+// error passes err to the error handlers of the backend.
+func (b *Backend) error(err error) {
+	b.onError(err)
+	if f, ok := b.clientOnError.Load().(func(error)); ok && f != nil {
+		f(err)
+	}
+}
+
+// get decodes the given field of the given hash into v and
+// reports whether it was found and decoded successfully.
+func (b *Backend) get(key, field string, v interface{}) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	data, err := b.client.HGet(ctx, b.prefix+key, field)
+	if err != nil {
+		b.error(fmt.Errorf("redisstate: could not get %s %s: %w", key, field, err))
+		return false
+	}
+	if data == nil {
+		return false
+	}
+
+	if err = json.Unmarshal(data, v); err != nil {
+		b.error(fmt.Errorf("redisstate: could not decode %s %s: %w", key, field, err))
+		return false
+	}
+	return true
+}
+
+// getAll calls decode with every field of the given hash and its value.
+func (b *Backend) getAll(key string, decode func(field string, data []byte) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	all, err := b.client.HGetAll(ctx, b.prefix+key)
+	if err != nil {
+		b.error(fmt.Errorf("redisstate: could not get %s: %w", key, err))
+		return
+	}
+
+	for field, data := range all {
+		if err = decode(field, []byte(data)); err != nil {
+			b.error(fmt.Errorf("redisstate: could not decode %s %s: %w", key, field, err))
+		}
+	}
+}
+
+func (b *Backend) set(key, field string, v interface{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		b.error(fmt.Errorf("redisstate: could not encode %s %s: %w", key, field, err))
+		return
+	}
+
+	if err = b.client.HSet(ctx, b.prefix+key, field, data); err != nil {
+		b.error(fmt.Errorf("redisstate: could not set %s %s: %w", key, field, err))
+	}
+}
+
+func (b *Backend) deleteAll(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if err := b.client.Del(ctx, b.prefix+key); err != nil {
+		b.error(fmt.Errorf("redisstate: could not delete %s: %w", key, err))
+	}
+}
+
+func (b *Backend) delete(key, field string) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if err := b.client.HDel(ctx, b.prefix+key, field); err != nil {
+		b.error(fmt.Errorf("redisstate: could not delete %s %s: %w", key, field, err))
+	}
+}
+
+// CurrentUser implements harmony.StateBackend.
+func (b *Backend) CurrentUser() *harmony.User {
+	var u harmony.User
+	if !b.get(keyCurrentUser, keyCurrentUser, &u) {
+		return nil
+	}
+	return &u
+}
+
+// SetCurrentUser implements harmony.StateBackend.
+func (b *Backend) SetCurrentUser(u *harmony.User) {
+	b.set(keyCurrentUser, keyCurrentUser, u)
+}
+
+// User implements harmony.StateBackend.
+func (b *Backend) User(id string) *harmony.User {
+	var u harmony.User
+	if !b.get(keyUsers, id, &u) {
+		return nil
+	}
+	return &u
+}
+
+// Users implements harmony.StateBackend.
+func (b *Backend) Users() map[string]*harmony.User {
+	users := make(map[string]*harmony.User)
+	b.getAll(keyUsers, func(id string, data []byte) error {
+		var u harmony.User
+		if err := json.Unmarshal(data, &u); err != nil {
+			return err
+		}
+		users[id] = &u
+		return nil
+	})
+	return users
+}
+
+// SetUser implements harmony.StateBackend.
+func (b *Backend) SetUser(u *harmony.User) {
+	b.set(keyUsers, u.ID, u)
+}
+
+// DeleteUser implements harmony.StateBackend.
+func (b *Backend) DeleteUser(id string) {
+	b.delete(keyUsers, id)
+}
+
+// Guild implements harmony.StateBackend.
+func (b *Backend) Guild(id string) *harmony.Guild {
+	var g harmony.Guild
+	if !b.get(keyGuilds, id, &g) {
+		return nil
+	}
+	return &g
+}
+
+// Guilds implements harmony.StateBackend.
+func (b *Backend) Guilds() map[string]*harmony.Guild {
+	guilds := make(map[string]*harmony.Guild)
+	b.getAll(keyGuilds, func(id string, data []byte) error {
+		var g harmony.Guild
+		if err := json.Unmarshal(data, &g); err != nil {
+			return err
+		}
+		guilds[id] = &g
+		return nil
+	})
+	return guilds
+}
+
+// SetGuild implements harmony.StateBackend.
+func (b *Backend) SetGuild(g *harmony.Guild) {
+	b.set(keyGuilds, g.ID, g)
+}
+
+// DeleteGuild implements harmony.StateBackend.
+func (b *Backend) DeleteGuild(id string) {
+	b.delete(keyGuilds, id)
+}
+
+// Member implements harmony.StateBackend.
+func (b *Backend) Member(guildID, userID string) *harmony.GuildMember {
+	var m harmony.GuildMember
+	if !b.get(keyMembers+guildID, userID, &m) {
+		return nil
+	}
+	return &m
+}
+
+// Members implements harmony.StateBackend.
+func (b *Backend) Members(guildID string) map[string]*harmony.GuildMember {
+	members := make(map[string]*harmony.GuildMember)
+	b.getAll(keyMembers+guildID, func(userID string, data []byte) error {
+		var m harmony.GuildMember
+		if err := json.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		members[userID] = &m
+		return nil
+	})
+	return members
+}
+
+// SetMember implements harmony.StateBackend.
+func (b *Backend) SetMember(guildID, userID string, m *harmony.GuildMember) {
+	b.set(keyMembers+guildID, userID, m)
+}
+
+// DeleteMember implements harmony.StateBackend.
+func (b *Backend) DeleteMember(guildID, userID string) {
+	b.delete(keyMembers+guildID, userID)
+}
+
+// DeleteMembers implements harmony.StateBackend.
+func (b *Backend) DeleteMembers(guildID string) {
+	b.deleteAll(keyMembers + guildID)
+}
+
+// UnavailableGuild implements harmony.StateBackend.
+func (b *Backend) UnavailableGuild(id string) *harmony.UnavailableGuild {
+	var g harmony.UnavailableGuild
+	if !b.get(keyUnavailableGuilds, id, &g) {
+		return nil
+	}
+	return &g
+}
+
+// UnavailableGuilds implements harmony.StateBackend.
+func (b *Backend) UnavailableGuilds() map[string]*harmony.UnavailableGuild {
+	guilds := make(map[string]*harmony.UnavailableGuild)
+	b.getAll(keyUnavailableGuilds, func(id string, data []byte) error {
+		var g harmony.UnavailableGuild
+		if err := json.Unmarshal(data, &g); err != nil {
+			return err
+		}
+		guilds[id] = &g
+		return nil
+	})
+	return guilds
+}
+
+// SetUnavailableGuild implements harmony.StateBackend.
+func (b *Backend) SetUnavailableGuild(g *harmony.UnavailableGuild) {
+	b.set(keyUnavailableGuilds, g.ID, g)
+}
+
+// DeleteUnavailableGuild implements harmony.StateBackend.
+func (b *Backend) DeleteUnavailableGuild(id string) {
+	b.delete(keyUnavailableGuilds, id)
+}
+
+// Channel implements harmony.StateBackend.
+func (b *Backend) Channel(id string) *harmony.Channel {
+	return b.channel(keyChannels, id)
+}
+
+// Channels implements harmony.StateBackend.
+func (b *Backend) Channels() map[string]*harmony.Channel {
+	return b.channels(keyChannels)
+}
+
+// SetChannel implements harmony.StateBackend.
+func (b *Backend) SetChannel(ch *harmony.Channel) {
+	b.set(keyChannels, ch.ID, ch)
+}
+
+// DeleteChannel implements harmony.StateBackend.
+func (b *Backend) DeleteChannel(id string) {
+	b.delete(keyChannels, id)
+}
+
+// DM implements harmony.StateBackend.
+func (b *Backend) DM(id string) *harmony.Channel {
+	return b.channel(keyDMs, id)
+}
+
+// DMs implements harmony.StateBackend.
+func (b *Backend) DMs() map[string]*harmony.Channel {
+	return b.channels(keyDMs)
+}
+
+// SetDM implements harmony.StateBackend.
+func (b *Backend) SetDM(ch *harmony.Channel) {
+	b.set(keyDMs, ch.ID, ch)
+}
+
+// DeleteDM implements harmony.StateBackend.
+func (b *Backend) DeleteDM(id string) {
+	b.delete(keyDMs, id)
+}
+
+// GroupDM implements harmony.StateBackend.
+func (b *Backend) GroupDM(id string) *harmony.Channel {
+	return b.channel(keyGroupDMs, id)
+}
+
+// GroupDMs implements harmony.StateBackend.
+func (b *Backend) GroupDMs() map[string]*harmony.Channel {
+	return b.channels(keyGroupDMs)
+}
+
+// SetGroupDM implements harmony.StateBackend.
+func (b *Backend) SetGroupDM(ch *harmony.Channel) {
+	b.set(keyGroupDMs, ch.ID, ch)
+}
+
+// DeleteGroupDM implements harmony.StateBackend.
+func (b *Backend) DeleteGroupDM(id string) {
+	b.delete(keyGroupDMs, id)
+}
+
+func (b *Backend) channel(key, id string) *harmony.Channel {
+	var ch harmony.Channel
+	if !b.get(key, id, &ch) {
+		return nil
+	}
+	return &ch
+}
+
+func (b *Backend) channels(key string) map[string]*harmony.Channel {
+	channels := make(map[string]*harmony.Channel)
+	b.getAll(key, func(id string, data []byte) error {
+		var ch harmony.Channel
+		if err := json.Unmarshal(data, &ch); err != nil {
+			return err
+		}
+		channels[id] = &ch
+		return nil
+	})
+	return channels
+}
+
+// Presence implements harmony.StateBackend.
+func (b *Backend) Presence(userID string) *harmony.Presence {
+	var p harmony.Presence
+	if !b.get(keyPresences, userID, &p) {
+		return nil
+	}
+	return &p
+}
+
+// Presences implements harmony.StateBackend.
+func (b *Backend) Presences() map[string]*harmony.Presence {
+	presences := make(map[string]*harmony.Presence)
+	b.getAll(keyPresences, func(userID string, data []byte) error {
+		var p harmony.Presence
+		if err := json.Unmarshal(data, &p); err != nil {
+			return err
+		}
+		presences[userID] = &p
+		return nil
+	})
+	return presences
+}
+
+// SetPresence implements harmony.StateBackend.
+func (b *Backend) SetPresence(p *harmony.Presence) {
+	b.set(keyPresences, p.User.ID, p)
+}
+
+// DeletePresence implements harmony.StateBackend.
+func (b *Backend) DeletePresence(userID string) {
+	b.delete(keyPresences, userID)
+}
+
+// GuildPresence implements harmony.StateBackend.
+func (b *Backend) GuildPresence(guildID, userID string) *harmony.Presence {
+	var p harmony.Presence
+	if !b.get(keyGuildPresences+guildID, userID, &p) {
+		return nil
+	}
+	return &p
+}
+
+// GuildPresences implements harmony.StateBackend.
+func (b *Backend) GuildPresences(guildID string) map[string]*harmony.Presence {
+	presences := make(map[string]*harmony.Presence)
+	b.getAll(keyGuildPresences+guildID, func(userID string, data []byte) error {
+		var p harmony.Presence
+		if err := json.Unmarshal(data, &p); err != nil {
+			return err
+		}
+		presences[userID] = &p
+		return nil
+	})
+	return presences
+}
+
+// SetGuildPresence implements harmony.StateBackend.
+func (b *Backend) SetGuildPresence(p *harmony.Presence) {
+	b.set(keyGuildPresences+p.GuildID, p.User.ID, p)
+}
+
+// DeleteGuildPresence implements harmony.StateBackend.
+func (b *Backend) DeleteGuildPresence(guildID, userID string) {
+	b.delete(keyGuildPresences+guildID, userID)
+}
+
+// DeleteGuildPresences implements harmony.StateBackend.
+func (b *Backend) DeleteGuildPresences(guildID string) {
+	b.deleteAll(keyGuildPresences + guildID)
+}
+
+// Webhooks implements harmony.StateBackend.
+func (b *Backend) Webhooks(channelID string) ([]harmony.Webhook, bool) {
+	var webhooks []harmony.Webhook
+	if !b.get(keyWebhooks, channelID, &webhooks) {
+		return nil, false
+	}
+	return webhooks, true
+}
+
+// SetWebhooks implements harmony.StateBackend.
+func (b *Backend) SetWebhooks(channelID string, webhooks []harmony.Webhook) {
+	b.set(keyWebhooks, channelID, webhooks)
+}
+
+// DeleteWebhooks implements harmony.StateBackend.
+func (b *Backend) DeleteWebhooks(channelID string) {
+	b.delete(keyWebhooks, channelID)
+}
+
+// Integrations implements harmony.StateBackend.
+func (b *Backend) Integrations(guildID string) ([]harmony.Integration, bool) {
+	var integrations []harmony.Integration
+	if !b.get(keyIntegrations, guildID, &integrations) {
+		return nil, false
+	}
+	return integrations, true
+}
+
+// SetIntegrations implements harmony.StateBackend.
+func (b *Backend) SetIntegrations(guildID string, integrations []harmony.Integration) {
+	b.set(keyIntegrations, guildID, integrations)
+}
+
+// DeleteIntegrations implements harmony.StateBackend.
+func (b *Backend) DeleteIntegrations(guildID string) {
+	b.delete(keyIntegrations, guildID)
+}
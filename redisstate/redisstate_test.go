@@ -0,0 +1,260 @@
+package redisstate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/harmonytest"
+)
+
+// fakeClient is a Client storing hashes in memory, failing while fail is set
+// and counting the commands it receives.
+type fakeClient struct {
+	mu       sync.Mutex
+	hashes   map[string]map[string]string
+	fail     bool
+	commands int
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{hashes: make(map[string]map[string]string)}
+}
+
+var errUnavailable = errors.New("redis unavailable")
+
+func (c *fakeClient) command() error {
+	c.commands++
+	if c.fail {
+		return errUnavailable
+	}
+	return nil
+}
+
+func (c *fakeClient) HGet(_ context.Context, key, field string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.command(); err != nil {
+		return nil, err
+	}
+	v, ok := c.hashes[key][field]
+	if !ok {
+		return nil, nil
+	}
+	return []byte(v), nil
+}
+
+func (c *fakeClient) HGetAll(_ context.Context, key string) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.command(); err != nil {
+		return nil, err
+	}
+	all := make(map[string]string, len(c.hashes[key]))
+	for k, v := range c.hashes[key] {
+		all[k] = v
+	}
+	return all, nil
+}
+
+func (c *fakeClient) HSet(_ context.Context, key, field string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.command(); err != nil {
+		return err
+	}
+	if c.hashes[key] == nil {
+		c.hashes[key] = make(map[string]string)
+	}
+	c.hashes[key][field] = string(value)
+	return nil
+}
+
+func (c *fakeClient) HDel(_ context.Context, key, field string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.command(); err != nil {
+		return err
+	}
+	delete(c.hashes[key], field)
+	return nil
+}
+
+func (c *fakeClient) Del(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.command(); err != nil {
+		return err
+	}
+	delete(c.hashes, key)
+	return nil
+}
+
+func (c *fakeClient) setFail(fail bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fail = fail
+}
+
+func (c *fakeClient) commandCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.commands
+}
+
+func TestBackendRoundTrip(t *testing.T) {
+	b := New(newFakeClient(), WithKeyPrefix("test:"))
+
+	b.SetGuild(&harmony.Guild{ID: "1", Name: "guild"})
+	if g := b.Guild("1"); g == nil || g.Name != "guild" {
+		t.Errorf("expected guild to be stored, got %+v", g)
+	}
+	if g := b.Guild("2"); g != nil {
+		t.Errorf("expected missing guild to be nil, got %+v", g)
+	}
+
+	b.SetMember("1", "10", &harmony.GuildMember{Nick: "a"})
+	b.SetMember("1", "11", &harmony.GuildMember{Nick: "b"})
+	if members := b.Members("1"); len(members) != 2 || members["11"].Nick != "b" {
+		t.Errorf("expected two members, got %+v", members)
+	}
+	b.DeleteMember("1", "10")
+	if m := b.Member("1", "10"); m != nil {
+		t.Errorf("expected deleted member to be nil, got %+v", m)
+	}
+
+	b.SetWebhooks("5", []harmony.Webhook{{ID: "6"}})
+	if webhooks, ok := b.Webhooks("5"); !ok || len(webhooks) != 1 {
+		t.Errorf("expected webhooks to be stored, got %v, %t", webhooks, ok)
+	}
+	if _, ok := b.Webhooks("7"); ok {
+		t.Error("expected missing webhooks not to be found")
+	}
+}
+
+func TestBackendBulkDeletes(t *testing.T) {
+	c := newFakeClient()
+	b := New(c)
+
+	for _, userID := range []string{"10", "11", "12"} {
+		b.SetMember("1", userID, &harmony.GuildMember{})
+		b.SetGuildPresence(&harmony.Presence{GuildID: "1", User: &harmony.User{ID: userID}})
+	}
+	b.SetMember("2", "10", &harmony.GuildMember{})
+
+	before := c.commandCount()
+	b.DeleteMembers("1")
+	b.DeleteGuildPresences("1")
+	if n := c.commandCount() - before; n != 2 {
+		t.Errorf("expected a single command per bulk delete, got %d commands", n)
+	}
+
+	if members := b.Members("1"); len(members) != 0 {
+		t.Errorf("expected members to be deleted, got %v", members)
+	}
+	if presences := b.GuildPresences("1"); len(presences) != 0 {
+		t.Errorf("expected presences to be deleted, got %v", presences)
+	}
+	if m := b.Member("2", "10"); m == nil {
+		t.Error("expected members of other guilds to be kept")
+	}
+}
+
+func TestBackendErrors(t *testing.T) {
+	c := newFakeClient()
+	var optErrs, clientErrs []error
+	b := New(c, WithErrorHandler(func(err error) { optErrs = append(optErrs, err) }))
+	b.SetErrorHandler(func(err error) { clientErrs = append(clientErrs, err) })
+
+	b.SetUser(&harmony.User{ID: "1"})
+	c.setFail(true)
+	if u := b.User("1"); u != nil {
+		t.Errorf("expected failed reads to return nil, got %+v", u)
+	}
+	b.DeleteMembers("1")
+
+	if len(optErrs) != 2 || len(clientErrs) != 2 {
+		t.Fatalf("expected errors to be passed to both handlers, got %v and %v", optErrs, clientErrs)
+	}
+	if !errors.Is(clientErrs[0], errUnavailable) {
+		t.Errorf("expected errors to wrap the error of the client, got %v", clientErrs[0])
+	}
+
+	// Objects that can not be decoded are treated as missing.
+	c.setFail(false)
+	c.hashes[DefaultKeyPrefix+keyUsers]["1"] = "{"
+	if u := b.User("1"); u != nil {
+		t.Errorf("expected undecodable objects to return nil, got %+v", u)
+	}
+	if len(clientErrs) != 3 {
+		t.Errorf("expected decoding errors to be reported, got %v", clientErrs)
+	}
+}
+
+// errorReporter records the errors reported by a client.
+type errorReporter struct {
+	mu     sync.Mutex
+	events []*harmony.ErrorEvent
+}
+
+func (r *errorReporter) CaptureException(_ error, event *harmony.ErrorEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func (r *errorReporter) stateErrors() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int
+	for _, e := range r.events {
+		if e.Source == harmony.ErrorSourceState {
+			n++
+		}
+	}
+	return n
+}
+
+func TestBackendErrorsReported(t *testing.T) {
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+	srv.AddGuild(&harmony.Guild{ID: "1", Name: "guild"})
+
+	rc := newFakeClient()
+	rc.setFail(true)
+	reporter := &errorReporter{}
+
+	c, err := srv.NewClient(harmony.WithStateBackend(New(rc)), harmony.WithErrorReporter(reporter))
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = c.Connect(ctx); err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer c.Disconnect()
+
+	// Storing the guild sent once connected fails.
+	for reporter.stateErrors() == 0 {
+		select {
+		case <-time.After(time.Millisecond):
+		case <-ctx.Done():
+			t.Fatal("expected state backend errors to be reported")
+		}
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
 	"net/http"
-	"net/http/httputil"
+	"net/url"
+	"strconv"
 	"time"
 
+	"github.com/skwair/harmony/clock"
 	"github.com/skwair/harmony/internal/endpoint"
-	"github.com/skwair/harmony/log"
 )
 
 // requestPayload is a payload that is sent to Discord's REST API.
@@ -48,8 +51,172 @@ func (c *Client) doReq(ctx context.Context, e *endpoint.Endpoint, p *requestPayl
 // doReqWithHeader sends an HTTP request and returns the response given an endpoint
 // an optional payload and some headers. It adds the required Authorization header,
 // Content-Type based on the given payload and also sets the User-Agent.
-// It also takes care of rate limiting, using the client's built in rate limiter.
+// It also takes care of rate limiting, using the client's built in rate limiter,
+// and retries requests that are rate limited anyway a few times.
 func (c *Client) doReqWithHeader(ctx context.Context, e *endpoint.Endpoint, p *requestPayload, h http.Header) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := c.newRequest(ctx, e, p, h)
+		if err != nil {
+			return nil, err
+		}
+
+		// Requests sent while banned make the ban last longer.
+		if d := c.banned(); d > 0 {
+			return nil, fmt.Errorf("%s %s: %w (retry in %s)", e.Method, redactPath(e.Path), ErrCloudflareBan, d)
+		}
+
+		if c.breaker != nil {
+			if d, ok := c.breaker.Allow(e.Key); !ok {
+				return nil, fmt.Errorf("%s %s: %w (retry in %s)", e.Method, redactPath(e.Path), ErrCircuitOpen, d)
+			}
+		}
+
+		waitStart := c.clock.Now()
+		err = c.limiter.Wait(ctx, e.Method, e.Key)
+		c.recordRetry(c.clock.Since(waitStart), false)
+		if err != nil {
+			c.cancelBreaker(e)
+			return nil, err
+		}
+
+		if c.logsPayloads() {
+			c.logRequest(e, req, p)
+		}
+
+		before := c.clock.Now()
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			c.observeRequest(e, 0, c.clock.Since(before))
+			c.limiter.Cancel(e.Method, e.Key)
+			if ctx.Err() != nil {
+				c.cancelBreaker(e)
+			} else {
+				c.recordBreaker(e, true)
+			}
+			// Errors returned by the HTTP client hold the URL of the request.
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				urlErr.URL = c.baseURL + redactPath(e.Path)
+			}
+			c.reportError(err, &ErrorEvent{Source: ErrorSourceREST, Method: e.Method, Path: redactPath(e.Path)})
+			return nil, err
+		}
+		c.observeRequest(e, resp.StatusCode, c.clock.Since(before))
+		c.recordBreaker(e, resp.StatusCode >= http.StatusInternalServerError)
+
+		if c.logsPayloads() {
+			c.logResponse(e, resp, c.clock.Since(before))
+		}
+
+		c.limiter.Update(e.Method, e.Key, resp.Header)
+		captureResponseInfo(ctx, resp)
+
+		// Make sure we agree on time with the server, otherwise rate limit would be inaccurate.
+		date, err := http.ParseTime(resp.Header.Get("Date"))
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("could not parse date header: %w", err)
+		}
+
+		now := c.clock.Now()
+
+		// Only print the warning if the request took less than one second, otherwise it
+		// could just be a very high network latency but not a time desynchronization.
+		// NOTE: these values probably need some tweaking.
+		if now.Sub(before) < time.Second &&
+			(now.Before(date.Add(-1500*time.Millisecond)) ||
+				now.After(date.Add(1500*time.Millisecond))) {
+			c.logger.Warnf("time desynchronization detected (server UTC time: %s, local UTC time: %s), rate limit will be inaccurate and you may encounter 429s, consider using NTP to synchronize time", date.UTC(), now.Round(time.Second).UTC())
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if err = c.checkCloudflareBan(e, resp); err != nil {
+				return nil, err
+			}
+		}
+
+		// We are being rate limited, rate limiter has been updated
+		// and will wait before sending future requests, but we must
+		// try and resend this one since it was rejected. This can
+		// happen when other applications share our rate limits, or
+		// when a bucket is shared by routes we did not know of yet.
+		// Give up after a few attempts and return the 429 to the caller.
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries {
+			retryAfter, global, err := parseRateLimit(resp)
+			if err != nil {
+				return nil, err
+			}
+			if global {
+				c.limiter.Limit(retryAfter)
+			}
+
+			c.logger.Debugf("rate limited on %s %s (global: %t), retrying in %s", e.Method, redactPath(e.Path), global, retryAfter)
+
+			retryStart := c.clock.Now()
+			select {
+			case <-c.clock.After(retryAfter + rateLimitJitter()):
+			case <-ctx.Done():
+				c.recordRetry(c.clock.Since(retryStart), false)
+				return nil, ctx.Err()
+			}
+			c.recordRetry(c.clock.Since(retryStart), true)
+			continue
+		}
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			err = fmt.Errorf("%s %s: %s", e.Method, redactPath(e.Path), resp.Status)
+			c.reportError(err, &ErrorEvent{
+				Source:     ErrorSourceREST,
+				Method:     e.Method,
+				Path:       redactPath(e.Path),
+				StatusCode: resp.StatusCode,
+			})
+		}
+
+		return resp, nil
+	}
+}
+
+// rateLimitSaveInterval is the minimum interval between two saves
+// of the state of the rate limiter. See WithRateLimitStore.
+const rateLimitSaveInterval = time.Second
+
+// restoreRateLimits restores the state of the rate limiter
+// from its store and persists it from now on.
+func (c *Client) restoreRateLimits() {
+	snap, err := c.rateLimitStore.Load()
+	if err != nil {
+		c.logger.Warnf("could not load rate limits, starting without them: %v", err)
+	} else if snap != nil {
+		c.limiter.Restore(snap)
+	}
+
+	c.limiter.Persist(c.rateLimitStore, rateLimitSaveInterval, func(err error) {
+		c.logger.Warnf("could not save rate limits: %v", err)
+	})
+}
+
+// recordBreaker records the result of a request to the given endpoint
+// in the circuit breaker of the client, if enabled.
+func (c *Client) recordBreaker(e *endpoint.Endpoint, failed bool) {
+	if c.breaker != nil {
+		c.breaker.Record(e.Key, failed)
+	}
+}
+
+// cancelBreaker tells the circuit breaker of the client, if enabled, that
+// a request to the given endpoint was not sent or did not complete.
+func (c *Client) cancelBreaker(e *endpoint.Endpoint) {
+	if c.breaker != nil {
+		c.breaker.Cancel(e.Key)
+	}
+}
+
+// newRequest creates a new HTTP request for the given endpoint, payload and
+// headers. It is called for each attempt since the body of a request can only
+// be read once.
+func (c *Client) newRequest(ctx context.Context, e *endpoint.Endpoint, p *requestPayload, h http.Header) (*http.Request, error) {
 	var (
 		err error
 		req *http.Request
@@ -71,6 +238,16 @@ func (c *Client) doReqWithHeader(ctx context.Context, e *endpoint.Endpoint, p *r
 			req.Header.Add(k, v)
 		}
 	}
+	// Add default headers of the client, unless they were
+	// explicitly set for this request.
+	for k, vs := range c.defaultHeader {
+		if _, ok := req.Header[k]; ok {
+			continue
+		}
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
 	// Add the Content-Type header accordingly to the payload's body, if any.
 	if p.hasBody() {
 		req.Header.Set("Content-Type", p.contentType)
@@ -79,72 +256,53 @@ func (c *Client) doReqWithHeader(ctx context.Context, e *endpoint.Endpoint, p *r
 	req.Header.Set("Authorization", c.token)
 	// Finally, set the User-Agent header.
 	ua := fmt.Sprintf("%s (github.com/skwair/harmony, %s)", c.name, version)
+	if c.userAgentSuffix != "" {
+		ua += " " + c.userAgentSuffix
+	}
 	req.Header.Set("User-Agent", ua)
 
-	c.limiter.Wait(e.Key)
+	return req, nil
+}
 
-	if c.logger.Level() == log.LevelDebug {
-		b, _ := httputil.DumpRequestOut(req, true)
-		c.logger.Debug("--> ", string(b))
-	}
+const (
+	// maxRateLimitRetries is the maximum number of times a
+	// request is retried when it is rate limited.
+	maxRateLimitRetries = 3
+	// maxRateLimitJitter is the maximum random delay added before retrying
+	// a rate limited request, so concurrent requests do not retry at once.
+	maxRateLimitJitter = 250 * time.Millisecond
+)
 
-	before := time.Now()
+func rateLimitJitter() time.Duration {
+	return time.Duration(rand.Int63n(int64(maxRateLimitJitter)))
+}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
+// parseRateLimit returns how long to wait before retrying a request that
+// was rate limited, and whether the global rate limit was hit. It closes
+// the body of the response.
+func parseRateLimit(resp *http.Response) (time.Duration, bool, error) {
+	defer resp.Body.Close()
 
-	if c.logger.Level() == log.LevelDebug {
-		b, _ := httputil.DumpResponse(resp, true)
-		c.logger.Debug("<-- ", time.Since(before), "\n", string(b))
+	var r rateLimitResp
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return 0, false, err
 	}
 
-	c.limiter.Update(e.Key, resp.Header)
-
-	// Make sure we agree on time with the server, otherwise rate limit would be inaccurate.
-	date, err := http.ParseTime(resp.Header.Get("Date"))
-	if err != nil {
-		return nil, fmt.Errorf("could not parse date header: %w", err)
-	}
-
-	now := time.Now()
-
-	// Only print the warning if the request took less than one second, otherwise it
-	// could just be a very high network latency but not a time desynchronization.
-	// NOTE: these values probably need some tweaking.
-	if now.Sub(before) < time.Second &&
-		(now.Before(date.Add(-1500*time.Millisecond)) ||
-			now.After(date.Add(1500*time.Millisecond))) {
-		c.logger.Warnf("time desynchronization detected (server UTC time: %s, local UTC time: %s), rate limit will be inaccurate and you may encounter 429s, consider using NTP to synchronize time", date.UTC(), now.Round(time.Second).UTC())
-	}
-
-	// We are being rate limited, rate limiter has been updated
-	// and will wait before sending future requests, but we must
-	// try and resend this one since it was rejected.
-	// NOTE: this should never happen as long as our time is in
-	// sync with Discord servers since we wait before sending requests.
-	// Still, keep this check to prevent spamming in the event where
-	// a time desynchronization happens.
-	if resp.StatusCode == http.StatusTooManyRequests {
-		var r rateLimitResp
-		if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
-			return nil, err
-		}
-
-		time.Sleep(time.Millisecond * time.Duration(r.RetryAfter))
-
-		return c.doReqWithHeader(ctx, e, p, h)
+	global := r.Global || resp.Header.Get("X-RateLimit-Global") != ""
+	// Prefer the Retry-After header and fall back on the body,
+	// both report the number of seconds to wait.
+	if after, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil && after > 0 {
+		return time.Duration(after * float64(time.Second)), global, nil
 	}
-
-	return resp, nil
+	return time.Duration(r.RetryAfter * float64(time.Second)), global, nil
 }
 
 // rateLimitResp is the JSON body Discord sends when we are rate limited.
 type rateLimitResp struct {
-	Message    string `json:"message"`
-	RetryAfter int    `json:"retry_after"`
-	Global     bool   `json:"global"`
+	Message string `json:"message"`
+	// Number of seconds to wait before retrying.
+	RetryAfter float64 `json:"retry_after"`
+	Global     bool    `json:"global"`
 }
 
 // doReqNoAuth is used to request endpoints that do not need authentication.
@@ -153,10 +311,47 @@ func doReqNoAuth(ctx context.Context, e *endpoint.Endpoint, p *requestPayload) (
 	return doReqNoAuthWithHeader(ctx, e, p, nil)
 }
 
-// doReqNoAuth is used to request endpoints that do not need authentication. It is
-// like doReqWithHeader otherwise, except for rate limiting where it is more likely
-// to result in 429's if abused.
+// noAuthClock is the clock used to wait before retrying rate limited
+// requests sent by doReqNoAuthWithHeader, which have no Client.
+var noAuthClock = clock.New()
+
+// doReqNoAuthWithHeader is used to request endpoints that do not need authentication.
+// It is like doReqWithHeader otherwise, except for rate limiting where it is more
+// likely to result in 429's if abused.
 func doReqNoAuthWithHeader(ctx context.Context, e *endpoint.Endpoint, p *requestPayload, h http.Header) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := newNoAuthRequest(ctx, e, p, h)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		// We are being rate limited, wait a bit and resend the request.
+		// Give up after a few attempts and return the 429 to the caller.
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries {
+			retryAfter, _, err := parseRateLimit(resp)
+			if err != nil {
+				return nil, err
+			}
+
+			select {
+			case <-noAuthClock.After(retryAfter + rateLimitJitter()):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			continue
+		}
+
+		return resp, nil
+	}
+}
+
+// newNoAuthRequest returns a new request to an endpoint that does not need authentication.
+func newNoAuthRequest(ctx context.Context, e *endpoint.Endpoint, p *requestPayload, h http.Header) (*http.Request, error) {
 	var (
 		err error
 		req *http.Request
@@ -178,29 +373,10 @@ func doReqNoAuthWithHeader(ctx context.Context, e *endpoint.Endpoint, p *request
 		}
 	}
 	if p.hasBody() {
-		h.Set("Content-Type", p.contentType)
+		req.Header.Set("Content-Type", p.contentType)
 	}
-	ua := fmt.Sprintf("%s (github.com/skwair/harmony, %s", "Harmony", version)
+	ua := fmt.Sprintf("%s (github.com/skwair/harmony, %s)", "Harmony", version)
 	req.Header.Set("User-Agent", ua)
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	// We are being rate limited, wait a bit and resend the request.
-	// NOTE: maybe use HTTP headers (if set) instead of having to
-	// parse some JSON.
-	if resp.StatusCode == http.StatusTooManyRequests {
-		var r rateLimitResp
-		if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
-			return nil, err
-		}
-
-		time.Sleep(time.Millisecond * time.Duration(r.RetryAfter))
-
-		return doReqNoAuthWithHeader(ctx, e, p, h)
-	}
-
-	return resp, nil
+	return req, nil
 }
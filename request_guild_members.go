@@ -0,0 +1,190 @@
+package harmony
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+)
+
+const (
+	// maxRequestedUserIDs is the maximum number of users
+	// that can be requested with Request Guild Members.
+	maxRequestedUserIDs = 100
+	// maxNonceLength is the maximum length of the nonce
+	// of a Request Guild Members payload.
+	maxNonceLength = 32
+)
+
+type requestGuildMembers struct {
+	GuildID   string   `json:"guild_id"`
+	Query     *string  `json:"query,omitempty"`
+	Limit     int      `json:"limit"`
+	Presences bool     `json:"presences,omitempty"`
+	UserIDs   []string `json:"user_ids,omitempty"`
+	Nonce     string   `json:"nonce,omitempty"`
+}
+
+// memberRequest is a pending Request Guild Members whose chunks are
+// sent to a channel. Chunks are queued so the goroutine handling Gateway
+// events never blocks on a slow consumer.
+type memberRequest struct {
+	mu       sync.Mutex
+	queue    []*GuildMembersChunk
+	received chan struct{} // Signaled when chunks are queued.
+}
+
+func (r *memberRequest) push(chunk *GuildMembersChunk) {
+	r.mu.Lock()
+	r.queue = append(r.queue, chunk)
+	r.mu.Unlock()
+
+	select {
+	case r.received <- struct{}{}:
+	default:
+	}
+}
+
+func (r *memberRequest) take() []*GuildMembersChunk {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	chunks := r.queue
+	r.queue = nil
+	return chunks
+}
+
+// RequestGuildMembers requests members of a guild and returns a channel through
+// which the Guild Members Chunk events sent in response are received. The channel
+// is closed once all chunks are received or when ctx is done, so ctx should have a
+// deadline since Discord does not respond when the client disconnects meanwhile.
+//
+// Members are requested either by username with query, which can be empty to
+// request all members, or by ID with userIDs, up to 100. limit is the maximum
+// number of members to send, or 0 to request all matching members. If presences
+// is true, presences of members are sent too. nonce identifies the request, a
+// unique one is generated if it is empty.
+//
+// Requesting all members requires the GatewayIntentGuildMembers intent and
+// requesting presences the GatewayIntentGuildPresences intent. Chunks are also
+// handled as regular events, updating the State and calling handlers registered
+// with OnGuildMembersChunk. You need to be connected to the Gateway to call this
+// method, else it will return ErrGatewayNotConnected.
+func (c *Client) RequestGuildMembers(ctx context.Context, guildID, query string, limit int, presences bool, userIDs []string, nonce string) (<-chan *GuildMembersChunk, error) {
+	if !c.isConnected() {
+		return nil, ErrGatewayNotConnected
+	}
+	if len(userIDs) > maxRequestedUserIDs {
+		return nil, errors.New("can not request more than 100 users by ID")
+	}
+	if len(userIDs) > 0 && query != "" {
+		return nil, errors.New("can not request members both by username and by ID")
+	}
+	if len(nonce) > maxNonceLength {
+		return nil, errors.New("nonce can not be longer than 32 bytes")
+	}
+
+	req := &memberRequest{received: make(chan struct{}, 1)}
+
+	c.memberRequestsMu.Lock()
+	if nonce == "" {
+		c.memberRequestsSeq++
+		nonce = "harmony-" + strconv.FormatInt(c.memberRequestsSeq, 10)
+	}
+	if _, ok := c.memberRequests[nonce]; ok {
+		c.memberRequestsMu.Unlock()
+		return nil, errors.New("a request with this nonce is already pending")
+	}
+	c.memberRequests[nonce] = req
+	c.memberRequestsMu.Unlock()
+
+	p := &requestGuildMembers{
+		GuildID:   guildID,
+		Limit:     limit,
+		Presences: presences,
+		UserIDs:   userIDs,
+		Nonce:     nonce,
+	}
+	if len(userIDs) == 0 {
+		p.Query = &query
+	}
+	if err := c.sendPayload(ctx, gatewayOpcodeRequestGuildMembers, p); err != nil {
+		c.removeMemberRequest(nonce)
+		return nil, err
+	}
+
+	ch := make(chan *GuildMembersChunk)
+	go c.forwardMemberChunks(ctx, nonce, req, ch)
+	return ch, nil
+}
+
+// forwardMemberChunks sends the chunks received for the given request to ch,
+// closing it once all of them are sent or when ctx is done.
+func (c *Client) forwardMemberChunks(ctx context.Context, nonce string, req *memberRequest, ch chan<- *GuildMembersChunk) {
+	defer close(ch)
+	defer c.removeMemberRequest(nonce)
+
+	var sent int
+	for {
+		select {
+		case <-req.received:
+		case <-ctx.Done():
+			return
+		}
+
+		for _, chunk := range req.take() {
+			select {
+			case ch <- chunk:
+			case <-ctx.Done():
+				return
+			}
+
+			sent++
+			if sent >= chunk.ChunkCount {
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) removeMemberRequest(nonce string) {
+	c.memberRequestsMu.Lock()
+	defer c.memberRequestsMu.Unlock()
+
+	delete(c.memberRequests, nonce)
+}
+
+// deliverMemberChunk passes the given chunk to the pending
+// request it responds to, if any.
+func (c *Client) deliverMemberChunk(chunk *GuildMembersChunk) {
+	if chunk.Nonce == "" {
+		return
+	}
+
+	c.memberRequestsMu.Lock()
+	req, ok := c.memberRequests[chunk.Nonce]
+	c.memberRequestsMu.Unlock()
+
+	if ok {
+		req.push(chunk)
+	}
+}
+
+// requestAllGuildMembers requests all members of the given guild if the Gateway
+// did not send all of them, so the State knows about all of them. See
+// WithMemberChunking.
+func (c *Client) requestAllGuildMembers(g *Guild) {
+	if !g.Large && len(g.Members) >= g.MemberCount {
+		return
+	}
+
+	query := ""
+	err := c.sendPayload(c.ctx, gatewayOpcodeRequestGuildMembers, &requestGuildMembers{
+		GuildID:   g.ID,
+		Query:     &query,
+		Presences: c.intents&GatewayIntentGuildPresences != 0,
+	})
+	if err != nil {
+		c.logger.Errorf("could not request members of guild %s: %v", g.ID, err)
+	}
+}
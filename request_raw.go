@@ -0,0 +1,102 @@
+package harmony
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/skwair/harmony/internal/endpoint"
+)
+
+// RequestOption allows to customize a request sent with Client.Do.
+type RequestOption func(*rawRequest)
+
+type rawRequest struct {
+	query  url.Values
+	header http.Header
+}
+
+// WithRequestReason sets the reason that will be set in the
+// audit log entry for the action performed by the request.
+func WithRequestReason(reason string) RequestOption {
+	return func(r *rawRequest) {
+		if reason != "" {
+			r.header.Set("X-Audit-Log-Reason", reason)
+		}
+	}
+}
+
+// WithRequestHeader adds a header to the request.
+func WithRequestHeader(key, value string) RequestOption {
+	return func(r *rawRequest) {
+		r.header.Add(key, value)
+	}
+}
+
+// WithRequestQuery adds query parameters to the request.
+func WithRequestQuery(q url.Values) RequestOption {
+	return func(r *rawRequest) {
+		for k, vs := range q {
+			for _, v := range vs {
+				r.query.Add(k, v)
+			}
+		}
+	}
+}
+
+// Do sends a request to an endpoint of Discord's REST API that is not wrapped
+// by Harmony yet. The path is relative to the base URL of the API, for instance
+// "/guilds/123/onboarding". The body, if not nil, is encoded to JSON. The request
+// goes through the same authentication, rate limiting and retry logic as any other
+// request sent by the client. Returns the raw JSON response, which is nil if the
+// response has no content. If Discord returns an error, it is an APIError or a
+// *ValidationError.
+func (c *Client) Do(ctx context.Context, method, path string, body interface{}, opts ...RequestOption) (json.RawMessage, error) {
+	r := rawRequest{
+		query:  url.Values{},
+		header: http.Header{},
+	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(path, "?") {
+			sep = "&"
+		}
+		path += sep + r.query.Encode()
+	}
+
+	var p *requestPayload
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		p = jsonPayload(b)
+	}
+
+	e := endpoint.Raw(method, path)
+	resp, err := c.doReqWithHeader(ctx, e, p, r.header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, apiError(resp)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	return b, nil
+}
@@ -0,0 +1,79 @@
+package harmony
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ResponseInfo holds metadata about a response received from Discord's REST API.
+// It is useful to log or debug Discord-side behavior, such as rate limiting.
+// See WithResponseInfo to capture it.
+type ResponseInfo struct {
+	// StatusCode of the HTTP response.
+	StatusCode int
+	// Bucket is the rate limit bucket of the route, as
+	// reported by the X-RateLimit-Bucket header.
+	Bucket string
+	// Limit is the number of requests that can be made in the bucket.
+	Limit int
+	// Remaining is the number of requests that can still be made
+	// in the bucket before being rate limited.
+	Remaining int
+	// Reset is the time at which the rate limit of the bucket resets.
+	Reset time.Time
+	// RetryAfter is how long to wait before retrying, as reported
+	// by the Retry-After header if the request was rate limited.
+	RetryAfter time.Duration
+	// Global is true if the request hit the global rate limit.
+	Global bool
+	// Header contains all headers of the response.
+	Header http.Header
+}
+
+type responseInfoKey struct{}
+
+// WithResponseInfo returns a copy of ctx that captures metadata about the
+// response of the request made with it into info. If the context is used
+// for more than one request, info holds metadata about the last one.
+// The info is only set after the request returns and must not be
+// shared across concurrent requests.
+//
+// For example:
+//
+//	var info harmony.ResponseInfo
+//	msg, err := client.Channel(id).SendMessage(harmony.WithResponseInfo(ctx, &info), "Hello")
+//	log.Printf("bucket=%s remaining=%d", info.Bucket, info.Remaining)
+func WithResponseInfo(ctx context.Context, info *ResponseInfo) context.Context {
+	return context.WithValue(ctx, responseInfoKey{}, info)
+}
+
+// captureResponseInfo fills the ResponseInfo of the given context,
+// if any, with metadata of the given response.
+func captureResponseInfo(ctx context.Context, resp *http.Response) {
+	info, ok := ctx.Value(responseInfoKey{}).(*ResponseInfo)
+	if !ok || info == nil {
+		return
+	}
+
+	h := resp.Header
+	*info = ResponseInfo{
+		StatusCode: resp.StatusCode,
+		Bucket:     h.Get("X-RateLimit-Bucket"),
+		Global:     h.Get("X-RateLimit-Global") != "",
+		Header:     h.Clone(),
+	}
+	if limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit")); err == nil {
+		info.Limit = limit
+	}
+	if remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining")); err == nil {
+		info.Remaining = remaining
+	}
+	if reset, err := strconv.ParseFloat(h.Get("X-RateLimit-Reset"), 64); err == nil {
+		info.Reset = time.Unix(0, int64(reset*float64(time.Second)))
+	}
+	if after, err := strconv.ParseFloat(h.Get("Retry-After"), 64); err == nil {
+		info.RetryAfter = time.Duration(after * float64(time.Second))
+	}
+}
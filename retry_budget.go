@@ -0,0 +1,64 @@
+package harmony
+
+import "time"
+
+// RetryBudget accounts for the time a client spent throttled by rate limits
+// during one minute, which helps detecting when a bot spends most of its time
+// waiting instead of sending requests. See Stats and WithRetryBudgetHandler.
+type RetryBudget struct {
+	// Start of the minute.
+	Start time.Time `json:"start"`
+	// Number of requests retried after being rate limited.
+	Retries int `json:"retries"`
+	// Time spent waiting for rate limits before sending requests and before
+	// retrying them. Since it is summed over concurrent requests, it can be
+	// more than a minute.
+	Waited time.Duration `json:"waited"`
+}
+
+// WithRetryBudgetHandler sets the function called with the retry budget of each
+// minute during which requests were retried or waited for rate limits. It is
+// called by the first request sent after the minute ended, so it must not block.
+// Defaults to nil, retry budgets are only available with Stats.
+func WithRetryBudgetHandler(f func(b RetryBudget)) ClientOption {
+	return func(c *Client) {
+		c.onRetryBudget = f
+	}
+}
+
+// recordRetry records that a request waited the given duration because of rate
+// limits before being sent, or before being retried if retry is true.
+func (c *Client) recordRetry(waited time.Duration, retry bool) {
+	c.statsMu.Lock()
+	ended, ok := c.rollRetryBudget()
+	c.retryBudget.Waited += waited
+	if retry {
+		c.retryBudget.Retries++
+	}
+	c.statsMu.Unlock()
+
+	if ok && c.onRetryBudget != nil {
+		c.onRetryBudget(ended)
+	}
+}
+
+// rollRetryBudget starts the retry budget of the current minute if the current
+// one is over, returning it if requests were retried or waited during it. It
+// must be called with statsMu held.
+func (c *Client) rollRetryBudget() (RetryBudget, bool) {
+	start := c.clock.Now().Truncate(time.Minute)
+	if c.retryBudget.Start.Equal(start) {
+		return RetryBudget{}, false
+	}
+
+	ended := c.retryBudget
+	if ended.Start.Equal(start.Add(-time.Minute)) {
+		c.lastRetryBudget = ended
+	} else {
+		// Nothing happened during the previous minute.
+		c.lastRetryBudget = RetryBudget{Start: start.Add(-time.Minute)}
+	}
+	c.retryBudget = RetryBudget{Start: start}
+
+	return ended, ended.Retries > 0 || ended.Waited > 0
+}
@@ -1,20 +1,42 @@
 package role
 
-import "github.com/skwair/harmony/optional"
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
-// Settings describes how to modify a guild role. All fields are optional.
+	"github.com/skwair/harmony/optional"
+)
+
+// Constraints enforced by Discord on role settings.
+const (
+	MaxNameLength = 100
+	MaxColor      = 0xFFFFFF
+)
+
+// Settings describes how to create or modify a guild role. All fields are
+// optional and only those explicitly set will be sent, even when set to
+// their zero value.
 type Settings struct {
-	Name        *optional.String `json:"name,omitempty"`
-	Permissions *optional.Int    `json:"permissions,omitempty"`
+	Name *optional.String `json:"name,omitempty"` // 1-100 characters.
+	// Permissions are sent as a string since they do not fit in 53 bits.
+	Permissions *optional.String `json:"permissions,omitempty"`
 	Color       *optional.Int    `json:"color,omitempty"`
 	Hoist       *optional.Bool   `json:"hoist,omitempty"`
 	Mentionable *optional.Bool   `json:"mentionable,omitempty"`
+	// Icon of the role, as a base64 encoded image. Requires the
+	// guild to have the ROLE_ICONS feature.
+	Icon *optional.String `json:"icon,omitempty"`
+	// Standard emoji shown as the icon of the role, instead of Icon.
+	UnicodeEmoji *optional.String `json:"unicode_emoji,omitempty"`
 }
 
 // Setting is a function that configures a guild role.
 type Setting func(*Settings)
 
-// NewSettings returns new Settings to modify a a guild role.
+// NewSettings returns new Settings to create or modify a guild role.
 func NewSettings(opts ...Setting) *Settings {
 	s := &Settings{}
 
@@ -25,21 +47,23 @@ func NewSettings(opts ...Setting) *Settings {
 	return s
 }
 
-// WithName sets the name of guild a role.
+// WithName sets the name of a guild role.
 func WithName(name string) Setting {
 	return func(s *Settings) {
 		s.Name = optional.NewString(name)
 	}
 }
 
-// WithPermissions sets the permissions of guild a role.
-func WithPermissions(perm int) Setting {
+// WithPermissions sets the permissions of a guild role, as a bit set
+// of permissions defined in the permission package.
+func WithPermissions(perm uint64) Setting {
 	return func(s *Settings) {
-		s.Permissions = optional.NewInt(perm)
+		s.Permissions = optional.NewString(strconv.FormatUint(perm, 10))
 	}
 }
 
-// WithColor sets the color of guild a role. It accepts hexadecimal value.
+// WithColor sets the color of a guild role, as an RGB value such as 0x336677.
+// A color of 0 will remove the current color.
 func WithColor(hexCode int) Setting {
 	return func(s *Settings) {
 		s.Color = optional.NewInt(hexCode)
@@ -59,3 +83,55 @@ func WithMentionable(yes bool) Setting {
 		s.Mentionable = optional.NewBool(yes)
 	}
 }
+
+// WithIcon sets the icon of a guild role, which is a base64 encoded image.
+// An empty icon will remove the current icon.
+func WithIcon(icon string) Setting {
+	return func(s *Settings) {
+		if icon == "" {
+			s.Icon = optional.NewNilString()
+		} else {
+			s.Icon = optional.NewString(icon)
+		}
+	}
+}
+
+// WithUnicodeEmoji sets the standard emoji shown as the icon of a guild role,
+// for instance "🔥". An empty emoji will remove the current emoji.
+func WithUnicodeEmoji(emoji string) Setting {
+	return func(s *Settings) {
+		if emoji == "" {
+			s.UnicodeEmoji = optional.NewNilString()
+		} else {
+			s.UnicodeEmoji = optional.NewString(emoji)
+		}
+	}
+}
+
+// Validate checks these settings against the constraints enforced by Discord
+// and returns an error describing every violated constraint, if any.
+func (s *Settings) Validate() error {
+	var problems []string
+
+	if s.Name != nil {
+		name, _ := s.Name.Value()
+		if l := utf8.RuneCountInString(name); l == 0 || l > MaxNameLength {
+			problems = append(problems, fmt.Sprintf("name must be between 1 and %d characters", MaxNameLength))
+		}
+	}
+
+	if color, ok := s.Color.Value(); ok && (color < 0 || color > MaxColor) {
+		problems = append(problems, fmt.Sprintf("color must be between 0 and %#x", MaxColor))
+	}
+
+	icon, _ := s.Icon.Value()
+	emoji, _ := s.UnicodeEmoji.Value()
+	if icon != "" && emoji != "" {
+		problems = append(problems, "only one of icon or unicode emoji can be set")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid role settings: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
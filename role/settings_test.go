@@ -0,0 +1,37 @@
+package role
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skwair/harmony/optional"
+)
+
+func TestSettingsValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings *Settings
+		valid    bool
+	}{
+		{name: "empty", settings: NewSettings(), valid: true},
+		{name: "name", settings: NewSettings(WithName("moderators")), valid: true},
+		{name: "empty name", settings: NewSettings(WithName("")), valid: false},
+		{name: "literal name too long", settings: &Settings{Name: optional.NewString(strings.Repeat("a", MaxNameLength+1))}, valid: false},
+		{name: "nil name", settings: &Settings{Name: optional.NewNilString()}, valid: false},
+		{name: "color", settings: NewSettings(WithColor(0x336677)), valid: true},
+		{name: "color out of range", settings: NewSettings(WithColor(MaxColor + 1)), valid: false},
+		{name: "literal color out of range", settings: &Settings{Color: optional.NewInt(-1)}, valid: false},
+		{name: "removed color", settings: &Settings{Color: optional.NewNilInt()}, valid: true},
+		{name: "icon", settings: NewSettings(WithIcon("data"), WithUnicodeEmoji("")), valid: true},
+		{name: "icon and emoji", settings: NewSettings(WithIcon("data"), WithUnicodeEmoji("🔥")), valid: false},
+		{name: "literal icon and emoji", settings: &Settings{Icon: optional.NewString("data"), UnicodeEmoji: optional.NewString("🔥")}, valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.settings.Validate(); (err == nil) != tt.valid {
+				t.Errorf("expected settings to be valid: %t, got error: %v", tt.valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package role
+
+import "encoding/json"
+
+// Tags are the tags a role can have, describing what manages it.
+type Tags struct {
+	// ID of the bot this role belongs to.
+	BotID *string
+	// ID of the integration this role belongs to.
+	IntegrationID *string
+	// Whether this is the guild's Booster role.
+	PremiumSubscriber bool
+	// ID of this role's subscription SKU and listing.
+	SubscriptionListingID *string
+	// Whether this role is available for purchase.
+	AvailableForPurchase bool
+	// Whether this role is a guild's linked role.
+	GuildConnections bool
+}
+
+// tags is the representation of Tags sent by Discord. Boolean tags
+// are set to null when true and are omitted when false.
+type tags struct {
+	BotID                 *string          `json:"bot_id,omitempty"`
+	IntegrationID         *string          `json:"integration_id,omitempty"`
+	PremiumSubscriber     *json.RawMessage `json:"premium_subscriber,omitempty"`
+	SubscriptionListingID *string          `json:"subscription_listing_id,omitempty"`
+	AvailableForPurchase  *json.RawMessage `json:"available_for_purchase,omitempty"`
+	GuildConnections      *json.RawMessage `json:"guild_connections,omitempty"`
+}
+
+var null = json.RawMessage("null")
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (t *Tags) UnmarshalJSON(b []byte) error {
+	// Unmarshal into a map to tell apart null values
+	// from missing ones, since both mean something
+	// different for boolean tags.
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	var tt tags
+	if err := json.Unmarshal(b, &tt); err != nil {
+		return err
+	}
+
+	_, premiumSubscriber := raw["premium_subscriber"]
+	_, availableForPurchase := raw["available_for_purchase"]
+	_, guildConnections := raw["guild_connections"]
+
+	*t = Tags{
+		BotID:                 tt.BotID,
+		IntegrationID:         tt.IntegrationID,
+		PremiumSubscriber:     premiumSubscriber,
+		SubscriptionListingID: tt.SubscriptionListingID,
+		AvailableForPurchase:  availableForPurchase,
+		GuildConnections:      guildConnections,
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (t *Tags) MarshalJSON() ([]byte, error) {
+	tt := tags{
+		BotID:                 t.BotID,
+		IntegrationID:         t.IntegrationID,
+		SubscriptionListingID: t.SubscriptionListingID,
+	}
+	if t.PremiumSubscriber {
+		tt.PremiumSubscriber = &null
+	}
+	if t.AvailableForPurchase {
+		tt.AvailableForPurchase = &null
+	}
+	if t.GuildConnections {
+		tt.GuildConnections = &null
+	}
+	return json.Marshal(tt)
+}
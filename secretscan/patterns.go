@@ -0,0 +1,76 @@
+package secretscan
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Kind is the kind of a secret.
+type Kind string
+
+// Kinds of secrets detected by the default patterns.
+const (
+	KindBotToken   Kind = "bot_token"
+	KindWebhookURL Kind = "webhook_url"
+)
+
+// Pattern is a pattern matching a kind of secret.
+type Pattern struct {
+	Kind   Kind
+	Regexp *regexp.Regexp
+}
+
+var (
+	// Tokens are made of the base64 encoded ID of the bot, a timestamp and an HMAC.
+	botTokenPattern = regexp.MustCompile(`\b[MNO][A-Za-z\d_-]{23,27}\.[A-Za-z\d_-]{6}\.[A-Za-z\d_-]{27,38}\b`)
+	// The first sub match is the ID of the webhook and the second its token.
+	webhookURLPattern = regexp.MustCompile(`https?://(?:(?:canary|ptb)\.)?discord(?:app)?\.com/api(?:/v\d+)?/webhooks/(\d+)/([A-Za-z\d_-]+)`)
+)
+
+// DefaultPatterns returns the patterns of secrets detected by default:
+// bot tokens and webhook URLs.
+func DefaultPatterns() []Pattern {
+	return []Pattern{
+		{Kind: KindBotToken, Regexp: botTokenPattern},
+		{Kind: KindWebhookURL, Regexp: webhookURLPattern},
+	}
+}
+
+// Finding is a secret found in some content.
+type Finding struct {
+	Kind Kind
+	// Match is the secret, as found in the content.
+	Match string
+	// WebhookID is set for webhook URLs.
+	WebhookID string
+}
+
+// Redacted returns the secret with all but its first characters
+// masked, so it can be logged or reported safely.
+func (f *Finding) Redacted() string {
+	const visible = 8
+	if len(f.Match) <= visible {
+		return strings.Repeat("*", len(f.Match))
+	}
+	return f.Match[:visible] + strings.Repeat("*", len(f.Match)-visible)
+}
+
+// Scan returns the secrets found in the given content using the given
+// patterns, or the default patterns if none are given.
+func Scan(content string, patterns ...Pattern) []Finding {
+	if len(patterns) == 0 {
+		patterns = DefaultPatterns()
+	}
+
+	var findings []Finding
+	for _, p := range patterns {
+		for _, m := range p.Regexp.FindAllStringSubmatch(content, -1) {
+			f := Finding{Kind: p.Kind, Match: m[0]}
+			if p.Kind == KindWebhookURL && len(m) > 1 {
+				f.WebhookID = m[1]
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
@@ -0,0 +1,168 @@
+/*
+Package secretscan provides an opt-in plugin scanning messages for secrets, such
+as bot tokens and webhook URLs, so security bots can delete them and alert their
+owners:
+
+	s := secretscan.New(func(d *secretscan.Detection) {
+		// Delete d.Message and alert its author...
+	}, secretscan.WithWebhookRevocation(true))
+	client, err := harmony.NewClient(token, harmony.WithPlugins(s))
+
+The patterns used are maintained in this package, see DefaultPatterns. The client
+needs the MESSAGE_CONTENT privileged intent to read the content of messages.
+*/
+package secretscan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/skwair/harmony"
+)
+
+// revokeTimeout is the time allowed to revoke the webhooks of a message.
+const revokeTimeout = 10 * time.Second
+
+// Detection describes secrets found in a message.
+type Detection struct {
+	Message  *harmony.Message
+	Findings []Finding
+	// IDs of the webhooks that were revoked, if webhook revocation is enabled.
+	RevokedWebhooks []string
+}
+
+// Scanner is a harmony.Plugin scanning created and edited messages for secrets.
+// Create one with New.
+type Scanner struct {
+	handler  func(*Detection)
+	patterns []Pattern
+	revoke   bool
+	onError  func(error)
+
+	client *harmony.Client
+	sub    *harmony.Subscription
+	userID string
+}
+
+// Option is a function that configures a Scanner.
+type Option func(*Scanner)
+
+// WithPatterns adds patterns of secrets to look for,
+// in addition to the default patterns.
+func WithPatterns(patterns ...Pattern) Option {
+	return func(s *Scanner) {
+		s.patterns = append(s.patterns, patterns...)
+	}
+}
+
+// WithWebhookRevocation sets whether webhooks found in messages are deleted if they
+// were created by the current user, which requires the 'MANAGE_WEBHOOKS' permission.
+// Webhooks created by others are never deleted. Disabled by default.
+func WithWebhookRevocation(yes bool) Option {
+	return func(s *Scanner) {
+		s.revoke = yes
+	}
+}
+
+// WithErrorHandler sets the function called when a webhook can not be
+// revoked. Errors are ignored by default.
+func WithErrorHandler(f func(err error)) Option {
+	return func(s *Scanner) {
+		s.onError = f
+	}
+}
+
+// New returns a new Scanner calling handler each time secrets are found in a message.
+// Messages sent by the current user are not scanned.
+func New(handler func(d *Detection), opts ...Option) *Scanner {
+	s := &Scanner{
+		handler:  handler,
+		patterns: DefaultPatterns(),
+		onError:  func(error) {},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Init implements the harmony.Plugin interface.
+func (s *Scanner) Init(c *harmony.Client) error {
+	s.client = c
+	return nil
+}
+
+// Start implements the harmony.Plugin interface.
+func (s *Scanner) Start(ctx context.Context) error {
+	u, err := s.client.CurrentUser().Get(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get current user: %w", err)
+	}
+	s.userID = u.ID
+
+	s.sub = s.client.Subscribe(s.handle)
+	return nil
+}
+
+// Stop implements the harmony.Plugin interface.
+func (s *Scanner) Stop(ctx context.Context) error {
+	return s.sub.Close(ctx)
+}
+
+func (s *Scanner) handle(e *harmony.Event) {
+	if e.Type != "MESSAGE_CREATE" && e.Type != "MESSAGE_UPDATE" {
+		return
+	}
+	if msg, ok := e.Data.(*harmony.Message); ok {
+		s.scan(msg)
+	}
+}
+
+func (s *Scanner) scan(msg *harmony.Message) {
+	if msg.Author != nil && msg.Author.ID == s.userID {
+		return
+	}
+
+	findings := Scan(msg.Content, s.patterns...)
+	if len(findings) == 0 {
+		return
+	}
+
+	d := &Detection{Message: msg, Findings: findings}
+	if s.revoke {
+		d.RevokedWebhooks = s.revokeWebhooks(findings)
+	}
+	s.handler(d)
+}
+
+// revokeWebhooks deletes webhooks found that were created by the current user
+// and returns their IDs.
+func (s *Scanner) revokeWebhooks(findings []Finding) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), revokeTimeout)
+	defer cancel()
+
+	var revoked []string
+	for _, f := range findings {
+		if f.WebhookID == "" {
+			continue
+		}
+
+		w := s.client.Webhook(f.WebhookID)
+		// Fetching the webhook fails if it was already deleted or if the
+		// current user can not manage it, in which case it is not ours.
+		wh, err := w.Get(ctx)
+		if err != nil || wh.User == nil || wh.User.ID != s.userID {
+			continue
+		}
+
+		if err = w.DeleteWithReason(ctx, "Secret scanner: webhook URL leaked in a message"); err != nil {
+			s.onError(fmt.Errorf("secretscan: could not revoke webhook %s: %w", f.WebhookID, err))
+			continue
+		}
+		revoked = append(revoked, f.WebhookID)
+	}
+	return revoked
+}
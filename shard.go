@@ -0,0 +1,99 @@
+package harmony
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Shard is a single Gateway shard, backed by its own Client. It gives manual
+// control over the shard, so advanced users can implement their own supervision
+// strategy, for instance restarting unhealthy shards or running shards across
+// several processes, instead of relying on a ShardManager.
+//
+// Unlike ShardManager.Connect, connecting shards does not wait for identify rate
+// limits: shards in the same bucket (see Bucket) must be connected at least 5
+// seconds apart. Create one with NewShard or get one from ShardManager.Shard.
+type Shard struct {
+	id     int
+	count  int
+	client *Client
+}
+
+// NewShard returns a new Shard with the given ID, out of count shards. Options
+// are applied to the client of the shard, WithSharding must not be used.
+func NewShard(token string, id, count int, opts ...ClientOption) (*Shard, error) {
+	if token == "" {
+		return nil, errors.New("harmony: a token is mandatory to create a shard")
+	}
+	if count < 1 || id < 0 || id >= count {
+		return nil, fmt.Errorf("harmony: invalid shard %d out of %d", id, count)
+	}
+
+	opts = append(append([]ClientOption{}, opts...), WithSharding(id, count))
+	c, err := NewClient(token, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Shard{id: id, count: count, client: c}, nil
+}
+
+// ID returns the ID of the shard.
+func (s *Shard) ID() int {
+	return s.id
+}
+
+// Count returns the total number of shards.
+func (s *Shard) Count() int {
+	return s.count
+}
+
+// Bucket returns the identify bucket of the shard, given the maximum identify
+// concurrency of the bot, available with Client.GatewayBotInfo. Shards of the
+// same bucket must not identify within 5 seconds of each other.
+func (s *Shard) Bucket(maxConcurrency int) int {
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+	return s.id % maxConcurrency
+}
+
+// Client returns the client of the shard, used to register event handlers and
+// send requests to the REST API.
+func (s *Shard) Client() *Client {
+	return s.client
+}
+
+// Connect connects the shard to the Gateway. See Client.Connect.
+func (s *Shard) Connect(ctx context.Context) error {
+	return s.client.Connect(ctx)
+}
+
+// Disconnect disconnects the shard from the Gateway. It can be connected again
+// afterwards. See Client.Disconnect.
+func (s *Shard) Disconnect() {
+	s.client.Disconnect()
+}
+
+// Connected returns whether the shard is connected to the Gateway.
+func (s *Shard) Connected() bool {
+	return s.client.Connected()
+}
+
+// SendPresence updates the presence of the bot on the guilds of this shard only.
+// It returns ErrGatewayNotConnected if the shard is not connected.
+func (s *Shard) SendPresence(status *Status) error {
+	return s.client.CurrentUser().SetStatus(status)
+}
+
+// RequestMembers requests members of a guild handled by this shard.
+// See Client.RequestGuildMembers.
+func (s *Shard) RequestMembers(ctx context.Context, guildID, query string, limit int, presences bool, userIDs []string, nonce string) (<-chan *GuildMembersChunk, error) {
+	return s.client.RequestGuildMembers(ctx, guildID, query, limit, presences, userIDs, nonce)
+}
+
+// Latency returns the latency of the shard. See Client.Latency.
+func (s *Shard) Latency() time.Duration {
+	return s.client.Latency()
+}
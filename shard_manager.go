@@ -0,0 +1,335 @@
+package harmony
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// identifyInterval is the interval at which each bucket
+// of shards is allowed to identify to the Gateway.
+const identifyInterval = 5 * time.Second
+
+// ShardManager runs a bot on multiple Gateway shards, one Client per shard.
+// It connects shards while respecting the identify concurrency allowed for the
+// bot, and lets handlers be registered once for all shards. Each shard
+// automatically reconnects on errors, like a regular Client does.
+// Create one with NewShardManager.
+type ShardManager struct {
+	token      string
+	opts       []ClientOption
+	shardCount int
+
+	mu      sync.Mutex
+	shards  []*Client
+	count   int           // Total number of shards, some may not be in shards yet.
+	stop    chan struct{} // Closed by Disconnect, nil if not connected.
+	setups  []func(*Client)
+	streams []chan *Event
+}
+
+// NewShardManager returns a new ShardManager that will run count shards, or the number
+// of shards recommended by Discord if count is 0. Options are applied to the
+// client of every shard, WithSharding must not be used.
+func NewShardManager(token string, count int, opts ...ClientOption) (*ShardManager, error) {
+	if token == "" {
+		return nil, errors.New("harmony: a token is mandatory to create a shard manager")
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("harmony: invalid shard count %d", count)
+	}
+
+	return &ShardManager{
+		token:      token,
+		opts:       opts,
+		shardCount: count,
+	}, nil
+}
+
+// Handle calls setup with the client of every shard, including shards started after
+// this call. Use it to register the same event handlers on all shards:
+//
+//	m.Handle(func(c *harmony.Client) {
+//		c.OnMessageCreate(func(msg *harmony.Message) {
+//			// ...
+//		})
+//	})
+func (m *ShardManager) Handle(setup func(c *Client)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.setups = append(m.setups, setup)
+	for _, c := range m.shards {
+		setup(c)
+	}
+}
+
+// Events is like Client.Events but the returned channel receives the events of
+// all shards. The Shard field of events tells which shard they were received on.
+// The channel is closed when the manager disconnects or when the returned function
+// is called, whichever comes first.
+func (m *ShardManager) Events(size int) (<-chan *Event, func()) {
+	ch := make(chan *Event, size)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.streams = append(m.streams, ch)
+	for _, c := range m.shards {
+		c.addEventStream(ch, false)
+	}
+	return ch, func() { m.removeEventStream(ch) }
+}
+
+// removeEventStream removes ch from the shards of the manager and closes
+// it. It is a no-op if ch was already removed.
+func (m *ShardManager) removeEventStream(ch chan *Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, s := range m.streams {
+		if s != ch {
+			continue
+		}
+		m.streams = append(m.streams[:i:i], m.streams[i+1:]...)
+		for _, c := range m.shards {
+			c.removeEventStream(ch)
+		}
+		close(ch)
+		return
+	}
+}
+
+// Connect starts all shards. Shards are connected in waves of as many shards as
+// the bot is allowed to identify concurrently, waiting between waves as required
+// by Discord. Each shard is available through Shards, ShardFor and Shard as soon
+// as it starts connecting. If a shard fails to connect, all shards are disconnected
+// and the error is returned. Calling Disconnect while shards are connecting stops
+// Connect, which then returns context.Canceled.
+func (m *ShardManager) Connect(ctx context.Context) error {
+	m.mu.Lock()
+	if m.stop != nil {
+		m.mu.Unlock()
+		return ErrAlreadyConnected
+	}
+	stop := make(chan struct{})
+	m.stop = stop
+	m.mu.Unlock()
+
+	// Connecting all shards can take a while, stop
+	// early if the manager is disconnected meanwhile.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	shards, err := m.connect(ctx, stop)
+	if err != nil {
+		m.mu.Lock()
+		if m.stop == stop {
+			m.shards, m.count, m.stop = nil, 0, nil
+		}
+		m.mu.Unlock()
+
+		disconnectAll(shards)
+		return err
+	}
+	return nil
+}
+
+// connect creates and connects all shards, returning the ones that were
+// created, even if an error occurred. The manager is only locked to publish
+// each shard, so it can be used while shards are connecting.
+func (m *ShardManager) connect(ctx context.Context, stop chan struct{}) ([]*Client, error) {
+	rest, err := NewClient(m.token, m.opts...)
+	if err != nil {
+		return nil, err
+	}
+	info, err := rest.GatewayBotInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get gateway information: %w", err)
+	}
+
+	count := m.shardCount
+	if count == 0 {
+		count = info.Shards
+	}
+	limit := info.SessionStartLimit
+	if limit.Remaining < count {
+		reset := time.Duration(limit.ResetAfter) * time.Millisecond
+		return nil, fmt.Errorf("not enough session starts remaining to start %d shards (%d remaining, resets in %s)", count, limit.Remaining, reset)
+	}
+	concurrency := limit.MaxConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	shards := make([]*Client, 0, count)
+
+	// Shards identify in buckets of ID % concurrency, each bucket being
+	// allowed to identify once every 5 seconds, so shards are connected
+	// in waves of consecutive IDs.
+	for start := 0; start < count; start += concurrency {
+		if start > 0 {
+			select {
+			case <-ctx.Done():
+				return shards, ctx.Err()
+			case <-rest.clock.After(identifyInterval):
+			}
+		}
+
+		end := start + concurrency
+		if end > count {
+			end = count
+		}
+		for id := start; id < end; id++ {
+			c, err := m.publishShard(stop, id, count)
+			if err != nil {
+				return shards, err
+			}
+			shards = append(shards, c)
+		}
+		if err = connectAll(ctx, shards[start:end]); err != nil {
+			return shards, err
+		}
+		rest.logger.Debugf("connected shards %d to %d (total=%d)", start, end-1, count)
+	}
+
+	return shards, nil
+}
+
+// publishShard creates the client of the shard with the given ID, with the handlers
+// and event streams registered so far, and adds it to the shards of the manager.
+// It returns context.Canceled if the manager was disconnected.
+func (m *ShardManager) publishShard(stop chan struct{}, id, count int) (*Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stop != stop {
+		return nil, context.Canceled
+	}
+
+	opts := append(append([]ClientOption{}, m.opts...), WithSharding(id, count))
+	c, err := NewClient(m.token, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for _, setup := range m.setups {
+		setup(c)
+	}
+	for _, ch := range m.streams {
+		c.addEventStream(ch, false)
+	}
+
+	m.shards = append(m.shards, c)
+	m.count = count
+	return c, nil
+}
+
+// connectAll concurrently connects the given clients and
+// returns the first error encountered, if any.
+func connectAll(ctx context.Context, clients []*Client) error {
+	errs := make([]error, len(clients))
+
+	var wg sync.WaitGroup
+	for i, c := range clients {
+		wg.Add(1)
+		go func(i int, c *Client) {
+			defer wg.Done()
+			if err := c.Connect(ctx); err != nil {
+				errs[i] = fmt.Errorf("shard %d: %w", c.shard[0], err)
+			}
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// disconnectAll concurrently disconnects the given clients.
+func disconnectAll(clients []*Client) {
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			c.Disconnect()
+		}(c)
+	}
+	wg.Wait()
+}
+
+// Disconnect disconnects all shards, stopping Connect if shards are still
+// connecting, and closes channels returned by Events. The manager can be
+// connected again afterwards.
+func (m *ShardManager) Disconnect() {
+	m.mu.Lock()
+	shards, streams := m.shards, m.streams
+	if m.stop != nil {
+		close(m.stop)
+	}
+	m.shards, m.count, m.stop, m.streams = nil, 0, nil, nil
+	m.mu.Unlock()
+
+	disconnectAll(shards)
+	for _, ch := range streams {
+		for _, c := range shards {
+			c.removeEventStream(ch)
+		}
+		close(ch)
+	}
+}
+
+// Shards returns the clients of all shards, indexed by shard ID.
+// It is empty if the manager is not connected.
+func (m *ShardManager) Shards() []*Client {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*Client(nil), m.shards...)
+}
+
+// ShardFor returns the client of the shard that receives the events of
+// the given guild, or nil if the manager is not connected, the shard did not
+// start connecting yet or the ID is invalid.
+func (m *ShardManager) ShardFor(guildID string) *Client {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id, err := strconv.ParseUint(guildID, 10, 64)
+	if err != nil || m.count == 0 {
+		return nil
+	}
+	// The shard may not have been created yet if shards are still connecting.
+	shard := (id >> 22) % uint64(m.count)
+	if shard >= uint64(len(m.shards)) {
+		return nil
+	}
+	return m.shards[shard]
+}
+
+// Shard returns the shard with the given ID, giving manual control over it, for
+// instance to reconnect it when it is unhealthy. It returns nil if the manager
+// is not connected or the ID is invalid.
+func (m *ShardManager) Shard(id int) *Shard {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if id < 0 || id >= len(m.shards) {
+		return nil
+	}
+	return &Shard{id: id, count: m.count, client: m.shards[id]}
+}
@@ -0,0 +1,346 @@
+/*
+Package slowmode schedules changes of the slowmode (rate limit per user) of
+channels, for instance to raise it during an event and lower it afterwards:
+
+	s := slowmode.New(client)
+	defer s.Close()
+
+	id, err := s.Add(slowmode.Schedule{
+		ChannelID:        channelID,
+		RateLimitPerUser: 30,
+		Start:            eventStart,
+		End:              eventEnd,
+	})
+
+When a schedule starts, the current slowmode of the channel is saved and restored
+when it ends. Schedules targeting the same channel can not overlap, Add returns a
+*ConflictError if they do. Schedules are kept in memory and are lost when the
+program stops.
+*/
+package slowmode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/clock"
+)
+
+// requestTimeout is the time allowed to apply or restore a slowmode.
+const requestTimeout = 10 * time.Second
+
+// Schedule describes a slowmode applied to a channel for a period of time.
+type Schedule struct {
+	ChannelID string
+	// RateLimitPerUser is the amount of seconds a user has to wait before
+	// sending another message, between 0 and channel.MaxRateLimitPerUser.
+	RateLimitPerUser int
+	Start            time.Time
+	End              time.Time
+	// Reason set in the audit log entries, optional.
+	Reason string
+}
+
+func (s *Schedule) overlaps(o *Schedule) bool {
+	return s.ChannelID == o.ChannelID && s.Start.Before(o.End) && o.Start.Before(s.End)
+}
+
+// ConflictError is returned when adding a schedule that overlaps with
+// other schedules of the same channel.
+type ConflictError struct {
+	ChannelID string
+	// IDs of the schedules the new one overlaps with.
+	Conflicts []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("slowmode: schedule conflicts with schedules %s of channel %s",
+		strings.Join(e.Conflicts, ", "), e.ChannelID)
+}
+
+// ErrModified is reported through the error handler when the slowmode of a
+// channel was changed by someone else while a schedule was active. In this
+// case, the previous slowmode is not restored so the change is kept.
+var ErrModified = errors.New("slowmode: slowmode was modified while the schedule was active")
+
+// Scheduler applies and restores the slowmode of channels according to schedules.
+// It is closed once the context of its client is done, see harmony.WithContext.
+// It is safe for concurrent use. Create one with New.
+type Scheduler struct {
+	client  *harmony.Client
+	clock   clock.Clock
+	onError func(error)
+
+	mu        sync.Mutex
+	schedules map[string]*entry
+	nextID    int
+
+	wg        sync.WaitGroup
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+type entry struct {
+	Schedule
+	active bool
+	cancel chan struct{}
+}
+
+// Option is a function that configures a Scheduler.
+type Option func(*Scheduler)
+
+// WithErrorHandler sets the function called when a slowmode can not be
+// applied or restored. Errors are ignored by default.
+func WithErrorHandler(f func(err error)) Option {
+	return func(s *Scheduler) {
+		s.onError = f
+	}
+}
+
+// WithClock sets the clock used by the scheduler, mainly for testing purposes.
+// Defaults to the clock of the client.
+func WithClock(c clock.Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = c
+	}
+}
+
+// New returns a new Scheduler changing slowmodes through the given client, which
+// requires the 'MANAGE_CHANNELS' permission in the channels of schedules.
+func New(c *harmony.Client, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		client:    c,
+		clock:     c.Clock(),
+		onError:   func(error) {},
+		schedules: make(map[string]*entry),
+		closed:    make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if done := c.Context().Done(); done != nil {
+		go s.closeOnDone(done)
+	}
+
+	return s
+}
+
+// Add adds a schedule and returns its ID. If the schedule already started,
+// its slowmode is applied right away. It returns a *ConflictError if the
+// schedule overlaps with another schedule of the same channel.
+func (s *Scheduler) Add(sch Schedule) (string, error) {
+	if err := s.validate(&sch); err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.closed:
+		return "", errors.New("slowmode: scheduler is closed")
+	default:
+	}
+
+	var conflicts []string
+	for id, e := range s.schedules {
+		if e.overlaps(&sch) {
+			conflicts = append(conflicts, id)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return "", &ConflictError{ChannelID: sch.ChannelID, Conflicts: conflicts}
+	}
+
+	s.nextID++
+	id := strconv.Itoa(s.nextID)
+	e := &entry{Schedule: sch, cancel: make(chan struct{})}
+	s.schedules[id] = e
+
+	s.wg.Add(1)
+	go s.run(id, e)
+
+	return id, nil
+}
+
+func (s *Scheduler) validate(sch *Schedule) error {
+	var problems []string
+
+	if sch.ChannelID == "" {
+		problems = append(problems, "channel ID is required")
+	}
+	if sch.RateLimitPerUser < 0 || sch.RateLimitPerUser > channel.MaxRateLimitPerUser {
+		problems = append(problems, fmt.Sprintf("rate limit per user must be between 0 and %d", channel.MaxRateLimitPerUser))
+	}
+	if !sch.End.After(sch.Start) {
+		problems = append(problems, "end must be after start")
+	} else if !sch.End.After(s.clock.Now()) {
+		problems = append(problems, "end must be in the future")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid slowmode schedule: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Cancel cancels the schedule with the given ID. If it is active, the previous
+// slowmode of its channel is restored. It returns false if there is no such
+// schedule, for instance because it already ended.
+func (s *Scheduler) Cancel(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.schedules[id]
+	if !ok {
+		return false
+	}
+	delete(s.schedules, id)
+	close(e.cancel)
+	return true
+}
+
+// Schedules returns the pending and active schedules of the given channel,
+// by ID.
+func (s *Scheduler) Schedules(channelID string) map[string]Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules := make(map[string]Schedule)
+	for id, e := range s.schedules {
+		if e.ChannelID == channelID {
+			schedules[id] = e.Schedule
+		}
+	}
+	return schedules
+}
+
+// Active returns whether the schedule with the given ID is active, meaning
+// its slowmode is currently applied.
+func (s *Scheduler) Active(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.schedules[id]
+	return ok && e.active
+}
+
+// Close cancels all schedules, restoring the previous slowmode of channels
+// with an active schedule, and waits for them to be restored.
+func (s *Scheduler) Close() {
+	s.close()
+	s.wg.Wait()
+}
+
+// close cancels all schedules without waiting for them.
+func (s *Scheduler) close() {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		close(s.closed)
+		s.mu.Unlock()
+	})
+}
+
+// closeOnDone closes the scheduler once done is closed.
+func (s *Scheduler) closeOnDone(done <-chan struct{}) {
+	select {
+	case <-done:
+		s.close()
+	case <-s.closed:
+	}
+}
+
+func (s *Scheduler) run(id string, e *entry) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		if s.schedules[id] == e {
+			delete(s.schedules, id)
+		}
+		s.mu.Unlock()
+	}()
+
+	if !s.wait(e, e.Start) {
+		return
+	}
+
+	previous, err := s.apply(e)
+	if err != nil {
+		s.onError(fmt.Errorf("slowmode: could not apply slowmode to channel %s: %w", e.ChannelID, err))
+		return
+	}
+	s.mu.Lock()
+	e.active = true
+	s.mu.Unlock()
+
+	s.wait(e, e.End)
+
+	if err = s.restore(e, previous); err != nil {
+		s.onError(fmt.Errorf("slowmode: could not restore slowmode of channel %s: %w", e.ChannelID, err))
+	}
+}
+
+// wait waits until the given time and returns true, or returns false if the
+// schedule is cancelled or the scheduler is closed before.
+func (s *Scheduler) wait(e *entry, t time.Time) bool {
+	d := s.clock.Until(t)
+	if d <= 0 {
+		return true
+	}
+
+	select {
+	case <-s.clock.After(d):
+		return true
+	case <-e.cancel:
+		return false
+	case <-s.closed:
+		return false
+	}
+}
+
+// apply applies the slowmode of the schedule and returns the previous one.
+func (s *Scheduler) apply(e *entry) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	ch, err := s.client.Channel(e.ChannelID).Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	settings := channel.NewSettings(channel.WithRateLimitPerUser(e.RateLimitPerUser))
+	if _, err = s.client.Channel(e.ChannelID).ModifyWithReason(ctx, settings, e.Reason); err != nil {
+		return 0, err
+	}
+	return ch.RateLimitPerUser, nil
+}
+
+// restore restores the previous slowmode of the channel of the schedule,
+// unless it was modified since the schedule started.
+func (s *Scheduler) restore(e *entry, previous int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	ch, err := s.client.Channel(e.ChannelID).Get(ctx)
+	if err != nil {
+		return err
+	}
+	if ch.RateLimitPerUser != e.RateLimitPerUser {
+		return ErrModified
+	}
+
+	settings := channel.NewSettings(channel.WithRateLimitPerUser(previous))
+	_, err = s.client.Channel(e.ChannelID).ModifyWithReason(ctx, settings, e.Reason)
+	return err
+}
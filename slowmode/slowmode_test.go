@@ -0,0 +1,108 @@
+package slowmode
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/clock"
+	"github.com/skwair/harmony/harmonytest"
+)
+
+// fakeChannel is a channel whose slowmode can be read and modified over REST.
+type fakeChannel struct {
+	mu               sync.Mutex
+	rateLimitPerUser int
+}
+
+func (ch *fakeChannel) get(w http.ResponseWriter, r *http.Request) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	harmonytest.WriteJSON(w, http.StatusOK, &harmony.Channel{ID: "1", RateLimitPerUser: ch.rateLimitPerUser})
+}
+
+func (ch *fakeChannel) modify(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RateLimitPerUser int `json:"rate_limit_per_user"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ch.mu.Lock()
+	ch.rateLimitPerUser = body.RateLimitPerUser
+	ch.mu.Unlock()
+
+	ch.get(w, r)
+}
+
+func (ch *fakeChannel) slowmode() int {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	return ch.rateLimitPerUser
+}
+
+func TestSchedulerClosedWithClientContext(t *testing.T) {
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	ch := &fakeChannel{rateLimitPerUser: 5}
+	srv.Handle(http.MethodGet, "/channels/:channel", ch.get)
+	srv.Handle(http.MethodPatch, "/channels/:channel", ch.modify)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clk := clock.NewMock(time.Unix(0, 0))
+	c, err := srv.NewClient(harmony.WithContext(ctx), harmony.WithClock(clk))
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	var errs []error
+	s := New(c, WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	defer s.Close()
+
+	id, err := s.Add(Schedule{ChannelID: "1", RateLimitPerUser: 30, Start: clk.Now(), End: clk.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("could not add schedule: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !s.Active(id) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected schedule to become active")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if slowmode := ch.slowmode(); slowmode != 30 {
+		t.Fatalf("expected slowmode to be 30, got %d", slowmode)
+	}
+
+	// The schedule ends early, without advancing the clock.
+	cancel()
+	for len(s.Schedules("1")) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected schedule to end once the context of the client is done")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.Close()
+
+	if slowmode := ch.slowmode(); slowmode != 5 {
+		t.Errorf("expected slowmode to be restored to 5, got %d", slowmode)
+	}
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if _, err = s.Add(Schedule{ChannelID: "1", Start: clk.Now(), End: clk.Now().Add(time.Hour)}); err == nil {
+		t.Error("expected schedules not to be added once the context of the client is done")
+	}
+}
@@ -17,3 +17,12 @@ func CreationTimeOf(id string) (time.Time, error) {
 
 	return time.Unix(ts/1000, 0), nil
 }
+
+// idLess reports whether the Discord ID a was created before b. IDs are
+// compared as numbers, without parsing them.
+func idLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
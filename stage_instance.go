@@ -0,0 +1,213 @@
+package harmony
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/internal/endpoint"
+)
+
+// StageInstance holds information about a live stage, in a stage channel.
+type StageInstance struct {
+	ID                    string                    `json:"id"`
+	GuildID               string                    `json:"guild_id"`
+	ChannelID             string                    `json:"channel_id"`
+	Topic                 string                    `json:"topic"`
+	PrivacyLevel          channel.StagePrivacyLevel `json:"privacy_level"`
+	GuildScheduledEventID string                    `json:"guild_scheduled_event_id"`
+}
+
+// NewStageInstance is like NewStageInstanceWithReason but with no particular reason.
+func (r *ChannelResource) NewStageInstance(ctx context.Context, settings *channel.StageInstanceSettings) (*StageInstance, error) {
+	return r.NewStageInstanceWithReason(ctx, settings, "")
+}
+
+// NewStageInstanceWithReason starts a stage instance in the stage channel. Settings
+// must have a topic. The current user must be a moderator of the stage channel: it
+// requires the 'MANAGE_CHANNELS', 'MUTE_MEMBERS' and 'MOVE_MEMBERS' permissions.
+// Fires a Stage Instance Create Gateway event.
+// The given reason will be set in the audit log entry for this action.
+func (r *ChannelResource) NewStageInstanceWithReason(ctx context.Context, settings *channel.StageInstanceSettings, reason string) (*StageInstance, error) {
+	if settings.Topic == nil {
+		return nil, errors.New("invalid stage instance settings: topic is required")
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	st := struct {
+		ChannelID string `json:"channel_id"`
+		*channel.StageInstanceSettings
+	}{
+		ChannelID:             r.channelID,
+		StageInstanceSettings: settings,
+	}
+	b, err := json.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+
+	e := endpoint.CreateStageInstance()
+	resp, err := r.client.doReqWithHeader(ctx, e, jsonPayload(b), reasonHeader(reason))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var si StageInstance
+	if err = json.NewDecoder(resp.Body).Decode(&si); err != nil {
+		return nil, err
+	}
+	return &si, nil
+}
+
+// StageInstance returns the live stage instance of the stage channel.
+func (r *ChannelResource) StageInstance(ctx context.Context) (*StageInstance, error) {
+	e := endpoint.GetStageInstance(r.channelID)
+	resp, err := r.client.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var si StageInstance
+	if err = json.NewDecoder(resp.Body).Decode(&si); err != nil {
+		return nil, err
+	}
+	return &si, nil
+}
+
+// ModifyStageInstance is like ModifyStageInstanceWithReason but with no particular reason.
+func (r *ChannelResource) ModifyStageInstance(ctx context.Context, settings *channel.StageInstanceSettings) (*StageInstance, error) {
+	return r.ModifyStageInstanceWithReason(ctx, settings, "")
+}
+
+// ModifyStageInstanceWithReason modifies the topic or privacy level of the live stage
+// instance of the stage channel. The current user must be a moderator of the stage
+// channel. Fires a Stage Instance Update Gateway event.
+// The given reason will be set in the audit log entry for this action.
+func (r *ChannelResource) ModifyStageInstanceWithReason(ctx context.Context, settings *channel.StageInstanceSettings, reason string) (*StageInstance, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	e := endpoint.ModifyStageInstance(r.channelID)
+	resp, err := r.client.doReqWithHeader(ctx, e, jsonPayload(b), reasonHeader(reason))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var si StageInstance
+	if err = json.NewDecoder(resp.Body).Decode(&si); err != nil {
+		return nil, err
+	}
+	return &si, nil
+}
+
+// DeleteStageInstance is like DeleteStageInstanceWithReason but with no particular reason.
+func (r *ChannelResource) DeleteStageInstance(ctx context.Context) error {
+	return r.DeleteStageInstanceWithReason(ctx, "")
+}
+
+// DeleteStageInstanceWithReason ends the live stage instance of the stage channel.
+// The current user must be a moderator of the stage channel. Fires a Stage Instance
+// Delete Gateway event.
+// The given reason will be set in the audit log entry for this action.
+func (r *ChannelResource) DeleteStageInstanceWithReason(ctx context.Context, reason string) error {
+	e := endpoint.DeleteStageInstance(r.channelID)
+	resp, err := r.client.doReqWithHeader(ctx, e, nil, reasonHeader(reason))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return apiError(resp)
+	}
+	return nil
+}
+
+// RequestToSpeak requests to speak, or cancels the request if request is false, in
+// the given stage channel of the guild, where the current user must be connected.
+// Requires the 'REQUEST_TO_SPEAK' permission. Fires a Voice State Update Gateway
+// event.
+func (r *GuildResource) RequestToSpeak(ctx context.Context, channelID string, request bool) error {
+	st := struct {
+		ChannelID               string     `json:"channel_id"`
+		RequestToSpeakTimestamp *time.Time `json:"request_to_speak_timestamp"`
+	}{
+		ChannelID: channelID,
+	}
+	if request {
+		now := r.client.clock.Now()
+		st.RequestToSpeakTimestamp = &now
+	}
+	return r.modifyVoiceState(ctx, endpoint.ModifyCurrentUserVoiceState(r.guildID), st)
+}
+
+// BecomeSpeaker makes the current user a speaker, or moves it back to the audience
+// if speaker is false, in the given stage channel of the guild, where it must be
+// connected. Becoming a speaker requires the 'MUTE_MEMBERS' permission. Fires a
+// Voice State Update Gateway event.
+func (r *GuildResource) BecomeSpeaker(ctx context.Context, channelID string, speaker bool) error {
+	return r.setSpeaker(ctx, endpoint.ModifyCurrentUserVoiceState(r.guildID), channelID, speaker)
+}
+
+// SetSpeaker invites the given user to speak, or moves them back to the audience if
+// speaker is false, in the given stage channel of the guild, where they must be
+// connected. This is how requests to speak are approved. Requires the 'MUTE_MEMBERS'
+// permission. Fires a Voice State Update Gateway event.
+func (r *GuildResource) SetSpeaker(ctx context.Context, channelID, userID string, speaker bool) error {
+	return r.setSpeaker(ctx, endpoint.ModifyUserVoiceState(r.guildID, userID), channelID, speaker)
+}
+
+func (r *GuildResource) setSpeaker(ctx context.Context, e *endpoint.Endpoint, channelID string, speaker bool) error {
+	st := struct {
+		ChannelID string `json:"channel_id"`
+		Suppress  bool   `json:"suppress"`
+	}{
+		ChannelID: channelID,
+		Suppress:  !speaker,
+	}
+	return r.modifyVoiceState(ctx, e, st)
+}
+
+func (r *GuildResource) modifyVoiceState(ctx context.Context, e *endpoint.Endpoint, st interface{}) error {
+	b, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.client.doReq(ctx, e, jsonPayload(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return apiError(resp)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+/*
+Package starboard provides a starboard plugin: messages that receive enough
+star reactions are reposted to a dedicated channel, with a link to the
+original message. The number of stars shown on the starboard is updated as
+reactions are added and removed.
+
+The starboard is configured per guild through a ConfigStore:
+
+	store := &starboard.MemoryStore{}
+	store.Set(guildID, starboard.Config{ChannelID: starboardChannelID, Threshold: 5})
+
+	client, err := harmony.NewClient(token, harmony.WithPlugins(starboard.New(store)))
+
+The client needs the GUILD_MESSAGE_REACTIONS intent, and the MESSAGE_CONTENT
+intent to repost the content of messages.
+*/
+package starboard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/embed"
+)
+
+// Default configuration values.
+const (
+	DefaultEmoji     = "⭐"
+	DefaultThreshold = 3
+)
+
+// requestTimeout is the time allowed to process a single event.
+const requestTimeout = 10 * time.Second
+
+// Starboard is a harmony.Plugin reposting starred messages. Create one with New.
+type Starboard struct {
+	store   ConfigStore
+	onError func(err error)
+
+	client *harmony.Client
+	sub    *harmony.Subscription
+
+	// posts maps IDs of starred messages to their post on the starboard.
+	// It is only accessed by the subscription processing events.
+	posts map[string]post
+}
+
+// post is a message posted on a starboard.
+type post struct {
+	channelID string
+	messageID string
+}
+
+// Option is a function that configures a Starboard.
+type Option func(*Starboard)
+
+// WithErrorHandler sets the function called when the starboard fails to
+// process an event, for instance because it lacks permissions.
+// Errors are ignored by default.
+func WithErrorHandler(f func(err error)) Option {
+	return func(s *Starboard) {
+		s.onError = f
+	}
+}
+
+// New returns a new starboard reading the configuration of guilds from the given store.
+// Starboard posts are tracked in memory, so stars received after a restart on messages
+// posted before do not update the existing post.
+func New(store ConfigStore, opts ...Option) *Starboard {
+	s := &Starboard{
+		store:   store,
+		onError: func(error) {},
+		posts:   make(map[string]post),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Init implements the harmony.Plugin interface.
+func (s *Starboard) Init(c *harmony.Client) error {
+	s.client = c
+	return nil
+}
+
+// Start implements the harmony.Plugin interface.
+func (s *Starboard) Start(_ context.Context) error {
+	s.sub = s.client.Subscribe(s.handle)
+	return nil
+}
+
+// Stop implements the harmony.Plugin interface.
+func (s *Starboard) Stop(ctx context.Context) error {
+	return s.sub.Close(ctx)
+}
+
+func (s *Starboard) handle(e *harmony.Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	var err error
+	switch d := e.Data.(type) {
+	case *harmony.MessageReaction:
+		err = s.update(ctx, d.GuildID, d.ChannelID, d.MessageID, d.Emoji)
+	case *harmony.MessageReactionRemoveEmoji:
+		err = s.update(ctx, d.GuildID, d.ChannelID, d.MessageID, d.Emoji)
+	case *harmony.MessageReactionRemoveAll:
+		err = s.update(ctx, d.GuildID, d.ChannelID, d.MessageID, nil)
+	case *harmony.MessageDelete:
+		err = s.remove(ctx, d.MessageID)
+	default:
+		return
+	}
+	if err != nil {
+		s.onError(fmt.Errorf("starboard: %s event: %w", e.Type, err))
+	}
+}
+
+// update posts, edits or removes the starboard post of the given message
+// depending on its current number of stars. If emoji is not nil, messages
+// are only updated if it is the star emoji of the guild.
+func (s *Starboard) update(ctx context.Context, guildID, channelID, messageID string, emoji *harmony.Emoji) error {
+	if guildID == "" {
+		return nil
+	}
+
+	cfg, err := s.store.GuildConfig(ctx, guildID)
+	if err != nil {
+		return fmt.Errorf("could not get configuration of guild %s: %w", guildID, err)
+	}
+	if cfg == nil || cfg.ChannelID == "" || cfg.ChannelID == channelID {
+		return nil
+	}
+	star, threshold := cfg.Emoji, cfg.Threshold
+	if star == "" {
+		star = DefaultEmoji
+	}
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	if emoji != nil && !isStar(emoji, star) {
+		return nil
+	}
+
+	msg, err := s.client.Channel(channelID).Message(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, r := range msg.Reactions {
+		if r.Emoji != nil && isStar(r.Emoji, star) {
+			count = r.Count
+			break
+		}
+	}
+
+	p, posted := s.posts[messageID]
+	if count < threshold {
+		if posted {
+			return s.remove(ctx, messageID)
+		}
+		return nil
+	}
+
+	content := fmt.Sprintf("%s **%d** <#%s>", displayEmoji(star), count, channelID)
+	if posted {
+		_, err = s.client.Channel(p.channelID).Edit(ctx, p.messageID, harmony.WithContent(content))
+		return err
+	}
+
+	m, err := s.client.Channel(cfg.ChannelID).Send(ctx,
+		harmony.WithContent(content),
+		harmony.WithEmbed(starEmbed(msg, guildID, cfg.Color)),
+	)
+	if err != nil {
+		return err
+	}
+	s.posts[messageID] = post{channelID: cfg.ChannelID, messageID: m.ID}
+	return nil
+}
+
+// remove deletes the starboard post of the given message, if any.
+func (s *Starboard) remove(ctx context.Context, messageID string) error {
+	p, ok := s.posts[messageID]
+	if !ok {
+		return nil
+	}
+
+	delete(s.posts, messageID)
+	return s.client.Channel(p.channelID).DeleteMessage(ctx, p.messageID)
+}
+
+// starEmbed returns the embed reposting the given message on the starboard.
+func starEmbed(msg *harmony.Message, guildID string, color int) *embed.Embed {
+	b := embed.New().
+		Description(msg.Content).
+		Color(color).
+		Timestamp(msg.Timestamp).
+		Fields(embed.NewField().Name("Source").Value("[Jump to message](" + JumpURL(guildID, msg.ChannelID, msg.ID) + ")").Build()).
+		Footer(embed.NewFooter().Text(msg.ID).Build())
+
+	if msg.Author != nil {
+		b.Author(embed.NewAuthor().Name(msg.Author.Username).IconURL(msg.Author.AvatarURL()).Build())
+	}
+	// Show the first image attached to the message, if any.
+	for _, a := range msg.Attachments {
+		if a.Width > 0 && a.Height > 0 {
+			b.Image(embed.NewImage(a.URL))
+			break
+		}
+	}
+
+	return b.Build()
+}
+
+// JumpURL returns the URL of the given message, which users can click to jump to it.
+func JumpURL(guildID, channelID, messageID string) string {
+	return fmt.Sprintf("https://discord.com/channels/%s/%s/%s", guildID, channelID, messageID)
+}
+
+// isStar reports whether e is the given star emoji.
+func isStar(e *harmony.Emoji, star string) bool {
+	if e.ID != "" {
+		return strings.HasSuffix(star, ":"+e.ID)
+	}
+	return e.Name == star
+}
+
+// displayEmoji returns the given emoji in the format used to show it in messages.
+func displayEmoji(emoji string) string {
+	if strings.Contains(emoji, ":") {
+		return "<:" + emoji + ">"
+	}
+	return emoji
+}
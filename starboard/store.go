@@ -0,0 +1,69 @@
+package starboard
+
+import (
+	"context"
+	"sync"
+)
+
+// Config is the starboard configuration of a guild.
+type Config struct {
+	// ChannelID is the channel starred messages are posted to.
+	// The starboard is disabled for the guild if it is empty.
+	ChannelID string
+	// Emoji counted as stars, in the format used to add reactions: the
+	// emoji itself for standard emojis or "name:id" for custom ones.
+	// Defaults to DefaultEmoji.
+	Emoji string
+	// Threshold is the number of stars a message needs to be posted
+	// to the starboard. Defaults to DefaultThreshold.
+	Threshold int
+	// Color of the embeds of starboard messages.
+	Color int
+}
+
+// ConfigStore provides the starboard configuration of guilds.
+// It must be safe for concurrent use.
+type ConfigStore interface {
+	// GuildConfig returns the starboard configuration of the given guild,
+	// or nil if the starboard is not configured for this guild.
+	GuildConfig(ctx context.Context, guildID string) (*Config, error)
+}
+
+// MemoryStore is a ConfigStore that keeps configurations in memory.
+// The zero value is ready to use.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	configs map[string]Config
+}
+
+// GuildConfig implements the ConfigStore interface.
+func (s *MemoryStore) GuildConfig(_ context.Context, guildID string) (*Config, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cfg, ok := s.configs[guildID]
+	if !ok {
+		return nil, nil
+	}
+	return &cfg, nil
+}
+
+// Set sets the starboard configuration of the given guild.
+func (s *MemoryStore) Set(guildID string, cfg Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.configs == nil {
+		s.configs = make(map[string]Config)
+	}
+	s.configs[guildID] = cfg
+}
+
+// Delete removes the starboard configuration of the given guild,
+// disabling the starboard for this guild.
+func (s *MemoryStore) Delete(guildID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.configs, guildID)
+}
@@ -1,10 +1,16 @@
 package harmony
 
 import (
+	"reflect"
+	"runtime"
+	"sort"
 	"sync"
 	"time"
 
+	"go.uber.org/atomic"
+
 	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/internal/intern"
 	"github.com/skwair/harmony/voice"
 )
 
@@ -13,108 +19,125 @@ import (
 // Objects returned by State methods are snapshots of original objects used
 // internally by the State. This means they are safe to be used and modified
 // but they won't be updated as new events are received.
+// Objects are stored in memory by default, see WithStateBackend to store
+// them elsewhere.
 type State struct {
-	mu sync.RWMutex
-
-	currentUser       *User
-	users             map[string]*User
-	guilds            map[string]*Guild
-	presences         map[string]*Presence // Presence by user ID.
-	channels          map[string]*Channel
-	dms               map[string]*Channel
-	groups            map[string]*Channel
-	unavailableGuilds map[string]*UnavailableGuild
-
-	rtt time.Duration
+	// Each type of object has its own lock, so reading objects of a type
+	// is not blocked by events updating objects of other types. Methods
+	// needing several locks acquire them in the order they are declared
+	// in, to prevent deadlocks.
+	guildsMu    sync.RWMutex // Guilds, unavailable guilds and integrations.
+	channelsMu  sync.RWMutex // Channels, DMs, group DMs and webhooks.
+	usersMu     sync.RWMutex // Users, including the current user.
+	presencesMu sync.RWMutex
+
+	// Webhooks and integrations are not sent through the Gateway.
+	// They are cached in the backend when fetched from the REST API
+	// and invalidated when Discord notifies us they changed.
+	backend StateBackend
+
+	rtt *atomic.Duration
+
+	// Interned strings of stored objects, nil if
+	// interning is disabled. See WithStateInterning.
+	strings *intern.Pool
+
+	// Last messages of each channel, nil if the message
+	// cache is disabled. See WithMessageCacheSize. Its lock
+	// is acquired after all the others.
+	messages *messageCache
 
 	// NOTE: consider adding statistics such as the uptime, ping, number
 	// of voice connections, etc... in the state.
 }
 
-// newState returns a new initialized state, ready to be used.
-func newState() *State {
-	return &State{
-		users:             make(map[string]*User),
-		guilds:            make(map[string]*Guild),
-		presences:         make(map[string]*Presence),
-		channels:          make(map[string]*Channel),
-		dms:               make(map[string]*Channel),
-		groups:            make(map[string]*Channel),
-		unavailableGuilds: make(map[string]*UnavailableGuild),
+// newState returns a new initialized state storing
+// its objects in the given backend, ready to be used.
+// If interning is true, strings of stored objects are
+// interned.
+func newState(b StateBackend, interning bool) *State {
+	s := &State{backend: b, rtt: atomic.NewDuration(0)}
+	if interning {
+		s.strings = intern.New()
 	}
+	return s
 }
 
 // CurrentUser returns the current user from the state.
 func (s *State) CurrentUser() *User {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.usersMu.RLock()
+	defer s.usersMu.RUnlock()
 
-	return s.currentUser.Clone()
+	return s.backend.CurrentUser().Clone()
 }
 
 // User returns a user given its ID from the state.
 func (s *State) User(id string) *User {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.usersMu.RLock()
+	defer s.usersMu.RUnlock()
 
-	return s.users[id].Clone()
+	return s.backend.User(id).Clone()
 }
 
 // Guild returns a guild given its ID from the state.
 func (s *State) Guild(id string) *Guild {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	return s.guilds[id].Clone()
+	s.guildsMu.RLock()
+	defer s.guildsMu.RUnlock()
+	s.usersMu.RLock()
+	defer s.usersMu.RUnlock()
+	s.presencesMu.RLock()
+	defer s.presencesMu.RUnlock()
+
+	return s.cloneGuild(s.backend.Guild(id))
 }
 
 // Channel returns a channel given its ID from the state.
 func (s *State) Channel(id string) *Channel {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.channelsMu.RLock()
+	defer s.channelsMu.RUnlock()
 
-	return s.channels[id].Clone()
+	return s.backend.Channel(id).Clone()
 }
 
 // GroupDM returns a group DM given its ID from the state.
 func (s *State) GroupDM(id string) *Channel {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.channelsMu.RLock()
+	defer s.channelsMu.RUnlock()
 
-	return s.groups[id].Clone()
+	return s.backend.GroupDM(id).Clone()
 }
 
 // DM returns a DM given its ID from the state.
 func (s *State) DM(id string) *Channel {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.channelsMu.RLock()
+	defer s.channelsMu.RUnlock()
 
-	return s.dms[id].Clone()
+	return s.backend.DM(id).Clone()
 }
 
 // Presence returns a presence given a user ID from the state.
 func (s *State) Presence(userID string) *Presence {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.presencesMu.RLock()
+	defer s.presencesMu.RUnlock()
 
-	return s.presences[userID].Clone()
+	return s.backend.Presence(userID).Clone()
 }
 
 // UnavailableGuild returns an unavailable guild given its ID from the state.
 func (s *State) UnavailableGuild(id string) *UnavailableGuild {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.guildsMu.RLock()
+	defer s.guildsMu.RUnlock()
 
-	return s.unavailableGuilds[id].Clone()
+	return s.backend.UnavailableGuild(id).Clone()
 }
 
 // Users returns a map of user ID to user from the state.
 func (s *State) Users() map[string]*User {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.usersMu.RLock()
+	defer s.usersMu.RUnlock()
 
 	newMap := make(map[string]*User)
-	for k, v := range s.users {
+	for k, v := range s.backend.Users() {
 		newMap[k] = v.Clone()
 	}
 
@@ -123,50 +146,86 @@ func (s *State) Users() map[string]*User {
 
 // Guilds returns a map of guild ID to guild from the state.
 func (s *State) Guilds() map[string]*Guild {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.guildsMu.RLock()
+	defer s.guildsMu.RUnlock()
+	s.usersMu.RLock()
+	defer s.usersMu.RUnlock()
+	s.presencesMu.RLock()
+	defer s.presencesMu.RUnlock()
 
 	newMap := make(map[string]*Guild)
-	for k, v := range s.guilds {
-		newMap[k] = v.Clone()
+	for k, v := range s.backend.Guilds() {
+		newMap[k] = s.cloneGuild(v)
 	}
 
 	return newMap
 }
 
-// Channels returns a map of channels ID to channels from the state.
-func (s *State) Channels() map[string]*Channel {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// cloneGuild returns a clone of the given guild, along with its members and
+// presences which are stored apart from it. Members and presences are sorted
+// by user ID. The guilds, users and presences locks must be held.
+func (s *State) cloneGuild(g *Guild) *Guild {
+	if g == nil {
+		return nil
+	}
 
-	newMap := make(map[string]*Channel)
-	for k, v := range s.channels {
-		newMap[k] = v.Clone()
+	clone := g.Clone()
+
+	if members := s.backend.Members(g.ID); len(members) > 0 {
+		clone.Members = make([]GuildMember, 0, len(members))
+		for userID, m := range members {
+			member := m.Clone()
+			member.User = s.backend.User(userID).Clone()
+			if member.User == nil {
+				member.User = &User{ID: userID}
+			}
+			clone.Members = append(clone.Members, *member)
+		}
+		sort.Slice(clone.Members, func(i, j int) bool {
+			return idLess(clone.Members[i].User.ID, clone.Members[j].User.ID)
+		})
 	}
 
-	return newMap
+	if presences := s.backend.GuildPresences(g.ID); len(presences) > 0 {
+		clone.Presences = make([]Presence, 0, len(presences))
+		for _, p := range presences {
+			clone.Presences = append(clone.Presences, *p.Clone())
+		}
+		sort.Slice(clone.Presences, func(i, j int) bool {
+			return idLess(clone.Presences[i].User.ID, clone.Presences[j].User.ID)
+		})
+	}
+
+	return clone
+}
+
+// Channels returns a map of channels ID to channels from the state.
+func (s *State) Channels() map[string]*Channel {
+	s.channelsMu.RLock()
+	defer s.channelsMu.RUnlock()
+
+	return cloneChannels(s.backend.Channels())
 }
 
 // GroupDMs returns a map of group DM ID to group DM from the state.
 func (s *State) GroupDMs() map[string]*Channel {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	newMap := make(map[string]*Channel)
-	for k, v := range s.channels {
-		newMap[k] = v.Clone()
-	}
+	s.channelsMu.RLock()
+	defer s.channelsMu.RUnlock()
 
-	return newMap
+	return cloneChannels(s.backend.GroupDMs())
 }
 
 // DMs returns a map of DM ID to DM from the state.
 func (s *State) DMs() map[string]*Channel {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.channelsMu.RLock()
+	defer s.channelsMu.RUnlock()
 
+	return cloneChannels(s.backend.DMs())
+}
+
+func cloneChannels(m map[string]*Channel) map[string]*Channel {
 	newMap := make(map[string]*Channel)
-	for k, v := range s.channels {
+	for k, v := range m {
 		newMap[k] = v.Clone()
 	}
 
@@ -175,11 +234,11 @@ func (s *State) DMs() map[string]*Channel {
 
 // Presences returns a map of user ID to presence from the state.
 func (s *State) Presences() map[string]*Presence {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.presencesMu.RLock()
+	defer s.presencesMu.RUnlock()
 
 	newMap := make(map[string]*Presence)
-	for k, v := range s.presences {
+	for k, v := range s.backend.Presences() {
 		newMap[k] = v.Clone()
 	}
 
@@ -188,67 +247,118 @@ func (s *State) Presences() map[string]*Presence {
 
 // UnavailableGuilds returns a map of guild ID to unavailable guild from the state.
 func (s *State) UnavailableGuilds() map[string]*UnavailableGuild {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.guildsMu.RLock()
+	defer s.guildsMu.RUnlock()
 
 	newMap := make(map[string]*UnavailableGuild)
-	for k, v := range s.unavailableGuilds {
+	for k, v := range s.backend.UnavailableGuilds() {
 		newMap[k] = v.Clone()
 	}
 
 	return newMap
 }
 
+// Webhooks returns the webhooks of a channel from the state. Webhooks are
+// cached when fetched with ChannelResource.Webhooks and invalidated as soon
+// as they are updated. The boolean reports whether they are currently cached.
+func (s *State) Webhooks(channelID string) ([]Webhook, bool) {
+	s.channelsMu.RLock()
+	defer s.channelsMu.RUnlock()
+
+	webhooks, ok := s.backend.Webhooks(channelID)
+	if !ok {
+		return nil, false
+	}
+
+	clone := make([]Webhook, 0, len(webhooks))
+	for i := 0; i < len(webhooks); i++ {
+		clone = append(clone, *webhooks[i].Clone())
+	}
+	return clone, true
+}
+
+// Integrations returns the integrations of a guild from the state. Integrations
+// are cached when fetched with GuildResource.Integrations and invalidated as soon
+// as they are updated. The boolean reports whether they are currently cached.
+func (s *State) Integrations(guildID string) ([]Integration, bool) {
+	s.guildsMu.RLock()
+	defer s.guildsMu.RUnlock()
+
+	integrations, ok := s.backend.Integrations(guildID)
+	if !ok {
+		return nil, false
+	}
+
+	clone := make([]Integration, 0, len(integrations))
+	for i := 0; i < len(integrations); i++ {
+		clone = append(clone, *integrations[i].Clone())
+	}
+	return clone, true
+}
+
 // RTT returns the Round Trip Time between the client and Discord's Gateway.
 // It is calculated and updated when sending heartbeat payloads (roughly
 // every minute).
 func (s *State) RTT() time.Duration {
-	s.mu.RLock()
-	defer s.mu.Unlock()
-
-	return s.rtt
+	return s.rtt.Load()
 }
 
 // setInitialState initializes the state with a Ready event received from the gateway.
 func (s *State) setInitialState(r *Ready) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	s.currentUser = r.User
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+	s.channelsMu.Lock()
+	defer s.channelsMu.Unlock()
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	s.backend.SetCurrentUser(r.User)
 	for i := 0; i < len(r.Guilds); i++ {
 		g := &r.Guilds[i]
-		s.guilds[g.ID] = &Guild{
+		guild := &Guild{
 			ID:          g.ID,
 			Name:        g.Name,
 			Owner:       g.Owner,
 			Permissions: g.Permissions,
 		}
 		if g.Icon != "" {
-			s.guilds[g.ID].Icon = &g.Icon
+			guild.Icon = &g.Icon
 		}
+		s.internGuild(guild)
+		s.backend.SetGuild(guild)
 	}
 	for i := 0; i < len(r.PrivateChannels); i++ {
 		dm := &r.PrivateChannels[i]
+		s.internChannel(dm)
 		if dm.Type == channel.TypeDM {
-			s.dms[dm.ID] = dm
+			s.backend.SetDM(dm)
 		}
 		if dm.Type == channel.TypeGroupDM {
-			s.groups[dm.ID] = dm
+			s.backend.SetGroupDM(dm)
 		}
 	}
 }
 
 // updateGuild adds the given guild to the state. If it already
-// exists, it merges its content with the existing guild.
+// exists, it merges its content with the existing guild. Members
+// and presences, if set, replace the ones of the existing guild.
 // It also removes this guild from the UnavailableGuilds map if
 // it was present.
 func (s *State) updateGuild(g *Guild) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.storeMembers(g.ID, g.Members)
+	s.storePresences(g.ID, g.Presences)
+
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+	s.channelsMu.Lock()
+	defer s.channelsMu.Unlock()
+
+	// Fields kept from the old guild below are already interned.
+	s.internGuild(g)
 
 	// Make sure we do not overwrite fields
 	// that were set before but not anymore.
-	old := s.guilds[g.ID]
+	old := s.backend.Guild(g.ID)
 	if old != nil {
 		if g.Roles == nil {
 			g.Roles = old.Roles
@@ -256,71 +366,189 @@ func (s *State) updateGuild(g *Guild) {
 		if g.Emojis == nil {
 			g.Emojis = old.Emojis
 		}
+		if g.Stickers == nil {
+			g.Stickers = old.Stickers
+		}
 		if g.VoiceStates == nil {
 			g.VoiceStates = old.VoiceStates
 		}
-		if g.Members == nil {
-			g.Members = old.Members
-		}
 		if g.Channels == nil {
 			g.Channels = old.Channels
 		}
-		if g.Presences == nil {
-			g.Presences = old.Presences
+		if g.Threads == nil {
+			g.Threads = old.Threads
 		}
 	}
 
 	for i := 0; i < len(g.Channels); i++ {
 		ch := &g.Channels[i]
 		ch.GuildID = g.ID
-		s.channels[ch.ID] = ch
+		s.backend.SetChannel(ch)
+	}
+
+	for i := 0; i < len(g.Threads); i++ {
+		th := &g.Threads[i]
+		th.GuildID = g.ID
+		s.backend.SetChannel(th)
+	}
+
+	// Members and presences are stored apart from their guild.
+	stored := *g
+	stored.Members, stored.Presences = nil, nil
+	s.backend.SetGuild(&stored)
+	s.backend.DeleteUnavailableGuild(g.ID)
+}
+
+// stateChunkSize is the number of users or presences of a guild stored
+// in the state before releasing its lock, see storeMembers.
+const stateChunkSize = 1000
+
+// storeMembers replaces the members of the given guild with the given ones,
+// if not nil, and stores their users in the state. Members of large guilds
+// are stored in chunks, releasing the locks of the state and yielding the
+// processor between chunks, so readers of the state are not blocked while a
+// large guild is added.
+func (s *State) storeMembers(guildID string, members []GuildMember) {
+	if members == nil {
+		return
 	}
 
-	for i := 0; i < len(g.Members); i++ {
-		m := &g.Members[i]
-		s.users[m.User.ID] = m.User
+	s.guildsMu.RLock()
+	stale := s.backend.Members(guildID)
+	s.guildsMu.RUnlock()
+
+	for start := 0; start < len(members); start += stateChunkSize {
+		if start > 0 {
+			runtime.Gosched()
+		}
+
+		end := start + stateChunkSize
+		if end > len(members) {
+			end = len(members)
+		}
+
+		s.guildsMu.Lock()
+		s.usersMu.Lock()
+		for i := start; i < end; i++ {
+			m := &members[i]
+			s.internMember(m)
+			s.backend.SetUser(m.User)
+			s.backend.SetMember(guildID, m.User.ID, memberWithoutUser(m))
+			delete(stale, m.User.ID)
+		}
+		s.usersMu.Unlock()
+		s.guildsMu.Unlock()
 	}
 
-	for i := 0; i < len(g.Presences); i++ {
-		p := &g.Presences[i]
-		s.presences[p.User.ID] = p
+	if len(stale) > 0 {
+		s.guildsMu.Lock()
+		for userID := range stale {
+			s.backend.DeleteMember(guildID, userID)
+		}
+		s.guildsMu.Unlock()
 	}
+}
 
-	s.guilds[g.ID] = g
-	delete(s.unavailableGuilds, g.ID)
+// memberWithoutUser returns a copy of the given member without its user,
+// as stored in the backend.
+func memberWithoutUser(m *GuildMember) *GuildMember {
+	member := *m
+	member.User = nil
+	return &member
+}
+
+// storePresences is like storeMembers for presences.
+func (s *State) storePresences(guildID string, presences []Presence) {
+	if presences == nil {
+		return
+	}
+
+	s.presencesMu.RLock()
+	stale := s.backend.GuildPresences(guildID)
+	s.presencesMu.RUnlock()
+
+	for start := 0; start < len(presences); start += stateChunkSize {
+		if start > 0 {
+			runtime.Gosched()
+		}
+
+		end := start + stateChunkSize
+		if end > len(presences) {
+			end = len(presences)
+		}
+
+		s.presencesMu.Lock()
+		for i := start; i < end; i++ {
+			p := &presences[i]
+			p.GuildID = guildID
+			s.internPresence(p)
+			s.backend.SetPresence(p)
+			s.backend.SetGuildPresence(p)
+			delete(stale, p.User.ID)
+		}
+		s.presencesMu.Unlock()
+	}
+
+	if len(stale) > 0 {
+		s.presencesMu.Lock()
+		for userID := range stale {
+			s.backend.DeleteGuildPresence(guildID, userID)
+		}
+		s.presencesMu.Unlock()
+	}
 }
 
 // removeGuild removes a guild from the Guilds map, adding it to
 // the UnavailableGuilds map.
 func (s *State) removeGuild(g *UnavailableGuild) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	delete(s.guilds, g.ID)
-	s.unavailableGuilds[g.ID] = g
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+	s.presencesMu.Lock()
+	defer s.presencesMu.Unlock()
+
+	s.backend.DeleteMembers(g.ID)
+	s.backend.DeleteGuildPresences(g.ID)
+	s.backend.DeleteGuild(g.ID)
+	s.backend.DeleteIntegrations(g.ID)
+	s.backend.SetUnavailableGuild(g)
+	s.removeGuildMessages(g.ID)
 }
 
 // updateGuildEmojis updates the emojis available in a guild if it
 // is already tracked by the state, does nothing otherwise.
 func (s *State) updateGuildEmojis(guildID string, emojis []Emoji) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+
+	if g := s.backend.Guild(guildID); g != nil {
+		g.Emojis = emojis
+		s.backend.SetGuild(g)
+	}
+}
 
-	if s.guilds[guildID] != nil {
-		s.guilds[guildID].Emojis = emojis
+// updateGuildStickers updates the stickers of a guild if it
+// is already tracked by the state, does nothing otherwise.
+func (s *State) updateGuildStickers(guildID string, stickers []Sticker) {
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+
+	if g := s.backend.Guild(guildID); g != nil {
+		g.Stickers = stickers
+		s.backend.SetGuild(g)
 	}
 }
 
 // updateGuildVoiceStates updates the voice states in a guild if it is
 // already tracked by the state, does nothing otherwise.
 func (s *State) updateGuildVoiceStates(vsu *voice.StateUpdate) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
 
-	g := s.guilds[vsu.GuildID]
+	g := s.backend.Guild(vsu.GuildID)
 	if g == nil {
 		return
 	}
+	s.internVoiceState(&vsu.State)
 
 	// If we have a channel ID, then it means it is either a new voice
 	// state or an update to an existing one.
@@ -353,54 +581,54 @@ func (s *State) updateGuildVoiceStates(vsu *voice.StateUpdate) {
 		g.VoiceStates[toRemove] = g.VoiceStates[len(g.VoiceStates)-1]
 		g.VoiceStates = g.VoiceStates[:len(g.VoiceStates)-1]
 	}
+
+	s.backend.SetGuild(g)
 }
 
-// updatePresence updates a presence both in the presences map as
-// well as in the Guilds map.
+// updatePresence updates a presence both in the presences map as well as
+// in the presences of its guild, if the user is a tracked member of it.
+// Presences are only set if they changed.
 func (s *State) updatePresence(p *Presence) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.usersMu.RLock()
+	defer s.usersMu.RUnlock()
+	s.presencesMu.Lock()
+	defer s.presencesMu.Unlock()
 
 	// Check that the concerned user exists in the state.
-	if s.users[p.User.ID] == nil {
+	if s.backend.User(p.User.ID) == nil {
 		return
 	}
 
+	s.internPresence(p)
 	// NOTE: consider removing the presence from the presence map
 	// if the user goes offline.
-	s.presences[p.User.ID] = p
-
-	// Check that his guild exists in the state.
-	if s.guilds[p.GuildID] == nil {
-		return
+	if !reflect.DeepEqual(s.backend.Presence(p.User.ID), p) {
+		s.backend.SetPresence(p)
 	}
 
-	for i := 0; i < len(s.guilds[p.GuildID].Presences); i++ {
-		if s.guilds[p.GuildID].Presences[i].User.ID == p.User.ID {
-			s.guilds[p.GuildID].Presences[i] = *p
-		}
+	if old := s.backend.GuildPresence(p.GuildID, p.User.ID); old != nil && !reflect.DeepEqual(old, p) {
+		s.backend.SetGuildPresence(p)
 	}
 }
 
-// updateUser updates a user in the Users map (or the User) as well
-// as in all the guilds this user is.
+// updateUser updates a user in the Users map (or the current user), if it
+// changed. Members of guilds are stored without their user, so they do not
+// need to be updated.
 func (s *State) updateUser(u *User) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
 
-	if u.ID == s.currentUser.ID {
-		s.currentUser = u
-	} else {
-		s.users[u.ID] = u
-	}
+	s.internUser(u)
 
-	for id := range s.guilds {
-		g := s.guilds[id]
-		for i := 0; i < len(g.Members); i++ {
-			if g.Members[i].User.ID == u.ID {
-				g.Members[i].User = u
-			}
+	if current := s.backend.CurrentUser(); current != nil && u.ID == current.ID {
+		if !reflect.DeepEqual(current, u) {
+			s.backend.SetCurrentUser(u)
 		}
+		return
+	}
+
+	if !reflect.DeepEqual(s.backend.User(u.ID), u) {
+		s.backend.SetUser(u)
 	}
 }
 
@@ -408,90 +636,201 @@ func (s *State) updateUser(u *User) {
 // the guild this channel is for guild text, voice and category channels.
 // If the channel does not exist yet, it is added.
 func (s *State) updateChannel(c *Channel) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+	s.channelsMu.Lock()
+	defer s.channelsMu.Unlock()
+
+	s.internChannel(c)
 
 	switch c.Type {
 	case channel.TypeDM:
-		s.dms[c.ID] = c
+		s.backend.SetDM(c)
 
 	case channel.TypeGroupDM:
-		s.groups[c.ID] = c
+		s.backend.SetGroupDM(c)
 
 	case channel.TypeGuildText, channel.TypeGuildVoice, channel.TypeGuildCategory,
-		channel.TypeGuildNews, channel.TypeGuildStore:
-		guild := s.guilds[c.GuildID]
-		var found bool
-		for i := 0; i < len(guild.Channels); i++ {
-			if guild.Channels[i].ID == c.ID {
-				guild.Channels[i] = *c
-				found = true
-				break
-			}
+		channel.TypeGuildNews, channel.TypeGuildStore, channel.TypeGuildStageVoice:
+		guild := s.backend.Guild(c.GuildID)
+		if guild == nil {
+			break
+		}
+		var changed bool
+		guild.Channels, changed = setGuildChannel(guild.Channels, c)
+		if changed {
+			s.backend.SetGuild(guild)
 		}
-		if !found {
-			guild.Channels = append(guild.Channels, *c)
+
+	case channel.TypeGuildNewsThread, channel.TypeGuildPublicThread, channel.TypeGuildPrivateThread:
+		guild := s.backend.Guild(c.GuildID)
+		if guild == nil {
+			break
+		}
+		var changed bool
+		guild.Threads, changed = setGuildChannel(guild.Threads, c)
+		if changed {
+			s.backend.SetGuild(guild)
 		}
 	}
 
-	s.channels[c.ID] = c
+	s.backend.SetChannel(c)
+}
+
+// setGuildChannel replaces the channel with the same ID as c in chs, or
+// appends c if there is none. It reports whether chs was changed.
+func setGuildChannel(chs []Channel, c *Channel) ([]Channel, bool) {
+	for i := 0; i < len(chs); i++ {
+		if chs[i].ID == c.ID {
+			if reflect.DeepEqual(chs[i], *c) {
+				return chs, false
+			}
+			chs[i] = *c
+			return chs, true
+		}
+	}
+	return append(chs, *c), true
+}
+
+// removeGuildChannel removes the channel with the given ID from chs.
+// It reports whether chs was changed.
+func removeGuildChannel(chs []Channel, id string) ([]Channel, bool) {
+	for i := 0; i < len(chs); i++ {
+		if chs[i].ID == id {
+			return append(chs[:i], chs[i+1:]...), true
+		}
+	}
+	return chs, false
 }
 
 // removeChannel removes the given channel from the channels map as
 // well as the guild it was in for guild text, voice and category channels.
 func (s *State) removeChannel(c *Channel) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+	s.channelsMu.Lock()
+	defer s.channelsMu.Unlock()
 
 	switch c.Type {
 	case channel.TypeDM:
-		delete(s.dms, c.ID)
+		s.backend.DeleteDM(c.ID)
 
 	case channel.TypeGroupDM:
-		delete(s.groups, c.ID)
+		s.backend.DeleteGroupDM(c.ID)
 
-	case channel.TypeGuildText, channel.TypeGuildVoice, channel.TypeGuildCategory:
-		g := s.guilds[c.GuildID]
+	case channel.TypeGuildText, channel.TypeGuildVoice, channel.TypeGuildCategory,
+		channel.TypeGuildStageVoice:
+		g := s.backend.Guild(c.GuildID)
 		if g == nil {
-			return
+			break
 		}
-		chs := g.Channels
-		for i := 0; i < len(chs); i++ {
-			if chs[i].ID == c.ID {
-				chs = append(chs[:i], chs[i+1:]...)
-				s.guilds[c.GuildID].Channels = chs
-				break
+		var changed bool
+		if g.Channels, changed = removeGuildChannel(g.Channels, c.ID); changed {
+			s.backend.SetGuild(g)
+		}
+
+	case channel.TypeGuildNewsThread, channel.TypeGuildPublicThread, channel.TypeGuildPrivateThread:
+		g := s.backend.Guild(c.GuildID)
+		if g == nil {
+			break
+		}
+		var changed bool
+		if g.Threads, changed = removeGuildChannel(g.Threads, c.ID); changed {
+			s.backend.SetGuild(g)
+		}
+	}
+
+	s.backend.DeleteChannel(c.ID)
+	s.backend.DeleteWebhooks(c.ID)
+	s.removeChannelMessages(c.ID)
+}
+
+// syncThreads replaces the threads of the given guild with the ones in the list,
+// restricted to threads of the synced channels if they are set.
+func (s *State) syncThreads(sync *ThreadListSync) {
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+	s.channelsMu.Lock()
+	defer s.channelsMu.Unlock()
+
+	synced := func(parentID string) bool {
+		if len(sync.ChannelIDs) == 0 {
+			return true
+		}
+		for _, id := range sync.ChannelIDs {
+			if id == parentID {
+				return true
 			}
 		}
+		return false
+	}
+
+	// Threads of the guild are tracked along with it, so there
+	// is no need to go through every channel of the state.
+	g := s.backend.Guild(sync.GuildID)
+	var threads []Channel
+	if g != nil {
+		for _, th := range g.Threads {
+			if synced(th.ParentID) {
+				s.backend.DeleteChannel(th.ID)
+				continue
+			}
+			threads = append(threads, th)
+		}
+	}
+
+	for i := 0; i < len(sync.Threads); i++ {
+		th := &sync.Threads[i]
+		th.GuildID = sync.GuildID
+		s.internChannel(th)
+		s.backend.SetChannel(th)
+		threads = append(threads, *th)
 	}
 
-	delete(s.channels, c.ID)
+	if g != nil {
+		g.Threads = threads
+		s.backend.SetGuild(g)
+	}
 }
 
 // updatePins updates the LastPinTimestamp of a channel in the Channel map
 // and in the DM, group DM or guild this channel is in.
 func (s *State) updatePins(p *ChannelPinsUpdate) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+	s.channelsMu.Lock()
+	defer s.channelsMu.Unlock()
 
-	ch := s.channels[p.ChannelID]
+	ch := s.backend.Channel(p.ChannelID)
 	if ch == nil {
 		return
 	}
 
 	ch.LastPinTimestamp = p.LastPinTimestamp
+	s.backend.SetChannel(ch)
+
 	switch ch.Type {
 	case channel.TypeDM:
-		s.dms[p.ChannelID].LastPinTimestamp = p.LastPinTimestamp
+		if dm := s.backend.DM(p.ChannelID); dm != nil {
+			dm.LastPinTimestamp = p.LastPinTimestamp
+			s.backend.SetDM(dm)
+		}
 
 	case channel.TypeGroupDM:
-		s.groups[p.ChannelID].LastPinTimestamp = p.LastPinTimestamp
+		if group := s.backend.GroupDM(p.ChannelID); group != nil {
+			group.LastPinTimestamp = p.LastPinTimestamp
+			s.backend.SetGroupDM(group)
+		}
 
 	case channel.TypeGuildText:
-		chs := s.guilds[ch.GuildID].Channels
-		for i := 0; i < len(chs); i++ {
-			if chs[i].ID == p.ChannelID {
-				chs[i].LastPinTimestamp = p.LastPinTimestamp
+		g := s.backend.Guild(ch.GuildID)
+		if g == nil {
+			return
+		}
+		for i := 0; i < len(g.Channels); i++ {
+			if g.Channels[i].ID == p.ChannelID {
+				g.Channels[i].LastPinTimestamp = p.LastPinTimestamp
+				s.backend.SetGuild(g)
 				break
 			}
 		}
@@ -499,97 +838,143 @@ func (s *State) updatePins(p *ChannelPinsUpdate) {
 }
 
 func (s *State) guildMemberAdd(m *GuildMemberAdd) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	s.internMember(m.GuildMember)
 
 	// This is a new user, add it to the users map.
-	if s.users[m.User.ID] == nil {
-		s.users[m.User.ID] = m.User
+	if s.backend.User(m.User.ID) == nil {
+		s.backend.SetUser(m.User)
 	}
 
-	g := s.guilds[m.GuildID]
+	g := s.backend.Guild(m.GuildID)
 	if g == nil {
 		return
 	}
 
 	g.MemberCount++
-	g.Members = append(g.Members, *m.GuildMember)
+	s.backend.SetGuild(g)
+	s.backend.SetMember(m.GuildID, m.User.ID, memberWithoutUser(m.GuildMember))
 }
 
 func (s *State) guildMemberUpdate(m *GuildMemberUpdate) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
 
-	g := s.guilds[m.GuildID]
-	if g == nil {
+	member := s.backend.Member(m.GuildID, m.User.ID)
+	if member == nil {
+		return
+	}
+
+	s.internUser(m.User)
+	if !reflect.DeepEqual(s.backend.User(m.User.ID), m.User) {
+		s.backend.SetUser(m.User)
+	}
+
+	if member.Nick == m.Nick && reflect.DeepEqual(member.Roles, m.Roles) {
 		return
 	}
+	if s.strings != nil {
+		s.strings.Strings(m.Roles)
+	}
+	member.Roles = m.Roles
+	member.Nick = m.Nick
+	s.backend.SetMember(m.GuildID, m.User.ID, member)
+}
 
-	for i := 0; i < len(g.Members); i++ {
-		if g.Members[i].User.ID == m.User.ID {
-			g.Members[i].Roles = m.Roles
-			g.Members[i].User = m.User
-			g.Members[i].Nick = m.Nick
+// guildMembersChunk adds the members of the given chunk to their guild,
+// or updates them if they are already tracked, as well as their presences.
+func (s *State) guildMembersChunk(chunk *GuildMembersChunk) {
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+	s.presencesMu.Lock()
+	defer s.presencesMu.Unlock()
+
+	tracked := s.backend.Guild(chunk.GuildID) != nil
+
+	for i := 0; i < len(chunk.Members); i++ {
+		m := &chunk.Members[i]
+		s.internMember(m)
+		s.backend.SetUser(m.User)
+		if tracked {
+			s.backend.SetMember(chunk.GuildID, m.User.ID, memberWithoutUser(m))
+		}
+	}
+	for i := 0; i < len(chunk.Presences); i++ {
+		p := &chunk.Presences[i]
+		p.GuildID = chunk.GuildID
+		s.internPresence(p)
+		s.backend.SetPresence(p)
+		if tracked {
+			s.backend.SetGuildPresence(p)
 		}
 	}
 }
 
 func (s *State) guildMemberRemove(r *GuildMemberRemove) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	g := s.guilds[r.GuildID]
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+	s.presencesMu.Lock()
+	defer s.presencesMu.Unlock()
+
+	g := s.backend.Guild(r.GuildID)
 	if g == nil {
 		return
 	}
 
 	g.MemberCount--
-	for i := 0; i < len(g.Members); i++ {
-		if g.Members[i].User.ID == r.User.ID {
-			g.Members = append(g.Members[:i], g.Members[i+1:]...)
-			break
-		}
-	}
+	s.backend.SetGuild(g)
+	s.backend.DeleteMember(r.GuildID, r.User.ID)
+	s.backend.DeleteGuildPresence(r.GuildID, r.User.ID)
 
-	var found bool
-	for id := range s.guilds {
-		g := s.guilds[id]
-		for _, m := range g.Members {
-			if m.User.ID == r.User.ID {
-				found = true
-				break
-			}
-		}
-	}
 	// If this user is in no other guild, remove it from the state.
-	if !found {
-		delete(s.users, r.User.ID)
+	for guildID := range s.backend.Guilds() {
+		if s.backend.Member(guildID, r.User.ID) != nil {
+			return
+		}
 	}
+	s.backend.DeleteUser(r.User.ID)
 }
 
 // guildRoleCreate adds a role to a guild.
 func (s *State) guildRoleCreate(gr *GuildRole) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
 
-	g := s.guilds[gr.GuildID]
+	g := s.backend.Guild(gr.GuildID)
 	if g == nil {
 		return
 	}
 
+	s.internRole(gr.Role)
 	g.Roles = append(g.Roles, *gr.Role)
+	s.backend.SetGuild(g)
 }
 
 // guildRoleUpdate updates a role in a guild.
 func (s *State) guildRoleUpdate(gr *GuildRole) {
-	g := s.guilds[gr.GuildID]
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+
+	g := s.backend.Guild(gr.GuildID)
 	if g == nil {
 		return
 	}
 
+	s.internRole(gr.Role)
 	for i := 0; i < len(g.Roles); i++ {
 		if g.Roles[i].ID == gr.Role.ID {
 			g.Roles[i] = *gr.Role
+			s.backend.SetGuild(g)
 			break
 		}
 	}
@@ -597,10 +982,10 @@ func (s *State) guildRoleUpdate(gr *GuildRole) {
 
 // guildRoleRemove removes a role from a guild.
 func (s *State) guildRoleRemove(gr *GuildRoleDelete) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
 
-	g := s.guilds[gr.GuildID]
+	g := s.backend.Guild(gr.GuildID)
 	if g == nil {
 		return
 	}
@@ -608,6 +993,7 @@ func (s *State) guildRoleRemove(gr *GuildRoleDelete) {
 	for i := 0; i < len(g.Roles); i++ {
 		if g.Roles[i].ID == gr.RoleID {
 			g.Roles = append(g.Roles[:i], g.Roles[i+1:]...)
+			s.backend.SetGuild(g)
 			break
 		}
 	}
@@ -616,8 +1002,45 @@ func (s *State) guildRoleRemove(gr *GuildRoleDelete) {
 // setRTT sets the Round Trip Time. See the RTT method for more information
 // on how it is calculated.
 func (s *State) setRTT(d time.Duration) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.rtt.Store(d)
+}
+
+// setWebhooks caches the webhooks of the given channel.
+func (s *State) setWebhooks(channelID string, webhooks []Webhook) {
+	s.channelsMu.Lock()
+	defer s.channelsMu.Unlock()
+
+	cached := make([]Webhook, 0, len(webhooks))
+	for i := 0; i < len(webhooks); i++ {
+		cached = append(cached, *webhooks[i].Clone())
+	}
+	s.backend.SetWebhooks(channelID, cached)
+}
+
+// invalidateWebhooks removes the cached webhooks of the given channel.
+func (s *State) invalidateWebhooks(channelID string) {
+	s.channelsMu.Lock()
+	defer s.channelsMu.Unlock()
+
+	s.backend.DeleteWebhooks(channelID)
+}
+
+// setIntegrations caches the integrations of the given guild.
+func (s *State) setIntegrations(guildID string, integrations []Integration) {
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
+
+	cached := make([]Integration, 0, len(integrations))
+	for i := 0; i < len(integrations); i++ {
+		cached = append(cached, *integrations[i].Clone())
+	}
+	s.backend.SetIntegrations(guildID, cached)
+}
+
+// invalidateIntegrations removes the cached integrations of the given guild.
+func (s *State) invalidateIntegrations(guildID string) {
+	s.guildsMu.Lock()
+	defer s.guildsMu.Unlock()
 
-	s.rtt = d
+	s.backend.DeleteIntegrations(guildID)
 }
@@ -0,0 +1,378 @@
+package harmony
+
+// StateBackend stores the objects cached by the State. Implementing it allows to
+// store them somewhere else than in memory, for instance to share them between
+// several processes, each handling some shards. See the redisstate package
+// for an implementation backed by Redis.
+//
+// The State keeps the logic of how events update its objects: it gets objects
+// from its backend, updates them and sets them back. Objects returned by a
+// backend can be modified by the State, which always sets them back afterwards,
+// and objects given to a backend must not be modified once set. Getters return
+// nil (or false) when an object is not in the backend.
+//
+// The State never calls a setter or a deleter concurrently with any other method
+// for the same type of object, but it may call getters concurrently, as well as
+// methods for different types of objects. Guilds, unavailable guilds, members and
+// integrations are considered the same type of object, as are channels, DMs,
+// group DMs and webhooks, users and the current user, and presences and guild
+// presences. Roles, emojis and voice states are stored within their guild, while
+// members and presences of guilds are stored apart, so updating one of them does
+// not require setting the whole guild.
+type StateBackend interface {
+	CurrentUser() *User
+	SetCurrentUser(u *User)
+
+	User(id string) *User
+	Users() map[string]*User
+	SetUser(u *User)
+	DeleteUser(id string)
+
+	Guild(id string) *Guild
+	Guilds() map[string]*Guild
+	SetGuild(g *Guild)
+	DeleteGuild(id string)
+
+	// Members are stored by guild ID and user ID, without their
+	// User, which is stored with SetUser.
+	Member(guildID, userID string) *GuildMember
+	Members(guildID string) map[string]*GuildMember
+	SetMember(guildID, userID string, m *GuildMember)
+	DeleteMember(guildID, userID string)
+	// DeleteMembers deletes all the members of a guild at once.
+	DeleteMembers(guildID string)
+
+	UnavailableGuild(id string) *UnavailableGuild
+	UnavailableGuilds() map[string]*UnavailableGuild
+	SetUnavailableGuild(g *UnavailableGuild)
+	DeleteUnavailableGuild(id string)
+
+	// Channels are guild channels and threads, as
+	// well as DMs and group DMs received through events.
+	Channel(id string) *Channel
+	Channels() map[string]*Channel
+	SetChannel(ch *Channel)
+	DeleteChannel(id string)
+
+	DM(id string) *Channel
+	DMs() map[string]*Channel
+	SetDM(ch *Channel)
+	DeleteDM(id string)
+
+	GroupDM(id string) *Channel
+	GroupDMs() map[string]*Channel
+	SetGroupDM(ch *Channel)
+	DeleteGroupDM(id string)
+
+	// Presences are stored by user ID.
+	Presence(userID string) *Presence
+	Presences() map[string]*Presence
+	SetPresence(p *Presence)
+	DeletePresence(userID string)
+
+	// Guild presences are the presences of the members of
+	// a guild, stored by guild ID and user ID.
+	GuildPresence(guildID, userID string) *Presence
+	GuildPresences(guildID string) map[string]*Presence
+	SetGuildPresence(p *Presence)
+	DeleteGuildPresence(guildID, userID string)
+	// DeleteGuildPresences deletes all the presences of a guild at once.
+	DeleteGuildPresences(guildID string)
+
+	Webhooks(channelID string) ([]Webhook, bool)
+	SetWebhooks(channelID string, webhooks []Webhook)
+	DeleteWebhooks(channelID string)
+
+	Integrations(guildID string) ([]Integration, bool)
+	SetIntegrations(guildID string, integrations []Integration)
+	DeleteIntegrations(guildID string)
+}
+
+// FallibleStateBackend is a StateBackend whose operations can fail, for instance
+// because it stores objects remotely. Since the methods of a StateBackend do not
+// return errors, objects that can not be read look like they are missing from the
+// State and failed writes leave it outdated. The client sets the error handler of
+// such backends so these errors are logged and reported to its ErrorReporter, with
+// ErrorSourceState. When several clients share a backend, as with a ShardManager,
+// the last client created handles its errors.
+type FallibleStateBackend interface {
+	StateBackend
+	// SetErrorHandler sets the function called each time an operation fails.
+	SetErrorHandler(f func(err error))
+}
+
+// stateBackendError logs the given error of the state backend and reports it.
+func (c *Client) stateBackendError(err error) {
+	c.logger.Errorf("state backend: %v", err)
+	c.reportError(err, &ErrorEvent{Source: ErrorSourceState})
+}
+
+// NewMemoryStateBackend returns a StateBackend storing objects in memory. It is
+// the backend used by default by the State.
+func NewMemoryStateBackend() StateBackend {
+	return &memoryBackend{
+		users:             make(map[string]*User),
+		guilds:            make(map[string]*Guild),
+		members:           make(map[string]map[string]*GuildMember),
+		unavailableGuilds: make(map[string]*UnavailableGuild),
+		channels:          make(map[string]*Channel),
+		dms:               make(map[string]*Channel),
+		groups:            make(map[string]*Channel),
+		presences:         make(map[string]*Presence),
+		guildPresences:    make(map[string]map[string]*Presence),
+		webhooks:          make(map[string][]Webhook),
+		integrations:      make(map[string][]Integration),
+	}
+}
+
+// memoryBackend is a StateBackend storing objects in maps. It does not need
+// any locking since maps can be read concurrently and each type of object has
+// its own maps.
+type memoryBackend struct {
+	currentUser       *User
+	users             map[string]*User
+	guilds            map[string]*Guild
+	members           map[string]map[string]*GuildMember // Members by guild ID and user ID.
+	unavailableGuilds map[string]*UnavailableGuild
+	channels          map[string]*Channel
+	dms               map[string]*Channel
+	groups            map[string]*Channel
+	presences         map[string]*Presence
+	guildPresences    map[string]map[string]*Presence // Presences by guild ID and user ID.
+	webhooks          map[string][]Webhook            // Webhooks by channel ID.
+	integrations      map[string][]Integration        // Integrations by guild ID.
+}
+
+func (b *memoryBackend) CurrentUser() *User {
+	return b.currentUser
+}
+
+func (b *memoryBackend) SetCurrentUser(u *User) {
+	b.currentUser = u
+}
+
+func (b *memoryBackend) User(id string) *User {
+	return b.users[id]
+}
+
+func (b *memoryBackend) Users() map[string]*User {
+	users := make(map[string]*User, len(b.users))
+	for k, v := range b.users {
+		users[k] = v
+	}
+	return users
+}
+
+func (b *memoryBackend) SetUser(u *User) {
+	b.users[u.ID] = u
+}
+
+func (b *memoryBackend) DeleteUser(id string) {
+	delete(b.users, id)
+}
+
+func (b *memoryBackend) Guild(id string) *Guild {
+	return b.guilds[id]
+}
+
+func (b *memoryBackend) Guilds() map[string]*Guild {
+	guilds := make(map[string]*Guild, len(b.guilds))
+	for k, v := range b.guilds {
+		guilds[k] = v
+	}
+	return guilds
+}
+
+func (b *memoryBackend) SetGuild(g *Guild) {
+	b.guilds[g.ID] = g
+}
+
+func (b *memoryBackend) DeleteGuild(id string) {
+	delete(b.guilds, id)
+}
+
+func (b *memoryBackend) Member(guildID, userID string) *GuildMember {
+	return b.members[guildID][userID]
+}
+
+func (b *memoryBackend) Members(guildID string) map[string]*GuildMember {
+	members := make(map[string]*GuildMember, len(b.members[guildID]))
+	for k, v := range b.members[guildID] {
+		members[k] = v
+	}
+	return members
+}
+
+func (b *memoryBackend) SetMember(guildID, userID string, m *GuildMember) {
+	members, ok := b.members[guildID]
+	if !ok {
+		members = make(map[string]*GuildMember)
+		b.members[guildID] = members
+	}
+	members[userID] = m
+}
+
+func (b *memoryBackend) DeleteMember(guildID, userID string) {
+	delete(b.members[guildID], userID)
+	if len(b.members[guildID]) == 0 {
+		delete(b.members, guildID)
+	}
+}
+
+func (b *memoryBackend) DeleteMembers(guildID string) {
+	delete(b.members, guildID)
+}
+
+func (b *memoryBackend) UnavailableGuild(id string) *UnavailableGuild {
+	return b.unavailableGuilds[id]
+}
+
+func (b *memoryBackend) UnavailableGuilds() map[string]*UnavailableGuild {
+	guilds := make(map[string]*UnavailableGuild, len(b.unavailableGuilds))
+	for k, v := range b.unavailableGuilds {
+		guilds[k] = v
+	}
+	return guilds
+}
+
+func (b *memoryBackend) SetUnavailableGuild(g *UnavailableGuild) {
+	b.unavailableGuilds[g.ID] = g
+}
+
+func (b *memoryBackend) DeleteUnavailableGuild(id string) {
+	delete(b.unavailableGuilds, id)
+}
+
+func (b *memoryBackend) Channel(id string) *Channel {
+	return b.channels[id]
+}
+
+func (b *memoryBackend) Channels() map[string]*Channel {
+	return copyChannels(b.channels)
+}
+
+func (b *memoryBackend) SetChannel(ch *Channel) {
+	b.channels[ch.ID] = ch
+}
+
+func (b *memoryBackend) DeleteChannel(id string) {
+	delete(b.channels, id)
+}
+
+func (b *memoryBackend) DM(id string) *Channel {
+	return b.dms[id]
+}
+
+func (b *memoryBackend) DMs() map[string]*Channel {
+	return copyChannels(b.dms)
+}
+
+func (b *memoryBackend) SetDM(ch *Channel) {
+	b.dms[ch.ID] = ch
+}
+
+func (b *memoryBackend) DeleteDM(id string) {
+	delete(b.dms, id)
+}
+
+func (b *memoryBackend) GroupDM(id string) *Channel {
+	return b.groups[id]
+}
+
+func (b *memoryBackend) GroupDMs() map[string]*Channel {
+	return copyChannels(b.groups)
+}
+
+func (b *memoryBackend) SetGroupDM(ch *Channel) {
+	b.groups[ch.ID] = ch
+}
+
+func (b *memoryBackend) DeleteGroupDM(id string) {
+	delete(b.groups, id)
+}
+
+func copyChannels(m map[string]*Channel) map[string]*Channel {
+	channels := make(map[string]*Channel, len(m))
+	for k, v := range m {
+		channels[k] = v
+	}
+	return channels
+}
+
+func (b *memoryBackend) Presence(userID string) *Presence {
+	return b.presences[userID]
+}
+
+func (b *memoryBackend) Presences() map[string]*Presence {
+	presences := make(map[string]*Presence, len(b.presences))
+	for k, v := range b.presences {
+		presences[k] = v
+	}
+	return presences
+}
+
+func (b *memoryBackend) SetPresence(p *Presence) {
+	b.presences[p.User.ID] = p
+}
+
+func (b *memoryBackend) DeletePresence(userID string) {
+	delete(b.presences, userID)
+}
+
+func (b *memoryBackend) GuildPresence(guildID, userID string) *Presence {
+	return b.guildPresences[guildID][userID]
+}
+
+func (b *memoryBackend) GuildPresences(guildID string) map[string]*Presence {
+	presences := make(map[string]*Presence, len(b.guildPresences[guildID]))
+	for k, v := range b.guildPresences[guildID] {
+		presences[k] = v
+	}
+	return presences
+}
+
+func (b *memoryBackend) SetGuildPresence(p *Presence) {
+	presences, ok := b.guildPresences[p.GuildID]
+	if !ok {
+		presences = make(map[string]*Presence)
+		b.guildPresences[p.GuildID] = presences
+	}
+	presences[p.User.ID] = p
+}
+
+func (b *memoryBackend) DeleteGuildPresence(guildID, userID string) {
+	delete(b.guildPresences[guildID], userID)
+	if len(b.guildPresences[guildID]) == 0 {
+		delete(b.guildPresences, guildID)
+	}
+}
+
+func (b *memoryBackend) DeleteGuildPresences(guildID string) {
+	delete(b.guildPresences, guildID)
+}
+
+func (b *memoryBackend) Webhooks(channelID string) ([]Webhook, bool) {
+	webhooks, ok := b.webhooks[channelID]
+	return webhooks, ok
+}
+
+func (b *memoryBackend) SetWebhooks(channelID string, webhooks []Webhook) {
+	b.webhooks[channelID] = webhooks
+}
+
+func (b *memoryBackend) DeleteWebhooks(channelID string) {
+	delete(b.webhooks, channelID)
+}
+
+func (b *memoryBackend) Integrations(guildID string) ([]Integration, bool) {
+	integrations, ok := b.integrations[guildID]
+	return integrations, ok
+}
+
+func (b *memoryBackend) SetIntegrations(guildID string, integrations []Integration) {
+	b.integrations[guildID] = integrations
+}
+
+func (b *memoryBackend) DeleteIntegrations(guildID string) {
+	delete(b.integrations, guildID)
+}
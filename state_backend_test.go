@@ -0,0 +1,31 @@
+package harmony
+
+import "testing"
+
+func TestStateRemoveGuild(t *testing.T) {
+	s := newState(NewMemoryStateBackend(), false)
+	for _, guildID := range []string{"1", "2"} {
+		s.backend.SetGuild(&Guild{ID: guildID})
+		s.backend.SetMember(guildID, "10", &GuildMember{})
+		s.backend.SetGuildPresence(&Presence{GuildID: guildID, User: &User{ID: "10"}})
+	}
+
+	unavailable := true
+	s.removeGuild(&UnavailableGuild{ID: "1", Unavailable: &unavailable})
+
+	if g := s.backend.Guild("1"); g != nil {
+		t.Error("expected the guild to be removed")
+	}
+	if g := s.backend.UnavailableGuild("1"); g == nil {
+		t.Error("expected the guild to be unavailable")
+	}
+	if members := s.backend.Members("1"); len(members) != 0 {
+		t.Errorf("expected members of the guild to be removed, got %v", members)
+	}
+	if presences := s.backend.GuildPresences("1"); len(presences) != 0 {
+		t.Errorf("expected presences of the guild to be removed, got %v", presences)
+	}
+	if len(s.backend.Members("2")) != 1 || len(s.backend.GuildPresences("2")) != 1 {
+		t.Error("expected members and presences of other guilds to be kept")
+	}
+}
@@ -0,0 +1,115 @@
+package harmony
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// newBenchState returns a state holding a guild with the given number of members,
+// each with a presence.
+func newBenchState(members int) *State {
+	s := newState(NewMemoryStateBackend(), false)
+
+	g := &Guild{ID: "1", Name: "bench"}
+	for i := 0; i < members; i++ {
+		u := &User{ID: strconv.Itoa(i + 100), Username: "user" + strconv.Itoa(i)}
+		g.Members = append(g.Members, GuildMember{User: u, Roles: []string{"10", "11"}})
+		g.Presences = append(g.Presences, Presence{User: u, GuildID: g.ID, Status: "online"})
+	}
+	g.Channels = []Channel{{ID: "2", GuildID: g.ID, Name: "general"}}
+	s.updateGuild(g)
+	return s
+}
+
+// writeGuilds updates the roles of the guild of the given state until stop is closed.
+func writeGuilds(s *State, stop <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for i := 0; ; i++ {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		s.guildRoleCreate(&GuildRole{GuildID: "1", Role: &Role{ID: strconv.Itoa(i)}})
+		s.guildRoleRemove(&GuildRoleDelete{GuildID: "1", RoleID: strconv.Itoa(i)})
+	}
+}
+
+// benchmarkReadsDuringGuildWrites measures the given read of the state while
+// another goroutine continuously updates the guild of the state.
+func benchmarkReadsDuringGuildWrites(b *testing.B, read func(s *State)) {
+	s := newBenchState(1000)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go writeGuilds(s, stop, &wg)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			read(s)
+		}
+	})
+	b.StopTimer()
+
+	close(stop)
+	wg.Wait()
+}
+
+func BenchmarkStateUserDuringGuildWrites(b *testing.B) {
+	benchmarkReadsDuringGuildWrites(b, func(s *State) {
+		s.User("500")
+	})
+}
+
+func BenchmarkStateChannelDuringGuildWrites(b *testing.B) {
+	benchmarkReadsDuringGuildWrites(b, func(s *State) {
+		s.Channel("2")
+	})
+}
+
+func BenchmarkStatePresenceDuringGuildWrites(b *testing.B) {
+	benchmarkReadsDuringGuildWrites(b, func(s *State) {
+		s.Presence("500")
+	})
+}
+
+func BenchmarkStateGuildDuringGuildWrites(b *testing.B) {
+	benchmarkReadsDuringGuildWrites(b, func(s *State) {
+		s.Guild("1")
+	})
+}
+
+func BenchmarkStateUserDuringPresenceWrites(b *testing.B) {
+	s := newBenchState(1000)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		u := &User{ID: "600"}
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			s.storePresences("1", []Presence{{User: u, GuildID: "1", Status: "idle"}})
+		}
+	}()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.User("500")
+		}
+	})
+	b.StopTimer()
+
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,108 @@
+package harmony
+
+import (
+	"github.com/skwair/harmony/voice"
+)
+
+// Those methods replace IDs and other frequently repeated strings of objects
+// about to be stored in the state with their interned copies, if interning is
+// enabled (see WithStateInterning). They must be called with the lock of the
+// given objects held, since they modify them.
+
+// internGuild does not intern members and presences of the
+// guild, they are interned by storeMembers and storePresences.
+func (s *State) internGuild(g *Guild) {
+	if s.strings == nil || g == nil {
+		return
+	}
+
+	g.ID = s.strings.String(g.ID)
+	g.OwnerID = s.strings.String(g.OwnerID)
+	g.Region = s.strings.String(g.Region)
+	g.PreferredLocale = s.strings.String(g.PreferredLocale)
+	s.strings.Strings(g.Features)
+	for i := range g.Roles {
+		s.internRole(&g.Roles[i])
+	}
+	for i := range g.Channels {
+		s.internChannel(&g.Channels[i])
+	}
+	for i := range g.Threads {
+		s.internChannel(&g.Threads[i])
+	}
+	for i := range g.VoiceStates {
+		s.internVoiceState(&g.VoiceStates[i])
+	}
+}
+
+func (s *State) internRole(r *Role) {
+	if s.strings == nil || r == nil {
+		return
+	}
+
+	r.ID = s.strings.String(r.ID)
+}
+
+func (s *State) internMember(m *GuildMember) {
+	if s.strings == nil || m == nil {
+		return
+	}
+
+	s.internUser(m.User)
+	s.strings.Strings(m.Roles)
+}
+
+func (s *State) internUser(u *User) {
+	if s.strings == nil || u == nil {
+		return
+	}
+
+	// The same user is received once per guild they are in.
+	u.ID = s.strings.String(u.ID)
+	u.Username = s.strings.String(u.Username)
+	u.Discriminator = s.strings.String(u.Discriminator)
+	u.Avatar = s.strings.String(u.Avatar)
+}
+
+func (s *State) internChannel(ch *Channel) {
+	if s.strings == nil || ch == nil {
+		return
+	}
+
+	ch.ID = s.strings.String(ch.ID)
+	ch.GuildID = s.strings.String(ch.GuildID)
+	ch.ParentID = s.strings.String(ch.ParentID)
+	ch.OwnerID = s.strings.String(ch.OwnerID)
+	for i := range ch.PermissionOverwrites {
+		o := &ch.PermissionOverwrites[i]
+		o.Type = s.strings.String(o.Type)
+		o.ID = s.strings.String(o.ID)
+	}
+	for i := range ch.Recipients {
+		s.internUser(&ch.Recipients[i])
+	}
+}
+
+func (s *State) internPresence(p *Presence) {
+	if s.strings == nil || p == nil {
+		return
+	}
+
+	s.internUser(p.User)
+	s.strings.Strings(p.Roles)
+	p.GuildID = s.strings.String(p.GuildID)
+	p.Status = s.strings.String(p.Status)
+}
+
+func (s *State) internVoiceState(vs *voice.State) {
+	if s.strings == nil || vs == nil {
+		return
+	}
+
+	vs.GuildID = s.strings.String(vs.GuildID)
+	vs.UserID = s.strings.String(vs.UserID)
+	if vs.ChannelID != nil {
+		id := s.strings.String(*vs.ChannelID)
+		vs.ChannelID = &id
+	}
+}
@@ -0,0 +1,225 @@
+package harmony
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// messageContentFields are the JSON names of the fields of messages that are empty
+// when the client does not have the GatewayIntentMessageContent intent.
+var messageContentFields = map[string]struct{}{
+	"content":     {},
+	"embeds":      {},
+	"attachments": {},
+	"components":  {},
+}
+
+// messageCache keeps the last messages of each channel. Unlike other objects
+// of the State, messages are always kept in memory, whatever the backend.
+type messageCache struct {
+	mu sync.RWMutex
+	// Messages by channel ID, from the oldest to the most recent.
+	messages map[string][]*Message
+
+	// Maximum number of messages kept per channel.
+	size int
+	// Whether the client receives the content of messages, see
+	// GatewayIntentMessageContent. Without it, empty content
+	// in updates does not erase the cached one.
+	content bool
+}
+
+// newMessageCache returns a message cache keeping the last size messages of
+// each channel. content reports whether the client has the message content
+// intent.
+func newMessageCache(size int, content bool) *messageCache {
+	return &messageCache{
+		messages: make(map[string][]*Message),
+		size:     size,
+		content:  content,
+	}
+}
+
+// Message returns a message given its channel ID and ID from the state. It
+// returns nil if the message is not cached, which is always the case if the
+// message cache is disabled, see WithMessageCacheSize.
+func (s *State) Message(channelID, id string) *Message {
+	if s.messages == nil {
+		return nil
+	}
+
+	s.messages.mu.RLock()
+	defer s.messages.mu.RUnlock()
+
+	return s.messages.get(channelID, id).Clone()
+}
+
+// Messages returns the messages of the given channel cached by the state, from
+// the oldest to the most recent. It returns nil if the message cache is
+// disabled, see WithMessageCacheSize.
+func (s *State) Messages(channelID string) []Message {
+	if s.messages == nil {
+		return nil
+	}
+
+	s.messages.mu.RLock()
+	defer s.messages.mu.RUnlock()
+
+	msgs := s.messages.messages[channelID]
+	if len(msgs) == 0 {
+		return nil
+	}
+	clones := make([]Message, 0, len(msgs))
+	for _, m := range msgs {
+		clones = append(clones, *m.Clone())
+	}
+	return clones
+}
+
+// addMessage caches the given message, evicting
+// the oldest message of its channel if it is full.
+func (s *State) addMessage(m *Message) {
+	if s.messages == nil {
+		return
+	}
+
+	s.messages.mu.Lock()
+	defer s.messages.mu.Unlock()
+
+	c := s.messages
+	if c.get(m.ChannelID, m.ID) != nil {
+		return
+	}
+	msgs := append(c.messages[m.ChannelID], m.Clone())
+	if len(msgs) > c.size {
+		// Copy the kept messages so the evicted
+		// ones do not stay in the backing array.
+		msgs = append([]*Message(nil), msgs[len(msgs)-c.size:]...)
+	}
+	c.messages[m.ChannelID] = msgs
+}
+
+// updateMessage merges the given message, decoded from the given payload of a
+// MESSAGE_UPDATE event, into the cached one, if any. Updates can be partial:
+// only the fields present in the payload are updated, so fields that are not
+// sent are not reset to their zero value. Without the message content intent,
+// empty content fields are ignored as well since they are not the actual
+// content of the message.
+func (s *State) updateMessage(m *Message, data json.RawMessage) {
+	if s.messages == nil {
+		return
+	}
+
+	s.messages.mu.Lock()
+	defer s.messages.mu.Unlock()
+
+	c := s.messages
+	cached := c.get(m.ChannelID, m.ID)
+	if cached == nil {
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return
+	}
+	mergeMessage(cached, m.Clone(), fields, !c.content)
+}
+
+// removeMessage removes a message from the cache and returns
+// it, or nil if it was not cached.
+func (s *State) removeMessage(channelID, id string) *Message {
+	if s.messages == nil {
+		return nil
+	}
+
+	s.messages.mu.Lock()
+	defer s.messages.mu.Unlock()
+
+	msgs := s.messages.messages[channelID]
+	for i, m := range msgs {
+		if m.ID == id {
+			s.messages.messages[channelID] = append(msgs[:i:i], msgs[i+1:]...)
+			return m
+		}
+	}
+	return nil
+}
+
+// removeChannelMessages removes all cached messages of a channel.
+func (s *State) removeChannelMessages(channelID string) {
+	if s.messages == nil {
+		return
+	}
+
+	s.messages.mu.Lock()
+	defer s.messages.mu.Unlock()
+
+	delete(s.messages.messages, channelID)
+}
+
+// removeGuildMessages removes all cached messages of the channels of a guild.
+func (s *State) removeGuildMessages(guildID string) {
+	if s.messages == nil {
+		return
+	}
+
+	s.messages.mu.Lock()
+	defer s.messages.mu.Unlock()
+
+	for channelID, msgs := range s.messages.messages {
+		if len(msgs) > 0 && msgs[0].GuildID == guildID {
+			delete(s.messages.messages, channelID)
+		}
+	}
+}
+
+// get returns the cached message with the given channel ID and ID,
+// or nil if there is none. The cache must be locked by the caller.
+func (c *messageCache) get(channelID, id string) *Message {
+	for _, m := range c.messages[channelID] {
+		if m.ID == id {
+			return m
+		}
+	}
+	return nil
+}
+
+// mergeMessage sets the fields of dst that are present in the given decoded
+// payload of src to their value in src. If keepContent is true, empty content
+// fields of src do not overwrite the ones of dst.
+func mergeMessage(dst, src *Message, fields map[string]json.RawMessage, keepContent bool) {
+	dv, sv := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem()
+	t := dv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if _, ok := fields[name]; !ok {
+			continue
+		}
+		if _, ok := messageContentFields[name]; ok && keepContent && isEmpty(sv.Field(i)) {
+			continue
+		}
+		dv.Field(i).Set(sv.Field(i))
+	}
+
+	for name, value := range src.RawExtra {
+		if dst.RawExtra == nil {
+			dst.RawExtra = make(RawExtra)
+		}
+		dst.RawExtra[name] = value
+	}
+}
+
+// isEmpty reports whether v is a zero value or an empty string, slice or map.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
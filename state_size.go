@@ -0,0 +1,337 @@
+package harmony
+
+import (
+	"reflect"
+	"sort"
+	"unsafe"
+)
+
+// StateSize is an estimation of the memory used by the objects cached in a
+// State, in bytes. It accounts for the objects themselves and for what they
+// point to, but not for the overhead of maps and of the memory allocator, so
+// actual usage is higher. See State.SizeEstimate.
+type StateSize struct {
+	Users             int64
+	Guilds            int64
+	UnavailableGuilds int64
+	Channels          int64
+	DMs               int64
+	GroupDMs          int64
+	Presences         int64
+	// Messages cached by the message cache, see WithMessageCacheSize.
+	Messages int64
+	// Total is the sum of all the above.
+	Total int64
+
+	// PerGuild holds the details of the memory used by each guild,
+	// by guild ID. Their sum is Guilds.
+	PerGuild map[string]GuildSize
+}
+
+// GuildSize is an estimation of the memory used by a guild cached in a State,
+// in bytes, by type of entity.
+type GuildSize struct {
+	GuildID string
+	Members int64
+	// Channels includes threads.
+	Channels    int64
+	Roles       int64
+	Emojis      int64
+	Stickers    int64
+	Presences   int64
+	VoiceStates int64
+	// Other is the memory used by the guild itself
+	// and by fields not listed above.
+	Other int64
+	// Total is the sum of all the above.
+	Total int64
+}
+
+// SizeEstimate returns an estimation of the memory used by the objects cached
+// in the state, per type of entity and per guild, which helps deciding which
+// caching to disable on large bots, for instance with WithMemberChunking or
+// gateway intents. It walks through all cached objects, so it is expensive on
+// large states and should not be called often. Objects are copied while holding
+// the locks of the state, one type of object at a time, and measured once they
+// are released, so events keep being handled meanwhile. Memory shared by several
+// objects, such as interned strings, is counted once, in the first object
+// measured. When the state is stored in another backend than the default one,
+// the estimation is the one of the objects once loaded in memory.
+func (s *State) SizeEstimate() *StateSize {
+	snap := s.sizeSnapshot()
+
+	z := newSizer()
+	size := &StateSize{PerGuild: make(map[string]GuildSize)}
+
+	for _, u := range snap.users {
+		size.Users += z.sizeOf(u)
+	}
+	for id, g := range snap.guilds {
+		gs := z.guildSize(g, snap.members[id], snap.guildPresences[id])
+		size.PerGuild[id] = gs
+		size.Guilds += gs.Total
+	}
+	for _, g := range snap.unavailableGuilds {
+		size.UnavailableGuilds += z.sizeOf(g)
+	}
+	for _, ch := range snap.channels {
+		size.Channels += z.sizeOf(ch)
+	}
+	for _, ch := range snap.dms {
+		size.DMs += z.sizeOf(ch)
+	}
+	for _, ch := range snap.groupDMs {
+		size.GroupDMs += z.sizeOf(ch)
+	}
+	for _, p := range snap.presences {
+		size.Presences += z.sizeOf(p)
+	}
+	for _, m := range snap.messages {
+		size.Messages += z.sizeOf(m)
+	}
+
+	size.Total = size.Users + size.Guilds + size.UnavailableGuilds +
+		size.Channels + size.DMs + size.GroupDMs + size.Presences + size.Messages
+	return size
+}
+
+// stateSnapshot holds copies of the objects cached in a State, see SizeEstimate.
+type stateSnapshot struct {
+	users             map[string]*User
+	guilds            map[string]*Guild
+	members           map[string]map[string]*GuildMember // By guild ID.
+	unavailableGuilds map[string]*UnavailableGuild
+	channels          map[string]*Channel
+	dms               map[string]*Channel
+	groupDMs          map[string]*Channel
+	presences         map[string]*Presence
+	guildPresences    map[string]map[string]*Presence // By guild ID.
+	messages          []*Message
+}
+
+// sizeSnapshot returns copies of the objects cached in the state, taking its
+// locks one at a time. Objects of the default backend are cloned since the
+// state modifies them in place, while other backends return new objects.
+func (s *State) sizeSnapshot() *stateSnapshot {
+	_, inMemory := s.backend.(*memoryBackend)
+	snap := &stateSnapshot{
+		members:        make(map[string]map[string]*GuildMember),
+		guildPresences: make(map[string]map[string]*Presence),
+	}
+
+	s.usersMu.RLock()
+	snap.users = s.backend.Users()
+	if inMemory {
+		for id, u := range snap.users {
+			snap.users[id] = u.Clone()
+		}
+	}
+	s.usersMu.RUnlock()
+
+	s.guildsMu.RLock()
+	snap.guilds = s.backend.Guilds()
+	for id, g := range snap.guilds {
+		members := s.backend.Members(id)
+		if inMemory {
+			snap.guilds[id] = g.Clone()
+			for userID, m := range members {
+				members[userID] = m.Clone()
+			}
+		}
+		snap.members[id] = members
+	}
+	snap.unavailableGuilds = s.backend.UnavailableGuilds()
+	if inMemory {
+		for id, g := range snap.unavailableGuilds {
+			snap.unavailableGuilds[id] = g.Clone()
+		}
+	}
+	s.guildsMu.RUnlock()
+
+	s.channelsMu.RLock()
+	snap.channels, snap.dms, snap.groupDMs = s.backend.Channels(), s.backend.DMs(), s.backend.GroupDMs()
+	if inMemory {
+		for _, channels := range []map[string]*Channel{snap.channels, snap.dms, snap.groupDMs} {
+			for id, ch := range channels {
+				channels[id] = ch.Clone()
+			}
+		}
+	}
+	s.channelsMu.RUnlock()
+
+	s.presencesMu.RLock()
+	snap.presences = s.backend.Presences()
+	for id := range snap.guilds {
+		snap.guildPresences[id] = s.backend.GuildPresences(id)
+	}
+	if inMemory {
+		for userID, p := range snap.presences {
+			snap.presences[userID] = p.Clone()
+		}
+		for _, presences := range snap.guildPresences {
+			for userID, p := range presences {
+				presences[userID] = p.Clone()
+			}
+		}
+	}
+	s.presencesMu.RUnlock()
+
+	if s.messages != nil {
+		s.messages.mu.RLock()
+		for _, msgs := range s.messages.messages {
+			for _, m := range msgs {
+				snap.messages = append(snap.messages, m.Clone())
+			}
+		}
+		s.messages.mu.RUnlock()
+	}
+
+	return snap
+}
+
+// TopGuildsByMemory returns the n guilds of the state using the most memory,
+// from the largest to the smallest. It returns all guilds if n is 0 or more
+// than the number of guilds. See SizeEstimate.
+func (s *State) TopGuildsByMemory(n int) []GuildSize {
+	size := s.SizeEstimate()
+
+	guilds := make([]GuildSize, 0, len(size.PerGuild))
+	for _, gs := range size.PerGuild {
+		guilds = append(guilds, gs)
+	}
+	sort.Slice(guilds, func(i, j int) bool {
+		if guilds[i].Total != guilds[j].Total {
+			return guilds[i].Total > guilds[j].Total
+		}
+		return guilds[i].GuildID < guilds[j].GuildID
+	})
+
+	if n > 0 && n < len(guilds) {
+		guilds = guilds[:n]
+	}
+	return guilds
+}
+
+// sizer estimates the memory used by objects and by everything they reference,
+// counting memory shared by several references once, in the first object
+// measured.
+type sizer struct {
+	seen map[uintptr]bool
+}
+
+func newSizer() *sizer {
+	return &sizer{seen: make(map[uintptr]bool)}
+}
+
+// guildSize estimates the memory used by the given guild, along with
+// its members and presences which are stored apart from it.
+func (z *sizer) guildSize(g *Guild, members map[string]*GuildMember, presences map[string]*Presence) GuildSize {
+	gs := GuildSize{
+		GuildID:     g.ID,
+		Members:     z.referencedSize(members),
+		Channels:    z.referencedSize(g.Channels) + z.referencedSize(g.Threads),
+		Roles:       z.referencedSize(g.Roles),
+		Emojis:      z.referencedSize(g.Emojis),
+		Stickers:    z.referencedSize(g.Stickers),
+		Presences:   z.referencedSize(presences),
+		VoiceStates: z.referencedSize(g.VoiceStates),
+	}
+	// Fields listed above were already measured, so
+	// this is the memory used by the rest of the guild.
+	gs.Other = z.sizeOf(g)
+	gs.Total = gs.Members + gs.Channels + gs.Roles + gs.Emojis + gs.Stickers +
+		gs.Presences + gs.VoiceStates + gs.Other
+	return gs
+}
+
+// sizeOf estimates the memory used by v and by everything it references.
+func (z *sizer) sizeOf(v interface{}) int64 {
+	if v == nil {
+		return 0
+	}
+	rv := reflect.ValueOf(v)
+	return int64(rv.Type().Size()) + z.indirectSize(rv)
+}
+
+// referencedSize is like sizeOf but does not count the size of v itself,
+// for instance to get the size of the elements of a slice field.
+func (z *sizer) referencedSize(v interface{}) int64 {
+	if v == nil {
+		return 0
+	}
+	return z.indirectSize(reflect.ValueOf(v))
+}
+
+// indirectSize returns the memory referenced by v, not including
+// the size of v itself.
+func (z *sizer) indirectSize(v reflect.Value) int64 {
+	seen := z.seen
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		elem := v.Elem()
+		return int64(elem.Type().Size()) + z.indirectSize(elem)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		elem := v.Elem()
+		return int64(elem.Type().Size()) + z.indirectSize(elem)
+
+	case reflect.String:
+		// Interned strings share their data, see WithStateInterning.
+		str := v.String()
+		data := (*reflect.StringHeader)(unsafe.Pointer(&str)).Data
+		if str == "" || seen[data] {
+			return 0
+		}
+		seen[data] = true
+		return int64(len(str))
+
+	case reflect.Slice:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		n := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			n += z.indirectSize(v.Index(i))
+		}
+		return n
+
+	case reflect.Array:
+		var n int64
+		for i := 0; i < v.Len(); i++ {
+			n += z.indirectSize(v.Index(i))
+		}
+		return n
+
+	case reflect.Map:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		var n int64
+		iter := v.MapRange()
+		for iter.Next() {
+			k, e := iter.Key(), iter.Value()
+			n += int64(k.Type().Size()) + z.indirectSize(k)
+			n += int64(e.Type().Size()) + z.indirectSize(e)
+		}
+		return n
+
+	case reflect.Struct:
+		var n int64
+		for i := 0; i < v.NumField(); i++ {
+			n += z.indirectSize(v.Field(i))
+		}
+		return n
+
+	default:
+		return 0
+	}
+}
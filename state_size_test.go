@@ -0,0 +1,81 @@
+package harmony
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSizerSharedMemory(t *testing.T) {
+	name := strings.Repeat("a", 1000)
+	// Both users share the data of their name, as interned strings do.
+	u1, u2 := &User{ID: "1", Username: name}, &User{ID: "2", Username: name}
+
+	z := newSizer()
+	first, second := z.sizeOf(u1), z.sizeOf(u2)
+	if first-second != int64(len(name)) {
+		t.Errorf("expected the shared name to be counted once, got sizes %d and %d", first, second)
+	}
+
+	if size := newSizer().sizeOf(u2); size != first {
+		t.Errorf("expected a new sizer to count the name again, got %d, expected %d", size, first)
+	}
+}
+
+func TestSizeEstimate(t *testing.T) {
+	s := newState(NewMemoryStateBackend(), false)
+	for _, guildID := range []string{"1", "2"} {
+		s.backend.SetGuild(&Guild{
+			ID:       guildID,
+			Name:     "guild " + guildID,
+			Channels: []Channel{{ID: guildID + "0", Name: "general"}},
+			Emojis:   []Emoji{{ID: guildID + "1", Name: "emoji"}},
+		})
+		s.backend.SetMember(guildID, "10", &GuildMember{Nick: "nick"})
+	}
+	s.backend.SetUser(&User{ID: "10", Username: "user"})
+	s.backend.SetChannel(&Channel{ID: "20", Name: "channel"})
+
+	size := s.SizeEstimate()
+
+	var guilds int64
+	for id, gs := range size.PerGuild {
+		if gs.GuildID != id || gs.Members == 0 || gs.Channels == 0 || gs.Emojis == 0 || gs.Other == 0 {
+			t.Errorf("expected every entity of guild %s to be measured, got %+v", id, gs)
+		}
+		if total := gs.Members + gs.Channels + gs.Roles + gs.Emojis + gs.Stickers + gs.Presences + gs.VoiceStates + gs.Other; total != gs.Total {
+			t.Errorf("expected guild %s to total %d, got %d", id, total, gs.Total)
+		}
+		guilds += gs.Total
+	}
+	if len(size.PerGuild) != 2 || guilds != size.Guilds {
+		t.Errorf("expected guilds to total %d, got %d", guilds, size.Guilds)
+	}
+	if size.Users == 0 || size.Channels == 0 {
+		t.Errorf("expected users and channels to be measured, got %+v", size)
+	}
+	if total := size.Users + size.Guilds + size.UnavailableGuilds + size.Channels + size.DMs + size.GroupDMs + size.Presences + size.Messages; total != size.Total {
+		t.Errorf("expected state to total %d, got %d", total, size.Total)
+	}
+}
+
+// TestSizeEstimateConcurrentUpdates measures the state while it is updated,
+// which must be run with -race to be meaningful.
+func TestSizeEstimateConcurrentUpdates(t *testing.T) {
+	s := newState(NewMemoryStateBackend(), false)
+	s.backend.SetGuild(&Guild{ID: "1"})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			s.updateGuildEmojis("1", []Emoji{{ID: "1", Name: strings.Repeat("a", i)}})
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		s.SizeEstimate()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,87 @@
+package harmony
+
+// Stats holds statistics about a Client.
+type Stats struct {
+	// UnknownEvents counts Gateway events received by the client
+	// that Harmony does not support yet, by event type.
+	UnknownEvents map[string]uint64 `json:"unknown_events"`
+	// UnknownOpcodes counts Gateway payloads received by the client
+	// with an opcode Harmony does not support yet, by opcode.
+	UnknownOpcodes map[int]uint64 `json:"unknown_opcodes"`
+	// FilteredEvents counts Gateway events dropped by the
+	// event filter, by event type. See WithEventFilter.
+	FilteredEvents map[string]uint64 `json:"filtered_events"`
+	// DroppedEvents counts events that were not sent to a channel
+	// returned by Events because its buffer was full, by event type.
+	DroppedEvents map[string]uint64 `json:"dropped_events"`
+	// RetryBudget is the retry budget of the last complete minute.
+	RetryBudget RetryBudget `json:"retry_budget"`
+}
+
+// Stats returns a snapshot of the statistics of the client. Unknown
+// events and opcodes are useful to see which new Discord features
+// are arriving on the wire before Harmony supports them.
+func (c *Client) Stats() *Stats {
+	c.statsMu.Lock()
+	ended, ok := c.rollRetryBudget()
+	defer func() {
+		c.statsMu.Unlock()
+		if ok && c.onRetryBudget != nil {
+			c.onRetryBudget(ended)
+		}
+	}()
+
+	s := &Stats{
+		UnknownEvents:  make(map[string]uint64, len(c.unknownEvents)),
+		UnknownOpcodes: make(map[int]uint64, len(c.unknownOpcodes)),
+		FilteredEvents: make(map[string]uint64, len(c.filteredEvents)),
+		DroppedEvents:  make(map[string]uint64, len(c.droppedEvents)),
+		RetryBudget:    c.lastRetryBudget,
+	}
+	for k, v := range c.unknownEvents {
+		s.UnknownEvents[k] = v
+	}
+	for k, v := range c.unknownOpcodes {
+		s.UnknownOpcodes[k] = v
+	}
+	for k, v := range c.filteredEvents {
+		s.FilteredEvents[k] = v
+	}
+	for k, v := range c.droppedEvents {
+		s.DroppedEvents[k] = v
+	}
+	return s
+}
+
+// recordUnknownEvent counts an event of the given type that is not supported.
+func (c *Client) recordUnknownEvent(typ string) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	c.unknownEvents[typ]++
+}
+
+// recordUnknownOpcode counts a payload with the given opcode that is not supported.
+func (c *Client) recordUnknownOpcode(op int) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	c.unknownOpcodes[op]++
+}
+
+// recordFilteredEvent counts an event of the given type dropped by the event filter.
+func (c *Client) recordFilteredEvent(typ string) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	c.filteredEvents[typ]++
+}
+
+// recordDroppedEvent counts an event of the given type that was not
+// sent to a channel returned by Events because it was full.
+func (c *Client) recordDroppedEvent(typ string) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	c.droppedEvents[typ]++
+}
@@ -0,0 +1,119 @@
+// Package sticker contains the types and settings of stickers.
+package sticker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/skwair/harmony/optional"
+)
+
+// Type describes the type of a sticker.
+type Type int
+
+// Supported sticker types:
+const (
+	// TypeStandard stickers are official stickers, part of a pack.
+	TypeStandard Type = 1
+	// TypeGuild stickers are uploaded to a guild.
+	TypeGuild Type = 2
+)
+
+// Format describes the file format of a sticker.
+type Format int
+
+// Supported sticker formats:
+const (
+	FormatPNG    Format = 1
+	FormatAPNG   Format = 2
+	FormatLottie Format = 3
+	FormatGIF    Format = 4
+)
+
+// Constraints enforced by Discord on guild sticker settings.
+const (
+	MinNameLength        = 2
+	MaxNameLength        = 30
+	MinDescriptionLength = 2
+	MaxDescriptionLength = 100
+	MaxTagsLength        = 200
+)
+
+// Settings describes how to create or modify a guild sticker. All fields are
+// optional and only those explicitly set will be sent.
+type Settings struct {
+	Name        *optional.String `json:"name,omitempty"`        // 2-30 characters.
+	Description *optional.String `json:"description,omitempty"` // Empty or 2-100 characters.
+	// Tags are autocomplete and suggestion tags, usually
+	// the name of a standard emoji related to the sticker.
+	Tags *optional.String `json:"tags,omitempty"` // 1-200 characters.
+}
+
+// Setting is a function that configures a guild sticker.
+type Setting func(*Settings)
+
+// NewSettings returns new Settings to create or modify a guild sticker.
+func NewSettings(opts ...Setting) *Settings {
+	s := &Settings{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithName sets the name of a guild sticker.
+func WithName(name string) Setting {
+	return func(s *Settings) {
+		s.Name = optional.NewString(name)
+	}
+}
+
+// WithDescription sets the description of a guild sticker.
+func WithDescription(description string) Setting {
+	return func(s *Settings) {
+		s.Description = optional.NewString(description)
+	}
+}
+
+// WithTags sets the autocomplete and suggestion tags of a guild sticker.
+func WithTags(tags string) Setting {
+	return func(s *Settings) {
+		s.Tags = optional.NewString(tags)
+	}
+}
+
+// Validate checks these settings against the constraints enforced by Discord
+// and returns an error describing every violated constraint, if any.
+func (s *Settings) Validate() error {
+	var problems []string
+
+	if s.Name != nil {
+		name, _ := s.Name.Value()
+		if l := utf8.RuneCountInString(name); l < MinNameLength || l > MaxNameLength {
+			problems = append(problems, fmt.Sprintf("name must be between %d and %d characters", MinNameLength, MaxNameLength))
+		}
+	}
+
+	if s.Description != nil {
+		description, _ := s.Description.Value()
+		if l := utf8.RuneCountInString(description); l != 0 && (l < MinDescriptionLength || l > MaxDescriptionLength) {
+			problems = append(problems, fmt.Sprintf("description must be empty or between %d and %d characters", MinDescriptionLength, MaxDescriptionLength))
+		}
+	}
+
+	if s.Tags != nil {
+		tags, _ := s.Tags.Value()
+		if l := utf8.RuneCountInString(tags); l == 0 || l > MaxTagsLength {
+			problems = append(problems, fmt.Sprintf("tags must be between 1 and %d characters", MaxTagsLength))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid sticker settings: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
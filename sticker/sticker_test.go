@@ -0,0 +1,33 @@
+package sticker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skwair/harmony/optional"
+)
+
+func TestSettingsValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings *Settings
+		valid    bool
+	}{
+		{name: "empty", settings: NewSettings(), valid: true},
+		{name: "valid", settings: NewSettings(WithName("wave"), WithDescription("Waving hand"), WithTags("wave")), valid: true},
+		{name: "name too short", settings: NewSettings(WithName("w")), valid: false},
+		{name: "literal name too long", settings: &Settings{Name: optional.NewString(strings.Repeat("a", MaxNameLength+1))}, valid: false},
+		{name: "empty description", settings: NewSettings(WithDescription("")), valid: true},
+		{name: "description too short", settings: &Settings{Description: optional.NewString("a")}, valid: false},
+		{name: "empty tags", settings: &Settings{Tags: optional.NewString("")}, valid: false},
+		{name: "tags too long", settings: NewSettings(WithTags(strings.Repeat("a", MaxTagsLength+1))), valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.settings.Validate(); (err == nil) != tt.valid {
+				t.Errorf("expected settings to be valid: %t, got error: %v", tt.valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package harmony
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/skwair/harmony/channel"
+	"github.com/skwair/harmony/internal/endpoint"
+)
+
+// ThreadMember is a user that joined a thread.
+type ThreadMember struct {
+	// ID of the thread. Omitted in THREAD_CREATE events.
+	ID            string    `json:"id,omitempty"`
+	UserID        string    `json:"user_id,omitempty"`
+	JoinTimestamp time.Time `json:"join_timestamp"`
+	// Flags are only used for client notification settings.
+	Flags int `json:"flags"`
+	// Guild member of the user, only set when explicitly requested.
+	Member *GuildMember `json:"member,omitempty"`
+}
+
+// ThreadList is a list of threads along with the
+// thread members of the current user for these threads.
+type ThreadList struct {
+	Threads []Channel `json:"threads"`
+	// Members has an entry for each thread the current user joined.
+	Members []ThreadMember `json:"members"`
+	// HasMore is set when listing archived threads, if there
+	// are more threads to fetch.
+	HasMore bool `json:"has_more"`
+}
+
+// StartThread starts a new thread that is not attached to a message. Settings must have
+// a name. Requires the 'CREATE_PUBLIC_THREADS' or 'CREATE_PRIVATE_THREADS' permission,
+// depending on the type of the thread. Fires a Thread Create Gateway event.
+func (r *ChannelResource) StartThread(ctx context.Context, settings *channel.ThreadSettings) (*Channel, error) {
+	return r.StartThreadWithReason(ctx, settings, "")
+}
+
+// StartThreadWithReason is like StartThread but with a reason
+// that will be set in the audit log entry for this action.
+func (r *ChannelResource) StartThreadWithReason(ctx context.Context, settings *channel.ThreadSettings, reason string) (*Channel, error) {
+	e := endpoint.StartThreadWithoutMessage(r.channelID)
+	return r.client.startThread(ctx, e, settings, reason)
+}
+
+// StartThreadFromMessage starts a new public thread from the given message, or a news
+// thread in news channels. Settings must have a name and the thread type can not be
+// set. Requires the 'CREATE_PUBLIC_THREADS' permission. Fires a Thread Create Gateway
+// event.
+func (r *ChannelResource) StartThreadFromMessage(ctx context.Context, messageID string, settings *channel.ThreadSettings) (*Channel, error) {
+	return r.StartThreadFromMessageWithReason(ctx, messageID, settings, "")
+}
+
+// StartThreadFromMessageWithReason is like StartThreadFromMessage but with a
+// reason that will be set in the audit log entry for this action.
+func (r *ChannelResource) StartThreadFromMessageWithReason(ctx context.Context, messageID string, settings *channel.ThreadSettings, reason string) (*Channel, error) {
+	if settings.Type != nil {
+		return nil, errors.New("the type of threads started from a message can not be set")
+	}
+
+	e := endpoint.StartThreadFromMessage(r.channelID, messageID)
+	return r.client.startThread(ctx, e, settings, reason)
+}
+
+func (c *Client) startThread(ctx context.Context, e *endpoint.Endpoint, settings *channel.ThreadSettings, reason string) (*Channel, error) {
+	if settings.Name == nil {
+		return nil, errors.New("thread name is required")
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doReqWithHeader(ctx, e, jsonPayload(b), reasonHeader(reason))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, apiError(resp)
+	}
+
+	var ch Channel
+	if err = json.NewDecoder(resp.Body).Decode(&ch); err != nil {
+		return nil, err
+	}
+	return &ch, nil
+}
+
+// ModifyThread is like ModifyThreadWithReason but with no particular reason.
+func (r *ChannelResource) ModifyThread(ctx context.Context, settings *channel.ThreadSettings) (*Channel, error) {
+	return r.ModifyThreadWithReason(ctx, settings, "")
+}
+
+// ModifyThreadWithReason updates the settings of the thread, for instance to archive or
+// lock it. Requires the 'MANAGE_THREADS' permission, except to unarchive a thread that is
+// not locked or to modify a thread created by the current user. Fires a Thread Update
+// Gateway event.
+// The given reason will be set in the audit log entry for this action.
+func (r *ChannelResource) ModifyThreadWithReason(ctx context.Context, settings *channel.ThreadSettings, reason string) (*Channel, error) {
+	if settings.Type != nil {
+		return nil, errors.New("the type of a thread can not be modified")
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	return r.modify(ctx, settings, reason)
+}
+
+// JoinThread adds the current user to the thread, which must not be archived.
+// Fires a Thread Members Update and a Thread Create Gateway event.
+func (r *ChannelResource) JoinThread(ctx context.Context) error {
+	e := endpoint.JoinThread(r.channelID)
+	return r.client.threadMemberReq(ctx, e)
+}
+
+// LeaveThread removes the current user from the thread, which must not be
+// archived. Fires a Thread Members Update Gateway event.
+func (r *ChannelResource) LeaveThread(ctx context.Context) error {
+	e := endpoint.LeaveThread(r.channelID)
+	return r.client.threadMemberReq(ctx, e)
+}
+
+// AddThreadMember adds the given user to the thread, which must not be archived. The
+// current user must be able to send messages in the thread. Fires a Thread Members
+// Update Gateway event.
+func (r *ChannelResource) AddThreadMember(ctx context.Context, userID string) error {
+	e := endpoint.AddThreadMember(r.channelID, userID)
+	return r.client.threadMemberReq(ctx, e)
+}
+
+// RemoveThreadMember removes the given user from the thread, which must not be
+// archived. Requires the 'MANAGE_THREADS' permission, or to be the creator of the
+// thread if it is private. Fires a Thread Members Update Gateway event.
+func (r *ChannelResource) RemoveThreadMember(ctx context.Context, userID string) error {
+	e := endpoint.RemoveThreadMember(r.channelID, userID)
+	return r.client.threadMemberReq(ctx, e)
+}
+
+func (c *Client) threadMemberReq(ctx context.Context, e *endpoint.Endpoint) error {
+	resp, err := c.doReq(ctx, e, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return apiError(resp)
+	}
+	return nil
+}
+
+// ThreadMembers returns the members of the thread. Requires
+// the GUILD_MEMBERS privileged intent to be enabled.
+func (r *ChannelResource) ThreadMembers(ctx context.Context) ([]ThreadMember, error) {
+	e := endpoint.ListThreadMembers(r.channelID)
+	resp, err := r.client.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var members []ThreadMember
+	if err = json.NewDecoder(resp.Body).Decode(&members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// ListActiveThreads returns all active threads of the guild, public and private, that
+// the current user can access. Active threads are listed by guild rather than by channel
+// in the Discord API, filter them on their ParentID to get the threads of a channel.
+func (r *GuildResource) ListActiveThreads(ctx context.Context) (*ThreadList, error) {
+	e := endpoint.ListActiveGuildThreads(r.guildID)
+	return r.client.threadList(ctx, e)
+}
+
+// ListArchivedThreads returns archived threads of the channel, most recently archived
+// first, archived before the given time if it is not zero. Limit is the maximum number of
+// threads to return, all if set to 0. Public threads require the 'READ_MESSAGE_HISTORY'
+// permission, private threads require the 'MANAGE_THREADS' permission as well.
+func (r *ChannelResource) ListArchivedThreads(ctx context.Context, private bool, before time.Time, limit int) (*ThreadList, error) {
+	q := url.Values{}
+	if !before.IsZero() {
+		q.Set("before", before.UTC().Format(time.RFC3339))
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+
+	var e *endpoint.Endpoint
+	if private {
+		e = endpoint.ListPrivateArchivedThreads(r.channelID, q.Encode())
+	} else {
+		e = endpoint.ListPublicArchivedThreads(r.channelID, q.Encode())
+	}
+	return r.client.threadList(ctx, e)
+}
+
+// ListJoinedArchivedThreads returns archived private threads of the channel that
+// the current user joined, ordered by descending ID, with an ID lower than before if
+// it is set. Limit is the maximum number of threads to return, all if set to 0.
+// Requires the 'READ_MESSAGE_HISTORY' permission.
+func (r *ChannelResource) ListJoinedArchivedThreads(ctx context.Context, before string, limit int) (*ThreadList, error) {
+	q := url.Values{}
+	if before != "" {
+		q.Set("before", before)
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+
+	e := endpoint.ListJoinedPrivateArchivedThreads(r.channelID, q.Encode())
+	return r.client.threadList(ctx, e)
+}
+
+func (c *Client) threadList(ctx context.Context, e *endpoint.Endpoint) (*ThreadList, error) {
+	resp, err := c.doReq(ctx, e, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var list ThreadList
+	if err = json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/skwair/harmony/internal/endpoint"
+	"github.com/skwair/harmony/user"
 )
 
 // User in Discord is generally considered the base entity.
@@ -26,15 +28,65 @@ type User struct {
 	MFAEnabled    bool   `json:"mfa_enabled,omitempty"`
 	Verified      bool   `json:"verified,omitempty"`
 	Email         string `json:"email,omitempty"`
+
+	// Following fields are only sent when fetching a
+	// user through the HTTP API.
+	Banner      *string `json:"banner,omitempty"`
+	AccentColor *int    `json:"accent_color,omitempty"`
+
+	AvatarDecorationData *AvatarDecorationData `json:"avatar_decoration_data,omitempty"`
+
+	// Fields received from the Gateway that are not known by
+	// Harmony, only set if WithRawExtra is enabled.
+	RawExtra RawExtra `json:"-"`
+}
+
+// AvatarDecorationData is the decoration displayed around the avatar of a user.
+type AvatarDecorationData struct {
+	// Asset is the hash of the avatar decoration.
+	Asset string `json:"asset"`
+	// SKUID is the ID of the SKU this decoration belongs to.
+	SKUID string `json:"sku_id"`
+}
+
+// cdnURL is the base URL of Discord's content delivery network.
+const cdnURL = "https://cdn.discordapp.com"
+
+// imageExtension returns the file extension to use for an image with the given hash.
+// Animated images have their hash prefixed with "a_".
+func imageExtension(hash string) string {
+	if strings.HasPrefix(hash, "a_") {
+		return "gif"
+	}
+	return "png"
 }
 
 // AvatarURL returns the user's avatar URL.
 func (u *User) AvatarURL() string {
 	if u.Avatar == "" {
 		d, _ := strconv.ParseInt(u.Discriminator, 10, 64)
-		return fmt.Sprintf("https://cdn.discordapp.com/embed/avatars/%d.png", d%5)
+		return fmt.Sprintf("%s/embed/avatars/%d.png", cdnURL, d%5)
 	}
-	return fmt.Sprintf("https://cdn.discordapp.com/avatars/%s/%s.png", u.ID, u.Avatar)
+	return fmt.Sprintf("%s/avatars/%s/%s.%s", cdnURL, u.ID, u.Avatar, imageExtension(u.Avatar))
+}
+
+// BannerURL returns the user's banner URL or an empty string if the user has
+// no banner. Note that the banner is only available when fetching a user with
+// Client.User.
+func (u *User) BannerURL() string {
+	if u.Banner == nil || *u.Banner == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/banners/%s/%s.%s", cdnURL, u.ID, *u.Banner, imageExtension(*u.Banner))
+}
+
+// AvatarDecorationURL returns the URL of the user's avatar decoration or an
+// empty string if the user has none.
+func (u *User) AvatarDecorationURL() string {
+	if u.AvatarDecorationData == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/avatar-decoration-presets/%s.png", cdnURL, u.AvatarDecorationData.Asset)
 }
 
 // User returns a user  given its ID. Use "@me" as the ID to fetch information
@@ -71,8 +123,13 @@ func (c *Client) CurrentUser() *CurrentUserResource {
 	}
 }
 
-// Get returns the current user.
+// Get returns the current user. With a bearer token, it requires the "identify"
+// scope, and the "email" scope for the email of the user to be set.
 func (r *CurrentUserResource) Get(ctx context.Context) (*User, error) {
+	if err := r.client.checkScope(ScopeIdentify); err != nil {
+		return nil, err
+	}
+
 	e := endpoint.GetUser("@me")
 	resp, err := r.client.doReq(ctx, e, nil)
 	if err != nil {
@@ -119,6 +176,37 @@ func (r *CurrentUserResource) Modify(ctx context.Context, username, avatar strin
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var u User
+	if err = json.NewDecoder(resp.Body).Decode(&u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// ModifyWithSettings modifies the current user account settings. Only
+// settings explicitly set will be modified. Returns the updated user
+// on success. Fires a User Update Gateway event.
+func (r *CurrentUserResource) ModifyWithSettings(ctx context.Context, settings *user.Settings) (*User, error) {
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	e := endpoint.ModifyCurrentUser()
+	resp, err := r.client.doReq(ctx, e, jsonPayload(b))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
 	var u User
 	if err = json.NewDecoder(resp.Body).Decode(&u); err != nil {
 		return nil, err
@@ -126,12 +214,39 @@ func (r *CurrentUserResource) Modify(ctx context.Context, username, avatar strin
 	return &u, nil
 }
 
+// SetAvatar sets the avatar of the current user. See user.WithAvatar
+// for the expected format, which can be obtained with ImageData.
+func (r *CurrentUserResource) SetAvatar(ctx context.Context, avatar string) (*User, error) {
+	return r.ModifyWithSettings(ctx, user.NewSettings(user.WithAvatar(avatar)))
+}
+
+// SetAvatarFromFile is like SetAvatar but reads the avatar from a local, on disk
+// image file. See ImageDataFromFile for supported formats.
+func (r *CurrentUserResource) SetAvatarFromFile(ctx context.Context, filepath string) (*User, error) {
+	avatar, err := ImageDataFromFile(filepath, MaxAvatarSize)
+	if err != nil {
+		return nil, err
+	}
+	return r.SetAvatar(ctx, avatar)
+}
+
+// SetBanner sets the banner of the current user. See user.WithBanner
+// for the expected format.
+func (r *CurrentUserResource) SetBanner(ctx context.Context, banner string) (*User, error) {
+	return r.ModifyWithSettings(ctx, user.NewSettings(user.WithBanner(banner)))
+}
+
 // Guilds returns a list of partial guilds the current
 // user is a member of. This endpoint returns at most 100 guilds by
 // default, which is the maximum number of guilds a non-bot user can
 // join. Therefore, pagination is not needed for integrations that need
 // to get a list of users' guilds.
+// With a bearer token, it requires the "guilds" scope.
 func (r *CurrentUserResource) Guilds(ctx context.Context) ([]PartialGuild, error) {
+	if err := r.client.checkScope(ScopeGuilds); err != nil {
+		return nil, err
+	}
+
 	e := endpoint.GetCurrentUserGuilds()
 	resp, err := r.client.doReq(ctx, e, nil)
 	if err != nil {
@@ -219,8 +334,49 @@ func (r *CurrentUserResource) NewDM(ctx context.Context, recipientID string) (*C
 	return &ch, nil
 }
 
+// NewGroupDM creates a new group DM channel with multiple users. The accessTokens
+// are OAuth2 access tokens of users that have granted the application the gdm.join
+// scope. The nicks map user IDs to the nickname they will have in the group DM.
+// Group DMs created this way are not visible in the Discord client.
+// Returns the created channel.
+func (c *Client) NewGroupDM(ctx context.Context, accessTokens []string, nicks map[string]string) (*Channel, error) {
+	st := struct {
+		AccessTokens []string          `json:"access_tokens"`
+		Nicks        map[string]string `json:"nicks,omitempty"`
+	}{
+		AccessTokens: accessTokens,
+		Nicks:        nicks,
+	}
+	b, err := json.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+
+	e := endpoint.CreateDM()
+	resp, err := c.doReq(ctx, e, jsonPayload(b))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var ch Channel
+	if err = json.NewDecoder(resp.Body).Decode(&ch); err != nil {
+		return nil, err
+	}
+	return &ch, nil
+}
+
 // Connections returns a list of connections for the connected user.
+// Requires a bearer token with the "connections" scope, see WithBearerToken.
 func (r *CurrentUserResource) Connections(ctx context.Context) ([]Connection, error) {
+	if err := r.client.requireScope(ScopeConnections); err != nil {
+		return nil, err
+	}
+
 	e := endpoint.GetUserConnections()
 	resp, err := r.client.doReq(ctx, e, nil)
 	if err != nil {
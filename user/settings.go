@@ -0,0 +1,61 @@
+package user
+
+import "github.com/skwair/harmony/optional"
+
+// Settings describes how to modify the current user. All fields are optional
+// and only those explicitly set will be modified.
+type Settings struct {
+	Username *optional.String `json:"username,omitempty"`
+	Avatar   *optional.String `json:"avatar,omitempty"`
+	Banner   *optional.String `json:"banner,omitempty"`
+}
+
+// Setting is a function that configures the current user.
+type Setting func(*Settings)
+
+// NewSettings returns new Settings to modify the current user.
+func NewSettings(opts ...Setting) *Settings {
+	s := &Settings{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithUsername sets the username of the current user. Changing it may
+// cause the user's discriminator to be randomized.
+func WithUsername(name string) Setting {
+	return func(s *Settings) {
+		s.Username = optional.NewString(name)
+	}
+}
+
+// WithAvatar sets the avatar of the current user. avatar is a Data URI
+// scheme that supports JPG, GIF, and PNG formats, such as:
+//
+//     data:image/jpeg;base64,BASE64_ENCODED_JPEG_IMAGE_DATA
+//
+// An empty avatar will remove the current avatar.
+func WithAvatar(avatar string) Setting {
+	return func(s *Settings) {
+		if avatar == "" {
+			s.Avatar = optional.NewNilString()
+		} else {
+			s.Avatar = optional.NewString(avatar)
+		}
+	}
+}
+
+// WithBanner sets the banner of the current user. banner is a Data URI,
+// like for WithAvatar. An empty banner will remove the current banner.
+func WithBanner(banner string) Setting {
+	return func(s *Settings) {
+		if banner == "" {
+			s.Banner = optional.NewNilString()
+		} else {
+			s.Banner = optional.NewString(banner)
+		}
+	}
+}
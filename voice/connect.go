@@ -7,12 +7,14 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"go.uber.org/atomic"
 	"nhooyr.io/websocket"
 
+	"github.com/skwair/harmony/clock"
 	"github.com/skwair/harmony/internal/payload"
 	"github.com/skwair/harmony/log"
 )
@@ -31,14 +33,17 @@ func Connect(ctx context.Context, state *StateUpdate, server *ServerUpdate, opts
 	vc := &Connection{
 		Send:                 make(chan []byte),
 		Recv:                 make(chan *AudioPacket),
+		demux:                newDemuxer(),
 		payloads:             make(chan *payload.Payload),
 		error:                make(chan error),
 		stop:                 make(chan struct{}),
 		state:                &state.State,
 		logger:               log.NewStd(os.Stderr, log.LevelError),
+		clock:                clock.New(),
 		lastHeartbeatACK:     atomic.NewInt64(0),
 		udpHeartbeatSequence: atomic.NewUint64(0),
 		lastUDPHeartbeatACK:  atomic.NewInt64(0),
+		lastAudioSent:        atomic.NewInt64(0),
 		connected:            atomic.NewBool(false),
 		connecting:           atomic.NewBool(false),
 		reconnecting:         atomic.NewBool(false),
@@ -50,6 +55,8 @@ func Connect(ctx context.Context, state *StateUpdate, server *ServerUpdate, opts
 		opt(vc)
 	}
 
+	vc.lastAudioSent.Store(vc.clock.Now().UnixNano())
+
 	if err := vc.connect(ctx, server); err != nil {
 		return nil, err
 	}
@@ -71,7 +78,7 @@ func (vc *Connection) connect(ctx context.Context, server *ServerUpdate) error {
 	var err error
 	vc.endpoint = fmt.Sprintf("wss://%s?v=%d", strings.TrimSuffix(server.Endpoint, ":80"), gatewayVersion)
 	vc.logger.Debugf("connecting to voice server: %s", vc.endpoint)
-	vc.conn, _, err = websocket.Dial(ctx, vc.endpoint, nil)
+	vc.conn, _, err = websocket.Dial(ctx, vc.endpoint, &websocket.DialOptions{HTTPClient: vc.dialClient})
 	if err != nil {
 		return err
 	}
@@ -139,7 +146,7 @@ func (vc *Connection) connect(ctx context.Context, server *ServerUpdate) error {
 	vc.ssrc = vr.SSRC
 
 	// We should now be able to open the voice UDP connection.
-	host := fmt.Sprintf("%s:%d", vr.IP, vr.Port)
+	host := net.JoinHostPort(vr.IP, strconv.Itoa(vr.Port))
 	vc.logger.Debug("resolving voice connection UDP endpoint")
 	vc.dataEndpoint, err = net.ResolveUDPAddr("udp", host)
 	if err != nil {
@@ -147,7 +154,7 @@ func (vc *Connection) connect(ctx context.Context, server *ServerUpdate) error {
 	}
 
 	vc.logger.Debugf("dialing voice connection endpoint: %s", host)
-	vc.udpConn, err = net.DialUDP("udp", nil, vc.dataEndpoint)
+	vc.udpConn, err = vc.dialUDP()
 	if err != nil {
 		return err
 	}
@@ -160,11 +167,15 @@ func (vc *Connection) connect(ctx context.Context, server *ServerUpdate) error {
 
 	// IP discovery.
 	vc.logger.Debug("starting IP discovery")
-	ip, port, err := ipDiscovery(vc.udpConn, vc.ssrc)
+	external, err := ipDiscovery(vc.udpConn, vc.ssrc)
 	if err != nil {
 		return err
 	}
-	vc.logger.Debugf("IP discovery result: %s:%d", ip, port)
+	vc.logger.Debugf("IP discovery result: %s (local address: %s)", external, vc.udpConn.LocalAddr())
+
+	vc.addrMu.Lock()
+	vc.externalAddr = external
+	vc.addrMu.Unlock()
 
 	// Start heartbeating on the UDP connection.
 	vc.wg.Add(1)
@@ -173,8 +184,8 @@ func (vc *Connection) connect(ctx context.Context, server *ServerUpdate) error {
 	sp := &selectProtocol{
 		Protocol: "udp",
 		Data: &selectProtocolData{
-			Address: ip,
-			Port:    port,
+			Address: external.IP.String(),
+			Port:    uint16(external.Port),
 			Mode:    "xsalsa20_poly1305",
 		},
 	}
@@ -309,4 +320,5 @@ func (vc *Connection) Close() {
 	// NOTE: maybe we should explicitly close
 	// other channels here.
 	close(vc.Recv)
+	vc.demux.close()
 }
@@ -3,12 +3,14 @@ package voice
 import (
 	"context"
 	"net"
+	"net/http"
 	"sync"
 	"time"
 
 	"go.uber.org/atomic"
 	"nhooyr.io/websocket"
 
+	"github.com/skwair/harmony/clock"
 	"github.com/skwair/harmony/internal/payload"
 	"github.com/skwair/harmony/log"
 )
@@ -28,6 +30,8 @@ type Connection struct {
 	// Recv is used to receive audio packets
 	// containing Opus encoded audio data.
 	Recv chan *AudioPacket
+	// Dispatches received packets by user, see UserAudio.
+	demux *demuxer
 
 	// General lock for long operations that should
 	// not happen concurrently like Close or SetSpeakingMode.
@@ -46,8 +50,23 @@ type Connection struct {
 	token string
 	// Websocket endpoint to connect to.
 	endpoint string
+	// HTTP client used to dial the websocket endpoint,
+	// nil to use http.DefaultClient.
+	dialClient *http.Client
 	// UDP endpoint to send voice data to.
 	dataEndpoint *net.UDPAddr
+	// Local IP address and port range the UDP connection is bound to,
+	// if set. See WithUDPAddr and WithUDPPortRange.
+	udpIP                  net.IP
+	udpPortMin, udpPortMax int
+	// Maximum number of frames sent at once, see WithWriteBatching.
+	writeBatchSize int
+	// See WithMetricsCollector.
+	metrics MetricsCollector
+	// Local and external addresses of the UDP connection.
+	addrMu       sync.RWMutex
+	localAddr    *net.UDPAddr
+	externalAddr *net.UDPAddr
 
 	connRMu sync.Mutex
 	conn    *websocket.Conn
@@ -103,6 +122,10 @@ type Connection struct {
 	// Accessed atomically, sequence number of the last
 	// UDP heartbeat we sent.
 	udpHeartbeatSequence *atomic.Uint64
+	// Accessed atomically, UNIX timestamp in nanoseconds
+	// of the last audio packet sent. Initially set to
+	// the time the connection was established.
+	lastAudioSent *atomic.Int64
 
 	// opusReadinessWG is a wait group used to make sure
 	// the Opus sender and receiver are correctly started
@@ -110,6 +133,9 @@ type Connection struct {
 	opusReadinessWG sync.WaitGroup
 
 	logger log.Logger
+	// Clock used for heartbeats, reconnection delays and
+	// tracking when audio was last sent. See WithClock.
+	clock clock.Clock
 }
 
 // Logger is here to make the logger available to third party packages that
@@ -126,6 +152,13 @@ func (vc *Connection) State() *State {
 	return vc.state
 }
 
+// LastAudioSent returns the time at which the last audio packet was sent
+// through this connection. If no audio was sent yet, it returns the time
+// at which the connection was established.
+func (vc *Connection) LastAudioSent() time.Time {
+	return time.Unix(0, vc.lastAudioSent.Load())
+}
+
 // SetState updates the state for this voice connections.
 func (vc *Connection) SetState(s *State) {
 	vc.stateMu.Lock()
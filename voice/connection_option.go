@@ -1,6 +1,13 @@
 package voice
 
-import "github.com/skwair/harmony/log"
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/skwair/harmony/clock"
+	"github.com/skwair/harmony/internal/proxy"
+	"github.com/skwair/harmony/log"
+)
 
 // ConnectionOption is a function that configures a Connection.
 // It is used in Connect.
@@ -14,3 +21,51 @@ func WithLogger(l log.Logger) ConnectionOption {
 		c.logger = l
 	}
 }
+
+// WithClock can be used to set the clock used by this connection for its
+// heartbeats, reconnection delays and to track when audio was last sent.
+// Defaults to the real clock, see clock.New.
+func WithClock(clk clock.Clock) ConnectionOption {
+	return func(c *Connection) {
+		c.clock = clk
+	}
+}
+
+// WithProxy can be used to set the proxy the voice websocket is dialed through.
+// The URL scheme can be http or https for proxies supporting the CONNECT method,
+// or socks5 for SOCKS5 proxies. A nil URL disables proxies. Voice data is still
+// sent directly over UDP.
+// Defaults to the proxy set by the HTTPS_PROXY and NO_PROXY environment
+// variables, if any, see http.ProxyFromEnvironment.
+func WithProxy(u *url.URL) ConnectionOption {
+	return func(c *Connection) {
+		c.dialClient = proxy.Client(u)
+	}
+}
+
+// WithHTTPClient can be used to set the HTTP client used to dial the voice
+// websocket, for instance to configure its proxy or TLS settings.
+// Defaults to http.DefaultClient.
+func WithHTTPClient(client *http.Client) ConnectionOption {
+	return func(c *Connection) {
+		c.dialClient = client
+	}
+}
+
+// MetricsCollector is the interface to implement in order to export metrics
+// about voice connections. harmony.MetricsCollector implements it.
+type MetricsCollector interface {
+	// ObserveVoicePackets is called each time audio packets are sent
+	// or received, with their number and size. It must be safe for
+	// concurrent use.
+	ObserveVoicePackets(sent bool, packets, bytes int)
+}
+
+// WithMetricsCollector can be used to set the MetricsCollector metrics about
+// this connection are sent to.
+// Defaults to nil, no metrics are collected.
+func WithMetricsCollector(m MetricsCollector) ConnectionOption {
+	return func(c *Connection) {
+		c.metrics = m
+	}
+}
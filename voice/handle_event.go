@@ -1,10 +1,6 @@
 package voice
 
-import (
-	"time"
-
-	"github.com/skwair/harmony/internal/payload"
-)
+import "github.com/skwair/harmony/internal/payload"
 
 func (vc *Connection) handleEvent(p *payload.Payload) error {
 	switch p.Op {
@@ -19,7 +15,7 @@ func (vc *Connection) handleEvent(p *payload.Payload) error {
 	// Heartbeat ACK.
 	case voiceOpcodeHeartbeatACK:
 		// TODO: Check nonce ?
-		vc.lastHeartbeatACK.Store(time.Now().UnixNano())
+		vc.lastHeartbeatACK.Store(vc.clock.Now().UnixNano())
 
 	// Resume acknowledged by the voice server.
 	case voiceOpcodeResumed:
@@ -27,10 +23,15 @@ func (vc *Connection) handleEvent(p *payload.Payload) error {
 			vc.payloads <- p
 		}
 
+	// A user started speaking, mapping their SSRC to their user ID.
+	case voiceOpcodeSpeaking:
+		return vc.demux.handleSpeaking(p.D)
+
 	// A client has disconnected from the voice channel.
 	case voiceOpcodeClientDisconnect:
 		// TODO: add a way to register to those events.
 		// Example payload: {code: 13, data: {"user_id":"220152355228164927"}}
+		return vc.demux.handleClientDisconnect(p.D)
 	}
 
 	return nil
@@ -15,6 +15,7 @@ func (vc *Connection) heartbeat(every time.Duration) {
 	defer vc.logger.Debug("stopped voice connection heartbeater")
 
 	heartbeat.Run(
+		vc.clock,
 		every,
 		vc.sendHeartbeatPayload,
 		vc.lastHeartbeatACK,
@@ -26,7 +27,7 @@ func (vc *Connection) heartbeat(every time.Duration) {
 // sendHeartbeatPayload sends a single heartbeat payload
 // to the voice server containing a nonce.
 func (vc *Connection) sendHeartbeatPayload() error {
-	return vc.sendPayload(vc.ctx, voiceOpcodeHeartbeat, time.Now().Unix())
+	return vc.sendPayload(vc.ctx, voiceOpcodeHeartbeat, vc.clock.Now().Unix())
 }
 
 // udpHeartbeat periodically sends a UDP heartbeat packet to the voice server.
@@ -37,6 +38,7 @@ func (vc *Connection) udpHeartbeat(every time.Duration) {
 	defer vc.logger.Debug("stopped UDP heartbeater")
 
 	heartbeat.RunUDP(
+		vc.clock,
 		time.Second*5,
 		vc.sendUDPHeartbeat,
 		vc.lastUDPHeartbeatACK,
@@ -3,33 +3,74 @@ package voice
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"net"
-	"strings"
+	"time"
 )
 
+const (
+	// ipDiscoveryAttempts is the number of IP discovery requests sent before
+	// giving up, since UDP packets can be lost.
+	ipDiscoveryAttempts = 3
+	// ipDiscoveryTimeout is the time to wait for each IP discovery response.
+	ipDiscoveryTimeout = 2 * time.Second
+	// ipDiscoverySize is the size of IP discovery packets: the SSRC, the address
+	// as a null terminated string and the port.
+	ipDiscoverySize = 70
+)
+
+// ErrUDPBlocked is returned when connecting to a voice server that does not answer
+// over UDP, usually because a firewall blocks UDP traffic. Voice data can only be
+// sent and received over UDP: there is no TCP or websocket fallback.
+var ErrUDPBlocked = errors.New("voice: no response from the voice server over UDP, it may be blocked by a firewall (voice has no TCP or websocket fallback)")
+
 // ipDiscovery uses Discord's IP discovery service to get the external ip and port the
 // given UDP connection is using.
-func ipDiscovery(conn *net.UDPConn, ssrc uint32) (ip string, port uint16, err error) {
-	b := make([]byte, 70)
-	binary.BigEndian.PutUint32(b, ssrc)
-	if _, err = conn.Write(b); err != nil {
-		return "", 0, err
-	}
+func ipDiscovery(conn *net.UDPConn, ssrc uint32) (*net.UDPAddr, error) {
+	req := make([]byte, ipDiscoverySize)
+	binary.BigEndian.PutUint32(req, ssrc)
+
+	// Do not leave a deadline on the connection once done.
+	defer conn.SetReadDeadline(time.Time{})
+
+	b := make([]byte, ipDiscoverySize)
+	for attempt := 0; attempt < ipDiscoveryAttempts; attempt++ {
+		if _, err := conn.Write(req); err != nil {
+			return nil, err
+		}
 
-	b = make([]byte, 70)
-	l, err := conn.Read(b)
-	if err != nil {
-		return "", 0, err
+		if err := conn.SetReadDeadline(time.Now().Add(ipDiscoveryTimeout)); err != nil {
+			return nil, err
+		}
+		l, err := conn.Read(b)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return nil, err
+		}
+		if l < ipDiscoverySize {
+			return nil, errors.New("ipDiscovery: did not receive enough bytes")
+		}
+		return parseIPDiscovery(b)
 	}
-	if l < 70 {
-		return "", 0, errors.New("ipDiscovery: did not receive enough bytes")
+	return nil, ErrUDPBlocked
+}
+
+// parseIPDiscovery parses the address in the given IP discovery response.
+func parseIPDiscovery(b []byte) (*net.UDPAddr, error) {
+	end := 4
+	for end < ipDiscoverySize-2 && b[end] != 0 {
+		end++
 	}
 
-	s := strings.Builder{}
-	for i := 4; b[i] != 0; i++ {
-		if err = s.WriteByte(b[i]); err != nil {
-			return "", 0, err
-		}
+	ip := net.ParseIP(string(b[4:end]))
+	if ip == nil {
+		return nil, fmt.Errorf("ipDiscovery: invalid IP address %q", b[4:end])
 	}
-	return s.String(), binary.LittleEndian.Uint16(b[68:70]), nil
+	return &net.UDPAddr{
+		IP:   ip,
+		Port: int(binary.LittleEndian.Uint16(b[ipDiscoverySize-2:])),
+	}, nil
 }
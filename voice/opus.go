@@ -18,7 +18,9 @@ type AudioPacket struct {
 	Sequence  uint16
 	Timestamp uint32
 	SSRC      uint32
-	Opus      []byte
+	// ID of the user that sent this packet, if known. See Connection.SSRCUser.
+	UserID string
+	Opus   []byte
 }
 
 // rtpFrame is a raw RTP frame, along with its size.
@@ -51,14 +53,15 @@ func (vc *Connection) opusReceiver() {
 				SSRC:      binary.BigEndian.Uint32(frame.raw[8:12]),
 			}
 			copy(nonce[:], frame.raw[0:12])
-			p.Opus, _ = secretbox.Open(nil, frame.raw[12:frame.size], &nonce, &vc.secret)
-
-			// If the RTP extension bit is set, we must remove the
-			// first 8 bytes corresponding to the header extension,
-			// else the opus signal will be invalid.
-			if len(p.Opus) > 8 && frame.raw[0] == 0x90 {
-				p.Opus = p.Opus[8:]
+			decrypted, ok := secretbox.Open(nil, frame.raw[12:frame.size], &nonce, &vc.secret)
+			if !ok {
+				continue
 			}
+			// If the RTP extension bit is set, we must remove the
+			// header extension, else the opus signal will be invalid.
+			p.Opus = rtpPayload(frame.raw[0], decrypted)
+
+			vc.demux.dispatch(p)
 
 			// Drop the packet if no one is receiving
 			// on the other end of the channel.
@@ -96,7 +99,7 @@ func (vc *Connection) readUDP(ch chan<- *rtpFrame) {
 
 		// Handle UDP heartbeat ACK.
 		if l == 8 {
-			vc.lastUDPHeartbeatACK.Store(time.Now().UnixNano())
+			vc.lastUDPHeartbeatACK.Store(vc.clock.Now().UnixNano())
 
 			// TODO: check the sequence number in the UDP heartbeat ?
 			// udpSeq := binary.LittleEndian.Uint64(buf[:l])
@@ -112,6 +115,10 @@ func (vc *Connection) readUDP(ch chan<- *rtpFrame) {
 			continue
 		}
 
+		if vc.metrics != nil {
+			vc.metrics.ObserveVoicePackets(false, 1, l)
+		}
+
 		// Only send voice data through the channel if someone is listening
 		// on the other side, else we'll just block forever.
 		select {
@@ -121,6 +128,18 @@ func (vc *Connection) readUDP(ch chan<- *rtpFrame) {
 	}
 }
 
+const (
+	sampleRate = 48000 // In Hz, the number of samples we take each second.
+	frameSize  = 960   // This is the number of samples we send at each interval,
+	// 960 samples at 48000Hz represents 20 milliseconds of audio.
+	// 960/(48000/1000) = 20
+
+	// rtpHeaderSize is the size of the header of RTP packets we send.
+	rtpHeaderSize = 12
+	// maxPacketSize is the size of the largest packet we send.
+	maxPacketSize = rtpHeaderSize + maxOpusFrameSize + secretbox.Overhead
+)
+
 // opusSender creates, encrypts and sends Opus encoded packets sent through the voice
 // connection's Send channel.
 func (vc *Connection) opusSender() {
@@ -130,13 +149,6 @@ func (vc *Connection) opusSender() {
 	vc.logger.Debug("starting Opus sender")
 	defer vc.logger.Debug("stopped Opus sender")
 
-	const (
-		sampleRate = 48000 // In Hz, the number of samples we take each second.
-		frameSize  = 960   // This is the number of samples we send at each interval,
-		// 960 samples at 48000Hz represents 20 milliseconds of audio.
-		// 960/(48000/1000) = 20
-	)
-
 	// According to the RTP RFC, the initial value of the sequence number
 	// SHOULD be random (unpredictable) to make known-plaintext attacks
 	// on encryption more difficult.
@@ -154,29 +166,35 @@ func (vc *Connection) opusSender() {
 	}
 	timestamp := uint32(r.Uint64())
 
-	var nonce [24]byte
-	rtpHeader := make([]byte, 12)
-	// Set the static part of the RTP header.
-	rtpHeader[0] = 0x80
-	rtpHeader[1] = 0x78
-	binary.BigEndian.PutUint32(rtpHeader[8:], vc.ssrc)
+	enc := newPacketEncoder(vc.ssrc, &vc.secret, seq, timestamp)
+
+	// Packets are encrypted in buffers allocated once,
+	// one per frame of a batch, see WithWriteBatching.
+	batchSize := vc.writeBatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	packets := make([][]byte, batchSize)
+	for i := range packets {
+		packets[i] = make([]byte, 0, maxPacketSize)
+	}
+	w := newBatchWriter(vc.udpConn, batchSize)
 
 	ticker := time.NewTicker(time.Millisecond * time.Duration(frameSize/(sampleRate/1000)))
 	defer ticker.Stop()
 
 	for {
+		var data []byte
 		select {
-		case data := <-vc.Send:
-			// Set the dynamic part of the RTP header.
-			binary.BigEndian.PutUint16(rtpHeader[2:], seq)
-			binary.BigEndian.PutUint32(rtpHeader[4:], timestamp)
-
-			// Generate the nonce from the rtpHeader. Since the RTP header is only 12 bytes
-			// long, it will leave the 12 trailing bytes of the nonce null, as specified by
-			// https://discord.com/developers/docs/topics/voice-connections#encrypting-and-sending-voice.
-			copy(nonce[:], rtpHeader)
+		case data = <-vc.Send:
+		case <-vc.stop:
+			return
+		}
 
-			buf := secretbox.Seal(rtpHeader, data, &nonce, &vc.secret)
+		n := 0
+		for {
+			packets[n] = enc.encode(packets[n][:0], data)
+			n++
 
 			// Send voice packets at regular interval.
 			// The ticker will drop ticks if we don't
@@ -184,30 +202,92 @@ func (vc *Connection) opusSender() {
 			// nothing to send.
 			<-ticker.C
 
-			_, err = vc.udpConn.Write(buf)
-			if err != nil {
-				// Silently break out of this loop because
-				// the connection was closed by the client.
-				if isConnectionClosed(err) {
-					return
-				}
+			if n == batchSize {
+				break
+			}
+			// Keep batching frames as long as the next one is ready.
+			ready := false
+			select {
+			case data = <-vc.Send:
+				ready = true
+			default:
+			}
+			if !ready {
+				break
+			}
+		}
 
-				vc.reportErr(err)
+		if err = w.write(packets[:n]); err != nil {
+			// Silently break out of this loop because
+			// the connection was closed by the client.
+			if isConnectionClosed(err) {
 				return
 			}
 
-			// Increase the sequence number. Since this is an unsigned
-			// int16, it will reset to 0 when reaching its max value.
-			seq++
+			vc.reportErr(err)
+			return
+		}
 
-			timestamp += frameSize
+		vc.lastAudioSent.Store(vc.clock.Now().UnixNano())
 
-		case <-vc.stop:
-			return
+		if vc.metrics != nil {
+			size := 0
+			for _, p := range packets[:n] {
+				size += len(p)
+			}
+			vc.metrics.ObserveVoicePackets(true, n, size)
 		}
 	}
 }
 
+// packetEncoder builds and encrypts the RTP packets of a stream of Opus frames.
+type packetEncoder struct {
+	header    [rtpHeaderSize]byte
+	nonce     [24]byte
+	secret    *[32]byte
+	seq       uint16
+	timestamp uint32
+}
+
+// newPacketEncoder returns a packet encoder for the stream of the given SSRC,
+// starting at the given sequence number and timestamp.
+func newPacketEncoder(ssrc uint32, secret *[32]byte, seq uint16, timestamp uint32) *packetEncoder {
+	e := &packetEncoder{
+		secret:    secret,
+		seq:       seq,
+		timestamp: timestamp,
+	}
+	// Set the static part of the RTP header.
+	e.header[0] = 0x80
+	e.header[1] = 0x78
+	binary.BigEndian.PutUint32(e.header[8:], ssrc)
+	return e
+}
+
+// encode appends the encrypted RTP packet of the given Opus frame to dst and
+// returns the extended buffer. It does not allocate if dst has enough capacity
+// for the packet, see maxPacketSize.
+func (e *packetEncoder) encode(dst, opus []byte) []byte {
+	// Set the dynamic part of the RTP header.
+	binary.BigEndian.PutUint16(e.header[2:], e.seq)
+	binary.BigEndian.PutUint32(e.header[4:], e.timestamp)
+
+	// Generate the nonce from the rtpHeader. Since the RTP header is only 12 bytes
+	// long, it will leave the 12 trailing bytes of the nonce null, as specified by
+	// https://discord.com/developers/docs/topics/voice-connections#encrypting-and-sending-voice.
+	copy(e.nonce[:], e.header[:])
+
+	dst = append(dst, e.header[:]...)
+	dst = secretbox.Seal(dst, opus, &e.nonce, e.secret)
+
+	// Increase the sequence number. Since this is an unsigned
+	// int16, it will reset to 0 when reaching its max value.
+	e.seq++
+	e.timestamp += frameSize
+
+	return dst
+}
+
 func isConnectionClosed(err error) bool {
 	if e, ok := err.(*net.OpError); ok {
 		// Ugly but : https://github.com/golang/go/issues/4373
@@ -3,6 +3,7 @@ package voice
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/skwair/harmony/internal/payload"
 )
@@ -24,6 +25,8 @@ type State struct {
 	SelfMute   bool    `json:"self_mute"`
 	SelfStream bool    `json:"self_stream"`
 	Suppress   bool    `json:"suppress"` // Whether this user is muted by the current user.
+	// Time at which the user requested to speak in a stage channel.
+	RequestToSpeakTimestamp *time.Time `json:"request_to_speak_timestamp"`
 }
 
 // Clone returns a clone of this StateUpdate.
@@ -42,6 +45,8 @@ func (v *State) Clone() *State {
 		SelfDeaf:  v.SelfDeaf,
 		SelfMute:  v.SelfMute,
 		Suppress:  v.Suppress,
+
+		RequestToSpeakTimestamp: v.RequestToSpeakTimestamp,
 	}
 }
 
@@ -0,0 +1,306 @@
+package voice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrPlaying is returned by Player.Play when the player is already playing.
+	ErrPlaying = errors.New("voice: player is already playing")
+	// ErrStopped is returned by Player.Play when playback is stopped with Player.Stop.
+	ErrStopped = errors.New("voice: playback stopped")
+)
+
+const (
+	// silenceFrames is the number of silence frames sent when audio stops, to
+	// avoid unintended Opus interpolation with subsequent transmissions.
+	silenceFrames = 5
+	// maxLag is how late frames can be sent before pacing starts over.
+	maxLag = 100 * time.Millisecond
+)
+
+// Player plays audio from sources on a voice connection, sending frames at a
+// regular pace and setting the speaking mode of the connection accordingly.
+// It plays one source at a time and is safe for concurrent use. Create one
+// with NewPlayer.
+//
+// Only one Player (or any other sender of audio) is meant to be used at
+// once on the same voice connection.
+type Player struct {
+	vc *Connection
+
+	mu      sync.Mutex
+	playing bool
+	paused  bool
+	// Closed when the player is resumed, replaced on each pause.
+	resume chan struct{}
+	// Closed when playback is stopped, replaced on each Play.
+	stop chan struct{}
+}
+
+// NewPlayer returns a new Player sending audio through the given connection.
+func NewPlayer(vc *Connection) *Player {
+	return &Player{vc: vc}
+}
+
+// Play plays the given source until it has no more frames, blocking meanwhile.
+// It returns ErrStopped if playback is stopped with Stop before, or ctx.Err() if
+// ctx is done. The connection is marked as speaking while audio is sent.
+func (p *Player) Play(ctx context.Context, src Source) error {
+	p.mu.Lock()
+	if p.playing {
+		p.mu.Unlock()
+		return ErrPlaying
+	}
+	p.playing = true
+	p.paused = false
+	p.stop = make(chan struct{})
+	stop := p.stop
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.playing = false
+		p.paused = false
+		p.mu.Unlock()
+	}()
+
+	if err := p.vc.SetSpeakingMode(SpeakingModeMicrophone); err != nil {
+		return err
+	}
+
+	err := p.play(ctx, src, stop)
+
+	// Send a few frames of silence so the audio does not cut off abruptly,
+	// unless ctx is done and the caller does not want to wait anymore.
+	if ctx.Err() == nil {
+		p.silence(ctx)
+	}
+	if serr := p.vc.SetSpeakingMode(SpeakingModeOff); err == nil {
+		err = serr
+	}
+	return err
+}
+
+func (p *Player) play(ctx context.Context, src Source, stop chan struct{}) error {
+	clk := p.vc.clock
+	next := clk.Now()
+
+	for {
+		if resume := p.pausedChan(); resume != nil {
+			p.silence(ctx)
+			if err := p.vc.SetSpeakingMode(SpeakingModeOff); err != nil {
+				return err
+			}
+
+			select {
+			case <-resume:
+			case <-stop:
+				return ErrStopped
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			if err := p.vc.SetSpeakingMode(SpeakingModeMicrophone); err != nil {
+				return err
+			}
+			next = clk.Now()
+		}
+
+		frame, err := src.ReadFrame()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Pace frames from the time the first one was sent rather than from
+		// the last one, so delays do not accumulate. If we fell behind by
+		// more than maxLag, start over instead of bursting.
+		if d := clk.Until(next); d > 0 {
+			select {
+			case <-clk.After(d):
+			case <-stop:
+				return ErrStopped
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		} else if d < -maxLag {
+			next = clk.Now()
+		}
+		next = next.Add(FrameDuration)
+
+		select {
+		case p.vc.Send <- frame:
+		case <-stop:
+			return ErrStopped
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// silence sends silence frames, giving up if it takes too long,
+// for instance because the connection was closed.
+func (p *Player) silence(ctx context.Context) {
+	timeout := p.vc.clock.After(2 * silenceFrames * FrameDuration)
+	for i := 0; i < silenceFrames; i++ {
+		select {
+		case p.vc.Send <- SilenceFrame:
+		case <-timeout:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pausedChan returns a channel closed when the player is
+// resumed if it is paused, nil otherwise.
+func (p *Player) pausedChan() chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.paused {
+		return nil
+	}
+	return p.resume
+}
+
+// Pause pauses playback. It does nothing if the player is not playing or
+// already paused.
+func (p *Player) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.playing || p.paused {
+		return
+	}
+	p.paused = true
+	p.resume = make(chan struct{})
+}
+
+// Resume resumes playback after a pause.
+func (p *Player) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.paused {
+		return
+	}
+	p.paused = false
+	close(p.resume)
+}
+
+// Stop stops playback, making Play return ErrStopped.
+// It does nothing if the player is not playing.
+func (p *Player) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.playing {
+		return
+	}
+	select {
+	case <-p.stop:
+	default:
+		close(p.stop)
+	}
+}
+
+// Playing returns whether the player is playing a source, even if paused.
+func (p *Player) Playing() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.playing
+}
+
+// Paused returns whether playback is paused.
+func (p *Player) Paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.paused
+}
+
+// OpenFunc opens the audio of the given source, as set in Track.Source,
+// starting at the given offset. See NewQueuePlayer.
+type OpenFunc func(source string, offset time.Duration) (Source, error)
+
+// NewQueuePlayer returns a QueuePlayer playing tracks of a Queue with the given
+// player. Sources of tracks are opened with open, typically by starting ffmpeg
+// to decode the audio file or URL of the track from the given offset. If the
+// opened source implements io.Closer, it is closed once played. Errors occurring
+// while playing a track end it, as if it had been played entirely.
+func NewQueuePlayer(p *Player, open OpenFunc) QueuePlayer {
+	return &queuePlayer{player: p, open: open}
+}
+
+type queuePlayer struct {
+	player *Player
+	open   OpenFunc
+
+	mu     sync.Mutex
+	done   chan struct{}
+	cancel context.CancelFunc
+}
+
+// PlayFrom implements the QueuePlayer interface.
+func (qp *queuePlayer) PlayFrom(source string, offset time.Duration) error {
+	src, err := qp.open(source, offset)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	qp.mu.Lock()
+	qp.done = done
+	qp.cancel = cancel
+	qp.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		defer cancel()
+
+		_ = qp.player.Play(ctx, src)
+		if c, ok := src.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}()
+
+	return nil
+}
+
+// Stop implements the QueuePlayer interface.
+func (qp *queuePlayer) Stop() {
+	qp.mu.Lock()
+	cancel := qp.cancel
+	qp.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	// Stopping the player sends silence frames before Play returns,
+	// but does nothing if Play was not called yet.
+	if qp.player.Playing() {
+		qp.player.Stop()
+	} else {
+		cancel()
+	}
+}
+
+// Done implements the QueuePlayer interface.
+func (qp *queuePlayer) Done() <-chan struct{} {
+	qp.mu.Lock()
+	defer qp.mu.Unlock()
+
+	return qp.done
+}
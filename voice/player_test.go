@@ -0,0 +1,138 @@
+package voice_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/harmonytest"
+	"github.com/skwair/harmony/voice"
+)
+
+// opusTrack is an Opus source of the given number of frames,
+// recording whether it was closed.
+type opusTrack struct {
+	voice.Source
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func newOpusTrack(frames int) *opusTrack {
+	var b bytes.Buffer
+	for i := 0; i < frames; i++ {
+		_ = binary.Write(&b, binary.LittleEndian, int16(len(voice.SilenceFrame)))
+		b.Write(voice.SilenceFrame)
+	}
+	return &opusTrack{Source: voice.NewOpusSource(&b)}
+}
+
+func (t *opusTrack) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	return nil
+}
+
+func (t *opusTrack) isClosed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.closed
+}
+
+func TestQueuePlayer(t *testing.T) {
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+	srv.AddGuild(&harmony.Guild{ID: "1", Name: "guild"})
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err = c.Connect(ctx); err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer c.Disconnect()
+
+	vc, err := c.JoinVoiceChannel(ctx, "1", "100", false, false, harmony.WithVoiceConnectionOptions(srv.VoiceConnectionOptions()...))
+	if err != nil {
+		t.Fatalf("could not join voice channel: %v", err)
+	}
+	defer vc.Close()
+
+	// The first track lasts long enough to be skipped.
+	sources := map[string]*opusTrack{
+		"long":  newOpusTrack(1000),
+		"short": newOpusTrack(3),
+	}
+	var (
+		mu     sync.Mutex
+		opened []string
+	)
+	open := func(source string, offset time.Duration) (voice.Source, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		opened = append(opened, source)
+		return sources[source], nil
+	}
+
+	q, err := voice.NewQueue("1", voice.NewQueuePlayer(voice.NewPlayer(vc), open), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ended := make(chan struct{})
+	q.OnNowPlaying(func(track voice.Track) {
+		if track.Source == "short" {
+			close(ended)
+		}
+	})
+
+	if err = q.Enqueue(voice.Track{Source: "long"}, voice.Track{Source: "short"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = q.Skip(); err != nil {
+		t.Fatal(err)
+	}
+	if !sources["long"].isClosed() {
+		t.Error("expected the skipped track to be closed")
+	}
+
+	select {
+	case <-ended:
+	case <-ctx.Done():
+		t.Fatal("expected the second track to be played")
+	}
+
+	// Once the short track ended, the queue is empty.
+	for {
+		if _, _, playing := q.NowPlaying(); !playing {
+			break
+		}
+		select {
+		case <-time.After(time.Millisecond):
+		case <-ctx.Done():
+			t.Fatal("expected the queue to stop playing")
+		}
+	}
+	if !sources["short"].isClosed() {
+		t.Error("expected the played track to be closed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(opened) != 2 || opened[0] != "long" || opened[1] != "short" {
+		t.Errorf("expected tracks long and short to be opened, got %v", opened)
+	}
+}
@@ -0,0 +1,403 @@
+package voice
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/skwair/harmony/clock"
+)
+
+var (
+	// ErrQueueNotPlaying is returned by Queue.Skip and Queue.Seek when nothing is being played.
+	ErrQueueNotPlaying = errors.New("voice: nothing is being played")
+	// ErrSeekPastEnd is returned by Queue.Seek when seeking past the end of the current track.
+	ErrSeekPastEnd = errors.New("voice: position is past the end of the track")
+)
+
+// Track is an audio track that can be added to a Queue.
+type Track struct {
+	// Source is the audio to play, as understood by the
+	// QueuePlayer, such as the path or URL of an audio file.
+	Source string
+	// Title is a human readable name for the track.
+	Title string
+	// Duration of the track, if known.
+	Duration time.Duration
+	// ID of the user that requested this track.
+	RequestedBy string
+	// Metadata holds arbitrary information about
+	// the track (artist, album, thumbnail, etc.).
+	Metadata map[string]string
+}
+
+// LoopMode dictates what a Queue does when a track ends.
+type LoopMode int
+
+// Available loop modes.
+const (
+	// LoopOff plays tracks in order and removes
+	// them from the queue once they ended.
+	LoopOff LoopMode = iota
+	// LoopTrack plays the current track over and over.
+	LoopTrack
+	// LoopQueue appends tracks to the end of the
+	// queue once they ended.
+	LoopQueue
+)
+
+// QueuePlayer plays the tracks of a Queue, one at a time. It typically decodes
+// tracks with a tool such as ffmpeg and sends the audio to a voice connection.
+// See NewQueuePlayer to play tracks with a Player.
+type QueuePlayer interface {
+	// PlayFrom starts playing the given source, as set in Track.Source, at the
+	// given offset and returns without waiting for the track to end.
+	PlayFrom(source string, offset time.Duration) error
+	// Stop stops the track being played, if any.
+	Stop()
+	// Done returns a channel closed when the track being played ends, either
+	// because it was played entirely or because it was stopped. It returns nil
+	// if no track was ever played.
+	Done() <-chan struct{}
+}
+
+// QueueStore is used to persist the content of queues so they can be
+// restored, for example after the bot restarted.
+type QueueStore interface {
+	// SaveQueue saves the tracks of the queue for the given guild.
+	// The first track, if any, is the one currently being played.
+	SaveQueue(guildID string, tracks []Track) error
+	// LoadQueue returns the tracks that were saved for the given guild.
+	LoadQueue(guildID string) ([]Track, error)
+}
+
+// Queue wraps a QueuePlayer and plays tracks one after another.
+// All methods are safe for concurrent use. Create one with NewQueue.
+type Queue struct {
+	mu sync.Mutex
+
+	guildID string
+	player  QueuePlayer
+	store   QueueStore
+	clock   clock.Clock
+
+	// Pending tracks. When something is being
+	// played, the first track is the current one.
+	tracks  []Track
+	playing bool
+	loop    LoopMode
+
+	// Position at which the current track was started
+	// and when it was started, used to compute the
+	// elapsed time of the current track.
+	offset    time.Duration
+	startedAt time.Time
+
+	// Incremented each time a track is (re)started, so that
+	// the goroutine waiting for a track to end can tell whether
+	// it ended naturally or was replaced (skipped or seeked).
+	generation int
+
+	// Incremented each time the tracks change, and version
+	// last saved to the store, see save. saveMu serializes
+	// saves, which happen without holding mu.
+	version      int
+	saveMu       sync.Mutex
+	savedVersion int
+
+	onNowPlaying func(Track)
+	onError      func(error)
+}
+
+// QueueOption is a function that configures a Queue.
+type QueueOption func(*Queue)
+
+// WithQueueClock sets the clock used to measure the elapsed time of tracks,
+// mainly for testing purposes. Defaults to the system clock.
+func WithQueueClock(clk clock.Clock) QueueOption {
+	return func(q *Queue) {
+		q.clock = clk
+	}
+}
+
+// NewQueue returns a new queue that plays tracks of the given guild on the given
+// player. If store is not nil, the queue will be restored from it and saved to
+// it each time it changes. Call Play to start playing restored tracks.
+func NewQueue(guildID string, player QueuePlayer, store QueueStore, opts ...QueueOption) (*Queue, error) {
+	q := &Queue{
+		guildID: guildID,
+		player:  player,
+		store:   store,
+		clock:   clock.New(),
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	if store != nil {
+		tracks, err := store.LoadQueue(guildID)
+		if err != nil {
+			return nil, err
+		}
+		q.tracks = tracks
+	}
+
+	return q, nil
+}
+
+// OnNowPlaying registers a callback that is called each time a new
+// track starts playing.
+func (q *Queue) OnNowPlaying(f func(Track)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.onNowPlaying = f
+}
+
+// OnError registers a callback that is called when an error occurs while
+// automatically advancing to the next track or saving the queue. Errors
+// are ignored by default.
+func (q *Queue) OnError(f func(error)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.onError = f
+}
+
+// Enqueue adds the given tracks at the end of the queue. If nothing is being
+// played, it starts playing the first track of the queue.
+func (q *Queue) Enqueue(tracks ...Track) error {
+	defer q.save()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.tracks = append(q.tracks, tracks...)
+	q.version++
+
+	if q.playing {
+		return nil
+	}
+	return q.playCurrent(0)
+}
+
+// Play starts playing the queue if it is not already playing.
+func (q *Queue) Play() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.playing {
+		return nil
+	}
+	return q.playCurrent(0)
+}
+
+// Skip stops the current track and starts playing the next one, regardless of
+// the loop mode (except for LoopQueue which still puts the skipped track at
+// the end of the queue).
+func (q *Queue) Skip() error {
+	defer q.save()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.playing {
+		return ErrQueueNotPlaying
+	}
+
+	q.stopCurrent()
+	q.advance(true)
+	return q.playCurrent(0)
+}
+
+// Seek restarts the current track at the given position.
+func (q *Queue) Seek(pos time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.playing {
+		return ErrQueueNotPlaying
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	if d := q.tracks[0].Duration; d > 0 && pos >= d {
+		return ErrSeekPastEnd
+	}
+
+	q.stopCurrent()
+	return q.playCurrent(pos)
+}
+
+// SetLoopMode sets the loop mode of the queue.
+func (q *Queue) SetLoopMode(mode LoopMode) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.loop = mode
+}
+
+// LoopMode returns the current loop mode of the queue.
+func (q *Queue) LoopMode() LoopMode {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.loop
+}
+
+// NowPlaying returns the track currently being played along with its
+// elapsed time. It returns false if nothing is being played.
+func (q *Queue) NowPlaying() (Track, time.Duration, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.playing {
+		return Track{}, 0, false
+	}
+	return q.tracks[0], q.offset + q.clock.Since(q.startedAt), true
+}
+
+// Tracks returns a copy of the tracks in the queue. If something is being
+// played, the first track is the current one.
+func (q *Queue) Tracks() []Track {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	tracks := make([]Track, len(q.tracks))
+	copy(tracks, q.tracks)
+	return tracks
+}
+
+// Clear stops the current track, if any, and removes every track from the queue.
+func (q *Queue) Clear() {
+	defer q.save()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.stopCurrent()
+	q.tracks = nil
+	q.version++
+}
+
+// stopCurrent stops the track being played, if any, and waits for the player to
+// be idle. Must be called with q.mu held.
+func (q *Queue) stopCurrent() {
+	if !q.playing {
+		return
+	}
+
+	// Invalidate the goroutine waiting for this track to end.
+	q.generation++
+	q.playing = false
+
+	done := q.player.Done()
+	q.player.Stop()
+	if done != nil {
+		<-done
+	}
+}
+
+// advance removes the current track from the head of the queue according to
+// the loop mode. If skip is true, LoopTrack is ignored. Must be called with
+// q.mu held.
+func (q *Queue) advance(skip bool) {
+	if len(q.tracks) == 0 {
+		return
+	}
+
+	switch {
+	case q.loop == LoopTrack && !skip:
+		// Keep the current track at the head of the queue.
+	case q.loop == LoopQueue:
+		q.tracks = append(q.tracks[1:], q.tracks[0])
+	default:
+		q.tracks = q.tracks[1:]
+	}
+	q.version++
+}
+
+// playCurrent starts playing the track at the head of the queue at the given
+// offset. It is a no-op if the queue is empty. Must be called with q.mu held.
+func (q *Queue) playCurrent(offset time.Duration) error {
+	if len(q.tracks) == 0 {
+		return nil
+	}
+
+	track := q.tracks[0]
+	if err := q.player.PlayFrom(track.Source, offset); err != nil {
+		return err
+	}
+
+	q.playing = true
+	q.offset = offset
+	q.startedAt = q.clock.Now()
+	q.generation++
+
+	go q.waitForEnd(q.generation, q.player.Done())
+
+	// Only notify when a track starts from the beginning,
+	// not when the current one is seeked.
+	if offset == 0 && q.onNowPlaying != nil {
+		go q.onNowPlaying(track)
+	}
+
+	return nil
+}
+
+// waitForEnd waits for the track started with the given generation to end and
+// starts playing the next one, unless it was stopped by the queue itself.
+func (q *Queue) waitForEnd(generation int, done <-chan struct{}) {
+	<-done
+
+	defer q.save()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// The track was skipped, seeked or the queue was cleared.
+	if generation != q.generation {
+		return
+	}
+
+	q.playing = false
+	q.advance(false)
+	if err := q.playCurrent(0); err != nil {
+		q.reportErr(err)
+	}
+}
+
+// save persists a copy of the tracks if a store was provided and they changed
+// since they were last saved. The store is called without holding q.mu, so
+// saves do not block the queue. Must be called without q.mu held.
+func (q *Queue) save() {
+	if q.store == nil {
+		return
+	}
+
+	q.saveMu.Lock()
+	defer q.saveMu.Unlock()
+
+	q.mu.Lock()
+	version := q.version
+	tracks := make([]Track, len(q.tracks))
+	copy(tracks, q.tracks)
+	q.mu.Unlock()
+
+	if version == q.savedVersion {
+		return
+	}
+
+	if err := q.store.SaveQueue(q.guildID, tracks); err != nil {
+		q.mu.Lock()
+		q.reportErr(err)
+		q.mu.Unlock()
+		return
+	}
+	q.savedVersion = version
+}
+
+// reportErr reports the given error to the error callback, if any.
+// Must be called with q.mu held.
+func (q *Queue) reportErr(err error) {
+	if q.onError != nil {
+		go q.onError(err)
+	}
+}
@@ -0,0 +1,306 @@
+package voice
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony/clock"
+)
+
+// fakePlayer is a QueuePlayer whose tracks only end when end or Stop is called.
+type fakePlayer struct {
+	mu      sync.Mutex
+	played  []string
+	offsets []time.Duration
+	done    chan struct{}
+}
+
+func (p *fakePlayer) PlayFrom(source string, offset time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.played = append(p.played, source)
+	p.offsets = append(p.offsets, offset)
+	p.done = make(chan struct{})
+	return nil
+}
+
+func (p *fakePlayer) Stop() {
+	p.end()
+}
+
+func (p *fakePlayer) Done() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.done
+}
+
+// end ends the track being played, if any.
+func (p *fakePlayer) end() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done == nil {
+		return
+	}
+	select {
+	case <-p.done:
+	default:
+		close(p.done)
+	}
+}
+
+func (p *fakePlayer) playedSources() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]string(nil), p.played...)
+}
+
+// memoryStore is a QueueStore keeping queues in memory.
+type memoryStore struct {
+	mu     sync.Mutex
+	queues map[string][]Track
+}
+
+func (s *memoryStore) SaveQueue(guildID string, tracks []Track) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queues[guildID] = append([]Track(nil), tracks...)
+	return nil
+}
+
+func (s *memoryStore) LoadQueue(guildID string) ([]Track, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.queues[guildID], nil
+}
+
+// waitFor waits for cond to be true, failing the test if it takes too long.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func tracks(sources ...string) []Track {
+	tracks := make([]Track, len(sources))
+	for i, src := range sources {
+		tracks[i] = Track{Source: src, Duration: time.Minute}
+	}
+	return tracks
+}
+
+func TestQueueLoopModes(t *testing.T) {
+	tests := []struct {
+		name string
+		loop LoopMode
+		// Number of tracks ended after enqueuing a, b and c.
+		ends   int
+		played []string
+	}{
+		{name: "off", loop: LoopOff, ends: 3, played: []string{"a", "b", "c"}},
+		{name: "track", loop: LoopTrack, ends: 2, played: []string{"a", "a", "a"}},
+		{name: "queue", loop: LoopQueue, ends: 4, played: []string{"a", "b", "c", "a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &fakePlayer{}
+			q, err := NewQueue("1", p, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q.SetLoopMode(tt.loop)
+
+			if err = q.Enqueue(tracks("a", "b", "c")...); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < tt.ends; i++ {
+				p.end()
+				// The last track of the queue ended if nothing else was played.
+				waitFor(t, func() bool {
+					_, _, playing := q.NowPlaying()
+					return len(p.playedSources()) == i+2 || !playing
+				})
+			}
+
+			if played := p.playedSources(); !reflect.DeepEqual(played, tt.played) {
+				t.Errorf("expected %v to be played, got %v", tt.played, played)
+			}
+		})
+	}
+}
+
+func TestQueueSkip(t *testing.T) {
+	tests := []struct {
+		name   string
+		loop   LoopMode
+		played []string
+		tracks []string
+	}{
+		{name: "off", loop: LoopOff, played: []string{"a", "b"}, tracks: []string{"b", "c"}},
+		{name: "track", loop: LoopTrack, played: []string{"a", "b"}, tracks: []string{"b", "c"}},
+		{name: "queue", loop: LoopQueue, played: []string{"a", "b"}, tracks: []string{"b", "c", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &fakePlayer{}
+			q, err := NewQueue("1", p, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q.SetLoopMode(tt.loop)
+
+			if err = q.Skip(); !errors.Is(err, ErrQueueNotPlaying) {
+				t.Fatalf("expected ErrQueueNotPlaying, got %v", err)
+			}
+
+			if err = q.Enqueue(tracks("a", "b", "c")...); err != nil {
+				t.Fatal(err)
+			}
+			if err = q.Skip(); err != nil {
+				t.Fatal(err)
+			}
+
+			if played := p.playedSources(); !reflect.DeepEqual(played, tt.played) {
+				t.Errorf("expected %v to be played, got %v", tt.played, played)
+			}
+			var sources []string
+			for _, track := range q.Tracks() {
+				sources = append(sources, track.Source)
+			}
+			if !reflect.DeepEqual(sources, tt.tracks) {
+				t.Errorf("expected %v to be queued, got %v", tt.tracks, sources)
+			}
+		})
+	}
+}
+
+func TestQueueSeek(t *testing.T) {
+	clk := clock.NewMock(time.Unix(0, 0))
+	p := &fakePlayer{}
+	q, err := NewQueue("1", p, nil, WithQueueClock(clk))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = q.Enqueue(tracks("a")...); err != nil {
+		t.Fatal(err)
+	}
+	clk.Add(10 * time.Second)
+
+	if err = q.Seek(2 * time.Minute); !errors.Is(err, ErrSeekPastEnd) {
+		t.Fatalf("expected ErrSeekPastEnd, got %v", err)
+	}
+	if err = q.Seek(30 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	clk.Add(5 * time.Second)
+
+	track, elapsed, ok := q.NowPlaying()
+	if !ok || track.Source != "a" {
+		t.Fatalf("expected a to be playing, got %v (playing=%t)", track.Source, ok)
+	}
+	if elapsed != 35*time.Second {
+		t.Errorf("expected elapsed time to be 35s, got %s", elapsed)
+	}
+	if want := []time.Duration{0, 30 * time.Second}; !reflect.DeepEqual(p.offsets, want) {
+		t.Errorf("expected offsets to be %v, got %v", want, p.offsets)
+	}
+}
+
+func TestQueueStore(t *testing.T) {
+	store := &memoryStore{queues: map[string][]Track{"1": tracks("a", "b")}}
+	p := &fakePlayer{}
+	q, err := NewQueue("1", p, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p.playedSources()) != 0 {
+		t.Fatal("expected restored tracks not to be played before Play is called")
+	}
+	if err = q.Play(); err != nil {
+		t.Fatal(err)
+	}
+	if err = q.Enqueue(tracks("c")...); err != nil {
+		t.Fatal(err)
+	}
+	p.end()
+	waitFor(t, func() bool { return len(p.playedSources()) == 2 })
+
+	// The queue is saved once the next track started playing.
+	want := tracks("b", "c")
+	waitFor(t, func() bool {
+		saved, _ := store.LoadQueue("1")
+		return reflect.DeepEqual(saved, want)
+	})
+
+	q.Clear()
+	if saved, _ := store.LoadQueue("1"); len(saved) != 0 {
+		t.Errorf("expected no tracks to be saved after Clear, got %v", saved)
+	}
+}
+
+// reentrantStore is a QueueStore reading the queue it saves, and modifying
+// the tracks it is given.
+type reentrantStore struct {
+	memoryStore
+	q *Queue
+}
+
+func (s *reentrantStore) SaveQueue(guildID string, tracks []Track) error {
+	if s.q != nil {
+		// Would deadlock if the store was called with the lock of the queue held.
+		s.q.Tracks()
+	}
+	err := s.memoryStore.SaveQueue(guildID, tracks)
+	for i := range tracks {
+		tracks[i].Title = "modified"
+	}
+	return err
+}
+
+func TestQueueSaveOutsideLock(t *testing.T) {
+	store := &reentrantStore{memoryStore: memoryStore{queues: make(map[string][]Track)}}
+	q, err := NewQueue("1", &fakePlayer{}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.q = q
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := q.Enqueue(tracks("a", "b")...); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Enqueue not to deadlock")
+	}
+
+	if got := q.Tracks(); !reflect.DeepEqual(got, tracks("a", "b")) {
+		t.Errorf("expected the store to be given a copy of the tracks, got %v", got)
+	}
+	if saved, _ := store.LoadQueue("1"); !reflect.DeepEqual(saved, tracks("a", "b")) {
+		t.Errorf("expected %v to be saved, got %v", tracks("a", "b"), saved)
+	}
+}
@@ -0,0 +1,232 @@
+package voice
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+)
+
+const (
+	// userAudioBufferSize is the size of the buffer of
+	// channels returned by Connection.UserAudio.
+	userAudioBufferSize = 64
+	// reorderWindow is the number of packets held for each SSRC while
+	// waiting for a missing packet, before giving up on it.
+	reorderWindow = 8
+	// rtpExtensionBit is set in the first byte of RTP headers
+	// followed by a header extension.
+	rtpExtensionBit = 0x10
+)
+
+// UserAudio returns a channel receiving the audio packets sent by the user
+// with the given ID, in order, once they start speaking. Packets received out
+// of order are reordered, and late packets are dropped. Packets are dropped if
+// the channel is full. Calling UserAudio several times for the same user returns
+// the same channel, which is closed when the connection is closed.
+func (vc *Connection) UserAudio(userID string) <-chan *AudioPacket {
+	return vc.demux.stream(userID)
+}
+
+// SSRCUser returns the ID of the user sending audio with the given SSRC, as
+// reported by the voice server when users start speaking.
+func (vc *Connection) SSRCUser(ssrc uint32) (string, bool) {
+	return vc.demux.user(ssrc)
+}
+
+// speaking is the payload sent by the voice server when a user starts speaking.
+type speaking struct {
+	UserID   string `json:"user_id"`
+	SSRC     uint32 `json:"ssrc"`
+	Speaking uint32 `json:"speaking"`
+}
+
+// clientDisconnect is the payload sent by the voice server
+// when a user disconnects from the voice channel.
+type clientDisconnect struct {
+	UserID string `json:"user_id"`
+}
+
+// demuxer dispatches received audio packets to the streams of their users.
+type demuxer struct {
+	mu      sync.Mutex
+	users   map[uint32]string // User IDs by SSRC.
+	streams map[string]chan *AudioPacket
+	buffers map[uint32]*reorderBuffer
+	closed  bool
+}
+
+func newDemuxer() *demuxer {
+	return &demuxer{
+		users:   make(map[uint32]string),
+		streams: make(map[string]chan *AudioPacket),
+		buffers: make(map[uint32]*reorderBuffer),
+	}
+}
+
+func (d *demuxer) stream(userID string) <-chan *AudioPacket {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ch, ok := d.streams[userID]
+	if !ok {
+		ch = make(chan *AudioPacket, userAudioBufferSize)
+		if d.closed {
+			close(ch)
+		}
+		d.streams[userID] = ch
+	}
+	return ch
+}
+
+func (d *demuxer) user(ssrc uint32) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	userID, ok := d.users[ssrc]
+	return userID, ok
+}
+
+// handleSpeaking maps the SSRC of the given Speaking payload to its user.
+func (d *demuxer) handleSpeaking(data json.RawMessage) error {
+	var s speaking
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if previous, ok := d.users[s.SSRC]; !ok || previous != s.UserID {
+		delete(d.buffers, s.SSRC)
+	}
+	d.users[s.SSRC] = s.UserID
+	return nil
+}
+
+// handleClientDisconnect forgets the SSRCs of the user
+// of the given Client Disconnect payload.
+func (d *demuxer) handleClientDisconnect(data json.RawMessage) error {
+	var cd clientDisconnect
+	if err := json.Unmarshal(data, &cd); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for ssrc, userID := range d.users {
+		if userID == cd.UserID {
+			delete(d.users, ssrc)
+			delete(d.buffers, ssrc)
+		}
+	}
+	return nil
+}
+
+// dispatch sends the given packet, and packets it was waiting for, to the
+// stream of its user. Packets from unknown SSRCs are dropped.
+func (d *demuxer) dispatch(p *AudioPacket) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	userID, ok := d.users[p.SSRC]
+	if !ok || d.closed {
+		return
+	}
+	p.UserID = userID
+
+	ch, ok := d.streams[userID]
+	if !ok {
+		return
+	}
+
+	b, ok := d.buffers[p.SSRC]
+	if !ok {
+		b = &reorderBuffer{next: p.Sequence}
+		d.buffers[p.SSRC] = b
+	}
+	for _, p := range b.push(p) {
+		select {
+		case ch <- p:
+		default:
+		}
+	}
+}
+
+func (d *demuxer) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return
+	}
+	d.closed = true
+	for _, ch := range d.streams {
+		close(ch)
+	}
+}
+
+// reorderBuffer reorders the packets of an SSRC by sequence number.
+type reorderBuffer struct {
+	next    uint16 // Sequence number of the next packet to release.
+	pending map[uint16]*AudioPacket
+}
+
+// push adds the given packet to the buffer and returns the packets that
+// can be released, in order.
+func (b *reorderBuffer) push(p *AudioPacket) []*AudioPacket {
+	// Sequence numbers wrap around, compare them as signed offsets.
+	if int16(p.Sequence-b.next) < 0 {
+		return nil // Late or duplicate packet.
+	}
+
+	if b.pending == nil {
+		b.pending = make(map[uint16]*AudioPacket)
+	}
+	b.pending[p.Sequence] = p
+
+	var released []*AudioPacket
+	for len(b.pending) > 0 {
+		next, ok := b.pending[b.next]
+		if !ok {
+			if len(b.pending) < reorderWindow {
+				break
+			}
+			// Give up on missing packets and skip to the first pending one.
+			b.next = b.first()
+			continue
+		}
+		delete(b.pending, b.next)
+		released = append(released, next)
+		b.next++
+	}
+	return released
+}
+
+// first returns the lowest sequence number of pending packets.
+func (b *reorderBuffer) first() uint16 {
+	first := b.next
+	min := int16(-1)
+	for seq := range b.pending {
+		if offset := int16(seq - b.next); min < 0 || offset < min {
+			first, min = seq, offset
+		}
+	}
+	return first
+}
+
+// rtpPayload returns the payload of a decrypted RTP packet whose
+// header starts with the given first byte, without its header extension.
+func rtpPayload(first byte, decrypted []byte) []byte {
+	if first&rtpExtensionBit == 0 || len(decrypted) < 4 {
+		return decrypted
+	}
+
+	// The extension starts with a 16 bits profile and its
+	// length, in 32 bits words, not counting this header.
+	size := 4 + 4*int(binary.BigEndian.Uint16(decrypted[2:4]))
+	if size > len(decrypted) {
+		return decrypted
+	}
+	return decrypted[size:]
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net"
 	"time"
 
 	"nhooyr.io/websocket"
@@ -50,7 +49,7 @@ func (vc *Connection) reconnectWithBackoff() {
 			vc.logger.Errorf("failed to reconnect to voice server: %v, retrying in %s", err, duration)
 
 			select {
-			case <-time.After(duration):
+			case <-vc.clock.After(duration):
 				continue // Make a new connection attempt.
 			case <-vc.stop:
 				// Client called Disconnect(), stop trying to reconnect.
@@ -78,7 +77,7 @@ func (vc *Connection) reconnect(ctx context.Context) error {
 	// Start by re-opening the voice websocket connection.
 	var err error
 	vc.logger.Debugf("connecting to voice server: %s", vc.endpoint)
-	vc.conn, _, err = websocket.Dial(ctx, vc.endpoint, nil)
+	vc.conn, _, err = websocket.Dial(ctx, vc.endpoint, &websocket.DialOptions{HTTPClient: vc.dialClient})
 	if err != nil {
 		return err
 	}
@@ -95,7 +94,7 @@ func (vc *Connection) reconnect(ctx context.Context) error {
 	}()
 
 	// Then re-establish the voice data UDP connection.
-	vc.udpConn, err = net.DialUDP("udp", nil, vc.dataEndpoint)
+	vc.udpConn, err = vc.dialUDP()
 	if err != nil {
 		return err
 	}
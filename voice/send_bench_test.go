@@ -0,0 +1,74 @@
+package voice
+
+import (
+	"net"
+	"strconv"
+	"testing"
+)
+
+// opusFrame is the size of a typical 20ms Opus frame at 128kbps.
+var opusFrame = make([]byte, 320)
+
+func BenchmarkPacketEncoder(b *testing.B) {
+	var secret [32]byte
+	enc := newPacketEncoder(1, &secret, 0, 0)
+	buf := make([]byte, 0, maxPacketSize)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(opusFrame)))
+	for i := 0; i < b.N; i++ {
+		buf = enc.encode(buf[:0], opusFrame)
+	}
+}
+
+// newBenchUDPConn returns a UDP connection to a local socket that discards
+// what it receives, and a function closing both.
+func newBenchUDPConn(b *testing.B) (*net.UDPConn, func()) {
+	l, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		b.Fatal(err)
+	}
+	go func() {
+		buf := make([]byte, maxPacketSize)
+		for {
+			if _, err := l.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+
+	conn, err := net.DialUDP("udp", nil, l.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		_ = l.Close()
+		b.Fatal(err)
+	}
+	return conn, func() {
+		_ = conn.Close()
+		_ = l.Close()
+	}
+}
+
+func BenchmarkBatchWriter(b *testing.B) {
+	for _, size := range []int{1, 2, 4, 16} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			conn, closeConn := newBenchUDPConn(b)
+			defer closeConn()
+
+			var secret [32]byte
+			enc := newPacketEncoder(1, &secret, 0, 0)
+			packets := make([][]byte, size)
+			for i := range packets {
+				packets[i] = enc.encode(make([]byte, 0, maxPacketSize), opusFrame)
+			}
+			w := newBatchWriter(conn, size)
+
+			b.ReportAllocs()
+			b.SetBytes(int64(size * len(packets[0])))
+			for i := 0; i < b.N; i++ {
+				if err := w.write(packets); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package voice
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// FrameDuration is the duration of the audio of each frame sent.
+	FrameDuration = 20 * time.Millisecond
+	// pcmFrameSamples is the number of samples in a 20ms frame of
+	// stereo PCM audio sampled at 48kHz: 960 per channel.
+	pcmFrameSamples = 960 * 2
+	// maxOpusFrameSize is the maximum size of an Opus frame.
+	maxOpusFrameSize = 4000
+)
+
+// Source is a source of Opus encoded audio frames, to be played with a Player.
+type Source interface {
+	// ReadFrame returns the next Opus frame of 20ms of audio, or io.EOF
+	// when there are no more frames.
+	ReadFrame() ([]byte, error)
+}
+
+// Encoder encodes 20ms frames of stereo PCM audio sampled at 48kHz with
+// Opus. See voiceutil.NewEncoder for an implementation.
+type Encoder interface {
+	Encode(pcm []int16) ([]byte, error)
+}
+
+// NewOpusSource returns a Source reading Opus frames from r, each prefixed by
+// its size as a little-endian 16 bits integer, as produced by tools such as dca.
+func NewOpusSource(r io.Reader) Source {
+	return &opusSource{r: r}
+}
+
+type opusSource struct {
+	r io.Reader
+}
+
+func (s *opusSource) ReadFrame() ([]byte, error) {
+	var size int16
+	if err := binary.Read(s.r, binary.LittleEndian, &size); err != nil {
+		// A truncated size is treated as the end of the stream.
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	if size <= 0 || size > maxOpusFrameSize {
+		return nil, fmt.Errorf("invalid Opus frame size %d", size)
+	}
+
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(s.r, frame); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	return frame, nil
+}
+
+// NewPCMSource returns a Source reading stereo PCM audio sampled at 48kHz from r,
+// as little-endian 16 bits samples, and encoding it with the given encoder.
+func NewPCMSource(r io.Reader, enc Encoder) Source {
+	return &pcmSource{r: r, enc: enc, pcm: make([]int16, pcmFrameSamples)}
+}
+
+type pcmSource struct {
+	r   io.Reader
+	enc Encoder
+	pcm []int16
+}
+
+func (s *pcmSource) ReadFrame() ([]byte, error) {
+	err := binary.Read(s.r, binary.LittleEndian, s.pcm)
+	if err == io.ErrUnexpectedEOF {
+		// binary.Read does not tell how much was read, so the last
+		// incomplete frame is dropped. It lasts less than 20ms.
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.enc.Encode(s.pcm)
+}
@@ -0,0 +1,134 @@
+package voice
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+)
+
+// WithUDPAddr can be used to set the local IP address the voice UDP connection
+// is bound to, for instance to choose a network interface. Its family must match
+// the one of the voice server, which can be IPv4 or IPv6.
+// Defaults to an address chosen by the system.
+func WithUDPAddr(ip net.IP) ConnectionOption {
+	return func(c *Connection) {
+		c.udpIP = ip
+	}
+}
+
+// WithUDPPortRange can be used to restrict the local port of the voice UDP
+// connection to the given range, inclusive, so firewalls can be configured to let
+// voice data through. Connections fail if no port of the range is available.
+// Defaults to a port chosen by the system.
+func WithUDPPortRange(min, max int) ConnectionOption {
+	return func(c *Connection) {
+		c.udpPortMin, c.udpPortMax = min, max
+	}
+}
+
+// WithWriteBatching can be used to send up to the given number of audio frames
+// at once when they are ready, instead of one every 20 milliseconds. On Linux,
+// batches are sent with a single sendmmsg system call, which lowers the CPU used
+// by bots sending many simultaneous streams. Frames are delayed by up to
+// 20 milliseconds per batched frame, so small values such as 2 or 3 should be
+// used to stay within the jitter buffer of clients.
+// Defaults to 1, which disables batching.
+func WithWriteBatching(frames int) ConnectionOption {
+	return func(c *Connection) {
+		c.writeBatchSize = frames
+	}
+}
+
+// LocalUDPAddr returns the local address of the voice UDP connection, or nil
+// if the connection is not established yet.
+func (vc *Connection) LocalUDPAddr() *net.UDPAddr {
+	vc.addrMu.RLock()
+	defer vc.addrMu.RUnlock()
+
+	return vc.localAddr
+}
+
+// ExternalUDPAddr returns the address of the voice UDP connection as seen by the
+// voice server, which is the address of the NAT in front of the connection if
+// any, or nil if the connection is not established yet.
+func (vc *Connection) ExternalUDPAddr() *net.UDPAddr {
+	vc.addrMu.RLock()
+	defer vc.addrMu.RUnlock()
+
+	return vc.externalAddr
+}
+
+// dialUDP dials the voice server over UDP. When reconnecting, it reuses the local
+// port of the previous connection if possible, so its external address does not
+// change.
+func (vc *Connection) dialUDP() (*net.UDPConn, error) {
+	vc.addrMu.RLock()
+	previous := vc.localAddr
+	vc.addrMu.RUnlock()
+
+	var (
+		conn *net.UDPConn
+		err  error
+	)
+	if previous != nil {
+		conn, err = net.DialUDP("udp", previous, vc.dataEndpoint)
+	}
+	if conn == nil {
+		conn, err = vc.dialUDPInRange()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	vc.addrMu.Lock()
+	vc.localAddr = conn.LocalAddr().(*net.UDPAddr)
+	vc.addrMu.Unlock()
+
+	return conn, nil
+}
+
+// dialUDPInRange dials the voice server over UDP from the configured
+// local IP address and port range.
+func (vc *Connection) dialUDPInRange() (*net.UDPConn, error) {
+	if vc.udpPortMin == 0 && vc.udpPortMax == 0 {
+		var laddr *net.UDPAddr
+		if vc.udpIP != nil {
+			laddr = &net.UDPAddr{IP: vc.udpIP}
+		}
+		return net.DialUDP("udp", laddr, vc.dataEndpoint)
+	}
+
+	if vc.udpPortMin <= 0 || vc.udpPortMax > 65535 || vc.udpPortMin > vc.udpPortMax {
+		return nil, fmt.Errorf("voice: invalid UDP port range %d-%d", vc.udpPortMin, vc.udpPortMax)
+	}
+
+	// Start from a random port so concurrent connections
+	// do not all try the same ports first.
+	n := vc.udpPortMax - vc.udpPortMin + 1
+	start := rand.Intn(n)
+	var err error
+	for i := 0; i < n; i++ {
+		laddr := &net.UDPAddr{IP: vc.udpIP, Port: vc.udpPortMin + (start+i)%n}
+		var conn *net.UDPConn
+		if conn, err = net.DialUDP("udp", laddr, vc.dataEndpoint); err == nil {
+			return conn, nil
+		}
+		var addrErr *net.AddrError
+		if errors.As(err, &addrErr) {
+			// Not a port conflict, other ports would fail the same way.
+			break
+		}
+	}
+	return nil, fmt.Errorf("voice: could not bind a UDP port between %d and %d: %w", vc.udpPortMin, vc.udpPortMax, err)
+}
+
+// writeEach writes the given packets to conn one by one.
+func writeEach(conn *net.UDPConn, packets [][]byte) error {
+	for _, p := range packets {
+		if _, err := conn.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+package voice
+
+// sysSendmmsg is the number of the sendmmsg system call,
+// which is missing from the syscall package on this platform.
+const sysSendmmsg = 307
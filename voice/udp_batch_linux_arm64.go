@@ -0,0 +1,6 @@
+package voice
+
+import "syscall"
+
+// sysSendmmsg is the number of the sendmmsg system call.
+const sysSendmmsg = syscall.SYS_SENDMMSG
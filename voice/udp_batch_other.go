@@ -0,0 +1,23 @@
+//go:build !linux || (linux && !amd64 && !arm64)
+// +build !linux linux,!amd64,!arm64
+
+package voice
+
+import "net"
+
+// batchWriter writes batches of packets to a UDP connection. Batches are only
+// sent with a single system call on Linux amd64 and arm64, other platforms
+// write packets one by one.
+type batchWriter struct {
+	conn *net.UDPConn
+}
+
+// newBatchWriter returns a batchWriter writing up to size packets at once to conn.
+func newBatchWriter(conn *net.UDPConn, size int) *batchWriter {
+	return &batchWriter{conn: conn}
+}
+
+// write writes the given packets, which must not be more than the size of the writer.
+func (w *batchWriter) write(packets [][]byte) error {
+	return writeEach(w.conn, packets)
+}
@@ -0,0 +1,100 @@
+//go:build (linux && amd64) || (linux && arm64)
+// +build linux,amd64 linux,arm64
+
+package voice
+
+import (
+	"net"
+	"syscall"
+	"unsafe"
+)
+
+// mmsghdr is the mmsghdr structure of sendmmsg(2).
+type mmsghdr struct {
+	hdr syscall.Msghdr
+	len uint32
+}
+
+// batchWriter writes batches of packets to a UDP connection with a single
+// sendmmsg system call. Its buffers are allocated once, so writing does not
+// allocate.
+type batchWriter struct {
+	conn *net.UDPConn
+	raw  syscall.RawConn
+
+	iovecs []syscall.Iovec
+	msgs   []mmsghdr
+
+	// State of the current write, used by writeFn.
+	n, sent int
+	errno   syscall.Errno
+	writeFn func(fd uintptr) bool
+}
+
+// newBatchWriter returns a batchWriter writing up to size packets at once to conn.
+func newBatchWriter(conn *net.UDPConn, size int) *batchWriter {
+	w := &batchWriter{
+		conn:   conn,
+		iovecs: make([]syscall.Iovec, size),
+		msgs:   make([]mmsghdr, size),
+	}
+	// Batching is best effort, fall back to regular writes if
+	// the underlying file descriptor is not available.
+	if size > 1 {
+		if raw, err := conn.SyscallConn(); err == nil {
+			w.raw = raw
+		}
+	}
+	w.writeFn = w.sendmmsg
+	return w
+}
+
+// write writes the given packets, which must not be more than the size of the writer.
+func (w *batchWriter) write(packets [][]byte) error {
+	if w.raw == nil || len(packets) == 1 {
+		return writeEach(w.conn, packets)
+	}
+
+	for i, p := range packets {
+		w.iovecs[i].Base = &p[0]
+		w.iovecs[i].SetLen(len(p))
+		w.msgs[i].hdr.Iov = &w.iovecs[i]
+		w.msgs[i].hdr.Iovlen = 1
+	}
+	w.n, w.sent, w.errno = len(packets), 0, 0
+
+	err := w.raw.Write(w.writeFn)
+	if err == nil && w.errno != 0 {
+		err = w.errno
+	}
+	if err != nil {
+		return &net.OpError{Op: "write", Net: "udp", Source: w.conn.LocalAddr(), Addr: w.conn.RemoteAddr(), Err: err}
+	}
+	return nil
+}
+
+// sendmmsg sends the messages of the current write that were not sent yet. It
+// reports whether it is done, so it is called again once the socket is writable
+// if it would block.
+func (w *batchWriter) sendmmsg(fd uintptr) bool {
+	for w.sent < w.n {
+		n, _, errno := syscall.Syscall6(
+			sysSendmmsg,
+			fd,
+			uintptr(unsafe.Pointer(&w.msgs[w.sent])),
+			uintptr(w.n-w.sent),
+			0, 0, 0,
+		)
+		switch errno {
+		case 0:
+			w.sent += int(n)
+		case syscall.EINTR:
+		case syscall.EAGAIN:
+			return false
+		default:
+			w.errno = errno
+			return true
+		}
+	}
+	return true
+}
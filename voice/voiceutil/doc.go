@@ -1,6 +1,13 @@
 /*
 Package voiceutil provides utilities to work with harmony voice
 connections. It contains adapters that do the conversion between
-PCM and Opus-encoded data.
+PCM and Opus-encoded data, and an Opus encoder to play PCM audio
+with a voice.Player:
+
+	enc, err := voiceutil.NewEncoder()
+	if err != nil {
+		// Handle error.
+	}
+	err = voice.NewPlayer(conn).Play(ctx, voice.NewPCMSource(pcm, enc))
 */
 package voiceutil
@@ -49,6 +49,24 @@ func OpusEncoder(conn *voice.Connection) (pcmIn chan []int16, err error) {
 	return pcmIn, nil
 }
 
+// NewEncoder returns a voice.Encoder encoding PCM audio with Opus, to
+// be used with voice.NewPCMSource.
+func NewEncoder() (voice.Encoder, error) {
+	enc, err := opus.NewEncoder(SampleRate, Channels, opus.Audio)
+	if err != nil {
+		return nil, err
+	}
+	return &encoder{enc: enc}, nil
+}
+
+type encoder struct {
+	enc *opus.Encoder
+}
+
+func (e *encoder) Encode(pcm []int16) ([]byte, error) {
+	return e.enc.Encode(pcm, FrameSize, FrameSize*2*2)
+}
+
 // OpusDecoder is an adapter that allows to read the incoming voice data
 // on conn as PCM, sent through the returned channel.
 //
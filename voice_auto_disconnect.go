@@ -0,0 +1,201 @@
+package harmony
+
+import (
+	"context"
+	"time"
+
+	"github.com/skwair/harmony/voice"
+)
+
+// voiceAutoDisconnectCheckInterval is the maximum interval at which voice
+// connections are checked for automatic disconnection.
+const voiceAutoDisconnectCheckInterval = 10 * time.Second
+
+// VoiceAutoDisconnectReason is the reason why a voice connection was
+// automatically disconnected.
+type VoiceAutoDisconnectReason int
+
+// Reasons for a voice connection to be automatically disconnected.
+const (
+	// VoiceAutoDisconnectIdle means no audio was sent
+	// for longer than the configured idle timeout.
+	VoiceAutoDisconnectIdle VoiceAutoDisconnectReason = iota
+	// VoiceAutoDisconnectAlone means there was no
+	// non-bot user left in the voice channel.
+	VoiceAutoDisconnectAlone
+)
+
+// String implements the fmt.Stringer interface.
+func (r VoiceAutoDisconnectReason) String() string {
+	switch r {
+	case VoiceAutoDisconnectIdle:
+		return "idle"
+	case VoiceAutoDisconnectAlone:
+		return "alone"
+	default:
+		return "unknown"
+	}
+}
+
+// VoiceOption is a function that configures a voice connection.
+// It is used in JoinVoiceChannel.
+type VoiceOption func(*voiceSettings)
+
+type voiceSettings struct {
+	idleTimeout          time.Duration
+	leaveWhenAlone       bool
+	beforeAutoDisconnect func(guildID string, reason VoiceAutoDisconnectReason)
+	connectionOptions    []voice.ConnectionOption
+}
+
+func (s *voiceSettings) autoDisconnectEnabled() bool {
+	return s.idleTimeout > 0 || s.leaveWhenAlone
+}
+
+// WithVoiceIdleTimeout makes the voice connection automatically leave its channel
+// when no audio has been sent through it for the given duration.
+// Defaults to 0, meaning the connection never leaves because it is idle.
+func WithVoiceIdleTimeout(d time.Duration) VoiceOption {
+	return func(s *voiceSettings) {
+		s.idleTimeout = d
+	}
+}
+
+// WithVoiceLeaveWhenAlone makes the voice connection automatically leave its
+// channel when no user other than bots remain in it. This relies on voice states
+// tracked by the State, so it has no effect if state tracking is disabled.
+// Defaults to false.
+func WithVoiceLeaveWhenAlone(y bool) VoiceOption {
+	return func(s *voiceSettings) {
+		s.leaveWhenAlone = y
+	}
+}
+
+// WithVoiceAutoDisconnectCallback sets a function that is called right before a
+// voice connection is automatically disconnected, along with the reason why.
+func WithVoiceAutoDisconnectCallback(f func(guildID string, reason VoiceAutoDisconnectReason)) VoiceOption {
+	return func(s *voiceSettings) {
+		s.beforeAutoDisconnect = f
+	}
+}
+
+// watchVoiceConnection starts a goroutine that periodically checks whether the
+// given voice connection should be automatically disconnected according to the
+// given settings.
+func (c *Client) watchVoiceConnection(guildID string, conn *voice.Connection, settings *voiceSettings) {
+	if settings.leaveWhenAlone && !c.withStateTracking {
+		c.logger.Warnf("voice connection (guild=%q) configured to leave when alone but state tracking is disabled, ignoring", guildID)
+		settings.leaveWhenAlone = false
+
+		if !settings.autoDisconnectEnabled() {
+			return
+		}
+	}
+
+	interval := voiceAutoDisconnectCheckInterval
+	if settings.idleTimeout > 0 && settings.idleTimeout < interval {
+		interval = settings.idleTimeout
+	}
+
+	stop := make(chan struct{})
+
+	c.voiceWatchersMu.Lock()
+	c.voiceWatchers[guildID] = stop
+	c.voiceWatchersMu.Unlock()
+
+	go func() {
+		ticker := c.clock.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C():
+				reason, ok := c.shouldAutoDisconnect(conn, settings)
+				if !ok {
+					continue
+				}
+
+				c.logger.Debugf("automatically leaving voice channel (guild=%q, reason=%s)", guildID, reason)
+				if settings.beforeAutoDisconnect != nil {
+					settings.beforeAutoDisconnect(guildID, reason)
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				if err := c.autoLeaveVoiceChannel(ctx, guildID, conn); err != nil {
+					c.logger.Errorf("could not automatically leave voice channel (guild=%q): %v", guildID, err)
+				}
+				cancel()
+				return
+
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// autoLeaveVoiceChannel leaves the voice channel of the given guild if it is
+// still connected to with the given voice connection. It may not be the case
+// anymore if the channel was left, and possibly joined again, while the watcher
+// of the connection was deciding to leave.
+func (c *Client) autoLeaveVoiceChannel(ctx context.Context, guildID string, conn *voice.Connection) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if current, ok := c.voiceConnections[guildID]; !ok || current != conn {
+		return nil
+	}
+	return c.leaveVoiceChannel(ctx, guildID, c.removeVoiceConnection(guildID))
+}
+
+// stopWatchingVoiceConnection stops the goroutine watching the voice connection
+// of the given guild, if any.
+func (c *Client) stopWatchingVoiceConnection(guildID string) {
+	c.voiceWatchersMu.Lock()
+	defer c.voiceWatchersMu.Unlock()
+
+	if stop, ok := c.voiceWatchers[guildID]; ok {
+		close(stop)
+		delete(c.voiceWatchers, guildID)
+	}
+}
+
+// shouldAutoDisconnect reports whether the given voice connection should be
+// automatically disconnected and why.
+func (c *Client) shouldAutoDisconnect(conn *voice.Connection, settings *voiceSettings) (VoiceAutoDisconnectReason, bool) {
+	if settings.idleTimeout > 0 && c.clock.Since(conn.LastAudioSent()) >= settings.idleTimeout {
+		return VoiceAutoDisconnectIdle, true
+	}
+
+	if settings.leaveWhenAlone && c.isAloneInVoiceChannel(conn.State()) {
+		return VoiceAutoDisconnectAlone, true
+	}
+
+	return 0, false
+}
+
+// isAloneInVoiceChannel reports whether the voice channel of the given voice state
+// has no user in it other than bots, according to the State. Users that are not
+// known by the State are not considered to be bots.
+func (c *Client) isAloneInVoiceChannel(state *voice.State) bool {
+	if state == nil || state.ChannelID == nil {
+		return false
+	}
+
+	g := c.State.Guild(state.GuildID)
+	if g == nil {
+		return false
+	}
+
+	for _, vs := range g.VoiceStates {
+		if vs.UserID == state.UserID || vs.ChannelID == nil || *vs.ChannelID != *state.ChannelID {
+			continue
+		}
+
+		u := c.State.User(vs.UserID)
+		if u == nil || !u.Bot {
+			return false
+		}
+	}
+	return true
+}
@@ -10,13 +10,23 @@ import (
 	"github.com/skwair/harmony/voice"
 )
 
+// voicePayloadsSize is the number of voice state and server
+// updates that can be pending while joining a voice channel.
+const voicePayloadsSize = 16
+
 // JoinVoiceChannel will create a new voice connection to the given voice channel.
 // If you already have an existing connection and want to switch to a different channel
 // instead, use the SwitchVoiceChannel method.
 // This method is safe to call from multiple goroutines, but connections will happen
 // sequentially.
-// To properly leave the voice channel, call LeaveVoiceChannel.
-func (c *Client) JoinVoiceChannel(ctx context.Context, guildID, channelID string, mute, deaf bool) (*voice.Connection, error) {
+// To properly leave the voice channel, call LeaveVoiceChannel. Optional VoiceOptions
+// can be given to automatically leave the channel under some conditions.
+func (c *Client) JoinVoiceChannel(ctx context.Context, guildID, channelID string, mute, deaf bool, opts ...VoiceOption) (*voice.Connection, error) {
+	var settings voiceSettings
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -34,6 +44,12 @@ func (c *Client) JoinVoiceChannel(ctx context.Context, guildID, channelID string
 	c.connectingToVoice.Store(true)
 	defer c.connectingToVoice.Store(false)
 
+	// Drop payloads left over from previous connections, received
+	// after they got the voice state and server they waited for.
+	for len(c.voicePayloads) > 0 {
+		<-c.voicePayloads
+	}
+
 	// Notify a voice server that we want to connect to a voice channel.
 	vsu := &voice.StateUpdate{
 		State: voice.State{
@@ -50,22 +66,45 @@ func (c *Client) JoinVoiceChannel(ctx context.Context, guildID, channelID string
 	// The voice server should answer with two payloads,
 	// describing the voice state and the voice server
 	// to connect to.
-	state, server, err := getStateAndServer(c.voicePayloads)
+	state, server, err := getStateAndServer(ctx, c.voicePayloads, guildID, c.userID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Establish the voice connection.
-	conn, err := voice.Connect(ctx, state, server, voice.WithLogger(c.logger))
+	voiceOpts := []voice.ConnectionOption{voice.WithLogger(c.logger), voice.WithClock(c.clock)}
+	if c.dialClient != nil {
+		voiceOpts = append(voiceOpts, voice.WithHTTPClient(c.dialClient))
+	}
+	if c.metrics != nil {
+		voiceOpts = append(voiceOpts, voice.WithMetricsCollector(c.metrics))
+	}
+	voiceOpts = append(voiceOpts, settings.connectionOptions...)
+	conn, err := voice.Connect(ctx, state, server, voiceOpts...)
 	if err != nil {
 		return nil, err
 	}
 
+	c.voiceConnectionsMu.Lock()
 	c.voiceConnections[guildID] = conn
+	c.voiceConnectionsMu.Unlock()
+
+	if settings.autoDisconnectEnabled() {
+		c.watchVoiceConnection(guildID, conn, &settings)
+	}
 
 	return conn, nil
 }
 
+// WithVoiceConnectionOptions sets options of the underlying voice connection,
+// for instance to configure its UDP connection with voice.WithUDPAddr and
+// voice.WithUDPPortRange.
+func WithVoiceConnectionOptions(opts ...voice.ConnectionOption) VoiceOption {
+	return func(s *voiceSettings) {
+		s.connectionOptions = append(s.connectionOptions, opts...)
+	}
+}
+
 // SwitchVoiceChannel can be used to switch from a voice channel to another. It requires an
 // active voice connection in the guild. You can get one with JoinVoiceChannel.
 func (c *Client) SwitchVoiceChannel(ctx context.Context, guildID string, channelID string) error {
@@ -94,10 +133,18 @@ func (c *Client) SwitchVoiceChannel(ctx context.Context, guildID string, channel
 // LeaveVoiceChannel notifies the Gateway we want the voice channel we are
 // connected to in the given guild.
 func (c *Client) LeaveVoiceChannel(ctx context.Context, guildID string) error {
-	conn, ok := c.voiceConnections[guildID]
-	if ok {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.leaveVoiceChannel(ctx, guildID, c.removeVoiceConnection(guildID))
+}
+
+// leaveVoiceChannel closes the given voice connection, if not nil, and notifies
+// the Gateway we want to leave the voice channel we are connected to in the
+// given guild.
+func (c *Client) leaveVoiceChannel(ctx context.Context, guildID string, conn *voice.Connection) error {
+	if conn != nil {
 		conn.Close()
-		delete(c.voiceConnections, guildID)
 	}
 
 	vsu := &voice.StateUpdate{
@@ -111,38 +158,75 @@ func (c *Client) LeaveVoiceChannel(ctx context.Context, guildID string) error {
 	return nil
 }
 
-// getStateAndServer will receive exactly two payloads from ch and extract the voice state
-// and the voice server information from them. The order of the payloads is not relevant
-// although only those two payloads must be sent through ch and only once each.
-// NOTE: check if those events are always sequentially sent in the same order, if so,
-// refactor this function.
-func getStateAndServer(ch chan *payload.Payload) (*voice.StateUpdate, *voice.ServerUpdate, error) {
+// removeVoiceConnection stops watching the voice connection of the given guild
+// and removes it from the client. It returns the removed connection, or nil if
+// there was none. c.mu must be held.
+func (c *Client) removeVoiceConnection(guildID string) *voice.Connection {
+	c.stopWatchingVoiceConnection(guildID)
+
+	c.voiceConnectionsMu.Lock()
+	defer c.voiceConnectionsMu.Unlock()
+
+	conn := c.voiceConnections[guildID]
+	delete(c.voiceConnections, guildID)
+	return conn
+}
+
+// voiceConnection returns the voice connection of the given guild, if any.
+// It can be called without holding c.mu.
+func (c *Client) voiceConnection(guildID string) (*voice.Connection, bool) {
+	c.voiceConnectionsMu.RLock()
+	defer c.voiceConnectionsMu.RUnlock()
+
+	conn, ok := c.voiceConnections[guildID]
+	return conn, ok
+}
+
+// getStateAndServer receives payloads from ch until it got the voice state of the given user and
+// the voice server of the given guild, and returns them. Voice state updates of other users or guilds,
+// or notifying that a voice channel was left, can be sent through ch while connecting and are ignored.
+// The order of the payloads is not relevant. It returns ctx.Err() if ctx is done before.
+func getStateAndServer(ctx context.Context, ch chan *payload.Payload, guildID, userID string) (*voice.StateUpdate, *voice.ServerUpdate, error) {
 	var (
 		server        voice.ServerUpdate
 		state         voice.StateUpdate
 		first, second bool
 	)
 
-	for i := 0; i < 2; i++ {
-		p := <-ch
+	for !first || !second {
+		var p *payload.Payload
+		select {
+		case p = <-ch:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+
 		if p.T == eventVoiceStateUpdate {
+			var s voice.StateUpdate
+			if err := json.Unmarshal(p.D, &s); err != nil {
+				return nil, nil, err
+			}
+			if s.GuildID != guildID || s.UserID != userID || s.ChannelID == nil {
+				continue
+			}
 			if first {
 				return nil, nil, errors.New("already received voice state update payload")
 			}
 			first = true
-
-			if err := json.Unmarshal(p.D, &state); err != nil {
+			state = s
+		} else if p.T == eventVoiceServerUpdate {
+			var s voice.ServerUpdate
+			if err := json.Unmarshal(p.D, &s); err != nil {
 				return nil, nil, err
 			}
-		} else if p.T == eventVoiceServerUpdate {
+			if s.GuildID != guildID {
+				continue
+			}
 			if second {
 				return nil, nil, errors.New("already received voice server update payload")
 			}
 			second = true
-
-			if err := json.Unmarshal(p.D, &server); err != nil {
-				return nil, nil, err
-			}
+			server = s
 		} else {
 			return nil, nil, fmt.Errorf(
 				"expected Opcode 0 VOICE_STATE_UPDATE or VOICE_SERVER_UPDATE; got Opcode %d %s",
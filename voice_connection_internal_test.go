@@ -0,0 +1,62 @@
+package harmony
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony/internal/payload"
+)
+
+func TestGetStateAndServer(t *testing.T) {
+	var (
+		state       = &payload.Payload{T: eventVoiceStateUpdate, D: json.RawMessage(`{"guild_id":"1","user_id":"2","channel_id":"3","session_id":"abc"}`)}
+		server      = &payload.Payload{T: eventVoiceServerUpdate, D: json.RawMessage(`{"guild_id":"1","token":"token","endpoint":"voice.discord.gg"}`)}
+		otherGuild  = &payload.Payload{T: eventVoiceServerUpdate, D: json.RawMessage(`{"guild_id":"4","token":"token","endpoint":"voice.discord.gg"}`)}
+		otherUser   = &payload.Payload{T: eventVoiceStateUpdate, D: json.RawMessage(`{"guild_id":"1","user_id":"5","channel_id":"3"}`)}
+		leftChannel = &payload.Payload{T: eventVoiceStateUpdate, D: json.RawMessage(`{"guild_id":"1","user_id":"2","channel_id":null}`)}
+		unexpected  = &payload.Payload{T: "MESSAGE_CREATE", D: json.RawMessage(`{}`)}
+	)
+
+	tests := []struct {
+		name     string
+		payloads []*payload.Payload
+		err      bool
+		timeout  bool
+	}{
+		{name: "state then server", payloads: []*payload.Payload{state, server}},
+		{name: "server then state", payloads: []*payload.Payload{server, state}},
+		{name: "ignored payloads", payloads: []*payload.Payload{otherUser, leftChannel, server, otherGuild, state}},
+		{name: "duplicate state", payloads: []*payload.Payload{state, state}, err: true},
+		{name: "unexpected payload", payloads: []*payload.Payload{unexpected}, err: true},
+		{name: "missing server", payloads: []*payload.Payload{state}, err: true, timeout: true},
+		{name: "no payloads", err: true, timeout: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan *payload.Payload, len(tt.payloads))
+			for _, p := range tt.payloads {
+				ch <- p
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			s, srv, err := getStateAndServer(ctx, ch, "1", "2")
+			if (err != nil) != tt.err {
+				t.Fatalf("expected an error: %t, got %v", tt.err, err)
+			}
+			if tt.timeout && err != context.DeadlineExceeded {
+				t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+			}
+			if err != nil {
+				return
+			}
+			if s.SessionID != "abc" || srv.Token != "token" {
+				t.Errorf("unexpected voice state %+v or server %+v", s, srv)
+			}
+		})
+	}
+}
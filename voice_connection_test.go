@@ -0,0 +1,118 @@
+package harmony_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/harmonytest"
+	"github.com/skwair/harmony/voice"
+)
+
+// TestJoinVoiceChannelAutoLeave joins voice channels in several guilds at the
+// same time while their previous connections automatically leave them, which
+// must be run with -race to be meaningful.
+func TestJoinVoiceChannelAutoLeave(t *testing.T) {
+	const (
+		guilds = 4
+		joins  = 3
+	)
+
+	srv := harmonytest.NewServer()
+	defer srv.Close()
+
+	for i := 0; i < guilds; i++ {
+		id := strconv.Itoa(i + 1)
+		srv.AddGuild(&harmony.Guild{ID: id, Name: "guild " + id})
+	}
+
+	c, err := srv.NewClient()
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	// Channels are left once per join, wait for the Gateway to
+	// acknowledge all of them before disconnecting.
+	leaves := make(chan struct{}, guilds*joins)
+	c.OnVoiceStateUpdate(func(update *voice.StateUpdate) {
+		if update.ChannelID == nil {
+			leaves <- struct{}{}
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err = c.Connect(ctx); err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer c.Disconnect()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, guilds)
+	for i := 0; i < guilds; i++ {
+		wg.Add(1)
+		go func(guildID string) {
+			defer wg.Done()
+			errs <- joinAndWaitAutoLeave(ctx, c, srv, guildID, joins)
+		}(strconv.Itoa(i + 1))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < guilds*joins; i++ {
+		select {
+		case <-leaves:
+		case <-ctx.Done():
+			t.Fatalf("left %d voice channels, expected %d", i, guilds*joins)
+		}
+	}
+}
+
+// joinAndWaitAutoLeave joins a voice channel of the given guild the given number
+// of times, waiting for the connection to be automatically left each time.
+func joinAndWaitAutoLeave(ctx context.Context, c *harmony.Client, srv *harmonytest.Server, guildID string, joins int) error {
+	left := make(chan struct{}, 1)
+	opts := []harmony.VoiceOption{
+		harmony.WithVoiceConnectionOptions(srv.VoiceConnectionOptions()...),
+		harmony.WithVoiceIdleTimeout(time.Millisecond),
+		harmony.WithVoiceAutoDisconnectCallback(func(string, harmony.VoiceAutoDisconnectReason) {
+			left <- struct{}{}
+		}),
+	}
+
+	for i := 0; i < joins; i++ {
+		for {
+			_, err := c.JoinVoiceChannel(ctx, guildID, "100"+guildID, false, false, opts...)
+			if err == nil {
+				break
+			}
+			// The previous connection may be about to be left.
+			if !errors.Is(err, harmony.ErrAlreadyConnectedToVoice) {
+				return err
+			}
+			select {
+			case <-time.After(time.Millisecond):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-left:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+/*
+Package voiceguard provides a plugin enforcing server mutes and deafens of guild
+members: if another moderator unmutes or undeafens them, they are muted or
+deafened again right away:
+
+	g := voiceguard.New()
+	client, err := harmony.NewClient(token, harmony.WithPlugins(g))
+
+	// Later, in a command handler:
+	err = g.Enforce(ctx, guildID, userID, voiceguard.Enforcement{Mute: true})
+
+Members that are not connected to a voice channel when an enforcement starts are
+muted or deafened when they join one. Enforcements are kept in memory and are lost
+when the program stops. The client needs the GUILD_VOICE_STATES intent and the
+'MUTE_MEMBERS' and 'DEAFEN_MEMBERS' permissions.
+*/
+package voiceguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/skwair/harmony"
+	"github.com/skwair/harmony/guild"
+	"github.com/skwair/harmony/voice"
+)
+
+// DefaultReason is the default reason set in the audit log
+// when a mute or a deafen is enforced again.
+const DefaultReason = "Voice enforcement"
+
+const (
+	// requestTimeout is the time allowed to enforce a voice state.
+	requestTimeout = 10 * time.Second
+	// codeNotConnectedToVoice is the JSON error code returned by Discord when
+	// trying to mute or deafen a member that is not connected to voice.
+	codeNotConnectedToVoice = 40032
+)
+
+// Enforcement describes what is enforced for a member.
+type Enforcement struct {
+	Mute bool
+	Deaf bool
+}
+
+// Guard is a harmony.Plugin enforcing server mutes and deafens. It is safe for
+// concurrent use. Create one with New.
+type Guard struct {
+	reason  string
+	onError func(error)
+
+	mu       sync.Mutex
+	enforced map[member]Enforcement
+
+	client *harmony.Client
+	sub    *harmony.Subscription
+}
+
+type member struct {
+	guildID string
+	userID  string
+}
+
+// Option is a function that configures a Guard.
+type Option func(*Guard)
+
+// WithReason sets the reason set in the audit log when a mute or a deafen
+// is enforced again. Defaults to DefaultReason.
+func WithReason(reason string) Option {
+	return func(g *Guard) {
+		g.reason = reason
+	}
+}
+
+// WithErrorHandler sets the function called when a mute or a deafen can
+// not be enforced again, for instance because of missing permissions.
+// Errors are ignored by default.
+func WithErrorHandler(f func(err error)) Option {
+	return func(g *Guard) {
+		g.onError = f
+	}
+}
+
+// New returns a new Guard enforcing nothing.
+func New(opts ...Option) *Guard {
+	g := &Guard{
+		reason:   DefaultReason,
+		onError:  func(error) {},
+		enforced: make(map[member]Enforcement),
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// Enforce starts enforcing the given mute and deafen for the given member, muting
+// or deafening them right away if they are connected to a voice channel. An
+// empty enforcement is the same as calling Release.
+func (g *Guard) Enforce(ctx context.Context, guildID, userID string, e Enforcement) error {
+	if e == (Enforcement{}) {
+		return g.Release(ctx, guildID, userID)
+	}
+
+	g.mu.Lock()
+	g.enforced[member{guildID: guildID, userID: userID}] = e
+	g.mu.Unlock()
+
+	var opts []guild.MemberSetting
+	if e.Mute {
+		opts = append(opts, guild.WithMute(true))
+	}
+	if e.Deaf {
+		opts = append(opts, guild.WithDeaf(true))
+	}
+	return g.modify(ctx, guildID, userID, guild.NewMemberSettings(opts...))
+}
+
+// Release stops enforcing mutes and deafens for the given member, unmuting and
+// undeafening them if they were enforced. It does nothing if nothing is enforced.
+func (g *Guard) Release(ctx context.Context, guildID, userID string) error {
+	m := member{guildID: guildID, userID: userID}
+
+	g.mu.Lock()
+	e, ok := g.enforced[m]
+	delete(g.enforced, m)
+	g.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	var opts []guild.MemberSetting
+	if e.Mute {
+		opts = append(opts, guild.WithMute(false))
+	}
+	if e.Deaf {
+		opts = append(opts, guild.WithDeaf(false))
+	}
+	return g.modify(ctx, guildID, userID, guild.NewMemberSettings(opts...))
+}
+
+// Enforced returns what is enforced for the given member, if anything.
+func (g *Guard) Enforced(guildID, userID string) (Enforcement, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	e, ok := g.enforced[member{guildID: guildID, userID: userID}]
+	return e, ok
+}
+
+// modify modifies the given member, ignoring errors caused by them
+// not being connected to a voice channel.
+func (g *Guard) modify(ctx context.Context, guildID, userID string, settings *guild.MemberSettings) error {
+	err := g.client.Guild(guildID).ModifyMemberWithReason(ctx, userID, settings, g.reason)
+
+	var apiErr harmony.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == codeNotConnectedToVoice {
+		return nil
+	}
+	return err
+}
+
+// Init implements the harmony.Plugin interface.
+func (g *Guard) Init(c *harmony.Client) error {
+	g.client = c
+	return nil
+}
+
+// Start implements the harmony.Plugin interface.
+func (g *Guard) Start(ctx context.Context) error {
+	g.sub = g.client.Subscribe(func(e *harmony.Event) {
+		if vs, ok := e.Data.(*voice.StateUpdate); ok {
+			g.handle(vs)
+		}
+	})
+	return nil
+}
+
+// Stop implements the harmony.Plugin interface.
+func (g *Guard) Stop(ctx context.Context) error {
+	return g.sub.Close(ctx)
+}
+
+// handle mutes or deafens again the member of the given voice
+// state if it does not respect what is enforced for them.
+func (g *Guard) handle(vs *voice.StateUpdate) {
+	// Members that left voice can not be muted or deafened.
+	if vs.ChannelID == nil {
+		return
+	}
+
+	e, ok := g.Enforced(vs.GuildID, vs.UserID)
+	if !ok {
+		return
+	}
+
+	var opts []guild.MemberSetting
+	if e.Mute && !vs.Mute {
+		opts = append(opts, guild.WithMute(true))
+	}
+	if e.Deaf && !vs.Deaf {
+		opts = append(opts, guild.WithDeaf(true))
+	}
+	if len(opts) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if err := g.modify(ctx, vs.GuildID, vs.UserID, guild.NewMemberSettings(opts...)); err != nil {
+		g.onError(fmt.Errorf("voiceguard: could not enforce voice state of user %s in guild %s: %w", vs.UserID, vs.GuildID, err))
+	}
+}
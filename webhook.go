@@ -10,6 +10,7 @@ import (
 
 	"github.com/skwair/harmony/embed"
 	"github.com/skwair/harmony/internal/endpoint"
+	"github.com/skwair/harmony/message"
 	"github.com/skwair/harmony/webhook"
 )
 
@@ -97,6 +98,14 @@ type WebhookParameters struct {
 	TTS       bool          `json:"tts,omitempty"`
 	Embeds    []embed.Embed `json:"embeds,omitempty"`
 	Files     []File        `json:"-"`
+	// Mentions in the content that actually notify users, optional.
+	AllowedMentions *message.AllowedMentions `json:"allowed_mentions,omitempty"`
+	// ID of the thread of the channel of the webhook the
+	// message is sent to or edited in, optional.
+	ThreadID string `json:"-"`
+	// Name of the post to create when the channel of the webhook is a forum
+	// channel, optional. The message is sent as the first message of the post.
+	ThreadName string `json:"thread_name,omitempty"`
 }
 
 // json implements the multipartPayload interface so WebhookParameters can be used as
@@ -108,11 +117,22 @@ func (p *WebhookParameters) json() ([]byte, error) {
 // ExecWebhook executes the webhook with the id id given its token and some
 // execution parameters. wait indicates if we should wait for server confirmation
 // of message send before response. If wait is set to false, the returned Message
-// will be nil even if there is no error.
+// will be nil even if there is no error. See WebhookResource.Execute to execute
+// webhooks with the rate limiter of a client.
 func ExecWebhook(ctx context.Context, id, token string, p *WebhookParameters, wait bool) (*Message, error) {
+	return execWebhook(ctx, doReqNoAuth, id, token, p, wait)
+}
+
+// doReqFunc sends a request to an endpoint, such as Client.doReq or doReqNoAuth.
+type doReqFunc func(ctx context.Context, e *endpoint.Endpoint, p *requestPayload) (*http.Response, error)
+
+func execWebhook(ctx context.Context, do doReqFunc, id, token string, p *WebhookParameters, wait bool) (*Message, error) {
 	if p == nil {
 		return nil, errors.New("p is nil")
 	}
+	if err := embed.ValidateAll(p.Embeds); err != nil {
+		return nil, err
+	}
 
 	var payload *requestPayload
 	if len(p.Files) > 0 {
@@ -129,15 +149,29 @@ func ExecWebhook(ctx context.Context, id, token string, p *WebhookParameters, wa
 		payload = jsonPayload(b)
 	}
 
-	q := url.Values{}
-	q.Set("wait", strconv.FormatBool(wait))
-	e := endpoint.ExecuteWebhook(id, token, q.Encode())
-	resp, err := doReqNoAuth(ctx, e, payload)
+	e := endpoint.ExecuteWebhook(id, token, webhookQuery(wait, p.ThreadID))
+	resp, err := do(ctx, e, payload)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	return webhookMessage(resp, wait)
+}
+
+// webhookQuery returns the query string of a webhook execution.
+func webhookQuery(wait bool, threadID string) string {
+	q := url.Values{}
+	q.Set("wait", strconv.FormatBool(wait))
+	if threadID != "" {
+		q.Set("thread_id", threadID)
+	}
+	return q.Encode()
+}
+
+// webhookMessage returns the message sent by executing a webhook, or nil if
+// wait is false.
+func webhookMessage(resp *http.Response, wait bool) (*Message, error) {
 	if !wait {
 		if resp.StatusCode != http.StatusNoContent {
 			return nil, apiError(resp)
@@ -149,6 +183,54 @@ func ExecWebhook(ctx context.Context, id, token string, p *WebhookParameters, wa
 		return nil, apiError(resp)
 	}
 
+	var m Message
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// EditWebhookMessage edits a message previously sent by the webhook with the id
+// id given its token. Only the content, the embeds and the allowed mentions of the
+// message can be edited, other parameters are ignored except ThreadID, which must
+// be set for messages sent in a thread. Fires a Message Update Gateway event.
+func EditWebhookMessage(ctx context.Context, id, token, messageID string, p *WebhookParameters) (*Message, error) {
+	if p == nil {
+		return nil, errors.New("p is nil")
+	}
+	if err := embed.ValidateAll(p.Embeds); err != nil {
+		return nil, err
+	}
+
+	edit := struct {
+		Content         string                   `json:"content"`
+		Embeds          []embed.Embed            `json:"embeds"`
+		AllowedMentions *message.AllowedMentions `json:"allowed_mentions,omitempty"`
+	}{
+		Content:         p.Content,
+		Embeds:          p.Embeds,
+		AllowedMentions: p.AllowedMentions,
+	}
+	b, err := json.Marshal(edit)
+	if err != nil {
+		return nil, err
+	}
+
+	var query string
+	if p.ThreadID != "" {
+		query = url.Values{"thread_id": {p.ThreadID}}.Encode()
+	}
+	e := endpoint.EditWebhookMessage(id, token, messageID, query)
+	resp, err := doReqNoAuth(ctx, e, jsonPayload(b))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
 	var m Message
 	if err = json.NewDecoder(resp.Body).Decode(&m); err != nil {
 		return nil, err
@@ -156,6 +238,32 @@ func ExecWebhook(ctx context.Context, id, token string, p *WebhookParameters, wa
 	return &m, nil
 }
 
+// DeleteWebhookMessage deletes a message previously sent by the webhook with
+// the id id given its token. Fires a Message Delete Gateway event.
+func DeleteWebhookMessage(ctx context.Context, id, token, messageID string) error {
+	return DeleteWebhookThreadMessage(ctx, id, token, "", messageID)
+}
+
+// DeleteWebhookThreadMessage is like DeleteWebhookMessage but for
+// messages sent in the thread with the given ID.
+func DeleteWebhookThreadMessage(ctx context.Context, id, token, threadID, messageID string) error {
+	var query string
+	if threadID != "" {
+		query = url.Values{"thread_id": {threadID}}.Encode()
+	}
+	e := endpoint.DeleteWebhookMessage(id, token, messageID, query)
+	resp, err := doReqNoAuth(ctx, e, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return apiError(resp)
+	}
+	return nil
+}
+
 func (c *Client) webhooks(ctx context.Context, e *endpoint.Endpoint) ([]Webhook, error) {
 	resp, err := c.doReq(ctx, e, nil)
 	if err != nil {
@@ -206,6 +314,43 @@ func (r *WebhookResource) Get(ctx context.Context) (*Webhook, error) {
 	return &w, nil
 }
 
+// Execute executes the webhook given its token and some execution parameters.
+// It is like ExecWebhook, except the request is sent by the client so it
+// shares its HTTP client and rate limiter.
+func (r *WebhookResource) Execute(ctx context.Context, token string, p *WebhookParameters, wait bool) (*Message, error) {
+	return execWebhook(ctx, r.client.doReq, r.webhookID, token, p, wait)
+}
+
+// ExecuteSlack executes the webhook given its token with a payload in the format
+// of Slack incoming webhooks. See Execute for the meaning of wait.
+func (r *WebhookResource) ExecuteSlack(ctx context.Context, token string, payload json.RawMessage, wait bool) (*Message, error) {
+	e := endpoint.ExecuteSlackWebhook(r.webhookID, token, webhookQuery(wait, ""))
+	resp, err := r.client.doReq(ctx, e, jsonPayload(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return webhookMessage(resp, wait)
+}
+
+// ExecuteGitHub executes the webhook given its token with a payload sent by a GitHub
+// webhook, event being the type of event as set in its X-GitHub-Event header, for
+// instance "push". See Execute for the meaning of wait.
+func (r *WebhookResource) ExecuteGitHub(ctx context.Context, token, event string, payload json.RawMessage, wait bool) (*Message, error) {
+	h := http.Header{}
+	h.Set("X-GitHub-Event", event)
+
+	e := endpoint.ExecuteGitHubWebhook(r.webhookID, token, webhookQuery(wait, ""))
+	resp, err := r.client.doReqWithHeader(ctx, e, jsonPayload(payload), h)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return webhookMessage(resp, wait)
+}
+
 // Modify is like ModifyWithReason but with no particular reason.
 func (r *WebhookResource) Modify(ctx context.Context, settings *webhook.Settings) (*Webhook, error) {
 	return r.ModifyWithReason(ctx, settings, "")
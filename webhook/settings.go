@@ -7,7 +7,8 @@ type Settings struct {
 	Name *optional.String `json:"name,omitempty"`
 	// Avatar is a data URI scheme that support JPG, GIF, and PNG formats, see
 	// https://discord.com/developers/docs/resources/user#avatar-data
-	// for more information.
+	// for more information. harmony.ImageData can be used to encode
+	// images in this format.
 	Avatar    *optional.String `json:"avatar,omitempty"`
 	ChannelID *optional.String `json:"channel_id,omitempty"`
 }
@@ -34,9 +35,14 @@ func WithName(name string) Setting {
 }
 
 // WithAvatar sets the avatar of a webhook.
+// An empty uri will remove the current avatar.
 func WithAvatar(uri string) Setting {
 	return func(s *Settings) {
-		s.Avatar = optional.NewString(uri)
+		if uri == "" {
+			s.Avatar = optional.NewNilString()
+		} else {
+			s.Avatar = optional.NewString(uri)
+		}
 	}
 }
 